@@ -72,7 +72,7 @@ var (
 
 func main() {
 	log.Println("creating solid...")
-	solid := SmoothJoin(0.1, MakeBody(), MakeHeadNeck(), MakeLegs(), MakeHindLegMuscles(),
+	solid := SmoothJoin(0.05, MakeBody(), MakeHeadNeck(), MakeLegs(), MakeHindLegMuscles(),
 		MakeSnout(), MakeNub(), MakeEars())
 	log.Println("creating mesh...")
 	mesh := model3d.MarchingCubesSearch(solid, 0.01, 8)
@@ -78,11 +78,7 @@ func SaveRendering(mesh *model3d.Mesh) {
 			Radius: 1.0,
 		}, render3d.NewColor(120.0)),
 	)
-	camera := render3d.NewCameraAt(
-		model3d.XYZ(2, -4, 4).Scale(0.8),
-		model3d.Coord3D{},
-		math.Pi/3.6,
-	)
+	camera := render3d.FitCamera(mesh, math.Pi/3.6, model3d.XYZ(2, -4, 4))
 	renderer := &render3d.BidirPathTracer{
 		Camera:         camera,
 		Light:          light,
@@ -88,6 +88,8 @@ func main() {
 	log.Println("Rendering...")
 	render3d.SaveRandomGrid("rendering_lid.png", lidMesh, 3, 3, 300, nil)
 	render3d.SaveRandomGrid("rendering_body.png", bodyMesh, 3, 3, 300, nil)
+	render3d.SaveCrossSectionGrid("rendering_body_section.png", bodyMesh, model3d.Y(1), 2, 2,
+		300, nil)
 }
 
 type PreviewCutout struct{}
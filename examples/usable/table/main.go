@@ -5,6 +5,7 @@ import (
 	"math"
 	"os"
 
+	"github.com/unixpickle/essentials"
 	"github.com/unixpickle/model3d/model3d"
 	"github.com/unixpickle/model3d/render3d"
 	"github.com/unixpickle/model3d/toolbox3d"
@@ -28,7 +29,7 @@ const (
 func main() {
 	if _, err := os.Stat("stand.stl"); os.IsNotExist(err) {
 		log.Println("Creating stand...")
-		mesh := model3d.MarchingCubesSearch(StandSolid(), 0.01, 8)
+		mesh := model3d.MarchingCubesSearch(model3d.OptimizeCSG(StandSolid()), 0.01, 8)
 		mesh.SaveGroupedSTL("stand.stl")
 		render3d.SaveRandomGrid("stand.png", mesh, 3, 3, 300, nil)
 	}
@@ -55,7 +56,7 @@ func main() {
 
 	if _, err := os.Stat("top.stl"); os.IsNotExist(err) {
 		log.Println("Creating top...")
-		mesh := model3d.MarchingCubesSearch(TopSolid(), 0.01, 8)
+		mesh := model3d.MarchingCubesOctreeSearch(TopSolid(), 0.01, 0.08, 8)
 		log.Println("Eliminating co-planar...")
 		mesh = mesh.EliminateCoplanar(1e-8)
 		mesh.SaveGroupedSTL("top.stl")
@@ -71,6 +72,24 @@ func main() {
 		})
 		mesh.SaveGroupedSTL("infill_cube.stl")
 	}
+
+	if _, err := os.Stat("table_kit"); os.IsNotExist(err) {
+		log.Println("Creating kit...")
+		kit := &toolbox3d.Kit{Name: "table"}
+		for _, part := range []*toolbox3d.KitPart{
+			{Name: "stand", Notes: "alternatively, print cone_stand.stl"},
+			{Name: "leg"},
+			{Name: "top"},
+			{Name: "infill_cube", Notes: "used to fill in the screws on the top"},
+		} {
+			mesh, err := model3d.LoadSTL(part.Name + ".stl")
+			essentials.Must(err)
+			part.Mesh = mesh
+			part.Material = "PLA"
+			kit.Add(part)
+		}
+		essentials.Must(kit.Save("table_kit"))
+	}
 }
 
 func StandSolid() model3d.Solid {
@@ -0,0 +1,345 @@
+package fileformats
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+)
+
+// An AMFColor is an RGBA color in an AMF file, where each
+// component ranges from 0 to 1.
+type AMFColor [4]float64
+
+// An AMFMaterial is a named material which volumes of an
+// AMF file can refer to by ID.
+type AMFMaterial struct {
+	ID    string
+	Name  string
+	Color *AMFColor
+}
+
+// An AMFVolume is a group of triangles in an object of an
+// AMF file which share a material.
+type AMFVolume struct {
+	// MaterialID refers to a material in the file, or is ""
+	// for no material.
+	MaterialID string
+
+	// Color overrides the color of the material and the
+	// object, if it is non-nil.
+	Color *AMFColor
+
+	// Triangles contains vertex indices, starting at 0.
+	Triangles [][3]int
+}
+
+// An AMFObject is a single mesh in an AMF file.
+type AMFObject struct {
+	ID   string
+	Name string
+
+	// Color is the default color for the volumes of the
+	// object, if it is non-nil.
+	Color *AMFColor
+
+	Vertices [][3]float64
+	Volumes  []*AMFVolume
+}
+
+// An AMFFile represents the contents of an additive
+// manufacturing file.
+type AMFFile struct {
+	// Unit is the unit of the coordinates, such as
+	// "millimeter" or "inch".
+	// If it is "", millimeters are implied.
+	Unit string
+
+	Materials []*AMFMaterial
+	Objects   []*AMFObject
+}
+
+// ReadAMF decodes an AMF file.
+//
+// Both plain XML and zip-compressed files are supported.
+// Colors must be constant; color formulas are not
+// supported.
+// Vertex and triangle colors, as well as constellations,
+// are ignored.
+func ReadAMF(r io.Reader) (a *AMFFile, err error) {
+	defer essentials.AddCtxTo("read AMF", &err)
+
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); string(magic) == "PK" {
+		data, err := ioutil.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		if len(zr.File) == 0 {
+			return nil, errors.New("empty zip archive")
+		}
+		f, err := zr.File[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		br = bufio.NewReader(f)
+	}
+
+	var raw amfXML
+	if err := xml.NewDecoder(br).Decode(&raw); err != nil {
+		return nil, err
+	}
+	return raw.decode()
+}
+
+// Write encodes the file as uncompressed XML.
+func (a *AMFFile) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(bw)
+	enc.Indent("", " ")
+	if err := enc.Encode(a.encode()); err != nil {
+		return err
+	}
+	if _, err := bw.WriteString("\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Material finds the material with the given ID, or
+// returns nil if there is no such material.
+func (a *AMFFile) Material(id string) *AMFMaterial {
+	for _, m := range a.Materials {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// VolumeColor gets the effective color of a volume in an
+// object, or nil if no color is specified.
+//
+// The volume's own color takes precedence, followed by the
+// color of its material, followed by the object's color.
+func (a *AMFFile) VolumeColor(obj *AMFObject, vol *AMFVolume) *AMFColor {
+	if vol.Color != nil {
+		return vol.Color
+	}
+	if vol.MaterialID != "" {
+		if m := a.Material(vol.MaterialID); m != nil && m.Color != nil {
+			return m.Color
+		}
+	}
+	return obj.Color
+}
+
+type amfXMLMetadata struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type amfXMLColor struct {
+	R string `xml:"r"`
+	G string `xml:"g"`
+	B string `xml:"b"`
+	A string `xml:"a,omitempty"`
+}
+
+type amfXMLMaterial struct {
+	ID       string           `xml:"id,attr"`
+	Metadata []amfXMLMetadata `xml:"metadata"`
+	Color    *amfXMLColor     `xml:"color"`
+}
+
+type amfXMLCoordinates struct {
+	X float64 `xml:"x"`
+	Y float64 `xml:"y"`
+	Z float64 `xml:"z"`
+}
+
+type amfXMLVertex struct {
+	Coordinates amfXMLCoordinates `xml:"coordinates"`
+}
+
+type amfXMLTriangle struct {
+	V1 int `xml:"v1"`
+	V2 int `xml:"v2"`
+	V3 int `xml:"v3"`
+}
+
+type amfXMLVolume struct {
+	MaterialID string           `xml:"materialid,attr,omitempty"`
+	Color      *amfXMLColor     `xml:"color"`
+	Triangles  []amfXMLTriangle `xml:"triangle"`
+}
+
+type amfXMLObject struct {
+	ID       string           `xml:"id,attr"`
+	Metadata []amfXMLMetadata `xml:"metadata"`
+	Color    *amfXMLColor     `xml:"color"`
+	Vertices []amfXMLVertex   `xml:"mesh>vertices>vertex"`
+	Volumes  []amfXMLVolume   `xml:"mesh>volume"`
+}
+
+type amfXML struct {
+	XMLName   xml.Name         `xml:"amf"`
+	Unit      string           `xml:"unit,attr,omitempty"`
+	Version   string           `xml:"version,attr,omitempty"`
+	Materials []amfXMLMaterial `xml:"material"`
+	Objects   []amfXMLObject   `xml:"object"`
+}
+
+func (a *AMFFile) encode() *amfXML {
+	res := &amfXML{Unit: a.Unit, Version: "1.1"}
+	for _, m := range a.Materials {
+		res.Materials = append(res.Materials, amfXMLMaterial{
+			ID:       m.ID,
+			Metadata: encodeAMFName(m.Name),
+			Color:    encodeAMFColor(m.Color),
+		})
+	}
+	for _, obj := range a.Objects {
+		xmlObj := amfXMLObject{
+			ID:       obj.ID,
+			Metadata: encodeAMFName(obj.Name),
+			Color:    encodeAMFColor(obj.Color),
+			Vertices: make([]amfXMLVertex, len(obj.Vertices)),
+		}
+		for i, v := range obj.Vertices {
+			xmlObj.Vertices[i].Coordinates = amfXMLCoordinates{X: v[0], Y: v[1], Z: v[2]}
+		}
+		for _, vol := range obj.Volumes {
+			xmlVol := amfXMLVolume{
+				MaterialID: vol.MaterialID,
+				Color:      encodeAMFColor(vol.Color),
+				Triangles:  make([]amfXMLTriangle, len(vol.Triangles)),
+			}
+			for i, t := range vol.Triangles {
+				xmlVol.Triangles[i] = amfXMLTriangle{V1: t[0], V2: t[1], V3: t[2]}
+			}
+			xmlObj.Volumes = append(xmlObj.Volumes, xmlVol)
+		}
+		res.Objects = append(res.Objects, xmlObj)
+	}
+	return res
+}
+
+func (a *amfXML) decode() (*AMFFile, error) {
+	res := &AMFFile{Unit: a.Unit}
+	for _, m := range a.Materials {
+		color, err := m.Color.decode()
+		if err != nil {
+			return nil, errors.Wrap(err, "material "+m.ID)
+		}
+		res.Materials = append(res.Materials, &AMFMaterial{
+			ID:    m.ID,
+			Name:  decodeAMFName(m.Metadata),
+			Color: color,
+		})
+	}
+	for _, obj := range a.Objects {
+		color, err := obj.Color.decode()
+		if err != nil {
+			return nil, errors.Wrap(err, "object "+obj.ID)
+		}
+		resObj := &AMFObject{
+			ID:       obj.ID,
+			Name:     decodeAMFName(obj.Metadata),
+			Color:    color,
+			Vertices: make([][3]float64, len(obj.Vertices)),
+		}
+		for i, v := range obj.Vertices {
+			c := v.Coordinates
+			resObj.Vertices[i] = [3]float64{c.X, c.Y, c.Z}
+		}
+		for _, vol := range obj.Volumes {
+			color, err := vol.Color.decode()
+			if err != nil {
+				return nil, errors.Wrap(err, "object "+obj.ID)
+			}
+			resVol := &AMFVolume{
+				MaterialID: vol.MaterialID,
+				Color:      color,
+				Triangles:  make([][3]int, len(vol.Triangles)),
+			}
+			for i, t := range vol.Triangles {
+				for _, idx := range []int{t.V1, t.V2, t.V3} {
+					if idx < 0 || idx >= len(obj.Vertices) {
+						return nil, fmt.Errorf("object %s: vertex index out of range: %d",
+							obj.ID, idx)
+					}
+				}
+				resVol.Triangles[i] = [3]int{t.V1, t.V2, t.V3}
+			}
+			resObj.Volumes = append(resObj.Volumes, resVol)
+		}
+		res.Objects = append(res.Objects, resObj)
+	}
+	return res, nil
+}
+
+func encodeAMFName(name string) []amfXMLMetadata {
+	if name == "" {
+		return nil
+	}
+	return []amfXMLMetadata{{Type: "name", Value: name}}
+}
+
+func decodeAMFName(metadata []amfXMLMetadata) string {
+	for _, m := range metadata {
+		if m.Type == "name" {
+			return strings.TrimSpace(m.Value)
+		}
+	}
+	return ""
+}
+
+func encodeAMFColor(c *AMFColor) *amfXMLColor {
+	if c == nil {
+		return nil
+	}
+	format := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	res := &amfXMLColor{R: format(c[0]), G: format(c[1]), B: format(c[2])}
+	if c[3] != 1 {
+		res.A = format(c[3])
+	}
+	return res
+}
+
+func (a *amfXMLColor) decode() (*AMFColor, error) {
+	if a == nil {
+		return nil, nil
+	}
+	res := &AMFColor{0, 0, 0, 1}
+	for i, s := range []string{a.R, a.G, a.B, a.A} {
+		s = strings.TrimSpace(s)
+		if s == "" && i == 3 {
+			continue
+		}
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.New("unsupported color: " + s)
+		}
+		res[i] = x
+	}
+	return res, nil
+}
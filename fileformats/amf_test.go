@@ -0,0 +1,134 @@
+package fileformats
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const testAMFData = `<?xml version="1.0" encoding="UTF-8"?>
+<amf unit="inch" version="1.1">
+ <metadata type="name">Test</metadata>
+ <material id="2">
+  <metadata type="name">Red</metadata>
+  <color><r>1</r><g>0</g><b>0</b></color>
+ </material>
+ <object id="1">
+  <metadata type="name">Wedge</metadata>
+  <color><r>0.5</r><g>0.5</g><b>0.5</b><a>0.25</a></color>
+  <mesh>
+   <vertices>
+    <vertex><coordinates><x>0</x><y>0</y><z>0</z></coordinates></vertex>
+    <vertex><coordinates><x>1</x><y>0</y><z>0</z></coordinates></vertex>
+    <vertex><coordinates><x>0</x><y>1</y><z>0</z></coordinates></vertex>
+    <vertex><coordinates><x>0</x><y>0</y><z>1</z></coordinates></vertex>
+   </vertices>
+   <volume materialid="2">
+    <triangle><v1>0</v1><v2>2</v2><v3>1</v3></triangle>
+    <triangle><v1>0</v1><v2>1</v2><v3>3</v3></triangle>
+   </volume>
+   <volume>
+    <triangle><v1>0</v1><v2>3</v2><v3>2</v3></triangle>
+   </volume>
+   <volume>
+    <color><r>0</r><g>0</g><b>1</b></color>
+    <triangle><v1>1</v1><v2>2</v2><v3>3</v3></triangle>
+   </volume>
+  </mesh>
+ </object>
+</amf>
+`
+
+func TestReadAMF(t *testing.T) {
+	expected := &AMFFile{
+		Unit: "inch",
+		Materials: []*AMFMaterial{
+			{ID: "2", Name: "Red", Color: &AMFColor{1, 0, 0, 1}},
+		},
+		Objects: []*AMFObject{
+			{
+				ID:       "1",
+				Name:     "Wedge",
+				Color:    &AMFColor{0.5, 0.5, 0.5, 0.25},
+				Vertices: [][3]float64{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+				Volumes: []*AMFVolume{
+					{MaterialID: "2", Triangles: [][3]int{{0, 2, 1}, {0, 1, 3}}},
+					{Triangles: [][3]int{{0, 3, 2}}},
+					{Color: &AMFColor{0, 0, 1, 1}, Triangles: [][3]int{{1, 2, 3}}},
+				},
+			},
+		},
+	}
+
+	t.Run("Plain", func(t *testing.T) {
+		actual, err := ReadAMF(bytes.NewReader([]byte(testAMFData)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected file: %#v", actual)
+		}
+	})
+
+	t.Run("Compressed", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.Create("test.amf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(testAMFData))
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := ReadAMF(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected file: %#v", actual)
+		}
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := expected.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := ReadAMF(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected file: %#v", actual)
+		}
+	})
+
+	t.Run("VolumeColor", func(t *testing.T) {
+		obj := expected.Objects[0]
+		expectedColors := []*AMFColor{
+			expected.Materials[0].Color,
+			obj.Color,
+			obj.Volumes[2].Color,
+		}
+		for i, vol := range obj.Volumes {
+			if c := expected.VolumeColor(obj, vol); c != expectedColors[i] {
+				t.Errorf("volume %d: unexpected color %v", i, c)
+			}
+		}
+	})
+}
+
+func TestReadAMFErrors(t *testing.T) {
+	for _, data := range []string{
+		`<amf><object id="0"><mesh><vertices></vertices>` +
+			`<volume><triangle><v1>0</v1><v2>1</v2><v3>2</v3></triangle></volume></mesh></object></amf>`,
+		`<amf><material id="1"><color><r>sin(x)</r><g>0</g><b>0</b></color></material></amf>`,
+		`<notamf></notamf>`,
+	} {
+		if _, err := ReadAMF(bytes.NewReader([]byte(data))); err == nil {
+			t.Errorf("expected error for data: %s", data)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package fileformats
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+)
+
+// An ExcellonHole is a hole drilled in a circuit board.
+//
+// All dimensions are in millimeters.
+type ExcellonHole struct {
+	Center   [2]float64
+	Diameter float64
+
+	// Plated is true if a comment in the file specified
+	// that the holes are plated, as in KiCad's separate PTH
+	// and NPTH files or the sections of a merged file.
+	Plated bool
+}
+
+// ReadExcellon reads the holes from an Excellon drill
+// file, such as the drill files exported by KiCad.
+//
+// Coordinates may be decimal or use implied decimal
+// points, with either leading or trailing zeros omitted.
+// Routed and G85 slots are ignored.
+func ReadExcellon(r io.Reader) (holes []ExcellonHole, err error) {
+	defer essentials.AddCtxTo("read Excellon", &err)
+
+	scale := 25.4
+	intDigits, decDigits := 2, 4
+	leadingZeros := false
+	plated := false
+	routing := false
+	tools := map[string]float64{}
+	var tool string
+	var x, y float64
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, ";") {
+			// Merged files have a section for each type.
+			if strings.Contains(line, "TYPE=NON_PLATED") ||
+				strings.Contains(line, "FileFunction,NonPlated") {
+				plated = false
+			} else if strings.Contains(line, "TYPE=PLATED") ||
+				strings.Contains(line, "FileFunction,Plated") {
+				plated = true
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "METRIC"), strings.HasPrefix(line, "M71"):
+			scale = 1
+			intDigits, decDigits = 3, 3
+			leadingZeros = strings.Contains(line, "LZ")
+			parseExcellonFormat(line, &intDigits, &decDigits)
+			continue
+		case strings.HasPrefix(line, "INCH"), strings.HasPrefix(line, "M72"):
+			scale = 25.4
+			intDigits, decDigits = 2, 4
+			leadingZeros = strings.Contains(line, "LZ")
+			parseExcellonFormat(line, &intDigits, &decDigits)
+			continue
+		case strings.HasPrefix(line, "G00"), strings.HasPrefix(line, "G01"),
+			strings.HasPrefix(line, "G02"), strings.HasPrefix(line, "G03"):
+			routing = true
+			continue
+		case strings.HasPrefix(line, "G05"):
+			routing = false
+			continue
+		}
+		if line[0] == 'T' {
+			name, diam, hasDiam := parseExcellonTool(line)
+			if hasDiam {
+				d, err := strconv.ParseFloat(diam, 64)
+				if err != nil {
+					return nil, errors.Errorf("line %d: invalid tool diameter", lineNum)
+				}
+				tools[name] = d * scale
+			} else if _, ok := tools[name]; ok || name == "0" {
+				tool = name
+			} else {
+				return nil, errors.Errorf("line %d: undefined tool %s", lineNum, name)
+			}
+			continue
+		}
+		if (line[0] != 'X' && line[0] != 'Y') || routing || strings.Contains(line, "G85") {
+			// Ignore slots and other commands.
+			continue
+		}
+		if tool == "" || tool == "0" {
+			return nil, errors.Errorf("line %d: no tool selected", lineNum)
+		}
+		for _, axis := range []struct {
+			Name   byte
+			Target *float64
+		}{{'X', &x}, {'Y', &y}} {
+			value, ok := excellonField(line, axis.Name)
+			if !ok {
+				continue
+			}
+			c, err := parseExcellonCoord(value, intDigits, decDigits, leadingZeros)
+			if err != nil {
+				return nil, errors.Errorf("line %d: %s", lineNum, err.Error())
+			}
+			*axis.Target = c * scale
+		}
+		holes = append(holes, ExcellonHole{
+			Center:   [2]float64{x, y},
+			Diameter: tools[tool],
+			Plated:   plated,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return holes, nil
+}
+
+// parseExcellonTool parses a tool definition (such as
+// "T1C0.800") or a tool selection (such as "T01").
+func parseExcellonTool(line string) (name, diameter string, hasDiameter bool) {
+	end := 1
+	for end < len(line) && line[end] >= '0' && line[end] <= '9' {
+		end++
+	}
+	name = strings.TrimLeft(line[1:end], "0")
+	if name == "" {
+		name = "0"
+	}
+	if idx := strings.IndexByte(line, 'C'); idx != -1 {
+		diam := line[idx+1:]
+		for i, ch := range diam {
+			if (ch < '0' || ch > '9') && ch != '.' {
+				diam = diam[:i]
+				break
+			}
+		}
+		return name, diam, true
+	}
+	return name, "", false
+}
+
+// parseExcellonFormat parses an optional digit format,
+// as in "METRIC,LZ,000.000".
+func parseExcellonFormat(line string, intDigits, decDigits *int) {
+	for _, field := range strings.Split(line, ",") {
+		parts := strings.Split(field, ".")
+		if len(parts) == 2 && strings.Trim(field, "0.") == "" {
+			*intDigits, *decDigits = len(parts[0]), len(parts[1])
+		}
+	}
+}
+
+func excellonField(line string, name byte) (string, bool) {
+	idx := strings.IndexByte(line, name)
+	if idx == -1 {
+		return "", false
+	}
+	value := line[idx+1:]
+	for i, ch := range value {
+		if (ch < '0' || ch > '9') && ch != '.' && ch != '-' && ch != '+' {
+			value = value[:i]
+			break
+		}
+	}
+	return value, true
+}
+
+func parseExcellonCoord(value string, intDigits, decDigits int, leadingZeros bool) (float64, error) {
+	if strings.Contains(value, ".") {
+		return strconv.ParseFloat(value, 64)
+	}
+	sign := 1.0
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+		value = value[1:]
+	} else if strings.HasPrefix(value, "+") {
+		value = value[1:]
+	}
+	if leadingZeros {
+		// Trailing zeros are omitted, so pad to the full
+		// number of digits.
+		for len(value) < intDigits+decDigits {
+			value += "0"
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid coordinate: " + value)
+	}
+	return sign * float64(n) / math.Pow(10, float64(decDigits)), nil
+}
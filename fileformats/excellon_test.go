@@ -0,0 +1,128 @@
+package fileformats
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestReadExcellonDecimal(t *testing.T) {
+	data := `M48
+; DRILL file {KiCad 7.0.0} date 2023-01-01
+; FORMAT={-:-/ absolute / metric / decimal}
+; #@! TF.FileFunction,Plated,1,2,PTH
+FMAT,2
+METRIC
+T1C0.400
+T2C3.200
+%
+G90
+G05
+T1
+X10.5Y-20.25
+X11.0Y-20.25
+T2
+X0.0Y0.0
+T1
+G00X1.0Y1.0
+M15
+G01X2.0Y1.0
+M16
+G05
+X3.0Y3.0
+T0
+M30
+`
+	holes, err := ReadExcellon(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ExcellonHole{
+		{Center: [2]float64{10.5, -20.25}, Diameter: 0.4, Plated: true},
+		{Center: [2]float64{11.0, -20.25}, Diameter: 0.4, Plated: true},
+		{Center: [2]float64{0, 0}, Diameter: 3.2, Plated: true},
+		{Center: [2]float64{3, 3}, Diameter: 0.4, Plated: true},
+	}
+	checkExcellonHoles(t, expected, holes)
+}
+
+func TestReadExcellonMerged(t *testing.T) {
+	data := `M48
+; DRILL file {KiCad 7.0.0} date 2023-01-01
+; FORMAT={-:-/ absolute / metric / decimal}
+; #@! TF.FileFunction,MixedPlating,1,2
+FMAT,2
+METRIC
+; #@! TA.AperFunction,Plated,PTH,ViaDrill
+T1C0.400
+; #@! TA.AperFunction,NonPlated,NPTH,ComponentDrill
+T2C3.200
+%
+G90
+G05
+; TYPE=PLATED
+T1
+X1.0Y2.0
+; TYPE=NON_PLATED
+T2
+X5.0Y6.0
+X7.0Y8.0
+M30
+`
+	holes, err := ReadExcellon(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ExcellonHole{
+		{Center: [2]float64{1, 2}, Diameter: 0.4, Plated: true},
+		{Center: [2]float64{5, 6}, Diameter: 3.2, Plated: false},
+		{Center: [2]float64{7, 8}, Diameter: 3.2, Plated: false},
+	}
+	checkExcellonHoles(t, expected, holes)
+}
+
+func TestReadExcellonImplied(t *testing.T) {
+	data := `M48
+INCH,LZ
+T01C0.125
+%
+T01
+X01Y005
+Y01
+M30
+`
+	holes, err := ReadExcellon(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ExcellonHole{
+		{Center: [2]float64{25.4, 12.7}, Diameter: 3.175},
+		{Center: [2]float64{25.4, 25.4}, Diameter: 3.175},
+	}
+	checkExcellonHoles(t, expected, holes)
+
+	data = strings.Replace(data, "INCH,LZ", "METRIC,TZ", 1)
+	data = strings.Replace(data, "X01Y005", "X1500Y-250", 1)
+	holes, err = ReadExcellon(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []ExcellonHole{
+		{Center: [2]float64{1.5, -0.25}, Diameter: 0.125},
+		{Center: [2]float64{1.5, 0.001}, Diameter: 0.125},
+	}
+	checkExcellonHoles(t, expected, holes)
+}
+
+func checkExcellonHoles(t *testing.T, expected, actual []ExcellonHole) {
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d holes but got %d", len(expected), len(actual))
+	}
+	for i, a := range actual {
+		e := expected[i]
+		if math.Abs(a.Center[0]-e.Center[0]) > 1e-8 || math.Abs(a.Center[1]-e.Center[1]) > 1e-8 ||
+			math.Abs(a.Diameter-e.Diameter) > 1e-8 || a.Plated != e.Plated {
+			t.Errorf("hole %d: expected %v but got %v", i, e, a)
+		}
+	}
+}
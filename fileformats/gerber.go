@@ -0,0 +1,202 @@
+package fileformats
+
+import (
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+)
+
+// gerberArcResolution is the maximum angle spanned by a
+// single segment when approximating an arc.
+const gerberArcResolution = math.Pi / 32
+
+// ReadGerberOutline reads the line segments drawn in a
+// Gerber file, such as a board outline (Edge.Cuts) layer
+// exported by KiCad.
+//
+// Arcs are approximated by line segments, and flashed
+// apertures are ignored.
+// Aperture sizes are ignored, so each segment follows the
+// center of the drawn line.
+// Coordinates are returned in millimeters.
+func ReadGerberOutline(r io.Reader) (segments [][2][2]float64, err error) {
+	defer essentials.AddCtxTo("read Gerber outline", &err)
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.NewReplacer("\r", "", "\n", "").Replace(string(data))
+
+	p := &gerberParser{
+		scale:         25.4,
+		decimals:      [2]int{6, 6},
+		interpolate:   1,
+		multiQuadrant: true,
+	}
+	for len(content) > 0 {
+		if content[0] == '%' {
+			end := strings.IndexByte(content[1:], '%')
+			if end == -1 {
+				return nil, errors.New("unterminated extended command")
+			}
+			for _, stmt := range strings.Split(content[1:end+1], "*") {
+				if err := p.extended(stmt); err != nil {
+					return nil, err
+				}
+			}
+			content = content[end+2:]
+		} else {
+			end := strings.IndexByte(content, '*')
+			if end == -1 {
+				end = len(content)
+			}
+			if err := p.statement(content[:end]); err != nil {
+				return nil, err
+			}
+			if end == len(content) {
+				break
+			}
+			content = content[end+1:]
+		}
+	}
+	return p.segments, nil
+}
+
+type gerberParser struct {
+	scale         float64
+	decimals      [2]int
+	interpolate   int
+	multiQuadrant bool
+
+	x, y     float64
+	segments [][2][2]float64
+}
+
+func (g *gerberParser) extended(stmt string) error {
+	switch {
+	case strings.HasPrefix(stmt, "MOMM"):
+		g.scale = 1
+	case strings.HasPrefix(stmt, "MOIN"):
+		g.scale = 25.4
+	case strings.HasPrefix(stmt, "FS"):
+		if strings.Contains(stmt, "T") {
+			return errors.New("trailing zero omission is not supported")
+		}
+		for i, axis := range []string{"X", "Y"} {
+			idx := strings.Index(stmt, axis)
+			if idx == -1 || idx+2 >= len(stmt) {
+				return errors.New("invalid format specification: " + stmt)
+			}
+			d, err := strconv.Atoi(stmt[idx+2 : idx+3])
+			if err != nil {
+				return errors.New("invalid format specification: " + stmt)
+			}
+			g.decimals[i] = d
+		}
+	}
+	return nil
+}
+
+func (g *gerberParser) statement(stmt string) error {
+	if stmt == "" || strings.HasPrefix(stmt, "G04") {
+		return nil
+	}
+	for strings.HasPrefix(stmt, "G") {
+		end := 1
+		for end < len(stmt) && stmt[end] >= '0' && stmt[end] <= '9' {
+			end++
+		}
+		code, _ := strconv.Atoi(stmt[1:end])
+		switch code {
+		case 1, 2, 3:
+			g.interpolate = code
+		case 74:
+			g.multiQuadrant = false
+		case 75:
+			g.multiQuadrant = true
+		}
+		stmt = stmt[end:]
+	}
+	dValue, ok := excellonField(stmt, 'D')
+	dCode, _ := strconv.Atoi(dValue)
+	if !ok || dCode > 3 || !strings.ContainsAny(stmt, "XYIJ") {
+		// Aperture selections and other commands do not
+		// affect the outline.
+		return nil
+	}
+
+	x, y := g.x, g.y
+	var i, j float64
+	var err error
+	parse := func(name byte, dest *float64, axis int) {
+		if err != nil {
+			return
+		}
+		if value, ok := excellonField(stmt, name); ok {
+			var n int64
+			n, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				err = errors.New("invalid coordinate: " + value)
+				return
+			}
+			*dest = float64(n) / math.Pow(10, float64(g.decimals[axis])) * g.scale
+		}
+	}
+	parse('X', &x, 0)
+	parse('Y', &y, 1)
+	parse('I', &i, 0)
+	parse('J', &j, 1)
+	if err != nil {
+		return err
+	}
+
+	if dCode == 1 {
+		if g.interpolate == 1 {
+			g.segments = append(g.segments, [2][2]float64{{g.x, g.y}, {x, y}})
+		} else {
+			if !g.multiQuadrant {
+				return errors.New("single-quadrant arcs are not supported")
+			}
+			g.arc(x, y, i, j)
+		}
+	}
+	g.x, g.y = x, y
+	return nil
+}
+
+func (g *gerberParser) arc(x, y, i, j float64) {
+	cx, cy := g.x+i, g.y+j
+	radius := math.Hypot(i, j)
+	start := math.Atan2(g.y-cy, g.x-cx)
+	end := math.Atan2(y-cy, x-cx)
+	sweep := end - start
+	if g.interpolate == 3 {
+		// Counter-clockwise.
+		if sweep <= 0 {
+			sweep += 2 * math.Pi
+		}
+	} else {
+		if sweep >= 0 {
+			sweep -= 2 * math.Pi
+		}
+	}
+	n := int(math.Ceil(math.Abs(sweep) / gerberArcResolution))
+	prev := [2]float64{g.x, g.y}
+	for k := 1; k <= n; k++ {
+		var next [2]float64
+		if k == n {
+			next = [2]float64{x, y}
+		} else {
+			theta := start + sweep*float64(k)/float64(n)
+			next = [2]float64{cx + radius*math.Cos(theta), cy + radius*math.Sin(theta)}
+		}
+		g.segments = append(g.segments, [2][2]float64{prev, next})
+		prev = next
+	}
+}
@@ -0,0 +1,71 @@
+package fileformats
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// gerberTestOutline is a 20x10 mm rectangle with a rounded
+// corner of radius 2 at (20, 10).
+const gerberTestOutline = `G04 #@! TF.FileFunction,Profile,NP*
+%FSLAX46Y46*%
+G04 Gerber Fmt 4.6, Leading zero omitted, Abs format (unit mm)*
+%MOMM*%
+%LPD*%
+G01*
+G04 APERTURE LIST*
+%ADD10C,0.100000*%
+G04 APERTURE END LIST*
+D10*
+X0Y0D02*
+X20000000Y0D01*
+X20000000Y8000000D01*
+G75*
+G03*
+X18000000Y10000000I-2000000J0D01*
+G01*
+X0Y10000000D01*
+X0Y0D01*
+M02*
+`
+
+func TestReadGerberOutline(t *testing.T) {
+	segments, err := ReadGerberOutline(strings.NewReader(gerberTestOutline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) < 10 {
+		t.Fatalf("expected arc to be split into segments, but got %d segments", len(segments))
+	}
+
+	// Check that the segments form a closed loop.
+	for i, s := range segments {
+		next := segments[(i+1)%len(segments)]
+		if s[1] != next[0] {
+			t.Fatalf("segment %d does not connect to next segment", i)
+		}
+	}
+
+	// Check that the area is correct.
+	var area float64
+	for _, s := range segments {
+		area += s[0][0]*s[1][1] - s[1][0]*s[0][1]
+	}
+	area /= 2
+	expected := 200 - 4 + math.Pi
+	if math.Abs(area-expected) > 0.01 {
+		t.Errorf("expected area %f but got %f", expected, area)
+	}
+
+	// Arc points should be on the circle.
+	for _, s := range segments {
+		p := s[0]
+		if p[0] > 18 && p[1] > 8 {
+			r := math.Hypot(p[0]-18, p[1]-8)
+			if math.Abs(r-2) > 1e-8 {
+				t.Errorf("point %v is not on arc", p)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package fileformats
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+)
+
+// A Placement is the position of a component on a circuit
+// board, as listed in a pick-and-place file.
+type Placement struct {
+	Ref     string
+	Value   string
+	Package string
+
+	// Position is the component's center in millimeters.
+	Position [2]float64
+
+	// Rotation is the component's rotation in degrees.
+	Rotation float64
+
+	// Bottom is true if the component is on the bottom of
+	// the board.
+	Bottom bool
+}
+
+// ReadPlacements reads a component placement file, such as
+// the footprint position files exported by KiCad.
+//
+// Both the CSV format and the whitespace-separated ASCII
+// format are supported.
+// The columns are identified by the header, which must
+// include the reference, position, and rotation.
+// Positions in ASCII files are assumed to be millimeters
+// unless the header specifies inches.
+func ReadPlacements(r io.Reader) (placements []*Placement, err error) {
+	defer essentials.AddCtxTo("read placements", &err)
+
+	br := bufio.NewReader(r)
+	first, err := br.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	var header []string
+	scale := 1.0
+	if first[0] == '#' {
+		scanner := bufio.NewScanner(br)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "#") {
+				if strings.Contains(line, "Unit = inches") {
+					scale = 25.4
+				}
+				fields := strings.Fields(strings.TrimPrefix(line, "#"))
+				if len(fields) > 0 && strings.EqualFold(fields[0], "Ref") {
+					header = fields
+				}
+			} else if line != "" {
+				rows = append(rows, strings.Fields(line))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		records, err := csv.NewReader(br).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			header, rows = records[0], records[1:]
+		}
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"ref", "posx", "posy", "rot"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New("missing column: " + name)
+		}
+	}
+	get := func(row []string, name string) string {
+		if idx, ok := columns[name]; ok && idx < len(row) {
+			return row[idx]
+		}
+		return ""
+	}
+
+	for i, row := range rows {
+		p := &Placement{
+			Ref:     get(row, "ref"),
+			Value:   get(row, "val"),
+			Package: get(row, "package"),
+			Bottom:  strings.EqualFold(get(row, "side"), "bottom"),
+		}
+		for j, name := range []string{"posx", "posy", "rot"} {
+			value, err := strconv.ParseFloat(get(row, name), 64)
+			if err != nil {
+				return nil, errors.Errorf("row %d: invalid %s", i+1, name)
+			}
+			if j < 2 {
+				p.Position[j] = value * scale
+			} else {
+				p.Rotation = value
+			}
+		}
+		placements = append(placements, p)
+	}
+	return placements, nil
+}
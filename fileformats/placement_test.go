@@ -0,0 +1,48 @@
+package fileformats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPlacements(t *testing.T) {
+	csvData := `Ref,Val,Package,PosX,PosY,Rot,Side
+"C1","100n","C_0603",101.5,-52.25,90.0,top
+"J1","USB_C","USB_C_Receptacle",120.0,-60.0,180.0,bottom
+`
+	asciiData := `### Footprint positions - created on 2023-01-01 ###
+## Unit = mm, Angle = deg.
+## Side : All
+# Ref     Val       Package                PosX       PosY       Rot  Side
+C1        100n      C_0603                101.5000   -52.2500   90.0000  top
+J1        USB_C     USB_C_Receptacle      120.0000   -60.0000  180.0000  bottom
+## End
+`
+	expected := []*Placement{
+		{
+			Ref:      "C1",
+			Value:    "100n",
+			Package:  "C_0603",
+			Position: [2]float64{101.5, -52.25},
+			Rotation: 90,
+		},
+		{
+			Ref:      "J1",
+			Value:    "USB_C",
+			Package:  "USB_C_Receptacle",
+			Position: [2]float64{120, -60},
+			Rotation: 180,
+			Bottom:   true,
+		},
+	}
+	for _, data := range []string{csvData, asciiData} {
+		actual, err := ReadPlacements(strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected %v but got %v", expected, actual)
+		}
+	}
+}
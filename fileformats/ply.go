@@ -22,21 +22,61 @@ type PLYWriter struct {
 	numCoords int
 	numTris   int
 
+	props PLYVertexProperties
+
 	writtenCoords int
 	writtenTris   int
 
 	builder strings.Builder
 }
 
+// PLYVertexProperties specifies which optional properties
+// are stored for each vertex of a PLY file, in addition to
+// the coordinates and colors.
+type PLYVertexProperties struct {
+	// Normals adds nx, ny, and nz properties.
+	Normals bool
+
+	// UVs adds s and t texture coordinate properties.
+	UVs bool
+}
+
+// A PLYVertex is a vertex in a PLY file.
+//
+// The Normal and UV fields are only written if the
+// corresponding PLYVertexProperties are enabled.
+type PLYVertex struct {
+	Coord  [3]float64
+	Color  [3]uint8
+	Normal [3]float64
+	UV     [2]float64
+}
+
 // NewPLYWriter creates a new PLYWriter and writes the
 // file header.
 func NewPLYWriter(w io.Writer, numCoords, numTris int) (*PLYWriter, error) {
+	return NewPLYWriterProperties(w, numCoords, numTris, PLYVertexProperties{})
+}
+
+// NewPLYWriterProperties is like NewPLYWriter, but it
+// includes extra vertex properties in the file.
+func NewPLYWriterProperties(w io.Writer, numCoords, numTris int,
+	props PLYVertexProperties) (*PLYWriter, error) {
 	var header strings.Builder
 	header.WriteString("ply\nformat ascii 1.0\n")
 	header.WriteString(fmt.Sprintf("element vertex %d\n", numCoords))
 	header.WriteString("property float x\n")
 	header.WriteString("property float y\n")
 	header.WriteString("property float z\n")
+	if props.Normals {
+		header.WriteString("property float nx\n")
+		header.WriteString("property float ny\n")
+		header.WriteString("property float nz\n")
+	}
+	if props.UVs {
+		header.WriteString("property float s\n")
+		header.WriteString("property float t\n")
+	}
 	header.WriteString("property uchar red\n")
 	header.WriteString("property uchar green\n")
 	header.WriteString("property uchar blue\n")
@@ -57,20 +97,35 @@ func NewPLYWriter(w io.Writer, numCoords, numTris int) (*PLYWriter, error) {
 		w:         bw,
 		numCoords: numCoords,
 		numTris:   numTris,
+		props:     props,
 	}, nil
 }
 
 // WriteCoord writes the next coordinate to the file.
 //
 // This should be called exactly numCoords times.
-func (p *PLYWriter) WriteCoord(c [3]float64, color [3]uint8) (err error) {
+func (p *PLYWriter) WriteCoord(c [3]float64, color [3]uint8) error {
+	return p.WriteVertex(&PLYVertex{Coord: c, Color: color})
+}
+
+// WriteVertex is like WriteCoord, but it includes the
+// extra properties of the vertex.
+func (p *PLYWriter) WriteVertex(v *PLYVertex) (err error) {
 	defer essentials.AddCtxTo("write PLY", &err)
 	if p.writtenTris > 0 || p.writtenCoords >= p.numCoords {
 		return errors.New("cannot write another coordinate")
 	}
-	coordLine := fmt.Sprintf("%f %f %f %d %d %d\n", c[0], c[1], c[2],
-		int(color[0]), int(color[1]), int(color[2]))
-	_, err = p.w.WriteString(coordLine)
+	p.builder.Reset()
+	p.builder.WriteString(fmt.Sprintf("%f %f %f", v.Coord[0], v.Coord[1], v.Coord[2]))
+	if p.props.Normals {
+		p.builder.WriteString(fmt.Sprintf(" %f %f %f", v.Normal[0], v.Normal[1], v.Normal[2]))
+	}
+	if p.props.UVs {
+		p.builder.WriteString(fmt.Sprintf(" %f %f", v.UV[0], v.UV[1]))
+	}
+	p.builder.WriteString(fmt.Sprintf(" %d %d %d\n", int(v.Color[0]), int(v.Color[1]),
+		int(v.Color[2])))
+	_, err = p.w.WriteString(p.builder.String())
 	p.writtenCoords++
 	return
 }
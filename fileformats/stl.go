@@ -1,9 +1,12 @@
 package fileformats
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
 	"io"
 	"math"
+	"strconv"
 
 	"github.com/pkg/errors"
 )
@@ -13,6 +16,12 @@ type STLWriter struct {
 	w        io.Writer
 	trisLeft uint32
 
+	// Fields for NewSTLStreamWriter.
+	stream  io.WriteSeeker
+	bw      *bufio.Writer
+	start   int64
+	written uint32
+
 	buffer [12]float32
 }
 
@@ -29,15 +38,39 @@ func NewSTLWriter(w io.Writer, numTris uint32) (*STLWriter, error) {
 	return &STLWriter{w: w, trisLeft: numTris}, nil
 }
 
+// NewSTLStreamWriter creates an STLWriter for an unknown
+// number of triangles.
+//
+// The header is written with a placeholder triangle count,
+// and Close must be called after the last triangle to seek
+// back and fill in the actual count.
+func NewSTLStreamWriter(w io.WriteSeeker) (*STLWriter, error) {
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, errors.Wrap(err, "write STL header")
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(make([]byte, 84)); err != nil {
+		return nil, errors.Wrap(err, "write STL header")
+	}
+	return &STLWriter{w: bw, stream: w, bw: bw, start: start}, nil
+}
+
 // WriteTriangle writes a triangle to the file.
 //
-// This should be called exactly the number of times passed
-// to NewSTLWriter.
+// For writers from NewSTLWriter, this should be called
+// exactly the number of times passed to NewSTLWriter.
 func (s *STLWriter) WriteTriangle(normal [3]float32, faces [3][3]float32) error {
-	if s.trisLeft == 0 {
+	if s.stream != nil {
+		if s.written == math.MaxUint32 {
+			return errors.New("write STL triangle: too many triangles written")
+		}
+		s.written++
+	} else if s.trisLeft == 0 {
 		return errors.New("write STL triangle: too many triangles written")
+	} else {
+		s.trisLeft -= 1
 	}
-	s.trisLeft -= 1
 	copy(s.buffer[0:3], normal[:])
 	copy(s.buffer[3:6], faces[0][:])
 	copy(s.buffer[6:9], faces[1][:])
@@ -52,6 +85,33 @@ func (s *STLWriter) WriteTriangle(normal [3]float32, faces [3][3]float32) error
 	return nil
 }
 
+// Close finishes a file from NewSTLStreamWriter by
+// writing the number of triangles into the header.
+//
+// For writers from NewSTLWriter, this does nothing.
+func (s *STLWriter) Close() error {
+	if s.stream == nil {
+		return nil
+	}
+	if err := s.bw.Flush(); err != nil {
+		return errors.Wrap(err, "close STL writer")
+	}
+	end, err := s.stream.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return errors.Wrap(err, "close STL writer")
+	}
+	if _, err := s.stream.Seek(s.start+80, io.SeekStart); err != nil {
+		return errors.Wrap(err, "close STL writer")
+	}
+	if err := binary.Write(s.stream, binary.LittleEndian, s.written); err != nil {
+		return errors.Wrap(err, "close STL writer")
+	}
+	if _, err := s.stream.Seek(end, io.SeekStart); err != nil {
+		return errors.Wrap(err, "close STL writer")
+	}
+	return nil
+}
+
 // An STLReader reads STL files.
 type STLReader struct {
 	r       io.Reader
@@ -98,3 +158,111 @@ func (s *STLReader) ReadTriangle() (normal [3]float32, vertices [3][3]float32, e
 	}
 	return
 }
+
+// An ASCIISTLReader reads STL files in the ASCII format.
+type ASCIISTLReader struct {
+	scanner *bufio.Scanner
+	done    bool
+}
+
+// NewASCIISTLReader creates an ASCII STL reader by reading
+// the "solid" line at the start of the file.
+func NewASCIISTLReader(r io.Reader) (*ASCIISTLReader, error) {
+	br := bufio.NewReader(r)
+	line, err := br.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return nil, errors.Wrap(err, "read ASCII STL header")
+	}
+	if fields := bytes.Fields([]byte(line)); len(fields) == 0 || string(fields[0]) != "solid" {
+		return nil, errors.New("read ASCII STL header: missing solid keyword")
+	}
+	scanner := bufio.NewScanner(br)
+	scanner.Split(bufio.ScanWords)
+	return &ASCIISTLReader{scanner: scanner}, nil
+}
+
+// ReadTriangle reads the next triangle from the file.
+//
+// After the last triangle, io.EOF is returned.
+func (a *ASCIISTLReader) ReadTriangle() (normal [3]float32, vertices [3][3]float32, err error) {
+	if a.done {
+		err = io.EOF
+		return
+	}
+	defer func() {
+		if err != nil && err != io.EOF {
+			err = errors.Wrap(err, "read ASCII STL triangle")
+		}
+	}()
+
+	word, err := a.nextWord()
+	if err != nil {
+		return
+	}
+	if word == "endsolid" {
+		a.done = true
+		err = io.EOF
+		return
+	} else if word != "facet" {
+		err = errors.New("unexpected token: " + word)
+		return
+	}
+	if err = a.expect("normal"); err != nil {
+		return
+	}
+	if normal, err = a.readVector(); err != nil {
+		return
+	}
+	if err = a.expect("outer", "loop"); err != nil {
+		return
+	}
+	for i := range vertices {
+		if err = a.expect("vertex"); err != nil {
+			return
+		}
+		if vertices[i], err = a.readVector(); err != nil {
+			return
+		}
+	}
+	err = a.expect("endloop", "endfacet")
+	return
+}
+
+func (a *ASCIISTLReader) nextWord() (string, error) {
+	if !a.scanner.Scan() {
+		if err := a.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.ErrUnexpectedEOF
+	}
+	return a.scanner.Text(), nil
+}
+
+func (a *ASCIISTLReader) expect(words ...string) error {
+	for _, expected := range words {
+		word, err := a.nextWord()
+		if err != nil {
+			return err
+		}
+		if word != expected {
+			return errors.New("expected " + expected + " but got " + word)
+		}
+	}
+	return nil
+}
+
+func (a *ASCIISTLReader) readVector() ([3]float32, error) {
+	var res [3]float32
+	for i := range res {
+		word, err := a.nextWord()
+		if err != nil {
+			return res, err
+		}
+		x, err := strconv.ParseFloat(word, 32)
+		if err != nil {
+			return res, err
+		}
+		res[i] = float32(x)
+	}
+	return res, nil
+}
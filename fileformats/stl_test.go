@@ -2,8 +2,11 @@ package fileformats
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
 	"math"
 	"math/rand"
+	"os"
 	"testing"
 )
 
@@ -53,3 +56,104 @@ func TestSTL(t *testing.T) {
 		}
 	}
 }
+
+func TestSTLStreamWriter(t *testing.T) {
+	f, err := ioutil.TempFile("", "stl_stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	// Data before the file should not be overwritten.
+	prefix := []byte("prefix")
+	if _, err := f.Write(prefix); err != nil {
+		t.Fatal(err)
+	}
+	writer, err := NewSTLStreamWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		vertex := [3]float32{float32(i), 0, 0}
+		if err := writer.WriteTriangle([3]float32{}, [3][3]float32{vertex, vertex, vertex}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, prefix) {
+		t.Fatal("prefix was modified")
+	}
+	reader, err := NewSTLReader(bytes.NewReader(data[len(prefix):]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.NumTriangles() != 10 {
+		t.Fatalf("unexpected triangle count: %d", reader.NumTriangles())
+	}
+	for i := 0; i < 10; i++ {
+		_, vertices, err := reader.ReadTriangle()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vertices[0][0] != float32(i) {
+			t.Errorf("unexpected vertex %d: %v", i, vertices[0])
+		}
+	}
+}
+
+func TestASCIISTL(t *testing.T) {
+	data := `solid my model
+  facet normal 0 0 1
+    outer loop
+      vertex 0 0 0
+      vertex 1 0 0
+      vertex 0 1.5e0 0
+    endloop
+  endfacet
+  facet normal 0 0 -1
+    outer loop
+      vertex 0 0 0
+      vertex 0 1 0
+      vertex 1 0 -2.5
+    endloop
+  endfacet
+endsolid my model
+`
+	reader, err := NewASCIISTLReader(bytes.NewReader([]byte(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][4][3]float32{
+		{{0, 0, 1}, {0, 0, 0}, {1, 0, 0}, {0, 1.5, 0}},
+		{{0, 0, -1}, {0, 0, 0}, {0, 1, 0}, {1, 0, -2.5}},
+	}
+	for i, x := range expected {
+		normal, vertices, err := reader.ReadTriangle()
+		if err != nil {
+			t.Fatal(err)
+		}
+		y := [4][3]float32{normal, vertices[0], vertices[1], vertices[2]}
+		if x != y {
+			t.Errorf("triangle %d: expected %v but got %v", i, x, y)
+		}
+	}
+	if _, _, err := reader.ReadTriangle(); err != io.EOF {
+		t.Errorf("expected EOF but got %v", err)
+	}
+
+	reader, err = NewASCIISTLReader(bytes.NewReader([]byte("solid\nfacet normal 0 0 1\nouter")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := reader.ReadTriangle(); err == nil || err == io.EOF {
+		t.Errorf("expected parse error but got %v", err)
+	}
+}
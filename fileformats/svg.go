@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -47,26 +48,40 @@ func (s *SVGWriter) WritePoly(points [][2]float64, attrs map[string]string) erro
 	line += strings.Join(pointStrs, " ")
 	line += `"`
 
-	var attrStrings []string
-	for attribute, value := range attrs {
-		var encodedString bytes.Buffer
-		if err := xml.EscapeText(&encodedString, []byte(value)); err != nil {
-			return errors.Wrap(err, "write SVG polygon")
-		}
-		attrStrings = append(attrStrings, fmt.Sprintf("%s=\"%s\"", attribute, string(encodedString.Bytes())))
+	attrStr, err := encodeSVGAttrs(attrs)
+	if err != nil {
+		return errors.Wrap(err, "write SVG polygon")
 	}
-	line += " " + strings.Join(attrStrings, " ")
+	line += " " + attrStr
 	if len(attrs) != 1 {
 		line += " "
 	}
 	line += "/>"
-	_, err := s.w.Write([]byte(line))
+	_, err = s.w.Write([]byte(line))
 	if err != nil {
 		return errors.Wrap(err, "write SVG polygon")
 	}
 	return nil
 }
 
+// WriteText writes a text element anchored at the point
+// (x, y).
+func (s *SVGWriter) WriteText(x, y float64, text string, attrs map[string]string) error {
+	attrStr, err := encodeSVGAttrs(attrs)
+	if err != nil {
+		return errors.Wrap(err, "write SVG text")
+	}
+	var encodedText bytes.Buffer
+	if err := xml.EscapeText(&encodedText, []byte(text)); err != nil {
+		return errors.Wrap(err, "write SVG text")
+	}
+	line := fmt.Sprintf(`<text x="%f" y="%f" %s>%s</text>`, x, y, attrStr, encodedText.String())
+	if _, err := s.w.Write([]byte(line)); err != nil {
+		return errors.Wrap(err, "write SVG text")
+	}
+	return nil
+}
+
 // WriteEnd writes any necessary footer information.
 func (s *SVGWriter) WriteEnd() error {
 	_, err := s.w.Write([]byte("</svg>"))
@@ -75,3 +90,21 @@ func (s *SVGWriter) WriteEnd() error {
 	}
 	return nil
 }
+
+func encodeSVGAttrs(attrs map[string]string) (string, error) {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrStrings := make([]string, len(keys))
+	for i, attribute := range keys {
+		var encodedString bytes.Buffer
+		if err := xml.EscapeText(&encodedString, []byte(attrs[attribute])); err != nil {
+			return "", err
+		}
+		attrStrings[i] = fmt.Sprintf("%s=\"%s\"", attribute, encodedString.String())
+	}
+	return strings.Join(attrStrings, " "), nil
+}
@@ -0,0 +1,257 @@
+package fileformats
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+)
+
+const (
+	threeMFModelPath   = "3D/3dmodel.model"
+	threeMFNamespace   = "http://schemas.microsoft.com/3dmanufacturing/core/2015/02"
+	threeMFModelType   = "application/vnd.ms-package.3dmanufacturing-3dmodel+xml"
+	threeMFRelType     = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+	threeMFContentType = `<?xml version="1.0" encoding="UTF-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+ <Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+ <Default Extension="model" ContentType="` + threeMFModelType + `"/>
+</Types>
+`
+	threeMFRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+ <Relationship Target="/` + threeMFModelPath + `" Id="rel0" Type="` + threeMFRelType + `"/>
+</Relationships>
+`
+)
+
+// A ThreeMFObject is a single mesh in a 3MF file.
+type ThreeMFObject struct {
+	Name string
+
+	Vertices [][3]float64
+
+	// Triangles contains vertex indices, starting at 0.
+	Triangles [][3]int
+}
+
+// A ThreeMFFile represents the contents of a 3D
+// manufacturing format (3MF) package.
+//
+// Every object is added to the build, so that all of the
+// objects are printed.
+type ThreeMFFile struct {
+	// Unit is the unit of the coordinates, such as
+	// "millimeter" or "inch".
+	// If it is "", millimeters are implied.
+	Unit string
+
+	// Metadata maps names, such as "Title", to values.
+	Metadata map[string]string
+
+	Objects []*ThreeMFObject
+}
+
+// Write encodes the file as a zip archive.
+func (t *ThreeMFFile) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		Name string
+		Data []byte
+	}{
+		{"[Content_Types].xml", []byte(threeMFContentType)},
+		{"_rels/.rels", []byte(threeMFRels)},
+	}
+	model, err := t.encodeModel()
+	if err != nil {
+		return err
+	}
+	files = append(files, struct {
+		Name string
+		Data []byte
+	}{threeMFModelPath, model})
+	for _, f := range files {
+		fw, err := zw.Create(f.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (t *ThreeMFFile) encodeModel() ([]byte, error) {
+	unit := t.Unit
+	if unit == "" {
+		unit = "millimeter"
+	}
+	model := &threeMFXMLModel{
+		Unit:  unit,
+		XMLNS: threeMFNamespace,
+	}
+	for name, value := range t.Metadata {
+		model.Metadata = append(model.Metadata, threeMFXMLMetadata{Name: name, Value: value})
+	}
+	essentials.VoodooSort(model.Metadata, func(i, j int) bool {
+		return model.Metadata[i].Name < model.Metadata[j].Name
+	})
+	format := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	for i, obj := range t.Objects {
+		id := strconv.Itoa(i + 1)
+		xmlObj := threeMFXMLObject{ID: id, Name: obj.Name, Type: "model"}
+		for _, v := range obj.Vertices {
+			xmlObj.Mesh.Vertices = append(xmlObj.Mesh.Vertices, threeMFXMLVertex{
+				X: format(v[0]),
+				Y: format(v[1]),
+				Z: format(v[2]),
+			})
+		}
+		for _, tri := range obj.Triangles {
+			for _, idx := range tri {
+				if idx < 0 || idx >= len(obj.Vertices) {
+					return nil, errors.New("write 3MF: vertex index out of range")
+				}
+			}
+			xmlObj.Mesh.Triangles = append(xmlObj.Mesh.Triangles, threeMFXMLTriangle{
+				V1: tri[0],
+				V2: tri[1],
+				V3: tri[2],
+			})
+		}
+		model.Objects = append(model.Objects, xmlObj)
+		model.Items = append(model.Items, threeMFXMLItem{ObjectID: id})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", " ")
+	if err := enc.Encode(model); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// Read3MF decodes a 3MF file.
+//
+// Only mesh objects are supported; components and build
+// item transforms are ignored.
+func Read3MF(r io.Reader) (t *ThreeMFFile, err error) {
+	defer essentials.AddCtxTo("read 3MF", &err)
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var modelData []byte
+	for _, f := range zr.File {
+		if f.Name != threeMFModelPath {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		modelData, err = ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if modelData == nil {
+		return nil, errors.New("missing model file")
+	}
+
+	var model threeMFXMLModel
+	if err := xml.Unmarshal(modelData, &model); err != nil {
+		return nil, err
+	}
+	res := &ThreeMFFile{Unit: model.Unit}
+	if len(model.Metadata) > 0 {
+		res.Metadata = map[string]string{}
+		for _, m := range model.Metadata {
+			res.Metadata[m.Name] = strings.TrimSpace(m.Value)
+		}
+	}
+	for _, xmlObj := range model.Objects {
+		obj := &ThreeMFObject{Name: xmlObj.Name}
+		for _, v := range xmlObj.Mesh.Vertices {
+			var coord [3]float64
+			for i, s := range []string{v.X, v.Y, v.Z} {
+				coord[i], err = strconv.ParseFloat(strings.TrimSpace(s), 64)
+				if err != nil {
+					return nil, errors.Wrap(err, "parse vertex")
+				}
+			}
+			obj.Vertices = append(obj.Vertices, coord)
+		}
+		for _, tri := range xmlObj.Mesh.Triangles {
+			indices := [3]int{tri.V1, tri.V2, tri.V3}
+			for _, idx := range indices {
+				if idx < 0 || idx >= len(obj.Vertices) {
+					return nil, errors.New("vertex index out of range")
+				}
+			}
+			obj.Triangles = append(obj.Triangles, indices)
+		}
+		res.Objects = append(res.Objects, obj)
+	}
+	return res, nil
+}
+
+type threeMFXMLModel struct {
+	XMLName  xml.Name             `xml:"model"`
+	Unit     string               `xml:"unit,attr"`
+	XMLNS    string               `xml:"xmlns,attr,omitempty"`
+	Metadata []threeMFXMLMetadata `xml:"metadata"`
+	Objects  []threeMFXMLObject   `xml:"resources>object"`
+	Items    []threeMFXMLItem     `xml:"build>item"`
+}
+
+type threeMFXMLMetadata struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type threeMFXMLObject struct {
+	ID   string         `xml:"id,attr"`
+	Name string         `xml:"name,attr,omitempty"`
+	Type string         `xml:"type,attr,omitempty"`
+	Mesh threeMFXMLMesh `xml:"mesh"`
+}
+
+type threeMFXMLMesh struct {
+	Vertices  []threeMFXMLVertex   `xml:"vertices>vertex"`
+	Triangles []threeMFXMLTriangle `xml:"triangles>triangle"`
+}
+
+type threeMFXMLVertex struct {
+	X string `xml:"x,attr"`
+	Y string `xml:"y,attr"`
+	Z string `xml:"z,attr"`
+}
+
+type threeMFXMLTriangle struct {
+	V1 int `xml:"v1,attr"`
+	V2 int `xml:"v2,attr"`
+	V3 int `xml:"v3,attr"`
+}
+
+type threeMFXMLItem struct {
+	ObjectID string `xml:"objectid,attr"`
+}
@@ -0,0 +1,75 @@
+package fileformats
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestThreeMFFileRoundTrip(t *testing.T) {
+	expected := &ThreeMFFile{
+		Unit:     "inch",
+		Metadata: map[string]string{"Title": "Wedges", "Designer": "Test"},
+		Objects: []*ThreeMFObject{
+			{
+				Name:      "Wedge",
+				Vertices:  [][3]float64{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1.5}},
+				Triangles: [][3]int{{0, 2, 1}, {0, 1, 3}, {0, 3, 2}, {1, 2, 3}},
+			},
+			{
+				Vertices:  [][3]float64{{0, 0, 0}, {-1, 0, 0}, {0, 1, 0}},
+				Triangles: [][3]int{{0, 1, 2}},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := expected.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == "[Content_Types].xml" {
+			rc, _ := f.Open()
+			data, _ := ioutil.ReadAll(rc)
+			rc.Close()
+			if !strings.Contains(string(data), "3dmanufacturing-3dmodel+xml") {
+				t.Error("missing model content type")
+			}
+		}
+	}
+	expectedNames := []string{"[Content_Types].xml", "_rels/.rels", "3D/3dmodel.model"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("unexpected files: %v", names)
+	}
+
+	actual, err := Read3MF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v but got %#v", expected, actual)
+	}
+}
+
+func TestThreeMFFileBadIndex(t *testing.T) {
+	f := &ThreeMFFile{
+		Objects: []*ThreeMFObject{
+			{
+				Vertices:  [][3]float64{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+				Triangles: [][3]int{{0, 1, 3}},
+			},
+		},
+	}
+	if err := f.Write(ioutil.Discard); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -3,8 +3,12 @@ package fileformats
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
 	"strconv"
+	"strings"
+
+	"github.com/unixpickle/essentials"
 )
 
 // An OBJFileFaceGroup is a group of faces with one
@@ -98,6 +102,94 @@ func (o *OBJFile) encodeFace(coords [3][3]int) string {
 	return res + "\n"
 }
 
+// An OBJReader reads the polygonal faces of a Wavefront obj
+// file.
+//
+// Only vertex positions, faces, and material names are
+// decoded. Texture coordinates, normals, and other
+// statements are ignored.
+type OBJReader struct {
+	r        *bufio.Reader
+	lineIdx  int
+	material string
+
+	vertices [][3]float64
+}
+
+// NewOBJReader creates an OBJReader that reads from r.
+func NewOBJReader(r io.Reader) *OBJReader {
+	return &OBJReader{r: bufio.NewReader(r)}
+}
+
+// ReadFace reads the next face, returning the positions of
+// its vertices and the name of its material, which is ""
+// if no material was set.
+//
+// Faces may only reference vertices which come before them
+// in the file.
+//
+// If no more faces exist to be read, io.EOF is returned
+// as the error.
+func (o *OBJReader) ReadFace() (face [][3]float64, material string, err error) {
+	defer func() {
+		if err != io.EOF {
+			err = essentials.AddCtx("read OBJ face", err)
+		}
+	}()
+	for {
+		line, err := o.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, "", err
+		}
+		o.lineIdx++
+		if idx := strings.IndexByte(line, '#'); idx != -1 {
+			line = line[:idx]
+		}
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
+		switch parts[0] {
+		case "v":
+			if len(parts) < 4 {
+				return nil, "", fmt.Errorf("line %d: unexpected number of tokens", o.lineIdx)
+			}
+			var vertex [3]float64
+			for i, part := range parts[1:4] {
+				vertex[i], err = strconv.ParseFloat(part, 64)
+				if err != nil {
+					return nil, "", fmt.Errorf("line %d: invalid vector component", o.lineIdx)
+				}
+			}
+			o.vertices = append(o.vertices, vertex)
+		case "usemtl":
+			o.material = strings.Join(parts[1:], " ")
+		case "f":
+			if len(parts) < 4 {
+				return nil, "", fmt.Errorf("line %d: face has fewer than three vertices", o.lineIdx)
+			}
+			face := make([][3]float64, len(parts)-1)
+			for i, part := range parts[1:] {
+				// Only the position index matters, as in 1/2/3,
+				// 1//3, or 1/2.
+				if slash := strings.IndexByte(part, '/'); slash != -1 {
+					part = part[:slash]
+				}
+				idx, err := strconv.Atoi(part)
+				if err == nil && idx < 0 {
+					// Negative indices are relative to the end.
+					idx += len(o.vertices) + 1
+				}
+				if err != nil || idx < 1 || idx > len(o.vertices) {
+					return nil, "", fmt.Errorf("line %d: invalid vertex index", o.lineIdx)
+				}
+				face[i] = o.vertices[idx-1]
+			}
+			return face, o.material, nil
+		}
+	}
+}
+
 // MTLFileTextureMap is a configured texture map for an
 // MTLFileMaterial.
 type MTLFileTextureMap struct {
@@ -0,0 +1,50 @@
+package fileformats
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestOBJReader(t *testing.T) {
+	data := `# A square and a triangle.
+mtllib material.mtl
+v 0 0 0
+v 1 0 0
+v 1 1 0 0.5 0.5 0.5
+v 0 1 0
+vt 0 0
+vn 0 0 1
+usemtl red
+f 1/1/1 2/1/1 3/1/1 4/1/1
+usemtl blue
+f -4//1 -2//1 -1//1 # relative indices
+`
+	reader := NewOBJReader(bytes.NewReader([]byte(data)))
+	expectedFaces := [][][3]float64{
+		{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+		{{0, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+	}
+	expectedMaterials := []string{"red", "blue"}
+	for i, expected := range expectedFaces {
+		face, material, err := reader.ReadFace()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(face, expected) {
+			t.Errorf("face %d: expected %v but got %v", i, expected, face)
+		}
+		if material != expectedMaterials[i] {
+			t.Errorf("face %d: expected material %s but got %s", i, expectedMaterials[i], material)
+		}
+	}
+	if _, _, err := reader.ReadFace(); err != io.EOF {
+		t.Errorf("expected EOF but got %v", err)
+	}
+
+	reader = NewOBJReader(bytes.NewReader([]byte("v 0 0 0\nf 1 2 3\n")))
+	if _, _, err := reader.ReadFace(); err == nil || err == io.EOF {
+		t.Errorf("expected index error but got %v", err)
+	}
+}
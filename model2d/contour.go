@@ -0,0 +1,172 @@
+package model2d
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const contourSearchIters = 8
+
+var (
+	contourPositiveColor = [3]float64{101.0 / 255.0, 188.0 / 255.0, 212.0 / 255.0}
+	contourNegativeColor = [3]float64{252.0 / 255.0, 121.0 / 255.0, 121.0 / 255.0}
+)
+
+// Contours computes the iso-contours of a scalar function
+// within a bounding box, returning a mesh for each of the
+// given levels.
+//
+// Each mesh traces the boundary of the region where f is
+// greater than the level, and is oriented like the mesh
+// of a solid for that region.
+// Contours which reach the edge of the bounding box are
+// left open rather than following the edge.
+//
+// The delta argument is the grid spacing used to trace
+// the contours.
+// The function f is only evaluated within the bounding box.
+//
+// To plot the contours of an SDF s, use
+// Contours(s, s.SDF, levels, delta).
+func Contours(b Bounder, f func(c Coord) float64, levels []float64, delta float64) []*Mesh {
+	min, max := b.Min(), b.Max()
+
+	// Extend the field past the bounds so that contours
+	// cross the edges of the bounds, and then remove the
+	// extra segments afterwards.
+	pad := XY(delta, delta)
+	res := make([]*Mesh, len(levels))
+	for i, level := range levels {
+		level := level
+		solid := FuncSolid(min.Sub(pad.Scale(2)), max.Add(pad.Scale(2)), func(c Coord) bool {
+			if !InBounds(NewRect(min.Sub(pad), max.Add(pad)), c) {
+				return false
+			}
+			return f(c.Max(min).Min(max)) > level
+		})
+		mesh := MarchingSquaresSearch(solid, delta, contourSearchIters)
+		mesh.Iterate(func(s *Segment) {
+			mid := s.Mid()
+			if mid.X < min.X-delta/4 || mid.Y < min.Y-delta/4 || mid.X > max.X+delta/4 ||
+				mid.Y > max.Y+delta/4 {
+				mesh.Remove(s)
+			}
+		})
+		res[i] = mesh
+	}
+	return res
+}
+
+// SaveContours plots the iso-contours of a scalar function
+// within a bounding box and saves the plot to an SVG, PNG,
+// or JPEG file.
+//
+// Contours for negative levels are drawn in red, positive
+// levels in blue, and the zero level in black.
+// Raster images also shade the region where f is
+// positive, which is the inside of an SDF.
+//
+// The scale determines how many pixels comprise a unit
+// distance, as in Rasterizer, and the contours are traced
+// at a resolution of one pixel.
+func SaveContours(path string, b Bounder, f func(c Coord) float64, levels []float64,
+	scale float64) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".svg" && ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+		return fmt.Errorf("save contours: unknown extension '%s'", ext)
+	}
+
+	meshes := Contours(b, f, levels, 1/scale)
+	colors := make([][3]float64, len(levels))
+	maxAbs := 0.0
+	for _, level := range levels {
+		maxAbs = math.Max(maxAbs, math.Abs(level))
+	}
+	for i, level := range levels {
+		colors[i] = contourColor(level, maxAbs)
+	}
+
+	var err error
+	if ext == ".svg" {
+		err = saveContoursSVG(path, b, meshes, colors, scale)
+	} else {
+		err = SaveImage(path, rasterizeContours(b, f, meshes, colors, scale))
+	}
+	if err != nil {
+		return errors.Wrap(err, "save contours")
+	}
+	return nil
+}
+
+func saveContoursSVG(path string, b Bounder, meshes []*Mesh, colors [][3]float64,
+	scale float64) error {
+	colorStrs := make([]string, len(meshes))
+	thicknesses := make([]float64, len(meshes))
+	for i, c := range colors {
+		colorStrs[i] = fmt.Sprintf("rgb(%d,%d,%d)", int(c[0]*255), int(c[1]*255), int(c[2]*255))
+		thicknesses[i] = RasterizerDefaultLineWidth / scale
+	}
+	return saveToFile(path, func(w io.Writer) error {
+		return WriteCustomSVG(w, meshes, colorStrs, thicknesses, b)
+	})
+}
+
+func rasterizeContours(b Bounder, f func(c Coord) float64, meshes []*Mesh,
+	colors [][3]float64, scale float64) image.Image {
+	min, max := b.Min(), b.Max()
+	rast := &Rasterizer{Scale: scale, Bounds: b}
+	background := NewRect(min, max)
+	positive := CheckedFuncSolid(min, max, func(c Coord) bool {
+		return f(c) > 0
+	})
+	imgs := []*image.Gray{rast.Rasterize(background), rast.Rasterize(positive)}
+	imgColors := []color.Color{color.White, contourRGBA(contourShade(contourPositiveColor))}
+	for i, m := range meshes {
+		imgs = append(imgs, rast.Rasterize(m))
+		imgColors = append(imgColors, contourRGBA(colors[i]))
+	}
+	return ColorizeOverlay(imgs, imgColors)
+}
+
+// contourColor picks a color for a contour level, where
+// levels further from zero are more saturated.
+func contourColor(level, maxAbs float64) [3]float64 {
+	if level == 0 {
+		return [3]float64{}
+	}
+	target := contourPositiveColor
+	if level < 0 {
+		target = contourNegativeColor
+	}
+	frac := 0.5 + 0.5*math.Abs(level)/maxAbs
+	var res [3]float64
+	for i, c := range target {
+		res[i] = (1-frac)*0.3 + frac*c
+	}
+	return res
+}
+
+// contourShade lightens a color for use as a background.
+func contourShade(c [3]float64) [3]float64 {
+	var res [3]float64
+	for i, x := range c {
+		res[i] = 0.75 + 0.25*x
+	}
+	return res
+}
+
+func contourRGBA(c [3]float64) color.Color {
+	return color.RGBA{
+		R: uint8(c[0] * 255.999),
+		G: uint8(c[1] * 255.999),
+		B: uint8(c[2] * 255.999),
+		A: 0xff,
+	}
+}
@@ -0,0 +1,69 @@
+package model2d
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestContours(t *testing.T) {
+	sdf := MeshToSDF(NewMeshPolar(func(theta float64) float64 {
+		return 1
+	}, 200))
+	levels := []float64{-0.5, 0, 0.5}
+	meshes := Contours(NewRect(XY(-2, -2), XY(2, 2)), sdf.SDF, levels, 0.05)
+	for i, level := range levels {
+		mesh := meshes[i]
+		if len(mesh.SegmentSlice()) == 0 {
+			t.Fatalf("level %f: empty contour", level)
+		}
+		if !mesh.Manifold() {
+			t.Errorf("level %f: contour is not closed", level)
+		}
+		for _, v := range mesh.VertexSlice() {
+			if math.Abs(v.Norm()-(1-level)) > 1e-2 {
+				t.Fatalf("level %f: unexpected vertex %v", level, v)
+			}
+		}
+	}
+
+	// Contours leaving the bounds should be open.
+	f := func(c Coord) float64 {
+		return c.X
+	}
+	mesh := Contours(NewRect(XY(-1, -1), XY(1, 1)), f, []float64{0.5}, 0.1)[0]
+	for _, v := range mesh.VertexSlice() {
+		if math.Abs(v.X-0.5) > 1e-3 {
+			t.Fatalf("unexpected vertex %v", v)
+		}
+	}
+}
+
+func TestSaveContours(t *testing.T) {
+	dir, err := ioutil.TempDir("", "model2d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	circle := func(c Coord) float64 {
+		return 1 - c.Norm()
+	}
+	bounds := NewRect(XY(-2, -2), XY(2, 2))
+	levels := []float64{-0.5, 0, 0.5}
+	for _, name := range []string{"contours.svg", "contours.png"} {
+		path := filepath.Join(dir, name)
+		if err := SaveContours(path, bounds, circle, levels, 20); err != nil {
+			t.Fatal(err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("missing output for %s", name)
+		}
+	}
+	if err := SaveContours(filepath.Join(dir, "contours.txt"), bounds, circle,
+		levels, 20); err == nil {
+		t.Error("expected error for unknown extension")
+	}
+}
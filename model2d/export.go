@@ -3,14 +3,28 @@ package model2d
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/unixpickle/model3d/fileformats"
 )
 
 // EncodeCSV encodes the mesh as a CSV file.
 func EncodeCSV(m *Mesh) []byte {
+	return []byte(strings.Join(csvLines(m), "\n"))
+}
+
+// WriteCSV writes the mesh to w as a CSV file.
+func WriteCSV(w io.Writer, m *Mesh) error {
+	if _, err := io.WriteString(w, strings.Join(csvLines(m), "\n")); err != nil {
+		return errors.Wrap(err, "write CSV")
+	}
+	return nil
+}
+
+func csvLines(m *Mesh) []string {
 	var lines []string
 	m.Iterate(func(s *Segment) {
 		line := ""
@@ -22,7 +36,7 @@ func EncodeCSV(m *Mesh) []byte {
 		}
 		lines = append(lines, line)
 	})
-	return []byte(strings.Join(lines, "\n"))
+	return lines
 }
 
 // EncodeSVG encodes the mesh as an SVG file.
@@ -30,6 +44,11 @@ func EncodeSVG(m *Mesh) []byte {
 	return EncodeCustomSVG([]*Mesh{m}, []string{"black"}, []float64{1.0}, nil)
 }
 
+// WriteSVG writes the mesh to w as an SVG file.
+func WriteSVG(w io.Writer, m *Mesh) error {
+	return WriteCustomSVG(w, []*Mesh{m}, []string{"black"}, []float64{1.0}, nil)
+}
+
 // EncodeCustomSVG encodes multiple meshes, each with a
 // different color and line thickness.
 //
@@ -37,6 +56,26 @@ func EncodeSVG(m *Mesh) []byte {
 // resulting bounds of the SVG.
 // Otherwise, the union of all meshes is used.
 func EncodeCustomSVG(meshes []*Mesh, colors []string, thicknesses []float64, bounds Bounder) []byte {
+	var result bytes.Buffer
+	if err := WriteCustomSVG(&result, meshes, colors, thicknesses, bounds); err != nil {
+		// Writing to a buffer never fails.
+		panic(err)
+	}
+	return result.Bytes()
+}
+
+// WriteCustomSVG is like EncodeCustomSVG, but it writes
+// the SVG file to w.
+func WriteCustomSVG(w io.Writer, meshes []*Mesh, colors []string, thicknesses []float64,
+	bounds Bounder) error {
+	if err := writeCustomSVG(w, meshes, colors, thicknesses, bounds); err != nil {
+		return errors.Wrap(err, "write SVG")
+	}
+	return nil
+}
+
+func writeCustomSVG(w io.Writer, meshes []*Mesh, colors []string, thicknesses []float64,
+	bounds Bounder) error {
 	if len(meshes) != len(colors) {
 		panic("incorrect number of colors")
 	}
@@ -56,18 +95,20 @@ func EncodeCustomSVG(meshes []*Mesh, colors []string, thicknesses []float64, bou
 		}
 	}
 
-	var result bytes.Buffer
-	writer, err := fileformats.NewSVGWriter(&result, [4]float64{
+	writer, err := fileformats.NewSVGWriter(w, [4]float64{
 		min.X, min.Y, max.X - min.X, max.Y - min.Y,
 	})
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	for i, m := range meshes {
 		color := colors[i]
 		thickness := fmt.Sprintf("%f", thicknesses[i])
 		findPolylines(m, func(points []Coord) {
+			if err != nil {
+				return
+			}
 			pointArrs := make([][2]float64, len(points))
 			for i, x := range points {
 				pointArrs[i] = x.Array()
@@ -77,16 +118,13 @@ func EncodeCustomSVG(meshes []*Mesh, colors []string, thicknesses []float64, bou
 				"stroke-width": thickness,
 				"stroke":       color,
 			})
-			if err != nil {
-				panic(err)
-			}
 		})
+		if err != nil {
+			return err
+		}
 	}
 
-	if err := writer.WriteEnd(); err != nil {
-		panic(err)
-	}
-	return result.Bytes()
+	return writer.WriteEnd()
 }
 
 // findPolylines finds sequences of connected segments and
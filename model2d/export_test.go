@@ -1,6 +1,10 @@
 package model2d
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
 
 func TestFindPolyline(t *testing.T) {
 	meshes := []*Mesh{
@@ -63,3 +67,30 @@ func TestEncodeCSV(t *testing.T) {
 		mesh.Remove(result[0])
 	}
 }
+
+func TestWriteCSV(t *testing.T) {
+	mesh := NewMeshRect(XY(0, 0), XY(1, 2))
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, mesh); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeCSV(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(mesh.SegmentSlice()) {
+		t.Error("invalid number of segments")
+	}
+	if err := WriteCSV(failingWriter{}, mesh); err == nil {
+		t.Error("expected write error")
+	}
+	if err := WriteSVG(failingWriter{}, mesh); err == nil {
+		t.Error("expected write error")
+	}
+}
+
+type failingWriter struct{}
+
+func (f failingWriter) Write(data []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
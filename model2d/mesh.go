@@ -3,6 +3,8 @@
 package model2d
 
 import (
+	"bufio"
+	"io"
 	"math"
 	"os"
 	"sort"
@@ -291,6 +293,72 @@ func (m *Mesh) Rotate(angle float64) *Mesh {
 	return m.Transform(Rotation(angle))
 }
 
+// ScaleAxes creates a new mesh by scaling each axis of the
+// coordinates by the corresponding component of s.
+//
+// If an odd number of components are negative, the mesh
+// is mirrored, so the faces are re-oriented to keep the
+// normals pointing outward.
+func (m *Mesh) ScaleAxes(s Coord) *Mesh {
+	var numNegative int
+	for _, x := range s.Array() {
+		if x < 0 {
+			numNegative++
+		}
+	}
+	if numNegative%2 == 1 {
+		return m.mapCoordsMirrored(s.Mul)
+	}
+	return m.MapCoords(s.Mul)
+}
+
+// Mirror creates a new mesh by reflecting all of the
+// coordinates across the line through point with the
+// given normal.
+//
+// The faces are re-oriented to keep the normals pointing
+// outward.
+func (m *Mesh) Mirror(point, normal Coord) *Mesh {
+	normal = normal.Normalize()
+	return m.mapCoordsMirrored(func(c Coord) Coord {
+		return c.Sub(normal.Scale(2 * c.Sub(point).Dot(normal)))
+	})
+}
+
+// SnapToGrid creates a new mesh by rounding every
+// coordinate to the nearest multiple of epsilon.
+//
+// This can merge vertices which are nearly equal, for
+// example to fix tiny gaps from floating-point error.
+// Faces which become degenerate are removed.
+func (m *Mesh) SnapToGrid(epsilon float64) *Mesh {
+	snapped := m.MapCoords(func(c Coord) Coord {
+		arr := c.Array()
+		for i, x := range arr {
+			arr[i] = math.Round(x/epsilon) * epsilon
+		}
+		return NewCoordArray(arr)
+	})
+	res := NewMesh()
+	snapped.Iterate(func(f *Segment) {
+		if f[0] == f[1] {
+			return
+		}
+		res.Add(f)
+	})
+	return res
+}
+
+func (m *Mesh) mapCoordsMirrored(f func(Coord) Coord) *Mesh {
+	res := NewMesh()
+	m.MapCoords(f).Iterate(func(t *Segment) {
+		t1 := *t
+		t1[0], t1[1] = t1[1], t1[0]
+		res.Add(&t1)
+	})
+	return res
+}
+
 // MapCoords creates a new mesh by transforming all of the
 // coordinates according to the function f.
 func (m *Mesh) MapCoords(f func(Coord) Coord) *Mesh {
@@ -327,14 +395,24 @@ func (m *Mesh) Transform(t Transform) *Mesh {
 
 // SaveSVG encodes the mesh to an SVG file.
 func (m *Mesh) SaveSVG(path string) error {
-	data := EncodeSVG(m)
-	w, err := os.Create(path)
-	if err != nil {
+	if err := saveToFile(path, m.WriteSVG); err != nil {
 		return errors.Wrap(err, "save SVG")
 	}
-	defer w.Close()
-	if _, err := w.Write(data); err != nil {
-		return errors.Wrap(err, "save SVG")
+	return nil
+}
+
+// WriteSVG encodes the mesh as an SVG file to w.
+func (m *Mesh) WriteSVG(w io.Writer) error {
+	return WriteSVG(w, m)
+}
+
+// SaveCSV encodes the mesh to a CSV file.
+func (m *Mesh) SaveCSV(path string) error {
+	err := saveToFile(path, func(w io.Writer) error {
+		return WriteCSV(w, m)
+	})
+	if err != nil {
+		return errors.Wrap(err, "save CSV")
 	}
 	return nil
 }
@@ -449,3 +527,23 @@ func (m *Mesh) getVertexToFaceOrNil() *CoordToFaces {
 	}
 	return res.(*CoordToFaces)
 }
+
+// saveToFile creates a file at path and writes it with f,
+// reporting errors from writing, flushing, and closing the
+// file.
+func saveToFile(path string, f func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	bufWriter := bufio.NewWriter(file)
+	if err := f(bufWriter); err != nil {
+		file.Close()
+		return err
+	}
+	if err := bufWriter.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -32,3 +32,53 @@ func TestNewMeshRect(t *testing.T) {
 	mesh := NewMeshRect(XY(0.2, 0.3), XY(0.25, 0.5))
 	MustValidateMesh(t, mesh)
 }
+
+func TestMeshScaleAxes(t *testing.T) {
+	mesh := NewMeshRect(XY(1, 1), XY(2, 3))
+	for _, s := range []Coord{XY(2, 3), XY(-2, 3), XY(-2, -0.5)} {
+		scaled := mesh.ScaleAxes(s)
+		if _, n := scaled.RepairNormals(1e-8); n != 0 {
+			t.Errorf("scale %v: %d bad normals", s, n)
+		}
+		expected := mesh.Area() * math.Abs(s.X*s.Y)
+		if a := scaled.Area(); math.Abs(a-expected) > 1e-8 {
+			t.Errorf("scale %v: expected area %f but got %f", s, expected, a)
+		}
+	}
+}
+
+func TestMeshMirror(t *testing.T) {
+	mesh := NewMeshPolar(func(theta float64) float64 {
+		return 2 + math.Cos(theta*3)
+	}, 30).Translate(XY(1, 2))
+	mirrored := mesh.Mirror(XY(0, 1), XY(1, 1))
+	if _, n := mirrored.RepairNormals(1e-8); n != 0 {
+		t.Errorf("%d bad normals", n)
+	}
+	if math.Abs(mirrored.Area()-mesh.Area()) > 1e-8 {
+		t.Errorf("area changed from %f to %f", mesh.Area(), mirrored.Area())
+	}
+	// The center of the mesh should be reflected.
+	var mid Coord
+	vertices := mirrored.VertexSlice()
+	for _, c := range vertices {
+		mid = mid.Add(c.Scale(1 / float64(len(vertices))))
+	}
+	if !mirrored.Manifold() || mid.Dist(XY(-1, 0)) > 1e-8 {
+		t.Errorf("unexpected mirrored mesh center: %v", mid)
+	}
+}
+
+func TestMeshSnapToGrid(t *testing.T) {
+	mesh := NewMesh()
+	mesh.Add(&Segment{XY(0.01, 0), XY(0.98, 1.02)})
+	mesh.Add(&Segment{XY(0.98, 1.02), XY(1.01, 0.99)})
+	snapped := mesh.SnapToGrid(0.5)
+	segs := snapped.SegmentSlice()
+	if len(segs) != 1 {
+		t.Fatalf("expected 1 segment but got %d", len(segs))
+	}
+	if *segs[0] != (Segment{XY(0, 0), XY(1, 1)}) {
+		t.Errorf("unexpected segment: %v", segs[0])
+	}
+}
@@ -43,20 +43,30 @@ func Rasterize(path string, obj interface{}, scale float64) error {
 // RasterizeColor is like Rasterize, but it renders
 // multiple objects in different colors.
 func RasterizeColor(path string, objs []interface{}, colors []color.Color, scale float64) error {
-	b0 := objs[0].(Bounder)
-	min, max := b0.Min(), b0.Max()
-	for _, obj := range objs {
-		b := obj.(Bounder)
-		min = min.Min(b.Min())
-		max = max.Max(b.Max())
+	if len(objs) != len(colors) {
+		panic("objects and colors must have same length")
 	}
-
-	rast := Rasterizer{Scale: scale, Bounds: NewRect(min, max)}
-	imgs := make([]*image.Gray, len(objs))
+	layers := make([]RasterLayer, len(objs))
 	for i, obj := range objs {
-		imgs[i] = rast.Rasterize(obj)
+		layers[i] = RasterLayer{Object: obj, Color: colors[i]}
+	}
+	return RasterizeLayers(path, layers, nil, scale)
+}
+
+// RasterizeLayers is like RasterizeColor, but it takes
+// layers of objects, and an optional background color.
+//
+// If background is nil, the background is transparent.
+func RasterizeLayers(path string, layers []RasterLayer, background color.Color,
+	scale float64) error {
+	rast := Rasterizer{Scale: scale}
+	img := rast.RasterizeLayers(layers)
+	if background != nil {
+		res := image.NewRGBA(img.Bounds())
+		draw.Draw(res, res.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+		draw.Draw(res, res.Bounds(), img, image.Point{}, draw.Over)
+		img = res
 	}
-	img := ColorizeOverlay(imgs, colors)
 	if err := SaveImage(path, img); err != nil {
 		return errors.Wrap(err, "rasterize image")
 	}
@@ -184,6 +194,52 @@ func (r *Rasterizer) Rasterize(obj interface{}) *image.Gray {
 	panic(fmt.Sprintf("cannot rasterize objects of type: %T", obj))
 }
 
+// A RasterLayer is an object which is rasterized in a
+// single color, as part of a layered image.
+type RasterLayer struct {
+	// Object is a Solid, Collider, or Mesh.
+	//
+	// Like for Rasterizer.Rasterize, solids are filled in,
+	// while colliders and meshes are drawn as lines.
+	Object interface{}
+
+	// Color is the color of the layer.
+	// The alpha channel can be used to make the layer
+	// translucent, so that layers beneath it are visible.
+	Color color.Color
+}
+
+// RasterizeLayers rasterizes objects in different colors
+// and overlays them, each on top of the last, using alpha
+// compositing.
+// The background of the resulting image is transparent.
+//
+// If r.Bounds is nil, the union of the bounds of every
+// object is used, so that the layers line up.
+func (r *Rasterizer) RasterizeLayers(layers []RasterLayer) *image.RGBA {
+	if len(layers) == 0 {
+		panic("must provide at least one layer")
+	}
+	rast := *r
+	if rast.Bounds == nil {
+		b0 := layers[0].Object.(Bounder)
+		min, max := b0.Min(), b0.Max()
+		for _, layer := range layers[1:] {
+			b := layer.Object.(Bounder)
+			min = min.Min(b.Min())
+			max = max.Max(b.Max())
+		}
+		rast.Bounds = NewRect(min, max)
+	}
+	imgs := make([]*image.Gray, len(layers))
+	colors := make([]color.Color, len(layers))
+	for i, layer := range layers {
+		imgs[i] = rast.Rasterize(layer.Object)
+		colors[i] = layer.Color
+	}
+	return ColorizeOverlay(imgs, colors)
+}
+
 // RasterizeSolid rasterizes a Solid into an image.
 func (r *Rasterizer) RasterizeSolid(s Solid) *image.Gray {
 	scale := r.scale()
@@ -1,6 +1,15 @@
 package model2d
 
-import "testing"
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestRasterizeCollider(t *testing.T) {
 	shape := &Circle{Radius: 40}
@@ -49,3 +58,75 @@ func TestRasterizeColliderSolid(t *testing.T) {
 		}
 	}
 }
+
+func TestRasterizeLayers(t *testing.T) {
+	layers := []RasterLayer{
+		{
+			Object: &Rect{MinVal: XY(0, 0), MaxVal: XY(2, 2)},
+			Color:  color.RGBA{B: 0xff, A: 0xff},
+		},
+		{
+			Object: &Rect{MinVal: XY(1, 1), MaxVal: XY(3, 3)},
+			Color:  color.NRGBA{R: 0xff, A: 0x80},
+		},
+	}
+	rast := &Rasterizer{Scale: 10, Subsamples: 1}
+	img := rast.RasterizeLayers(layers)
+	if img.Bounds() != image.Rect(0, 0, 30, 30) {
+		t.Fatalf("unexpected bounds: %v", img.Bounds())
+	}
+
+	expected := map[[2]int]color.RGBA{
+		// Only the bottom layer.
+		{5, 5}: {B: 0xff, A: 0xff},
+		// The top layer blended over the bottom layer.
+		{15, 15}: {R: 0x80, B: 0x7f, A: 0xff},
+		// Only the translucent top layer.
+		{25, 25}: {R: 0x80, A: 0x80},
+		// Neither layer.
+		{5, 25}: {},
+	}
+	for p, c := range expected {
+		actual := img.RGBAAt(p[0], p[1])
+		for i, pair := range [][2]uint8{
+			{actual.R, c.R}, {actual.G, c.G}, {actual.B, c.B}, {actual.A, c.A},
+		} {
+			if math.Abs(float64(pair[0])-float64(pair[1])) > 1 {
+				t.Errorf("pixel %v channel %d: expected %v but got %v", p, i, c, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestRasterizeLayersBackground(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rasterize_layers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "layers.png")
+	layers := []RasterLayer{
+		{Object: &Circle{Center: XY(2, 2), Radius: 2}, Color: color.Black},
+	}
+	if err := RasterizeLayers(path, layers, color.White, 5); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corner := color.RGBAModel.Convert(img.At(0, 0)).(color.RGBA)
+	if corner != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("unexpected background: %v", corner)
+	}
+	center := color.RGBAModel.Convert(img.At(10, 10)).(color.RGBA)
+	if center != (color.RGBA{A: 0xff}) {
+		t.Errorf("unexpected center: %v", center)
+	}
+}
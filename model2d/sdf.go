@@ -40,6 +40,17 @@ type FaceSDF interface {
 	FaceSDF(c Coord) (*Segment, Coord, float64)
 }
 
+// A NormalSDF is an SDF that can additionally compute
+// the direction of the surface normal.
+type NormalSDF interface {
+	SDF
+
+	// NormalSDF gets the SDF at c and also returns the
+	// outward unit normal, which is the direction in which
+	// the SDF decreases the fastest.
+	NormalSDF(c Coord) (Coord, float64)
+}
+
 type funcSDF struct {
 	min Coord
 	max Coord
@@ -174,6 +185,117 @@ func (c *colliderSDF) boundDistance(coord Coord) (min, max float64) {
 	}
 }
 
+type sdfSolid struct {
+	sdf SDF
+}
+
+// SDFToSolid creates a Solid which contains the points
+// where the SDF is positive.
+//
+// The resulting Solid is also an SDF.
+func SDFToSolid(s SDF) Solid {
+	return &sdfSolid{sdf: s}
+}
+
+func (s *sdfSolid) Min() Coord {
+	return s.sdf.Min()
+}
+
+func (s *sdfSolid) Max() Coord {
+	return s.sdf.Max()
+}
+
+func (s *sdfSolid) Contains(c Coord) bool {
+	return InBounds(s, c) && s.sdf.SDF(c) > 0
+}
+
+func (s *sdfSolid) SDF(c Coord) float64 {
+	return s.sdf.SDF(c)
+}
+
+// SolidToSDF approximates the SDF of a Solid by creating a
+// mesh of its surface with the given grid spacing.
+//
+// The resulting SDF is only as accurate as the mesh, so
+// delta should be small compared to the features of the
+// solid.
+func SolidToSDF(s Solid, delta float64) FaceSDF {
+	return MeshToSDF(MarchingSquaresSearch(s, delta, 8))
+}
+
+type normalSDF struct {
+	sdf     SDF
+	epsilon float64
+}
+
+// SDFToNormalSDF creates a NormalSDF which estimates
+// normals using SDFGradient.
+//
+// If s is already a NormalSDF, it is returned as-is.
+func SDFToNormalSDF(s SDF, epsilon float64) NormalSDF {
+	if n, ok := s.(NormalSDF); ok {
+		return n
+	}
+	return &normalSDF{sdf: s, epsilon: epsilon}
+}
+
+func (n *normalSDF) Min() Coord {
+	return n.sdf.Min()
+}
+
+func (n *normalSDF) Max() Coord {
+	return n.sdf.Max()
+}
+
+func (n *normalSDF) SDF(c Coord) float64 {
+	return n.sdf.SDF(c)
+}
+
+func (n *normalSDF) NormalSDF(c Coord) (Coord, float64) {
+	return SDFGradient(n.sdf, c, n.epsilon).Scale(-1).Normalize(), n.sdf.SDF(c)
+}
+
+// SDFGradient computes the gradient of an SDF at a point,
+// which points away from the surface on the inside and
+// towards the surface on the outside.
+//
+// If s is a NormalSDF or a PointSDF, the gradient is
+// computed exactly where possible.
+// Otherwise, it is estimated with central differences
+// using a step size of epsilon.
+// If epsilon is 0, a step size is chosen based on the
+// size of the SDF's bounds.
+func SDFGradient(s SDF, c Coord, epsilon float64) Coord {
+	switch s := s.(type) {
+	case NormalSDF:
+		normal, _ := s.NormalSDF(c)
+		return normal.Scale(-1)
+	case PointSDF:
+		point, dist := s.PointSDF(c)
+		if diff := c.Sub(point); dist != 0 && diff.Norm() > 0 {
+			if dist < 0 {
+				diff = diff.Scale(-1)
+			}
+			return diff.Normalize()
+		}
+	}
+
+	if epsilon == 0 {
+		epsilon = 1e-5
+		for _, size := range s.Max().Sub(s.Min()).Array() {
+			epsilon = math.Max(epsilon, size*1e-5)
+		}
+	}
+	var res [2]float64
+	for i := range res {
+		var delta [2]float64
+		delta[i] = epsilon
+		d := NewCoordArray(delta)
+		res[i] = (s.SDF(c.Add(d)) - s.SDF(c.Sub(d))) / (2 * epsilon)
+	}
+	return NewCoordArray(res)
+}
+
 type meshSDF struct {
 	Solid
 	MDF *meshDistFunc
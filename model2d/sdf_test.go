@@ -54,3 +54,24 @@ func sdfTestingSolid() Solid {
 		},
 	}
 }
+
+func TestSDFGradient(t *testing.T) {
+	circle := MeshToSDF(NewMeshPolar(func(theta float64) float64 {
+		return 1
+	}, 1000))
+	funcCircle := FuncSDF(circle.Min(), circle.Max(), circle.SDF)
+	solid := SDFToSolid(funcCircle)
+	for i := 0; i < 100; i++ {
+		c := NewCoordRandNorm().Scale(0.5)
+		if solid.Contains(c) != (c.Norm() < 1) {
+			t.Fatalf("unexpected containment at %v", c)
+		}
+		// The SDF increases towards the center everywhere.
+		expected := c.Normalize().Scale(-1)
+		for _, s := range []SDF{circle, funcCircle} {
+			if actual := SDFGradient(s, c, 0); actual.Dist(expected) > 1e-2 {
+				t.Fatalf("expected gradient %v but got %v", expected, actual)
+			}
+		}
+	}
+}
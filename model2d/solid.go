@@ -3,6 +3,7 @@
 package model2d
 
 import (
+	"math"
 	"sort"
 )
 
@@ -161,6 +162,117 @@ func (i IntersectedSolid) Contains(c Coord) bool {
 	return true
 }
 
+// OptimizeCSG creates a version of a tree of JoinedSolid,
+// SubtractedSolid, and IntersectedSolid combinators which
+// contains the same points but is faster to query.
+//
+// Nested joins and intersections are flattened, and the
+// negative parts of nested subtractions are joined.
+// Children which cannot affect the result are pruned, such
+// as negative solids outside the bounds of their positive
+// solid, and the bounds of the remaining children are
+// shrunk to the region where they matter.
+// Every child is wrapped in a bounds check, so points
+// outside of a child's bounds never reach it.
+func OptimizeCSG(s Solid) Solid {
+	res := optimizeCSG(s, s.Min(), s.Max())
+	if res == nil {
+		// The solid is provably empty, but we cannot express
+		// an empty solid with the same bounds otherwise.
+		return ForceSolidBounds(s, s.Min(), s.Max())
+	}
+	return res
+}
+
+// optimizeCSG optimizes s for queries within the bounds
+// from min to max, returning nil if s is empty there.
+func optimizeCSG(s Solid, min, max Coord) Solid {
+	min = min.Max(s.Min())
+	max = max.Min(s.Max())
+	if min.Min(max) != min {
+		return nil
+	}
+	switch s := s.(type) {
+	case JoinedSolid:
+		var children JoinedSolid
+		for _, child := range flattenJoined(s, nil) {
+			if optimized := optimizeCSG(child, min, max); optimized != nil {
+				children = append(children, optimized)
+			}
+		}
+		if len(children) == 0 {
+			return nil
+		} else if len(children) == 1 {
+			return children[0]
+		} else if len(children) >= 4 {
+			return children.Optimize()
+		}
+		return CacheSolidBounds(children)
+	case IntersectedSolid:
+		var children IntersectedSolid
+		for _, child := range flattenIntersected(s, nil) {
+			optimized := optimizeCSG(child, min, max)
+			if optimized == nil {
+				return nil
+			}
+			children = append(children, optimized)
+		}
+		if len(children) == 1 {
+			return children[0]
+		}
+		return CacheSolidBounds(children)
+	case *SubtractedSolid:
+		positive, negatives := flattenSubtracted(s)
+		pos := optimizeCSG(positive, min, max)
+		if pos == nil {
+			return nil
+		}
+		neg := optimizeCSG(negatives, pos.Min(), pos.Max())
+		if neg == nil {
+			return pos
+		}
+		return CacheSolidBounds(&SubtractedSolid{Positive: pos, Negative: neg})
+	default:
+		return ForceSolidBounds(s, min, max)
+	}
+}
+
+func flattenJoined(j JoinedSolid, res []Solid) []Solid {
+	for _, s := range j {
+		if sub, ok := s.(JoinedSolid); ok {
+			res = flattenJoined(sub, res)
+		} else {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func flattenIntersected(i IntersectedSolid, res []Solid) []Solid {
+	for _, s := range i {
+		if sub, ok := s.(IntersectedSolid); ok {
+			res = flattenIntersected(sub, res)
+		} else {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// flattenSubtracted turns (A - B) - C into A - (B + C).
+func flattenSubtracted(s *SubtractedSolid) (Solid, JoinedSolid) {
+	negatives := JoinedSolid{s.Negative}
+	positive := s.Positive
+	for {
+		sub, ok := positive.(*SubtractedSolid)
+		if !ok {
+			return positive, negatives
+		}
+		negatives = append(negatives, sub.Negative)
+		positive = sub.Positive
+	}
+}
+
 // A ColliderSolid is a Solid that uses a Collider to
 // check if points are in the solid.
 //
@@ -236,6 +348,18 @@ func ForceSolidBounds(s Solid, min, max Coord) Solid {
 	return CheckedFuncSolid(min, max, s.Contains)
 }
 
+// PadSolidBounds creates a new solid whose bounds are
+// expanded by a separate amount along each axis.
+//
+// This is useful for solids whose reported bounds are too
+// tight along some axes, such as solids which are only
+// slightly larger than their bounds due to rounding.
+//
+// Every component of padding should be non-negative.
+func PadSolidBounds(s Solid, padding Coord) Solid {
+	return FuncSolid(s.Min().Sub(padding), s.Max().Add(padding), s.Contains)
+}
+
 // CacheSolidBounds creates a Solid that has a cached
 // version of the solid's boundary coordinates.
 //
@@ -258,6 +382,13 @@ type smoothJoin struct {
 //
 // If the radius is 0, it is equivalent to turning the
 // SDFs directly into solids and then joining them.
+//
+// The fillets are circular arcs of the given radius,
+// which fill the gap wherever two surfaces come within
+// the radius of each other.
+// The result is only a Solid; for a smooth union which is
+// also an SDF, and can therefore be smoothed further, see
+// SmoothJoinedSolid.
 func SmoothJoin(radius float64, sdfs ...SDF) Solid {
 	min := sdfs[0].Min()
 	max := sdfs[0].Max()
@@ -304,6 +435,139 @@ func (s *smoothJoin) Contains(c Coord) bool {
 	return d1*d1+d2*d2 > s.radius*s.radius
 }
 
+// A SmoothJoinedSolid is a Solid and SDF which joins SDFs
+// using a smooth maximum, creating fillets where the
+// surfaces meet.
+//
+// The Radius controls the size of the blended region.
+// The surfaces are only blended where their SDFs differ
+// by less than Radius, and the blend moves the surface
+// outward by at most Radius/4.
+// If the Radius is 0, this is equivalent to a JoinedSolid
+// of the SDFs.
+//
+// Unlike SmoothJoin, which creates circular fillets of a
+// given radius but is only a Solid, this is also an SDF,
+// so it can be nested inside other smooth operations.
+// The two radii are not interchangeable: SmoothJoin's
+// radius is that of the fillets themselves, while Radius
+// bounds the difference in SDF values over which the
+// surfaces are blended.
+type SmoothJoinedSolid struct {
+	SDFs   []SDF
+	Radius float64
+}
+
+func (s *SmoothJoinedSolid) Min() Coord {
+	min := s.SDFs[0].Min()
+	for _, x := range s.SDFs[1:] {
+		min = min.Min(x.Min())
+	}
+	return min.AddScalar(-s.Radius / 4)
+}
+
+func (s *SmoothJoinedSolid) Max() Coord {
+	max := s.SDFs[0].Max()
+	for _, x := range s.SDFs[1:] {
+		max = max.Max(x.Max())
+	}
+	return max.AddScalar(s.Radius / 4)
+}
+
+func (s *SmoothJoinedSolid) Contains(c Coord) bool {
+	return InBounds(s, c) && s.SDF(c) > 0
+}
+
+// SDF computes the smooth maximum of the SDFs.
+func (s *SmoothJoinedSolid) SDF(c Coord) float64 {
+	res := s.SDFs[0].SDF(c)
+	for _, x := range s.SDFs[1:] {
+		res = smoothMax(res, x.SDF(c), s.Radius)
+	}
+	return res
+}
+
+// A SmoothIntersectedSolid is a Solid and SDF which
+// intersects SDFs using a smooth minimum, rounding off the
+// edges where the surfaces meet.
+//
+// The Radius has the same meaning as for
+// SmoothJoinedSolid.
+type SmoothIntersectedSolid struct {
+	SDFs   []SDF
+	Radius float64
+}
+
+func (s *SmoothIntersectedSolid) Min() Coord {
+	bound := s.SDFs[0].Min()
+	for _, x := range s.SDFs[1:] {
+		bound = bound.Max(x.Min())
+	}
+	return bound
+}
+
+func (s *SmoothIntersectedSolid) Max() Coord {
+	bound := s.SDFs[0].Max()
+	for _, x := range s.SDFs[1:] {
+		bound = bound.Min(x.Max())
+	}
+	// Prevent negative area.
+	return bound.Max(s.Min())
+}
+
+func (s *SmoothIntersectedSolid) Contains(c Coord) bool {
+	return InBounds(s, c) && s.SDF(c) > 0
+}
+
+// SDF computes the smooth minimum of the SDFs.
+func (s *SmoothIntersectedSolid) SDF(c Coord) float64 {
+	res := s.SDFs[0].SDF(c)
+	for _, x := range s.SDFs[1:] {
+		res = -smoothMax(-res, -x.SDF(c), s.Radius)
+	}
+	return res
+}
+
+// A SmoothSubtractedSolid is a Solid and SDF containing
+// the points in Positive which are not in Negative, with
+// the edges of the cut rounded off.
+//
+// The Radius has the same meaning as for
+// SmoothJoinedSolid.
+type SmoothSubtractedSolid struct {
+	Positive SDF
+	Negative SDF
+	Radius   float64
+}
+
+func (s *SmoothSubtractedSolid) Min() Coord {
+	return s.Positive.Min()
+}
+
+func (s *SmoothSubtractedSolid) Max() Coord {
+	return s.Positive.Max()
+}
+
+func (s *SmoothSubtractedSolid) Contains(c Coord) bool {
+	return InBounds(s, c) && s.SDF(c) > 0
+}
+
+// SDF computes the smooth minimum of the positive SDF and
+// the negated negative SDF.
+func (s *SmoothSubtractedSolid) SDF(c Coord) float64 {
+	return -smoothMax(-s.Positive.SDF(c), s.Negative.SDF(c), s.Radius)
+}
+
+// smoothMax computes a polynomial smooth maximum, which is
+// never more than k/4 above the true maximum.
+func smoothMax(a, b, k float64) float64 {
+	if k == 0 {
+		return math.Max(a, b)
+	}
+	h := math.Max(k-math.Abs(a-b), 0) / k
+	return math.Max(a, b) + h*h*k/4
+}
+
 func BitmapToSolid(b *Bitmap) Solid {
 	return CheckedFuncSolid(Coord{}, XY(float64(b.Width), float64(b.Height)), func(c Coord) bool {
 		return b.Get(int(c.X), int(c.Y))
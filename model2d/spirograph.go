@@ -0,0 +1,119 @@
+package model2d
+
+import "math"
+
+// A Trochoid is a spirograph curve traced by a pen
+// attached to a circle rolling around a fixed circle.
+//
+// If Epi is false, the circle rolls inside of the fixed
+// circle, producing a hypotrochoid.
+// Otherwise, it rolls around the outside, producing an
+// epitrochoid.
+//
+// The curve is centered at the origin, and t in [0, 1]
+// traces out the entire closed curve.
+type Trochoid struct {
+	// FixedRadius is the radius of the stationary circle.
+	FixedRadius float64
+
+	// RollingRadius is the radius of the rolling circle.
+	RollingRadius float64
+
+	// PenDistance is the distance from the center of the
+	// rolling circle to the pen.
+	PenDistance float64
+
+	// Epi determines if the circle rolls outside of the
+	// fixed circle.
+	Epi bool
+
+	// Revolutions, if non-zero, is the number of times
+	// the rolling circle travels around the fixed circle.
+	//
+	// If 0, it is the smallest number of revolutions that
+	// closes the curve, assuming the ratio of the radii is
+	// a reasonably simple fraction.
+	Revolutions int
+}
+
+// Eval evaluates the curve at t in [0, 1].
+func (t *Trochoid) Eval(tVal float64) Coord {
+	theta := tVal * 2 * math.Pi * float64(t.revolutions())
+	if t.Epi {
+		sum := t.FixedRadius + t.RollingRadius
+		inner := sum / t.RollingRadius * theta
+		return XY(
+			sum*math.Cos(theta)-t.PenDistance*math.Cos(inner),
+			sum*math.Sin(theta)-t.PenDistance*math.Sin(inner),
+		)
+	}
+	diff := t.FixedRadius - t.RollingRadius
+	inner := diff / t.RollingRadius * theta
+	return XY(
+		diff*math.Cos(theta)+t.PenDistance*math.Cos(inner),
+		diff*math.Sin(theta)-t.PenDistance*math.Sin(inner),
+	)
+}
+
+// Mesh creates a closed polyline along the curve with n
+// evenly-spaced segments.
+func (t *Trochoid) Mesh(n int) *Mesh {
+	m := NewMesh()
+	first := t.Eval(0)
+	c1 := first
+	for i := 1; i <= n; i++ {
+		c2 := first
+		if i < n {
+			c2 = t.Eval(float64(i) / float64(n))
+		}
+		m.Add(&Segment{c1, c2})
+		c1 = c2
+	}
+	return m
+}
+
+// Solid creates a solid stroke along the curve with the
+// given thickness, approximating the curve with n
+// segments.
+//
+// The result can be meshed with MarchingSquaresSearch and
+// extruded into a 3D shape.
+func (t *Trochoid) Solid(thickness float64, n int) Solid {
+	return NewColliderSolidHollow(MeshToCollider(t.Mesh(n)), thickness/2)
+}
+
+func (t *Trochoid) revolutions() int {
+	if t.Revolutions != 0 {
+		return t.Revolutions
+	}
+	// The curve closes after the rolling circle has made a
+	// whole number of rotations relative to the fixed
+	// circle, i.e. after p revolutions where p/q is the
+	// reduced ratio RollingRadius/FixedRadius.
+	p, _ := rationalApprox(t.RollingRadius/t.FixedRadius, 1000)
+	return p
+}
+
+// rationalApprox approximates x as p/q with q <= maxDenom
+// using continued fractions.
+func rationalApprox(x float64, maxDenom int) (int, int) {
+	p0, q0, p1, q1 := 0, 1, 1, 0
+	for i := 0; i < 64; i++ {
+		a := math.Floor(x)
+		p2 := int(a)*p1 + p0
+		q2 := int(a)*q1 + q0
+		if q2 > maxDenom {
+			break
+		}
+		p0, q0, p1, q1 = p1, q1, p2, q2
+		frac := x - a
+		if frac < 1e-9 {
+			break
+		}
+		x = 1 / frac
+	}
+	if p1 == 0 {
+		return 1, q1
+	}
+	return p1, q1
+}
@@ -0,0 +1,49 @@
+package model2d
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrochoidClosed(t *testing.T) {
+	for _, epi := range []bool{false, true} {
+		for _, radii := range [][2]float64{{5, 3}, {1, 0.25}, {7, 2.1}} {
+			tr := &Trochoid{
+				FixedRadius:   radii[0],
+				RollingRadius: radii[1],
+				PenDistance:   0.7 * radii[1],
+				Epi:           epi,
+			}
+			if d := tr.Eval(0).Dist(tr.Eval(1)); d > 1e-8 {
+				t.Errorf("curve %v (epi=%v) is not closed: distance %f", radii, epi, d)
+			}
+			// The curve should not close too early.
+			revs := tr.revolutions()
+			for i := 1; i < revs; i++ {
+				frac := float64(i) / float64(revs)
+				if tr.Eval(0).Dist(tr.Eval(frac)) < 1e-8 {
+					t.Errorf("curve %v (epi=%v) closes at %f", radii, epi, frac)
+				}
+			}
+		}
+	}
+}
+
+func TestTrochoidAstroid(t *testing.T) {
+	// An astroid is a hypocycloid with four cusps.
+	tr := &Trochoid{FixedRadius: 4, RollingRadius: 1, PenDistance: 1}
+	for i := 0; i < 4; i++ {
+		theta := float64(i) * math.Pi / 2
+		expected := XY(math.Cos(theta), math.Sin(theta)).Scale(4)
+		if actual := tr.Eval(float64(i) / 4); actual.Dist(expected) > 1e-8 {
+			t.Errorf("cusp %d: expected %v but got %v", i, expected, actual)
+		}
+	}
+	solid := tr.Solid(0.1, 1000)
+	if !solid.Contains(XY(4, 0.01)) {
+		t.Error("expected point on curve to be in solid")
+	}
+	if solid.Contains(XY(0, 0)) {
+		t.Error("expected center to be outside of stroke")
+	}
+}
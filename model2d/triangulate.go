@@ -92,12 +92,14 @@ func isVertexEar(polygon []Coord, vertex int) bool {
 	inverseMat := (&Matrix2{p1.X - p2.X, p3.X - p2.X, p1.Y - p2.Y, p3.Y - p2.Y}).Inverse()
 
 	for i, p := range polygon {
-		if i == idx1 || i == vertex || i == idx3 {
+		if i == idx1 || i == vertex || i == idx3 || p == p1 || p == p2 || p == p3 {
 			continue
 		}
 		coords := inverseMat.MulColumn(p.Sub(p2))
-		if coords.X > 0 && coords.Y > 0 && coords.X+coords.Y < 1 {
-			// Another point lies inside this triangle.
+		if coords.X >= 0 && coords.Y >= 0 && coords.X+coords.Y <= 1 {
+			// Another point lies inside this triangle, or on
+			// its new edge, which would make the remaining
+			// polygon touch itself.
 			return false
 		}
 	}
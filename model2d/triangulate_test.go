@@ -63,6 +63,23 @@ func TestTriangulate(t *testing.T) {
 	}
 }
 
+func TestTriangulateTouchingEar(t *testing.T) {
+	// Clipping the corner at the origin creates an edge
+	// which passes through the reflex vertex at (1, 1).
+	poly := []Coord{XY(0, 0), XY(2, 0), XY(2, 1), XY(1, 1), XY(1, 2), XY(0, 2)}
+	for i := range poly {
+		rotated := append(append([]Coord{}, poly[i:]...), poly[:i]...)
+		var area float64
+		for _, tri := range Triangulate(rotated) {
+			v1, v2 := tri[1].Sub(tri[0]), tri[2].Sub(tri[0])
+			area += math.Abs(v1.X*v2.Y-v1.Y*v2.X) / 2
+		}
+		if math.Abs(area-3) > 1e-8 {
+			t.Errorf("rotation %d: expected area 3 but got %f", i, area)
+		}
+	}
+}
+
 func TestTriangulateMeshBasic(t *testing.T) {
 	mesh := NewMeshPolar(func(theta float64) float64 {
 		return math.Cos(theta) + 1.5
@@ -0,0 +1,332 @@
+package model3d
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/model2d"
+)
+
+// An AtlasChart is a piece of a mesh which has been
+// flattened into 2D, such as by PlanarAtlasCharts.
+type AtlasChart struct {
+	// UV maps each triangle in the chart to 2D coordinates
+	// for its vertices.
+	//
+	// Coordinates should be in the same units as the
+	// triangles, so that charts can be scaled uniformly
+	// to preserve relative surface area.
+	UV map[*Triangle][3]model2d.Coord
+}
+
+// PlanarAtlasCharts creates a chart for each flat region
+// of a mesh, as found by Mesh.ExtractPlanarFaces.
+//
+// Each chart is a projection onto its plane, so surface
+// area is preserved exactly within a chart.
+func PlanarAtlasCharts(m *Mesh, tolerance float64) []*AtlasChart {
+	var res []*AtlasChart
+	for _, face := range m.ExtractPlanarFaces(tolerance) {
+		chart := &AtlasChart{UV: map[*Triangle][3]model2d.Coord{}}
+		face.Mesh.Iterate(func(t *Triangle) {
+			chart.UV[t] = [3]model2d.Coord{
+				face.Plane.To2D(t[0]),
+				face.Plane.To2D(t[1]),
+				face.Plane.To2D(t[2]),
+			}
+		})
+		res = append(res, chart)
+	}
+	return res
+}
+
+// An Atlas maps the triangles of a mesh into a square
+// texture, where texture coordinates range from 0 to 1.
+type Atlas struct {
+	// UV maps each triangle to texture coordinates for its
+	// vertices.
+	UV map[*Triangle][3]model2d.Coord
+
+	// Scale is the ratio between distances in texture
+	// coordinates and distances in the charts.
+	Scale float64
+
+	lookupOnce sync.Once
+	lookup     *atlasLookup
+}
+
+// PackAtlas arranges charts in a square texture without
+// overlap, leaving at least padding space between charts
+// and around the edges of the texture.
+//
+// Every chart is scaled by the same amount, so the texture
+// resolution is uniform across the surface.
+// Charts may be rotated to fit more tightly.
+//
+// The padding is measured in texture coordinates, so a
+// padding of 2/1024 leaves a two pixel gap in a 1024x1024
+// texture.
+// If the padding is too large for the charts to fit at
+// any scale, this panics.
+func PackAtlas(charts []*AtlasChart, padding float64) *Atlas {
+	boxes := make([]*atlasBox, 0, len(charts))
+	for _, chart := range charts {
+		if len(chart.UV) > 0 {
+			boxes = append(boxes, newAtlasBox(chart))
+		}
+	}
+	res := &Atlas{UV: map[*Triangle][3]model2d.Coord{}}
+	if len(boxes) == 0 {
+		return res
+	}
+
+	sort.SliceStable(boxes, func(i, j int) bool {
+		return boxes[i].Size.Y > boxes[j].Size.Y
+	})
+
+	// Any scale small enough for all the charts to fit
+	// in a single column is valid.
+	var maxSize, totalHeight float64
+	for _, b := range boxes {
+		maxSize = math.Max(maxSize, b.Size.X)
+		totalHeight += b.Size.Y
+	}
+	minScale := (1 - padding*float64(len(boxes)+1)) / math.Max(maxSize, totalHeight)
+	maxScale := (1 - 2*padding) / maxSize
+	if minScale <= 0 || !atlasShelfPack(boxes, minScale, padding) {
+		// Fall back on a valid packing with the padding,
+		// even if the charts are tiny.
+		minScale = 0
+	}
+	for i := 0; i < 64; i++ {
+		mid := (minScale + maxScale) / 2
+		if atlasShelfPack(boxes, mid, padding) {
+			minScale = mid
+		} else {
+			maxScale = mid
+		}
+	}
+	if !atlasShelfPack(boxes, minScale, padding) {
+		panic("atlas padding is too large to fit every chart")
+	}
+
+	res.Scale = minScale
+	for _, b := range boxes {
+		for t, uvs := range b.Chart.UV {
+			var newUVs [3]model2d.Coord
+			for i, uv := range uvs {
+				newUVs[i] = b.Transform(uv).Scale(minScale).Add(b.Offset)
+			}
+			res.UV[t] = newUVs
+		}
+	}
+	return res
+}
+
+// AttributedMesh creates an AttributedMesh for m with the
+// texture coordinates of the atlas as corner UVs, so that
+// the mesh can be exported with its texture, e.g. with
+// render3d.GLTFExporter or AttributedMesh.SaveOBJ.
+//
+// The triangles of m should be the ones in the atlas.
+// Triangles which are not in the atlas get no UVs.
+func (a *Atlas) AttributedMesh(m *Mesh) *AttributedMesh {
+	res := NewAttributedMesh(m)
+	m.Iterate(func(t *Triangle) {
+		if uvs, ok := a.UV[t]; ok {
+			for i, uv := range uvs {
+				res.Attributes.CornerUVs[MeshCorner{Triangle: t, Index: i}] = uv
+			}
+		}
+	})
+	return res
+}
+
+// TextureCoord gets the texture coordinate of a point on
+// a triangle in the atlas.
+func (a *Atlas) TextureCoord(t *Triangle, c Coord3D) model2d.Coord {
+	bary := atlasBarycentric(t, c)
+	uvs := a.UV[t]
+	return uvs[0].Scale(bary[0]).Add(uvs[1].Scale(bary[1])).Add(uvs[2].Scale(bary[2]))
+}
+
+// SurfacePoint finds the triangle and point on the mesh
+// which correspond to a texture coordinate.
+//
+// This is the inverse of TextureCoord, and can be used to
+// bake values from the surface of a mesh into a texture.
+// If no triangle covers the texture coordinate, false is
+// returned.
+func (a *Atlas) SurfacePoint(uv model2d.Coord) (*Triangle, Coord3D, bool) {
+	a.lookupOnce.Do(func() {
+		a.lookup = newAtlasLookup(a.UV)
+	})
+	return a.lookup.Find(uv)
+}
+
+// atlasBox is the rotated bounding box of a chart.
+type atlasBox struct {
+	Chart *AtlasChart
+
+	// Rotation is applied to chart coordinates before
+	// subtracting Min.
+	Rotation *model2d.Matrix2
+	Min      model2d.Coord
+	Size     model2d.Coord
+
+	// Offset is the position of the box in the texture.
+	Offset model2d.Coord
+}
+
+func newAtlasBox(chart *AtlasChart) *atlasBox {
+	// Align the chart's principal axis with the x-axis.
+	var mean model2d.Coord
+	var count float64
+	for _, uvs := range chart.UV {
+		for _, uv := range uvs {
+			mean = mean.Add(uv)
+			count++
+		}
+	}
+	mean = mean.Scale(1 / count)
+	var cxx, cxy, cyy float64
+	for _, uvs := range chart.UV {
+		for _, uv := range uvs {
+			d := uv.Sub(mean)
+			cxx += d.X * d.X
+			cxy += d.X * d.Y
+			cyy += d.Y * d.Y
+		}
+	}
+	theta := -0.5 * math.Atan2(2*cxy, cxx-cyy)
+	rotation := model2d.NewMatrix2Rotation(theta)
+
+	min := model2d.Ones(math.Inf(1))
+	max := model2d.Ones(math.Inf(-1))
+	for _, uvs := range chart.UV {
+		for _, uv := range uvs {
+			p := rotation.MulColumn(uv)
+			min = min.Min(p)
+			max = max.Max(p)
+		}
+	}
+	return &atlasBox{
+		Chart:    chart,
+		Rotation: rotation,
+		Min:      min,
+		Size:     max.Sub(min),
+	}
+}
+
+// Transform maps a chart coordinate into the box, before
+// scaling and offsetting.
+func (a *atlasBox) Transform(c model2d.Coord) model2d.Coord {
+	return a.Rotation.MulColumn(c).Sub(a.Min)
+}
+
+// atlasShelfPack places boxes in rows, which are stacked
+// from the bottom of the texture, and reports if all of
+// the boxes fit.
+//
+// The boxes should be sorted by decreasing height.
+func atlasShelfPack(boxes []*atlasBox, scale, padding float64) bool {
+	x, y := padding, padding
+	var shelfHeight float64
+	for _, b := range boxes {
+		size := b.Size.Scale(scale)
+		if size.X+2*padding > 1 {
+			return false
+		}
+		if x+size.X+padding > 1 {
+			x = padding
+			y += shelfHeight + padding
+			shelfHeight = 0
+		}
+		b.Offset = model2d.XY(x, y)
+		x += size.X + padding
+		shelfHeight = math.Max(shelfHeight, size.Y)
+	}
+	return y+shelfHeight+padding <= 1
+}
+
+func atlasBarycentric(t *Triangle, c Coord3D) [3]float64 {
+	normal := t[1].Sub(t[0]).Cross(t[2].Sub(t[0]))
+	norm := normal.Dot(normal)
+	if norm == 0 {
+		return [3]float64{1, 0, 0}
+	}
+	var res [3]float64
+	for i := 0; i < 3; i++ {
+		p1, p2 := t[(i+1)%3], t[(i+2)%3]
+		res[i] = p1.Sub(c).Cross(p2.Sub(c)).Dot(normal) / norm
+	}
+	return res
+}
+
+// atlasLookup is a grid of triangles in texture space.
+type atlasLookup struct {
+	size  int
+	cells [][]*Triangle
+	uvs   map[*Triangle][3]model2d.Coord
+}
+
+func newAtlasLookup(uvs map[*Triangle][3]model2d.Coord) *atlasLookup {
+	size := essentials.MaxInt(1, int(math.Ceil(math.Sqrt(float64(len(uvs))))))
+	res := &atlasLookup{
+		size:  size,
+		cells: make([][]*Triangle, size*size),
+		uvs:   uvs,
+	}
+	for t, uv := range uvs {
+		min := uv[0].Min(uv[1]).Min(uv[2])
+		max := uv[0].Max(uv[1]).Max(uv[2])
+		minX, minY := res.cellIndex(min.X), res.cellIndex(min.Y)
+		maxX, maxY := res.cellIndex(max.X), res.cellIndex(max.Y)
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				res.cells[y*size+x] = append(res.cells[y*size+x], t)
+			}
+		}
+	}
+	return res
+}
+
+func (a *atlasLookup) Find(uv model2d.Coord) (*Triangle, Coord3D, bool) {
+	if uv.X < 0 || uv.Y < 0 || uv.X > 1 || uv.Y > 1 {
+		return nil, Coord3D{}, false
+	}
+	x, y := a.cellIndex(uv.X), a.cellIndex(uv.Y)
+	for _, t := range a.cells[y*a.size+x] {
+		uvs := a.uvs[t]
+		bary, ok := atlasBarycentric2D(uvs, uv)
+		if !ok {
+			continue
+		}
+		point := t[0].Scale(bary[0]).Add(t[1].Scale(bary[1])).Add(t[2].Scale(bary[2]))
+		return t, point, true
+	}
+	return nil, Coord3D{}, false
+}
+
+func (a *atlasLookup) cellIndex(c float64) int {
+	return essentials.MaxInt(0, essentials.MinInt(a.size-1, int(c*float64(a.size))))
+}
+
+func atlasBarycentric2D(t [3]model2d.Coord, c model2d.Coord) ([3]float64, bool) {
+	v1, v2 := t[1].Sub(t[0]), t[2].Sub(t[0])
+	det := v1.X*v2.Y - v1.Y*v2.X
+	if det == 0 {
+		return [3]float64{}, false
+	}
+	d := c.Sub(t[0])
+	b1 := (d.X*v2.Y - d.Y*v2.X) / det
+	b2 := (v1.X*d.Y - v1.Y*d.X) / det
+	b0 := 1 - b1 - b2
+	const eps = 1e-8
+	if b0 < -eps || b1 < -eps || b2 < -eps {
+		return [3]float64{}, false
+	}
+	return [3]float64{b0, b1, b2}, true
+}
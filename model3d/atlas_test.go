@@ -0,0 +1,111 @@
+package model3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+func TestPackAtlas(t *testing.T) {
+	meshes := map[string]*Mesh{
+		"box":    NewMeshRect(Coord3D{}, XYZ(3, 2, 1)),
+		"sphere": NewMeshIcosphere(Coord3D{}, 1, 4),
+	}
+	for name, mesh := range meshes {
+		t.Run(name, func(t *testing.T) {
+			const padding = 0.01
+			atlas := PackAtlas(PlanarAtlasCharts(mesh, 1e-5), padding)
+			if len(atlas.UV) != len(mesh.TriangleSlice()) {
+				t.Fatalf("expected %d triangles but got %d", len(mesh.TriangleSlice()),
+					len(atlas.UV))
+			}
+
+			var uvArea float64
+			for tri, uvs := range atlas.UV {
+				for _, uv := range uvs {
+					if uv.X < padding-1e-8 || uv.Y < padding-1e-8 ||
+						uv.X > 1-padding+1e-8 || uv.Y > 1-padding+1e-8 {
+						t.Fatalf("texture coordinate out of bounds: %v", uv)
+					}
+				}
+				area := atlasTriangleArea(uvs)
+				expected := tri.Area() * atlas.Scale * atlas.Scale
+				if math.Abs(area-expected) > 1e-8 {
+					t.Fatalf("expected area %f but got %f", expected, area)
+				}
+				uvArea += area
+			}
+			if uvArea < 0.2 {
+				t.Errorf("atlas is too sparse: %f", uvArea)
+			}
+
+			// No texture coordinate should be covered twice.
+			for i := 0; i < 1000; i++ {
+				uv := model2d.XY(rand.Float64(), rand.Float64())
+				var count int
+				for _, uvs := range atlas.UV {
+					if _, ok := atlasBarycentric2D(uvs, uv); ok {
+						count++
+					}
+				}
+				if count > 1 {
+					t.Fatalf("texture coordinate %v is covered %d times", uv, count)
+				}
+			}
+		})
+	}
+}
+
+func TestAtlasSurfacePoint(t *testing.T) {
+	mesh := NewMeshIcosphere(XYZ(1, 2, 3), 1, 2)
+	atlas := PackAtlas(PlanarAtlasCharts(mesh, 1e-5), 0.01)
+	for _, tri := range mesh.TriangleSlice() {
+		w1, w2 := rand.Float64(), rand.Float64()
+		if w1+w2 > 1 {
+			w1, w2 = 1-w1, 1-w2
+		}
+		point := tri[0].Add(tri[1].Sub(tri[0]).Scale(w1)).Add(tri[2].Sub(tri[0]).Scale(w2))
+		uv := atlas.TextureCoord(tri, point)
+		actualTri, actualPoint, ok := atlas.SurfacePoint(uv)
+		if !ok {
+			t.Fatalf("no triangle found for %v", uv)
+		}
+		if actualTri != tri || actualPoint.Dist(point) > 1e-8 {
+			t.Fatalf("expected %v but got %v", point, actualPoint)
+		}
+	}
+	if _, _, ok := atlas.SurfacePoint(model2d.XY(0.001, 0.001)); ok {
+		t.Error("expected no triangle in the padding")
+	}
+}
+
+func atlasTriangleArea(t [3]model2d.Coord) float64 {
+	v1, v2 := t[1].Sub(t[0]), t[2].Sub(t[0])
+	return math.Abs(v1.X*v2.Y-v1.Y*v2.X) / 2
+}
+
+func TestPackAtlasTooMuchPadding(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for excessive padding")
+		}
+	}()
+	mesh := NewMeshRect(Coord3D{}, XYZ(3, 2, 1))
+	PackAtlas(PlanarAtlasCharts(mesh, 1e-5), 0.4)
+}
+
+func TestAtlasAttributedMesh(t *testing.T) {
+	mesh := NewMeshRect(Coord3D{}, XYZ(3, 2, 1))
+	atlas := PackAtlas(PlanarAtlasCharts(mesh, 1e-5), 0.01)
+	attrMesh := atlas.AttributedMesh(mesh)
+	mesh.Iterate(func(tri *Triangle) {
+		for i := range tri {
+			uv, ok := attrMesh.Attributes.UV(tri, i)
+			if !ok || uv != atlas.UV[tri][i] {
+				t.Fatalf("unexpected UV %v (expected %v)", uv, atlas.UV[tri][i])
+			}
+		}
+	})
+}
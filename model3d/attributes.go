@@ -0,0 +1,382 @@
+package model3d
+
+import (
+	"bytes"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/fileformats"
+	"github.com/unixpickle/model3d/model2d"
+)
+
+// A MeshCorner identifies one vertex of a triangle, where
+// Index is 0, 1, or 2.
+type MeshCorner struct {
+	Triangle *Triangle
+	Index    int
+}
+
+// MeshAttributes stores optional per-vertex data for the
+// triangles of a mesh, such as texture coordinates,
+// normals, and colors.
+//
+// Attributes can be stored per vertex, in which case all
+// of the triangles touching a vertex share the same value,
+// or per corner, which allows seams in texture coordinates
+// and hard edges in normals.
+// Corner attributes take precedence over vertex attributes.
+//
+// Any of the maps may be nil if the attribute is unused.
+type MeshAttributes struct {
+	UVs     map[Coord3D]model2d.Coord
+	Normals map[Coord3D]Coord3D
+	Colors  map[Coord3D][3]float64
+
+	CornerUVs     map[MeshCorner]model2d.Coord
+	CornerNormals map[MeshCorner]Coord3D
+	CornerColors  map[MeshCorner][3]float64
+}
+
+// NewMeshAttributes creates an empty set of attributes,
+// where all of the maps are allocated.
+func NewMeshAttributes() *MeshAttributes {
+	return &MeshAttributes{
+		UVs:     map[Coord3D]model2d.Coord{},
+		Normals: map[Coord3D]Coord3D{},
+		Colors:  map[Coord3D][3]float64{},
+
+		CornerUVs:     map[MeshCorner]model2d.Coord{},
+		CornerNormals: map[MeshCorner]Coord3D{},
+		CornerColors:  map[MeshCorner][3]float64{},
+	}
+}
+
+// UV gets the texture coordinate for the i-th vertex of t,
+// or returns false if there is none.
+func (m *MeshAttributes) UV(t *Triangle, i int) (model2d.Coord, bool) {
+	if uv, ok := m.CornerUVs[MeshCorner{t, i}]; ok {
+		return uv, true
+	}
+	uv, ok := m.UVs[t[i]]
+	return uv, ok
+}
+
+// Normal gets the normal for the i-th vertex of t, or
+// returns false if there is none.
+func (m *MeshAttributes) Normal(t *Triangle, i int) (Coord3D, bool) {
+	if n, ok := m.CornerNormals[MeshCorner{t, i}]; ok {
+		return n, true
+	}
+	n, ok := m.Normals[t[i]]
+	return n, ok
+}
+
+// Color gets the RGB color for the i-th vertex of t, or
+// returns false if there is none.
+func (m *MeshAttributes) Color(t *Triangle, i int) ([3]float64, bool) {
+	if c, ok := m.CornerColors[MeshCorner{t, i}]; ok {
+		return c, true
+	}
+	c, ok := m.Colors[t[i]]
+	return c, ok
+}
+
+// HasUVs checks if any texture coordinates are stored.
+func (m *MeshAttributes) HasUVs() bool {
+	return len(m.UVs) > 0 || len(m.CornerUVs) > 0
+}
+
+// HasNormals checks if any normals are stored.
+func (m *MeshAttributes) HasNormals() bool {
+	return len(m.Normals) > 0 || len(m.CornerNormals) > 0
+}
+
+// HasColors checks if any colors are stored.
+func (m *MeshAttributes) HasColors() bool {
+	return len(m.Colors) > 0 || len(m.CornerColors) > 0
+}
+
+// An AttributedMesh is a mesh with per-vertex attributes.
+//
+// Mapping the mesh with MapCoords carries the attributes
+// over to the resulting triangles, so that textured models
+// can be transformed and then exported.
+type AttributedMesh struct {
+	Mesh       *Mesh
+	Attributes *MeshAttributes
+}
+
+// NewAttributedMesh creates an AttributedMesh for m with
+// no attributes.
+func NewAttributedMesh(m *Mesh) *AttributedMesh {
+	return &AttributedMesh{Mesh: m, Attributes: NewMeshAttributes()}
+}
+
+// NewAttributedMeshNormals creates an AttributedMesh for m
+// with normals from m.SmoothNormals(maxAngle).
+//
+// Vertices in a single smoothing group get vertex normals,
+// while vertices on sharp edges get corner normals.
+func NewAttributedMeshNormals(m *Mesh, maxAngle float64) *AttributedMesh {
+	res := NewAttributedMesh(m)
+	corners := m.SmoothNormals(maxAngle)
+	split := map[Coord3D]bool{}
+	for corner, n := range corners {
+		c := corner.Triangle[corner.Index]
+		if n1, ok := res.Attributes.Normals[c]; !ok {
+			res.Attributes.Normals[c] = n
+		} else if n1 != n {
+			split[c] = true
+		}
+	}
+	for corner, n := range corners {
+		if c := corner.Triangle[corner.Index]; split[c] {
+			delete(res.Attributes.Normals, c)
+			res.Attributes.CornerNormals[corner] = n
+		}
+	}
+	return res
+}
+
+// MapCoords creates a new attributed mesh by transforming
+// all of the coordinates according to f.
+//
+// Texture coordinates and colors are copied as-is, while
+// normals are transformed by the local linear
+// approximation of f, so that they remain perpendicular
+// to the surface.
+func (a *AttributedMesh) MapCoords(f func(Coord3D) Coord3D) *AttributedMesh {
+	attrs := a.Attributes
+	res := &AttributedMesh{Mesh: NewMesh(), Attributes: NewMeshAttributes()}
+
+	epsilon := 1e-6
+	if len(a.Mesh.faces) > 0 {
+		epsilon *= math.Max(1, a.Mesh.Max().Sub(a.Mesh.Min()).Norm())
+	}
+	mapNormal := func(c, n Coord3D) Coord3D {
+		b1, b2 := n.OrthoBasis()
+		t1 := f(c.Add(b1.Scale(epsilon))).Sub(f(c.Sub(b1.Scale(epsilon))))
+		t2 := f(c.Add(b2.Scale(epsilon))).Sub(f(c.Sub(b2.Scale(epsilon))))
+		n1 := t1.Cross(t2)
+		if n1.Norm() == 0 {
+			return n
+		}
+		if b1.Cross(b2).Dot(n) < 0 {
+			n1 = n1.Scale(-1)
+		}
+		return n1.Normalize()
+	}
+
+	mapping := NewCoordToCoord()
+	a.Mesh.Iterate(func(t *Triangle) {
+		t1 := *t
+		for i, p := range t {
+			p1, ok := mapping.Load(p)
+			if !ok {
+				p1 = f(p)
+				mapping.Store(p, p1)
+				if uv, ok := attrs.UVs[p]; ok {
+					res.Attributes.UVs[p1] = uv
+				}
+				if n, ok := attrs.Normals[p]; ok {
+					res.Attributes.Normals[p1] = mapNormal(p, n)
+				}
+				if c, ok := attrs.Colors[p]; ok {
+					res.Attributes.Colors[p1] = c
+				}
+			}
+			t1[i] = p1
+		}
+		for i, p := range t {
+			corner := MeshCorner{t, i}
+			corner1 := MeshCorner{&t1, i}
+			if uv, ok := attrs.CornerUVs[corner]; ok {
+				res.Attributes.CornerUVs[corner1] = uv
+			}
+			if n, ok := attrs.CornerNormals[corner]; ok {
+				res.Attributes.CornerNormals[corner1] = mapNormal(p, n)
+			}
+			if c, ok := attrs.CornerColors[corner]; ok {
+				res.Attributes.CornerColors[corner1] = c
+			}
+		}
+		res.Mesh.Add(&t1)
+	})
+	return res
+}
+
+// Transform applies t to the coordinates.
+func (a *AttributedMesh) Transform(t Transform) *AttributedMesh {
+	return a.MapCoords(t.Apply)
+}
+
+// BuildOBJ creates an OBJ file for the mesh, including
+// texture coordinates and normals if they are present.
+//
+// Each distinct texture coordinate and normal is stored
+// once.
+// Corners without a texture coordinate use (0, 0), and
+// corners without a normal use the triangle's normal.
+func (a *AttributedMesh) BuildOBJ() *fileformats.OBJFile {
+	res := &fileformats.OBJFile{}
+	group := &fileformats.OBJFileFaceGroup{}
+	res.FaceGroups = []*fileformats.OBJFileFaceGroup{group}
+
+	coordToIdx := NewCoordToInt()
+	uvToIdx := map[model2d.Coord]int{}
+	normalToIdx := NewCoordToInt()
+	hasUVs := a.Attributes.HasUVs()
+	hasNormals := a.Attributes.HasNormals()
+
+	a.Mesh.IterateSorted(func(t *Triangle) {
+		var face [3][3]int
+		for i, p := range t {
+			idx, ok := coordToIdx.Load(p)
+			if !ok {
+				idx = len(res.Vertices)
+				coordToIdx.Store(p, idx)
+				res.Vertices = append(res.Vertices, p.Array())
+			}
+			face[i][0] = idx + 1
+			if hasUVs {
+				uv, _ := a.Attributes.UV(t, i)
+				idx, ok := uvToIdx[uv]
+				if !ok {
+					idx = len(res.UVs)
+					uvToIdx[uv] = idx
+					res.UVs = append(res.UVs, uv.Array())
+				}
+				face[i][1] = idx + 1
+			}
+			if hasNormals {
+				n, ok := a.Attributes.Normal(t, i)
+				if !ok {
+					n = t.Normal()
+				}
+				idx, ok := normalToIdx.Load(n)
+				if !ok {
+					idx = len(res.Normals)
+					normalToIdx.Store(n, idx)
+					res.Normals = append(res.Normals, n.Array())
+				}
+				face[i][2] = idx + 1
+			}
+		}
+		group.Faces = append(group.Faces, face)
+	}, attributedMeshTriangleLess)
+	return res
+}
+
+// WriteOBJ writes the mesh as an OBJ file, including
+// texture coordinates and normals.
+func (a *AttributedMesh) WriteOBJ(w io.Writer) error {
+	if err := a.BuildOBJ().Write(w); err != nil {
+		return errors.Wrap(err, "write OBJ")
+	}
+	return nil
+}
+
+// EncodeOBJ encodes the mesh as an OBJ file.
+func (a *AttributedMesh) EncodeOBJ() []byte {
+	var buf bytes.Buffer
+	a.WriteOBJ(&buf)
+	return buf.Bytes()
+}
+
+// SaveOBJ saves the mesh as an OBJ file.
+func (a *AttributedMesh) SaveOBJ(path string) error {
+	return saveToFile(path, a.WriteOBJ)
+}
+
+// WritePLY writes the mesh as a PLY file, including
+// texture coordinates, normals, and colors.
+//
+// Since PLY files only support per-vertex attributes, a
+// vertex is duplicated for each distinct combination of
+// attributes at its corners.
+// Colors in the range [0, 1] are converted to 24-bit RGB,
+// and vertices without colors are written as white.
+func (a *AttributedMesh) WritePLY(w io.Writer) error {
+	attrs := a.Attributes
+	props := fileformats.PLYVertexProperties{
+		Normals: attrs.HasNormals(),
+		UVs:     attrs.HasUVs(),
+	}
+
+	var vertices []*fileformats.PLYVertex
+	var faces [][3]int
+	vertexToIdx := map[fileformats.PLYVertex]int{}
+	a.Mesh.IterateSorted(func(t *Triangle) {
+		var face [3]int
+		for i, p := range t {
+			v := fileformats.PLYVertex{Coord: p.Array(), Color: [3]uint8{255, 255, 255}}
+			if c, ok := attrs.Color(t, i); ok {
+				for j, x := range c {
+					v.Color[j] = uint8(math.Round(math.Max(0, math.Min(1, x)) * 255))
+				}
+			}
+			if props.Normals {
+				n, ok := attrs.Normal(t, i)
+				if !ok {
+					n = t.Normal()
+				}
+				v.Normal = n.Array()
+			}
+			if props.UVs {
+				uv, _ := attrs.UV(t, i)
+				v.UV = uv.Array()
+			}
+			idx, ok := vertexToIdx[v]
+			if !ok {
+				idx = len(vertices)
+				vertexToIdx[v] = idx
+				vertices = append(vertices, &v)
+			}
+			face[i] = idx
+		}
+		faces = append(faces, face)
+	}, attributedMeshTriangleLess)
+
+	p, err := fileformats.NewPLYWriterProperties(w, len(vertices), len(faces), props)
+	if err != nil {
+		return err
+	}
+	for _, v := range vertices {
+		if err := p.WriteVertex(v); err != nil {
+			return err
+		}
+	}
+	for _, f := range faces {
+		if err := p.WriteTriangle(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodePLY encodes the mesh as a PLY file.
+func (a *AttributedMesh) EncodePLY() []byte {
+	var buf bytes.Buffer
+	a.WritePLY(&buf)
+	return buf.Bytes()
+}
+
+// SavePLY saves the mesh as a PLY file.
+func (a *AttributedMesh) SavePLY(path string) error {
+	return saveToFile(path, a.WritePLY)
+}
+
+// attributedMeshTriangleLess orders triangles so that
+// exports are deterministic.
+func attributedMeshTriangleLess(t1, t2 *Triangle) bool {
+	for i := range t1 {
+		a1, a2 := t1[i].Array(), t2[i].Array()
+		for j := range a1 {
+			if a1[j] != a2[j] {
+				return a1[j] < a2[j]
+			}
+		}
+	}
+	return false
+}
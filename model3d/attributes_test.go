@@ -0,0 +1,126 @@
+package model3d
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+func testAttributedBox() *AttributedMesh {
+	mesh := NewMeshRect(XYZ(-1, -1, -1), XYZ(1, 1, 1))
+	res := NewAttributedMesh(mesh)
+	mesh.IterateVertices(func(c Coord3D) {
+		res.Attributes.Normals[c] = c.Normalize()
+		res.Attributes.UVs[c] = model2d.XY(c.X, c.Y)
+	})
+	mesh.Iterate(func(t *Triangle) {
+		if t.Normal().Z > 0.5 {
+			for i := range t {
+				res.Attributes.CornerColors[MeshCorner{t, i}] = [3]float64{1, 0, 0}
+			}
+		}
+	})
+	return res
+}
+
+func TestAttributedMeshMapCoords(t *testing.T) {
+	box := testAttributedBox()
+	mapped := box.MapCoords(func(c Coord3D) Coord3D {
+		return XYZ(c.X*2, c.Y, c.Z+1)
+	})
+	attrs := mapped.Attributes
+	if len(attrs.Normals) != 8 || len(attrs.UVs) != 8 || len(attrs.CornerColors) != 6 {
+		t.Fatalf("unexpected attribute counts: %d, %d, %d", len(attrs.Normals),
+			len(attrs.UVs), len(attrs.CornerColors))
+	}
+	mapped.Mesh.Iterate(func(tri *Triangle) {
+		for i, c := range tri {
+			uv, ok := attrs.UV(tri, i)
+			if !ok || uv != model2d.XY(c.X/2, c.Y) {
+				t.Fatalf("unexpected UV %v at %v", uv, c)
+			}
+			n, ok := attrs.Normal(tri, i)
+			expected := XYZ(c.X/4, c.Y, c.Z-1).Normalize()
+			if !ok || n.Dist(expected) > 1e-5 {
+				t.Fatalf("expected normal %v but got %v", expected, n)
+			}
+			color, ok := attrs.Color(tri, i)
+			if ok != (tri.Normal().Z > 0.5) || (ok && color != [3]float64{1, 0, 0}) {
+				t.Fatalf("unexpected color %v (%v) for triangle %v", color, ok, tri)
+			}
+		}
+	})
+}
+
+func TestAttributedMeshOBJ(t *testing.T) {
+	box := testAttributedBox()
+	data := string(box.EncodeOBJ())
+	counts := map[string]int{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			counts[fields[0]]++
+		}
+		if len(fields) > 0 && fields[0] == "f" {
+			for _, f := range fields[1:] {
+				if len(strings.Split(f, "/")) != 3 {
+					t.Fatalf("unexpected face: %s", line)
+				}
+			}
+		}
+	}
+	if counts["v"] != 8 || counts["vn"] != 8 || counts["vt"] != 4 || counts["f"] != 12 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+
+	tris, err := ReadOBJ(bytes.NewReader([]byte(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := NewMeshTriangles(tris).Volume(); math.Abs(v-8) > 1e-5 {
+		t.Errorf("unexpected volume: %f", v)
+	}
+}
+
+func TestAttributedMeshPLY(t *testing.T) {
+	box := testAttributedBox()
+	data := string(box.EncodePLY())
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	for _, prop := range []string{"nx", "ny", "nz", "s", "t", "red"} {
+		if !strings.Contains(data, "property float "+prop+"\n") &&
+			!strings.Contains(data, "property uchar "+prop+"\n") {
+			t.Errorf("missing property: %s", prop)
+		}
+	}
+
+	// The four top vertices are duplicated, since they are
+	// only colored on the top face.
+	if !strings.Contains(data, "element vertex 12\n") {
+		t.Error("unexpected vertex count")
+	}
+	var headerEnd int
+	for i, line := range lines {
+		if line == "end_header" {
+			headerEnd = i
+		}
+	}
+	if len(lines)-headerEnd-1 != 12+12 {
+		t.Errorf("unexpected line count: %d", len(lines)-headerEnd-1)
+	}
+	var numRed int
+	for _, line := range lines[headerEnd+1 : headerEnd+13] {
+		fields := strings.Fields(line)
+		if len(fields) != 11 {
+			t.Fatalf("unexpected vertex line: %s", line)
+		}
+		if strings.Join(fields[8:], " ") == "255 0 0" {
+			numRed++
+		}
+	}
+	if numRed != 4 {
+		t.Errorf("expected 4 red vertices but got %d", numRed)
+	}
+}
@@ -0,0 +1,389 @@
+package model3d
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	DefaultBVHBuilderMaxLeafSize   = 4
+	DefaultBVHBuilderNumBins       = 16
+	DefaultBVHBuilderTraversalCost = 1.0
+
+	// bvhBuilderParallelSize is the smallest number of
+	// triangles for which subtrees are built concurrently.
+	bvhBuilderParallelSize = 1 << 14
+)
+
+// A BVHBuilder constructs a BVH of triangles using the
+// surface area heuristic (SAH).
+//
+// The SAH estimates the cost of casting a random ray
+// against a node as the cost of visiting the node, plus
+// the cost of testing each child in proportion to the
+// probability that the ray hits the child's bounding box.
+// Splits are chosen by evaluating this cost at a fixed
+// number of bins along each axis, making construction
+// fast enough for meshes with millions of triangles.
+//
+// Leaves with more than one triangle are represented as
+// branches whose children are all single-triangle leaves,
+// so the resulting BVH works anywhere a BVH is accepted,
+// such as BVHToCollider() and BVHToSDF().
+type BVHBuilder struct {
+	// MaxLeafSize is the maximum number of triangles in a
+	// leaf.
+	// Leaves may be smaller if the SAH suggests that
+	// splitting them further is cheaper.
+	//
+	// If 0, DefaultBVHBuilderMaxLeafSize is used.
+	MaxLeafSize int
+
+	// NumBins is the number of candidate split positions
+	// evaluated along each axis, at each node.
+	//
+	// If 0, DefaultBVHBuilderNumBins is used.
+	NumBins int
+
+	// TraversalCost is the cost of visiting a node,
+	// relative to the cost of testing a ray against one
+	// triangle.
+	// Larger values produce shallower trees with larger
+	// leaves.
+	//
+	// If 0, DefaultBVHBuilderTraversalCost is used.
+	TraversalCost float64
+}
+
+// Build creates a BVH for the triangles.
+//
+// The triangles must not be empty.
+func (b *BVHBuilder) Build(tris []*Triangle) *BVH {
+	if len(tris) == 0 {
+		panic("cannot build BVH without triangles")
+	}
+	prims := make([]bvhPrim, len(tris))
+	for i, t := range tris {
+		min, max := t.Min(), t.Max()
+		prims[i] = bvhPrim{Triangle: t, Min: min, Max: max, Mid: min.Mid(max)}
+	}
+	return b.build(prims)
+}
+
+// BuildMesh is like Build, but for the triangles of a
+// mesh.
+func (b *BVHBuilder) BuildMesh(m *Mesh) *BVH {
+	return b.Build(m.TriangleSlice())
+}
+
+func (b *BVHBuilder) build(prims []bvhPrim) *BVH {
+	if len(prims) == 1 {
+		return &BVH{Leaf: prims[0].Triangle}
+	}
+
+	min, max := prims[0].Min, prims[0].Max
+	midMin, midMax := prims[0].Mid, prims[0].Mid
+	for _, p := range prims[1:] {
+		min = min.Min(p.Min)
+		max = max.Max(p.Max)
+		midMin = midMin.Min(p.Mid)
+		midMax = midMax.Max(p.Mid)
+	}
+
+	axis, splitBin, cost := b.bestSplit(prims, boundsArea(min, max), midMin, midMax)
+	leafSize := b.maxLeafSize()
+	if len(prims) <= leafSize && cost >= float64(len(prims)) {
+		return bvhLeafNode(prims)
+	}
+
+	var idx int
+	if axis < 0 {
+		// All of the centroids coincide, so there is no
+		// meaningful split and we divide arbitrarily.
+		if len(prims) <= leafSize {
+			return bvhLeafNode(prims)
+		}
+		idx = len(prims) / 2
+	} else {
+		idx = b.partition(prims, axis, splitBin, midMin, midMax)
+	}
+
+	res := &BVH{Branch: make([]*BVH, 2)}
+	if len(prims) >= bvhBuilderParallelSize {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.Branch[0] = b.build(prims[:idx])
+		}()
+		res.Branch[1] = b.build(prims[idx:])
+		wg.Wait()
+	} else {
+		res.Branch[0] = b.build(prims[:idx])
+		res.Branch[1] = b.build(prims[idx:])
+	}
+	return res
+}
+
+// bestSplit finds the axis and bin which minimize the SAH
+// cost, where the cost of a leaf is the number of
+// triangles in it.
+// Triangles in the returned bin and the bins before it
+// are on the first side of the split.
+//
+// If no split separates the triangles, the axis is -1.
+func (b *BVHBuilder) bestSplit(prims []bvhPrim, area float64,
+	midMin, midMax Coord3D) (axis, splitBin int, cost float64) {
+	numBins := b.numBins()
+	traversal := b.traversalCost()
+
+	axis = -1
+	cost = math.Inf(1)
+	if area == 0 {
+		// Every split is free for flat bounds.
+		area = 1
+	}
+
+	counts := make([]int, numBins)
+	mins := make([]Coord3D, numBins)
+	maxes := make([]Coord3D, numBins)
+	rightAreas := make([]float64, numBins)
+	for ax := 0; ax < 3; ax++ {
+		lo, hi := bvhAxis(midMin, ax), bvhAxis(midMax, ax)
+		if hi <= lo {
+			continue
+		}
+		for i := range counts {
+			counts[i] = 0
+		}
+		scale := float64(numBins) / (hi - lo)
+		for _, p := range prims {
+			bin := bvhBin(bvhAxis(p.Mid, ax), lo, scale, numBins)
+			if counts[bin] == 0 {
+				mins[bin], maxes[bin] = p.Min, p.Max
+			} else {
+				mins[bin] = mins[bin].Min(p.Min)
+				maxes[bin] = maxes[bin].Max(p.Max)
+			}
+			counts[bin]++
+		}
+
+		// Sweep from the right to compute the area of
+		// everything after each split.
+		var rightMin, rightMax Coord3D
+		rightCount := 0
+		for i := numBins - 1; i > 0; i-- {
+			if counts[i] > 0 {
+				if rightCount == 0 {
+					rightMin, rightMax = mins[i], maxes[i]
+				} else {
+					rightMin = rightMin.Min(mins[i])
+					rightMax = rightMax.Max(maxes[i])
+				}
+				rightCount += counts[i]
+			}
+			rightAreas[i] = boundsArea(rightMin, rightMax) * float64(rightCount)
+		}
+
+		var leftMin, leftMax Coord3D
+		leftCount := 0
+		for i := 0; i < numBins-1; i++ {
+			if counts[i] > 0 {
+				if leftCount == 0 {
+					leftMin, leftMax = mins[i], maxes[i]
+				} else {
+					leftMin = leftMin.Min(mins[i])
+					leftMax = leftMax.Max(maxes[i])
+				}
+				leftCount += counts[i]
+			}
+			if leftCount == 0 || leftCount == len(prims) {
+				continue
+			}
+			c := traversal + (boundsArea(leftMin, leftMax)*float64(leftCount)+rightAreas[i+1])/area
+			if c < cost {
+				axis = ax
+				splitBin = i
+				cost = c
+			}
+		}
+	}
+	return
+}
+
+func (b *BVHBuilder) maxLeafSize() int {
+	if b.MaxLeafSize == 0 {
+		return DefaultBVHBuilderMaxLeafSize
+	}
+	return b.MaxLeafSize
+}
+
+func (b *BVHBuilder) numBins() int {
+	if b.NumBins == 0 {
+		return DefaultBVHBuilderNumBins
+	}
+	return b.NumBins
+}
+
+func (b *BVHBuilder) traversalCost() float64 {
+	if b.TraversalCost == 0 {
+		return DefaultBVHBuilderTraversalCost
+	}
+	return b.TraversalCost
+}
+
+type bvhPrim struct {
+	Triangle *Triangle
+	Min      Coord3D
+	Max      Coord3D
+	Mid      Coord3D
+}
+
+func bvhAxis(c Coord3D, axis int) float64 {
+	switch axis {
+	case 0:
+		return c.X
+	case 1:
+		return c.Y
+	default:
+		return c.Z
+	}
+}
+
+func bvhBin(value, lo, scale float64, numBins int) int {
+	bin := int((value - lo) * scale)
+	if bin >= numBins {
+		return numBins - 1
+	} else if bin < 0 {
+		return 0
+	}
+	return bin
+}
+
+// partition reorders prims so that those on the first
+// side of a split from bestSplit come first, and returns
+// the number of such prims.
+func (b *BVHBuilder) partition(prims []bvhPrim, axis, splitBin int, midMin, midMax Coord3D) int {
+	numBins := b.numBins()
+	lo, hi := bvhAxis(midMin, axis), bvhAxis(midMax, axis)
+	scale := float64(numBins) / (hi - lo)
+	i, j := 0, len(prims)-1
+	for i <= j {
+		if bvhBin(bvhAxis(prims[i].Mid, axis), lo, scale, numBins) <= splitBin {
+			i++
+		} else {
+			prims[i], prims[j] = prims[j], prims[i]
+			j--
+		}
+	}
+	return i
+}
+
+func bvhLeafNode(prims []bvhPrim) *BVH {
+	res := &BVH{Branch: make([]*BVH, len(prims))}
+	for i, p := range prims {
+		res.Branch[i] = &BVH{Leaf: p.Triangle}
+	}
+	return res
+}
+
+// BVHStats summarizes the structure of a BVH.
+//
+// A leaf is either a single-triangle node, or a branch
+// whose children are all single-triangle nodes, as
+// produced by BVHBuilder.
+type BVHStats struct {
+	NumTriangles int
+	NumBranches  int
+	NumLeaves    int
+	MaxLeafSize  int
+	MaxDepth     int
+
+	// MeanLeafDepth is the average depth of the leaves,
+	// where the root is at depth 0.
+	MeanLeafDepth float64
+
+	// SAHCost is the expected cost of casting a ray
+	// through the BVH, measured in triangle tests,
+	// assuming each visited branch costs one test.
+	SAHCost float64
+}
+
+// MeanLeafSize gets the average number of triangles per
+// leaf.
+func (b *BVHStats) MeanLeafSize() float64 {
+	return float64(b.NumTriangles) / float64(b.NumLeaves)
+}
+
+// Stats computes statistics about the BVH.
+func (b *BVH) Stats() *BVHStats {
+	res := &BVHStats{}
+	min, max := b.bounds()
+	rootArea := boundsArea(min, max)
+	var depthSum int
+	var walk func(node *BVH, depth int) (Coord3D, Coord3D)
+	walk = func(node *BVH, depth int) (Coord3D, Coord3D) {
+		if depth > res.MaxDepth {
+			res.MaxDepth = depth
+		}
+		if node.Leaf != nil || node.isLeafGroup() {
+			min, max := node.bounds()
+			size := 1
+			if node.Leaf == nil {
+				size = len(node.Branch)
+			}
+			res.NumTriangles += size
+			res.NumLeaves++
+			if size > res.MaxLeafSize {
+				res.MaxLeafSize = size
+			}
+			depthSum += depth
+			res.SAHCost += float64(size) * bvhRelativeArea(min, max, rootArea)
+			if node.Leaf == nil && depth+1 > res.MaxDepth {
+				res.MaxDepth = depth + 1
+			}
+			return min, max
+		}
+		res.NumBranches++
+		min, max := walk(node.Branch[0], depth+1)
+		for _, child := range node.Branch[1:] {
+			min1, max1 := walk(child, depth+1)
+			min = min.Min(min1)
+			max = max.Max(max1)
+		}
+		res.SAHCost += bvhRelativeArea(min, max, rootArea)
+		return min, max
+	}
+	walk(b, 0)
+	res.MeanLeafDepth = float64(depthSum) / float64(res.NumLeaves)
+	return res
+}
+
+func (b *BVH) isLeafGroup() bool {
+	for _, child := range b.Branch {
+		if child.Leaf == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *BVH) bounds() (min, max Coord3D) {
+	if b.Leaf != nil {
+		return b.Leaf.Min(), b.Leaf.Max()
+	}
+	min, max = b.Branch[0].bounds()
+	for _, child := range b.Branch[1:] {
+		min1, max1 := child.bounds()
+		min = min.Min(min1)
+		max = max.Max(max1)
+	}
+	return
+}
+
+func bvhRelativeArea(min, max Coord3D, rootArea float64) float64 {
+	if rootArea == 0 {
+		return 1
+	}
+	return boundsArea(min, max) / rootArea
+}
@@ -0,0 +1,116 @@
+package model3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBVHBuilderCollider(t *testing.T) {
+	mesh := MarchingCubesSearch(sdfTestingSolid(), 0.04, 8)
+	expected := MeshToCollider(mesh)
+	for _, leafSize := range []int{1, 4, 16} {
+		builder := &BVHBuilder{MaxLeafSize: leafSize}
+		actual := BVHToCollider(builder.BuildMesh(mesh))
+		for i := 0; i < 1000; i++ {
+			ray := &Ray{
+				Origin:    NewCoord3DRandNorm(),
+				Direction: NewCoord3DRandUnit(),
+			}
+			n1 := expected.RayCollisions(ray, nil)
+			n2 := actual.RayCollisions(ray, nil)
+			if n1 != n2 {
+				t.Fatalf("leaf size %d: expected %d collisions but got %d", leafSize, n1, n2)
+			}
+			rc1, ok1 := expected.FirstRayCollision(ray)
+			rc2, ok2 := actual.FirstRayCollision(ray)
+			if ok1 != ok2 || math.Abs(rc1.Scale-rc2.Scale) > 1e-8 {
+				t.Fatalf("leaf size %d: expected first collision %v (%v) but got %v (%v)",
+					leafSize, rc1.Scale, ok1, rc2.Scale, ok2)
+			}
+
+			center := NewCoord3DRandNorm()
+			radius := rand.Float64()
+			if expected.SphereCollision(center, radius) != actual.SphereCollision(center, radius) {
+				t.Fatalf("leaf size %d: mismatched sphere collision", leafSize)
+			}
+		}
+	}
+}
+
+func TestBVHBuilderSDF(t *testing.T) {
+	mesh := MarchingCubesSearch(sdfTestingSolid(), 0.04, 8)
+	expected := MeshToSDF(mesh)
+	actual := BVHToSDF((&BVHBuilder{MaxLeafSize: 8}).BuildMesh(mesh))
+	for i := 0; i < 1000; i++ {
+		c := NewCoord3DRandNorm()
+		face1, p1, sdf1 := expected.FaceSDF(c)
+		face2, p2, sdf2 := actual.FaceSDF(c)
+		if math.Abs(sdf1-sdf2) > 1e-8 {
+			t.Fatalf("expected SDF %f but got %f", sdf1, sdf2)
+		}
+		if p1.Dist(p2) > 1e-8 && face1 != face2 {
+			t.Fatalf("expected closest point %v but got %v", p1, p2)
+		}
+	}
+}
+
+func TestBVHBuilderStats(t *testing.T) {
+	mesh := MarchingCubesSearch(sdfTestingSolid(), 0.04, 8)
+	numTris := len(mesh.TriangleSlice())
+	for _, leafSize := range []int{1, 4, 16} {
+		stats := (&BVHBuilder{MaxLeafSize: leafSize}).BuildMesh(mesh).Stats()
+		if stats.NumTriangles != numTris {
+			t.Errorf("expected %d triangles but got %d", numTris, stats.NumTriangles)
+		}
+		if stats.MaxLeafSize > leafSize && leafSize > 1 {
+			t.Errorf("leaf size %d exceeds maximum %d", stats.MaxLeafSize, leafSize)
+		}
+		if stats.NumBranches != stats.NumLeaves-1 {
+			t.Errorf("unexpected number of branches: %d", stats.NumBranches)
+		}
+		minDepth := math.Log2(float64(stats.NumLeaves))
+		if stats.MeanLeafDepth < minDepth-1 || float64(stats.MaxDepth) < minDepth {
+			t.Errorf("unexpected depth: mean=%f max=%d", stats.MeanLeafDepth, stats.MaxDepth)
+		}
+		if stats.SAHCost < 1 {
+			t.Errorf("unexpected SAH cost: %f", stats.SAHCost)
+		}
+	}
+
+	// The SAH should beat a median split on a mesh with
+	// very uneven density.
+	solid := JoinedSolid{
+		&Sphere{Radius: 0.1},
+		&Rect{MinVal: XYZ(1, -1, -1), MaxVal: XYZ(3, 1, 1)},
+	}
+	mesh = MarchingCubesSearch(solid, 0.02, 8)
+	tris := mesh.TriangleSlice()
+	GroupTriangles(tris)
+	sahStats := (&BVHBuilder{MaxLeafSize: 1}).Build(tris).Stats()
+	medianStats := groupedTrianglesToBVH(tris).Stats()
+	if sahStats.SAHCost >= medianStats.SAHCost {
+		t.Errorf("expected SAH cost %f to be less than median cost %f",
+			sahStats.SAHCost, medianStats.SAHCost)
+	}
+}
+
+func BenchmarkBVHBuilder(b *testing.B) {
+	mesh := MarchingCubesSearch(sdfTestingSolid(), 0.01, 8)
+	tris := mesh.TriangleSlice()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		(&BVHBuilder{}).Build(tris)
+	}
+}
+
+func groupedTrianglesToBVH(tris []*Triangle) *BVH {
+	if len(tris) == 1 {
+		return &BVH{Leaf: tris[0]}
+	}
+	midIdx := len(tris) / 2
+	return &BVH{Branch: []*BVH{
+		groupedTrianglesToBVH(tris[:midIdx]),
+		groupedTrianglesToBVH(tris[midIdx:]),
+	}}
+}
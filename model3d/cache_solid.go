@@ -0,0 +1,158 @@
+package model3d
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	cacheVoxelUnknown = iota
+	cacheVoxelInside
+	cacheVoxelOutside
+	cacheVoxelMixed
+)
+
+type cachedSolid struct {
+	solid      Solid
+	sdf        SDF
+	min        Coord3D
+	max        Coord3D
+	resolution float64
+
+	lock    sync.RWMutex
+	corners map[[3]int]bool
+	voxels  map[[3]int]uint8
+}
+
+// CacheSolid creates a Solid which caches the results of
+// s.Contains() on a lazily computed voxel grid, where each
+// voxel has side length resolution.
+//
+// The first time a point in a voxel is queried, s is
+// evaluated at the corners of the voxel and of its 26
+// neighbors.
+// If all of these corners agree, then every point in the
+// voxel is assumed to have the same value, and s is never
+// evaluated in the voxel again.
+// Otherwise, the voxel may be near the boundary of s, and
+// every query in it is passed to s directly, so the
+// surface is not distorted.
+//
+// If s also implements SDF, a voxel is only treated as
+// uniform when its center is at least half a voxel
+// diagonal away from the surface, so features thinner
+// than resolution are never filled in or erased.
+// Without an SDF, features of s which fit between grid
+// corners may still be missed, so the resolution should
+// be smaller than the thinnest part of the solid.
+//
+// The resulting solid is safe to use concurrently, for
+// example with MarchingCubes, if s is.
+func CacheSolid(s Solid, resolution float64) Solid {
+	sdf, _ := s.(SDF)
+	return &cachedSolid{
+		solid:      s,
+		sdf:        sdf,
+		min:        s.Min(),
+		max:        s.Max(),
+		resolution: resolution,
+		corners:    map[[3]int]bool{},
+		voxels:     map[[3]int]uint8{},
+	}
+}
+
+func (c *cachedSolid) Min() Coord3D {
+	return c.min
+}
+
+func (c *cachedSolid) Max() Coord3D {
+	return c.max
+}
+
+func (c *cachedSolid) Contains(coord Coord3D) bool {
+	if !InBounds(c, coord) {
+		return false
+	}
+	scaled := coord.Sub(c.min).Scale(1 / c.resolution)
+	voxel := [3]int{
+		int(math.Floor(scaled.X)),
+		int(math.Floor(scaled.Y)),
+		int(math.Floor(scaled.Z)),
+	}
+
+	c.lock.RLock()
+	status := c.voxels[voxel]
+	c.lock.RUnlock()
+
+	if status == cacheVoxelUnknown {
+		status = c.computeVoxel(voxel)
+	}
+
+	switch status {
+	case cacheVoxelInside:
+		return true
+	case cacheVoxelOutside:
+		return false
+	default:
+		return c.solid.Contains(coord)
+	}
+}
+
+func (c *cachedSolid) computeVoxel(voxel [3]int) uint8 {
+	status := c.uniformStatus(voxel)
+
+	c.lock.Lock()
+	c.voxels[voxel] = status
+	c.lock.Unlock()
+
+	return status
+}
+
+// uniformStatus checks the corners of the voxel and its
+// neighbors, so that a voxel next to a boundary voxel is
+// never trusted to be uniform.
+func (c *cachedSolid) uniformStatus(voxel [3]int) uint8 {
+	first := c.cornerValue([3]int{voxel[0] - 1, voxel[1] - 1, voxel[2] - 1})
+	for x := -1; x <= 2; x++ {
+		for y := -1; y <= 2; y++ {
+			for z := -1; z <= 2; z++ {
+				corner := [3]int{voxel[0] + x, voxel[1] + y, voxel[2] + z}
+				if c.cornerValue(corner) != first {
+					return cacheVoxelMixed
+				}
+			}
+		}
+	}
+	if c.sdf != nil {
+		center := c.min.Add(XYZ(
+			float64(voxel[0])+0.5,
+			float64(voxel[1])+0.5,
+			float64(voxel[2])+0.5,
+		).Scale(c.resolution))
+		if math.Abs(c.sdf.SDF(center)) < c.resolution*math.Sqrt(3)/2 {
+			return cacheVoxelMixed
+		}
+	}
+	if first {
+		return cacheVoxelInside
+	}
+	return cacheVoxelOutside
+}
+
+func (c *cachedSolid) cornerValue(corner [3]int) bool {
+	c.lock.RLock()
+	value, ok := c.corners[corner]
+	c.lock.RUnlock()
+	if ok {
+		return value
+	}
+
+	coord := c.min.Add(XYZ(float64(corner[0]), float64(corner[1]), float64(corner[2])).Scale(c.resolution))
+	value = c.solid.Contains(coord)
+
+	c.lock.Lock()
+	c.corners[corner] = value
+	c.lock.Unlock()
+
+	return value
+}
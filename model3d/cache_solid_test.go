@@ -0,0 +1,97 @@
+package model3d
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCacheSolid(t *testing.T) {
+	var numCalls int64
+	sphere := &Sphere{Radius: 1}
+	counted := FuncSolid(sphere.Min(), sphere.Max(), func(c Coord3D) bool {
+		atomic.AddInt64(&numCalls, 1)
+		return sphere.Contains(c)
+	})
+	resolution := 0.05
+	cached := CacheSolid(counted, resolution)
+
+	if cached.Min() != sphere.Min() || cached.Max() != sphere.Max() {
+		t.Error("incorrect bounds")
+	}
+
+	points := make([]Coord3D, 10000)
+	for i := range points {
+		points[i] = NewCoord3DRandBounds(sphere.Min(), sphere.Max())
+	}
+	for _, p := range points {
+		actual := cached.Contains(p)
+		expected := sphere.Contains(p)
+		// Voxels entirely outside the sphere may be crossed
+		// slightly by the curved surface.
+		if actual != expected && math.Abs(p.Norm()-1) > resolution {
+			t.Fatalf("unexpected result at %v", p)
+		}
+	}
+
+	// Repeated queries should only hit voxels near the
+	// boundary.
+	numCalls = 0
+	var numBoundary int64
+	for _, p := range points {
+		cached.Contains(p)
+		if math.Abs(p.Norm()-1) < 2*resolution*math.Sqrt(3) {
+			numBoundary++
+		}
+	}
+	if numCalls > numBoundary {
+		t.Errorf("too many calls: %d (boundary points: %d)", numCalls, numBoundary)
+	}
+
+	mesh := MarchingCubesSearch(cached, 0.04, 8)
+	MustValidateMesh(t, mesh, true)
+	if v := mesh.Volume(); math.Abs(v-4*math.Pi/3) > 0.05 {
+		t.Errorf("unexpected volume: %f", v)
+	}
+}
+
+func TestCacheSolidThinWall(t *testing.T) {
+	resolution := 0.1
+
+	// A wall thinner than one voxel which lies strictly
+	// between two planes of grid corners.
+	wall := &Rect{
+		MinVal: XYZ(0.53, 0, 0),
+		MaxVal: XYZ(0.56, 1, 1),
+	}
+	bounds := &Rect{MinVal: XYZ(0, 0, 0), MaxVal: XYZ(1, 1, 1)}
+	cached := CacheSolid(&thinWallSolid{Rect: wall, Bounds: bounds}, resolution)
+
+	for i := 0; i < 1000; i++ {
+		p := NewCoord3DRandBounds(wall.Min(), wall.Max())
+		if !cached.Contains(p) {
+			t.Fatalf("point in wall reported outside: %v", p)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		p := NewCoord3DRandBounds(bounds.Min(), bounds.Max())
+		if cached.Contains(p) != wall.Contains(p) {
+			t.Fatalf("unexpected result at %v", p)
+		}
+	}
+}
+
+// thinWallSolid is a Rect with larger bounds, so that the
+// cache grid is not aligned to the Rect itself.
+type thinWallSolid struct {
+	*Rect
+	Bounds *Rect
+}
+
+func (t *thinWallSolid) Min() Coord3D {
+	return t.Bounds.Min()
+}
+
+func (t *thinWallSolid) Max() Coord3D {
+	return t.Bounds.Max()
+}
@@ -0,0 +1,68 @@
+package model3d
+
+import "sort"
+
+// Canonicalize gets the triangles of the mesh in a
+// deterministic order which depends only on the geometry
+// of the mesh.
+//
+// Since a Mesh is unordered, the canonical order is
+// returned as a slice, which can be passed directly to
+// functions like WriteSTL or EncodeMaterialOBJ.
+// Exports of equal meshes will then be byte-for-byte
+// identical, regardless of how the meshes were built.
+//
+// See CanonicalTriangles for details on the ordering.
+func (m *Mesh) Canonicalize() []*Triangle {
+	return CanonicalTriangles(m.TriangleSlice())
+}
+
+// CanonicalTriangles sorts triangles into a deterministic
+// order which depends only on their coordinates.
+//
+// Each triangle is copied and its vertices are rotated so
+// that the smallest vertex comes first, preserving the
+// winding order and normal.
+// The triangles are then sorted lexicographically by their
+// vertices.
+// Since exporters number vertices in the order they first
+// appear, this ordering also makes vertex indices
+// deterministic.
+//
+// Negative zero coordinates are replaced with positive
+// zeros, since they would otherwise be encoded differently
+// in binary formats.
+func CanonicalTriangles(tris []*Triangle) []*Triangle {
+	res := make([]*Triangle, len(tris))
+	for i, t := range tris {
+		var c Triangle
+		for j, p := range t {
+			c[j] = XYZ(p.X+0, p.Y+0, p.Z+0)
+		}
+		c = canonicalRotation(&c)
+		res[i] = &c
+	}
+	sort.Slice(res, func(i, j int) bool {
+		t1, t2 := res[i], res[j]
+		for k := 0; k < 3; k++ {
+			if t1[k] != t2[k] {
+				return coordLess(t1[k], t2[k])
+			}
+		}
+		return false
+	})
+	return res
+}
+
+// canonicalRotation rotates the vertices of a triangle so
+// that the smallest vertex comes first, without changing
+// the winding order.
+func canonicalRotation(t *Triangle) Triangle {
+	first := 0
+	for j := 1; j < 3; j++ {
+		if coordLess(t[j], t[first]) {
+			first = j
+		}
+	}
+	return Triangle{t[first], t[(first+1)%3], t[(first+2)%3]}
+}
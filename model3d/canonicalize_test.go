@@ -0,0 +1,63 @@
+package model3d
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMeshCanonicalize(t *testing.T) {
+	base := NewMeshIcosphere(XYZ(0, 0, 0), 1, 2)
+	tris := base.TriangleSlice()
+
+	var prevSTL, prevOBJ []byte
+	for i := 0; i < 5; i++ {
+		rand.Shuffle(len(tris), func(i, j int) {
+			tris[i], tris[j] = tris[j], tris[i]
+		})
+		mesh := NewMesh()
+		for _, t := range tris {
+			// Rotate the vertices to change the ordering
+			// without changing the normal.
+			shift := rand.Intn(3)
+			mesh.Add(&Triangle{t[shift], t[(shift+1)%3], t[(shift+2)%3]})
+		}
+		canonical := mesh.Canonicalize()
+		if len(canonical) != len(tris) {
+			t.Fatalf("expected %d triangles but got %d", len(tris), len(canonical))
+		}
+		for _, tri := range canonical {
+			if len(base.Find(tri[0], tri[1], tri[2])) != 1 {
+				t.Fatal("unexpected triangle")
+			}
+		}
+		stl := EncodeSTL(canonical)
+		obj := EncodeMaterialOBJ(canonical, func(t *Triangle) [3]float64 {
+			return [3]float64{1, 0, 0}
+		})
+		if i > 0 {
+			if !bytes.Equal(stl, prevSTL) {
+				t.Error("STL encodings differ")
+			}
+			if !bytes.Equal(obj, prevOBJ) {
+				t.Error("OBJ encodings differ")
+			}
+		}
+		prevSTL, prevOBJ = stl, obj
+	}
+}
+
+func TestCanonicalTrianglesWinding(t *testing.T) {
+	tri := &Triangle{XYZ(1, 0, 0), XYZ(0, 1, 0), XYZ(0, 0, math.Copysign(0, -1))}
+	res := CanonicalTriangles([]*Triangle{tri})[0]
+	if res[0] != XYZ(0, 0, 0) || math.Signbit(res[0].Z) {
+		t.Errorf("unexpected first vertex: %v", res[0])
+	}
+	if res.Normal().Dist(tri.Normal()) > 1e-8 {
+		t.Errorf("normal changed from %v to %v", tri.Normal(), res.Normal())
+	}
+	if tri[2].Z != 0 || !math.Signbit(tri[2].Z) {
+		t.Error("input triangle was modified")
+	}
+}
@@ -135,12 +135,49 @@ func ColliderContains(c Collider, coord Coord3D, margin float64) bool {
 
 // MeshToCollider creates an efficient MultiCollider out
 // of a mesh.
+//
+// For more control over the hierarchy, or for faster ray
+// casting on large meshes, use a BVHBuilder with
+// BVHToCollider().
 func MeshToCollider(m *Mesh) MultiCollider {
 	tris := m.TriangleSlice()
 	GroupTriangles(tris)
 	return GroupedTrianglesToCollider(tris)
 }
 
+// ColliderTriangles gets the triangles of a Collider which
+// is made up entirely of triangles, such as a Collider from
+// MeshToCollider.
+//
+// If the Collider contains anything other than triangles,
+// false is returned.
+func ColliderTriangles(c Collider) ([]*Triangle, bool) {
+	var res []*Triangle
+	var walk func(c Collider) bool
+	walk = func(c Collider) bool {
+		switch c := c.(type) {
+		case *Triangle:
+			res = append(res, c)
+		case nullCollider:
+		case joinedMultiCollider:
+			return walk(c.JoinedCollider)
+		case *JoinedCollider:
+			for _, c1 := range c.colliders {
+				if !walk(c1) {
+					return false
+				}
+			}
+		default:
+			return false
+		}
+		return true
+	}
+	if !walk(c) {
+		return nil, false
+	}
+	return res, true
+}
+
 // GroupedTrianglesToCollider converts a mesh of triangles
 // into a MultiCollider.
 //
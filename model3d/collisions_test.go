@@ -364,6 +364,25 @@ func (c *combinedSolidColliderSDF) SDF(coord Coord3D) float64 {
 	return c.InternalSDF.SDF(coord)
 }
 
+func TestColliderTriangles(t *testing.T) {
+	mesh := NewMeshIcosphere(Coord3D{}, 1, 3)
+	tris, ok := ColliderTriangles(MeshToCollider(mesh))
+	if !ok {
+		t.Fatal("expected triangles from mesh collider")
+	}
+	if len(tris) != len(mesh.TriangleSlice()) {
+		t.Errorf("expected %d triangles but got %d", len(mesh.TriangleSlice()), len(tris))
+	}
+	for _, tri := range tris {
+		if !mesh.Contains(tri) {
+			t.Fatal("unexpected triangle")
+		}
+	}
+	if _, ok := ColliderTriangles(&Sphere{Radius: 1}); ok {
+		t.Error("expected no triangles from sphere")
+	}
+}
+
 func BenchmarkMeshToCollider(b *testing.B) {
 	mesh := NewMeshPolar(func(g GeoCoord) float64 {
 		return 1
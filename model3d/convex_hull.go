@@ -0,0 +1,257 @@
+package model3d
+
+import (
+	"math"
+)
+
+// ConvexHull computes the smallest convex mesh containing
+// all of the points.
+//
+// The resulting mesh is closed and its normals point
+// outward.
+// If the points are all coplanar, there is no hull with
+// non-zero volume and an empty mesh is returned.
+func ConvexHull(points []Coord3D) *Mesh {
+	points = uniqueCoords(points)
+	if len(points) < 4 {
+		return NewMesh()
+	}
+	min, max := points[0], points[0]
+	for _, p := range points[1:] {
+		min = min.Min(p)
+		max = max.Max(p)
+	}
+	epsilon := max.Dist(min) * 1e-10
+
+	hull := newConvexHull(points, epsilon)
+	if hull == nil {
+		return NewMesh()
+	}
+	hull.Expand()
+	return hull.Mesh()
+}
+
+func uniqueCoords(points []Coord3D) []Coord3D {
+	seen := make(map[Coord3D]bool, len(points))
+	res := make([]Coord3D, 0, len(points))
+	for _, p := range points {
+		if !seen[p] {
+			seen[p] = true
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+type convexHullFace struct {
+	Vertices [3]int
+	Plane    *Plane
+	Removed  bool
+
+	// Outside contains points which are in front of the
+	// face and have not been added to the hull.
+	Outside []int
+}
+
+type convexHull struct {
+	Points  []Coord3D
+	Epsilon float64
+
+	// Faces maps each directed edge to the face which
+	// contains it in counter-clockwise order.
+	Faces map[[2]int]*convexHullFace
+
+	// Pending contains faces which may have outside points.
+	Pending []*convexHullFace
+}
+
+// newConvexHull creates a tetrahedron from four of the
+// points, or returns nil if all the points are coplanar.
+func newConvexHull(points []Coord3D, epsilon float64) *convexHull {
+	i0 := 0
+	for i, p := range points {
+		if p.X < points[i0].X {
+			i0 = i
+		}
+	}
+	i1 := convexHullFarthest(points, func(c Coord3D) float64 {
+		return c.Dist(points[i0])
+	})
+	line := points[i1].Sub(points[i0]).Normalize()
+	i2 := convexHullFarthest(points, func(c Coord3D) float64 {
+		d := c.Sub(points[i0])
+		return d.Sub(line.Scale(d.Dot(line))).Norm()
+	})
+	normal := points[i1].Sub(points[i0]).Cross(points[i2].Sub(points[i0]))
+	if normal.Norm() <= epsilon*epsilon {
+		return nil
+	}
+	plane := NewPlane(points[i0], normal)
+	i3 := convexHullFarthest(points, func(c Coord3D) float64 {
+		return plane.Dist(c)
+	})
+	if plane.Dist(points[i3]) <= epsilon {
+		return nil
+	}
+	if plane.SignedDist(points[i3]) > 0 {
+		i1, i2 = i2, i1
+	}
+
+	res := &convexHull{
+		Points:  points,
+		Epsilon: epsilon,
+		Faces:   map[[2]int]*convexHullFace{},
+	}
+	faces := []*convexHullFace{
+		res.addFace(i0, i1, i2),
+		res.addFace(i0, i3, i1),
+		res.addFace(i1, i3, i2),
+		res.addFace(i2, i3, i0),
+	}
+	var remaining []int
+	for i := range points {
+		if i != i0 && i != i1 && i != i2 && i != i3 {
+			remaining = append(remaining, i)
+		}
+	}
+	res.assignOutside(remaining, faces)
+	return res
+}
+
+func convexHullFarthest(points []Coord3D, f func(c Coord3D) float64) int {
+	var res int
+	maxValue := math.Inf(-1)
+	for i, p := range points {
+		if v := f(p); v > maxValue {
+			maxValue = v
+			res = i
+		}
+	}
+	return res
+}
+
+// Expand adds outside points to the hull until every
+// point is contained in it.
+//
+// This is the quickhull algorithm, which always adds the
+// point furthest from a face so that most points end up
+// inside the hull without being added.
+func (c *convexHull) Expand() {
+	for len(c.Pending) > 0 {
+		f := c.Pending[len(c.Pending)-1]
+		c.Pending = c.Pending[:len(c.Pending)-1]
+		if f.Removed || len(f.Outside) == 0 {
+			continue
+		}
+		idx := f.Outside[0]
+		for _, i := range f.Outside[1:] {
+			if f.Plane.SignedDist(c.Points[i]) > f.Plane.SignedDist(c.Points[idx]) {
+				idx = i
+			}
+		}
+		c.insert(f, idx)
+	}
+}
+
+func (c *convexHull) insert(start *convexHullFace, idx int) {
+	p := c.Points[idx]
+
+	// The visible faces are connected, so they can be found
+	// by searching outward from the starting face, and the
+	// horizon is the boundary of this region.
+	visible := []*convexHullFace{start}
+	start.Removed = true
+	var horizon [][2]int
+	for i := 0; i < len(visible); i++ {
+		f := visible[i]
+		for j := 0; j < 3; j++ {
+			edge := [2]int{f.Vertices[j], f.Vertices[(j+1)%3]}
+			other := c.Faces[[2]int{edge[1], edge[0]}]
+			if other.Removed {
+				continue
+			}
+			if other.Plane.SignedDist(p) > c.Epsilon {
+				other.Removed = true
+				visible = append(visible, other)
+			} else {
+				horizon = append(horizon, edge)
+			}
+		}
+	}
+
+	var orphans []int
+	for _, f := range visible {
+		for j := 0; j < 3; j++ {
+			edge := [2]int{f.Vertices[j], f.Vertices[(j+1)%3]}
+			if c.Faces[edge] == f {
+				delete(c.Faces, edge)
+			}
+		}
+		for _, i := range f.Outside {
+			if i != idx {
+				orphans = append(orphans, i)
+			}
+		}
+	}
+	newFaces := make([]*convexHullFace, len(horizon))
+	for i, edge := range horizon {
+		newFaces[i] = c.addFace(edge[0], edge[1], idx)
+	}
+	c.assignOutside(orphans, newFaces)
+}
+
+// assignOutside adds each point to the outside set of the
+// first face that it is in front of, if any.
+func (c *convexHull) assignOutside(points []int, faces []*convexHullFace) {
+	for _, i := range points {
+		for _, f := range faces {
+			if f.Plane.SignedDist(c.Points[i]) > c.Epsilon {
+				f.Outside = append(f.Outside, i)
+				break
+			}
+		}
+	}
+	for _, f := range faces {
+		if len(f.Outside) > 0 {
+			c.Pending = append(c.Pending, f)
+		}
+	}
+}
+
+// Mesh creates a mesh from the current faces.
+func (c *convexHull) Mesh() *Mesh {
+	res := NewMesh()
+	for _, f := range c.uniqueFaces() {
+		res.Add(&Triangle{
+			c.Points[f.Vertices[0]],
+			c.Points[f.Vertices[1]],
+			c.Points[f.Vertices[2]],
+		})
+	}
+	return res
+}
+
+func (c *convexHull) addFace(i0, i1, i2 int) *convexHullFace {
+	f := &convexHullFace{
+		Vertices: [3]int{i0, i1, i2},
+		Plane: NewPlane(
+			c.Points[i0],
+			c.Points[i1].Sub(c.Points[i0]).Cross(c.Points[i2].Sub(c.Points[i0])),
+		),
+	}
+	for i := 0; i < 3; i++ {
+		c.Faces[[2]int{f.Vertices[i], f.Vertices[(i+1)%3]}] = f
+	}
+	return f
+}
+
+func (c *convexHull) uniqueFaces() []*convexHullFace {
+	res := make([]*convexHullFace, 0, len(c.Faces)/3)
+	for edge, f := range c.Faces {
+		// Each face is counted once, by its first edge.
+		if edge[0] == f.Vertices[0] {
+			res = append(res, f)
+		}
+	}
+	return res
+}
@@ -0,0 +1,61 @@
+package model3d
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvexHull(t *testing.T) {
+	// Points inside a box should not affect its hull.
+	box := NewMeshRect(XYZ(-1, -2, -3), XYZ(3, 2, 1))
+	points := box.VertexSlice()
+	for i := 0; i < 1000; i++ {
+		points = append(points, NewCoord3DRandUniform().Mul(XYZ(4, 4, 4)).Add(XYZ(-1, -2, -3)))
+	}
+	hull := ConvexHull(points)
+	MustValidateMesh(t, hull, true)
+	if v := hull.Volume(); math.Abs(v-64) > 1e-8 {
+		t.Errorf("expected volume 64 but got %f", v)
+	}
+	if n := len(hull.VertexSlice()); n != 8 {
+		t.Errorf("expected 8 vertices but got %d", n)
+	}
+
+	sphere := NewMeshIcosphere(XYZ(1, 2, 3), 2, 4)
+	hull = ConvexHull(append(sphere.VertexSlice(), XYZ(1, 2, 3)))
+	MustValidateMesh(t, hull, true)
+	if v1, v2 := hull.Volume(), sphere.Volume(); math.Abs(v1-v2) > 1e-8 {
+		t.Errorf("expected volume %f but got %f", v2, v1)
+	}
+	hull.Iterate(func(tri *Triangle) {
+		if tri.Normal().Dot(tri[0].Sub(XYZ(1, 2, 3))) < 0 {
+			t.Fatal("normal points inward")
+		}
+	})
+
+	flat := []Coord3D{X(1), Y(1), XY(1, 1), Coord3D{}, XY(0.5, 0.5)}
+	if n := len(ConvexHull(flat).TriangleSlice()); n != 0 {
+		t.Errorf("expected empty hull but got %d triangles", n)
+	}
+}
+
+func TestMeshCentroid(t *testing.T) {
+	box := NewMeshRect(XYZ(-1, -2, -3), XYZ(3, 2, 1))
+	if c := box.Centroid(); c.Dist(XYZ(1, 0, -1)) > 1e-8 {
+		t.Errorf("unexpected centroid: %v", c)
+	}
+
+	// A cone's centroid is a quarter of the way up.
+	cone := MarchingCubesSearch(&Cone{Tip: XYZ(1, 2, 4), Base: XYZ(1, 2, 0), Radius: 1}, 0.02, 8)
+	if c := cone.Centroid(); c.Dist(XYZ(1, 2, 1)) > 0.01 {
+		t.Errorf("unexpected centroid: %v", c)
+	}
+}
+
+func BenchmarkConvexHull(b *testing.B) {
+	points := NewMeshIcosphere(Coord3D{}, 1, 20).VertexSlice()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ConvexHull(points)
+	}
+}
@@ -73,6 +73,15 @@ func (d *Decimator) Decimate(m *Mesh) *Mesh {
 	return d.decimator().Decimate(m)
 }
 
+// DecimateTagged is like Decimate, but it preserves the
+// tags of a TaggedMesh.
+//
+// Vertices on the boundary between differently tagged
+// triangles are never removed.
+func (d *Decimator) DecimateTagged(t *TaggedMesh) *TaggedMesh {
+	return d.decimator().DecimateTagged(t)
+}
+
 func (d *Decimator) decimator() *decimator {
 	return &decimator{
 		FeatureAngle:       d.FeatureAngle,
@@ -134,6 +143,23 @@ func (n *normalDecCriterion) canRemoveVertex(v *decVertex) bool {
 	return false
 }
 
+// tagDecCriterion wraps another criterion to prevent the
+// removal of vertices on the boundary between differently
+// tagged triangles.
+type tagDecCriterion struct {
+	Criterion decCriterion
+}
+
+func (t *tagDecCriterion) canRemoveVertex(v *decVertex) bool {
+	tris := v.Vertex.Triangles
+	for _, tri := range tris[1:] {
+		if tri.Tag != tris[0].Tag {
+			return false
+		}
+	}
+	return t.Criterion.canRemoveVertex(v)
+}
+
 // decimator decimates meshes using arbitrary criteria.
 type decimator struct {
 	FeatureAngle       float64
@@ -148,6 +174,20 @@ func (d *decimator) Decimate(m *Mesh) *Mesh {
 	return pm.Mesh()
 }
 
+// DecimateTagged decimates a tagged mesh, keeping the
+// tags of the triangles.
+//
+// Vertices on the boundary between differently tagged
+// triangles are never removed, so the regions keep their
+// outlines.
+func (d *decimator) DecimateTagged(t *TaggedMesh) *TaggedMesh {
+	pm := newPtrMeshTagged(t)
+	d1 := *d
+	d1.Criterion = &tagDecCriterion{Criterion: d.Criterion}
+	d1.decimatePtrMesh(pm)
+	return pm.TaggedMesh()
+}
+
 func (d *decimator) decimatePtrMesh(p *ptrMesh) int {
 	coords := map[*ptrCoord]struct{}{}
 	p.Iterate(func(t *ptrTriangle) {
@@ -186,6 +226,12 @@ func (d *decimator) attemptRemoveVertex(p *ptrMesh, v *decVertex) bool {
 		return false
 	}
 
+	// The removed triangles share a tag when tags are being
+	// preserved (see tagDecCriterion).
+	for _, t := range newTriangles {
+		t.Tag = v.Vertex.Triangles[0].Tag
+	}
+
 	oldTriangles := append([]*ptrTriangle{}, v.Vertex.Triangles...)
 	for _, t := range oldTriangles {
 		p.Remove(t)
@@ -0,0 +1,438 @@
+package model3d
+
+import (
+	"container/heap"
+	"math"
+)
+
+// quadricBoundaryWeight scales the quadrics which keep
+// boundary edges in place on meshes with holes.
+const quadricBoundaryWeight = 1000
+
+// Decimate simplifies the mesh until it has at most
+// targetTriangles triangles, using a QuadricDecimator.
+//
+// Fewer triangles may be left if the mesh cannot be
+// simplified further without changing its topology.
+func (m *Mesh) Decimate(targetTriangles int) *Mesh {
+	q := &QuadricDecimator{TargetTriangles: targetTriangles}
+	return q.Decimate(m)
+}
+
+// QuadricDecimator simplifies triangle meshes by repeatedly
+// collapsing the edge which changes the shape the least.
+//
+// Unlike Decimator, this works well on curved surfaces,
+// such as the output of marching cubes.
+// Collapses are never performed if they would change the
+// topology of the mesh or flip the normal of a triangle.
+// Meshes with holes are supported, and their boundaries
+// are approximately preserved.
+//
+// The algorithm is described in:
+// "Surface Simplification Using Quadric Error Metrics" -
+// Michael Garland and Paul S. Heckbert.
+// https://www.cs.cmu.edu/~./garland/Papers/quadrics.pdf.
+type QuadricDecimator struct {
+	// TargetTriangles, if non-zero, stops decimation once
+	// the mesh has at most this many triangles.
+	TargetTriangles int
+
+	// MaxError, if non-zero, is the maximum error of a
+	// collapse.
+	// The error is the square root of the sum of squared
+	// distances from the new vertex to the planes of the
+	// original triangles around it, so it is measured in
+	// units of distance.
+	//
+	// If both MaxError and TargetTriangles are 0, the mesh
+	// is simplified as much as possible.
+	MaxError float64
+
+	// FilterFunc, if specified, can be used to prevent
+	// certain vertices from being moved or removed.
+	// If FilterFunc returns false for a coordinate, it
+	// will be left in place.
+	FilterFunc func(c Coord3D) bool
+}
+
+// Decimate applies the decimation algorithm to m,
+// producing a new mesh.
+func (q *QuadricDecimator) Decimate(m *Mesh) *Mesh {
+	state := newQuadricState(m, q.FilterFunc)
+	maxCost := math.Inf(1)
+	if q.MaxError != 0 {
+		maxCost = q.MaxError * q.MaxError
+	}
+
+	for state.Len() > 0 {
+		if state.numTriangles <= q.TargetTriangles {
+			break
+		}
+		collapse := heap.Pop(state).(*quadricCollapse)
+		if !state.isCurrent(collapse) {
+			continue
+		}
+		if collapse.cost > maxCost {
+			break
+		}
+		state.attemptCollapse(collapse)
+	}
+
+	return state.Mesh()
+}
+
+// quadric is a symmetric 4x4 matrix representing a sum of
+// squared distances to planes.
+//
+// The error of a point p is p'Ap + 2b'p + c, where the
+// entries are stored as A[0,0], A[0,1], A[0,2], A[1,1],
+// A[1,2], A[2,2], b[0], b[1], b[2], c.
+type quadric [10]float64
+
+func newQuadricPlane(normal Coord3D, point Coord3D, weight float64) *quadric {
+	n := normal
+	d := -n.Dot(point)
+	return &quadric{
+		weight * n.X * n.X, weight * n.X * n.Y, weight * n.X * n.Z,
+		weight * n.Y * n.Y, weight * n.Y * n.Z, weight * n.Z * n.Z,
+		weight * d * n.X, weight * d * n.Y, weight * d * n.Z,
+		weight * d * d,
+	}
+}
+
+func (q *quadric) Add(q1 *quadric) {
+	for i, x := range q1 {
+		q[i] += x
+	}
+}
+
+func (q *quadric) Eval(p Coord3D) float64 {
+	return q[0]*p.X*p.X + q[3]*p.Y*p.Y + q[5]*p.Z*p.Z +
+		2*(q[1]*p.X*p.Y+q[2]*p.X*p.Z+q[4]*p.Y*p.Z) +
+		2*(q[6]*p.X+q[7]*p.Y+q[8]*p.Z) + q[9]
+}
+
+// Minimize finds the point with the lowest error, if the
+// minimum is well-defined.
+func (q *quadric) Minimize() (Coord3D, bool) {
+	mat := &Matrix3{
+		q[0], q[1], q[2],
+		q[1], q[3], q[4],
+		q[2], q[4], q[5],
+	}
+	trace := q[0] + q[3] + q[5]
+	det := mat.Det()
+	if trace == 0 || math.Abs(det) < 1e-8*trace*trace*trace {
+		return Coord3D{}, false
+	}
+	mat.InvertInPlaceDet(det)
+	return mat.MulColumn(XYZ(-q[6], -q[7], -q[8])), true
+}
+
+type quadricCollapse struct {
+	cost   float64
+	v1     int
+	v2     int
+	point  Coord3D
+	stamp1 int
+	stamp2 int
+}
+
+// quadricState is an indexed mesh which supports edge
+// collapses, along with a priority queue of collapses.
+type quadricState struct {
+	coords    []Coord3D
+	quadrics  []quadric
+	stamps    []int
+	alive     []bool
+	fixed     []bool
+	triangles [][3]int
+	triAlive  []bool
+	coordTris [][]int
+
+	numTriangles int
+	queue        []*quadricCollapse
+}
+
+func newQuadricState(m *Mesh, filter func(c Coord3D) bool) *quadricState {
+	s := &quadricState{}
+	indices := map[Coord3D]int{}
+	m.Iterate(func(t *Triangle) {
+		var tri [3]int
+		for i, c := range t {
+			idx, ok := indices[c]
+			if !ok {
+				idx = len(s.coords)
+				indices[c] = idx
+				s.coords = append(s.coords, c)
+				s.coordTris = append(s.coordTris, nil)
+			}
+			tri[i] = idx
+			s.coordTris[idx] = append(s.coordTris[idx], len(s.triangles))
+		}
+		s.triangles = append(s.triangles, tri)
+		s.triAlive = append(s.triAlive, true)
+	})
+	s.numTriangles = len(s.triangles)
+	s.quadrics = make([]quadric, len(s.coords))
+	s.stamps = make([]int, len(s.coords))
+	s.alive = make([]bool, len(s.coords))
+	s.fixed = make([]bool, len(s.coords))
+	for i, c := range s.coords {
+		s.alive[i] = true
+		s.fixed[i] = filter != nil && !filter(c)
+	}
+
+	edgeCounts := map[[2]int]int{}
+	for i, tri := range s.triangles {
+		normal := s.triangleNormal(i)
+		if norm := normal.Norm(); norm != 0 {
+			q := newQuadricPlane(normal.Scale(1/norm), s.coords[tri[0]], 1)
+			for _, idx := range tri {
+				s.quadrics[idx].Add(q)
+			}
+		}
+		for j := 0; j < 3; j++ {
+			edgeCounts[quadricEdge(tri[j], tri[(j+1)%3])]++
+		}
+	}
+
+	// Constrain boundary vertices to stay near planes
+	// orthogonal to the boundary.
+	for i, tri := range s.triangles {
+		normal := s.triangleNormal(i)
+		for j := 0; j < 3; j++ {
+			v1, v2 := tri[j], tri[(j+1)%3]
+			if edgeCounts[quadricEdge(v1, v2)] != 1 {
+				continue
+			}
+			edgeNormal := s.coords[v2].Sub(s.coords[v1]).Cross(normal)
+			if norm := edgeNormal.Norm(); norm != 0 {
+				q := newQuadricPlane(edgeNormal.Scale(1/norm), s.coords[v1],
+					quadricBoundaryWeight)
+				s.quadrics[v1].Add(q)
+				s.quadrics[v2].Add(q)
+			}
+		}
+	}
+
+	for _, tri := range s.triangles {
+		for j := 0; j < 3; j++ {
+			edge := quadricEdge(tri[j], tri[(j+1)%3])
+			if edgeCounts[edge] > 0 {
+				// Mark the edge as visited.
+				edgeCounts[edge] = 0
+				s.pushCollapse(edge[0], edge[1])
+			}
+		}
+	}
+	return s
+}
+
+// Mesh creates a mesh from the remaining triangles.
+func (q *quadricState) Mesh() *Mesh {
+	res := NewMesh()
+	for i, tri := range q.triangles {
+		if q.triAlive[i] {
+			res.Add(&Triangle{q.coords[tri[0]], q.coords[tri[1]], q.coords[tri[2]]})
+		}
+	}
+	return res
+}
+
+func (q *quadricState) isCurrent(c *quadricCollapse) bool {
+	return q.alive[c.v1] && q.alive[c.v2] && q.stamps[c.v1] == c.stamp1 &&
+		q.stamps[c.v2] == c.stamp2
+}
+
+func (q *quadricState) pushCollapse(v1, v2 int) {
+	if q.fixed[v1] || q.fixed[v2] {
+		return
+	}
+	quad := q.quadrics[v1]
+	quad.Add(&q.quadrics[v2])
+	point, ok := quad.Minimize()
+	cost := math.Inf(1)
+	if ok {
+		cost = quad.Eval(point)
+	}
+	c1, c2 := q.coords[v1], q.coords[v2]
+	for _, p := range []Coord3D{c1, c2, c1.Mid(c2)} {
+		if e := quad.Eval(p); e < cost {
+			point, cost = p, e
+		}
+	}
+	collapse := &quadricCollapse{
+		cost:   math.Max(0, cost),
+		v1:     v1,
+		v2:     v2,
+		point:  point,
+		stamp1: q.stamps[v1],
+		stamp2: q.stamps[v2],
+	}
+	heap.Push(q, collapse)
+}
+
+func (q *quadricState) attemptCollapse(c *quadricCollapse) {
+	v1, v2 := c.v1, c.v2
+	tris1, tris2 := q.liveTriangles(v1), q.liveTriangles(v2)
+
+	var shared []int
+	for _, t := range tris1 {
+		if q.hasVertex(t, v2) {
+			shared = append(shared, t)
+		}
+	}
+	if q.numTriangles-len(shared) < 4 {
+		return
+	}
+
+	// Link condition: the only vertices adjacent to both
+	// endpoints must be those of the collapsed triangles.
+	neighbors1 := q.neighborCounts(tris1)
+	neighbors2 := q.neighborCounts(tris2)
+	var numCommon int
+	for n := range neighbors1 {
+		if _, ok := neighbors2[n]; ok && n != v1 && n != v2 {
+			numCommon++
+		}
+	}
+	if numCommon != len(shared) {
+		return
+	}
+
+	// Joining two boundaries through the interior would
+	// create a non-manifold vertex.
+	boundaryEdge := len(shared) == 1
+	if !boundaryEdge && quadricIsBoundary(neighbors1, v1) &&
+		quadricIsBoundary(neighbors2, v2) {
+		return
+	}
+
+	// Prevent flipped or degenerate triangles.
+	for _, tris := range [2][]int{tris1, tris2} {
+		for _, t := range tris {
+			if q.hasVertex(t, v1) && q.hasVertex(t, v2) {
+				continue
+			}
+			oldNormal := q.triangleNormal(t)
+			var coords [3]Coord3D
+			for i, idx := range q.triangles[t] {
+				if idx == v1 || idx == v2 {
+					coords[i] = c.point
+				} else {
+					coords[i] = q.coords[idx]
+				}
+			}
+			newNormal := coords[1].Sub(coords[0]).Cross(coords[2].Sub(coords[0]))
+			if newNormal.Dot(oldNormal) <= 1e-3*oldNormal.Norm()*newNormal.Norm() ||
+				newNormal.Norm() <= 1e-8*oldNormal.Norm() {
+				return
+			}
+		}
+	}
+
+	for _, t := range shared {
+		q.triAlive[t] = false
+		q.numTriangles--
+	}
+	for _, t := range tris2 {
+		if !q.triAlive[t] {
+			continue
+		}
+		for i, idx := range q.triangles[t] {
+			if idx == v2 {
+				q.triangles[t][i] = v1
+			}
+		}
+		q.coordTris[v1] = append(q.coordTris[v1], t)
+	}
+	q.coords[v1] = c.point
+	q.quadrics[v1].Add(&q.quadrics[v2])
+	q.stamps[v1]++
+	q.alive[v2] = false
+	q.coordTris[v2] = nil
+	q.coordTris[v1] = q.liveTriangles(v1)
+
+	for n := range q.neighborCounts(q.coordTris[v1]) {
+		if n != v1 {
+			q.pushCollapse(v1, n)
+		}
+	}
+}
+
+func (q *quadricState) liveTriangles(v int) []int {
+	var res []int
+	for _, t := range q.coordTris[v] {
+		if q.triAlive[t] {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func (q *quadricState) hasVertex(t, v int) bool {
+	tri := q.triangles[t]
+	return tri[0] == v || tri[1] == v || tri[2] == v
+}
+
+// neighborCounts counts the number of triangles in which
+// each vertex appears.
+func (q *quadricState) neighborCounts(tris []int) map[int]int {
+	res := map[int]int{}
+	for _, t := range tris {
+		for _, idx := range q.triangles[t] {
+			res[idx]++
+		}
+	}
+	return res
+}
+
+func (q *quadricState) triangleNormal(t int) Coord3D {
+	tri := q.triangles[t]
+	c1, c2, c3 := q.coords[tri[0]], q.coords[tri[1]], q.coords[tri[2]]
+	return c2.Sub(c1).Cross(c3.Sub(c1))
+}
+
+func (q *quadricState) Len() int {
+	return len(q.queue)
+}
+
+func (q *quadricState) Less(i, j int) bool {
+	return q.queue[i].cost < q.queue[j].cost
+}
+
+func (q *quadricState) Swap(i, j int) {
+	q.queue[i], q.queue[j] = q.queue[j], q.queue[i]
+}
+
+func (q *quadricState) Push(x interface{}) {
+	q.queue = append(q.queue, x.(*quadricCollapse))
+}
+
+func (q *quadricState) Pop() interface{} {
+	res := q.queue[len(q.queue)-1]
+	q.queue = q.queue[:len(q.queue)-1]
+	return res
+}
+
+// quadricIsBoundary checks if a vertex is on a boundary,
+// given the neighbor counts of its triangles.
+//
+// On a boundary, some neighbor shares only one triangle.
+func quadricIsBoundary(neighbors map[int]int, v int) bool {
+	for n, count := range neighbors {
+		if n != v && count == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func quadricEdge(v1, v2 int) [2]int {
+	if v1 < v2 {
+		return [2]int{v1, v2}
+	}
+	return [2]int{v2, v1}
+}
@@ -0,0 +1,121 @@
+package model3d
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuadricDecimatorSphere(t *testing.T) {
+	mesh := MarchingCubesSearch(&Sphere{Radius: 1}, 0.05, 8)
+	numTris := len(mesh.TriangleSlice())
+	target := numTris / 20
+
+	decimated := mesh.Decimate(target)
+	if n := len(decimated.TriangleSlice()); n > target || n < target-2 {
+		t.Errorf("expected about %d triangles but got %d", target, n)
+	}
+	MustValidateMesh(t, decimated, true)
+	if v := decimated.Volume(); math.Abs(v-4*math.Pi/3) > 0.1 {
+		t.Errorf("unexpected volume: %f", v)
+	}
+	for _, v := range decimated.VertexSlice() {
+		if math.Abs(v.Norm()-1) > 0.02 {
+			t.Fatalf("vertex is far from sphere: %v", v)
+		}
+	}
+}
+
+func TestQuadricDecimatorMaxError(t *testing.T) {
+	// A box has lots of coplanar triangles which can be
+	// removed without any error.
+	mesh := MarchingCubesSearch(&Rect{MaxVal: XYZ(1, 2, 3)}, 0.1, 8)
+	d := &QuadricDecimator{MaxError: 1e-5}
+	decimated := d.Decimate(mesh)
+	MustValidateMesh(t, decimated, true)
+	if n, n1 := len(decimated.TriangleSlice()), len(mesh.TriangleSlice()); n > n1/10 {
+		t.Errorf("expected far fewer than %d triangles but got %d", n1, n)
+	}
+	if v := decimated.Volume(); math.Abs(v-mesh.Volume()) > 1e-3 {
+		t.Errorf("volume changed from %f to %f", mesh.Volume(), v)
+	}
+
+	// A sphere has no error-free collapses.
+	sphere := NewMeshIcosphere(Coord3D{}, 1, 5)
+	if n := len(d.Decimate(sphere).TriangleSlice()); n != len(sphere.TriangleSlice()) {
+		t.Errorf("expected no collapses but got %d triangles", n)
+	}
+}
+
+func TestQuadricDecimatorBoundary(t *testing.T) {
+	// An open, curved surface with a square boundary.
+	mesh := NewMesh()
+	height := func(x, y float64) float64 {
+		return 0.3 * math.Sin(x*3) * math.Cos(y*2)
+	}
+	const n = 30
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			x0, x1 := float64(i)/n, float64(i+1)/n
+			y0, y1 := float64(j)/n, float64(j+1)/n
+			p00 := XYZ(x0, y0, height(x0, y0))
+			p10 := XYZ(x1, y0, height(x1, y0))
+			p01 := XYZ(x0, y1, height(x0, y1))
+			p11 := XYZ(x1, y1, height(x1, y1))
+			mesh.Add(&Triangle{p00, p10, p11})
+			mesh.Add(&Triangle{p00, p11, p01})
+		}
+	}
+
+	decimated := mesh.Decimate(200)
+	if n := len(decimated.TriangleSlice()); n > 200 {
+		t.Errorf("too many triangles: %d", n)
+	}
+	if len(decimated.SingularVertices()) != 0 {
+		t.Error("singular vertices")
+	}
+	if _, n := decimated.RepairNormals(1e-8); n != 0 {
+		t.Errorf("%d flipped normals", n)
+	}
+	decimated.Iterate(func(tri *Triangle) {
+		if tri.Normal().Z < 0 {
+			t.Fatalf("flipped triangle: %v", tri)
+		}
+	})
+
+	// The boundary should stay roughly in place.
+	min, max := decimated.Min(), decimated.Max()
+	if math.Abs(min.X)+math.Abs(min.Y) > 1e-3 || math.Abs(max.X-1)+math.Abs(max.Y-1) > 1e-3 {
+		t.Errorf("unexpected bounds: %v, %v", min, max)
+	}
+	for _, v := range decimated.VertexSlice() {
+		if math.Abs(v.Z-height(v.X, v.Y)) > 0.02 {
+			t.Fatalf("vertex is far from surface: %v", v)
+		}
+	}
+}
+
+func TestQuadricDecimatorFilter(t *testing.T) {
+	mesh := NewMeshIcosphere(Coord3D{}, 1, 5)
+	d := &QuadricDecimator{
+		TargetTriangles: 50,
+		FilterFunc: func(c Coord3D) bool {
+			return c.Z < 0.5
+		},
+	}
+	decimated := d.Decimate(mesh)
+	MustValidateMesh(t, decimated, false)
+	for _, v := range mesh.VertexSlice() {
+		if v.Z >= 0.5 && len(decimated.Find(v)) == 0 {
+			t.Fatalf("filtered vertex was removed: %v", v)
+		}
+	}
+}
+
+func BenchmarkQuadricDecimator(b *testing.B) {
+	mesh := MarchingCubesSearch(&Sphere{Radius: 1}, 0.02, 8)
+	target := len(mesh.TriangleSlice()) / 10
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mesh.Decimate(target)
+	}
+}
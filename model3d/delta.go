@@ -0,0 +1,66 @@
+package model3d
+
+import "math"
+
+const (
+	// suggestDeltaCells is the number of grid cells along
+	// the longest axis of a solid's bounds used to
+	// estimate its surface.
+	suggestDeltaCells = 32
+
+	// suggestFeatureCells is the number of grid points
+	// along the longest axis of an SDF's bounds used to
+	// search for thin features.
+	suggestFeatureCells = 64
+)
+
+// SuggestDelta suggests a delta for marching cubes such
+// that the resulting mesh has roughly numTriangles
+// triangles.
+//
+// The estimate is obtained by running marching cubes at a
+// coarse resolution, so it may be inaccurate for solids
+// with many features that are small relative to their
+// bounds.
+func SuggestDelta(s Solid, numTriangles int) float64 {
+	if numTriangles <= 0 {
+		panic("number of triangles must be positive")
+	}
+	size := s.Max().Sub(s.Min())
+	delta := math.Max(math.Max(size.X, size.Y), size.Z) / suggestDeltaCells
+	if delta == 0 {
+		return 0
+	}
+
+	// The number of triangles is roughly proportional to
+	// the surface area divided by the area of a grid cell.
+	for i := 0; i < 4; i++ {
+		var count int
+		MarchingCubesStream(s, delta, func(t *Triangle) {
+			count++
+		})
+		if count > 0 {
+			return delta * math.Sqrt(float64(count)/float64(numTriangles))
+		}
+		// The coarse grid may miss small solids entirely.
+		delta /= 2
+	}
+	return delta
+}
+
+// SuggestFeatureDelta suggests a delta for marching cubes
+// that resolves the thinnest part of an SDF with
+// cellsPerFeature grid cells across its thickness.
+//
+// The thickness is estimated with EstimateMinFeatureSize.
+// Features thinner than 1/64 of the longest side of the
+// bounds may be missed.
+//
+// Returns 0 if the SDF contains no interior points.
+func SuggestFeatureDelta(s SDF, cellsPerFeature float64) float64 {
+	thickness, _ := EstimateMinFeatureSize(s, suggestFeatureCells)
+	if math.IsInf(thickness, 1) {
+		return 0
+	}
+	return thickness / cellsPerFeature
+}
@@ -0,0 +1,49 @@
+package model3d
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSuggestDelta(t *testing.T) {
+	solids := []Solid{
+		&Sphere{Radius: 1},
+		&Cylinder{P1: XYZ(1, 2, 3), P2: XYZ(3, 1, 4), Radius: 0.3},
+		&Sphere{Center: XYZ(1, 2, 3), Radius: 0.01},
+	}
+	for i, solid := range solids {
+		for _, target := range []int{2000, 20000} {
+			delta := SuggestDelta(solid, target)
+			var count int
+			MarchingCubesStream(solid, delta, func(t *Triangle) {
+				count++
+			})
+			ratio := float64(count) / float64(target)
+			if ratio < 0.7 || ratio > 1.3 {
+				t.Errorf("solid %d: expected %d triangles but got %d", i, target, count)
+			}
+		}
+	}
+}
+
+func TestSuggestFeatureDelta(t *testing.T) {
+	t.Run("Plate", func(t *testing.T) {
+		plate := &Rect{MinVal: XYZ(-1, -2, 0.3), MaxVal: XYZ(1, 1, 0.4)}
+		delta := SuggestFeatureDelta(plate, 4)
+		if math.Abs(delta-0.025) > 0.005 {
+			t.Errorf("unexpected delta: %f", delta)
+		}
+	})
+	t.Run("Sphere", func(t *testing.T) {
+		delta := SuggestFeatureDelta(&Sphere{Center: XYZ(1, 2, 3), Radius: 0.5}, 10)
+		if math.Abs(delta-0.1) > 0.01 {
+			t.Errorf("unexpected delta: %f", delta)
+		}
+	})
+	t.Run("Empty", func(t *testing.T) {
+		solid := &Rect{MinVal: XYZ(1, 2, 3), MaxVal: XYZ(1, 2, 3)}
+		if delta := SuggestFeatureDelta(solid, 10); delta != 0 {
+			t.Errorf("unexpected delta: %f", delta)
+		}
+	})
+}
@@ -7,6 +7,7 @@
 //     * model2d - 2D graphics, smoothing, bitmaps.
 //     * toolbox3d - modular 3D-printable components to
 //                   use in larger 3D models.
+//     * slicer3d - G-code generation for FDM printers.
 //
 // In addition, model3d comes with a large collection of
 // examples for both modeling and rendering.
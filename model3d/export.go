@@ -5,7 +5,12 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/unixpickle/model3d/fileformats"
@@ -217,6 +222,119 @@ func BuildMaterialOBJ(t []*Triangle, c func(t *Triangle) [3]float64) (o *filefor
 	return
 }
 
+// BuildNamedMaterialOBJ constructs obj and mtl files from
+// a triangle mesh where each triangle's material is named
+// by a function f.
+//
+// For example, to tag triangles with a map, pass a function
+// which looks up each triangle in the map.
+// Triangles with the empty name "" have no material.
+// These triangles are written before any others, since a
+// group without a material would otherwise inherit the
+// material of the preceding group.
+//
+// The materials argument defines the named materials.
+// Only the materials used by some triangle are included
+// in the mtl file, and names which are not defined get a
+// default gray material.
+//
+// Since the obj file must reference the mtl file, it does
+// so by the name "material.mtl". Change o.MaterialFiles
+// if this is not desired.
+func BuildNamedMaterialOBJ(t []*Triangle, f func(t *Triangle) string,
+	materials []*fileformats.MTLFileMaterial) (o *fileformats.OBJFile, m *fileformats.MTLFile) {
+	o = &fileformats.OBJFile{
+		MaterialFiles: []string{"material.mtl"},
+	}
+	m = &fileformats.MTLFile{}
+
+	definitions := map[string]*fileformats.MTLFileMaterial{}
+	for _, mat := range materials {
+		definitions[mat.Name] = mat
+	}
+
+	nameToGroup := map[string]*fileformats.OBJFileFaceGroup{}
+	coordToIdx := NewCoordToInt()
+	for _, tri := range t {
+		name := f(tri)
+		group, ok := nameToGroup[name]
+		if !ok {
+			group = &fileformats.OBJFileFaceGroup{Material: name}
+			nameToGroup[name] = group
+			o.FaceGroups = append(o.FaceGroups, group)
+			if name != "" {
+				mat, ok := definitions[name]
+				if !ok {
+					mat = &fileformats.MTLFileMaterial{
+						Name:    name,
+						Ambient: [3]float32{0.5, 0.5, 0.5},
+						Diffuse: [3]float32{0.5, 0.5, 0.5},
+					}
+				}
+				m.Materials = append(m.Materials, mat)
+			}
+		}
+		face := [3][3]int{}
+		for i, p := range tri {
+			idx, ok := coordToIdx.Load(p)
+			if !ok {
+				idx = coordToIdx.Len()
+				coordToIdx.Store(p, idx)
+				o.Vertices = append(o.Vertices, p.Array())
+			}
+			face[i][0] = idx + 1
+		}
+		group.Faces = append(group.Faces, face)
+	}
+
+	if group, ok := nameToGroup[""]; ok && o.FaceGroups[0] != group {
+		groups := []*fileformats.OBJFileFaceGroup{group}
+		for _, g := range o.FaceGroups {
+			if g != group {
+				groups = append(groups, g)
+			}
+		}
+		o.FaceGroups = groups
+	}
+
+	return
+}
+
+// SaveNamedMaterialOBJ saves a triangle mesh as an obj
+// file at path and an mtl file next to it, using the
+// materials from BuildNamedMaterialOBJ.
+//
+// The mtl file has the same name as the obj file, but with
+// a ".mtl" extension, so that programs like Blender can
+// find the materials when importing the obj file.
+func SaveNamedMaterialOBJ(path string, t []*Triangle, f func(t *Triangle) string,
+	materials []*fileformats.MTLFileMaterial) error {
+	if err := saveNamedMaterialOBJ(path, t, f, materials); err != nil {
+		return errors.Wrap(err, "save named material OBJ")
+	}
+	return nil
+}
+
+func saveNamedMaterialOBJ(path string, t []*Triangle, f func(t *Triangle) string,
+	materials []*fileformats.MTLFileMaterial) error {
+	mtlPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".mtl"
+	obj, mtl := BuildNamedMaterialOBJ(t, f, materials)
+	obj.MaterialFiles = []string{filepath.Base(mtlPath)}
+
+	write := func(path string, file interface{ Write(w io.Writer) error }) error {
+		w, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		return file.Write(w)
+	}
+	if err := write(mtlPath, mtl); err != nil {
+		return err
+	}
+	return write(path, obj)
+}
+
 // VertexColorsToTriangle creates a per-triangle color
 // function that averages the colors at each of the
 // vertices.
@@ -232,3 +350,237 @@ func VertexColorsToTriangle(f func(c Coord3D) [3]float64) func(t *Triangle) [3]f
 		return sum
 	}
 }
+
+// RoundTriangles creates new triangles by rounding every
+// coordinate to the given number of decimal places.
+//
+// Vertices which round to the same point are merged by
+// exporters such as EncodeMaterialOBJ and EncodeAMF, which
+// shrinks the files and avoids near-duplicate vertices
+// that can confuse slicers.
+// Triangles which become degenerate are removed,
+// including those whose vertices round onto one line, as
+// are exact duplicates.
+//
+// Unlike Mesh.SnapToGrid, each coordinate is rounded to
+// the closest floating-point value to a decimal number, so
+// text formats print at most the given number of decimal
+// places.
+// A negative number of decimals rounds to a multiple of a
+// power of ten.
+func RoundTriangles(triangles []*Triangle, decimals int) []*Triangle {
+	// Coordinates are first rounded to integer multiples of
+	// the smallest decimal place, so that degenerate
+	// triangles can be detected exactly with integer
+	// arithmetic.
+	steps := func(x float64) float64 {
+		if decimals < 0 {
+			return math.Round(x / math.Pow10(-decimals))
+		}
+		return math.Round(x * math.Pow10(decimals))
+	}
+	round := func(x float64) float64 {
+		// Adding zero turns -0 into 0.
+		if decimals < 0 {
+			return x*math.Pow10(-decimals) + 0
+		}
+		return x/math.Pow10(decimals) + 0
+	}
+
+	res := make([]*Triangle, 0, len(triangles))
+	seen := make(map[Triangle]bool, len(triangles))
+	for _, t := range triangles {
+		var grid, rounded Triangle
+		for i, c := range t {
+			grid[i] = XYZ(steps(c.X), steps(c.Y), steps(c.Z))
+			rounded[i] = XYZ(round(grid[i].X), round(grid[i].Y), round(grid[i].Z))
+		}
+		if gridTriangleDegenerate(&grid) {
+			continue
+		}
+		key := canonicalRotation(&rounded)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, &rounded)
+	}
+	return res
+}
+
+// gridTriangleDegenerate checks if a triangle with integer
+// coordinates has zero area.
+//
+// Float64 products of the edge vectors are only exact for
+// edges below 2^26 grid units, so longer edges fall back
+// on arbitrary-precision integers.
+func gridTriangleDegenerate(t *Triangle) bool {
+	e1, e2 := t[1].Sub(t[0]), t[2].Sub(t[0])
+	const maxExact = 1 << 26
+	a, b := e1.Array(), e2.Array()
+	exact := true
+	for i := 0; i < 3; i++ {
+		if math.Abs(a[i]) >= maxExact || math.Abs(b[i]) >= maxExact {
+			exact = false
+		}
+	}
+	if exact {
+		return e1.Cross(e2) == (Coord3D{})
+	}
+	toInt := func(x float64) *big.Int {
+		res, _ := big.NewFloat(x).Int(nil)
+		return res
+	}
+	for i := 0; i < 3; i++ {
+		j, k := (i+1)%3, (i+2)%3
+		p1 := new(big.Int).Mul(toInt(a[j]), toInt(b[k]))
+		p2 := new(big.Int).Mul(toInt(a[k]), toInt(b[j]))
+		if p1.Cmp(p2) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Round is like RoundTriangles, but it creates a new mesh.
+func (m *Mesh) Round(decimals int) *Mesh {
+	return NewMeshTriangles(RoundTriangles(m.TriangleSlice(), decimals))
+}
+
+// EncodeAMF encodes a 3D model as an AMF file.
+//
+// See BuildAMF for details.
+func EncodeAMF(triangles []*Triangle, colorFunc func(t *Triangle) [3]float64) []byte {
+	var buf bytes.Buffer
+	WriteAMF(&buf, triangles, colorFunc)
+	return buf.Bytes()
+}
+
+// WriteAMF writes a 3D model as an AMF file.
+//
+// See BuildAMF for details.
+func WriteAMF(w io.Writer, triangles []*Triangle, colorFunc func(t *Triangle) [3]float64) error {
+	if err := BuildAMF(triangles, colorFunc).Write(w); err != nil {
+		return errors.Wrap(err, "write AMF")
+	}
+	return nil
+}
+
+// BuildAMF creates an AMF file containing a single object,
+// where each triangle's color is determined by colorFunc.
+//
+// Like BuildMaterialOBJ, a different material is created
+// for every color, and the triangles of each material are
+// stored in a separate volume.
+// If colorFunc is nil, the object has no colors.
+func BuildAMF(triangles []*Triangle, colorFunc func(t *Triangle) [3]float64) *fileformats.AMFFile {
+	res := &fileformats.AMFFile{Unit: "millimeter"}
+	obj := &fileformats.AMFObject{ID: "0"}
+	res.Objects = append(res.Objects, obj)
+
+	coordToIdx := NewCoordToInt()
+	colorToVolume := map[[3]float64]*fileformats.AMFVolume{}
+	for _, t := range triangles {
+		var color [3]float64
+		if colorFunc != nil {
+			color = colorFunc(t)
+		}
+		vol, ok := colorToVolume[color]
+		if !ok {
+			vol = &fileformats.AMFVolume{}
+			if colorFunc != nil {
+				vol.MaterialID = strconv.Itoa(len(res.Materials) + 1)
+				res.Materials = append(res.Materials, &fileformats.AMFMaterial{
+					ID:    vol.MaterialID,
+					Name:  "mat" + strconv.Itoa(len(res.Materials)),
+					Color: &fileformats.AMFColor{color[0], color[1], color[2], 1},
+				})
+			}
+			colorToVolume[color] = vol
+			obj.Volumes = append(obj.Volumes, vol)
+		}
+		vol.Triangles = append(vol.Triangles, amfTriangle(obj, coordToIdx, t))
+	}
+	return res
+}
+
+// BuildTaggedAMF creates an AMF file with an object for
+// each tag in a TaggedMesh.
+//
+// Objects are colored according to the colors map, and
+// tags without a color produce uncolored objects.
+// Each object's ID is its tag.
+func BuildTaggedAMF(t *TaggedMesh, colors map[int][3]float64) *fileformats.AMFFile {
+	res := &fileformats.AMFFile{Unit: "millimeter"}
+	for _, tag := range t.TagSet() {
+		obj := &fileformats.AMFObject{ID: strconv.Itoa(tag)}
+		if color, ok := colors[tag]; ok {
+			obj.Color = &fileformats.AMFColor{color[0], color[1], color[2], 1}
+		}
+		vol := &fileformats.AMFVolume{}
+		obj.Volumes = append(obj.Volumes, vol)
+		coordToIdx := NewCoordToInt()
+		t.Select(tag).Iterate(func(tri *Triangle) {
+			vol.Triangles = append(vol.Triangles, amfTriangle(obj, coordToIdx, tri))
+		})
+		res.Objects = append(res.Objects, obj)
+	}
+	return res
+}
+
+// Encode3MF encodes a 3D model as a 3MF file.
+//
+// See Build3MF for details.
+func Encode3MF(triangles []*Triangle) []byte {
+	var buf bytes.Buffer
+	Write3MF(&buf, triangles)
+	return buf.Bytes()
+}
+
+// Write3MF writes a 3D model as a 3MF file.
+//
+// See Build3MF for details.
+func Write3MF(w io.Writer, triangles []*Triangle) error {
+	if err := Build3MF(triangles).Write(w); err != nil {
+		return errors.Wrap(err, "write 3MF")
+	}
+	return nil
+}
+
+// Build3MF creates a 3MF file containing a single object
+// with the given triangles, measured in millimeters.
+func Build3MF(triangles []*Triangle) *fileformats.ThreeMFFile {
+	obj := &fileformats.ThreeMFObject{}
+	coordToIdx := NewCoordToInt()
+	for _, t := range triangles {
+		var tri [3]int
+		for i, p := range t {
+			idx, ok := coordToIdx.Load(p)
+			if !ok {
+				idx = len(obj.Vertices)
+				coordToIdx.Store(p, idx)
+				obj.Vertices = append(obj.Vertices, p.Array())
+			}
+			tri[i] = idx
+		}
+		obj.Triangles = append(obj.Triangles, tri)
+	}
+	return &fileformats.ThreeMFFile{
+		Unit:    "millimeter",
+		Objects: []*fileformats.ThreeMFObject{obj},
+	}
+}
+
+func amfTriangle(obj *fileformats.AMFObject, coordToIdx *CoordToInt, t *Triangle) [3]int {
+	var res [3]int
+	for i, p := range t {
+		idx, ok := coordToIdx.Load(p)
+		if !ok {
+			idx = len(obj.Vertices)
+			coordToIdx.Store(p, idx)
+			obj.Vertices = append(obj.Vertices, p.Array())
+		}
+		res[i] = idx
+	}
+	return res
+}
@@ -0,0 +1,212 @@
+package model3d
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/model3d/fileformats"
+)
+
+func TestSaveNamedMaterialOBJ(t *testing.T) {
+	box1 := NewMeshRect(XYZ(0, 0, 0), XYZ(1, 1, 1))
+	box2 := NewMeshRect(XYZ(2, 0, 0), XYZ(3, 1, 1))
+	names := map[*Triangle]string{}
+	box1.Iterate(func(t *Triangle) {
+		names[t] = "red"
+	})
+	box2.Iterate(func(t *Triangle) {
+		names[t] = "blue"
+	})
+	mesh := NewMesh()
+	mesh.AddMesh(box1)
+	mesh.AddMesh(box2)
+
+	dir, err := ioutil.TempDir("", "model3d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	materials := []*fileformats.MTLFileMaterial{
+		{Name: "red", Diffuse: [3]float32{1, 0, 0}},
+		{Name: "unused", Diffuse: [3]float32{0, 1, 0}},
+	}
+	path := filepath.Join(dir, "boxes.obj")
+	err = mesh.SaveNamedMaterialOBJ(path, func(t *Triangle) string {
+		return names[t]
+	}, materials)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objData, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := string(objData)
+	if !strings.HasPrefix(obj, "mtllib boxes.mtl\n") {
+		t.Error("missing mtllib reference")
+	}
+	if n := strings.Count(obj, "\nf "); n != 24 {
+		t.Errorf("expected 24 faces but got %d", n)
+	}
+	if n := strings.Count(obj, "\nv "); n != 16 {
+		t.Errorf("expected 16 vertices but got %d", n)
+	}
+	for _, name := range []string{"red", "blue"} {
+		if !strings.Contains(obj, "usemtl "+name+"\n") {
+			t.Errorf("missing usemtl for %s", name)
+		}
+	}
+
+	mtlData, err := ioutil.ReadFile(filepath.Join(dir, "boxes.mtl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtl := string(mtlData)
+	if !strings.Contains(mtl, "newmtl red\n") || !strings.Contains(mtl, "Kd 1.0000 0.0000 0.0000\n") {
+		t.Error("missing defined material")
+	}
+	if !strings.Contains(mtl, "newmtl blue\n") {
+		t.Error("missing default material")
+	}
+	if strings.Contains(mtl, "unused") {
+		t.Error("unused material should not be written")
+	}
+}
+
+func TestBuildNamedMaterialOBJUnnamed(t *testing.T) {
+	box1 := NewMeshRect(XYZ(0, 0, 0), XYZ(1, 1, 1))
+	box2 := NewMeshRect(XYZ(2, 0, 0), XYZ(3, 1, 1))
+	names := map[*Triangle]string{}
+	box1.Iterate(func(t *Triangle) {
+		names[t] = "red"
+	})
+
+	// Put named triangles before unnamed ones.
+	tris := append(box1.TriangleSlice(), box2.TriangleSlice()...)
+	o, _ := BuildNamedMaterialOBJ(tris, func(t *Triangle) string {
+		return names[t]
+	}, nil)
+
+	var buf strings.Builder
+	if err := o.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var material string
+	counts := map[string]int{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "usemtl ") {
+			material = strings.TrimPrefix(line, "usemtl ")
+		} else if strings.HasPrefix(line, "f ") {
+			counts[material]++
+		}
+	}
+	if counts[""] != 12 || counts["red"] != 12 {
+		t.Errorf("unexpected face counts per material: %v", counts)
+	}
+}
+
+func TestSaveSTL(t *testing.T) {
+	mesh := NewMeshRect(XYZ(0, 0, 0), XYZ(1, 2, 3))
+
+	dir, err := ioutil.TempDir("", "model3d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "box.stl")
+	if err := mesh.SaveSTL(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSTL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meshesEqual(mesh, loaded) {
+		t.Error("loaded mesh does not match")
+	}
+
+	badPath := filepath.Join(dir, "missing", "box.stl")
+	if err := mesh.SaveSTL(badPath); err == nil {
+		t.Error("expected error saving to missing directory")
+	}
+	if err := mesh.SaveGroupedSTL(badPath); err == nil {
+		t.Error("expected error saving to missing directory")
+	}
+}
+
+func TestRoundTriangles(t *testing.T) {
+	mesh := NewMeshIcosphere(XYZ(0.1, 0.2, 0.3), 1, 3)
+
+	// Split every vertex into near-duplicates, like the
+	// output of some marching cubes implementations.
+	var jittered []*Triangle
+	mesh.Iterate(func(tri *Triangle) {
+		t1 := *tri
+		for i := range t1 {
+			t1[i] = t1[i].Add(NewCoord3DRandUnit().Scale(1e-9))
+		}
+		jittered = append(jittered, &t1)
+	})
+
+	rounded := RoundTriangles(jittered, 5)
+	if len(rounded) != len(jittered) {
+		t.Fatalf("expected %d triangles but got %d", len(jittered), len(rounded))
+	}
+	obj, _ := BuildMaterialOBJ(rounded, func(t *Triangle) [3]float64 {
+		return [3]float64{1, 1, 1}
+	})
+	if n := len(obj.Vertices); n != len(mesh.VertexSlice()) {
+		t.Errorf("expected %d vertices but got %d", len(mesh.VertexSlice()), n)
+	}
+	for _, tri := range rounded {
+		for _, c := range tri {
+			for _, x := range c.Array() {
+				s := strconv.FormatFloat(x, 'f', -1, 64)
+				if idx := strings.Index(s, "."); idx != -1 && len(s)-idx-1 > 5 {
+					t.Fatalf("too many decimals: %s", s)
+				}
+			}
+		}
+	}
+	if NewMeshTriangles(rounded).NeedsRepair() {
+		t.Error("rounded mesh needs repair")
+	}
+
+	// Degenerate and duplicate triangles are removed.
+	tris := []*Triangle{
+		{XYZ(-0.001, 0, 0), XYZ(1, 0, 0), XYZ(0, 1, 0)},
+		{XYZ(0, 0, 0), XYZ(1, 0, 0), XYZ(0, 1, 0)},
+		{XYZ(1, 0, 0), XYZ(0, 1, 0), XYZ(0, 0, 0)},
+		{XYZ(0, 0, 0), XYZ(0.001, 0, 0), XYZ(0, 1, 0)},
+		// Distinct vertices which round onto one line.
+		{XYZ(0, 0, 0), XYZ(0.1, 0.101, 0.1), XYZ(0.3, 0.299, 0.3)},
+		{XYZ(0, 0, 0), XYZ(1.004, 2, 0), XYZ(2, 3.996, 0)},
+	}
+	rounded = RoundTriangles(tris, 2)
+	if len(rounded) != 1 {
+		t.Fatalf("expected 1 triangle but got %d", len(rounded))
+	}
+	if math.Signbit(rounded[0][0].X) {
+		t.Error("negative zero was not removed")
+	}
+
+	// With many decimals, float64 cross products of large
+	// coordinates are inexact, but thin triangles should
+	// still be kept.
+	tris = []*Triangle{
+		{XYZ(0, 0, 0), XYZ(100.000001, 100, 0), XYZ(100, 99.999999, 0)},
+		{XYZ(0, 0, 0), XYZ(100.000001, 100, 0), XYZ(200.000002, 200, 0)},
+	}
+	rounded = RoundTriangles(tris, 6)
+	if len(rounded) != 1 || rounded[0][2] != tris[0][2] {
+		t.Fatalf("unexpected triangles: %v", rounded)
+	}
+}
@@ -0,0 +1,97 @@
+package model3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/essentials"
+)
+
+// EstimateMinFeatureSize estimates the thickness of the
+// thinnest parts of a shape, given its SDF.
+//
+// The positive result is the thickness of the thinnest
+// part of the shape itself, such as a thin wall.
+// The negative result is the width of the narrowest gap
+// in the shape, such as a slot or a hole, within the
+// bounds of the SDF.
+// These can be used for printability checks, e.g. to
+// find walls which are thinner than a printer's nozzle.
+//
+// Thicknesses are estimated by probing the SDF on a grid
+// with the given number of samples along the longest side
+// of the bounds, and finding local maxima of the distance
+// to the surface, which lie along the medial axis.
+// Features which are thinner than the grid spacing may be
+// missed or underestimated.
+//
+// If no feature is found, the corresponding result is
+// infinite.
+func EstimateMinFeatureSize(sdf SDF, samples int) (positive, negative float64) {
+	positive, negative = math.Inf(1), math.Inf(1)
+
+	min, max := sdf.Min(), sdf.Max()
+	size := max.Sub(min)
+	spacing := math.Max(math.Max(size.X, size.Y), size.Z) / float64(samples)
+	if spacing == 0 || samples <= 0 {
+		return
+	}
+	var counts [3]int
+	for i, x := range size.Array() {
+		// Include an extra point on either side so that
+		// every point within the bounds has neighbors.
+		counts[i] = int(math.Ceil(x/spacing)) + 3
+	}
+	origin := min.Sub(XYZ(spacing, spacing, spacing))
+
+	// Only three layers of the grid are stored at once.
+	fetchLayer := func(z int, values []float64) {
+		essentials.ConcurrentMap(0, counts[1], func(y int) {
+			for x := 0; x < counts[0]; x++ {
+				c := origin.Add(XYZ(float64(x), float64(y), float64(z)).Scale(spacing))
+				values[x+y*counts[0]] = sdf.SDF(c)
+			}
+		})
+	}
+	var layers [3][]float64
+	for i := range layers {
+		layers[i] = make([]float64, counts[0]*counts[1])
+		if i < 2 {
+			fetchLayer(i, layers[i])
+		}
+	}
+
+	for z := 1; z < counts[2]-1; z++ {
+		fetchLayer(z+1, layers[2])
+		for y := 1; y < counts[1]-1; y++ {
+			for x := 1; x < counts[0]-1; x++ {
+				value := layers[1][x+y*counts[0]]
+				if value > 0 && 2*value < positive {
+					if featureLocalMax(layers, counts[0], x, y, value, 1) {
+						positive = 2 * value
+					}
+				} else if value < 0 && -2*value < negative {
+					if featureLocalMax(layers, counts[0], x, y, value, -1) {
+						negative = -2 * value
+					}
+				}
+			}
+		}
+		layers[0], layers[1], layers[2] = layers[1], layers[2], layers[0]
+	}
+	return
+}
+
+// featureLocalMax checks if sign*value is at least as
+// large as all of its neighbors in the grid.
+func featureLocalMax(layers [3][]float64, rowSize, x, y int, value, sign float64) bool {
+	for _, layer := range layers {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if sign*layer[x+dx+(y+dy)*rowSize] > sign*value {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
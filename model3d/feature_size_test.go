@@ -0,0 +1,46 @@
+package model3d
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateMinFeatureSize(t *testing.T) {
+	t.Run("Plate", func(t *testing.T) {
+		plate := &Rect{MinVal: XYZ(-1, -2, 0.3), MaxVal: XYZ(1, 1, 0.4)}
+		positive, negative := EstimateMinFeatureSize(plate, 100)
+		if math.Abs(positive-0.1) > 0.02 {
+			t.Errorf("unexpected positive size: %f", positive)
+		}
+		if !math.IsInf(negative, 1) {
+			t.Errorf("unexpected negative size: %f", negative)
+		}
+	})
+	t.Run("Gap", func(t *testing.T) {
+		plate1 := &Rect{MinVal: XYZ(-1, -1, 0), MaxVal: XYZ(1, 1, 0.1)}
+		plate2 := &Rect{MinVal: XYZ(-1, -1, 0.25), MaxVal: XYZ(1, 1, 0.45)}
+		sdf := FuncSDF(plate1.Min(), plate2.Max(), func(c Coord3D) float64 {
+			return math.Max(plate1.SDF(c), plate2.SDF(c))
+		})
+		positive, negative := EstimateMinFeatureSize(sdf, 100)
+		if math.Abs(positive-0.1) > 0.02 {
+			t.Errorf("unexpected positive size: %f", positive)
+		}
+		if math.Abs(negative-0.15) > 0.02 {
+			t.Errorf("unexpected negative size: %f", negative)
+		}
+	})
+	t.Run("Shell", func(t *testing.T) {
+		sdf := FuncSDF(XYZ(-1, -1, -1), XYZ(1, 1, 1), func(c Coord3D) float64 {
+			r := c.Norm()
+			return math.Min(1-r, r-0.8)
+		})
+		positive, negative := EstimateMinFeatureSize(sdf, 50)
+		if math.Abs(positive-0.2) > 0.04 {
+			t.Errorf("unexpected positive size: %f", positive)
+		}
+		if math.Abs(negative-1.6) > 0.04 {
+			t.Errorf("unexpected negative size: %f", negative)
+		}
+	})
+}
@@ -2,23 +2,81 @@ package model3d
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/binary"
 	"io"
+	"io/ioutil"
+	"math"
+	"os"
 
 	"github.com/pkg/errors"
 	"github.com/unixpickle/model3d/fileformats"
 )
 
+// STLWeldEpsilon is the distance, relative to the largest
+// side of a mesh's bounding box, within which vertices are
+// welded together by LoadSTL and DecodeSTL.
+const STLWeldEpsilon = 1e-6
+
+// LoadSTL reads an STL file from a path and creates a
+// mesh from its triangles.
+//
+// See DecodeSTL for details.
+func LoadSTL(path string) (*Mesh, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "load STL")
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "load STL")
+	}
+	tris, err := readSTL(f, info.Size())
+	if err != nil {
+		return nil, errors.Wrap(err, "load STL")
+	}
+	return weldSTLTriangles(tris), nil
+}
+
+// DecodeSTL decodes a binary or ASCII STL file and creates
+// a mesh from its triangles.
+//
+// Since STL files store every triangle separately, and
+// some exporters round coordinates inconsistently, nearby
+// vertices are welded together using STLWeldEpsilon.
+// Triangles which become degenerate after welding are
+// removed.
+func DecodeSTL(data []byte) (*Mesh, error) {
+	tris, err := readSTL(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "decode STL")
+	}
+	return weldSTLTriangles(tris), nil
+}
+
 // ReadSTL decodes a file in the STL file format.
+//
+// Both the binary and ASCII variants are supported.
+// To tell them apart reliably, the entire file is read
+// into memory before it is decoded.
 func ReadSTL(r io.Reader) ([]*Triangle, error) {
-	tris, err := readSTL(r)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "read STL")
+	}
+	tris, err := readSTL(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
 		return nil, errors.Wrap(err, "read STL")
 	}
 	return tris, nil
 }
 
-func readSTL(r io.Reader) ([]*Triangle, error) {
+func readSTL(r io.Reader, size int64) ([]*Triangle, error) {
 	br := bufio.NewReader(r)
+	if isASCIISTL(br, size) {
+		return readASCIISTL(br)
+	}
 	reader, err := fileformats.NewSTLReader(br)
 	if err != nil {
 		return nil, err
@@ -38,6 +96,115 @@ func readSTL(r io.Reader) ([]*Triangle, error) {
 	return tris, nil
 }
 
+// isASCIISTL checks if a buffered STL file of a given size
+// (in bytes) is in the ASCII format without consuming any
+// data.
+//
+// Binary files may also begin with "solid", so the start
+// of the first facet must also be present.
+func isASCIISTL(br *bufio.Reader, size int64) bool {
+	header, _ := br.Peek(1024)
+	if !bytes.HasPrefix(bytes.TrimLeft(header, " \t\r\n"), []byte("solid")) {
+		return false
+	}
+	if len(header) >= 84 {
+		// A binary file with a matching triangle count is
+		// never treated as ASCII.
+		numTris := binary.LittleEndian.Uint32(header[80:84])
+		if size == 84+50*int64(numTris) {
+			return false
+		}
+	}
+	return bytes.Contains(header, []byte("facet")) || bytes.Contains(header, []byte("endsolid"))
+}
+
+func readASCIISTL(r io.Reader) ([]*Triangle, error) {
+	reader, err := fileformats.NewASCIISTLReader(r)
+	if err != nil {
+		return nil, err
+	}
+	var tris []*Triangle
+	for {
+		_, vertices, err := reader.ReadTriangle()
+		if err == io.EOF {
+			return tris, nil
+		} else if err != nil {
+			return nil, err
+		}
+		tri := &Triangle{}
+		for j, vert := range vertices {
+			tri[j] = XYZ(float64(vert[0]), float64(vert[1]), float64(vert[2]))
+		}
+		tris = append(tris, tri)
+	}
+}
+
+func weldSTLTriangles(tris []*Triangle) *Mesh {
+	mesh := NewMeshTriangles(tris)
+	if len(tris) == 0 {
+		return mesh
+	}
+	size := mesh.Max().Sub(mesh.Min())
+	epsilon := STLWeldEpsilon * math.Max(size.X, math.Max(size.Y, size.Z))
+	if epsilon == 0 {
+		return mesh
+	}
+	mesh = mesh.Repair(epsilon)
+	mesh.Iterate(func(t *Triangle) {
+		if t[0] == t[1] || t[1] == t[2] || t[0] == t[2] {
+			mesh.Remove(t)
+		}
+	})
+	return mesh
+}
+
+// ReadOBJ decodes the faces of a Wavefront obj file.
+//
+// Faces with more than three vertices are triangulated.
+// Materials, texture coordinates, and normals are ignored.
+func ReadOBJ(r io.Reader) ([]*Triangle, error) {
+	reader := fileformats.NewOBJReader(r)
+	var triangles []*Triangle
+	for {
+		face, _, err := reader.ReadFace()
+		if err == io.EOF {
+			return triangles, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "read OBJ")
+		}
+		if len(face) == 3 {
+			triangles = append(triangles, &Triangle{
+				NewCoord3DArray(face[0]),
+				NewCoord3DArray(face[1]),
+				NewCoord3DArray(face[2]),
+			})
+			continue
+		}
+		poly := make([]Coord3D, len(face))
+		for i, x := range face {
+			poly[i] = NewCoord3DArray(x)
+		}
+		triangles = append(triangles, TriangulateFace(poly)...)
+	}
+}
+
+// LoadOBJ reads a Wavefront obj file from a path and
+// creates a mesh from its faces.
+//
+// See ReadOBJ for details.
+func LoadOBJ(path string) (*Mesh, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "load OBJ")
+	}
+	defer f.Close()
+	tris, err := ReadOBJ(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewMeshTriangles(tris), nil
+}
+
 // ReadOFF decodes a file in the object file format.
 // See http://segeval.cs.princeton.edu/public/off_format.html.
 func ReadOFF(r io.Reader) ([]*Triangle, error) {
@@ -59,3 +226,113 @@ func ReadOFF(r io.Reader) ([]*Triangle, error) {
 	}
 	return triangles, nil
 }
+
+// ReadAMF decodes an AMF file, which may be compressed.
+//
+// Every volume of every object is given its own tag in the
+// resulting mesh, starting at 1.
+// The colors map contains the effective color of each
+// tag, and tags without a color are omitted.
+//
+// Coordinates are converted to millimeters.
+func ReadAMF(r io.Reader) (mesh *TaggedMesh, colors map[int][3]float64, err error) {
+	file, err := fileformats.ReadAMF(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	scale, ok := unitToMillimeters(file.Unit)
+	if !ok {
+		return nil, nil, errors.New("read AMF: unknown unit: " + file.Unit)
+	}
+
+	mesh = NewTaggedMesh()
+	colors = map[int][3]float64{}
+	var tag int
+	for _, obj := range file.Objects {
+		for _, vol := range obj.Volumes {
+			tag++
+			if c := file.VolumeColor(obj, vol); c != nil {
+				colors[tag] = [3]float64{c[0], c[1], c[2]}
+			}
+			for _, t := range vol.Triangles {
+				tri := &Triangle{}
+				for i, idx := range t {
+					tri[i] = NewCoord3DArray(obj.Vertices[idx]).Scale(scale)
+				}
+				mesh.Add(tri, tag)
+			}
+		}
+	}
+	return mesh, colors, nil
+}
+
+// LoadAMF reads an AMF file from a path.
+//
+// See ReadAMF for details.
+func LoadAMF(path string) (*TaggedMesh, map[int][3]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "load AMF")
+	}
+	defer f.Close()
+	return ReadAMF(f)
+}
+
+// Read3MF decodes a 3MF file, combining the triangles of
+// every object.
+//
+// Coordinates are converted to millimeters.
+func Read3MF(r io.Reader) ([]*Triangle, error) {
+	file, err := fileformats.Read3MF(r)
+	if err != nil {
+		return nil, err
+	}
+	scale, ok := unitToMillimeters(file.Unit)
+	if !ok {
+		return nil, errors.New("read 3MF: unknown unit: " + file.Unit)
+	}
+	var triangles []*Triangle
+	for _, obj := range file.Objects {
+		for _, t := range obj.Triangles {
+			tri := &Triangle{}
+			for i, idx := range t {
+				tri[i] = NewCoord3DArray(obj.Vertices[idx]).Scale(scale)
+			}
+			triangles = append(triangles, tri)
+		}
+	}
+	return triangles, nil
+}
+
+// Load3MF reads a 3MF file from a path and creates a mesh
+// from its triangles.
+//
+// See Read3MF for details.
+func Load3MF(path string) (*Mesh, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "load 3MF")
+	}
+	defer f.Close()
+	tris, err := Read3MF(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewMeshTriangles(tris), nil
+}
+
+// unitToMillimeters gets the number of millimeters in a
+// unit of an AMF or 3MF file.
+func unitToMillimeters(unit string) (float64, bool) {
+	scale, ok := map[string]float64{
+		"":           1,
+		"millimeter": 1,
+		"micron":     1e-3,
+		"centimeter": 10,
+		"meter":      1e3,
+		"inch":       25.4,
+		"foot":       304.8,
+		"feet":       304.8,
+	}[unit]
+	return scale, ok
+}
@@ -2,9 +2,11 @@ package model3d
 
 import (
 	"bytes"
+	"io/ioutil"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -35,6 +37,131 @@ func TestImportSTL(t *testing.T) {
 	}
 }
 
+func TestDecodeSTL(t *testing.T) {
+	t.Run("Binary", func(t *testing.T) {
+		original := NewMeshIcosphere(XYZ(1, 2, 3), 1.5, 3)
+		mesh, err := DecodeSTL(original.EncodeSTL())
+		if err != nil {
+			t.Fatal(err)
+		}
+		MustValidateMesh(t, mesh, true)
+		if n1, n2 := len(mesh.TriangleSlice()), len(original.TriangleSlice()); n1 != n2 {
+			t.Errorf("expected %d triangles but got %d", n2, n1)
+		}
+		if v1, v2 := mesh.Volume(), original.Volume(); math.Abs(v1-v2) > 1e-4 {
+			t.Errorf("expected volume %f but got %f", v2, v1)
+		}
+	})
+
+	t.Run("ASCII", func(t *testing.T) {
+		// A tetrahedron where one vertex is written with
+		// inconsistent precision, and one degenerate facet.
+		data := `solid tetra
+facet normal 0 0 -1
+ outer loop
+  vertex 0 0 0
+  vertex 0 1 0
+  vertex 1 0 0
+ endloop
+endfacet
+facet normal 0 -1 0
+ outer loop
+  vertex 0 0 0
+  vertex 1.0000000001 0 0
+  vertex 0 0 1
+ endloop
+endfacet
+facet normal -1 0 0
+ outer loop
+  vertex 0 0 0
+  vertex 0 0 1
+  vertex 0 1 0
+ endloop
+endfacet
+facet normal 1 1 1
+ outer loop
+  vertex 1 0 0
+  vertex 0 1 0
+  vertex 0 0 1
+ endloop
+endfacet
+facet normal 0 0 1
+ outer loop
+  vertex 0 0 1
+  vertex 0 0 1.00000001
+  vertex 0 1 0
+ endloop
+endfacet
+endsolid tetra
+`
+		mesh, err := DecodeSTL([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(mesh.TriangleSlice()); n != 4 {
+			t.Fatalf("expected 4 triangles but got %d", n)
+		}
+		MustValidateMesh(t, mesh, true)
+		if v := mesh.Volume(); math.Abs(v-1.0/6) > 1e-5 {
+			t.Errorf("unexpected volume: %f", v)
+		}
+	})
+
+	t.Run("BinarySolidHeader", func(t *testing.T) {
+		original := NewMeshRect(XYZ(0, 0, 0), XYZ(1, 1, 1))
+		data := original.EncodeSTL()
+		copy(data, []byte("solid exported with facet keyword"))
+		mesh, err := DecodeSTL(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(mesh.TriangleSlice()); n != 12 {
+			t.Errorf("expected 12 triangles but got %d", n)
+		}
+	})
+
+	t.Run("LargeBinarySolidHeader", func(t *testing.T) {
+		// The file is much larger than a read buffer.
+		original := MarchingCubesSearch(&Sphere{Radius: 1}, 0.1, 0)
+		numTris := len(original.TriangleSlice())
+		data := original.EncodeSTL()
+		copy(data, []byte("solid exported with facet keyword"))
+
+		mesh, err := DecodeSTL(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(mesh.TriangleSlice()); n != numTris {
+			t.Errorf("expected %d triangles but got %d", numTris, n)
+		}
+
+		tris, err := ReadSTL(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tris) != numTris {
+			t.Errorf("expected %d triangles but got %d", numTris, len(tris))
+		}
+
+		dir, err := ioutil.TempDir("", "model3d")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "mesh.stl")
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mesh, err = LoadSTL(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(mesh.TriangleSlice()); n != numTris {
+			t.Errorf("expected %d triangles but got %d", numTris, n)
+		}
+	})
+}
+
 func TestImportOFF(t *testing.T) {
 	f, err := os.Open("test_data/cube.off")
 	if err != nil {
@@ -58,3 +185,136 @@ func TestImportOFF(t *testing.T) {
 		t.Errorf("incorrect area: %f", area)
 	}
 }
+
+func TestImportOBJ(t *testing.T) {
+	// A unit cube with quad faces.
+	data := `v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+v 0 0 1
+v 1 0 1
+v 1 1 1
+v 0 1 1
+f 1 4 3 2
+f 5 6 7 8
+f 1 2 6 5
+f 2 3 7 6
+f 3 4 8 7
+f 4 1 5 8
+`
+	triangles, err := ReadOBJ(bytes.NewReader([]byte(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triangles) != 12 {
+		t.Errorf("expected %d triangles but got %d", 12, len(triangles))
+	}
+	mesh := NewMeshTriangles(triangles)
+	MustValidateMesh(t, mesh, true)
+	if volume := mesh.Volume(); math.Abs(volume-1) > 1e-5 {
+		t.Errorf("incorrect volume: %f", volume)
+	}
+
+	t.Run("Concave", func(t *testing.T) {
+		data := `v 0 0 0
+v 2 0 0
+v 2 1 0
+v 1 1 0
+v 1 2 0
+v 0 2 0
+f 1 2 3 4 5 6
+`
+		triangles, err := ReadOBJ(bytes.NewReader([]byte(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(triangles) != 4 {
+			t.Fatalf("expected 4 triangles but got %d", len(triangles))
+		}
+		if area := NewMeshTriangles(triangles).Area(); math.Abs(area-3) > 1e-8 {
+			t.Errorf("expected area 3 but got %f", area)
+		}
+	})
+}
+
+func TestImportAMF(t *testing.T) {
+	box := NewMeshRect(XYZ(0, 0, 0), XYZ(2, 1, 1))
+	sphere := NewMeshIcosphere(XYZ(5, 0, 0), 1, 2)
+
+	t.Run("Colors", func(t *testing.T) {
+		colors := map[*Triangle][3]float64{}
+		box.Iterate(func(tri *Triangle) {
+			if tri.Normal().Z > 0.5 {
+				colors[tri] = [3]float64{1, 0, 0}
+			} else {
+				colors[tri] = [3]float64{0, 1, 0}
+			}
+		})
+		data := box.EncodeAMF(func(tri *Triangle) [3]float64 {
+			return colors[tri]
+		})
+		decoded, decodedColors, err := ReadAMF(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		MustValidateMesh(t, decoded.Mesh, true)
+		if len(decodedColors) != 2 {
+			t.Fatalf("expected 2 colors but got %d", len(decodedColors))
+		}
+		colorFunc := decoded.TriangleColor(decodedColors)
+		decoded.Mesh.Iterate(func(tri *Triangle) {
+			expected := [3]float64{0, 1, 0}
+			if tri.Normal().Z > 0.5 {
+				expected = [3]float64{1, 0, 0}
+			}
+			if colorFunc(tri) != expected {
+				t.Fatalf("unexpected color %v for normal %v", colorFunc(tri), tri.Normal())
+			}
+		})
+		if v := decoded.Mesh.Volume(); math.Abs(v-2) > 1e-8 {
+			t.Errorf("unexpected volume: %f", v)
+		}
+	})
+
+	t.Run("Tagged", func(t *testing.T) {
+		tagged := NewTaggedMeshMesh(box, 3)
+		tagged.AddMesh(sphere, 7)
+		var buf bytes.Buffer
+		if err := tagged.WriteAMF(&buf, map[int][3]float64{7: {0, 0, 1}}); err != nil {
+			t.Fatal(err)
+		}
+		decoded, colors, err := ReadAMF(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded.TagSet()) != 2 {
+			t.Fatalf("unexpected tags: %v", decoded.TagSet())
+		}
+		if _, ok := colors[1]; ok {
+			t.Error("box should not have a color")
+		}
+		if colors[2] != [3]float64{0, 0, 1} {
+			t.Errorf("unexpected sphere color: %v", colors[2])
+		}
+		if v := decoded.Select(1).Volume(); math.Abs(v-2) > 1e-8 {
+			t.Errorf("unexpected box volume: %f", v)
+		}
+		if v, expected := decoded.Select(2).Volume(), sphere.Volume(); math.Abs(v-expected) > 1e-8 {
+			t.Errorf("expected sphere volume %f but got %f", expected, v)
+		}
+	})
+}
+
+func TestImport3MF(t *testing.T) {
+	sphere := NewMeshIcosphere(XYZ(5, 0, 0), 1, 2)
+	decoded, err := Read3MF(bytes.NewReader(Encode3MF(sphere.TriangleSlice())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesh := NewMeshTriangles(decoded)
+	MustValidateMesh(t, mesh, true)
+	if v, expected := mesh.Volume(), sphere.Volume(); math.Abs(v-expected) > 1e-8 {
+		t.Errorf("expected volume %f but got %f", expected, v)
+	}
+}
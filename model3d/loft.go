@@ -0,0 +1,112 @@
+package model3d
+
+import (
+	"math"
+	"sort"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+// LoftSolid creates a solid by interpolating between 2D
+// cross-sections at increasing z values.
+//
+// Between two consecutive sections, the signed distance
+// functions of the sections are linearly interpolated, and
+// the solid contains the points where the interpolated SDF
+// is positive.
+// Thus, the solid morphs smoothly from one shape to the
+// next, even if the shapes have different topologies.
+//
+// Sections can be created from 2D meshes with
+// model2d.MeshToSDF.
+// The zs must be strictly increasing, and there must be
+// at least two sections.
+func LoftSolid(sections []model2d.SDF, zs []float64) Solid {
+	if len(sections) != len(zs) {
+		panic("number of sections must match number of z values")
+	}
+	if len(sections) < 2 {
+		panic("at least two sections are required")
+	}
+	for i := 1; i < len(zs); i++ {
+		if zs[i] <= zs[i-1] {
+			panic("z values must be strictly increasing")
+		}
+	}
+	min2d, max2d := sections[0].Min(), sections[0].Max()
+	for _, s := range sections[1:] {
+		min2d = min2d.Min(s.Min())
+		max2d = max2d.Max(s.Max())
+	}
+	return &loftSolid{
+		sections: sections,
+		zs:       zs,
+		min:      XYZ(min2d.X, min2d.Y, zs[0]),
+		max:      XYZ(max2d.X, max2d.Y, zs[len(zs)-1]),
+	}
+}
+
+// LoftSDF is like LoftSolid, but creates an approximate
+// SDF.
+//
+// Inside the z range of the sections, the result is the
+// interpolated 2D SDF, which does not account for the
+// slope of the surface.
+// Above and below the sections, distances to the top and
+// bottom caps are combined with the 2D SDF.
+func LoftSDF(sections []model2d.SDF, zs []float64) SDF {
+	return LoftSolid(sections, zs).(*loftSolid)
+}
+
+type loftSolid struct {
+	sections []model2d.SDF
+	zs       []float64
+	min      Coord3D
+	max      Coord3D
+}
+
+func (l *loftSolid) Min() Coord3D {
+	return l.min
+}
+
+func (l *loftSolid) Max() Coord3D {
+	return l.max
+}
+
+func (l *loftSolid) Contains(c Coord3D) bool {
+	if !InBounds(l, c) {
+		return false
+	}
+	return l.sectionSDF(c) > 0
+}
+
+func (l *loftSolid) SDF(c Coord3D) float64 {
+	d := l.sectionSDF(c)
+	capDist := math.Min(c.Z-l.min.Z, l.max.Z-c.Z)
+	if capDist >= 0 {
+		return math.Min(d, capDist)
+	} else if d >= 0 {
+		return capDist
+	} else {
+		return -math.Sqrt(d*d + capDist*capDist)
+	}
+}
+
+// sectionSDF interpolates the 2D SDF at the z value of c,
+// clamping to the first and last sections.
+func (l *loftSolid) sectionSDF(c Coord3D) float64 {
+	p := c.XY()
+	n := len(l.zs)
+	if c.Z <= l.zs[0] {
+		return l.sections[0].SDF(p)
+	} else if c.Z >= l.zs[n-1] {
+		return l.sections[n-1].SDF(p)
+	}
+	idx := sort.SearchFloat64s(l.zs, c.Z)
+	if l.zs[idx] == c.Z {
+		return l.sections[idx].SDF(p)
+	}
+	z1, z2 := l.zs[idx-1], l.zs[idx]
+	t := (c.Z - z1) / (z2 - z1)
+	return (1-t)*l.sections[idx-1].SDF(p) + t*l.sections[idx].SDF(p)
+}
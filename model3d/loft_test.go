@@ -0,0 +1,80 @@
+package model3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+func TestLoftSolid(t *testing.T) {
+	circle := func(r float64) model2d.SDF {
+		return model2d.FuncSDF(model2d.XY(-r, -r), model2d.XY(r, r), func(c model2d.Coord) float64 {
+			return r - c.Norm()
+		})
+	}
+	square := model2d.MeshToSDF(model2d.NewMeshRect(model2d.XY(-2, -2), model2d.XY(2, 2)))
+
+	t.Run("Cone", func(t *testing.T) {
+		// Linearly interpolating two circle SDFs gives a cone.
+		solid := LoftSolid([]model2d.SDF{circle(2), circle(1)}, []float64{0, 2})
+		if solid.Min().Dist(XYZ(-2, -2, 0)) > 1e-8 || solid.Max().Dist(XYZ(2, 2, 2)) > 1e-8 {
+			t.Errorf("unexpected bounds: %v %v", solid.Min(), solid.Max())
+		}
+		for i := 0; i < 1000; i++ {
+			c := XYZ(rand.Float64()*4-2, rand.Float64()*4-2, rand.Float64()*2)
+			expected := c.XY().Norm() < 2-c.Z/2
+			if solid.Contains(c) != expected {
+				t.Fatalf("unexpected containment at %v", c)
+			}
+		}
+	})
+
+	t.Run("Sections", func(t *testing.T) {
+		solid := LoftSolid(
+			[]model2d.SDF{square, circle(1), square},
+			[]float64{0, 1, 3},
+		)
+		for _, z := range []float64{0, 3} {
+			if !solid.Contains(XYZ(1.9, 1.9, z)) {
+				t.Errorf("expected square corner at z=%f", z)
+			}
+		}
+		if solid.Contains(XYZ(1.1, 0, 1)) || !solid.Contains(XYZ(0.9, 0, 1)) {
+			t.Error("expected circular section at z=1")
+		}
+		if solid.Contains(XYZ(0, 0, 3.01)) || solid.Contains(XYZ(0, 0, -0.01)) {
+			t.Error("solid should be capped")
+		}
+
+		mesh := MarchingCubesSearch(solid, 0.05, 8)
+		if mesh.NeedsRepair() {
+			t.Error("mesh needs repair")
+		}
+		// The middle section is narrower, so the volume is
+		// less than that of a box.
+		if v := mesh.Volume(); v > 48 || v < 4*math.Pi {
+			t.Errorf("unexpected volume: %f", v)
+		}
+	})
+}
+
+func TestLoftSDF(t *testing.T) {
+	circle := model2d.FuncSDF(model2d.XY(-1, -1), model2d.XY(1, 1), func(c model2d.Coord) float64 {
+		return 1 - c.Norm()
+	})
+	sdf := LoftSDF([]model2d.SDF{circle, circle}, []float64{0, 4})
+	cases := map[Coord3D]float64{
+		XYZ(0, 0, 2):   1,
+		XYZ(0, 0, 0.5): 0.5,
+		XYZ(2, 0, 2):   -1,
+		XYZ(0, 0, 5):   -1,
+		XYZ(4, 0, 8):   -5,
+	}
+	for c, expected := range cases {
+		if actual := sdf.SDF(c); math.Abs(actual-expected) > 1e-8 {
+			t.Errorf("SDF at %v: expected %f but got %f", c, expected, actual)
+		}
+	}
+}
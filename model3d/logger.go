@@ -0,0 +1,144 @@
+package model3d
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// A Logger receives progress information from long-running
+// operations, such as meshing, smoothing, and rendering.
+//
+// Operations are split into named phases, like "marching
+// cubes" followed by "search".
+// Methods may be called from multiple Goroutines at once.
+type Logger interface {
+	// StartPhase is called when a phase begins.
+	StartPhase(name string)
+
+	// Progress is called periodically during a phase with
+	// the fraction of the phase which is complete.
+	Progress(name string, frac float64)
+
+	// EndPhase is called when a phase is complete, with the
+	// time that the phase took.
+	EndPhase(name string, elapsed time.Duration)
+}
+
+// A StdLogger is a Logger which prints messages using the
+// standard log package.
+type StdLogger struct {
+	// Logger is used to print messages.
+	// If nil, the standard logger is used.
+	Logger *log.Logger
+
+	// ProgressInterval is the minimum increase in progress
+	// between printed progress messages.
+	// If 0, a default of 0.1 is used.
+	ProgressInterval float64
+
+	lock     sync.Mutex
+	lastFrac map[string]float64
+}
+
+// StartPhase prints that a phase has started.
+func (s *StdLogger) StartPhase(name string) {
+	s.lock.Lock()
+	if s.lastFrac == nil {
+		s.lastFrac = map[string]float64{}
+	}
+	s.lastFrac[name] = 0
+	s.lock.Unlock()
+	s.printf("%s: started", name)
+}
+
+// Progress prints the progress of a phase if it has
+// increased enough since the last message.
+func (s *StdLogger) Progress(name string, frac float64) {
+	interval := s.ProgressInterval
+	if interval == 0 {
+		interval = 0.1
+	}
+	s.lock.Lock()
+	if s.lastFrac == nil {
+		s.lastFrac = map[string]float64{}
+	}
+	shouldPrint := frac-s.lastFrac[name] >= interval
+	if shouldPrint {
+		s.lastFrac[name] = frac
+	}
+	s.lock.Unlock()
+	if shouldPrint {
+		s.printf("%s: %.0f%%", name, frac*100)
+	}
+}
+
+// EndPhase prints that a phase has finished, along with
+// the time it took.
+func (s *StdLogger) EndPhase(name string, elapsed time.Duration) {
+	s.lock.Lock()
+	delete(s.lastFrac, name)
+	s.lock.Unlock()
+	s.printf("%s: done in %v", name, elapsed)
+}
+
+func (s *StdLogger) printf(format string, args ...interface{}) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+// A LogPhase tracks a single phase of an operation for a
+// Logger, which may be nil.
+//
+// This is intended to make it easy to support a Logger in
+// long-running operations.
+type LogPhase struct {
+	logger Logger
+	name   string
+	start  time.Time
+
+	lock     sync.Mutex
+	lastFrac float64
+}
+
+// StartLogPhase calls l.StartPhase(name) and returns a
+// LogPhase for reporting progress.
+//
+// If l is nil, the resulting LogPhase does nothing.
+func StartLogPhase(l Logger, name string) *LogPhase {
+	if l != nil {
+		l.StartPhase(name)
+	}
+	return &LogPhase{logger: l, name: name, start: time.Now()}
+}
+
+// Progress reports the fraction of the phase which is
+// complete.
+//
+// To avoid flooding the Logger, progress is only passed
+// along once it increases by at least 0.01.
+// It is safe to call Progress from multiple Goroutines.
+func (l *LogPhase) Progress(frac float64) {
+	if l.logger == nil {
+		return
+	}
+	l.lock.Lock()
+	if frac-l.lastFrac < 0.01 {
+		l.lock.Unlock()
+		return
+	}
+	l.lastFrac = frac
+	l.lock.Unlock()
+	l.logger.Progress(l.name, math.Min(1, frac))
+}
+
+// End reports that the phase is complete.
+func (l *LogPhase) End() {
+	if l.logger != nil {
+		l.logger.EndPhase(l.name, time.Since(l.start))
+	}
+}
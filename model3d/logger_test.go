@@ -0,0 +1,82 @@
+package model3d
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingLogger struct {
+	lock     sync.Mutex
+	events   []string
+	progress map[string][]float64
+}
+
+func (r *recordingLogger) StartPhase(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, "start "+name)
+}
+
+func (r *recordingLogger) Progress(name string, frac float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.progress == nil {
+		r.progress = map[string][]float64{}
+	}
+	r.progress[name] = append(r.progress[name], frac)
+}
+
+func (r *recordingLogger) EndPhase(name string, elapsed time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, "end "+name)
+}
+
+func TestMarchingCubesSearchLog(t *testing.T) {
+	logger := &recordingLogger{}
+	solid := &Sphere{Radius: 1}
+	mesh := MarchingCubesSearchLog(solid, 0.05, 4, logger)
+	if !meshesEqual(mesh, MarchingCubesSearch(solid, 0.05, 4)) {
+		t.Error("logging should not change the mesh")
+	}
+
+	expected := []string{"start marching cubes", "end marching cubes", "start search", "end search"}
+	if strings.Join(logger.events, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected events: %v", logger.events)
+	}
+	for _, name := range []string{"marching cubes", "search"} {
+		progress := logger.progress[name]
+		if len(progress) < 10 || len(progress) > 101 {
+			t.Errorf("%s: unexpected number of progress updates: %d", name, len(progress))
+		} else if last := progress[len(progress)-1]; last < 0.99 || last > 1 {
+			t.Errorf("%s: unexpected final progress: %f", name, last)
+		}
+	}
+}
+
+func TestMeshSmootherLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	smoother := &MeshSmoother{StepSize: 0.1, Iterations: 20, Logger: logger}
+	smoother.Smooth(NewMeshIcosphere(Coord3D{}, 1, 3))
+	if len(logger.events) != 2 || len(logger.progress["smooth"]) != 20 {
+		t.Errorf("unexpected logs: %v %v", logger.events, logger.progress)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &StdLogger{Logger: log.New(&buf, "", 0), ProgressInterval: 0.5}
+	logger.StartPhase("test")
+	for i := 1; i <= 10; i++ {
+		logger.Progress("test", float64(i)/10)
+	}
+	logger.EndPhase("test", time.Second)
+	expected := "test: started\ntest: 50%\ntest: 100%\ntest: done in 1s\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
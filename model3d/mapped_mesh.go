@@ -0,0 +1,624 @@
+package model3d
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const mappedMeshLeafSize = 8
+
+// A MappedMesh is a read-only triangle mesh which decodes
+// triangles on demand from the raw bytes of a file.
+//
+// On most platforms, the file is memory-mapped, so large
+// meshes can be inspected and rendered without creating a
+// Go object for every triangle.
+// The data is paged in by the operating system as it is
+// accessed.
+//
+// A MappedMesh must be closed with Close once it is no
+// longer needed, after which none of its methods or
+// colliders may be used.
+type MappedMesh struct {
+	data  []byte
+	unmap func() error
+
+	numTris int
+	decode  func(idx int, out *Triangle)
+
+	min Coord3D
+	max Coord3D
+
+	colliderOnce sync.Once
+	collider     *mappedMeshCollider
+}
+
+// LoadMappedSTL maps a binary STL file into memory.
+//
+// Unlike LoadSTL, vertices are not welded, and ASCII STL
+// files are not supported.
+func LoadMappedSTL(path string) (*MappedMesh, error) {
+	data, unmap, err := mmapFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "load mapped STL")
+	}
+	res, err := newMappedSTL(data)
+	if err != nil {
+		unmap()
+		return nil, errors.Wrap(err, "load mapped STL")
+	}
+	res.unmap = unmap
+	return res, nil
+}
+
+// LoadMappedPLY maps a binary little-endian PLY file into
+// memory.
+//
+// The file must contain a "vertex" element with x, y, and
+// z properties, and a "face" element with a single list
+// property, where every face is a triangle.
+// Other elements and properties are ignored.
+func LoadMappedPLY(path string) (*MappedMesh, error) {
+	data, unmap, err := mmapFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "load mapped PLY")
+	}
+	res, err := newMappedPLY(data)
+	if err != nil {
+		unmap()
+		return nil, errors.Wrap(err, "load mapped PLY")
+	}
+	res.unmap = unmap
+	return res, nil
+}
+
+func newMappedSTL(data []byte) (*MappedMesh, error) {
+	if len(data) < 84 {
+		return nil, errors.New("missing header")
+	}
+	numTris := int(binary.LittleEndian.Uint32(data[80:]))
+	if len(data) < 84+50*numTris {
+		if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("solid")) {
+			return nil, errors.New("ASCII STL files are not supported")
+		}
+		return nil, errors.New("file is too short for triangle count")
+	}
+	res := &MappedMesh{
+		data:    data,
+		numTris: numTris,
+		decode: func(idx int, out *Triangle) {
+			offset := 84 + 50*idx + 12
+			for i := range out {
+				out[i] = readMappedFloat32Coord(data[offset+12*i:])
+			}
+		},
+	}
+	res.computeBounds()
+	return res, nil
+}
+
+func newMappedPLY(data []byte) (*MappedMesh, error) {
+	header, err := parseMappedPLYHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	vertices, faces := header.Vertices, header.Faces
+	// Elements may appear in any order, so both ranges
+	// must be checked.
+	if len(data) < faces.Offset+faces.Count*faces.Stride ||
+		len(data) < vertices.Offset+vertices.Count*vertices.Stride {
+		return nil, errors.New("file is too short for element counts")
+	}
+
+	readCoord := func(idx int) Coord3D {
+		offset := vertices.Offset + idx*vertices.Stride
+		var arr [3]float64
+		for i, prop := range vertices.Coords {
+			arr[i] = prop.ReadFloat(data[offset+prop.Offset:])
+		}
+		return NewCoord3DArray(arr)
+	}
+	readIndex := func(face, i int) int {
+		offset := faces.Offset + face*faces.Stride + faces.Indices.Offset
+		offset += faces.Indices.CountSize + i*faces.Indices.Size
+		return faces.Indices.ReadInt(data[offset:])
+	}
+
+	for i := 0; i < faces.Count; i++ {
+		offset := faces.Offset + i*faces.Stride + faces.Indices.Offset
+		if n := faces.Indices.ReadCount(data[offset:]); n != 3 {
+			return nil, fmt.Errorf("face %d has %d vertices (expected 3)", i, n)
+		}
+		for j := 0; j < 3; j++ {
+			if idx := readIndex(i, j); idx < 0 || idx >= vertices.Count {
+				return nil, fmt.Errorf("face %d has out-of-bounds vertex %d", i, idx)
+			}
+		}
+	}
+
+	res := &MappedMesh{
+		data:    data,
+		numTris: faces.Count,
+		decode: func(idx int, out *Triangle) {
+			for i := range out {
+				out[i] = readCoord(readIndex(idx, i))
+			}
+		},
+	}
+	res.computeBounds()
+	return res, nil
+}
+
+// Close releases the underlying file data.
+func (m *MappedMesh) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+	unmap := m.unmap
+	m.unmap = nil
+	m.data = nil
+	return unmap()
+}
+
+// NumTriangles gets the number of triangles in the mesh.
+func (m *MappedMesh) NumTriangles() int {
+	return m.numTris
+}
+
+// Triangle decodes the triangle at the given index.
+//
+// A new Triangle is returned for every call, so the
+// result may be modified by the caller.
+func (m *MappedMesh) Triangle(idx int) *Triangle {
+	if idx < 0 || idx >= m.numTris {
+		panic("triangle index out of bounds")
+	}
+	res := &Triangle{}
+	m.decode(idx, res)
+	return res
+}
+
+// Iterate calls f with every triangle in the mesh, in the
+// order that they are stored in the file.
+//
+// The triangle passed to f is reused between calls, so it
+// must be copied if it is retained.
+func (m *MappedMesh) Iterate(f func(idx int, t *Triangle)) {
+	var t Triangle
+	for i := 0; i < m.numTris; i++ {
+		m.decode(i, &t)
+		f(i, &t)
+	}
+}
+
+// Min gets the minimum point of the bounding box.
+func (m *MappedMesh) Min() Coord3D {
+	return m.min
+}
+
+// Max gets the maximum point of the bounding box.
+func (m *MappedMesh) Max() Coord3D {
+	return m.max
+}
+
+// Mesh decodes every triangle into a regular Mesh.
+func (m *MappedMesh) Mesh() *Mesh {
+	res := NewMesh()
+	m.Iterate(func(idx int, t *Triangle) {
+		t1 := *t
+		res.Add(&t1)
+	})
+	return res
+}
+
+// Collider gets a Collider for the mesh.
+//
+// A BVH is built the first time this is called, which
+// stores a few dozen bytes per triangle rather than
+// decoding every triangle into memory.
+// Triangles are decoded as they are needed for collision
+// checks.
+//
+// This is safe to call from multiple Goroutines.
+func (m *MappedMesh) Collider() Collider {
+	m.colliderOnce.Do(func() {
+		m.collider = newMappedMeshCollider(m)
+	})
+	return m.collider
+}
+
+func (m *MappedMesh) computeBounds() {
+	if m.numTris == 0 {
+		return
+	}
+	min := XYZ(math.Inf(1), math.Inf(1), math.Inf(1))
+	max := min.Scale(-1)
+	m.Iterate(func(idx int, t *Triangle) {
+		for _, c := range t {
+			min = min.Min(c)
+			max = max.Max(c)
+		}
+	})
+	m.min, m.max = min, max
+}
+
+func readMappedFloat32Coord(data []byte) Coord3D {
+	return XYZ(
+		float64(math.Float32frombits(binary.LittleEndian.Uint32(data))),
+		float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4:]))),
+		float64(math.Float32frombits(binary.LittleEndian.Uint32(data[8:]))),
+	)
+}
+
+type mappedMeshBVHNode struct {
+	Min Coord3D
+	Max Coord3D
+
+	// Start and End are the range of triangles in the
+	// node, as indices into the collider's order.
+	Start int
+	End   int
+
+	// Right is the index of the second child node, or -1
+	// for leaves.
+	// The first child always follows its parent.
+	Right int
+}
+
+type mappedMeshCollider struct {
+	mesh  *MappedMesh
+	order []uint32
+	nodes []mappedMeshBVHNode
+}
+
+func newMappedMeshCollider(m *MappedMesh) *mappedMeshCollider {
+	res := &mappedMeshCollider{
+		mesh:  m,
+		order: make([]uint32, m.numTris),
+	}
+	centers := make([]Coord3D, m.numTris)
+	m.Iterate(func(idx int, t *Triangle) {
+		res.order[idx] = uint32(idx)
+		centers[idx] = t[0].Add(t[1]).Add(t[2]).Scale(1.0 / 3)
+	})
+	if m.numTris > 0 {
+		res.build(centers, 0, m.numTris)
+	}
+	return res
+}
+
+func (m *mappedMeshCollider) build(centers []Coord3D, start, end int) int {
+	nodeIdx := len(m.nodes)
+	m.nodes = append(m.nodes, mappedMeshBVHNode{Start: start, End: end, Right: -1})
+
+	min := XYZ(math.Inf(1), math.Inf(1), math.Inf(1))
+	max := min.Scale(-1)
+	minCenter, maxCenter := min, max
+	var t Triangle
+	for _, idx := range m.order[start:end] {
+		m.mesh.decode(int(idx), &t)
+		min = min.Min(t.Min())
+		max = max.Max(t.Max())
+		minCenter = minCenter.Min(centers[idx])
+		maxCenter = maxCenter.Max(centers[idx])
+	}
+	m.nodes[nodeIdx].Min = min
+	m.nodes[nodeIdx].Max = max
+	if end-start <= mappedMeshLeafSize {
+		return nodeIdx
+	}
+
+	// Split at the median along the longest axis.
+	size := maxCenter.Sub(minCenter).Array()
+	axis := 0
+	for i := 1; i < 3; i++ {
+		if size[i] > size[axis] {
+			axis = i
+		}
+	}
+	sub := m.order[start:end]
+	sort.Slice(sub, func(i, j int) bool {
+		return centers[sub[i]].Array()[axis] < centers[sub[j]].Array()[axis]
+	})
+	mid := (start + end) / 2
+	m.build(centers, start, mid)
+	right := m.build(centers, mid, end)
+	m.nodes[nodeIdx].Right = right
+	return nodeIdx
+}
+
+func (m *mappedMeshCollider) Min() Coord3D {
+	return m.mesh.Min()
+}
+
+func (m *mappedMeshCollider) Max() Coord3D {
+	return m.mesh.Max()
+}
+
+func (m *mappedMeshCollider) RayCollisions(r *Ray, f func(RayCollision)) int {
+	var count int
+	var t Triangle
+	m.traverse(func(node *mappedMeshBVHNode) bool {
+		minFrac, maxFrac := rayCollisionWithBounds(r, node.Min, node.Max)
+		return maxFrac >= minFrac && maxFrac >= 0
+	}, func(idx int) {
+		m.mesh.decode(idx, &t)
+		count += t.RayCollisions(r, f)
+	})
+	return count
+}
+
+func (m *mappedMeshCollider) FirstRayCollision(r *Ray) (RayCollision, bool) {
+	var res RayCollision
+	var found bool
+	var t Triangle
+	m.traverse(func(node *mappedMeshBVHNode) bool {
+		minFrac, maxFrac := rayCollisionWithBounds(r, node.Min, node.Max)
+		return maxFrac >= minFrac && maxFrac >= 0 && (!found || minFrac < res.Scale)
+	}, func(idx int) {
+		m.mesh.decode(idx, &t)
+		if coll, ok := t.FirstRayCollision(r); ok && (!found || coll.Scale < res.Scale) {
+			res = coll
+			found = true
+		}
+	})
+	return res, found
+}
+
+func (m *mappedMeshCollider) SphereCollision(c Coord3D, r float64) bool {
+	var found bool
+	var t Triangle
+	m.traverse(func(node *mappedMeshBVHNode) bool {
+		return !found && sphereTouchesBounds(c, r, node.Min, node.Max)
+	}, func(idx int) {
+		if !found {
+			m.mesh.decode(idx, &t)
+			found = t.SphereCollision(c, r)
+		}
+	})
+	return found
+}
+
+// traverse visits the triangles in every leaf reachable
+// through nodes for which visit returns true.
+func (m *mappedMeshCollider) traverse(visit func(node *mappedMeshBVHNode) bool, f func(idx int)) {
+	if len(m.nodes) == 0 {
+		return
+	}
+	stack := make([]int, 1, 64)
+	for len(stack) > 0 {
+		nodeIdx := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &m.nodes[nodeIdx]
+		if !visit(node) {
+			continue
+		}
+		if node.Right == -1 {
+			for _, idx := range m.order[node.Start:node.End] {
+				f(int(idx))
+			}
+		} else {
+			stack = append(stack, node.Right, nodeIdx+1)
+		}
+	}
+}
+
+type mappedPLYProperty struct {
+	Offset int
+	Type   string
+	Size   int
+
+	// CountType and CountSize are only set for lists.
+	CountType string
+	CountSize int
+}
+
+func (m *mappedPLYProperty) ReadFloat(data []byte) float64 {
+	switch m.Type {
+	case "float", "float32":
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+	case "double", "float64":
+		return math.Float64frombits(binary.LittleEndian.Uint64(data))
+	}
+	return float64(readMappedPLYInt(m.Type, data))
+}
+
+func (m *mappedPLYProperty) ReadInt(data []byte) int {
+	return readMappedPLYInt(m.Type, data)
+}
+
+func (m *mappedPLYProperty) ReadCount(data []byte) int {
+	return readMappedPLYInt(m.CountType, data)
+}
+
+type mappedPLYElement struct {
+	Name   string
+	Count  int
+	Offset int
+	Stride int
+
+	// Coords stores the x, y, and z properties of the
+	// vertex element.
+	Coords [3]*mappedPLYProperty
+
+	// Indices stores the list property of the face
+	// element.
+	Indices *mappedPLYProperty
+}
+
+type mappedPLYHeader struct {
+	Vertices *mappedPLYElement
+	Faces    *mappedPLYElement
+}
+
+func parseMappedPLYHeader(data []byte) (*mappedPLYHeader, error) {
+	endMarker := []byte("end_header\n")
+	headerEnd := bytes.Index(data, endMarker)
+	if headerEnd == -1 || !bytes.HasPrefix(data, []byte("ply\n")) {
+		return nil, errors.New("missing PLY header")
+	}
+	lines := strings.Split(string(data[:headerEnd]), "\n")
+
+	var elements []*mappedPLYElement
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 || fields[1] != "binary_little_endian" {
+				return nil, errors.New("only binary_little_endian PLY files are supported")
+			}
+		case "element":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("invalid element line: %s", line)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("invalid element count: %s", fields[2])
+			}
+			elements = append(elements, &mappedPLYElement{Name: fields[1], Count: count})
+		case "property":
+			if len(elements) == 0 {
+				return nil, errors.New("property outside of element")
+			}
+			if err := addMappedPLYProperty(elements[len(elements)-1], fields); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res := &mappedPLYHeader{}
+	offset := headerEnd + len(endMarker)
+	for _, elem := range elements {
+		if elem.Stride < 0 {
+			if res.Vertices != nil && res.Faces != nil {
+				// Variable-size data at the end can be
+				// ignored.
+				break
+			}
+			return nil, fmt.Errorf("unsupported list properties in element: %s", elem.Name)
+		}
+		elem.Offset = offset
+		offset += elem.Count * elem.Stride
+		if elem.Name == "vertex" {
+			for i, c := range elem.Coords {
+				if c == nil {
+					return nil, fmt.Errorf("missing vertex property: %c", 'x'+i)
+				}
+			}
+			res.Vertices = elem
+		} else if elem.Name == "face" {
+			if elem.Indices == nil {
+				return nil, errors.New("missing face vertex indices")
+			}
+			res.Faces = elem
+		}
+	}
+	if res.Vertices == nil || res.Faces == nil {
+		return nil, errors.New("missing vertex or face element")
+	}
+	return res, nil
+}
+
+// addMappedPLYProperty adds a property to an element.
+//
+// Since faces are required to be triangles, a single list
+// property is given a fixed size of three entries in the
+// face element.
+// In other elements, lists make the stride negative to
+// mark the element as variable-size.
+func addMappedPLYProperty(elem *mappedPLYElement, fields []string) error {
+	if elem.Stride < 0 {
+		return nil
+	}
+	if len(fields) == 5 && fields[1] == "list" {
+		countSize := mappedPLYTypeSize(fields[2])
+		size := mappedPLYTypeSize(fields[3])
+		if !mappedPLYIntType(fields[2]) || !mappedPLYIntType(fields[3]) {
+			return fmt.Errorf("unsupported list types: %s %s", fields[2], fields[3])
+		}
+		if elem.Name != "face" || elem.Indices != nil {
+			elem.Stride = -1
+			return nil
+		}
+		elem.Indices = &mappedPLYProperty{
+			Offset:    elem.Stride,
+			Type:      fields[3],
+			Size:      size,
+			CountType: fields[2],
+			CountSize: countSize,
+		}
+		elem.Stride += countSize + 3*size
+		return nil
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("invalid property: %s", strings.Join(fields, " "))
+	}
+	size := mappedPLYTypeSize(fields[1])
+	if size == 0 {
+		return fmt.Errorf("unsupported property type: %s", fields[1])
+	}
+	prop := &mappedPLYProperty{Offset: elem.Stride, Type: fields[1], Size: size}
+	elem.Stride += size
+	if elem.Name == "vertex" {
+		switch fields[2] {
+		case "x":
+			elem.Coords[0] = prop
+		case "y":
+			elem.Coords[1] = prop
+		case "z":
+			elem.Coords[2] = prop
+		}
+	}
+	return nil
+}
+
+func mappedPLYTypeSize(typeName string) int {
+	switch typeName {
+	case "char", "int8", "uchar", "uint8":
+		return 1
+	case "short", "int16", "ushort", "uint16":
+		return 2
+	case "int", "int32", "uint", "uint32", "float", "float32":
+		return 4
+	case "double", "float64":
+		return 8
+	}
+	return 0
+}
+
+func mappedPLYIntType(typeName string) bool {
+	switch typeName {
+	case "float", "float32", "double", "float64":
+		return false
+	}
+	return mappedPLYTypeSize(typeName) != 0
+}
+
+func readMappedPLYInt(typeName string, data []byte) int {
+	switch typeName {
+	case "char", "int8":
+		return int(int8(data[0]))
+	case "uchar", "uint8":
+		return int(data[0])
+	case "short", "int16":
+		return int(int16(binary.LittleEndian.Uint16(data)))
+	case "ushort", "uint16":
+		return int(binary.LittleEndian.Uint16(data))
+	case "int", "int32":
+		return int(int32(binary.LittleEndian.Uint32(data)))
+	case "uint", "uint32":
+		return int(binary.LittleEndian.Uint32(data))
+	}
+	panic("unsupported integer type: " + typeName)
+}
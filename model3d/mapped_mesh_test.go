@@ -0,0 +1,158 @@
+package model3d
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedMeshSTL(t *testing.T) {
+	mesh := NewMeshTorus(XYZ(0.5, 0.25, 0), Z(1), 0.3, 1, 20, 30)
+	path, cleanup := tempMappedMeshPath(t, "mesh.stl")
+	defer cleanup()
+	if err := mesh.SaveSTL(path); err != nil {
+		t.Fatal(err)
+	}
+	mapped, err := LoadMappedSTL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mapped.Close()
+	testMappedMesh(t, mesh, mapped)
+}
+
+func TestMappedMeshPLY(t *testing.T) {
+	mesh := NewMeshTorus(XYZ(0.5, 0.25, 0), Z(1), 0.3, 1, 20, 30)
+	path, cleanup := tempMappedMeshPath(t, "mesh.ply")
+	defer cleanup()
+
+	// Create a file with extra properties and elements to
+	// make sure they are skipped properly.
+	vertices := mesh.VertexSlice()
+	indices := map[Coord3D]int{}
+	for i, v := range vertices {
+		indices[v] = i
+	}
+	tris := mesh.TriangleSlice()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "ply\nformat binary_little_endian 1.0\ncomment test\n"+
+		"element info 2\nproperty short value\n"+
+		"element vertex %d\nproperty uchar red\nproperty double z\n"+
+		"property float x\nproperty float y\n"+
+		"element face %d\nproperty list uchar int vertex_indices\nproperty uchar flag\n"+
+		"end_header\n", len(vertices), len(tris))
+	binary.Write(&buf, binary.LittleEndian, []int16{1, 2})
+	for _, v := range vertices {
+		binary.Write(&buf, binary.LittleEndian, uint8(3))
+		binary.Write(&buf, binary.LittleEndian, v.Z)
+		binary.Write(&buf, binary.LittleEndian, []float32{float32(v.X), float32(v.Y)})
+	}
+	for _, tri := range tris {
+		binary.Write(&buf, binary.LittleEndian, uint8(3))
+		for _, c := range tri {
+			binary.Write(&buf, binary.LittleEndian, int32(indices[c]))
+		}
+		binary.Write(&buf, binary.LittleEndian, uint8(7))
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mapped, err := LoadMappedPLY(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mapped.Close()
+
+	// Round the expected mesh to match the file.
+	expected := mesh.MapCoords(func(c Coord3D) Coord3D {
+		return XYZ(float64(float32(c.X)), float64(float32(c.Y)), c.Z)
+	})
+	testMappedMesh(t, expected, mapped)
+}
+
+func TestMappedMeshErrors(t *testing.T) {
+	path, cleanup := tempMappedMeshPath(t, "mesh.ply")
+	defer cleanup()
+
+	data := []byte("ply\nformat ascii 1.0\nelement vertex 0\n" +
+		"element face 0\nproperty list uchar int vertex_indices\nend_header\n")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMappedPLY(path); err == nil {
+		t.Error("expected error for ASCII PLY")
+	}
+
+	// Truncated vertices after the face element.
+	var buf bytes.Buffer
+	buf.WriteString("ply\nformat binary_little_endian 1.0\n" +
+		"element face 1\nproperty list uchar int vertex_indices\n" +
+		"element vertex 3\nproperty float x\nproperty float y\nproperty float z\n" +
+		"end_header\n")
+	binary.Write(&buf, binary.LittleEndian, uint8(3))
+	binary.Write(&buf, binary.LittleEndian, []int32{0, 1, 2})
+	binary.Write(&buf, binary.LittleEndian, []float32{1, 2, 3})
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMappedPLY(path); err == nil {
+		t.Error("expected error for truncated vertices")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("solid x\nfacet normal 0 0 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMappedSTL(path); err == nil {
+		t.Error("expected error for ASCII STL")
+	}
+}
+
+func testMappedMesh(t *testing.T, expected *Mesh, mapped *MappedMesh) {
+	if mapped.NumTriangles() != len(expected.TriangleSlice()) {
+		t.Fatalf("expected %d triangles but got %d", len(expected.TriangleSlice()),
+			mapped.NumTriangles())
+	}
+	if mapped.Min().Dist(expected.Min()) > 1e-5 || mapped.Max().Dist(expected.Max()) > 1e-5 {
+		t.Errorf("unexpected bounds %v-%v (expected %v-%v)", mapped.Min(), mapped.Max(),
+			expected.Min(), expected.Max())
+	}
+	if !meshesEqual(expected.SnapToGrid(1e-5), mapped.Mesh().SnapToGrid(1e-5)) {
+		t.Error("decoded mesh does not match")
+	}
+
+	actual := mapped.Collider()
+	collider := MeshToCollider(expected)
+	for i := 0; i < 1000; i++ {
+		ray := &Ray{
+			Origin:    NewCoord3DRandNorm().Scale(2),
+			Direction: NewCoord3DRandUnit(),
+		}
+		if n1, n2 := collider.RayCollisions(ray, nil), actual.RayCollisions(ray, nil); n1 != n2 {
+			t.Fatalf("expected %d collisions but got %d", n1, n2)
+		}
+		c1, ok1 := collider.FirstRayCollision(ray)
+		c2, ok2 := actual.FirstRayCollision(ray)
+		if ok1 != ok2 || math.Abs(c1.Scale-c2.Scale) > 1e-5 {
+			t.Fatalf("expected collision %v (%v) but got %v (%v)", c1, ok1, c2, ok2)
+		}
+		center := NewCoord3DRandNorm()
+		radius := math.Abs(NewCoord3DRandNorm().X) * 0.3
+		if collider.SphereCollision(center, radius) != actual.SphereCollision(center, radius) {
+			t.Fatal("mismatched sphere collision")
+		}
+	}
+}
+
+func tempMappedMeshPath(t *testing.T, name string) (string, func()) {
+	dir, err := ioutil.TempDir("", "model3d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, name), func() { os.RemoveAll(dir) }
+}
@@ -12,32 +12,216 @@ import (
 // MarchingCubes turns a Solid into a surface mesh using a
 // corrected marching cubes algorithm.
 func MarchingCubes(s Solid, delta float64) *Mesh {
+	return marchingCubes(s, delta, nil)
+}
+
+func marchingCubes(s Solid, delta float64, l Logger) *Mesh {
+	mesh := NewMesh()
+	mcStreamLayers(s, delta, l, func(tris []*Triangle) {
+		for _, t := range tris {
+			mesh.Add(t)
+		}
+	})
+	return mesh
+}
+
+// MarchingCubesStream is like MarchingCubes, but rather
+// than building a mesh, it passes every triangle to f as
+// soon as it is created.
+//
+// The grid is scanned one layer at a time along the Z
+// axis, and only a few planes of grid corners are stored
+// at once.
+// Thus, the memory used is proportional to the area of
+// the XY cross-section of the grid rather than its
+// volume, making it possible to triangulate very large
+// grids if f writes the triangles to a file.
+//
+// The function f is called from a single Goroutine, and
+// the triangles are the same as those from MarchingCubes.
+func MarchingCubesStream(s Solid, delta float64, f func(t *Triangle)) {
+	mcStreamLayers(s, delta, nil, func(tris []*Triangle) {
+		for _, t := range tris {
+			f(t)
+		}
+	})
+}
+
+// MarchingCubesSearchStream is like MarchingCubesStream,
+// but it applies the search step from MarchingCubesSearch
+// to every triangle before passing it to f.
+//
+// Searched vertices are only remembered for two layers of
+// the grid, so memory usage remains bounded.
+// The triangles are the same as those from
+// MarchingCubesSearch.
+func MarchingCubesSearchStream(s Solid, delta float64, iters int, f func(t *Triangle)) {
+	mcSearchStreamLayers(s, delta, iters, func(tris []*Triangle) {
+		for _, t := range tris {
+			f(t)
+		}
+	})
+}
+
+// mcSearchStreamLayers is like mcStreamLayers, but it
+// applies the search step to the triangles of every layer.
+func mcSearchStreamLayers(s Solid, delta float64, iters int, f func(tris []*Triangle)) {
+	if iters == 0 {
+		mcStreamLayers(s, delta, nil, f)
+		return
+	}
+
+	min := s.Min().Array()
+
+	// Vertices on the top of one layer are shared with the
+	// bottom of the next layer, so we keep the previous
+	// layer's results around.
+	prevLayer := map[Coord3D]Coord3D{}
+	mcStreamLayers(s, delta, nil, func(tris []*Triangle) {
+		curLayer := map[Coord3D]Coord3D{}
+		var newVertices []Coord3D
+		for _, t := range tris {
+			for _, c := range t {
+				if _, ok := prevLayer[c]; ok {
+					continue
+				}
+				if _, ok := curLayer[c]; !ok {
+					curLayer[c] = c
+					newVertices = append(newVertices, c)
+				}
+			}
+		}
+		searched := make([]Coord3D, len(newVertices))
+		essentials.ConcurrentMap(0, len(newVertices), func(i int) {
+			searched[i] = mcSearchPoint(s, delta, iters, min, newVertices[i])
+		})
+		for i, c := range newVertices {
+			curLayer[c] = searched[i]
+		}
+		for _, t := range tris {
+			for i, c := range t {
+				if out, ok := curLayer[c]; ok {
+					t[i] = out
+				} else {
+					t[i] = prevLayer[c]
+				}
+			}
+		}
+		f(tris)
+		prevLayer = curLayer
+	})
+}
+
+// mcStreamLayers runs marching cubes and calls f with the
+// triangles from each layer of the grid, in order.
+func mcStreamLayers(s Solid, delta float64, l Logger, f func(tris []*Triangle)) {
 	if !BoundsValid(s) {
 		panic("invalid bounds for solid")
 	}
 
+	phase := StartLogPhase(l, "marching cubes")
+	defer phase.End()
+
 	table := mcLookupTable()
 	spacer := newSquareSpacer(s, delta)
-	mesh := NewMesh()
+	var tris []*Triangle
 	spacer.Scan(s, func(z int, bottomCache, topCache *solidCache) {
-		for y := 0; y < len(spacer.Ys)-1; y++ {
-			for x := 0; x < len(spacer.Xs)-1; x++ {
-				bits := bottomCache.GetSquare(x, y) | (topCache.GetSquare(x, y) << 4)
-				triangles := table[bits]
-				if len(triangles) > 0 {
-					min := spacer.CornerCoord(x, y, z-1)
-					max := spacer.CornerCoord(x+1, y+1, z)
-					corners := mcCornerCoordinates(min, max)
-					for _, t := range triangles {
-						mesh.Add(t.Triangle(corners))
-					}
+		tris = tris[:0]
+		mcLayerTriangles(table, spacer, z, bottomCache, topCache, func(t *Triangle) {
+			tris = append(tris, t)
+		})
+		f(tris)
+		phase.Progress(float64(z) / float64(spacer.NumZ-1))
+	})
+}
+
+// MarchingCubesParallel is like MarchingCubes, but it
+// splits the grid into slabs along the Z axis and
+// triangulates the slabs concurrently.
+//
+// The numWorkers argument specifies the number of
+// Goroutines to use. If it is 0, GOMAXPROCS is used.
+//
+// The resulting mesh is identical to the result of
+// MarchingCubes, regardless of the number of workers.
+func MarchingCubesParallel(s Solid, delta float64, numWorkers int) *Mesh {
+	if !BoundsValid(s) {
+		panic("invalid bounds for solid")
+	}
+	if numWorkers == 0 {
+		numWorkers = runtime.GOMAXPROCS(0)
+	}
+
+	table := mcLookupTable()
+	spacer := newSquareSpacer(s, delta)
+
+	// Use more slabs than workers so that slow regions of
+	// the solid do not leave most workers idle.
+	numLayers := spacer.NumZ - 1
+	numSlabs := essentials.MinInt(numLayers, numWorkers*4)
+	slabs := make([][]*Triangle, numSlabs)
+
+	slabStart := func(i int) int {
+		return i * numLayers / numSlabs
+	}
+
+	// Compute the layers shared between adjacent slabs
+	// once, so that both slabs see the same values.
+	boundaries := make([]*solidCache, numSlabs+1)
+	essentials.ConcurrentMap(numWorkers, numSlabs+1, func(i int) {
+		boundaries[i] = newSolidCache(s, spacer)
+		boundaries[i].FetchZ(slabStart(i))
+	})
+
+	essentials.ConcurrentMap(numWorkers, numSlabs, func(i int) {
+		startZ, endZ := slabStart(i), slabStart(i+1)
+		bottom := boundaries[i]
+		var scratch [2]*solidCache
+		for z := startZ + 1; z <= endZ; z++ {
+			var top *solidCache
+			if z == endZ {
+				top = boundaries[i+1]
+			} else {
+				if scratch[z%2] == nil {
+					scratch[z%2] = newSolidCache(s, spacer)
 				}
+				top = scratch[z%2]
+				top.FetchZ(z)
 			}
+			mcLayerTriangles(table, spacer, z, bottom, top, func(t *Triangle) {
+				slabs[i] = append(slabs[i], t)
+			})
+			bottom = top
 		}
 	})
+
+	mesh := NewMesh()
+	for _, slab := range slabs {
+		for _, t := range slab {
+			mesh.Add(t)
+		}
+	}
 	return mesh
 }
 
+func mcLayerTriangles(table [256][]mcTriangle, spacer *squareSpacer, z int,
+	bottomCache, topCache *solidCache, f func(t *Triangle)) {
+	for y := 0; y < spacer.NumY-1; y++ {
+		for x := 0; x < spacer.NumX-1; x++ {
+			bits := bottomCache.GetSquare(x, y) | (topCache.GetSquare(x, y) << 4)
+			triangles := table[bits]
+			if len(triangles) > 0 {
+				min := spacer.CornerCoord(x, y, z-1)
+				max := spacer.CornerCoord(x+1, y+1, z)
+				corners := mcCornerCoordinates(min, max)
+				for _, t := range triangles {
+					f(t.Triangle(corners))
+				}
+			}
+		}
+	}
+}
+
 // MarchingCubesSearch is like MarchingCubes, but applies
 // an additional search step to move the vertices along
 // the edges of each cube.
@@ -46,17 +230,46 @@ func MarchingCubes(s Solid, delta float64) *Mesh {
 // every iteration.
 func MarchingCubesSearch(s Solid, delta float64, iters int) *Mesh {
 	mesh := MarchingCubes(s, delta)
+	mcSearchMesh(s, delta, iters, mesh, nil)
+	return mesh
+}
+
+// MarchingCubesSearchLog is like MarchingCubesSearch, but
+// reports progress to a Logger.
+//
+// The phases are "marching cubes" and "search".
+func MarchingCubesSearchLog(s Solid, delta float64, iters int, l Logger) *Mesh {
+	mesh := marchingCubes(s, delta, l)
+	mcSearchMesh(s, delta, iters, mesh, l)
+	return mesh
+}
+
+// MarchingCubesSearchParallel is like MarchingCubesSearch,
+// but it uses MarchingCubesParallel for the initial mesh.
+func MarchingCubesSearchParallel(s Solid, delta float64, iters, numWorkers int) *Mesh {
+	mesh := MarchingCubesParallel(s, delta, numWorkers)
+	mcSearchMesh(s, delta, iters, mesh, nil)
+	return mesh
+}
 
+// mcSearchMesh moves the vertices of a marching cubes mesh
+// in place using mcSearchPoint.
+func mcSearchMesh(s Solid, delta float64, iters int, mesh *Mesh, l Logger) {
 	if iters == 0 {
-		return mesh
+		return
 	}
 
+	phase := StartLogPhase(l, "search")
+	defer phase.End()
+
 	inVertices := mesh.VertexSlice()
 	outVertices := make([]Coord3D, len(inVertices))
 
 	min := s.Min().Array()
+	var numDone int64
 	essentials.ConcurrentMap(0, len(inVertices), func(i int) {
-		outVertices[i] = mcSearchPoint(s, delta, iters, mesh, min, inVertices[i])
+		outVertices[i] = mcSearchPoint(s, delta, iters, min, inVertices[i])
+		phase.Progress(float64(atomic.AddInt64(&numDone, 1)) / float64(len(inVertices)))
 	})
 
 	v2t := mesh.getVertexToFace()
@@ -75,8 +288,6 @@ func MarchingCubesSearch(s Solid, delta float64, iters int) *Mesh {
 	// We just invalidated the entire v2t cache by
 	// replacing the vertices in the triangles.
 	mesh.vertexToFace = atomic.Value{}
-
-	return mesh
 }
 
 // MarchingCubesConj is like MarchingCubesSearch, but in a
@@ -90,7 +301,7 @@ func MarchingCubesConj(s Solid, delta float64, iters int, xforms ...Transform) *
 	return mesh.Transform(joined.Inverse())
 }
 
-func mcSearchPoint(s Solid, delta float64, iters int, m *Mesh, min [3]float64, c Coord3D) Coord3D {
+func mcSearchPoint(s Solid, delta float64, iters int, min [3]float64, c Coord3D) Coord3D {
 	arr := c.Array()
 
 	// Figure out which axis the containing edge spans.
@@ -108,7 +319,12 @@ func mcSearchPoint(s Solid, delta float64, iters int, m *Mesh, min [3]float64, c
 	if axis == -1 {
 		panic("vertex not on edge")
 	}
-	if m.Find(c)[0].Normal().Array()[axis] > 0 {
+
+	// Check the endpoint directly rather than using the
+	// normal of an arbitrary neighboring triangle, so that
+	// the result is deterministic.
+	arr[axis] = falsePoint
+	if s.Contains(NewCoord3DArray(arr)) {
 		truePoint, falsePoint = falsePoint, truePoint
 	}
 
@@ -455,62 +671,70 @@ var baseTriangleTable = map[mcIntersections][]mcTriangle{
 	newMcIntersections(0, 1, 2, 3, 4, 5, 6, 7): {},
 }
 
+// squareSpacer describes a grid of corners with spacing
+// delta which surrounds a solid.
+//
+// Coordinates are computed on demand rather than stored,
+// so the spacer uses constant memory regardless of the
+// size of the grid.
 type squareSpacer struct {
-	Xs []float64
-	Ys []float64
-	Zs []float64
+	Origin Coord3D
+	Delta  float64
+	NumX   int
+	NumY   int
+	NumZ   int
 }
 
 func newSquareSpacer(s Solid, delta float64) *squareSpacer {
-	var xs, ys, zs []float64
 	min := s.Min()
-	max := s.Max()
-	for x := min.X - delta; x <= max.X+delta; x += delta {
-		xs = append(xs, x)
-	}
-	for y := min.Y - delta; y <= max.Y+delta; y += delta {
-		ys = append(ys, y)
+	size := s.Max().Sub(min)
+	count := func(size float64) int {
+		// Include one extra corner on either side of the
+		// bounds, so that the outermost corners are never
+		// inside the solid.
+		return int(math.Floor(size/delta+1e-8)) + 3
 	}
-	for z := min.Z - delta; z <= max.Z+delta; z += delta {
-		zs = append(zs, z)
+	return &squareSpacer{
+		Origin: min.Sub(XYZ(delta, delta, delta)),
+		Delta:  delta,
+		NumX:   count(size.X),
+		NumY:   count(size.Y),
+		NumZ:   count(size.Z),
 	}
-	return &squareSpacer{Xs: xs, Ys: ys, Zs: zs}
 }
 
 func (s *squareSpacer) CornerCoord(x, y, z int) Coord3D {
-	return XYZ(s.Xs[x], s.Ys[y], s.Zs[z])
+	return XYZ(
+		s.Origin.X+float64(x)*s.Delta,
+		s.Origin.Y+float64(y)*s.Delta,
+		s.Origin.Z+float64(z)*s.Delta,
+	)
 }
 
+// Scan calls f for every pair of consecutive planes of
+// corners, in order of increasing z.
+//
+// Only three planes are stored at once: the two passed to
+// f, and the next plane, which is computed while f runs.
 func (s *squareSpacer) Scan(solid Solid, f func(z int, bottom, top *solidCache)) {
-	numGos := runtime.GOMAXPROCS(0)
-
-	// Prevent edge case where we are making a very
-	// flat object on a multi-core machine.
-	if numGos > len(s.Zs)-1 {
-		numGos = len(s.Zs) - 1
-	}
-
-	caches := make([]*asyncSolidCache, numGos+1)
+	var caches [3]*solidCache
 	for i := range caches {
-		caches[i] = &asyncSolidCache{
-			Cache: newSolidCache(solid, s),
-			Done:  make(chan struct{}, 1),
-		}
-		caches[i].FetchZ(i)
+		caches[i] = newSolidCache(solid, s)
 	}
-
-	<-caches[0].Done
-	for nextZ := 1; nextZ < len(s.Zs); nextZ++ {
-		prevIdx := (nextZ - 1) % len(caches)
-		curIdx := nextZ % len(caches)
-
-		<-caches[curIdx].Done
-
-		f(nextZ, caches[prevIdx].Cache, caches[curIdx].Cache)
-
-		if nextZ+len(caches)-1 < len(s.Zs) {
-			caches[prevIdx].FetchZ(nextZ + len(caches) - 1)
+	caches[0].FetchZConcurrent(0)
+	caches[1].FetchZConcurrent(1)
+	for z := 1; z < s.NumZ; z++ {
+		done := make(chan struct{})
+		if z+1 < s.NumZ {
+			go func(next *solidCache, nextZ int) {
+				defer close(done)
+				next.FetchZConcurrent(nextZ)
+			}(caches[(z+1)%3], z+1)
+		} else {
+			close(done)
 		}
+		f(z, caches[(z-1)%3], caches[z%3])
+		<-done
 	}
 }
 
@@ -524,30 +748,42 @@ func newSolidCache(solid Solid, spacer *squareSpacer) *solidCache {
 	return &solidCache{
 		spacer: spacer,
 		solid:  solid,
-		values: make([]bool, len(spacer.Xs)*len(spacer.Ys)),
+		values: make([]bool, spacer.NumX*spacer.NumY),
 	}
 }
 
+// FetchZ evaluates the solid on a plane of corners.
 func (s *solidCache) FetchZ(z int) {
-	maxX := len(s.spacer.Xs) - 1
-	maxY := len(s.spacer.Ys) - 1
-	onEdge := z == 0 || z == len(s.spacer.Zs)-1
-
-	var idx int
-	for i := 0; i < len(s.spacer.Ys); i++ {
-		for j := 0; j < len(s.spacer.Xs); j++ {
-			b := s.solid.Contains(s.spacer.CornerCoord(j, i, z))
-			s.values[idx] = b
-			idx++
-			if b && (onEdge || i == 0 || j == 0 || i == maxY || j == maxX) {
-				panic("solid is true outside of bounds")
-			}
+	for y := 0; y < s.spacer.NumY; y++ {
+		s.fetchRow(y, z)
+	}
+}
+
+// FetchZConcurrent is like FetchZ, but it evaluates rows
+// of the plane on multiple Goroutines.
+func (s *solidCache) FetchZConcurrent(z int) {
+	essentials.ConcurrentMap(0, s.spacer.NumY, func(y int) {
+		s.fetchRow(y, z)
+	})
+}
+
+func (s *solidCache) fetchRow(y, z int) {
+	maxX := s.spacer.NumX - 1
+	onEdge := z == 0 || z == s.spacer.NumZ-1 || y == 0 || y == s.spacer.NumY-1
+
+	idx := y * s.spacer.NumX
+	for x := 0; x <= maxX; x++ {
+		b := s.solid.Contains(s.spacer.CornerCoord(x, y, z))
+		s.values[idx] = b
+		idx++
+		if b && (onEdge || x == 0 || x == maxX) {
+			panic("solid is true outside of bounds")
 		}
 	}
 }
 
 func (s *solidCache) Get(x, y int) bool {
-	return s.values[x+y*len(s.spacer.Xs)]
+	return s.values[x+y*s.spacer.NumX]
 }
 
 func (s *solidCache) GetSquare(x, y int) mcIntersections {
@@ -563,15 +799,3 @@ func (s *solidCache) GetSquare(x, y int) mcIntersections {
 	}
 	return result
 }
-
-type asyncSolidCache struct {
-	Cache *solidCache
-	Done  chan struct{}
-}
-
-func (a *asyncSolidCache) FetchZ(z int) {
-	go func() {
-		a.Cache.FetchZ(z)
-		a.Done <- struct{}{}
-	}()
-}
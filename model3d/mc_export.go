@@ -0,0 +1,205 @@
+package model3d
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/fileformats"
+)
+
+// MarchingCubesSTL is like MarchingCubesSearchStream, but
+// it writes the triangles directly to a binary STL file.
+//
+// Since the mesh is never stored in memory, this can be
+// used for models whose meshes would not fit in memory.
+//
+// The writer must support seeking, such as an *os.File,
+// so that the number of triangles can be filled in once
+// all of the triangles have been written.
+func MarchingCubesSTL(w io.WriteSeeker, s Solid, delta float64, iters int) (err error) {
+	defer essentials.AddCtxTo("marching cubes STL", &err)
+
+	writer, err := fileformats.NewSTLStreamWriter(w)
+	if err != nil {
+		return err
+	}
+	mcSearchStreamLayers(s, delta, iters, func(tris []*Triangle) {
+		for _, t := range tris {
+			if err != nil {
+				return
+			}
+			verts := [3][3]float32{
+				castVector32(t[0]),
+				castVector32(t[1]),
+				castVector32(t[2]),
+			}
+			err = writer.WriteTriangle(castVector32(t.Normal()), verts)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// MarchingCubesPLY is like MarchingCubesSearchStream, but
+// it writes the triangles to a PLY file like WritePLY.
+//
+// If weld is true, vertices shared by multiple triangles
+// are only written once.
+// Only the vertices of two layers of the grid are
+// remembered at a time, so memory usage remains bounded.
+// If weld is false, every triangle has its own vertices.
+//
+// The colorFunc maps coordinates to 24-bit RGB colors.
+// If it is nil, every vertex is white.
+//
+// Since PLY files store every vertex before any of the
+// faces, the vertices and faces are buffered in temporary
+// files before being written to w.
+func MarchingCubesPLY(w io.Writer, s Solid, delta float64, iters int, weld bool,
+	colorFunc func(Coord3D) [3]uint8) (err error) {
+	defer essentials.AddCtxTo("marching cubes PLY", &err)
+
+	vertexFile, err := newMCTempFile()
+	if err != nil {
+		return err
+	}
+	defer vertexFile.Close()
+	faceFile, err := newMCTempFile()
+	if err != nil {
+		return err
+	}
+	defer faceFile.Close()
+
+	if colorFunc == nil {
+		colorFunc = func(c Coord3D) [3]uint8 {
+			return [3]uint8{255, 255, 255}
+		}
+	}
+
+	var numVertices, numFaces int64
+	addVertex := func(c Coord3D) int64 {
+		if err == nil {
+			color := colorFunc(c)
+			err = vertexFile.Write(c.Array(), color)
+		}
+		numVertices++
+		return numVertices - 1
+	}
+
+	prevLayer := map[Coord3D]int64{}
+	mcSearchStreamLayers(s, delta, iters, func(tris []*Triangle) {
+		curLayer := map[Coord3D]int64{}
+		for _, t := range tris {
+			var face [3]int64
+			for i, c := range t {
+				if !weld {
+					face[i] = addVertex(c)
+				} else if idx, ok := curLayer[c]; ok {
+					face[i] = idx
+				} else if idx, ok := prevLayer[c]; ok {
+					face[i] = idx
+				} else {
+					face[i] = addVertex(c)
+					curLayer[c] = face[i]
+				}
+			}
+			if err == nil {
+				err = faceFile.Write(face)
+			}
+			numFaces++
+		}
+		prevLayer = curLayer
+	})
+	if err != nil {
+		return err
+	}
+
+	p, err := fileformats.NewPLYWriter(w, int(numVertices), int(numFaces))
+	if err != nil {
+		return err
+	}
+	if err := vertexFile.Rewind(); err != nil {
+		return err
+	}
+	for i := int64(0); i < numVertices; i++ {
+		var coord [3]float64
+		var color [3]uint8
+		if err := vertexFile.Read(&coord, &color); err != nil {
+			return err
+		}
+		if err := p.WriteCoord(coord, color); err != nil {
+			return err
+		}
+	}
+	if err := faceFile.Rewind(); err != nil {
+		return err
+	}
+	for i := int64(0); i < numFaces; i++ {
+		var face [3]int64
+		if err := faceFile.Read(&face); err != nil {
+			return err
+		}
+		if err := p.WriteTriangle([3]int{int(face[0]), int(face[1]), int(face[2])}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mcTempFile is a buffered temporary file of binary
+// records, which is deleted when it is closed.
+type mcTempFile struct {
+	f *os.File
+	w *bufio.Writer
+	r *bufio.Reader
+}
+
+func newMCTempFile() (*mcTempFile, error) {
+	f, err := ioutil.TempFile("", "model3d_mc")
+	if err != nil {
+		return nil, err
+	}
+	return &mcTempFile{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (m *mcTempFile) Write(data ...interface{}) error {
+	for _, x := range data {
+		if err := binary.Write(m.w, binary.LittleEndian, x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rewind flushes written data and prepares to read the
+// file from the beginning.
+func (m *mcTempFile) Rewind() error {
+	if err := m.w.Flush(); err != nil {
+		return err
+	}
+	if _, err := m.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	m.r = bufio.NewReader(m.f)
+	return nil
+}
+
+func (m *mcTempFile) Read(data ...interface{}) error {
+	for _, x := range data {
+		if err := binary.Read(m.r, binary.LittleEndian, x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mcTempFile) Close() error {
+	m.f.Close()
+	return os.Remove(m.f.Name())
+}
@@ -0,0 +1,129 @@
+package model3d
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMarchingCubesSTL(t *testing.T) {
+	solid := JoinedSolid{
+		&CylinderSolid{P1: XYZ(1, 2, 3), P2: XYZ(3, 1, 4), Radius: 0.5},
+		&Sphere{Center: XYZ(1, 1, 3), Radius: 0.7},
+	}
+	for _, iters := range []int{0, 4} {
+		f, err := ioutil.TempFile("", "mc_stl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		if err := MarchingCubesSTL(f, solid, 0.05, iters); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		actual, err := LoadSTL(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := MarchingCubesSearch(solid, 0.05, iters)
+		if len(actual.TriangleSlice()) != len(expected.TriangleSlice()) {
+			t.Errorf("iters %d: expected %d triangles but got %d", iters,
+				len(expected.TriangleSlice()), len(actual.TriangleSlice()))
+		}
+		MustValidateMesh(t, actual, true)
+		if v := actual.Volume(); math.Abs(v-expected.Volume()) > 1e-4 {
+			t.Errorf("iters %d: unexpected volume %f", iters, v)
+		}
+	}
+}
+
+func TestMarchingCubesPLY(t *testing.T) {
+	solid := JoinedSolid{
+		&CylinderSolid{P1: XYZ(1, 2, 3), P2: XYZ(3, 1, 4), Radius: 0.5},
+		&Sphere{Center: XYZ(1, 1, 3), Radius: 0.7},
+	}
+	expected := MarchingCubesSearch(solid, 0.05, 2)
+	numTris := len(expected.TriangleSlice())
+	white := func(c Coord3D) [3]uint8 {
+		return [3]uint8{255, 255, 255}
+	}
+
+	for _, weld := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := MarchingCubesPLY(&buf, solid, 0.05, 2, weld, white); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		var numVertices, numFaces, headerEnd int
+		for i, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) == 3 && fields[0] == "element" {
+				n, _ := strconv.Atoi(fields[2])
+				if fields[1] == "vertex" {
+					numVertices = n
+				} else if fields[1] == "face" {
+					numFaces = n
+				}
+			} else if line == "end_header" {
+				headerEnd = i
+				break
+			}
+		}
+		if numFaces != numTris {
+			t.Errorf("weld %v: expected %d faces but got %d", weld, numTris, numFaces)
+		}
+		expectedVertices := 3 * numTris
+		if weld {
+			expectedVertices = len(expected.VertexSlice())
+		}
+		if numVertices != expectedVertices {
+			t.Errorf("weld %v: expected %d vertices but got %d", weld, expectedVertices,
+				numVertices)
+		}
+		if len(lines)-headerEnd-1 != numVertices+numFaces {
+			t.Errorf("weld %v: unexpected line count", weld)
+		}
+		for _, line := range lines[headerEnd+1+numVertices:] {
+			fields := strings.Fields(line)
+			for _, field := range fields[1:] {
+				idx, err := strconv.Atoi(field)
+				if err != nil || idx < 0 || idx >= numVertices {
+					t.Fatalf("weld %v: invalid face: %s", weld, line)
+				}
+			}
+		}
+	}
+}
+
+func TestMarchingCubesPLYNilColor(t *testing.T) {
+	solid := &Sphere{Center: XYZ(1, 1, 3), Radius: 0.7}
+	var buf bytes.Buffer
+	if err := MarchingCubesPLY(&buf, solid, 0.1, 0, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var numVertices int
+	for i, line := range lines {
+		if line == "end_header" {
+			lines = lines[i+1:]
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "element" && fields[1] == "vertex" {
+			numVertices, _ = strconv.Atoi(fields[2])
+		}
+	}
+	if numVertices == 0 {
+		t.Fatal("no vertices written")
+	}
+	for _, line := range lines[:numVertices] {
+		if !strings.HasSuffix(line, " 255 255 255") {
+			t.Fatalf("unexpected vertex: %s", line)
+		}
+	}
+}
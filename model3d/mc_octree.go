@@ -0,0 +1,280 @@
+package model3d
+
+import (
+	"math"
+	"sort"
+
+	"github.com/unixpickle/essentials"
+)
+
+const (
+	octreeCornerUnknown = iota
+	octreeCornerInside
+	octreeCornerOutside
+)
+
+// MarchingCubesOctree is like MarchingCubes, but it uses
+// an octree to avoid evaluating the solid far away from
+// its surface.
+//
+// The grid is split into blocks no larger than maxDelta,
+// and blocks whose corners are all inside or all outside
+// of the solid are skipped.
+// Other blocks are recursively subdivided into cubes of
+// size minDelta, and then the surface is traced through
+// neighboring cubes to fill in any parts of it that were
+// missed by the octree.
+//
+// As long as the octree finds some part of every
+// connected piece of the surface, the result is identical
+// to MarchingCubes(s, minDelta).
+// However, features smaller than maxDelta may be missed
+// entirely, so maxDelta should be smaller than the
+// smallest disconnected part of the solid.
+func MarchingCubesOctree(s Solid, minDelta, maxDelta float64) *Mesh {
+	if !BoundsValid(s) {
+		panic("invalid bounds for solid")
+	}
+	if maxDelta < minDelta {
+		panic("maxDelta must not be smaller than minDelta")
+	}
+
+	spacer := newSquareSpacer(s, minDelta)
+	cells := octreeSurfaceCells(s, spacer, maxDelta)
+	cells = octreeTraceSurface(s, spacer, cells)
+
+	keys := make([][3]int, 0, len(cells))
+	for key := range cells {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		k1, k2 := keys[i], keys[j]
+		for axis := 2; axis >= 0; axis-- {
+			if k1[axis] != k2[axis] {
+				return k1[axis] < k2[axis]
+			}
+		}
+		return false
+	})
+
+	table := mcLookupTable()
+	mesh := NewMesh()
+	for _, key := range keys {
+		min := spacer.CornerCoord(key[0], key[1], key[2])
+		max := spacer.CornerCoord(key[0]+1, key[1]+1, key[2]+1)
+		corners := mcCornerCoordinates(min, max)
+		for _, t := range table[cells[key]] {
+			mesh.Add(t.Triangle(corners))
+		}
+	}
+	return mesh
+}
+
+// MarchingCubesOctreeSearch is like MarchingCubesOctree,
+// but applies the search step from MarchingCubesSearch.
+func MarchingCubesOctreeSearch(s Solid, minDelta, maxDelta float64, iters int) *Mesh {
+	mesh := MarchingCubesOctree(s, minDelta, maxDelta)
+	mcSearchMesh(s, minDelta, iters, mesh, nil)
+	return mesh
+}
+
+// octreeSurfaceCells finds the cubes of the fine grid
+// which contain part of the surface, according to an
+// octree search.
+func octreeSurfaceCells(s Solid, spacer *squareSpacer,
+	maxDelta float64) map[[3]int]mcIntersections {
+	// Use the largest power of two which fits in maxDelta
+	// as the block size, so that blocks can be evenly
+	// subdivided down to single cubes.
+	blockSize := 1
+	for float64(blockSize*2)*spacer.Delta <= maxDelta*(1+1e-8) {
+		blockSize *= 2
+	}
+	numBlocks := [3]int{
+		(spacer.NumX - 2 + blockSize) / blockSize,
+		(spacer.NumY - 2 + blockSize) / blockSize,
+		(spacer.NumZ - 2 + blockSize) / blockSize,
+	}
+	totalBlocks := numBlocks[0] * numBlocks[1] * numBlocks[2]
+
+	blockCells := make([]map[[3]int]mcIntersections, totalBlocks)
+	essentials.StatefulConcurrentMap(0, totalBlocks, func() func(int) {
+		search := newOctreeBlockSearch(s, spacer, blockSize)
+		return func(i int) {
+			x := i % numBlocks[0]
+			y := (i / numBlocks[0]) % numBlocks[1]
+			z := i / (numBlocks[0] * numBlocks[1])
+			blockCells[i] = search.Search(x*blockSize, y*blockSize, z*blockSize)
+		}
+	})
+
+	res := map[[3]int]mcIntersections{}
+	for _, cells := range blockCells {
+		for key, value := range cells {
+			res[key] = value
+		}
+	}
+	return res
+}
+
+// octreeTraceSurface adds cubes to a set of surface cubes
+// until the surface is closed.
+//
+// Whenever a face of a cube has corners both inside and
+// outside the solid, the surface must continue into the
+// neighboring cube.
+func octreeTraceSurface(s Solid, spacer *squareSpacer,
+	cells map[[3]int]mcIntersections) map[[3]int]mcIntersections {
+	corners := map[[3]int]bool{}
+	cornerValue := func(x, y, z int) bool {
+		key := [3]int{x, y, z}
+		if value, ok := corners[key]; ok {
+			return value
+		}
+		value := s.Contains(spacer.CornerCoord(x, y, z))
+		corners[key] = value
+		return value
+	}
+
+	queue := make([][3]int, 0, len(cells))
+	for key := range cells {
+		queue = append(queue, key)
+	}
+	for len(queue) > 0 {
+		cell := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		bits := cells[cell]
+		for axis := 0; axis < 3; axis++ {
+			for side := 0; side < 2; side++ {
+				if !octreeFaceMixed(bits, axis, side) {
+					continue
+				}
+				neighbor := cell
+				neighbor[axis] += side*2 - 1
+				if _, ok := cells[neighbor]; ok {
+					continue
+				}
+				if neighbor[axis] < 0 || neighbor[axis] >= octreeNumCells(spacer, axis) {
+					panic("solid is true outside of bounds")
+				}
+				var neighborBits mcIntersections
+				for i := 0; i < 8; i++ {
+					if cornerValue(neighbor[0]+(i&1), neighbor[1]+((i>>1)&1), neighbor[2]+((i>>2)&1)) {
+						neighborBits |= 1 << uint(i)
+					}
+				}
+				cells[neighbor] = neighborBits
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return cells
+}
+
+// octreeFaceMixed checks if a face of a cube has some
+// corners inside and some outside the solid.
+//
+// The side is 0 for the face at the minimum of the axis,
+// or 1 for the face at the maximum.
+func octreeFaceMixed(bits mcIntersections, axis, side int) bool {
+	var numInside int
+	for i := 0; i < 8; i++ {
+		if (i>>uint(axis))&1 == side && bits.Inside(mcCorner(i)) {
+			numInside++
+		}
+	}
+	return numInside != 0 && numInside != 4
+}
+
+func octreeNumCells(spacer *squareSpacer, axis int) int {
+	return [3]int{spacer.NumX, spacer.NumY, spacer.NumZ}[axis] - 1
+}
+
+// octreeBlockSearch recursively subdivides a single block
+// of the coarse grid, caching the values at the corners of
+// the fine grid within the block.
+type octreeBlockSearch struct {
+	solid     Solid
+	spacer    *squareSpacer
+	blockSize int
+	numCells  [3]int
+
+	origin  [3]int
+	corners []uint8
+	result  map[[3]int]mcIntersections
+}
+
+func newOctreeBlockSearch(s Solid, spacer *squareSpacer, blockSize int) *octreeBlockSearch {
+	return &octreeBlockSearch{
+		solid:     s,
+		spacer:    spacer,
+		blockSize: blockSize,
+		numCells: [3]int{
+			octreeNumCells(spacer, 0),
+			octreeNumCells(spacer, 1),
+			octreeNumCells(spacer, 2),
+		},
+		corners: make([]uint8, int(math.Pow(float64(blockSize+1), 3))),
+	}
+}
+
+// Search finds the surface cubes in the block starting at
+// the given cube.
+//
+// The resulting map is nil if the block contains no part
+// of the surface.
+func (o *octreeBlockSearch) Search(x, y, z int) map[[3]int]mcIntersections {
+	o.origin = [3]int{x, y, z}
+	o.result = nil
+	for i := range o.corners {
+		o.corners[i] = octreeCornerUnknown
+	}
+	o.searchCube(0, 0, 0, o.blockSize)
+	return o.result
+}
+
+func (o *octreeBlockSearch) searchCube(x, y, z, size int) {
+	if x+o.origin[0] >= o.numCells[0] || y+o.origin[1] >= o.numCells[1] ||
+		z+o.origin[2] >= o.numCells[2] {
+		return
+	}
+	var bits mcIntersections
+	for i := 0; i < 8; i++ {
+		if o.corner(x+size*(i&1), y+size*((i>>1)&1), z+size*((i>>2)&1)) {
+			bits |= 1 << uint(i)
+		}
+	}
+	if bits == 0 || bits == 0xff {
+		return
+	}
+	if size == 1 {
+		if o.result == nil {
+			o.result = map[[3]int]mcIntersections{}
+		}
+		o.result[[3]int{x + o.origin[0], y + o.origin[1], z + o.origin[2]}] = bits
+		return
+	}
+	half := size / 2
+	for i := 0; i < 8; i++ {
+		o.searchCube(x+half*(i&1), y+half*((i>>1)&1), z+half*((i>>2)&1), half)
+	}
+}
+
+func (o *octreeBlockSearch) corner(x, y, z int) bool {
+	stride := o.blockSize + 1
+	idx := x + stride*(y+stride*z)
+	switch o.corners[idx] {
+	case octreeCornerInside:
+		return true
+	case octreeCornerOutside:
+		return false
+	}
+	c := o.spacer.CornerCoord(x+o.origin[0], y+o.origin[1], z+o.origin[2])
+	value := o.solid.Contains(c)
+	if value {
+		o.corners[idx] = octreeCornerInside
+	} else {
+		o.corners[idx] = octreeCornerOutside
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package model3d
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestMarchingCubesOctree(t *testing.T) {
+	solid := JoinedSolid{
+		&CylinderSolid{P1: XYZ(1, 2, 3), P2: XYZ(3, 1, 4), Radius: 0.5},
+		&Sphere{Center: XYZ(1, 1, 3), Radius: 0.7},
+	}
+	expected := MarchingCubes(solid, 0.05)
+	for _, maxDelta := range []float64{0.05, 0.1, 0.4} {
+		actual := MarchingCubesOctree(solid, 0.05, maxDelta)
+		if !meshesEqual(expected, actual) {
+			t.Errorf("mismatched mesh for maxDelta %f", maxDelta)
+		}
+	}
+
+	expected = MarchingCubesSearch(solid, 0.05, 4)
+	actual := MarchingCubesOctreeSearch(solid, 0.05, 0.2, 4)
+	if !meshesEqual(expected, actual) {
+		t.Error("mismatched search mesh")
+	}
+	MustValidateMesh(t, actual, true)
+}
+
+func TestMarchingCubesOctreeEfficiency(t *testing.T) {
+	// A large box should only be evaluated near the
+	// surface.
+	box := &countingSolid{Solid: &Rect{MaxVal: XYZ(3, 3, 3)}}
+	mesh := MarchingCubesOctree(box, 0.05, 0.4)
+	MustValidateMesh(t, mesh, true)
+
+	numCorners := (3/0.05 + 3) * (3/0.05 + 3) * (3/0.05 + 3)
+	if n := atomic.LoadInt64(&box.Count); float64(n) > numCorners/2 {
+		t.Errorf("too many evaluations: %d (grid has %d corners)", n, int(numCorners))
+	}
+
+	if !meshesEqual(mesh, MarchingCubes(box.Solid, 0.05)) {
+		t.Error("mismatched mesh")
+	}
+}
+
+func BenchmarkMarchingCubesOctree(b *testing.B) {
+	solid := &CylinderSolid{
+		P1:     XYZ(1, 2, 3),
+		P2:     XYZ(3, 1, 4),
+		Radius: 0.5,
+	}
+	for i := 0; i < b.N; i++ {
+		MarchingCubesOctree(solid, 0.025, 0.2)
+	}
+}
+
+type countingSolid struct {
+	Solid
+	Count int64
+}
+
+func (c *countingSolid) Contains(coord Coord3D) bool {
+	atomic.AddInt64(&c.Count, 1)
+	return c.Solid.Contains(coord)
+}
@@ -1,6 +1,7 @@
 package model3d
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 )
@@ -39,6 +40,60 @@ func TestMarchingCubesRandom(t *testing.T) {
 	}
 }
 
+func TestMarchingCubesParallel(t *testing.T) {
+	solid := JoinedSolid{
+		&CylinderSolid{P1: XYZ(1, 2, 3), P2: XYZ(3, 1, 4), Radius: 0.5},
+		&Sphere{Center: XYZ(1, 1, 3), Radius: 0.7},
+	}
+	expected := MarchingCubes(solid, 0.05)
+	for _, numWorkers := range []int{0, 1, 3, 1000} {
+		actual := MarchingCubesParallel(solid, 0.05, numWorkers)
+		if !meshesEqual(expected, actual) {
+			t.Errorf("mismatched mesh for %d workers", numWorkers)
+		}
+	}
+
+	expected = MarchingCubesSearch(solid, 0.05, 4)
+	actual := MarchingCubesSearchParallel(solid, 0.05, 4, 0)
+	if !meshesEqual(expected, actual) {
+		t.Error("mismatched search mesh")
+	}
+
+	for i := 0; i < 30; i++ {
+		MustValidateMesh(t, MarchingCubesParallel(randomSolid{}, 0.1, 0), true)
+	}
+}
+
+func TestMarchingCubesStream(t *testing.T) {
+	solid := JoinedSolid{
+		&CylinderSolid{P1: XYZ(1, 2, 3), P2: XYZ(3, 1, 4), Radius: 0.5},
+		&Sphere{Center: XYZ(1, 1, 3), Radius: 0.7},
+	}
+
+	actual := NewMesh()
+	lastZ := math.Inf(-1)
+	MarchingCubesStream(solid, 0.05, func(tri *Triangle) {
+		minZ := tri.Min().Z
+		if minZ < lastZ-0.05-1e-8 {
+			t.Fatal("triangles are not in layer order")
+		}
+		lastZ = math.Max(lastZ, minZ)
+		actual.Add(tri)
+	})
+	if !meshesEqual(MarchingCubes(solid, 0.05), actual) {
+		t.Error("mismatched mesh")
+	}
+
+	for _, iters := range []int{0, 4} {
+		actual = NewMesh()
+		MarchingCubesSearchStream(solid, 0.05, iters, actual.Add)
+		if !meshesEqual(MarchingCubesSearch(solid, 0.05, iters), actual) {
+			t.Errorf("mismatched search mesh for %d iters", iters)
+		}
+		MustValidateMesh(t, actual, true)
+	}
+}
+
 func BenchmarkMarchingCubes(b *testing.B) {
 	solid := &CylinderSolid{
 		P1:     XYZ(1, 2, 3),
@@ -50,6 +105,17 @@ func BenchmarkMarchingCubes(b *testing.B) {
 	}
 }
 
+func BenchmarkMarchingCubesParallel(b *testing.B) {
+	solid := &CylinderSolid{
+		P1:     XYZ(1, 2, 3),
+		P2:     XYZ(3, 1, 4),
+		Radius: 0.5,
+	}
+	for i := 0; i < b.N; i++ {
+		MarchingCubesParallel(solid, 0.025, 0)
+	}
+}
+
 type randomSolid struct{}
 
 func (r randomSolid) Min() Coord3D {
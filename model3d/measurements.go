@@ -27,3 +27,20 @@ func (m *Mesh) Volume() float64 {
 	})
 	return math.Abs(result)
 }
+
+// Centroid computes the center of mass of the volume
+// enclosed by the mesh, assuming uniform density.
+//
+// Like Volume, this assumes that the mesh is manifold and
+// the normals are consistent.
+func (m *Mesh) Centroid() Coord3D {
+	var volume float64
+	var moment Coord3D
+	m.Iterate(func(t *Triangle) {
+		// Signed volume of the tetrahedron from the origin.
+		v := t[0].Dot(t[1].Cross(t[2])) / 6
+		volume += v
+		moment = moment.Add(t[0].Add(t[1]).Add(t[2]).Scale(v / 4))
+	})
+	return moment.Scale(1 / volume)
+}
@@ -4,6 +4,7 @@ package model3d
 
 import (
 	"bufio"
+	"io"
 	"math"
 	"os"
 	"sort"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/fileformats"
 	"github.com/unixpickle/model3d/model2d"
 )
 
@@ -540,6 +542,80 @@ func (m *Mesh) Rotate(axis Coord3D, angle float64) *Mesh {
 	return m.Transform(Rotation(axis, angle))
 }
 
+// ScaleAxes creates a new mesh by scaling each axis of the
+// coordinates by the corresponding component of s.
+//
+// If an odd number of components are negative, the mesh
+// is mirrored, so the faces are re-oriented to keep the
+// normals pointing outward.
+func (m *Mesh) ScaleAxes(s Coord3D) *Mesh {
+	var numNegative int
+	for _, x := range s.Array() {
+		if x < 0 {
+			numNegative++
+		}
+	}
+	if numNegative%2 == 1 {
+		return m.mapCoordsMirrored(s.Mul)
+	}
+	return m.MapCoords(s.Mul)
+}
+
+// Mirror creates a new mesh by reflecting all of the
+// coordinates across the plane through point with the
+// given normal.
+//
+// The faces are re-oriented to keep the normals pointing
+// outward.
+func (m *Mesh) Mirror(point, normal Coord3D) *Mesh {
+	normal = normal.Normalize()
+	return m.mapCoordsMirrored(func(c Coord3D) Coord3D {
+		return c.Sub(normal.Scale(2 * c.Sub(point).Dot(normal)))
+	})
+}
+
+// SnapToGrid creates a new mesh by rounding every
+// coordinate to the nearest multiple of epsilon.
+//
+// This can merge vertices which are nearly equal, for
+// example to fix tiny gaps from floating-point error.
+// Faces which become degenerate are removed, including
+// faces whose vertices all snap onto one line.
+func (m *Mesh) SnapToGrid(epsilon float64) *Mesh {
+	snapped := m.MapCoords(func(c Coord3D) Coord3D {
+		arr := c.Array()
+		for i, x := range arr {
+			arr[i] = math.Round(x/epsilon) * epsilon
+		}
+		return NewCoord3DArray(arr)
+	})
+	res := NewMesh()
+	snapped.Iterate(func(f *Triangle) {
+		// Check for zero area in grid units, where the
+		// coordinates are integers.
+		var grid Triangle
+		for i, c := range f {
+			grid[i] = c.Scale(1 / epsilon)
+			grid[i] = XYZ(math.Round(grid[i].X), math.Round(grid[i].Y), math.Round(grid[i].Z))
+		}
+		if gridTriangleDegenerate(&grid) {
+			return
+		}
+		res.Add(f)
+	})
+	return res
+}
+
+func (m *Mesh) mapCoordsMirrored(f func(Coord3D) Coord3D) *Mesh {
+	res := NewMesh()
+	m.MapCoords(f).Iterate(func(t *Triangle) {
+		t1 := *t
+		t1[0], t1[1] = t1[1], t1[0]
+		res.Add(&t1)
+	})
+	return res
+}
+
 // MapCoords creates a new mesh by transforming all of the
 // coordinates according to the function f.
 func (m *Mesh) MapCoords(f func(Coord3D) Coord3D) *Mesh {
@@ -584,6 +660,43 @@ func (m *Mesh) EncodePLY(colorFunc func(c Coord3D) [3]uint8) []byte {
 	return EncodePLY(m.TriangleSlice(), colorFunc)
 }
 
+// WriteSTL writes the mesh to w as a binary STL file.
+func (m *Mesh) WriteSTL(w io.Writer) error {
+	return WriteSTL(w, m.TriangleSlice())
+}
+
+// SaveSTL saves the mesh to a binary STL file.
+//
+// Unlike SaveGroupedSTL, the triangles are not reordered.
+func (m *Mesh) SaveSTL(path string) error {
+	if err := saveToFile(path, m.WriteSTL); err != nil {
+		return errors.Wrap(err, "save STL")
+	}
+	return nil
+}
+
+// WritePLY writes the mesh to w as a PLY file with color.
+func (m *Mesh) WritePLY(w io.Writer, colorFunc func(c Coord3D) [3]uint8) error {
+	return WritePLY(w, m.TriangleSlice(), colorFunc)
+}
+
+// SavePLY saves the mesh to a PLY file with color.
+func (m *Mesh) SavePLY(path string, colorFunc func(c Coord3D) [3]uint8) error {
+	err := saveToFile(path, func(w io.Writer) error {
+		return m.WritePLY(w, colorFunc)
+	})
+	if err != nil {
+		return errors.Wrap(err, "save PLY")
+	}
+	return nil
+}
+
+// WriteMaterialOBJ writes the mesh to w as a zip file with
+// per-triangle material.
+func (m *Mesh) WriteMaterialOBJ(w io.Writer, colorFunc func(t *Triangle) [3]float64) error {
+	return WriteMaterialOBJ(w, m.TriangleSlice(), colorFunc)
+}
+
 // EncodeMaterialOBJ encodes the mesh as a zip file with
 // per-triangle material.
 func (m *Mesh) EncodeMaterialOBJ(colorFunc func(t *Triangle) [3]float64) []byte {
@@ -593,38 +706,63 @@ func (m *Mesh) EncodeMaterialOBJ(colorFunc func(t *Triangle) [3]float64) []byte
 // SaveMaterialOBJ saves the mesh to a zip file with a
 // per-triangle material.
 func (m *Mesh) SaveMaterialOBJ(path string, colorFunc func(t *Triangle) [3]float64) error {
-	f, err := os.Create(path)
+	err := saveToFile(path, func(w io.Writer) error {
+		return m.WriteMaterialOBJ(w, colorFunc)
+	})
 	if err != nil {
 		return errors.Wrap(err, "save material OBJ")
 	}
-	defer f.Close()
-	err = WriteMaterialOBJ(f, m.TriangleSlice(), colorFunc)
+	return nil
+}
+
+// WriteAMF writes the mesh to w as an AMF file with
+// per-triangle colors, which may be nil.
+//
+// See BuildAMF for details.
+func (m *Mesh) WriteAMF(w io.Writer, colorFunc func(t *Triangle) [3]float64) error {
+	return WriteAMF(w, m.TriangleSlice(), colorFunc)
+}
+
+// EncodeAMF encodes the mesh as an AMF file with
+// per-triangle colors, which may be nil.
+func (m *Mesh) EncodeAMF(colorFunc func(t *Triangle) [3]float64) []byte {
+	return EncodeAMF(m.TriangleSlice(), colorFunc)
+}
+
+// SaveAMF saves the mesh to an AMF file with per-triangle
+// colors, which may be nil.
+func (m *Mesh) SaveAMF(path string, colorFunc func(t *Triangle) [3]float64) error {
+	err := saveToFile(path, func(w io.Writer) error {
+		return m.WriteAMF(w, colorFunc)
+	})
 	if err != nil {
-		return errors.Wrap(err, "save material OBJ")
+		return errors.Wrap(err, "save AMF")
 	}
 	return nil
 }
 
+// SaveNamedMaterialOBJ saves the mesh to an obj file and
+// an accompanying mtl file, where each triangle's material
+// is named by f.
+//
+// See SaveNamedMaterialOBJ for details.
+func (m *Mesh) SaveNamedMaterialOBJ(path string, f func(t *Triangle) string,
+	materials []*fileformats.MTLFileMaterial) error {
+	return SaveNamedMaterialOBJ(path, m.TriangleSlice(), f, materials)
+}
+
 // SaveGroupedSTL writes the mesh to an STL file with the
 // triangles grouped in such a way that the file can be
 // compressed efficiently.
 func (m *Mesh) SaveGroupedSTL(path string) error {
-	w, err := os.Create(path)
+	err := saveToFile(path, func(w io.Writer) error {
+		tris := m.TriangleSlice()
+		GroupTriangles(tris)
+		return WriteSTL(w, tris)
+	})
 	if err != nil {
 		return errors.Wrap(err, "save grouped STL")
 	}
-	defer w.Close()
-
-	bufWriter := bufio.NewWriter(w)
-
-	tris := m.TriangleSlice()
-	GroupTriangles(tris)
-	if err := WriteSTL(bufWriter, tris); err != nil {
-		return errors.Wrap(err, "save grouped STL")
-	}
-	if err := bufWriter.Flush(); err != nil {
-		return errors.Wrap(err, "save grouped STL")
-	}
 	return nil
 }
 
@@ -732,3 +870,23 @@ func (m *Mesh) getVertexToFaceOrNil() *CoordToFaces {
 	}
 	return res.(*CoordToFaces)
 }
+
+// saveToFile creates a file at path and writes it with f,
+// reporting errors from writing, flushing, and closing the
+// file.
+func saveToFile(path string, f func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	bufWriter := bufio.NewWriter(file)
+	if err := f(bufWriter); err != nil {
+		file.Close()
+		return err
+	}
+	if err := bufWriter.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -29,6 +29,12 @@ func (m *Mesh) Blur(rates ...float64) *Mesh {
 //
 // If f is nil, then this is equivalent to Blur().
 func (m *Mesh) BlurFiltered(f func(c1, c2 Coord3D) bool, rates ...float64) *Mesh {
+	return m.MapCoords(m.blurMapping(f, rates))
+}
+
+// blurMapping computes the vertex mapping for
+// BlurFiltered().
+func (m *Mesh) blurMapping(f func(c1, c2 Coord3D) bool, rates []float64) func(Coord3D) Coord3D {
 	capacity := len(m.faces) * 3
 	if v2t := m.getVertexToFaceOrNil(); v2t != nil {
 		capacity = v2t.Len()
@@ -94,15 +100,9 @@ func (m *Mesh) BlurFiltered(f func(c1, c2 Coord3D) bool, rates ...float64) *Mesh
 		copy(coords, newCoords)
 	}
 
-	m1 := NewMesh()
-	m.Iterate(func(t *Triangle) {
-		t1 := *t
-		for i, c := range t1 {
-			t1[i] = coords[coordToIdx[c]]
-		}
-		m1.Add(&t1)
-	})
-	return m1
+	return func(c Coord3D) Coord3D {
+		return coords[coordToIdx[c]]
+	}
 }
 
 // SmoothAreas uses gradient descent to iteratively smooth
@@ -221,6 +221,11 @@ func (m *Mesh) FlattenBase(maxAngle float64) *Mesh {
 // be. In particular, it sets the approximate maximum
 // distance across all dimensions.
 func (m *Mesh) Repair(epsilon float64) *Mesh {
+	return m.MapCoords(m.repairMapping(epsilon))
+}
+
+// repairMapping computes the vertex mapping for Repair().
+func (m *Mesh) repairMapping(epsilon float64) func(Coord3D) Coord3D {
 	hashToClass := map[Coord3D]*equivalenceClass{}
 	allClasses := map[*equivalenceClass]bool{}
 	m.getVertexToFace().KeyRange(func(c Coord3D) bool {
@@ -288,9 +293,9 @@ func (m *Mesh) Repair(epsilon float64) *Mesh {
 		}
 	}
 
-	return m.MapCoords(func(c Coord3D) Coord3D {
+	return func(c Coord3D) Coord3D {
 		return coordToClass[c].Canonical
-	})
+	}
 }
 
 // An equivalenceClass stores a set of points which share
@@ -420,6 +425,113 @@ func (m *Mesh) RepairNormals(epsilon float64) (*Mesh, int) {
 	return newMesh, numFlipped
 }
 
+// VertexNormals computes a normal for every vertex by
+// averaging the normals of the triangles touching it,
+// weighted by the angle of each triangle at the vertex.
+//
+// Angle weighting makes the result independent of how the
+// surface around a vertex is triangulated.
+// Degenerate triangles do not contribute to the normals.
+func (m *Mesh) VertexNormals() *CoordToCoord {
+	res := NewCoordToCoord()
+	m.getVertexToFace().Range(func(c Coord3D, tris []*Triangle) bool {
+		var sum Coord3D
+		for _, t := range tris {
+			sum = sum.Add(triangleWeightedNormal(t, c))
+		}
+		res.Store(c, sum.Normalize())
+		return true
+	})
+	return res
+}
+
+// SmoothNormals computes normals for the corners of every
+// triangle, splitting the surface into smoothing groups at
+// sharp edges.
+//
+// Around each vertex, triangles which share an edge are
+// put in the same group if the angle between their normals
+// is at most maxAngle.
+// Each corner gets the angle-weighted normal of its group,
+// as in VertexNormals.
+//
+// A maxAngle of 0 gives flat normals for non-coplanar
+// triangles, while a maxAngle of pi gives the same result
+// as VertexNormals.
+func (m *Mesh) SmoothNormals(maxAngle float64) map[MeshCorner]Coord3D {
+	minCos := math.Cos(maxAngle)
+	res := map[MeshCorner]Coord3D{}
+	m.getVertexToFace().Range(func(c Coord3D, tris []*Triangle) bool {
+		normals := make([]Coord3D, len(tris))
+		groups := make([]int, len(tris))
+		for i, t := range tris {
+			if t.crossProduct().Norm() > 0 {
+				normals[i] = t.Normal()
+			}
+			groups[i] = i
+		}
+		var find func(i int) int
+		find = func(i int) int {
+			if groups[i] != i {
+				groups[i] = find(groups[i])
+			}
+			return groups[i]
+		}
+		for i, t1 := range tris {
+			for j := i + 1; j < len(tris); j++ {
+				if normals[i].Dot(normals[j]) >= minCos-1e-8 && t1.SharesEdge(tris[j]) {
+					groups[find(i)] = find(j)
+				}
+			}
+		}
+		sums := map[int]Coord3D{}
+		var total Coord3D
+		for i, t := range tris {
+			g := find(i)
+			weighted := triangleWeightedNormal(t, c)
+			sums[g] = sums[g].Add(weighted)
+			total = total.Add(weighted)
+		}
+		for i, t := range tris {
+			sum := sums[find(i)]
+			if sum.Norm() == 0 {
+				// Groups of degenerate triangles fall back to
+				// the normal of the whole vertex.
+				sum = total
+			}
+			n := sum.Normalize()
+			for j, p := range t {
+				if p == c {
+					res[MeshCorner{t, j}] = n
+				}
+			}
+		}
+		return true
+	})
+	return res
+}
+
+// triangleWeightedNormal gets the normal of t scaled by
+// its angle at the vertex c, or zero if t is degenerate.
+func triangleWeightedNormal(t *Triangle, c Coord3D) Coord3D {
+	cross := t.crossProduct()
+	if norm := cross.Norm(); norm > 0 {
+		return cross.Scale(triangleCornerAngle(t, c) / norm)
+	}
+	return Coord3D{}
+}
+
+func triangleCornerAngle(t *Triangle, c Coord3D) float64 {
+	for i, p := range t {
+		if p == c {
+			v1 := t[(i+1)%3].Sub(p).Normalize()
+			v2 := t[(i+2)%3].Sub(p).Normalize()
+			return math.Acos(math.Max(-1, math.Min(1, v1.Dot(v2))))
+		}
+	}
+	return 0
+}
+
 // FlipDelaunay "flips" edges in triangle pairs until the
 // mesh is Delaunay.
 //
@@ -518,14 +630,17 @@ func (m *Mesh) EliminateEdges(f func(tmp *Mesh, segment Segment) bool) *Mesh {
 // must be for the triangles to be considered coplanar.
 // A good value for very precise results is 1e-8.
 func (m *Mesh) EliminateCoplanar(epsilon float64) *Mesh {
-	dec := &decimator{
+	return coplanarDecimator(epsilon).Decimate(m)
+}
+
+func coplanarDecimator(epsilon float64) *decimator {
+	return &decimator{
 		FeatureAngle:       math.Acos(1 - epsilon),
 		MinimumAspectRatio: 0.01,
 		Criterion: &normalDecCriterion{
 			CosineEpsilon: epsilon,
 		},
 	}
-	return dec.Decimate(m)
 }
 
 func canEliminateSegment(m *Mesh, seg Segment) bool {
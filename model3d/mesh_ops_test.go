@@ -165,6 +165,77 @@ func TestMeshRepairNormals(t *testing.T) {
 	}
 }
 
+func TestMeshVertexNormals(t *testing.T) {
+	t.Run("Box", func(t *testing.T) {
+		mesh := NewMeshRect(XYZ(-1, -1, -1), XYZ(1, 1, 1))
+		normals := mesh.VertexNormals()
+		if normals.Len() != 8 {
+			t.Fatalf("unexpected number of normals: %d", normals.Len())
+		}
+		normals.Range(func(c, n Coord3D) bool {
+			if n.Dist(c.Normalize()) > 1e-8 {
+				t.Errorf("vertex %v: unexpected normal %v", c, n)
+			}
+			return true
+		})
+	})
+	t.Run("Sphere", func(t *testing.T) {
+		mesh := NewMeshIcosphere(Coord3D{}, 1, 5)
+		mesh.VertexNormals().Range(func(c, n Coord3D) bool {
+			if n.Dist(c.Normalize()) > 1e-2 {
+				t.Errorf("vertex %v: unexpected normal %v", c, n)
+			}
+			return true
+		})
+	})
+}
+
+func TestMeshSmoothNormals(t *testing.T) {
+	box := NewMeshRect(XYZ(-1, -1, -1), XYZ(1, 1, 1))
+	normals := box.SmoothNormals(math.Pi / 6)
+	if len(normals) != 36 {
+		t.Fatalf("unexpected number of normals: %d", len(normals))
+	}
+	for corner, n := range normals {
+		if n.Dist(corner.Triangle.Normal()) > 1e-8 {
+			t.Errorf("expected flat normal %v but got %v", corner.Triangle.Normal(), n)
+		}
+	}
+	vertexNormals := box.VertexNormals()
+	for corner, n := range box.SmoothNormals(math.Pi) {
+		expected := vertexNormals.Value(corner.Triangle[corner.Index])
+		if n.Dist(expected) > 1e-8 {
+			t.Errorf("expected vertex normal %v but got %v", expected, n)
+		}
+	}
+
+	// The sides of a cylinder should be smooth, while the
+	// edges around the caps should be sharp.
+	cylinder := NewMeshCylinder(Z(-1), Z(1), 1, 32)
+	for corner, n := range cylinder.SmoothNormals(math.Pi / 6) {
+		c := corner.Triangle[corner.Index]
+		var expected Coord3D
+		if math.Abs(corner.Triangle.Normal().Z) > 0.99 {
+			expected = Z(corner.Triangle.Normal().Z)
+		} else {
+			expected = XY(c.X, c.Y).Normalize()
+		}
+		if n.Dist(expected) > 1e-2 {
+			t.Errorf("vertex %v: expected normal %v but got %v", c, expected, n)
+		}
+	}
+
+	attributed := NewAttributedMeshNormals(cylinder, math.Pi/6)
+	if len(attributed.Attributes.Normals) != 2 {
+		// Only the centers of the caps should be unsplit.
+		t.Errorf("unexpected number of vertex normals: %d", len(attributed.Attributes.Normals))
+	}
+	attributed = NewAttributedMeshNormals(NewMeshIcosphere(Coord3D{}, 1, 3), math.Pi/6)
+	if len(attributed.Attributes.CornerNormals) != 0 {
+		t.Error("expected no vertices to be split")
+	}
+}
+
 func TestMeshEliminateMinimal(t *testing.T) {
 	m := NewMesh()
 	m.Add(&Triangle{
@@ -155,3 +155,59 @@ func BenchmarkVertexToFace(b *testing.B) {
 		mesh.vertexToFace.Store(v2f)
 	}
 }
+
+func TestMeshScaleAxes(t *testing.T) {
+	mesh := NewMeshRect(XYZ(1, 1, 1), XYZ(2, 3, 4))
+	for _, s := range []Coord3D{XYZ(2, 3, 0.5), XYZ(-2, 3, 0.5), XYZ(-2, -3, -0.5)} {
+		scaled := mesh.ScaleAxes(s)
+		if _, n := scaled.RepairNormals(1e-8); n != 0 {
+			t.Errorf("scale %v: %d bad normals", s, n)
+		}
+		expected := mesh.Volume() * math.Abs(s.X*s.Y*s.Z)
+		if v := scaled.Volume(); math.Abs(v-expected) > 1e-8 {
+			t.Errorf("scale %v: expected volume %f but got %f", s, expected, v)
+		}
+		min := XYZ(1, 1, 1).Mul(s).Min(XYZ(2, 3, 4).Mul(s))
+		if scaled.Min().Dist(min) > 1e-8 {
+			t.Errorf("scale %v: unexpected min %v", s, scaled.Min())
+		}
+	}
+}
+
+func TestMeshMirror(t *testing.T) {
+	mesh := NewMeshTorus(XYZ(1, 2, 3), XYZ(1, 1, 0).Normalize(), 0.3, 1, 10, 10)
+	mirrored := mesh.Mirror(XYZ(0, 1, 0), XYZ(1, 1, 1))
+	if _, n := mirrored.RepairNormals(1e-8); n != 0 {
+		t.Errorf("%d bad normals", n)
+	}
+	if math.Abs(mirrored.Volume()-mesh.Volume()) > 1e-8 {
+		t.Errorf("volume changed from %f to %f", mesh.Volume(), mirrored.Volume())
+	}
+	// Mirroring twice should give back the original mesh.
+	twice := mirrored.Mirror(XYZ(0, 1, 0), XYZ(1, 1, 1))
+	if twice.Min().Dist(mesh.Min()) > 1e-8 || twice.Max().Dist(mesh.Max()) > 1e-8 {
+		t.Error("mirroring twice changed the bounds")
+	}
+	collider := MeshToCollider(mesh)
+	twice.IterateVertices(func(c Coord3D) {
+		if !collider.SphereCollision(c, 1e-8) {
+			t.Fatalf("vertex %v is not on the original mesh", c)
+		}
+	})
+}
+
+func TestMeshSnapToGrid(t *testing.T) {
+	mesh := NewMesh()
+	mesh.Add(&Triangle{XYZ(0, 0, 0), XYZ(1.02, 0, 0), XYZ(0, 0.98, 0)})
+	mesh.Add(&Triangle{XYZ(0, 0, 0), XYZ(0.01, 0.02, 0), XYZ(0, 1, 1)})
+	// Distinct vertices which snap onto one line.
+	mesh.Add(&Triangle{XYZ(0, 0, 0), XYZ(0.6, 0.4, 0.4), XYZ(1.1, 1.05, 0.9)})
+	snapped := mesh.SnapToGrid(0.5)
+	tris := snapped.TriangleSlice()
+	if len(tris) != 1 {
+		t.Fatalf("expected 1 triangle but got %d", len(tris))
+	}
+	if *tris[0] != (Triangle{XYZ(0, 0, 0), XYZ(1, 0, 0), XYZ(0, 1, 0)}) {
+		t.Errorf("unexpected triangle: %v", tris[0])
+	}
+}
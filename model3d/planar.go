@@ -0,0 +1,238 @@
+package model3d
+
+import (
+	"sort"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+// Basis gets two orthogonal unit vectors spanning the
+// plane, such that x.Cross(y) points along the normal.
+//
+// This defines the coordinate system used by To2D and
+// From2D.
+func (p *Plane) Basis() (x, y Coord3D) {
+	x, y = p.Normal.OrthoBasis()
+	if x.Cross(y).Dot(p.Normal) < 0 {
+		y = y.Scale(-1)
+	}
+	return
+}
+
+// To2D projects a point onto the plane and gets its 2D
+// coordinates within the plane.
+func (p *Plane) To2D(c Coord3D) model2d.Coord {
+	x, y := p.Basis()
+	c = c.Sub(p.origin())
+	return model2d.XY(x.Dot(c), y.Dot(c))
+}
+
+// From2D is the inverse of To2D, mapping 2D coordinates in
+// the plane to the corresponding 3D point.
+func (p *Plane) From2D(c model2d.Coord) Coord3D {
+	x, y := p.Basis()
+	return p.origin().Add(x.Scale(c.X)).Add(y.Scale(c.Y))
+}
+
+func (p *Plane) origin() Coord3D {
+	return p.Normal.Scale(p.Offset / p.Normal.Dot(p.Normal))
+}
+
+// PlanarMeshTo2D converts a flat mesh within a plane to a
+// 2D mesh of its outline, using the coordinates from
+// Plane.To2D.
+//
+// Triangles facing along the plane's normal become a
+// correctly oriented 2D mesh, while triangles facing the
+// opposite direction become holes.
+// Vertices are projected onto the plane, so the mesh need
+// not lie exactly within it.
+func PlanarMeshTo2D(m *Mesh, p *Plane) *model2d.Mesh {
+	res := model2d.NewMesh()
+	m.Iterate(func(t *Triangle) {
+		for i := 0; i < 3; i++ {
+			p1, p2 := t[i], t[(i+1)%3]
+			if len(m.Find(p1, p2)) != 1 {
+				continue
+			}
+			// Triangles in model2d are oriented clockwise, so
+			// the boundary is traversed backwards.
+			res.Add(&model2d.Segment{p.To2D(p2), p.To2D(p1)})
+		}
+	})
+	return res
+}
+
+// PlanarMeshFrom2D triangulates a 2D mesh and embeds it in
+// a plane, using the coordinates from Plane.From2D.
+//
+// The 2D mesh must be manifold, closed, and oriented, as
+// for model2d.TriangulateMesh.
+// The resulting triangles face along the plane's normal.
+func PlanarMeshFrom2D(m *model2d.Mesh, p *Plane) *Mesh {
+	res := NewMesh()
+	for _, t := range model2d.TriangulateMesh(m) {
+		res.Add(&Triangle{p.From2D(t[1]), p.From2D(t[0]), p.From2D(t[2])})
+	}
+	return res
+}
+
+// A PlanarFace is a connected region of a mesh in which
+// all of the triangles lie within a common plane.
+type PlanarFace struct {
+	// Plane is the plane that best fits the face, with a
+	// normal pointing in the same direction as the
+	// triangles' normals.
+	Plane *Plane
+
+	// Mesh contains the triangles of the face.
+	Mesh *Mesh
+
+	// Boundary contains the closed loops of vertices around
+	// the edge of the face.
+	//
+	// Loops are ordered counter-clockwise around the plane's
+	// normal for outer boundaries, and clockwise for holes.
+	Boundary [][]Coord3D
+}
+
+// Outline converts the face to a 2D mesh of its boundary
+// using PlanarMeshTo2D.
+func (p *PlanarFace) Outline() *model2d.Mesh {
+	return PlanarMeshTo2D(p.Mesh, p.Plane)
+}
+
+// ExtractPlanarFaces splits the mesh into connected flat
+// regions by growing each region outward from a seed
+// triangle.
+//
+// A neighboring triangle joins a region if it faces the
+// same way as the seed and all of its vertices are within
+// tolerance of the seed's plane.
+// Larger triangles are used as seeds first, and the faces
+// are returned in order of decreasing area.
+//
+// Every triangle with non-zero area belongs to exactly one
+// face, although curved parts of the mesh will result in
+// many small faces.
+func (m *Mesh) ExtractPlanarFaces(tolerance float64) []*PlanarFace {
+	tris := m.TriangleSlice()
+	areas := make(map[*Triangle]float64, len(tris))
+	for _, t := range tris {
+		areas[t] = t.Area()
+	}
+	sort.SliceStable(tris, func(i, j int) bool {
+		return areas[tris[i]] > areas[tris[j]]
+	})
+
+	assigned := make(map[*Triangle]bool, len(tris))
+	var faces []*PlanarFace
+	for _, seed := range tris {
+		if assigned[seed] || areas[seed] == 0 {
+			continue
+		}
+		plane := NewPlaneTriangle(seed)
+		faceMesh := NewMesh()
+		queue := []*Triangle{seed}
+		assigned[seed] = true
+		for len(queue) > 0 {
+			t := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			faceMesh.Add(t)
+			for _, n := range m.Neighbors(t) {
+				if !assigned[n] && planarFaceAccepts(plane, n, areas[n], tolerance) {
+					assigned[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+		faces = append(faces, &PlanarFace{
+			Plane:    fitPlanarFace(faceMesh),
+			Mesh:     faceMesh,
+			Boundary: planarFaceBoundary(faceMesh),
+		})
+	}
+
+	sort.SliceStable(faces, func(i, j int) bool {
+		return faces[i].Mesh.Area() > faces[j].Mesh.Area()
+	})
+	return faces
+}
+
+func planarFaceAccepts(p *Plane, t *Triangle, area, tolerance float64) bool {
+	for _, c := range t {
+		if p.Dist(c) > tolerance {
+			return false
+		}
+	}
+	return area == 0 || t.Normal().Dot(p.Normal) > 0
+}
+
+// fitPlanarFace computes an area-weighted plane for the
+// triangles in a mesh.
+func fitPlanarFace(m *Mesh) *Plane {
+	var normalSum, centerSum Coord3D
+	var totalArea float64
+	m.Iterate(func(t *Triangle) {
+		area := t.Area()
+		normalSum = normalSum.Add(t.Normal().Scale(area))
+		centerSum = centerSum.Add(t[0].Add(t[1]).Add(t[2]).Scale(area / 3))
+		totalArea += area
+	})
+	return NewPlane(centerSum.Scale(1/totalArea), normalSum)
+}
+
+// planarFaceBoundary chains the boundary edges of a mesh
+// into loops which follow the triangles' orientation.
+func planarFaceBoundary(m *Mesh) [][]Coord3D {
+	next := map[Coord3D][]Coord3D{}
+	var starts []Coord3D
+	m.Iterate(func(t *Triangle) {
+		for i := 0; i < 3; i++ {
+			p1, p2 := t[i], t[(i+1)%3]
+			if len(m.Find(p1, p2)) == 1 {
+				next[p1] = append(next[p1], p2)
+				starts = append(starts, p1)
+			}
+		}
+	})
+
+	// Start each loop at its smallest vertex so that the
+	// result does not depend on iteration order.
+	sort.Slice(starts, func(i, j int) bool {
+		return coordLess(starts[i], starts[j])
+	})
+
+	var loops [][]Coord3D
+	for _, start := range starts {
+		if len(next[start]) == 0 {
+			continue
+		}
+		loop := []Coord3D{start}
+		cur := start
+		for {
+			nexts := next[cur]
+			if len(nexts) == 0 {
+				break
+			}
+			n := nexts[len(nexts)-1]
+			next[cur] = nexts[:len(nexts)-1]
+			if n == start {
+				break
+			}
+			loop = append(loop, n)
+			cur = n
+		}
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+func coordLess(c1, c2 Coord3D) bool {
+	if c1.X != c2.X {
+		return c1.X < c2.X
+	} else if c1.Y != c2.Y {
+		return c1.Y < c2.Y
+	}
+	return c1.Z < c2.Z
+}
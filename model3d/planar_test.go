@@ -0,0 +1,164 @@
+package model3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+func TestPlaneTo2D(t *testing.T) {
+	plane := NewPlane(XYZ(1, 2, 3), XYZ(1, -2, 0.5))
+	x, y := plane.Basis()
+	if math.Abs(x.Dot(y)) > 1e-8 || math.Abs(x.Cross(y).Dot(plane.Normal)-1) > 1e-8 {
+		t.Fatalf("invalid basis: %v, %v", x, y)
+	}
+	for i := 0; i < 10; i++ {
+		c := model2d.NewCoordRandNorm()
+		c3 := plane.From2D(c)
+		if plane.Dist(c3) > 1e-8 {
+			t.Fatalf("point is not on plane: %v", c3)
+		}
+		if actual := plane.To2D(c3); actual.Dist(c) > 1e-8 {
+			t.Fatalf("expected %v but got %v", c, actual)
+		}
+
+		// Points off the plane should be projected.
+		offset := c3.Add(plane.Normal.Scale(0.5))
+		if actual := plane.To2D(offset); actual.Dist(c) > 1e-8 {
+			t.Fatalf("expected %v but got %v", c, actual)
+		}
+	}
+}
+
+func TestPlanarMeshConversion(t *testing.T) {
+	// A square with a square hole.
+	mesh2d := model2d.NewMeshRect(model2d.XY(0, 0), model2d.XY(3, 2))
+	mesh2d.AddMesh(model2d.NewMeshRect(model2d.XY(1, 0.5), model2d.XY(2, 1.5)).Invert())
+
+	plane := NewPlane(XYZ(1, 2, 3), XYZ(-1, 2, 0.5))
+	mesh3d := PlanarMeshFrom2D(mesh2d, plane)
+	if a := mesh3d.Area(); math.Abs(a-mesh2d.Area()) > 1e-8 {
+		t.Errorf("expected area %f but got %f", mesh2d.Area(), a)
+	}
+	mesh3d.Iterate(func(tri *Triangle) {
+		if tri.Normal().Dot(plane.Normal) < 1-1e-8 {
+			t.Fatalf("unexpected normal: %v", tri.Normal())
+		}
+		for _, c := range tri {
+			if plane.Dist(c) > 1e-8 {
+				t.Fatalf("vertex is not on plane: %v", c)
+			}
+		}
+	})
+
+	actual := PlanarMeshTo2D(mesh3d, plane)
+	segs := actual.SegmentSlice()
+	if len(segs) != len(mesh2d.SegmentSlice()) {
+		t.Fatalf("expected %d segments but got %d", len(mesh2d.SegmentSlice()), len(segs))
+	}
+	for _, seg := range segs {
+		var found bool
+		mesh2d.Iterate(func(expected *model2d.Segment) {
+			if seg[0].Dist(expected[0]) < 1e-8 && seg[1].Dist(expected[1]) < 1e-8 {
+				found = true
+			}
+		})
+		if !found {
+			t.Fatalf("unexpected segment: %v", seg)
+		}
+	}
+
+	// Flipping the triangles should reverse the outline.
+	flipped := NewMesh()
+	mesh3d.Iterate(func(tri *Triangle) {
+		flipped.Add(&Triangle{tri[1], tri[0], tri[2]})
+	})
+	PlanarMeshTo2D(flipped, plane).Iterate(func(seg *model2d.Segment) {
+		var found bool
+		mesh2d.Iterate(func(expected *model2d.Segment) {
+			if seg[0].Dist(expected[1]) < 1e-8 && seg[1].Dist(expected[0]) < 1e-8 {
+				found = true
+			}
+		})
+		if !found {
+			t.Fatalf("unexpected segment: %v", seg)
+		}
+	})
+}
+
+func TestMeshExtractPlanarFaces(t *testing.T) {
+	box := NewMeshRect(Coord3D{}, XYZ(3, 2, 1))
+	faces := box.ExtractPlanarFaces(1e-5)
+	if len(faces) != 6 {
+		t.Fatalf("expected 6 faces but got %d", len(faces))
+	}
+	expectedAreas := []float64{6, 6, 3, 3, 2, 2}
+	var numTris int
+	for i, face := range faces {
+		if a := face.Mesh.Area(); math.Abs(a-expectedAreas[i]) > 1e-8 {
+			t.Errorf("face %d: expected area %f but got %f", i, expectedAreas[i], a)
+		}
+		if len(face.Boundary) != 1 {
+			t.Errorf("face %d: expected one boundary loop but got %d", i, len(face.Boundary))
+		}
+		face.Mesh.Iterate(func(tri *Triangle) {
+			if tri.Normal().Dot(face.Plane.Normal) < 1-1e-5 {
+				t.Fatalf("face %d: unexpected normal", i)
+			}
+		})
+		numTris += len(face.Mesh.TriangleSlice())
+	}
+	if numTris != len(box.TriangleSlice()) {
+		t.Errorf("expected %d triangles but got %d", len(box.TriangleSlice()), numTris)
+	}
+
+	// Marching cubes bevels the edges, but the sides should
+	// still be the largest faces.
+	box = MarchingCubesSearch(&Rect{MaxVal: XYZ(3, 2, 1)}, 0.1, 8)
+	faces = box.ExtractPlanarFaces(1e-5)
+	for i, expected := range expectedAreas {
+		if a := faces[i].Mesh.Area(); a > expected || a < expected*0.8 {
+			t.Errorf("face %d: expected area near %f but got %f", i, expected, a)
+		}
+	}
+
+	// A curved surface should be split into many faces.
+	sphere := NewMeshIcosphere(Coord3D{}, 1, 3)
+	if n := len(sphere.ExtractPlanarFaces(1e-5)); n != len(sphere.TriangleSlice()) {
+		t.Errorf("expected %d faces but got %d", len(sphere.TriangleSlice()), n)
+	}
+}
+
+func TestPlanarFaceBoundary(t *testing.T) {
+	mesh2d := model2d.NewMeshRect(model2d.XY(0, 0), model2d.XY(3, 2))
+	mesh2d.AddMesh(model2d.NewMeshRect(model2d.XY(1, 0.5), model2d.XY(2, 1.5)).Invert())
+	plane := NewPlane(XYZ(1, 2, 3), XYZ(-1, 2, 0.5))
+	faces := PlanarMeshFrom2D(mesh2d, plane).ExtractPlanarFaces(1e-8)
+	if len(faces) != 1 {
+		t.Fatalf("expected one face but got %d", len(faces))
+	}
+	face := faces[0]
+	if len(face.Boundary) != 2 {
+		t.Fatalf("expected two loops but got %d", len(face.Boundary))
+	}
+
+	var signedAreas []float64
+	for _, loop := range face.Boundary {
+		if len(loop) != 4 {
+			t.Fatalf("unexpected loop length: %d", len(loop))
+		}
+		var sum Coord3D
+		for i, c := range loop {
+			sum = sum.Add(c.Cross(loop[(i+1)%len(loop)]))
+		}
+		signedAreas = append(signedAreas, sum.Dot(face.Plane.Normal)/2)
+	}
+	if math.Abs(signedAreas[0]-6) > 1e-8 || math.Abs(signedAreas[1]+1) > 1e-8 {
+		t.Errorf("unexpected loop areas: %v", signedAreas)
+	}
+
+	if a := face.Outline().Area(); math.Abs(a-mesh2d.Area()) > 1e-8 {
+		t.Errorf("expected outline area %f but got %f", mesh2d.Area(), a)
+	}
+}
@@ -0,0 +1,172 @@
+package model3d
+
+import "math"
+
+// parallelEpsilon is the threshold for the sine of the
+// angle between two directions for them to be considered
+// parallel.
+const parallelEpsilon = 1e-10
+
+// A Line is an infinite line through Origin, extending in
+// both directions along Direction.
+//
+// The direction need not be normalized, but it must be
+// non-zero.
+type Line struct {
+	Origin    Coord3D
+	Direction Coord3D
+}
+
+// NewLine creates a line through two distinct points.
+func NewLine(p1, p2 Coord3D) *Line {
+	return &Line{Origin: p1, Direction: p2.Sub(p1)}
+}
+
+// At gets the point Origin + t*Direction.
+func (l *Line) At(t float64) Coord3D {
+	return l.Origin.Add(l.Direction.Scale(t))
+}
+
+// ClosestT gets the value t such that At(t) is the point
+// on the line closest to c.
+func (l *Line) ClosestT(c Coord3D) float64 {
+	return c.Sub(l.Origin).Dot(l.Direction) / l.Direction.Dot(l.Direction)
+}
+
+// Closest gets the point on the line closest to c.
+func (l *Line) Closest(c Coord3D) Coord3D {
+	return l.At(l.ClosestT(c))
+}
+
+// Dist gets the distance from c to the line.
+func (l *Line) Dist(c Coord3D) float64 {
+	return c.Dist(l.Closest(c))
+}
+
+// LineClosest finds the closest pair of points on two
+// lines, returned as values t1 and t2 such that l.At(t1)
+// and l1.At(t2) are closest.
+//
+// If the lines are parallel, ok is false.
+func (l *Line) LineClosest(l1 *Line) (t1, t2 float64, ok bool) {
+	d1, d2 := l.Direction, l1.Direction
+	cross := d1.Cross(d2)
+	crossNormSq := cross.Dot(cross)
+	if crossNormSq <= parallelEpsilon*parallelEpsilon*d1.Dot(d1)*d2.Dot(d2) {
+		return 0, 0, false
+	}
+	diff := l1.Origin.Sub(l.Origin)
+	t1 = diff.Cross(d2).Dot(cross) / crossNormSq
+	t2 = diff.Cross(d1).Dot(cross) / crossNormSq
+	return t1, t2, true
+}
+
+// LineDist gets the minimum distance between two lines.
+func (l *Line) LineDist(l1 *Line) float64 {
+	t1, t2, ok := l.LineClosest(l1)
+	if !ok {
+		return l.Dist(l1.Origin)
+	}
+	return l.At(t1).Dist(l1.At(t2))
+}
+
+// A Plane is the set of points c such that
+// c.Dot(Normal) == Offset.
+//
+// The normal should be a unit vector, so that distances
+// are measured correctly.
+type Plane struct {
+	Normal Coord3D
+	Offset float64
+}
+
+// NewPlane creates a plane through a point with the given
+// normal, which is normalized automatically.
+func NewPlane(point, normal Coord3D) *Plane {
+	normal = normal.Normalize()
+	return &Plane{Normal: normal, Offset: normal.Dot(point)}
+}
+
+// NewPlaneTriangle creates the plane containing a
+// triangle, with the same normal as the triangle.
+func NewPlaneTriangle(t *Triangle) *Plane {
+	return NewPlane(t[0], t.Normal())
+}
+
+// SignedDist gets the distance from c to the plane, which
+// is positive on the side that the normal points towards.
+func (p *Plane) SignedDist(c Coord3D) float64 {
+	return c.Dot(p.Normal) - p.Offset
+}
+
+// Dist gets the distance from c to the plane.
+func (p *Plane) Dist(c Coord3D) float64 {
+	return math.Abs(p.SignedDist(c))
+}
+
+// Project gets the point on the plane closest to c.
+func (p *Plane) Project(c Coord3D) Coord3D {
+	return c.Sub(p.Normal.Scale(p.SignedDist(c)))
+}
+
+// Reflect reflects c across the plane.
+func (p *Plane) Reflect(c Coord3D) Coord3D {
+	return c.Sub(p.Normal.Scale(2 * p.SignedDist(c)))
+}
+
+// LineIntersection finds the value t such that l.At(t)
+// is on the plane.
+//
+// If the line is parallel to the plane, ok is false.
+func (p *Plane) LineIntersection(l *Line) (t float64, ok bool) {
+	denom := l.Direction.Dot(p.Normal)
+	if math.Abs(denom) <= parallelEpsilon*l.Direction.Norm() {
+		return 0, false
+	}
+	return -p.SignedDist(l.Origin) / denom, true
+}
+
+// SegmentIntersection finds the point where a segment
+// crosses the plane.
+//
+// If the segment does not touch the plane, or lies within
+// the plane, ok is false.
+func (p *Plane) SegmentIntersection(s Segment) (point Coord3D, ok bool) {
+	d1, d2 := p.SignedDist(s[0]), p.SignedDist(s[1])
+	if (d1 > 0 && d2 > 0) || (d1 < 0 && d2 < 0) || d1 == d2 {
+		return Coord3D{}, false
+	}
+	t := d1 / (d1 - d2)
+	return s[0].Add(s[1].Sub(s[0]).Scale(t)), true
+}
+
+// PlaneIntersection finds the line where two planes meet.
+//
+// The direction of the resulting line is the cross product
+// of the two normals, and its origin is the point on the
+// line closest to the coordinate origin.
+//
+// If the planes are parallel, ok is false.
+func (p *Plane) PlaneIntersection(p1 *Plane) (line *Line, ok bool) {
+	n1, n2 := p.Normal, p1.Normal
+	dir := n1.Cross(n2)
+	dirNormSq := dir.Dot(dir)
+	if dirNormSq <= parallelEpsilon*parallelEpsilon*n1.Dot(n1)*n2.Dot(n2) {
+		return nil, false
+	}
+	origin := n2.Cross(dir).Scale(p.Offset).Add(dir.Cross(n1).Scale(p1.Offset)).Scale(1 / dirNormSq)
+	return &Line{Origin: origin, Direction: dir}, true
+}
+
+// SegmentIntersection finds the point where a segment
+// passes through the triangle.
+//
+// If the segment does not pass through the triangle, or
+// it is parallel to the triangle, ok is false.
+func (t *Triangle) SegmentIntersection(s Segment) (point Coord3D, ok bool) {
+	coll, ok := t.FirstRayCollision(&Ray{Origin: s[0], Direction: s[1].Sub(s[0])})
+	if !ok || coll.Scale > 1 {
+		return Coord3D{}, false
+	}
+	return s[0].Add(s[1].Sub(s[0]).Scale(coll.Scale)), true
+}
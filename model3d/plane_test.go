@@ -0,0 +1,121 @@
+package model3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLine(t *testing.T) {
+	l := NewLine(XYZ(1, 1, 0), XYZ(3, 1, 0))
+	if c := l.Closest(XYZ(5, 3, 2)); c.Dist(XYZ(5, 1, 0)) > 1e-8 {
+		t.Errorf("unexpected closest point: %v", c)
+	}
+	if d := l.Dist(XYZ(5, 3, 2)); math.Abs(d-math.Sqrt(8)) > 1e-8 {
+		t.Errorf("unexpected distance: %f", d)
+	}
+
+	for i := 0; i < 100; i++ {
+		l1 := &Line{Origin: NewCoord3DRandNorm(), Direction: NewCoord3DRandNorm()}
+		l2 := &Line{Origin: NewCoord3DRandNorm(), Direction: NewCoord3DRandNorm()}
+		t1, t2, ok := l1.LineClosest(l2)
+		if !ok {
+			t.Fatal("lines should not be parallel")
+		}
+		// The segment between the closest points should be
+		// perpendicular to both lines.
+		diff := l2.At(t2).Sub(l1.At(t1))
+		if math.Abs(diff.Dot(l1.Direction)) > 1e-8 || math.Abs(diff.Dot(l2.Direction)) > 1e-8 {
+			t.Fatalf("closest points are not perpendicular: %v", diff)
+		}
+		if math.Abs(l1.LineDist(l2)-diff.Norm()) > 1e-8 {
+			t.Fatal("unexpected line distance")
+		}
+	}
+
+	parallel := &Line{Origin: XYZ(0, 3, 0), Direction: X(-2)}
+	if _, _, ok := l.LineClosest(parallel); ok {
+		t.Error("lines should be parallel")
+	}
+	if d := l.LineDist(parallel); math.Abs(d-2) > 1e-8 {
+		t.Errorf("unexpected parallel distance: %f", d)
+	}
+}
+
+func TestPlane(t *testing.T) {
+	p := NewPlane(XYZ(0, 0, 2), Z(3))
+	if p.Offset != 2 || p.Normal != Z(1) {
+		t.Errorf("unexpected plane: %v", p)
+	}
+	if d := p.SignedDist(XYZ(1, 2, -1)); d != -3 {
+		t.Errorf("unexpected signed distance: %f", d)
+	}
+	if c := p.Project(XYZ(1, 2, -1)); c != XYZ(1, 2, 2) {
+		t.Errorf("unexpected projection: %v", c)
+	}
+	if c := p.Reflect(XYZ(1, 2, -1)); c != XYZ(1, 2, 5) {
+		t.Errorf("unexpected reflection: %v", c)
+	}
+
+	l := NewLine(XYZ(1, 1, 0), XYZ(2, 2, 1))
+	if tVal, ok := p.LineIntersection(l); !ok || l.At(tVal).Dist(XYZ(3, 3, 2)) > 1e-8 {
+		t.Errorf("unexpected line intersection: %f %v", tVal, ok)
+	}
+	if _, ok := p.LineIntersection(NewLine(XYZ(0, 0, 0), XYZ(1, 0, 0))); ok {
+		t.Error("parallel line should not intersect")
+	}
+
+	if c, ok := p.SegmentIntersection(NewSegment(XYZ(0, 0, 0), XYZ(4, 0, 4))); !ok ||
+		c.Dist(XYZ(2, 0, 2)) > 1e-8 {
+		t.Errorf("unexpected segment intersection: %v %v", c, ok)
+	}
+	if _, ok := p.SegmentIntersection(NewSegment(XYZ(0, 0, 0), XYZ(4, 0, 1))); ok {
+		t.Error("segment should not intersect")
+	}
+
+	tri := &Triangle{XYZ(1, 0, 0), XYZ(0, 1, 0), XYZ(0, 0, 1)}
+	triPlane := NewPlaneTriangle(tri)
+	for _, c := range tri {
+		if triPlane.Dist(c) > 1e-8 {
+			t.Errorf("triangle vertex not on plane: %v", c)
+		}
+	}
+}
+
+func TestPlaneIntersection(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		p1 := NewPlane(NewCoord3DRandNorm(), NewCoord3DRandNorm())
+		p2 := NewPlane(NewCoord3DRandNorm(), NewCoord3DRandNorm())
+		line, ok := p1.PlaneIntersection(p2)
+		if !ok {
+			t.Fatal("planes should not be parallel")
+		}
+		for _, tVal := range []float64{0, rand.NormFloat64()} {
+			c := line.At(tVal)
+			if p1.Dist(c) > 1e-8 || p2.Dist(c) > 1e-8 {
+				t.Fatalf("point %v is not on both planes", c)
+			}
+		}
+		if math.Abs(line.Origin.Dot(line.Direction)) > 1e-8 {
+			t.Fatal("origin should be closest to the coordinate origin")
+		}
+	}
+	p1 := NewPlane(Z(1), Z(1))
+	if _, ok := p1.PlaneIntersection(NewPlane(Z(2), Z(-1))); ok {
+		t.Error("planes should be parallel")
+	}
+}
+
+func TestTriangleSegmentIntersection(t *testing.T) {
+	tri := &Triangle{XYZ(0, 0, 0), XYZ(2, 0, 0), XYZ(0, 2, 0)}
+	if c, ok := tri.SegmentIntersection(NewSegment(XYZ(0.5, 0.5, -1), XYZ(0.5, 0.5, 1))); !ok ||
+		c.Dist(XYZ(0.5, 0.5, 0)) > 1e-8 {
+		t.Errorf("unexpected intersection: %v %v", c, ok)
+	}
+	if _, ok := tri.SegmentIntersection(NewSegment(XYZ(0.5, 0.5, 1), XYZ(0.5, 0.5, 2))); ok {
+		t.Error("segment should not reach triangle")
+	}
+	if _, ok := tri.SegmentIntersection(NewSegment(XYZ(1.5, 1.5, -1), XYZ(1.5, 1.5, 1))); ok {
+		t.Error("segment should miss triangle")
+	}
+}
@@ -0,0 +1,124 @@
+package model3d
+
+// CSGProvenance traces a boundary between two points back
+// through a tree of JoinedSolid, SubtractedSolid, and
+// IntersectedSolid combinators to find which child solid
+// produced it.
+//
+// The inside point should be just inside the surface of s,
+// and the outside point should be just outside of it.
+// The result is a path of child indices, starting at the
+// root of the tree.
+// For a SubtractedSolid, index 0 is Positive and index 1 is
+// Negative.
+// The path ends when it reaches a solid which is not a
+// combinator, or when no single child is responsible for
+// the boundary.
+//
+// Passing the same point for inside and outside finds the
+// child that claimed a single sampled point.
+func CSGProvenance(s Solid, inside, outside Coord3D) []int {
+	var res []int
+	for {
+		switch solid := s.(type) {
+		case JoinedSolid:
+			// The union's surface comes from the child that
+			// contains the inside point.
+			idx := -1
+			for i, child := range solid {
+				if child.Contains(inside) {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				return res
+			}
+			res = append(res, idx)
+			s = solid[idx]
+		case IntersectedSolid:
+			// The intersection's surface comes from the child
+			// that excludes the outside point.
+			idx := -1
+			for i, child := range solid {
+				if !child.Contains(outside) {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				return res
+			}
+			res = append(res, idx)
+			s = solid[idx]
+		case *SubtractedSolid:
+			if solid.Negative.Contains(outside) {
+				// The surface is the negative solid's surface,
+				// seen from the other side.
+				res = append(res, 1)
+				s = solid.Negative
+				inside, outside = outside, inside
+			} else {
+				res = append(res, 0)
+				s = solid.Positive
+			}
+		default:
+			return res
+		}
+	}
+}
+
+// CSGTriangleProvenance is like CSGProvenance, but it finds
+// the child which produced a triangle from a mesh of s,
+// such as the output of MarchingCubesSearch.
+//
+// The epsilon argument is the distance from the triangle's
+// center at which to sample inside and outside points.
+// It should be larger than the error of the mesh, but
+// smaller than the size of the solid's features.
+func CSGTriangleProvenance(s Solid, t *Triangle, epsilon float64) []int {
+	center := t[0].Add(t[1]).Add(t[2]).Scale(1.0 / 3)
+	offset := t.Normal().Scale(epsilon)
+	return CSGProvenance(s, center.Sub(offset), center.Add(offset))
+}
+
+// CSGProvenanceColor creates a per-triangle color function
+// which colors a mesh of s by the child of s which
+// produced each triangle.
+//
+// Only the first depth indices of each provenance path are
+// used, so a depth of 1 colors triangles by the root's
+// direct children. If depth is 0, full paths are used.
+// Triangles with no provenance are gray.
+//
+// The result can be passed to Mesh.SaveMaterialOBJ or
+// render3d.TriangleColorFunc to debug boolean operations.
+func CSGProvenanceColor(s Solid, epsilon float64, depth int) func(t *Triangle) [3]float64 {
+	return func(t *Triangle) [3]float64 {
+		path := CSGTriangleProvenance(s, t, epsilon)
+		if len(path) == 0 {
+			return [3]float64{0.5, 0.5, 0.5}
+		}
+		if depth > 0 && len(path) > depth {
+			path = path[:depth]
+		}
+		var hash int
+		for _, idx := range path {
+			hash = hash*7 + idx + 1
+		}
+		return provenancePalette[(hash-1)%len(provenancePalette)]
+	}
+}
+
+var provenancePalette = [][3]float64{
+	{0.90, 0.10, 0.10},
+	{0.10, 0.45, 0.90},
+	{0.20, 0.75, 0.20},
+	{0.95, 0.60, 0.05},
+	{0.60, 0.20, 0.80},
+	{0.05, 0.75, 0.75},
+	{0.95, 0.85, 0.10},
+	{0.85, 0.35, 0.65},
+	{0.55, 0.35, 0.15},
+	{0.10, 0.10, 0.50},
+}
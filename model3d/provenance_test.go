@@ -0,0 +1,67 @@
+package model3d
+
+import "testing"
+
+func TestCSGProvenance(t *testing.T) {
+	box := &Rect{MinVal: XYZ(-1, -1, -1), MaxVal: XYZ(1, 1, 1)}
+	sphere := &Sphere{Center: XYZ(1, 0, 0), Radius: 0.5}
+	cyl := &Cylinder{P1: XYZ(0, 0, -2), P2: XYZ(0, 0, 2), Radius: 0.3}
+	solid := &SubtractedSolid{
+		Positive: JoinedSolid{box, sphere},
+		Negative: IntersectedSolid{cyl, box},
+	}
+
+	cases := []struct {
+		Inside   Coord3D
+		Outside  Coord3D
+		Expected []int
+	}{
+		// Top of the box, away from the hole.
+		{XYZ(-0.5, 0, 0.99), XYZ(-0.5, 0, 1.01), []int{0, 0}},
+		// Tip of the sphere.
+		{XYZ(1.49, 0, 0), XYZ(1.51, 0, 0), []int{0, 1}},
+		// Wall of the hole, from the cylinder.
+		{XYZ(0.31, 0, 0), XYZ(0.29, 0, 0), []int{1, 0}},
+		// Point in the hole.
+		{XYZ(0, 0, 0), XYZ(0, 0, 0), []int{1}},
+		// Point outside of everything.
+		{XYZ(3, 3, 3), XYZ(3, 3, 3), []int{0}},
+	}
+	for i, c := range cases {
+		actual := CSGProvenance(solid, c.Inside, c.Outside)
+		if len(actual) != len(c.Expected) {
+			t.Errorf("case %d: expected %v but got %v", i, c.Expected, actual)
+			continue
+		}
+		for j, x := range c.Expected {
+			if actual[j] != x {
+				t.Errorf("case %d: expected %v but got %v", i, c.Expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestCSGProvenanceColor(t *testing.T) {
+	solid := JoinedSolid{
+		&Rect{MinVal: XYZ(0, 0, 0), MaxVal: XYZ(1, 1, 1)},
+		&Rect{MinVal: XYZ(2, 0, 0), MaxVal: XYZ(3, 1, 1)},
+	}
+	mesh := MarchingCubesSearch(solid, 0.1, 8)
+	colorFn := CSGProvenanceColor(solid, 0.01, 1)
+	colors := map[[3]float64]bool{}
+	mesh.Iterate(func(tri *Triangle) {
+		color := colorFn(tri)
+		expected := provenancePalette[0]
+		if tri[0].X > 1.5 {
+			expected = provenancePalette[1]
+		}
+		if color != expected {
+			t.Fatalf("unexpected color %v for triangle %v", color, tri)
+		}
+		colors[color] = true
+	})
+	if len(colors) != 2 {
+		t.Errorf("expected 2 colors but got %d", len(colors))
+	}
+}
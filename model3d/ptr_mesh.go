@@ -22,6 +22,19 @@ func newPtrMeshMesh(m *Mesh) *ptrMesh {
 	return res
 }
 
+// newPtrMeshTagged creates a ptrMesh from a TaggedMesh,
+// storing the tags in the triangles.
+func newPtrMeshTagged(t *TaggedMesh) *ptrMesh {
+	mapping := newPtrCoordMap()
+	res := newPtrMesh()
+	t.Mesh.Iterate(func(tri *Triangle) {
+		pt := mapping.Triangle(tri)
+		pt.Tag = t.Tag(tri)
+		res.Add(pt)
+	})
+	return res
+}
+
 // Add adds a triangle to the mesh.
 //
 // The triangle must not already be in a mesh.
@@ -69,6 +82,16 @@ func (p *ptrMesh) Mesh() *Mesh {
 	return m
 }
 
+// TaggedMesh turns the ptrMesh into a TaggedMesh using the
+// tags of the triangles.
+func (p *ptrMesh) TaggedMesh() *TaggedMesh {
+	res := NewTaggedMesh()
+	p.Iterate(func(t *ptrTriangle) {
+		res.Add(t.Triangle(), t.Tag)
+	})
+	return res
+}
+
 // Peek quickly returns an arbitrary coordinate in the
 // mesh, or nil if the mesh is empty.
 func (p *ptrMesh) Peek() *ptrCoord {
@@ -239,6 +262,10 @@ type ptrTriangle struct {
 	Coords [3]*ptrCoord
 	Prev   *ptrTriangle
 	Next   *ptrTriangle
+
+	// Tag is carried over from a TaggedMesh, if there is
+	// one.
+	Tag int
 }
 
 // newPtrTriangle creates a triangle and adds it to all of
@@ -0,0 +1,206 @@
+package model3d
+
+import "math"
+
+// A Quaternion represents a 3D rotation as a unit
+// quaternion W + X*i + Y*j + Z*k.
+//
+// The identity rotation is Quaternion{W: 1}.
+//
+// Unlike a Matrix3, quaternions can be interpolated
+// smoothly with Slerp, which makes them useful for
+// animating orientations.
+type Quaternion struct {
+	W float64
+	X float64
+	Y float64
+	Z float64
+}
+
+// NewQuaternionAxisAngle creates a quaternion which
+// rotates points around the given vector in a
+// right-handed direction, like NewMatrix3Rotation.
+//
+// The axis is assumed to be normalized.
+// The angle is measured in radians.
+func NewQuaternionAxisAngle(axis Coord3D, angle float64) Quaternion {
+	s := math.Sin(angle / 2)
+	return Quaternion{
+		W: math.Cos(angle / 2),
+		X: axis.X * s,
+		Y: axis.Y * s,
+		Z: axis.Z * s,
+	}
+}
+
+// NewQuaternionMatrix creates a quaternion from a rotation
+// matrix.
+//
+// The matrix is assumed to be orthonormal with a
+// determinant of 1.
+func NewQuaternionMatrix(m *Matrix3) Quaternion {
+	var q Quaternion
+	trace := m[0] + m[4] + m[8]
+	if trace > 0 {
+		s := 2 * math.Sqrt(trace+1)
+		q = Quaternion{
+			W: s / 4,
+			X: (m[7] - m[5]) / s,
+			Y: (m[2] - m[6]) / s,
+			Z: (m[3] - m[1]) / s,
+		}
+	} else if m[0] > m[4] && m[0] > m[8] {
+		s := 2 * math.Sqrt(1+m[0]-m[4]-m[8])
+		q = Quaternion{
+			W: (m[7] - m[5]) / s,
+			X: s / 4,
+			Y: (m[1] + m[3]) / s,
+			Z: (m[2] + m[6]) / s,
+		}
+	} else if m[4] > m[8] {
+		s := 2 * math.Sqrt(1+m[4]-m[0]-m[8])
+		q = Quaternion{
+			W: (m[2] - m[6]) / s,
+			X: (m[1] + m[3]) / s,
+			Y: s / 4,
+			Z: (m[5] + m[7]) / s,
+		}
+	} else {
+		s := 2 * math.Sqrt(1+m[8]-m[0]-m[4])
+		q = Quaternion{
+			W: (m[3] - m[1]) / s,
+			X: (m[2] + m[6]) / s,
+			Y: (m[5] + m[7]) / s,
+			Z: s / 4,
+		}
+	}
+	return q.Normalize()
+}
+
+// AxisAngle gets the axis and angle of the rotation.
+//
+// The angle is in [0, pi], and the axis is normalized.
+// For the identity rotation, the axis is arbitrary.
+func (q Quaternion) AxisAngle() (axis Coord3D, angle float64) {
+	q = q.Normalize()
+	if q.W < 0 {
+		q = q.Scale(-1)
+	}
+	v := q.Vector()
+	norm := v.Norm()
+	if norm == 0 {
+		return X(1), 0
+	}
+	return v.Scale(1 / norm), 2 * math.Atan2(norm, q.W)
+}
+
+// Matrix creates a rotation matrix which performs the same
+// rotation as q.
+func (q Quaternion) Matrix() *Matrix3 {
+	w, x, y, z := q.W, q.X, q.Y, q.Z
+	return &Matrix3{
+		1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y),
+		2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x),
+		2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y),
+	}
+}
+
+// Transform creates a DistTransform which applies the
+// rotation.
+func (q Quaternion) Transform() DistTransform {
+	return &orthoMatrix3Transform{
+		Matrix3Transform{
+			Matrix: q.Matrix(),
+		},
+	}
+}
+
+// Vector gets the imaginary part of q.
+func (q Quaternion) Vector() Coord3D {
+	return XYZ(q.X, q.Y, q.Z)
+}
+
+// Mul computes the product q*q1, which is the rotation
+// that applies q1 and then q.
+func (q Quaternion) Mul(q1 Quaternion) Quaternion {
+	return Quaternion{
+		W: q.W*q1.W - q.X*q1.X - q.Y*q1.Y - q.Z*q1.Z,
+		X: q.W*q1.X + q.X*q1.W + q.Y*q1.Z - q.Z*q1.Y,
+		Y: q.W*q1.Y - q.X*q1.Z + q.Y*q1.W + q.Z*q1.X,
+		Z: q.W*q1.Z + q.X*q1.Y - q.Y*q1.X + q.Z*q1.W,
+	}
+}
+
+// Conj computes the conjugate of q, which is the inverse
+// rotation for a unit quaternion.
+func (q Quaternion) Conj() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// Scale multiplies every component of q by s.
+func (q Quaternion) Scale(s float64) Quaternion {
+	return Quaternion{W: q.W * s, X: q.X * s, Y: q.Y * s, Z: q.Z * s}
+}
+
+// Dot computes the dot product of q and q1 as 4D vectors.
+func (q Quaternion) Dot(q1 Quaternion) float64 {
+	return q.W*q1.W + q.X*q1.X + q.Y*q1.Y + q.Z*q1.Z
+}
+
+// Norm computes the magnitude of q.
+func (q Quaternion) Norm() float64 {
+	return math.Sqrt(q.Dot(q))
+}
+
+// Normalize scales q to have unit norm.
+func (q Quaternion) Normalize() Quaternion {
+	return q.Scale(1 / q.Norm())
+}
+
+// Apply rotates a coordinate.
+//
+// The quaternion is assumed to be normalized.
+func (q Quaternion) Apply(c Coord3D) Coord3D {
+	u := q.Vector()
+	t := u.Cross(c).Scale(2)
+	return c.Add(t.Scale(q.W)).Add(u.Cross(t))
+}
+
+// Slerp performs spherical linear interpolation between q
+// and q1, where t=0 gives q and t=1 gives q1.
+//
+// The interpolation takes the shortest path between the
+// two rotations, at a constant angular velocity.
+// Both quaternions are assumed to be normalized.
+func (q Quaternion) Slerp(q1 Quaternion, t float64) Quaternion {
+	dot := q.Dot(q1)
+	if dot < 0 {
+		// q1 and -q1 are the same rotation, but one of them
+		// is closer to q.
+		q1 = q1.Scale(-1)
+		dot = -dot
+	}
+	if dot > 0.9995 {
+		// Avoid dividing by a tiny sine for nearby inputs.
+		return Quaternion{
+			W: q.W + (q1.W-q.W)*t,
+			X: q.X + (q1.X-q.X)*t,
+			Y: q.Y + (q1.Y-q.Y)*t,
+			Z: q.Z + (q1.Z-q.Z)*t,
+		}.Normalize()
+	}
+	theta := math.Acos(dot) * t
+	ortho := Quaternion{
+		W: q1.W - q.W*dot,
+		X: q1.X - q.X*dot,
+		Y: q1.Y - q.Y*dot,
+		Z: q1.Z - q.Z*dot,
+	}.Normalize()
+	s, c := math.Sin(theta), math.Cos(theta)
+	return Quaternion{
+		W: q.W*c + ortho.W*s,
+		X: q.X*c + ortho.X*s,
+		Y: q.Y*c + ortho.Y*s,
+		Z: q.Z*c + ortho.Z*s,
+	}
+}
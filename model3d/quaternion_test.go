@@ -0,0 +1,88 @@
+package model3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestQuaternionMatrix(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		axis := NewCoord3DRandUnit()
+		angle := (rand.Float64()*2 - 1) * math.Pi
+		q := NewQuaternionAxisAngle(axis, angle)
+		expected := NewMatrix3Rotation(axis, angle)
+		actual := q.Matrix()
+		for j, x := range expected {
+			if math.Abs(x-actual[j]) > 1e-8 {
+				t.Fatalf("entry %d: expected %f but got %f", j, x, actual[j])
+			}
+		}
+
+		c := NewCoord3DRandNorm()
+		if applied := q.Apply(c); applied.Dist(expected.MulColumn(c)) > 1e-8 {
+			t.Fatalf("unexpected rotated point: %v", applied)
+		}
+
+		q1 := NewQuaternionMatrix(expected)
+		if math.Abs(math.Abs(q1.Dot(q))-1) > 1e-8 {
+			t.Fatalf("expected %v but got %v", q, q1)
+		}
+	}
+}
+
+func TestQuaternionAxisAngle(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		axis := NewCoord3DRandUnit()
+		angle := rand.Float64() * math.Pi
+		q := NewQuaternionAxisAngle(axis, angle)
+		if rand.Intn(2) == 0 {
+			// Both signs represent the same rotation.
+			q = q.Scale(-1)
+		}
+		actualAxis, actualAngle := q.AxisAngle()
+		if actualAxis.Dist(axis) > 1e-8 || math.Abs(actualAngle-angle) > 1e-8 {
+			t.Fatalf("expected %v, %f but got %v, %f", axis, angle, actualAxis, actualAngle)
+		}
+	}
+}
+
+func TestQuaternionMul(t *testing.T) {
+	q1 := NewQuaternionAxisAngle(NewCoord3DRandUnit(), 1.3)
+	q2 := NewQuaternionAxisAngle(NewCoord3DRandUnit(), -0.4)
+	c := NewCoord3DRandNorm()
+	expected := q1.Apply(q2.Apply(c))
+	if actual := q1.Mul(q2).Apply(c); actual.Dist(expected) > 1e-8 {
+		t.Errorf("expected %v but got %v", expected, actual)
+	}
+	if actual := q1.Conj().Apply(q1.Apply(c)); actual.Dist(c) > 1e-8 {
+		t.Errorf("conjugate should invert rotation: %v", actual)
+	}
+}
+
+func TestQuaternionSlerp(t *testing.T) {
+	axis := NewCoord3DRandUnit()
+	q1 := NewQuaternionAxisAngle(axis, 0.2)
+	q2 := NewQuaternionAxisAngle(axis, 1.8)
+	for _, frac := range []float64{0, 0.25, 0.5, 1} {
+		expected := NewQuaternionAxisAngle(axis, 0.2+1.6*frac)
+		actual := q1.Slerp(q2, frac)
+		if math.Abs(actual.Dot(expected)-1) > 1e-8 {
+			t.Errorf("frac %f: expected %v but got %v", frac, expected, actual)
+		}
+	}
+
+	// The negated quaternion is the same rotation, so the
+	// interpolation should take the same path.
+	actual := q1.Slerp(q2.Scale(-1), 0.5)
+	expected := NewQuaternionAxisAngle(axis, 1.0)
+	if math.Abs(math.Abs(actual.Dot(expected))-1) > 1e-8 {
+		t.Errorf("expected %v but got %v", expected, actual)
+	}
+
+	// Nearby rotations should not be numerically unstable.
+	q3 := NewQuaternionAxisAngle(axis, 0.2+1e-9)
+	if actual := q1.Slerp(q3, 0.5); math.Abs(actual.Norm()-1) > 1e-8 {
+		t.Errorf("unexpected norm: %f", actual.Norm())
+	}
+}
@@ -42,6 +42,17 @@ type FaceSDF interface {
 	FaceSDF(c Coord3D) (*Triangle, Coord3D, float64)
 }
 
+// A NormalSDF is an SDF that can additionally compute
+// the direction of the surface normal.
+type NormalSDF interface {
+	SDF
+
+	// NormalSDF gets the SDF at c and also returns the
+	// outward unit normal, which is the direction in which
+	// the SDF decreases the fastest.
+	NormalSDF(c Coord3D) (Coord3D, float64)
+}
+
 type funcSDF struct {
 	min Coord3D
 	max Coord3D
@@ -176,12 +187,126 @@ func (c *colliderSDF) boundDistance(coord Coord3D) (min, max float64) {
 	}
 }
 
+type sdfSolid struct {
+	sdf SDF
+}
+
+// SDFToSolid creates a Solid which contains the points
+// where the SDF is positive.
+//
+// The resulting Solid is also an SDF.
+func SDFToSolid(s SDF) Solid {
+	return &sdfSolid{sdf: s}
+}
+
+func (s *sdfSolid) Min() Coord3D {
+	return s.sdf.Min()
+}
+
+func (s *sdfSolid) Max() Coord3D {
+	return s.sdf.Max()
+}
+
+func (s *sdfSolid) Contains(c Coord3D) bool {
+	return InBounds(s, c) && s.sdf.SDF(c) > 0
+}
+
+func (s *sdfSolid) SDF(c Coord3D) float64 {
+	return s.sdf.SDF(c)
+}
+
+// SolidToSDF approximates the SDF of a Solid by creating a
+// mesh of its surface with the given grid spacing.
+//
+// The resulting SDF is only as accurate as the mesh, so
+// delta should be small compared to the features of the
+// solid.
+func SolidToSDF(s Solid, delta float64) FaceSDF {
+	return MeshToSDF(MarchingCubesSearch(s, delta, 8))
+}
+
+type normalSDF struct {
+	sdf     SDF
+	epsilon float64
+}
+
+// SDFToNormalSDF creates a NormalSDF which estimates
+// normals using SDFGradient.
+//
+// If s is already a NormalSDF, it is returned as-is.
+func SDFToNormalSDF(s SDF, epsilon float64) NormalSDF {
+	if n, ok := s.(NormalSDF); ok {
+		return n
+	}
+	return &normalSDF{sdf: s, epsilon: epsilon}
+}
+
+func (n *normalSDF) Min() Coord3D {
+	return n.sdf.Min()
+}
+
+func (n *normalSDF) Max() Coord3D {
+	return n.sdf.Max()
+}
+
+func (n *normalSDF) SDF(c Coord3D) float64 {
+	return n.sdf.SDF(c)
+}
+
+func (n *normalSDF) NormalSDF(c Coord3D) (Coord3D, float64) {
+	return SDFGradient(n.sdf, c, n.epsilon).Scale(-1).Normalize(), n.sdf.SDF(c)
+}
+
+// SDFGradient computes the gradient of an SDF at a point,
+// which points away from the surface on the inside and
+// towards the surface on the outside.
+//
+// If s is a NormalSDF or a PointSDF, the gradient is
+// computed exactly where possible.
+// Otherwise, it is estimated with central differences
+// using a step size of epsilon.
+// If epsilon is 0, a step size is chosen based on the
+// size of the SDF's bounds.
+func SDFGradient(s SDF, c Coord3D, epsilon float64) Coord3D {
+	switch s := s.(type) {
+	case NormalSDF:
+		normal, _ := s.NormalSDF(c)
+		return normal.Scale(-1)
+	case PointSDF:
+		point, dist := s.PointSDF(c)
+		if diff := c.Sub(point); dist != 0 && diff.Norm() > 0 {
+			if dist < 0 {
+				diff = diff.Scale(-1)
+			}
+			return diff.Normalize()
+		}
+	}
+
+	if epsilon == 0 {
+		epsilon = 1e-5
+		for _, size := range s.Max().Sub(s.Min()).Array() {
+			epsilon = math.Max(epsilon, size*1e-5)
+		}
+	}
+	var res [3]float64
+	for i := range res {
+		var delta [3]float64
+		delta[i] = epsilon
+		d := NewCoord3DArray(delta)
+		res[i] = (s.SDF(c.Add(d)) - s.SDF(c.Sub(d))) / (2 * epsilon)
+	}
+	return NewCoord3DArray(res)
+}
+
 type meshSDF struct {
 	Solid
 	MDF *meshDistFunc
 }
 
 // MeshToSDF turns a mesh into a FaceSDF.
+//
+// For more control over the hierarchy, use a BVHBuilder
+// with BVHToSDF().
 func MeshToSDF(m *Mesh) FaceSDF {
 	faces := m.TriangleSlice()
 	GroupTriangles(faces)
@@ -202,6 +327,18 @@ func GroupedTrianglesToSDF(faces []*Triangle) FaceSDF {
 	}
 }
 
+// BVHToSDF creates a FaceSDF from the triangles in a BVH,
+// using the BVH to accelerate queries.
+//
+// This can be used with a BVHBuilder to control the
+// structure of the SDF.
+func BVHToSDF(b *BVH) FaceSDF {
+	return &meshSDF{
+		Solid: NewColliderSolid(BVHToCollider(b)),
+		MDF:   newMeshDistFuncBVH(b.Leaf, b.Branch),
+	}
+}
+
 func (m *meshSDF) SDF(c Coord3D) float64 {
 	dist := math.Inf(1)
 	m.MDF.Dist(c, &dist, nil, nil)
@@ -257,6 +394,25 @@ func newMeshDistFunc(faces []*Triangle) *meshDistFunc {
 
 }
 
+// newMeshDistFuncBVH creates a meshDistFunc for a BVH
+// node, splitting branches with many children into a
+// binary tree.
+func newMeshDistFuncBVH(leaf *Triangle, branch []*BVH) *meshDistFunc {
+	if leaf != nil {
+		return &meshDistFunc{root: leaf, min: leaf.Min(), max: leaf.Max()}
+	} else if len(branch) == 1 {
+		return newMeshDistFuncBVH(branch[0].Leaf, branch[0].Branch)
+	}
+	midIdx := len(branch) / 2
+	t1 := newMeshDistFuncBVH(nil, branch[:midIdx])
+	t2 := newMeshDistFuncBVH(nil, branch[midIdx:])
+	return &meshDistFunc{
+		min:      t1.Min().Min(t2.Min()),
+		max:      t1.Max().Max(t2.Max()),
+		children: [2]*meshDistFunc{t1, t2},
+	}
+}
+
 func (m *meshDistFunc) Min() Coord3D {
 	return m.min
 }
@@ -152,3 +152,46 @@ func sdfTestingSolid() Solid {
 		OuterRadius: 0.7,
 	}
 }
+
+func TestSDFToSolid(t *testing.T) {
+	sphere := &Sphere{Center: XYZ(1, 2, 3), Radius: 0.5}
+	solid := SDFToSolid(sphere)
+	for i := 0; i < 1000; i++ {
+		c := NewCoord3DRandNorm().Add(sphere.Center)
+		if solid.Contains(c) != sphere.Contains(c) {
+			t.Fatalf("mismatched containment at %v", c)
+		}
+	}
+
+	sdf := SolidToSDF(solid, 0.02)
+	for i := 0; i < 100; i++ {
+		c := NewCoord3DRandNorm().Scale(0.4).Add(sphere.Center)
+		if actual, expected := sdf.SDF(c), sphere.SDF(c); math.Abs(actual-expected) > 0.02 {
+			t.Fatalf("expected SDF %f but got %f", expected, actual)
+		}
+	}
+}
+
+func TestSDFGradient(t *testing.T) {
+	sphere := &Sphere{Center: XYZ(1, 2, 3), Radius: 0.5}
+	funcSphere := FuncSDF(sphere.Min(), sphere.Max(), sphere.SDF)
+	for i := 0; i < 100; i++ {
+		c := NewCoord3DRandNorm().Add(sphere.Center)
+		expected := sphere.Center.Sub(c).Normalize()
+		for _, s := range []SDF{sphere, funcSphere} {
+			if actual := SDFGradient(s, c, 0); actual.Dist(expected) > 1e-4 {
+				t.Fatalf("expected gradient %v but got %v", expected, actual)
+			}
+		}
+		normal, dist := SDFToNormalSDF(funcSphere, 0).NormalSDF(c)
+		if normal.Dist(expected.Scale(-1)) > 1e-4 || dist != sphere.SDF(c) {
+			t.Fatalf("unexpected normal %v and SDF %f", normal, dist)
+		}
+	}
+
+	// The gradient of a box should point to the nearest face.
+	box := &Rect{MinVal: XYZ(-1, -1, -1), MaxVal: XYZ(1, 1, 1)}
+	if actual := SDFGradient(box, XYZ(0.1, 0.9, 0.2), 1e-3); actual.Dist(Y(-1)) > 1e-8 {
+		t.Errorf("unexpected gradient: %v", actual)
+	}
+}
@@ -0,0 +1,81 @@
+package model3d
+
+import (
+	"github.com/unixpickle/model3d/model2d"
+)
+
+// SliceZ intersects the mesh with the plane z=c and gets
+// the resulting cross-section as a 2D mesh in the (x, y)
+// coordinates of the plane.
+//
+// For a closed, oriented mesh, the result is a closed,
+// oriented 2D mesh, with holes where the plane passes
+// through cavities.
+//
+// Vertices which lie exactly on the plane are treated as
+// being slightly above it, so faces within the plane do
+// not contribute to the result.
+func (m *Mesh) SliceZ(z float64) *model2d.Mesh {
+	return m.slice(&Plane{Normal: Z(1), Offset: z}, func(c Coord3D) model2d.Coord {
+		return model2d.XY(c.X, c.Y)
+	})
+}
+
+// SlicePlane is like SliceZ, but for an arbitrary plane.
+//
+// The resulting 2D coordinates are computed by
+// Plane.To2D, so Plane.From2D can map them back to 3D.
+func (m *Mesh) SlicePlane(p *Plane) *model2d.Mesh {
+	return m.slice(p, p.To2D)
+}
+
+func (m *Mesh) slice(p *Plane, to2D func(c Coord3D) model2d.Coord) *model2d.Mesh {
+	res := model2d.NewMesh()
+	zero := to2D(Coord3D{})
+	m.Iterate(func(t *Triangle) {
+		var dists [3]float64
+		var numBelow int
+		for i, c := range t {
+			dists[i] = p.SignedDist(c)
+			if dists[i] < 0 {
+				numBelow++
+			}
+		}
+		if numBelow == 0 || numBelow == 3 {
+			return
+		}
+		var points []Coord3D
+		for i := 0; i < 3; i++ {
+			j := (i + 1) % 3
+			if (dists[i] < 0) != (dists[j] < 0) {
+				points = append(points, sliceEdgePoint(t[i], t[j], dists[i], dists[j]))
+			}
+		}
+		p1, p2 := to2D(points[0]), to2D(points[1])
+		if p1 == p2 {
+			return
+		}
+		// The triangle's normal, projected into the plane,
+		// points out of the cross-section, and 2D meshes are
+		// oriented clockwise.
+		outward := to2D(t.Normal()).Sub(zero)
+		dir := p2.Sub(p1)
+		if dir.X*outward.Y-dir.Y*outward.X < 0 {
+			p1, p2 = p2, p1
+		}
+		res.Add(&model2d.Segment{p1, p2})
+	})
+	return res
+}
+
+// sliceEdgePoint computes the point where an edge crosses
+// a plane, such that the result does not depend on the
+// order of the endpoints.
+func sliceEdgePoint(c1, c2 Coord3D, d1, d2 float64) Coord3D {
+	if coordLess(c2, c1) {
+		c1, c2 = c2, c1
+		d1, d2 = d2, d1
+	}
+	t := d1 / (d1 - d2)
+	return c1.Add(c2.Sub(c1).Scale(t))
+}
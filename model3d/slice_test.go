@@ -0,0 +1,73 @@
+package model3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+func TestMeshSliceZ(t *testing.T) {
+	// A box with a cubic cavity, which creates a hole in
+	// the middle of the cross-section.
+	mesh := NewMeshRect(Coord3D{}, XYZ(3, 2, 2))
+	NewMeshRect(XYZ(1, 0.5, 0.5), XYZ(2, 1.5, 1.5)).Iterate(func(t *Triangle) {
+		mesh.Add(&Triangle{t[1], t[0], t[2]})
+	})
+
+	slice := mesh.SliceZ(1)
+	validateSlice(t, slice)
+	if a := slice.Area(); math.Abs(a-5) > 1e-8 {
+		t.Errorf("expected area 5 but got %f", a)
+	}
+	sdf := model2d.MeshToSDF(slice)
+	for _, c := range []model2d.Coord{model2d.XY(0.5, 1), model2d.XY(2.5, 0.2)} {
+		if sdf.SDF(c) <= 0 {
+			t.Errorf("expected %v to be inside", c)
+		}
+	}
+	if sdf.SDF(model2d.XY(1.5, 1)) >= 0 {
+		t.Error("expected hole to be outside")
+	}
+
+	// Slices through vertices and faces.
+	if a := mesh.SliceZ(0.5).Area(); math.Abs(a-6) > 1e-8 {
+		t.Errorf("expected area 6 but got %f", a)
+	}
+	if n := len(mesh.SliceZ(3).SegmentSlice()); n != 0 {
+		t.Errorf("expected empty slice but got %d segments", n)
+	}
+
+	sphere := NewMeshIcosphere(XYZ(1, 2, 3), 2, 5)
+	slice = sphere.SliceZ(4)
+	validateSlice(t, slice)
+	expected := math.Pi * (4 - 1)
+	if a := slice.Area(); math.Abs(a-expected) > expected*0.03 {
+		t.Errorf("expected area %f but got %f", expected, a)
+	}
+}
+
+func TestMeshSlicePlane(t *testing.T) {
+	sphere := NewMeshIcosphere(XYZ(1, 2, 3), 2, 5)
+	plane := NewPlane(XYZ(1.5, 2, 3), XYZ(1, 1, -1))
+	slice := sphere.SlicePlane(plane)
+	validateSlice(t, slice)
+	r2 := 4 - math.Pow(plane.Dist(sphere.Max().Mid(sphere.Min())), 2)
+	if a := slice.Area(); math.Abs(a-math.Pi*r2) > math.Pi*r2*0.03 {
+		t.Errorf("expected area %f but got %f", math.Pi*r2, a)
+	}
+	slice.IterateVertices(func(c model2d.Coord) {
+		if d := plane.From2D(c).Dist(XYZ(1, 2, 3)); math.Abs(d-2) > 0.03 {
+			t.Fatalf("vertex not on sphere: %v (dist %f)", c, d)
+		}
+	})
+}
+
+func validateSlice(t *testing.T, m *model2d.Mesh) {
+	if !m.Manifold() {
+		t.Fatal("mesh is not manifold")
+	}
+	if _, n := m.RepairNormals(1e-8); n != 0 {
+		t.Fatalf("mesh has %d incorrect normals", n)
+	}
+}
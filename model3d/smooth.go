@@ -49,10 +49,17 @@ type MeshSmoother struct {
 	// returns true for all of the initial points that
 	// should not be modified at all.
 	HardConstraintFunc func(origin Coord3D) bool
+
+	// Logger, if non-nil, receives progress for the
+	// "smooth" phase.
+	Logger Logger
 }
 
 // Smooth applies gradient descent to smooth the mesh.
 func (m *MeshSmoother) Smooth(mesh *Mesh) *Mesh {
+	phase := StartLogPhase(m.Logger, "smooth")
+	defer phase.End()
+
 	im := newIndexMesh(mesh)
 	origins := append([]Coord3D{}, im.Coords...)
 	newCoords := append([]Coord3D{}, im.Coords...)
@@ -101,6 +108,7 @@ func (m *MeshSmoother) Smooth(mesh *Mesh) *Mesh {
 			}
 		}
 		copy(im.Coords, newCoords)
+		phase.Progress(float64(step+1) / float64(m.Iterations))
 	}
 
 	return im.Mesh()
@@ -28,3 +28,150 @@ func TestJoinedSolidOptimize(t *testing.T) {
 		}
 	}
 }
+
+func TestOptimizeCSG(t *testing.T) {
+	// The far solids are outside of the region where they
+	// could affect the result.
+	farCalls := 0
+	far := FuncSolid(XYZ(5, 5, 5), XYZ(6, 6, 6), func(c Coord3D) bool {
+		farCalls++
+		return c.Min(XYZ(5, 5, 5)) == XYZ(5, 5, 5) && c.Max(XYZ(6, 6, 6)) == XYZ(6, 6, 6)
+	})
+	var spheres JoinedSolid
+	for i := 0; i < 6; i++ {
+		spheres = append(spheres, &Sphere{Center: NewCoord3DRandNorm(), Radius: 0.3})
+	}
+	solid := &SubtractedSolid{
+		Positive: &SubtractedSolid{
+			Positive: JoinedSolid{
+				spheres,
+				JoinedSolid{
+					&Rect{MinVal: XYZ(-1, -1, -0.2), MaxVal: XYZ(1, 1, 0.2)},
+				},
+			},
+			Negative: far,
+		},
+		Negative: IntersectedSolid{
+			IntersectedSolid{
+				&Cylinder{P1: XYZ(0, 0, -2), P2: XYZ(0, 0, 2), Radius: 0.5},
+				JoinedSolid{far, &Sphere{Radius: 0.6}},
+			},
+			&Rect{MinVal: XYZ(-1, -1, -1), MaxVal: XYZ(1, 1, 0)},
+		},
+	}
+	opt := OptimizeCSG(solid)
+
+	if opt.Min() != solid.Min() || opt.Max() != solid.Max() {
+		t.Error("incorrect bounds")
+	}
+	for i := 0; i < 10000; i++ {
+		c := NewCoord3DRandNorm()
+		farCalls = 0
+		expected := solid.Contains(c)
+		farCalls = 0
+		actual := opt.Contains(c)
+		if actual != expected {
+			t.Fatalf("expected contains %v but got %v at %v", expected, actual, c)
+		}
+		if farCalls != 0 {
+			t.Fatal("pruned solid was queried")
+		}
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		empty := IntersectedSolid{
+			&Sphere{Radius: 1},
+			&Sphere{Center: X(1.5), Radius: 0.4},
+		}
+		opt := OptimizeCSG(empty)
+		if opt.Contains(X(0.75)) {
+			t.Error("empty solid contains point")
+		}
+	})
+}
+
+func TestSmoothJoinedSolid(t *testing.T) {
+	s1 := &Sphere{Center: XYZ(-0.8, 0, 0), Radius: 1}
+	s2 := &Sphere{Center: XYZ(0.8, 0, 0), Radius: 1}
+	sharp := JoinedSolid{s1, s2}
+	smooth := &SmoothJoinedSolid{SDFs: []SDF{s1, s2}, Radius: 0.5}
+	unblended := &SmoothJoinedSolid{SDFs: []SDF{s1, s2}}
+
+	testSmoothCSG(t, sharp, smooth, unblended, 1)
+
+	// The crease between the spheres should be filled in.
+	crease := XYZ(0, 0.65, 0)
+	if sharp.Contains(crease) || !smooth.Contains(crease) {
+		t.Error("crease should be filled in")
+	}
+}
+
+func TestSmoothIntersectedSolid(t *testing.T) {
+	s1 := &Sphere{Center: XYZ(-0.8, 0, 0), Radius: 1}
+	s2 := &Sphere{Center: XYZ(0.8, 0, 0), Radius: 1}
+	sharp := IntersectedSolid{s1, s2}
+	smooth := &SmoothIntersectedSolid{SDFs: []SDF{s1, s2}, Radius: 0.5}
+	unblended := &SmoothIntersectedSolid{SDFs: []SDF{s1, s2}}
+
+	testSmoothCSG(t, sharp, smooth, unblended, -1)
+
+	// The sharp rim of the lens should be rounded off.
+	rim := XYZ(0, 0.55, 0)
+	if !sharp.Contains(rim) || smooth.Contains(rim) {
+		t.Error("rim should be rounded off")
+	}
+}
+
+func TestSmoothSubtractedSolid(t *testing.T) {
+	pos := &Rect{MinVal: XYZ(-1, -1, -1), MaxVal: XYZ(1, 1, 1)}
+	neg := &Sphere{Center: XYZ(0, 0, 1), Radius: 0.5}
+	sharp := &SubtractedSolid{Positive: pos, Negative: neg}
+	smooth := &SmoothSubtractedSolid{Positive: pos, Negative: neg, Radius: 0.3}
+	unblended := &SmoothSubtractedSolid{Positive: pos, Negative: neg}
+
+	testSmoothCSG(t, sharp, smooth, unblended, -1)
+
+	// The edge of the hole should be rounded off.
+	edge := XYZ(0.52, 0, 0.99)
+	if !sharp.Contains(edge) || smooth.Contains(edge) {
+		t.Error("edge of hole should be rounded off")
+	}
+}
+
+// testSmoothCSG checks that a smooth solid only adds (for
+// sign 1) or removes (for sign -1) points from the sharp
+// solid, and that a zero radius matches the sharp solid.
+func testSmoothCSG(t *testing.T, sharp, smooth, unblended Solid, sign int) {
+	min := sharp.Min().Min(smooth.Min())
+	max := sharp.Max().Max(smooth.Max())
+	for i := 0; i < 10000; i++ {
+		c := NewCoord3DRandBounds(min.AddScalar(-0.1), max.AddScalar(0.1))
+		expected := sharp.Contains(c)
+		if actual := unblended.Contains(c); actual != expected {
+			t.Fatalf("zero radius mismatch at %v: expected %v but got %v", c, expected, actual)
+		}
+		actual := smooth.Contains(c)
+		if sign > 0 && expected && !actual {
+			t.Fatalf("smooth solid is missing point %v", c)
+		} else if sign < 0 && actual && !expected {
+			t.Fatalf("smooth solid has extra point %v", c)
+		}
+	}
+}
+
+func TestPadSolidBounds(t *testing.T) {
+	solid := &Sphere{Center: XYZ(1, 2, 3), Radius: 0.5}
+	padded := PadSolidBounds(solid, XYZ(0.1, 0, 0.3))
+	if padded.Min().Dist(XYZ(0.4, 1.5, 2.2)) > 1e-8 {
+		t.Errorf("unexpected min: %v", padded.Min())
+	}
+	if padded.Max().Dist(XYZ(1.6, 2.5, 3.8)) > 1e-8 {
+		t.Errorf("unexpected max: %v", padded.Max())
+	}
+	for i := 0; i < 1000; i++ {
+		c := NewCoord3DRandNorm().Add(solid.Center)
+		if padded.Contains(c) != solid.Contains(c) {
+			t.Fatalf("mismatched containment at %v", c)
+		}
+	}
+}
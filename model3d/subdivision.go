@@ -1,12 +1,25 @@
 package model3d
 
+// Subdivide uses Loop subdivision to smooth the mesh,
+// splitting every triangle into four triangles in each of
+// iters iterations.
+//
+// This is equivalent to LoopSubdivision(m, iters).
+// To add triangles without changing the shape of the
+// mesh, use SubdivideEdges instead.
+func (m *Mesh) Subdivide(iters int) *Mesh {
+	return LoopSubdivision(m, iters)
+}
+
 // LoopSubdivision subdivides the mesh using the Loop
 // subdivision rule, creating a smoother surface with
 // more triangles.
 //
 // The mesh is subdivided iters times.
 //
-// The mesh must not have singular edges.
+// The mesh must not have singular edges, but it may have
+// boundary edges, which are smoothed as curves so that
+// holes in the mesh stay open.
 func LoopSubdivision(m *Mesh, iters int) *Mesh {
 	for i := 0; i < iters; i++ {
 		m = loopSubdivision(m)
@@ -22,7 +35,10 @@ func loopSubdivision(m *Mesh) *Mesh {
 				continue
 			}
 			ts := m.Find(seg[0], seg[1])
-			if len(ts) != 2 {
+			if len(ts) == 1 {
+				edgePoints[seg] = seg.Mid()
+				continue
+			} else if len(ts) != 2 {
 				panic("singular edge detected")
 			}
 			o1 := seg.Other(ts[0])
@@ -34,14 +50,33 @@ func loopSubdivision(m *Mesh) *Mesh {
 	cornerPoints := map[Coord3D]Coord3D{}
 	m.getVertexToFace().Range(func(corner Coord3D, tris []*Triangle) bool {
 		neighbors := map[Coord3D]bool{}
+		edgeCounts := map[Coord3D]int{}
 		for _, t := range tris {
 			for _, c := range t {
 				if c != corner {
 					neighbors[c] = true
+					edgeCounts[c]++
 				}
 			}
 		}
 
+		var boundary []Coord3D
+		for c, count := range edgeCounts {
+			if count == 1 {
+				boundary = append(boundary, c)
+			}
+		}
+		if len(boundary) == 2 {
+			cornerPoints[corner] = corner.Scale(3.0 / 4).Add(
+				boundary[0].Add(boundary[1]).Scale(1.0 / 8),
+			)
+			return true
+		} else if len(boundary) > 0 {
+			// Leave singular boundary vertices in place.
+			cornerPoints[corner] = corner
+			return true
+		}
+
 		var beta float64
 		if len(neighbors) == 3 {
 			beta = 3.0 / 16
@@ -12,6 +12,53 @@ func TestLoopSubdivision(t *testing.T) {
 	MustValidateMesh(t, mesh, false)
 }
 
+func TestLoopSubdivisionBoundary(t *testing.T) {
+	// Create a box with an open top.
+	base := NewMeshRect(X(-1), XYZ(1, 1, 1))
+	base.Iterate(func(t *Triangle) {
+		if t.Normal().Z > 0.5 {
+			base.Remove(t)
+		}
+	})
+
+	mesh := base.Subdivide(3)
+	if n, expected := len(mesh.TriangleSlice()), len(base.TriangleSlice())*64; n != expected {
+		t.Errorf("expected %d triangles but got %d", expected, n)
+	}
+	if n := len(mesh.SingularVertices()); n != 0 {
+		t.Errorf("got %d singular vertices", n)
+	}
+
+	// Neighboring triangles should traverse their shared
+	// edge in opposite directions.
+	directed := map[Segment]bool{}
+	mesh.Iterate(func(tri *Triangle) {
+		for i := 0; i < 3; i++ {
+			seg := Segment{tri[i], tri[(i+1)%3]}
+			if directed[seg] {
+				t.Fatalf("inconsistent orientation at edge %v", seg)
+			}
+			directed[seg] = true
+		}
+	})
+
+	// The hole should remain open and planar.
+	var numBoundary int
+	mesh.Iterate(func(tri *Triangle) {
+		for _, seg := range tri.Segments() {
+			if len(mesh.Find(seg[0], seg[1])) == 1 {
+				numBoundary++
+				if seg[0].Z != 1 || seg[1].Z != 1 {
+					t.Fatalf("unexpected boundary segment: %v", seg)
+				}
+			}
+		}
+	})
+	if numBoundary != 4*8 {
+		t.Errorf("expected %d boundary segments but got %d", 4*8, numBoundary)
+	}
+}
+
 func TestSubdivideEdges(t *testing.T) {
 	base := NewMeshTorus(XYZ(0.2, 0.3, 0.4), XY(0.5, 1.0).Normalize(), 0.2, 1.0, 5, 5)
 	for i := 1; i < 6; i++ {
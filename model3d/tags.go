@@ -0,0 +1,177 @@
+package model3d
+
+import (
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// A TaggedMesh is a mesh where each triangle is labeled
+// with an integer tag, such as the ID of the solid it came
+// from or the region of a model it belongs to.
+//
+// Mesh operations on a TaggedMesh carry the tags over to
+// the resulting triangles, so that multi-part exports and
+// selective post-processing can tell which triangles came
+// from which feature.
+type TaggedMesh struct {
+	Mesh *Mesh
+
+	// Tags maps triangles of Mesh to their tags.
+	// Triangles without an entry have a tag of 0.
+	Tags map[*Triangle]int
+}
+
+// NewTaggedMesh creates an empty TaggedMesh.
+func NewTaggedMesh() *TaggedMesh {
+	return &TaggedMesh{Mesh: NewMesh(), Tags: map[*Triangle]int{}}
+}
+
+// NewTaggedMeshMesh creates a TaggedMesh where every
+// triangle of m has the same tag.
+//
+// The triangles of m are shared with the new mesh.
+func NewTaggedMeshMesh(m *Mesh, tag int) *TaggedMesh {
+	res := NewTaggedMesh()
+	res.AddMesh(m, tag)
+	return res
+}
+
+// Add adds a triangle with the given tag.
+func (t *TaggedMesh) Add(tri *Triangle, tag int) {
+	t.Mesh.Add(tri)
+	t.Tags[tri] = tag
+}
+
+// AddMesh adds all of the triangles of m with the given
+// tag.
+func (t *TaggedMesh) AddMesh(m *Mesh, tag int) {
+	m.Iterate(func(tri *Triangle) {
+		t.Add(tri, tag)
+	})
+}
+
+// AddTagged adds all of the triangles of another tagged
+// mesh, keeping their tags.
+func (t *TaggedMesh) AddTagged(t1 *TaggedMesh) {
+	t1.Mesh.Iterate(func(tri *Triangle) {
+		t.Add(tri, t1.Tag(tri))
+	})
+}
+
+// Remove removes a triangle and its tag.
+func (t *TaggedMesh) Remove(tri *Triangle) {
+	t.Mesh.Remove(tri)
+	delete(t.Tags, tri)
+}
+
+// Tag gets the tag of a triangle.
+func (t *TaggedMesh) Tag(tri *Triangle) int {
+	return t.Tags[tri]
+}
+
+// TagSet gets the distinct tags in the mesh in ascending
+// order.
+func (t *TaggedMesh) TagSet() []int {
+	seen := map[int]bool{}
+	var res []int
+	t.Mesh.Iterate(func(tri *Triangle) {
+		tag := t.Tag(tri)
+		if !seen[tag] {
+			seen[tag] = true
+			res = append(res, tag)
+		}
+	})
+	sort.Ints(res)
+	return res
+}
+
+// Select creates a mesh containing only the triangles with
+// the given tag.
+func (t *TaggedMesh) Select(tag int) *Mesh {
+	res := NewMesh()
+	t.Mesh.Iterate(func(tri *Triangle) {
+		if t.Tag(tri) == tag {
+			res.Add(tri)
+		}
+	})
+	return res
+}
+
+// TriangleColor creates a per-triangle color function
+// which looks up each triangle's tag in colors.
+//
+// The result can be passed to Mesh.SaveMaterialOBJ.
+func (t *TaggedMesh) TriangleColor(colors map[int][3]float64) func(tri *Triangle) [3]float64 {
+	return func(tri *Triangle) [3]float64 {
+		return colors[t.Tag(tri)]
+	}
+}
+
+// WriteAMF writes the mesh to w as an AMF file with an
+// object for each tag.
+//
+// See BuildTaggedAMF for details.
+func (t *TaggedMesh) WriteAMF(w io.Writer, colors map[int][3]float64) error {
+	if err := BuildTaggedAMF(t, colors).Write(w); err != nil {
+		return errors.Wrap(err, "write AMF")
+	}
+	return nil
+}
+
+// SaveAMF saves the mesh to an AMF file with an object for
+// each tag.
+//
+// See BuildTaggedAMF for details.
+func (t *TaggedMesh) SaveAMF(path string, colors map[int][3]float64) error {
+	err := saveToFile(path, func(w io.Writer) error {
+		return t.WriteAMF(w, colors)
+	})
+	if err != nil {
+		return errors.Wrap(err, "save AMF")
+	}
+	return nil
+}
+
+// MapCoords creates a new tagged mesh by transforming all
+// of the coordinates according to f.
+//
+// Each new triangle has the tag of the triangle it was
+// created from.
+func (t *TaggedMesh) MapCoords(f func(Coord3D) Coord3D) *TaggedMesh {
+	res := NewTaggedMesh()
+	t.Mesh.Iterate(func(tri *Triangle) {
+		tri1 := *tri
+		for i, c := range tri1 {
+			tri1[i] = f(c)
+		}
+		res.Add(&tri1, t.Tag(tri))
+	})
+	return res
+}
+
+// Blur is like Mesh.Blur, but it preserves tags.
+func (t *TaggedMesh) Blur(rates ...float64) *TaggedMesh {
+	return t.BlurFiltered(nil, rates...)
+}
+
+// BlurFiltered is like Mesh.BlurFiltered, but it preserves
+// tags.
+func (t *TaggedMesh) BlurFiltered(f func(c1, c2 Coord3D) bool, rates ...float64) *TaggedMesh {
+	return t.MapCoords(t.Mesh.blurMapping(f, rates))
+}
+
+// Repair is like Mesh.Repair, but it preserves tags.
+func (t *TaggedMesh) Repair(epsilon float64) *TaggedMesh {
+	return t.MapCoords(t.Mesh.repairMapping(epsilon))
+}
+
+// EliminateCoplanar is like Mesh.EliminateCoplanar, but it
+// preserves tags.
+//
+// Vertices on the boundary between differently tagged
+// triangles are never removed.
+func (t *TaggedMesh) EliminateCoplanar(epsilon float64) *TaggedMesh {
+	return coplanarDecimator(epsilon).DecimateTagged(t)
+}
@@ -0,0 +1,119 @@
+package model3d
+
+import (
+	"math"
+	"testing"
+)
+
+func testingTaggedMesh() *TaggedMesh {
+	// Two boxes sharing a face, subdivided so that there are
+	// coplanar vertices to remove.
+	mesh := NewMeshRect(XYZ(0, 0, 0), XYZ(2, 1, 1))
+	mesh = SubdivideEdges(mesh, 4)
+	res := NewTaggedMesh()
+	mesh.Iterate(func(t *Triangle) {
+		c := t[0].Add(t[1]).Add(t[2]).Scale(1.0 / 3)
+		if c.X < 1 {
+			res.Add(t, 1)
+		} else {
+			res.Add(t, 2)
+		}
+	})
+	return res
+}
+
+func checkTaggedMesh(t *testing.T, tm *TaggedMesh) {
+	if tm.Mesh.NeedsRepair() {
+		t.Fatal("mesh needs repair")
+	}
+	if len(tm.Tags) != len(tm.Mesh.TriangleSlice()) {
+		t.Fatal("tag count does not match triangle count")
+	}
+	tm.Mesh.Iterate(func(tri *Triangle) {
+		c := tri[0].Add(tri[1]).Add(tri[2]).Scale(1.0 / 3)
+		expected := 1
+		if c.X > 1 {
+			expected = 2
+		}
+		if tm.Tag(tri) != expected {
+			t.Fatalf("triangle at %v has tag %d", c, tm.Tag(tri))
+		}
+	})
+}
+
+func TestTaggedMeshBasics(t *testing.T) {
+	tm := testingTaggedMesh()
+	if tags := tm.TagSet(); len(tags) != 2 || tags[0] != 1 || tags[1] != 2 {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	total := len(tm.Mesh.TriangleSlice())
+	n1 := len(tm.Select(1).TriangleSlice())
+	n2 := len(tm.Select(2).TriangleSlice())
+	if n1 == 0 || n2 == 0 || n1+n2 != total {
+		t.Errorf("unexpected selection sizes: %d, %d (total %d)", n1, n2, total)
+	}
+	colors := tm.TriangleColor(map[int][3]float64{2: {1, 0, 0}})
+	for _, tri := range tm.Select(2).TriangleSlice() {
+		if colors(tri) != [3]float64{1, 0, 0} {
+			t.Fatal("unexpected color")
+		}
+	}
+}
+
+func TestTaggedMeshOps(t *testing.T) {
+	tm := testingTaggedMesh()
+
+	t.Run("Repair", func(t *testing.T) {
+		// Perturb each triangle separately so that the
+		// vertices no longer line up.
+		jittered := NewTaggedMesh()
+		tm.Mesh.Iterate(func(tri *Triangle) {
+			tri1 := *tri
+			for i := range tri1 {
+				tri1[i] = tri1[i].Add(NewCoord3DRandNorm().Scale(1e-7))
+			}
+			jittered.Add(&tri1, tm.Tag(tri))
+		})
+		if !jittered.Mesh.NeedsRepair() {
+			t.Fatal("jittered mesh should need repair")
+		}
+		checkTaggedMesh(t, jittered.Repair(1e-5))
+	})
+
+	t.Run("Blur", func(t *testing.T) {
+		blurred := tm.Blur(0.1)
+		if len(blurred.Tags) != len(tm.Tags) {
+			t.Fatal("unexpected number of triangles")
+		}
+		for _, tag := range []int{1, 2} {
+			area1 := tm.Select(tag).Area()
+			area2 := blurred.Select(tag).Area()
+			if area2 >= area1 || area2 < area1/2 {
+				t.Errorf("tag %d: unexpected area %f (original %f)", tag, area2, area1)
+			}
+		}
+	})
+
+	t.Run("EliminateCoplanar", func(t *testing.T) {
+		elim := tm.EliminateCoplanar(1e-5)
+		checkTaggedMesh(t, elim)
+		if len(elim.Tags) >= len(tm.Tags) {
+			t.Error("no triangles were removed")
+		}
+		for _, tag := range []int{1, 2} {
+			a1, a2 := tm.Select(tag).Area(), elim.Select(tag).Area()
+			if math.Abs(a1-a2) > 1e-8 {
+				t.Errorf("tag %d: area changed from %f to %f", tag, a1, a2)
+			}
+		}
+	})
+
+	t.Run("Decimate", func(t *testing.T) {
+		d := &Decimator{PlaneDistance: 1e-3, BoundaryDistance: 1e-3}
+		dec := d.DecimateTagged(tm)
+		checkTaggedMesh(t, dec)
+		if len(dec.Tags) >= len(tm.Tags) {
+			t.Error("no triangles were removed")
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package render3d
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// TurntablePath creates a camera path which circles
+// around an axis through center, for use with
+// RenderAnimation.
+//
+// The camera starts at origin and always faces center,
+// completing one full rotation as t goes from 0 to 1.
+//
+// If fov is 0, DefaultFieldOfView is used.
+func TurntablePath(center, origin, axis model3d.Coord3D, fov float64) func(t float64) Camera {
+	offset := origin.Sub(center)
+	return func(t float64) Camera {
+		rotation := model3d.Rotation(axis, 2*math.Pi*t)
+		return NewCameraAt(center.Add(rotation.Apply(offset)), center, fov)
+	}
+}
+
+// RenderAnimation renders frames of an object as seen by
+// a camera moving along a path, such as a TurntablePath.
+//
+// The path is called with evenly spaced times in [0, 1),
+// so that looping animations do not repeat the first
+// frame.
+//
+// Frames are rendered like RenderView, with a light
+// behind the camera in every frame.
+// The obj argument must be supported by Objectify.
+func RenderAnimation(obj interface{}, path func(t float64) Camera, numFrames, width,
+	height int, colorFunc ColorFunc) []*Image {
+	object := Objectify(obj, colorFunc)
+	center := object.Min().Mid(object.Max())
+	return RenderAnimationFunc(path, numFrames, width, height, func(img *Image,
+		camera Camera) {
+		// Find the center of the view to position the light.
+		gen := rand.New(rand.NewSource(0))
+		maxX, maxY := float64(width)-1, float64(height)-1
+		ray := camera.Rays(maxX, maxY)(gen, maxX/2, maxY/2)
+		caster := &RayCaster{
+			Camera: camera,
+			Lights: []*PointLight{
+				{
+					Origin: center.Add(ray.Origin.Sub(center).Scale(1000)),
+					Color:  NewColor(1.0),
+				},
+			},
+		}
+		caster.Render(img, object)
+	})
+}
+
+// RenderAnimationFunc is like RenderAnimation, but it uses
+// a custom function to render each frame from a camera.
+//
+// This can be used to animate scenes with renderers such
+// as a RecursiveRayTracer, by setting the renderer's
+// camera in the render function.
+func RenderAnimationFunc(path func(t float64) Camera, numFrames, width, height int,
+	render func(img *Image, camera Camera)) []*Image {
+	frames := make([]*Image, numFrames)
+	for i := range frames {
+		frames[i] = NewImage(width, height)
+		render(frames[i], path(float64(i)/float64(numFrames)))
+	}
+	return frames
+}
+
+// SaveAnimationFrames saves a sequence of frames as
+// numbered images.
+//
+// The pathFormat is a format string for the frame index,
+// such as "frame_%03d.png".
+// The format of each image is determined by Image.Save.
+func SaveAnimationFrames(pathFormat string, frames []*Image) error {
+	for i, frame := range frames {
+		if err := frame.Save(fmt.Sprintf(pathFormat, i)); err != nil {
+			return errors.Wrap(err, "save animation frames")
+		}
+	}
+	return nil
+}
+
+// SaveAnimationGIF saves a sequence of frames as an
+// animated GIF which loops forever.
+//
+// The delay is the duration of each frame, in hundredths
+// of a second.
+func SaveAnimationGIF(path string, frames []*Image, delay int) error {
+	w, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "save animation GIF")
+	}
+	if err := WriteAnimationGIF(w, frames, delay); err != nil {
+		w.Close()
+		return errors.Wrap(err, "save animation GIF")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "save animation GIF")
+	}
+	return nil
+}
+
+// WriteAnimationGIF encodes a sequence of frames as an
+// animated GIF which loops forever.
+//
+// Colors are reduced to a fixed palette with dithering.
+func WriteAnimationGIF(w io.Writer, frames []*Image, delay int) error {
+	var g gif.GIF
+	for _, frame := range frames {
+		rgba := frame.RGBA()
+		paletted := image.NewPaletted(rgba.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, rgba.Bounds(), rgba, image.Point{})
+		g.Image = append(g.Image, paletted)
+		g.Delay = append(g.Delay, delay)
+	}
+	if err := gif.EncodeAll(w, &g); err != nil {
+		return errors.Wrap(err, "write animation GIF")
+	}
+	return nil
+}
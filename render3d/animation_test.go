@@ -0,0 +1,83 @@
+package render3d
+
+import (
+	"bytes"
+	"fmt"
+	"image/gif"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestTurntablePath(t *testing.T) {
+	center := model3d.XYZ(1, 2, 3)
+	path := TurntablePath(center, model3d.XYZ(1, -2, 4), model3d.Z(1), 0)
+	expected := []model3d.Coord3D{
+		model3d.XYZ(1, -2, 4),
+		model3d.XYZ(5, 2, 4),
+		model3d.XYZ(1, 6, 4),
+		model3d.XYZ(-3, 2, 4),
+	}
+	for i, origin := range expected {
+		cam := path(float64(i) / 4).(*PinholeCamera)
+		if cam.Origin.Dist(origin) > 1e-8 {
+			t.Errorf("frame %d: expected origin %v but got %v", i, origin, cam.Origin)
+		}
+		forward := cam.ScreenX.Cross(cam.ScreenY)
+		if forward.Dist(center.Sub(origin).Normalize()) > 1e-8 {
+			t.Errorf("frame %d: camera does not face center", i)
+		}
+	}
+}
+
+func TestRenderAnimation(t *testing.T) {
+	box := &model3d.Rect{MinVal: model3d.XYZ(-1, -1, -1), MaxVal: model3d.XYZ(1, 1, 1)}
+	path := TurntablePath(model3d.Coord3D{}, model3d.XYZ(0, -5, 2), model3d.Z(1), 0)
+	frames := RenderAnimation(box, path, 3, 16, 12, nil)
+	if len(frames) != 3 {
+		t.Fatalf("unexpected number of frames: %d", len(frames))
+	}
+	for i, frame := range frames {
+		if frame.Width != 16 || frame.Height != 12 {
+			t.Fatalf("frame %d: unexpected size", i)
+		}
+		center := frame.Data[6*16+8]
+		if center.Sum() < 0.1 {
+			t.Errorf("frame %d: center is not lit: %v", i, center)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAnimationGIF(&buf, frames, 7); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Image) != 3 {
+		t.Errorf("unexpected number of GIF frames: %d", len(decoded.Image))
+	}
+	for i, delay := range decoded.Delay {
+		if delay != 7 {
+			t.Errorf("frame %d: unexpected delay %d", i, delay)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "render_animation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := SaveAnimationFrames(filepath.Join(dir, "frame_%03d.png"), frames); err != nil {
+		t.Fatal(err)
+	}
+	for i := range frames {
+		if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("frame_%03d.png", i))); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -12,7 +12,7 @@ import (
 // Lights in the path tracer should be part of the scene,
 // but should also be provided as an AreaLight.
 type BidirPathTracer struct {
-	Camera *Camera
+	Camera Camera
 
 	// Light is the (possibly joined) area light that is
 	// sampled for light-to-eye paths.
@@ -60,6 +60,9 @@ type BidirPathTracer struct {
 	Antialias float64
 	Epsilon   float64
 	LogFunc   func(frac float64, sampleRate float64)
+	Logger    model3d.Logger
+	Buffers   *GeometryBuffers
+	Denoiser  Denoiser
 }
 
 // Render renders the object to an image.
@@ -67,6 +70,16 @@ func (b *BidirPathTracer) Render(img *Image, obj Object) {
 	b.rayRenderer().Render(img, obj)
 }
 
+// RenderTiled is like Render, but it renders the image
+// one tile at a time, periodically saving progress to a
+// checkpoint which can be used to resume an interrupted
+// render.
+//
+// See TileOptions for details.
+func (b *BidirPathTracer) RenderTiled(img *Image, obj Object, opts *TileOptions) error {
+	return b.rayRenderer().RenderTiled(img, obj, opts)
+}
+
 // RenderVariance computes the variance per pixel using a
 // fixed number of rays per pixel, and writes the results
 // as pixels in an image.
@@ -95,6 +108,9 @@ func (b *BidirPathTracer) rayRenderer() *rayRenderer {
 		Convergence:          b.Convergence,
 		Antialias:            b.Antialias,
 		LogFunc:              b.LogFunc,
+		Logger:               b.Logger,
+		Buffers:              b.Buffers,
+		Denoiser:             b.Denoiser,
 	}
 }
 
@@ -0,0 +1,202 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// albedoNumSamples is the number of directions sampled to
+// estimate the albedo of each pixel.
+const albedoNumSamples = 16
+
+// GeometryBuffers store information about the first
+// surface seen through each pixel of a rendering, such as
+// its depth and normal.
+//
+// These are useful for compositing renderings, guiding
+// denoisers, and generating datasets.
+//
+// Pixels are stored in the same order as Image.Data.
+type GeometryBuffers struct {
+	Width  int
+	Height int
+
+	// Depth is the distance from the camera to each
+	// pixel's surface, as computed by Camera.Depth.
+	// For most cameras, this is measured along the
+	// direction the camera is facing (rather than along
+	// the ray).
+	//
+	// Pixels where no surface is visible have infinite
+	// depth.
+	Depth []float64
+
+	// Normals are the unit normals of each pixel's surface
+	// in world coordinates.
+	//
+	// Pixels where no surface is visible have zero normals.
+	Normals []model3d.Coord3D
+
+	// Albedo is the fraction of light reflected toward the
+	// camera by each pixel's surface, averaged over all
+	// incoming directions.
+	// This is roughly the surface's color, without any
+	// lighting or shadows.
+	//
+	// Pixels where no surface is visible have zero albedo.
+	Albedo []Color
+
+	// Variance is the estimated variance of each pixel's
+	// color, as computed by the renderer from the spread
+	// of its samples.
+	// This is the variance of the pixel's mean, so it
+	// shrinks as more samples are taken.
+	//
+	// This is only filled in by sampling renderers, such as
+	// RecursiveRayTracer, and is zero otherwise.
+	Variance []Color
+}
+
+// NewGeometryBuffers creates empty buffers of the given
+// size.
+func NewGeometryBuffers(width, height int) *GeometryBuffers {
+	res := &GeometryBuffers{
+		Width:    width,
+		Height:   height,
+		Depth:    make([]float64, width*height),
+		Normals:  make([]model3d.Coord3D, width*height),
+		Albedo:   make([]Color, width*height),
+		Variance: make([]Color, width*height),
+	}
+	for i := range res.Depth {
+		res.Depth[i] = math.Inf(1)
+	}
+	return res
+}
+
+// RenderGeometryBuffers computes geometry buffers for an
+// object as seen from a camera.
+//
+// Rays are cast through the center of each pixel, so the
+// buffers are aligned with the corresponding image from
+// any renderer that uses the same camera.
+func RenderGeometryBuffers(c Camera, obj Object, width, height int) *GeometryBuffers {
+	res := NewGeometryBuffers(width, height)
+	res.render(c, obj)
+	return res
+}
+
+// render fills in the buffers, which must already have
+// the correct size.
+func (g *GeometryBuffers) render(c Camera, obj Object) {
+	rays := c.Rays(float64(g.Width)-1, float64(g.Height)-1)
+	mapCoordinates(g.Width, g.Height, func(gi *goInfo, x, y, idx int) {
+		ray := rays(gi.Gen, float64(x), float64(y))
+		g.Variance[idx] = Color{}
+		collision, material, ok := obj.Cast(&ray)
+		if !ok {
+			g.Depth[idx] = math.Inf(1)
+			g.Normals[idx] = model3d.Coord3D{}
+			g.Albedo[idx] = Color{}
+			return
+		}
+		normal := collision.Normal.Normalize()
+		dest := ray.Direction.Normalize().Scale(-1)
+		g.Depth[idx] = c.Depth(ray.Origin.Add(ray.Direction.Scale(collision.Scale)))
+		g.Normals[idx] = normal
+		g.Albedo[idx] = materialAlbedo(gi.Gen, material, normal, dest)
+	})
+}
+
+// materialAlbedo estimates the fraction of light that a
+// material reflects into the direction dest, averaged
+// over all source directions.
+func materialAlbedo(gen *rand.Rand, mat Material, normal, dest model3d.Coord3D) Color {
+	var sum Color
+	for i := 0; i < albedoNumSamples; i++ {
+		source := mat.SampleSource(gen, normal, dest)
+		density := mat.SourceDensity(normal, source, dest)
+		if density == 0 {
+			continue
+		}
+		weight := math.Abs(source.Dot(normal)) / density
+		sum = sum.Add(mat.BSDF(normal, source, dest).Scale(weight))
+	}
+	return sum.Scale(1.0 / albedoNumSamples).Min(NewColor(1))
+}
+
+// resize makes sure the buffers match an image size.
+func (g *GeometryBuffers) resize(width, height int) {
+	n := width * height
+	if g.Width != width || g.Height != height || len(g.Depth) != n ||
+		len(g.Normals) != n || len(g.Albedo) != n || len(g.Variance) != n {
+		*g = *NewGeometryBuffers(width, height)
+	}
+}
+
+// DepthImage creates a grayscale image of the depth
+// buffer, where the nearest surface is white, the
+// furthest surface is black, and empty pixels are black.
+//
+// The pixel values are linear in depth once the image is
+// saved.
+func (g *GeometryBuffers) DepthImage() *Image {
+	minDepth, maxDepth := math.Inf(1), math.Inf(-1)
+	for _, d := range g.Depth {
+		if !math.IsInf(d, 1) {
+			minDepth = math.Min(minDepth, d)
+			maxDepth = math.Max(maxDepth, d)
+		}
+	}
+	return g.DepthImageRange(minDepth, maxDepth)
+}
+
+// DepthImageRange is like DepthImage, but it uses a fixed
+// range of depths, so that multiple images can be
+// compared.
+//
+// Depths outside of the range are clamped.
+func (g *GeometryBuffers) DepthImageRange(minDepth, maxDepth float64) *Image {
+	res := NewImage(g.Width, g.Height)
+	for i, d := range g.Depth {
+		if math.IsInf(d, 1) {
+			continue
+		}
+		var v float64
+		if maxDepth > minDepth {
+			v = math.Max(0, math.Min(1, (maxDepth-d)/(maxDepth-minDepth)))
+		} else {
+			v = 1
+		}
+		res.Data[i] = NewColorRGB(v, v, v)
+	}
+	return res
+}
+
+// NormalImage creates an image of the normal buffer,
+// where each component of a normal is mapped from [-1, 1]
+// to a color channel in [0, 1] once the image is saved.
+//
+// Empty pixels are black.
+func (g *GeometryBuffers) NormalImage() *Image {
+	res := NewImage(g.Width, g.Height)
+	for i, n := range g.Normals {
+		if (n == model3d.Coord3D{}) {
+			continue
+		}
+		c := n.Add(model3d.XYZ(1, 1, 1)).Scale(0.5)
+		res.Data[i] = NewColorRGB(c.X, c.Y, c.Z)
+	}
+	return res
+}
+
+// AlbedoImage creates an image of the albedo buffer.
+//
+// Empty pixels are black.
+func (g *GeometryBuffers) AlbedoImage() *Image {
+	res := NewImage(g.Width, g.Height)
+	copy(res.Data, g.Albedo)
+	return res
+}
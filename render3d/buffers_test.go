@@ -0,0 +1,108 @@
+package render3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestGeometryBuffers(t *testing.T) {
+	camera := NewCameraAt(model3d.Y(-5), model3d.Coord3D{}, math.Pi/3)
+	sphere := &model3d.Sphere{Radius: 1}
+	buffers := RenderGeometryBuffers(camera, Objectify(sphere, nil), 33, 33)
+
+	center := 16*33 + 16
+	if math.Abs(buffers.Depth[center]-4) > 1e-5 {
+		t.Errorf("unexpected center depth: %f", buffers.Depth[center])
+	}
+	if buffers.Normals[center].Dist(model3d.Y(-1)) > 1e-5 {
+		t.Errorf("unexpected center normal: %v", buffers.Normals[center])
+	}
+	if !math.IsInf(buffers.Depth[0], 1) || (buffers.Normals[0] != model3d.Coord3D{}) {
+		t.Errorf("unexpected corner: depth=%f normal=%v", buffers.Depth[0], buffers.Normals[0])
+	}
+
+	// Depth is measured along the camera's axis, so every
+	// visible point's depth is its distance from the plane
+	// of the camera.
+	caster := camera.Caster(32, 32)
+	for y := 0; y < 33; y++ {
+		for x := 0; x < 33; x++ {
+			idx := y*33 + x
+			ray := &model3d.Ray{Origin: camera.Origin, Direction: caster(float64(x), float64(y))}
+			rc, ok := sphere.FirstRayCollision(ray)
+			if ok != !math.IsInf(buffers.Depth[idx], 1) {
+				t.Fatalf("pixel %d,%d: unexpected visibility", x, y)
+			} else if !ok {
+				continue
+			}
+			p := ray.Origin.Add(ray.Direction.Scale(rc.Scale))
+			if math.Abs(buffers.Depth[idx]-(p.Y+5)) > 1e-5 {
+				t.Fatalf("pixel %d,%d: expected depth %f but got %f", x, y, p.Y+5,
+					buffers.Depth[idx])
+			}
+			if buffers.Normals[idx].Dist(p.Normalize()) > 1e-5 {
+				t.Fatalf("pixel %d,%d: expected normal %v but got %v", x, y, p.Normalize(),
+					buffers.Normals[idx])
+			}
+		}
+	}
+
+	depthImage := buffers.DepthImage()
+	if r, g, b := RGB(depthImage.Data[center]); math.Abs(r-1) > 1e-5 || r != g || r != b {
+		t.Errorf("unexpected center depth color: %f, %f, %f", r, g, b)
+	}
+	if (depthImage.Data[0] != Color{}) {
+		t.Errorf("unexpected corner depth color: %v", depthImage.Data[0])
+	}
+	r, g, b := RGB(buffers.NormalImage().Data[center])
+	if math.Abs(r-0.5) > 1e-5 || math.Abs(g) > 1e-5 || math.Abs(b-0.5) > 1e-5 {
+		t.Errorf("unexpected center normal color: %f, %f, %f", r, g, b)
+	}
+}
+
+func TestRendererGeometryBuffers(t *testing.T) {
+	camera := NewCameraAt(model3d.XYZ(3, -4, 2), model3d.Coord3D{}, math.Pi/3)
+	obj := Objectify(&model3d.Sphere{Radius: 1}, nil)
+	expected := RenderGeometryBuffers(camera, obj, 16, 12)
+
+	lights := []*PointLight{{Origin: model3d.XYZ(3, -4, 5), Color: NewColor(1)}}
+	type renderer interface {
+		Render(img *Image, obj Object)
+	}
+	renderers := map[string]func(buffers *GeometryBuffers) renderer{
+		"RayCaster": func(buffers *GeometryBuffers) renderer {
+			return &RayCaster{Camera: camera, Lights: lights, Buffers: buffers}
+		},
+		"RecursiveRayTracer": func(buffers *GeometryBuffers) renderer {
+			return &RecursiveRayTracer{
+				Camera:     camera,
+				Lights:     lights,
+				NumSamples: 1,
+				Antialias:  1,
+				Buffers:    buffers,
+			}
+		},
+	}
+	for name, f := range renderers {
+		// The buffers should be resized automatically.
+		buffers := NewGeometryBuffers(1, 1)
+		f(buffers).Render(NewImage(16, 12), obj)
+		if buffers.Width != 16 || buffers.Height != 12 {
+			t.Errorf("%s: unexpected size %dx%d", name, buffers.Width, buffers.Height)
+			continue
+		}
+		for i, d := range expected.Depth {
+			if d != buffers.Depth[i] && math.Abs(d-buffers.Depth[i]) > 1e-8 {
+				t.Errorf("%s: pixel %d: expected depth %f but got %f", name, i, d,
+					buffers.Depth[i])
+				break
+			}
+			if expected.Normals[i].Dist(buffers.Normals[i]) > 1e-8 {
+				t.Errorf("%s: pixel %d: unexpected normal", name, i)
+				break
+			}
+		}
+	}
+}
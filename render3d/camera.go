@@ -2,21 +2,45 @@ package render3d
 
 import (
 	"math"
+	"math/rand"
 
 	"github.com/unixpickle/model3d/model3d"
 )
 
 const DefaultFieldOfView = math.Pi / 2
 
-// A Camera defines a viewer's position, orientation, and
-// field of view for rendering.
+// fitCameraMargin is the fraction of each side of the
+// image which FitCamera leaves empty around an object.
+const fitCameraMargin = 0.05
+
+// A Camera determines the rays of sight which are used to
+// render each pixel of an image.
+type Camera interface {
+	// Rays produces a function that converts image
+	// coordinates into rays of sight.
+	//
+	// Arguments to the resulting function are x and y
+	// values ranging from [0, imageWidth] and
+	// [0, imageHeight], along with a random number
+	// generator for cameras which sample rays randomly,
+	// such as to simulate depth of field.
+	Rays(imageWidth, imageHeight float64) func(gen *rand.Rand, x, y float64) model3d.Ray
+
+	// Depth computes the depth of a point as seen by the
+	// camera, as stored in GeometryBuffers.
+	Depth(c model3d.Coord3D) float64
+}
+
+// A PinholeCamera defines a viewer's position,
+// orientation, and field of view for rendering with a
+// perspective projection.
 //
 // The right-hand rule is used to determine which way the
 // camera is facing, such that if the viewing plane goes
 // from top to bottom and left to right, then the rays of
 // sight go away from the camera's origin.
 // To reverse this, simply use a negative FieldOfView.
-type Camera struct {
+type PinholeCamera struct {
 	// Origin is the location of the camera, from whence
 	// lines if sight originate.
 	Origin model3d.Coord3D
@@ -39,25 +63,18 @@ type Camera struct {
 	FieldOfView float64
 }
 
-// NewCameraAt creates a new Camera that is looking at a
-// point from another point.
+// NewCameraAt creates a new PinholeCamera that is looking
+// at a point from another point.
 //
 // If fov is 0, DefaultFieldOfView is used.
 //
 // The image axes are automatically determined.
-func NewCameraAt(source, dest model3d.Coord3D, fov float64) *Camera {
+func NewCameraAt(source, dest model3d.Coord3D, fov float64) *PinholeCamera {
 	if fov == 0 {
 		fov = DefaultFieldOfView
 	}
-	zAxis := dest.Sub(source).Normalize()
-	xAxis := model3d.Coord3D{X: zAxis.Y, Y: -zAxis.X}
-	if xAxis.Norm() < 1e-5 {
-		// There is no well-defined x-axis.
-		xAxis = model3d.X(1).ProjectOut(zAxis)
-	}
-	xAxis = xAxis.Normalize()
-	yAxis := zAxis.Cross(xAxis)
-	return &Camera{
+	xAxis, yAxis := screenAxes(source, dest)
+	return &PinholeCamera{
 		Origin:      source,
 		ScreenX:     xAxis,
 		ScreenY:     yAxis,
@@ -65,13 +82,30 @@ func NewCameraAt(source, dest model3d.Coord3D, fov float64) *Camera {
 	}
 }
 
+// Rays produces a function that casts rays from the
+// origin, as computed by Caster.
+func (c *PinholeCamera) Rays(imageWidth, imageHeight float64) func(gen *rand.Rand, x,
+	y float64) model3d.Ray {
+	caster := c.Caster(imageWidth, imageHeight)
+	return func(gen *rand.Rand, x, y float64) model3d.Ray {
+		return model3d.Ray{Origin: c.Origin, Direction: caster(x, y)}
+	}
+}
+
+// Depth computes the distance from the plane of the
+// camera to a point, along the viewing direction.
+func (c *PinholeCamera) Depth(coord model3d.Coord3D) float64 {
+	return coord.Sub(c.Origin).Dot(c.forward())
+}
+
 // Caster produces a function that converts image
 // coordinates into directions for rays that emenate from
 // the origin.
 //
 // Arguments to the resulting function are x and y values
 // ranging from [0, imageWidth] and [0, imageHeight].
-func (c *Camera) Caster(imageWidth, imageHeight float64) func(x, y float64) model3d.Coord3D {
+func (c *PinholeCamera) Caster(imageWidth,
+	imageHeight float64) func(x, y float64) model3d.Coord3D {
 	x, y, z := c.axes(imageWidth, imageHeight)
 	cx, cy := imageWidth/2, imageHeight/2
 	return func(imgX, imgY float64) model3d.Coord3D {
@@ -84,8 +118,8 @@ func (c *Camera) Caster(imageWidth, imageHeight float64) func(x, y float64) mode
 // Uncaster produces a function that converts spatial
 // coordinates to screen coordinates using a perspective
 // projection.
-func (c *Camera) Uncaster(imageWidth, imageHeight float64) func(model3d.Coord3D) (float64,
-	float64) {
+func (c *PinholeCamera) Uncaster(imageWidth,
+	imageHeight float64) func(model3d.Coord3D) (float64, float64) {
 	x, y, z := c.axes(imageWidth, imageHeight)
 	invMat := model3d.NewMatrix3Columns(x, y, z).Inverse()
 
@@ -97,7 +131,99 @@ func (c *Camera) Uncaster(imageWidth, imageHeight float64) func(model3d.Coord3D)
 	}
 }
 
-func (c *Camera) axes(imageWidth, imageHeight float64) (x, y, z model3d.Coord3D) {
+// FitCamera creates a camera which looks at an object
+// from the given direction, positioned as close as
+// possible while keeping the object's bounding box fully
+// in view with a small margin.
+//
+// The direction points from the object towards the
+// camera, and needn't be normalized.
+// The camera faces the center of the object's extent, so
+// the object is centered in the image.
+//
+// If fov is 0, DefaultFieldOfView is used.
+func FitCamera(bounds model3d.Bounder, fov float64,
+	direction model3d.Coord3D) *PinholeCamera {
+	return fitCamera(boundsCorners(bounds), fov, direction)
+}
+
+func fitCamera(points []model3d.Coord3D, fov float64,
+	direction model3d.Coord3D) *PinholeCamera {
+	if fov == 0 {
+		fov = DefaultFieldOfView
+	}
+	direction = direction.Normalize()
+	center, baseline := projectedCenter(points, direction)
+	if baseline == 0 {
+		baseline = 1
+	}
+
+	minDist := baseline * 1e-4
+	maxDist := baseline * 1e4
+	for i := 0; i < 32; i++ {
+		d := (minDist + maxDist) / 2
+		cam := NewCameraAt(center.Add(direction.Scale(d)), center, fov)
+		if cameraContains(cam, points) {
+			maxDist = d
+		} else {
+			minDist = d
+		}
+	}
+
+	return NewCameraAt(center.Add(direction.Scale(maxDist)), center, fov)
+}
+
+// projectedCenter finds a point at the center of the
+// points' extent along the plane orthogonal to direction,
+// as well as the diameter of their bounding box.
+func projectedCenter(points []model3d.Coord3D, direction model3d.Coord3D) (model3d.Coord3D,
+	float64) {
+	b1, b2 := direction.OrthoBasis()
+	basis := [3]model3d.Coord3D{b1, b2, direction}
+	var min, max model3d.Coord3D
+	for i, p := range points {
+		proj := model3d.XYZ(p.Dot(basis[0]), p.Dot(basis[1]), p.Dot(basis[2]))
+		if i == 0 {
+			min, max = proj, proj
+		} else {
+			min, max = min.Min(proj), max.Max(proj)
+		}
+	}
+	mid := min.Mid(max)
+	center := basis[0].Scale(mid.X).Add(basis[1].Scale(mid.Y)).Add(basis[2].Scale(mid.Z))
+	return center, min.Dist(max)
+}
+
+func cameraContains(cam *PinholeCamera, points []model3d.Coord3D) bool {
+	uncaster := cam.Uncaster(1, 1)
+	forward := cam.forward()
+	for _, p := range points {
+		if p.Sub(cam.Origin).Dot(forward) <= 0 {
+			return false
+		}
+		sx, sy := uncaster(p)
+		if sx < fitCameraMargin || sy < fitCameraMargin || sx >= 1-fitCameraMargin ||
+			sy >= 1-fitCameraMargin {
+			return false
+		}
+	}
+	return true
+}
+
+func boundsCorners(b model3d.Bounder) []model3d.Coord3D {
+	min, max := b.Min(), b.Max()
+	var res []model3d.Coord3D
+	for _, x := range []float64{min.X, max.X} {
+		for _, y := range []float64{min.Y, max.Y} {
+			for _, z := range []float64{min.Z, max.Z} {
+				res = append(res, model3d.XYZ(x, y, z))
+			}
+		}
+	}
+	return res
+}
+
+func (c *PinholeCamera) axes(imageWidth, imageHeight float64) (x, y, z model3d.Coord3D) {
 	planeDistance := 1 / math.Tan(c.FieldOfView/2)
 
 	x, y = c.ScreenX, c.ScreenY
@@ -111,3 +237,21 @@ func (c *Camera) axes(imageWidth, imageHeight float64) (x, y, z model3d.Coord3D)
 
 	return
 }
+
+func (c *PinholeCamera) forward() model3d.Coord3D {
+	return c.ScreenX.Cross(c.ScreenY).Normalize()
+}
+
+// screenAxes computes the image axes for a camera looking
+// at a point from another point.
+func screenAxes(source, dest model3d.Coord3D) (xAxis, yAxis model3d.Coord3D) {
+	zAxis := dest.Sub(source).Normalize()
+	xAxis = model3d.Coord3D{X: zAxis.Y, Y: -zAxis.X}
+	if xAxis.Norm() < 1e-5 {
+		// There is no well-defined x-axis.
+		xAxis = model3d.X(1).ProjectOut(zAxis)
+	}
+	xAxis = xAxis.Normalize()
+	yAxis = zAxis.Cross(xAxis)
+	return
+}
@@ -0,0 +1,49 @@
+package render3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestFitCamera(t *testing.T) {
+	t.Run("Bounds", func(t *testing.T) {
+		box := &model3d.Rect{MinVal: model3d.XYZ(1, 2, 3), MaxVal: model3d.XYZ(2, 5, 4)}
+		for i := 0; i < 10; i++ {
+			cam := FitCamera(box, math.Pi/3, model3d.NewCoord3DRandNorm())
+			testFitCameraPoints(t, cam, boundsCorners(box))
+		}
+	})
+
+	t.Run("Vertices", func(t *testing.T) {
+		// A long, thin diagonal rod is much smaller than its
+		// bounding box from most angles.
+		mesh := model3d.NewMeshCylinder(model3d.XYZ(0, 0, 0), model3d.XYZ(5, 5, 5), 0.1, 16)
+		points := framingPoints(mesh, Objectify(mesh, nil))
+		for i := 0; i < 10; i++ {
+			cam := fitCamera(points, helperFieldOfView, model3d.NewCoord3DRandUnit())
+			testFitCameraPoints(t, cam, points)
+		}
+	})
+}
+
+func testFitCameraPoints(t *testing.T, cam *PinholeCamera, points []model3d.Coord3D) {
+	if !cameraContains(cam, points) {
+		t.Fatal("camera does not contain all points")
+	}
+
+	// The object should nearly touch the margin on at
+	// least one side.
+	uncaster := cam.Uncaster(1, 1)
+	minMargin := math.Inf(1)
+	for _, p := range points {
+		sx, sy := uncaster(p)
+		for _, m := range []float64{sx, sy, 1 - sx, 1 - sy} {
+			minMargin = math.Min(minMargin, m)
+		}
+	}
+	if math.Abs(minMargin-fitCameraMargin) > 1e-3 {
+		t.Errorf("unexpected minimum margin: %f", minMargin)
+	}
+}
@@ -0,0 +1,309 @@
+package render3d
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+)
+
+const (
+	DefaultImageTolerance = 0.05
+
+	ssimSigma  = 1.5
+	ssimRadius = 5
+	ssimC1     = 0.01 * 0.01
+	ssimC2     = 0.03 * 0.03
+)
+
+// An ImageComparer checks if two images look alike, for
+// example when comparing a new rendering to a reference
+// rendering in a regression test.
+//
+// Images are compared in sRGB space, where all values are
+// in the range [0, 1].
+// Since renderings are often noisy, it is typically best
+// to allow a small fraction of mismatched pixels.
+type ImageComparer struct {
+	// Tolerance is the largest difference of any color
+	// channel for which two pixels are considered equal.
+	//
+	// If 0, DefaultImageTolerance is used.
+	Tolerance float64
+
+	// MaxMismatch is the largest fraction of pixels which
+	// may differ by more than Tolerance.
+	MaxMismatch float64
+
+	// MinSSIM, if non-zero, is the minimum structural
+	// similarity index for the images to match.
+	MinSSIM float64
+
+	// MaxShift is the largest offset, in pixels, that the
+	// images may be translated by to align them.
+	//
+	// Allowing small shifts makes comparisons robust to
+	// tiny changes in the camera or object placement.
+	MaxShift int
+}
+
+// An ImageDiff summarizes the differences between two
+// images.
+type ImageDiff struct {
+	// ShiftX and ShiftY are the offset of the first image
+	// relative to the second, chosen to best align them.
+	ShiftX int
+	ShiftY int
+
+	// MeanError is the mean absolute difference over all
+	// color channels of all overlapping pixels.
+	MeanError float64
+
+	// MaxError is the largest absolute difference over all
+	// color channels.
+	MaxError float64
+
+	// Mismatch is the fraction of pixels which differ by
+	// more than the tolerance.
+	Mismatch float64
+
+	// SSIM is the structural similarity index of the
+	// luminance of the images, where 1 means identical.
+	SSIM float64
+
+	// Image contains the absolute difference at every
+	// overlapping pixel, which can be saved to visually
+	// inspect the differences.
+	Image *Image
+}
+
+// Compare computes the differences between an image and
+// a reference image of the same size.
+func (i *ImageComparer) Compare(actual, expected *Image) (*ImageDiff, error) {
+	if actual.Width != expected.Width || actual.Height != expected.Height {
+		return nil, fmt.Errorf("compare images: size %dx%d does not match %dx%d",
+			actual.Width, actual.Height, expected.Width, expected.Height)
+	}
+	a, e := srgbChannels(actual), srgbChannels(expected)
+	w, h := actual.Width, actual.Height
+
+	maxShift := essentials.MaxInt(0, essentials.MinInt(i.MaxShift, w-1, h-1))
+	var bestX, bestY int
+	bestErr := math.Inf(1)
+	for dy := -maxShift; dy <= maxShift; dy++ {
+		for dx := -maxShift; dx <= maxShift; dx++ {
+			meanErr := shiftedMeanError(a, e, w, h, dx, dy)
+			if meanErr < bestErr || (meanErr == bestErr && shiftSize(dx, dy) < shiftSize(bestX, bestY)) {
+				bestErr = meanErr
+				bestX, bestY = dx, dy
+			}
+		}
+	}
+
+	tol := i.Tolerance
+	if tol == 0 {
+		tol = DefaultImageTolerance
+	}
+	overlapW, overlapH := w-essentials.AbsInt(bestX), h-essentials.AbsInt(bestY)
+	res := &ImageDiff{
+		ShiftX: bestX,
+		ShiftY: bestY,
+		Image:  NewImage(overlapW, overlapH),
+	}
+	aLuma := make([]float64, overlapW*overlapH)
+	eLuma := make([]float64, overlapW*overlapH)
+	var numBad int
+	for y := 0; y < overlapH; y++ {
+		for x := 0; x < overlapW; x++ {
+			ac := a[shiftedIndex(w, x, y, bestX, bestY, true)]
+			ec := e[shiftedIndex(w, x, y, bestX, bestY, false)]
+			diff := colorAbsDiff(ac, ec)
+			maxDiff := math.Max(math.Max(diff.X, diff.Y), diff.Z)
+			res.MeanError += diff.X + diff.Y + diff.Z
+			res.MaxError = math.Max(res.MaxError, maxDiff)
+			if maxDiff > tol {
+				numBad++
+			}
+			idx := y*overlapW + x
+			res.Image.Data[idx] = diff
+			aLuma[idx] = srgbLuma(ac)
+			eLuma[idx] = srgbLuma(ec)
+		}
+	}
+	numPixels := float64(overlapW * overlapH)
+	res.MeanError /= numPixels * 3
+	res.Mismatch = float64(numBad) / numPixels
+	res.SSIM = ssim(aLuma, eLuma, overlapW, overlapH)
+	return res, nil
+}
+
+// Match checks that an image matches a reference image,
+// returning a descriptive error if it does not.
+func (i *ImageComparer) Match(actual, expected *Image) error {
+	diff, err := i.Compare(actual, expected)
+	if err != nil {
+		return err
+	}
+	if diff.Mismatch > i.MaxMismatch {
+		return fmt.Errorf("match images: %.2f%% of pixels differ (max error %f, shift %d,%d)",
+			diff.Mismatch*100, diff.MaxError, diff.ShiftX, diff.ShiftY)
+	}
+	if i.MinSSIM != 0 && diff.SSIM < i.MinSSIM {
+		return fmt.Errorf("match images: SSIM %f is below %f", diff.SSIM, i.MinSSIM)
+	}
+	return nil
+}
+
+// MatchFile is like Match, except that it loads the
+// reference image from a file.
+func (i *ImageComparer) MatchFile(actual *Image, expectedPath string) error {
+	expected, err := LoadImage(expectedPath)
+	if err != nil {
+		return errors.Wrap(err, "match images")
+	}
+	return i.Match(actual, expected)
+}
+
+// SSIM computes the structural similarity index between
+// the luminance of two images of the same size.
+//
+// The result is 1 for identical images, and decreases
+// towards 0 (or below) as the images become less similar.
+func (i *Image) SSIM(i1 *Image) float64 {
+	if i.Width != i1.Width || i.Height != i1.Height {
+		panic("image sizes do not match")
+	}
+	luma1 := make([]float64, len(i.Data))
+	luma2 := make([]float64, len(i.Data))
+	for j, c := range srgbChannels(i) {
+		luma1[j] = srgbLuma(c)
+	}
+	for j, c := range srgbChannels(i1) {
+		luma2[j] = srgbLuma(c)
+	}
+	return ssim(luma1, luma2, i.Width, i.Height)
+}
+
+func ssim(x, y []float64, w, h int) float64 {
+	if len(x) == 0 {
+		return 1
+	}
+	xx := make([]float64, len(x))
+	yy := make([]float64, len(x))
+	xy := make([]float64, len(x))
+	for i := range x {
+		xx[i] = x[i] * x[i]
+		yy[i] = y[i] * y[i]
+		xy[i] = x[i] * y[i]
+	}
+	kernel := ssimKernel()
+	muX := gaussianBlur(x, w, h, kernel)
+	muY := gaussianBlur(y, w, h, kernel)
+	sigmaXX := gaussianBlur(xx, w, h, kernel)
+	sigmaYY := gaussianBlur(yy, w, h, kernel)
+	sigmaXY := gaussianBlur(xy, w, h, kernel)
+
+	var sum float64
+	for i := range x {
+		mx, my := muX[i], muY[i]
+		vx := sigmaXX[i] - mx*mx
+		vy := sigmaYY[i] - my*my
+		cov := sigmaXY[i] - mx*my
+		num := (2*mx*my + ssimC1) * (2*cov + ssimC2)
+		denom := (mx*mx + my*my + ssimC1) * (vx + vy + ssimC2)
+		sum += num / denom
+	}
+	return sum / float64(len(x))
+}
+
+func ssimKernel() []float64 {
+	kernel := make([]float64, ssimRadius*2+1)
+	for i := range kernel {
+		d := float64(i - ssimRadius)
+		kernel[i] = math.Exp(-d * d / (2 * ssimSigma * ssimSigma))
+	}
+	return kernel
+}
+
+// gaussianBlur applies a separable blur to a grid of
+// values, renormalizing the kernel near the edges.
+func gaussianBlur(values []float64, w, h int, kernel []float64) []float64 {
+	radius := len(kernel) / 2
+	blurAxis := func(src []float64, dx, dy int) []float64 {
+		dst := make([]float64, len(src))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				var sum, weight float64
+				for k, kv := range kernel {
+					x1, y1 := x+(k-radius)*dx, y+(k-radius)*dy
+					if x1 < 0 || y1 < 0 || x1 >= w || y1 >= h {
+						continue
+					}
+					sum += src[y1*w+x1] * kv
+					weight += kv
+				}
+				dst[y*w+x] = sum / weight
+			}
+		}
+		return dst
+	}
+	return blurAxis(blurAxis(values, 1, 0), 0, 1)
+}
+
+func shiftedMeanError(a, e []Color, w, h, dx, dy int) float64 {
+	overlapW, overlapH := w-essentials.AbsInt(dx), h-essentials.AbsInt(dy)
+	var sum float64
+	for y := 0; y < overlapH; y++ {
+		for x := 0; x < overlapW; x++ {
+			ac := a[shiftedIndex(w, x, y, dx, dy, true)]
+			ec := e[shiftedIndex(w, x, y, dx, dy, false)]
+			sum += colorAbsDiff(ac, ec).Sum()
+		}
+	}
+	return sum / float64(overlapW*overlapH)
+}
+
+// shiftedIndex gets the index of a pixel in the overlap
+// between two images, where the first image is offset by
+// (dx, dy) relative to the second.
+func shiftedIndex(w, x, y, dx, dy int, first bool) int {
+	if first {
+		if dx > 0 {
+			x += dx
+		}
+		if dy > 0 {
+			y += dy
+		}
+	} else {
+		if dx < 0 {
+			x -= dx
+		}
+		if dy < 0 {
+			y -= dy
+		}
+	}
+	return y*w + x
+}
+
+func srgbChannels(img *Image) []Color {
+	res := make([]Color, len(img.Data))
+	for i, c := range img.Data {
+		r, g, b := RGB(ClampColor(c))
+		res[i] = Color{X: r, Y: g, Z: b}
+	}
+	return res
+}
+
+func colorAbsDiff(c1, c2 Color) Color {
+	diff := c1.Sub(c2)
+	return diff.Max(diff.Scale(-1))
+}
+
+func srgbLuma(c Color) float64 {
+	return 0.299*c.X + 0.587*c.Y + 0.114*c.Z
+}
+
+func shiftSize(dx, dy int) int {
+	return essentials.AbsInt(dx) + essentials.AbsInt(dy)
+}
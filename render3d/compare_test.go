@@ -0,0 +1,112 @@
+package render3d
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestImageComparer(t *testing.T) {
+	img := RenderView(&model3d.Sphere{Radius: 1}, model3d.XYZ(2, -3, 1), 40, 40, nil)
+
+	cmp := &ImageComparer{MaxShift: 2}
+	diff, err := cmp.Compare(img, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.ShiftX != 0 || diff.ShiftY != 0 || diff.MaxError != 0 || diff.Mismatch != 0 {
+		t.Errorf("unexpected diff for identical images: %+v", diff)
+	}
+	if math.Abs(diff.SSIM-1) > 1e-8 {
+		t.Errorf("unexpected SSIM: %f", diff.SSIM)
+	}
+
+	// A translated image should be aligned.
+	shifted := NewImage(img.Width, img.Height)
+	shifted.CopyFrom(img, 2, 1)
+	diff, err = cmp.Compare(shifted, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.ShiftX != 2 || diff.ShiftY != 1 || diff.Mismatch != 0 {
+		t.Errorf("unexpected diff for shifted image: %+v", diff)
+	}
+	if err := (&ImageComparer{}).Match(shifted, img); err == nil {
+		t.Error("expected mismatch without alignment")
+	}
+	if err := cmp.Match(shifted, img); err != nil {
+		t.Error(err)
+	}
+
+	// A different object should not match.
+	other := RenderView(&model3d.Rect{MinVal: model3d.XYZ(-1, -1, -1),
+		MaxVal: model3d.XYZ(1, 1, 1)}, model3d.XYZ(2, -3, 1), 40, 40, nil)
+	diff, err = cmp.Compare(other, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Mismatch < 0.05 || diff.SSIM > 0.9 {
+		t.Errorf("unexpected diff for different images: %+v", diff)
+	}
+
+	if _, err := cmp.Compare(img.Downsample(2), img); err == nil {
+		t.Error("expected error for mismatched sizes")
+	}
+}
+
+func TestImageSSIMNoise(t *testing.T) {
+	img := NewImage(32, 32)
+	for y := 0; y < img.Height; y++ {
+		for x := 0; x < img.Width; x++ {
+			img.Data[y*img.Width+x] = NewColor(float64(x) / float64(img.Width))
+		}
+	}
+	var prevSSIM float64 = 1
+	for _, noise := range []float64{0.01, 0.05, 0.2} {
+		noisy := NewImage(img.Width, img.Height)
+		for i, c := range img.Data {
+			// Deterministic pseudo-noise.
+			n := math.Sin(float64(i)*12.9898) * noise
+			noisy.Data[i] = c.Add(NewColor(n))
+		}
+		ssim := noisy.SSIM(img)
+		if ssim >= prevSSIM {
+			t.Errorf("SSIM should decrease with noise: %f >= %f", ssim, prevSSIM)
+		}
+		prevSSIM = ssim
+	}
+}
+
+func TestImageComparerMatchFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "render3d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	img := RenderView(&model3d.Sphere{Radius: 1}, model3d.XYZ(2, -3, 1), 30, 20, nil)
+	path := filepath.Join(dir, "golden.png")
+	if err := img.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Width != img.Width || loaded.Height != img.Height {
+		t.Fatalf("unexpected size: %dx%d", loaded.Width, loaded.Height)
+	}
+
+	// PNG quantization should be well within tolerance.
+	cmp := &ImageComparer{Tolerance: 0.01, MinSSIM: 0.99}
+	if err := cmp.MatchFile(img, path); err != nil {
+		t.Error(err)
+	}
+	if err := cmp.MatchFile(img, filepath.Join(dir, "missing.png")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,261 @@
+package render3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// denoiseMinAlbedo is the smallest albedo which is divided
+// out of a pixel's color before denoising.
+const denoiseMinAlbedo = 1e-3
+
+const (
+	DefaultBilateralDenoiserSpatialSigma  = 2.0
+	DefaultBilateralDenoiserNormalSigma   = 0.05
+	DefaultBilateralDenoiserDepthSigma    = 0.1
+	DefaultBilateralDenoiserAlbedoSigma   = 0.1
+	DefaultBilateralDenoiserVarianceScale = 4.0
+)
+
+// A Denoiser removes sampling noise from a rendered image.
+//
+// Denoisers may use the geometry buffers of the rendering
+// to avoid blurring across edges, and the variance buffer
+// to decide how much each pixel should be smoothed.
+// The buffers have the same size as the image.
+//
+// A Denoiser should not modify its arguments.
+type Denoiser interface {
+	Denoise(img *Image, buffers *GeometryBuffers) *Image
+}
+
+// A BilateralDenoiser is a Denoiser which averages each
+// pixel with nearby pixels that have a similar depth,
+// normal, and albedo.
+//
+// Lighting is filtered separately from surface colors, by
+// dividing out the albedo before filtering, so textures
+// are not blurred.
+//
+// If the buffers contain variance information, pixels with
+// colors that differ by more than their noise can explain
+// are averaged less, which preserves sharp shadows and
+// highlights.
+type BilateralDenoiser struct {
+	// SpatialSigma is the standard deviation, in pixels,
+	// of the spatial filter.
+	//
+	// If 0, DefaultBilateralDenoiserSpatialSigma is used.
+	SpatialSigma float64
+
+	// NormalSigma controls how quickly the weight of a
+	// neighboring pixel decreases as its normal differs,
+	// in terms of one minus the cosine between normals.
+	//
+	// If 0, DefaultBilateralDenoiserNormalSigma is used.
+	NormalSigma float64
+
+	// DepthSigma is the standard deviation of the relative
+	// difference in depth per pixel of distance between
+	// neighboring pixels.
+	//
+	// If 0, DefaultBilateralDenoiserDepthSigma is used.
+	DepthSigma float64
+
+	// AlbedoSigma is the standard deviation of the
+	// difference in albedo between neighboring pixels.
+	//
+	// If 0, DefaultBilateralDenoiserAlbedoSigma is used.
+	AlbedoSigma float64
+
+	// VarianceScale scales the variance of two pixels to
+	// determine how different their colors can be while
+	// still being averaged.
+	//
+	// If 0, DefaultBilateralDenoiserVarianceScale is used.
+	VarianceScale float64
+}
+
+// Denoise filters the image.
+//
+// If buffers is nil, the image is only filtered spatially,
+// which blurs edges.
+func (b *BilateralDenoiser) Denoise(img *Image, buffers *GeometryBuffers) *Image {
+	if buffers != nil && (buffers.Width != img.Width || buffers.Height != img.Height) {
+		panic("buffers do not match image size")
+	}
+	spatialSigma := b.spatialSigma()
+	radius := int(math.Ceil(spatialSigma * 2.5))
+
+	// Divide out the albedo so that only the lighting is
+	// filtered.
+	demod := make([]Color, len(img.Data))
+	factors := make([]Color, len(img.Data))
+	for i, c := range img.Data {
+		factor := [3]float64{1, 1, 1}
+		if buffers != nil {
+			for j, a := range buffers.Albedo[i].Array() {
+				if a > denoiseMinAlbedo {
+					factor[j] = a
+				}
+			}
+		}
+		factors[i] = model3d.NewCoord3DArray(factor)
+		demod[i] = c.Div(factors[i])
+	}
+
+	// The variance of the demodulated colors, blurred
+	// slightly since it is estimated from few samples.
+	var variance []float64
+	if buffers != nil {
+		variance = denoiseVariance(buffers, factors)
+	}
+
+	res := NewImage(img.Width, img.Height)
+	mapCoordinates(img.Width, img.Height, func(_ *goInfo, x, y, idx int) {
+		var sum Color
+		var weightSum float64
+		for dy := -radius; dy <= radius; dy++ {
+			y1 := y + dy
+			if y1 < 0 || y1 >= img.Height {
+				continue
+			}
+			for dx := -radius; dx <= radius; dx++ {
+				x1 := x + dx
+				if x1 < 0 || x1 >= img.Width {
+					continue
+				}
+				idx1 := y1*img.Width + x1
+				dist := math.Sqrt(float64(dx*dx + dy*dy))
+				logWeight := -dist * dist / (2 * spatialSigma * spatialSigma)
+				if buffers != nil && idx1 != idx {
+					lw, ok := b.guideLogWeight(buffers, demod, variance, idx, idx1, dist)
+					if !ok {
+						continue
+					}
+					logWeight += lw
+				}
+				weight := math.Exp(logWeight)
+				sum = sum.Add(demod[idx1].Scale(weight))
+				weightSum += weight
+			}
+		}
+		res.Data[idx] = sum.Scale(1 / weightSum).Mul(factors[idx])
+	})
+	return res
+}
+
+// guideLogWeight computes the log of the weight of pixel
+// idx1 when filtering pixel idx, excluding the spatial
+// term.
+//
+// If the pixels should not be mixed at all, false is
+// returned.
+func (b *BilateralDenoiser) guideLogWeight(buffers *GeometryBuffers, demod []Color,
+	variance []float64, idx, idx1 int, dist float64) (float64, bool) {
+	d, d1 := buffers.Depth[idx], buffers.Depth[idx1]
+	if math.IsInf(d, 1) != math.IsInf(d1, 1) {
+		return 0, false
+	}
+
+	var logWeight float64
+	if !math.IsInf(d, 1) {
+		relDepth := math.Abs(d-d1) / (math.Max(d, 1e-8) * dist)
+		depthSigma := b.depthSigma()
+		logWeight -= relDepth * relDepth / (2 * depthSigma * depthSigma)
+
+		normalDiff := 1 - buffers.Normals[idx].Dot(buffers.Normals[idx1])
+		logWeight -= math.Max(0, normalDiff) / b.normalSigma()
+
+		albedoDist := buffers.Albedo[idx].SquaredDist(buffers.Albedo[idx1])
+		albedoSigma := b.albedoSigma()
+		logWeight -= albedoDist / (2 * albedoSigma * albedoSigma)
+	}
+
+	// Only the variance of the center pixel is used, so
+	// that outliers in noisy neighbors do not spread into
+	// well-converged pixels.
+	if variance != nil {
+		colorDist := demod[idx].SquaredDist(demod[idx1])
+		if variance[idx] == 0 {
+			if colorDist > 0 {
+				return 0, false
+			}
+		} else {
+			logWeight -= colorDist / (b.varianceScale() * variance[idx])
+		}
+	}
+
+	return logWeight, true
+}
+
+// denoiseVariance computes the total variance of each
+// pixel's demodulated color, averaged over a 3x3 window.
+//
+// If the buffers contain no variance, nil is returned.
+func denoiseVariance(buffers *GeometryBuffers, factors []Color) []float64 {
+	raw := make([]float64, len(buffers.Variance))
+	var hasVariance bool
+	for i, v := range buffers.Variance {
+		raw[i] = v.Div(factors[i].Mul(factors[i])).Sum()
+		if raw[i] > 0 {
+			hasVariance = true
+		}
+	}
+	if !hasVariance {
+		return nil
+	}
+	res := make([]float64, len(raw))
+	w, h := buffers.Width, buffers.Height
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum float64
+			var count int
+			for y1 := essentials.MaxInt(0, y-1); y1 <= essentials.MinInt(h-1, y+1); y1++ {
+				for x1 := essentials.MaxInt(0, x-1); x1 <= essentials.MinInt(w-1, x+1); x1++ {
+					sum += raw[y1*w+x1]
+					count++
+				}
+			}
+			res[y*w+x] = sum / float64(count)
+		}
+	}
+	return res
+}
+
+func (b *BilateralDenoiser) spatialSigma() float64 {
+	if b.SpatialSigma == 0 {
+		return DefaultBilateralDenoiserSpatialSigma
+	}
+	return b.SpatialSigma
+}
+
+func (b *BilateralDenoiser) normalSigma() float64 {
+	if b.NormalSigma == 0 {
+		return DefaultBilateralDenoiserNormalSigma
+	}
+	return b.NormalSigma
+}
+
+func (b *BilateralDenoiser) depthSigma() float64 {
+	if b.DepthSigma == 0 {
+		return DefaultBilateralDenoiserDepthSigma
+	}
+	return b.DepthSigma
+}
+
+func (b *BilateralDenoiser) albedoSigma() float64 {
+	if b.AlbedoSigma == 0 {
+		return DefaultBilateralDenoiserAlbedoSigma
+	}
+	return b.AlbedoSigma
+}
+
+func (b *BilateralDenoiser) varianceScale() float64 {
+	if b.VarianceScale == 0 {
+		return DefaultBilateralDenoiserVarianceScale
+	}
+	return b.VarianceScale
+}
@@ -0,0 +1,137 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestBilateralDenoiserNoise(t *testing.T) {
+	const size = 32
+	expected := NewImage(size, size)
+	noisy := NewImage(size, size)
+	buffers := NewGeometryBuffers(size, size)
+	for i := range noisy.Data {
+		x := i % size
+		// The albedo has a sharp edge, which should not be
+		// blurred by the denoiser.
+		albedo := NewColorRGB(0.8, 0.2, 0.2)
+		if x >= size/2 {
+			albedo = NewColorRGB(0.2, 0.2, 0.8)
+		}
+		expected.Data[i] = albedo.Scale(0.5)
+		noise := 1 + 0.5*rand.NormFloat64()
+		noisy.Data[i] = expected.Data[i].Scale(noise)
+
+		buffers.Depth[i] = 3
+		buffers.Normals[i] = model3d.Z(1)
+		buffers.Albedo[i] = albedo
+		buffers.Variance[i] = expected.Data[i].Mul(expected.Data[i]).Scale(0.25)
+	}
+
+	denoised := (&BilateralDenoiser{}).Denoise(noisy, buffers)
+	noisyErr := imageMSE(noisy, expected)
+	denoisedErr := imageMSE(denoised, expected)
+	if denoisedErr > noisyErr/3 {
+		t.Errorf("denoising reduced MSE from %f to only %f", noisyErr, denoisedErr)
+	}
+	for _, x := range []int{size/2 - 1, size / 2} {
+		var actualMean, expectedMean Color
+		for y := 0; y < size; y++ {
+			idx := y*size + x
+			actualMean = actualMean.Add(denoised.Data[idx].Scale(1.0 / size))
+			expectedMean = expectedMean.Add(expected.Data[idx].Scale(1.0 / size))
+		}
+		if actualMean.Dist(expectedMean) > 0.05 {
+			t.Errorf("edge was not preserved at column %d: expected %v but got %v", x,
+				expectedMean, actualMean)
+		}
+	}
+}
+
+func TestBilateralDenoiserEdges(t *testing.T) {
+	// Without any noise, an image with distinct surfaces
+	// should be left nearly unchanged.
+	camera := NewCameraAt(model3d.XYZ(2, -4, 3), model3d.Coord3D{}, math.Pi/3)
+	obj := &ColliderObject{
+		Collider: model3d.NewRect(model3d.XYZ(-1, -1, -1), model3d.XYZ(1, 1, 1)),
+		Material: &LambertMaterial{DiffuseColor: NewColor(0.8)},
+	}
+	caster := &RayCaster{
+		Camera:  camera,
+		Lights:  []*PointLight{{Origin: model3d.XYZ(3, -5, 10), Color: NewColor(3)}},
+		Buffers: NewGeometryBuffers(1, 1),
+	}
+	img := NewImage(48, 48)
+	caster.Render(img, obj)
+	denoised := (&BilateralDenoiser{}).Denoise(img, caster.Buffers)
+	if err := imageMSE(denoised, img); err > 1e-4 {
+		t.Errorf("unexpected MSE: %f", err)
+	}
+}
+
+func TestRendererDenoiser(t *testing.T) {
+	camera := NewCameraAt(model3d.XYZ(0, -4, 2), model3d.Coord3D{}, math.Pi/3)
+	obj := JoinedObject{
+		&ColliderObject{
+			Collider: &model3d.Sphere{Radius: 1},
+			Material: &LambertMaterial{DiffuseColor: NewColor(0.7)},
+		},
+		&ColliderObject{
+			Collider: model3d.NewRect(model3d.XYZ(-5, -5, -2), model3d.XYZ(5, 5, -1)),
+			Material: &LambertMaterial{DiffuseColor: NewColorRGB(0.2, 0.6, 0.2)},
+		},
+		&ColliderObject{
+			Collider: &model3d.Sphere{Center: model3d.XYZ(2, -2, 4), Radius: 1},
+			Material: &LambertMaterial{EmissionColor: NewColor(5)},
+		},
+	}
+	newTracer := func(samples int, denoiser Denoiser) *RecursiveRayTracer {
+		return &RecursiveRayTracer{
+			Camera:     camera,
+			MaxDepth:   3,
+			NumSamples: samples,
+			Antialias:  1,
+			Buffers:    NewGeometryBuffers(1, 1),
+			Denoiser:   denoiser,
+		}
+	}
+
+	reference := NewImage(32, 32)
+	newTracer(400, nil).Render(reference, obj)
+
+	tracer := newTracer(4, nil)
+	noisy := NewImage(32, 32)
+	tracer.Render(noisy, obj)
+
+	var totalVariance float64
+	for _, v := range tracer.Buffers.Variance {
+		totalVariance += v.Sum()
+	}
+	if totalVariance == 0 {
+		t.Error("no variance was recorded")
+	}
+
+	floorIdx := 31*32 + 16
+	if a := tracer.Buffers.Albedo[floorIdx]; a.Dist(NewColorRGB(0.2, 0.6, 0.2)) > 1e-8 {
+		t.Errorf("unexpected floor albedo: %v", a)
+	}
+
+	denoised := NewImage(32, 32)
+	newTracer(4, &BilateralDenoiser{}).Render(denoised, obj)
+	noisyErr := imageMSE(noisy, reference)
+	denoisedErr := imageMSE(denoised, reference)
+	if denoisedErr >= noisyErr {
+		t.Errorf("denoising increased MSE from %f to %f", noisyErr, denoisedErr)
+	}
+}
+
+func imageMSE(actual, expected *Image) float64 {
+	var sum float64
+	for i, c := range actual.Data {
+		sum += c.SquaredDist(expected.Data[i])
+	}
+	return sum / float64(3*len(actual.Data))
+}
@@ -0,0 +1,176 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// An EnvironmentLight is an infinitely distant light which
+// surrounds the scene, with radiance given by an
+// equirectangular image, such as an HDR panorama.
+//
+// The image is laid out like a toolbox3d.Equirect: the top
+// row is the north pole (the direction +Y, as in
+// model3d.GeoCoord), and the columns span longitudes from
+// -pi to pi.
+//
+// Directions are sampled in proportion to the brightness
+// of the image, so that small, bright regions like the sun
+// can be found efficiently by renderers.
+type EnvironmentLight struct {
+	// Rotation, if non-nil, maps directions in the scene
+	// to directions in the image's coordinate system.
+	//
+	// For example, a rotation which maps +Z to +Y makes
+	// the top of the image appear above scenes which use
+	// +Z as the up direction.
+	Rotation *model3d.Matrix3
+
+	// Scale, if non-zero, is multiplied by the radiance of
+	// every pixel of the image.
+	Scale float64
+
+	image      *Image
+	cumWeights []float64
+	total      float64
+}
+
+// NewEnvironmentLight creates an EnvironmentLight from a
+// linear (not gamma corrected) equirectangular image.
+//
+// The image should not be modified after it is passed to
+// this function.
+func NewEnvironmentLight(img *Image) *EnvironmentLight {
+	res := &EnvironmentLight{
+		image:      img,
+		cumWeights: make([]float64, len(img.Data)),
+	}
+	for y := 0; y < img.Height; y++ {
+		// Rows near the poles cover less solid angle.
+		rowScale := res.rowArea(y)
+		for x := 0; x < img.Width; x++ {
+			idx := y*img.Width + x
+			res.total += img.Data[idx].Sum() * rowScale
+			res.cumWeights[idx] = res.total
+		}
+	}
+	return res
+}
+
+// LoadEnvironmentLight creates an EnvironmentLight from an
+// image file, such as an .hdr file.
+//
+// See LoadImage for supported formats.
+func LoadEnvironmentLight(path string) (*EnvironmentLight, error) {
+	img, err := LoadImage(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewEnvironmentLight(img), nil
+}
+
+// Radiance gets the light arriving from the given
+// direction, which need not be normalized.
+func (e *EnvironmentLight) Radiance(direction model3d.Coord3D) Color {
+	x, y := e.pixel(direction)
+	return e.image.Data[y*e.image.Width+x].Scale(e.scale())
+}
+
+// SampleDirection samples a direction toward the light,
+// along with the radiance from that direction and the
+// density of the sample.
+//
+// The density is relative to the uniform distribution on
+// the sphere, like Material.SourceDensity.
+//
+// If the image is black, the density is zero and the
+// direction should not be used.
+func (e *EnvironmentLight) SampleDirection(gen *rand.Rand) (direction model3d.Coord3D,
+	radiance Color, density float64) {
+	if e.total == 0 {
+		return model3d.X(1), Color{}, 0
+	}
+	idx := sort.SearchFloat64s(e.cumWeights, gen.Float64()*e.total)
+	if idx == len(e.cumWeights) {
+		idx--
+	}
+	// A sample of exactly zero may land on black pixels.
+	for idx == 0 && e.cumWeights[idx] == 0 || idx > 0 && e.cumWeights[idx] == e.cumWeights[idx-1] {
+		idx++
+	}
+	x, y := idx%e.image.Width, idx/e.image.Width
+	// Sample uniformly by solid angle within the pixel.
+	u := (float64(x) + gen.Float64()) / float64(e.image.Width)
+	minSin, maxSin := e.rowSines(y)
+	geo := model3d.GeoCoord{
+		Lat: math.Asin(minSin + gen.Float64()*(maxSin-minSin)),
+		Lon: 2*math.Pi*u - math.Pi,
+	}
+	direction = geo.Coord3D()
+	if e.Rotation != nil {
+		direction = e.Rotation.Transpose().MulColumn(direction)
+	}
+	return direction, e.Radiance(direction), e.Density(direction)
+}
+
+// Density computes the density of SampleDirection for
+// the given direction.
+func (e *EnvironmentLight) Density(direction model3d.Coord3D) float64 {
+	if e.total == 0 {
+		return 0
+	}
+	x, y := e.pixel(direction)
+
+	// The pixel is chosen with probability
+	// brightness*rowArea/total, and covers a fraction
+	// rowArea/(2*width) of the sphere.
+	brightness := e.image.Data[y*e.image.Width+x].Sum()
+	return brightness * 2 * float64(e.image.Width) / e.total
+}
+
+// rowSines gets the sines of the minimum and maximum
+// latitude of a row of the image.
+func (e *EnvironmentLight) rowSines(y int) (float64, float64) {
+	maxLat := math.Pi/2 - math.Pi*float64(y)/float64(e.image.Height)
+	minLat := math.Pi/2 - math.Pi*float64(y+1)/float64(e.image.Height)
+	return math.Sin(minLat), math.Sin(maxLat)
+}
+
+// rowArea gets the solid angle covered by a row of the
+// image, divided by 2*pi.
+func (e *EnvironmentLight) rowArea(y int) float64 {
+	minSin, maxSin := e.rowSines(y)
+	return maxSin - minSin
+}
+
+func (e *EnvironmentLight) pixel(direction model3d.Coord3D) (int, int) {
+	if e.Rotation != nil {
+		direction = e.Rotation.MulColumn(direction)
+	}
+	geo := direction.Geo()
+	u := (geo.Lon + math.Pi) / (2 * math.Pi)
+	v := (math.Pi/2 - geo.Lat) / math.Pi
+	x := int(u * float64(e.image.Width))
+	y := int(v * float64(e.image.Height))
+	if x < 0 {
+		x = 0
+	} else if x >= e.image.Width {
+		x = e.image.Width - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= e.image.Height {
+		y = e.image.Height - 1
+	}
+	return x, y
+}
+
+func (e *EnvironmentLight) scale() float64 {
+	if e.Scale == 0 {
+		return 1
+	}
+	return e.Scale
+}
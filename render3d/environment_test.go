@@ -0,0 +1,162 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestEnvironmentLightDensity(t *testing.T) {
+	img := NewImage(16, 8)
+	for i := range img.Data {
+		img.Data[i] = NewColor(0.01 * float64(i%5))
+	}
+	// A small, bright sun.
+	img.Data[2*16+11] = NewColor(50)
+	light := NewEnvironmentLight(img)
+	light.Rotation = model3d.NewMatrix3Rotation(model3d.X(1), 0.3)
+
+	gen := rand.New(rand.NewSource(1))
+
+	// The density should integrate to 1 over the sphere.
+	var meanDensity float64
+	const numUniform = 200000
+	for i := 0; i < numUniform; i++ {
+		dir := model3d.XYZ(gen.NormFloat64(), gen.NormFloat64(), gen.NormFloat64())
+		meanDensity += light.Density(dir) / numUniform
+	}
+	if math.Abs(meanDensity-1) > 0.02 {
+		t.Errorf("expected mean density 1 but got %f", meanDensity)
+	}
+
+	// Importance sampling should give unbiased estimates
+	// of the total radiance.
+	var expected Color
+	for i := 0; i < numUniform; i++ {
+		dir := model3d.XYZ(gen.NormFloat64(), gen.NormFloat64(), gen.NormFloat64())
+		expected = expected.Add(light.Radiance(dir).Scale(1.0 / numUniform))
+	}
+	var actual Color
+	var sunSamples int
+	const numSamples = 20000
+	for i := 0; i < numSamples; i++ {
+		dir, radiance, density := light.SampleDirection(gen)
+		if math.Abs(dir.Norm()-1) > 1e-8 {
+			t.Fatalf("direction is not normalized: %v", dir)
+		}
+		if radiance != light.Radiance(dir) || density != light.Density(dir) {
+			t.Fatal("inconsistent sample")
+		}
+		if radiance.X == 50 {
+			sunSamples++
+		}
+		actual = actual.Add(radiance.Scale(1 / (density * numSamples)))
+	}
+	if math.Abs(actual.X-expected.X) > expected.X*0.05 {
+		t.Errorf("expected mean radiance %v but got %v", expected, actual)
+	}
+	if sunSamples < numSamples/4 {
+		t.Errorf("sun was only sampled %d times", sunSamples)
+	}
+}
+
+func TestRecursiveRayTracerEnvironment(t *testing.T) {
+	img := NewImage(32, 16)
+	for i := range img.Data {
+		img.Data[i] = NewColor(0.2)
+	}
+	// A bright patch, which should be found by explicit
+	// sampling and by bouncing rays.
+	for y := 2; y < 5; y++ {
+		for x := 20; x < 24; x++ {
+			img.Data[y*32+x] = Color{X: 20, Y: 10, Z: 5}
+		}
+	}
+	env := NewEnvironmentLight(img)
+
+	scene := &ColliderObject{
+		Collider: &model3d.Sphere{Radius: 1},
+		Material: &LambertMaterial{DiffuseColor: NewColor(0.5)},
+	}
+	camera := NewCameraAt(model3d.XYZ(0, 0, -4), model3d.Coord3D{}, math.Pi/4)
+	render := func(mis bool, numSamples int) *Image {
+		renderer := &RecursiveRayTracer{
+			Camera:      camera,
+			Environment: env,
+			MaxDepth:    2,
+			NumSamples:  numSamples,
+		}
+		out := NewImage(16, 16)
+		if mis {
+			renderer.Render(out, scene)
+		} else {
+			// Without explicit sampling, only bouncing
+			// rays can find the light.
+			renderer.Environment = nil
+			renderer.Render(out, &environmentBackground{Object: scene, Env: env})
+		}
+		return out
+	}
+	withMIS := render(true, 200)
+	reference := render(false, 4000)
+
+	// Corners see the background directly.
+	if withMIS.Data[0].Dist(env.Radiance(camera.Caster(15, 15)(0, 0))) > 1e-8 {
+		t.Errorf("unexpected background: %v", withMIS.Data[0])
+	}
+
+	var meanMIS, meanRef Color
+	for i, c := range withMIS.Data {
+		meanMIS = meanMIS.Add(c)
+		meanRef = meanRef.Add(reference.Data[i])
+	}
+	if meanMIS.Dist(meanRef) > meanRef.Norm()*0.05 {
+		t.Errorf("expected mean %v but got %v", meanRef, meanMIS)
+	}
+}
+
+// environmentBackground wraps an object so that rays which
+// escape it hit an emissive sphere matching an environment
+// light.
+type environmentBackground struct {
+	Object
+	Env *EnvironmentLight
+}
+
+func (e *environmentBackground) Cast(r *model3d.Ray) (model3d.RayCollision, Material, bool) {
+	if coll, mat, ok := e.Object.Cast(r); ok {
+		return coll, mat, true
+	}
+	return model3d.RayCollision{Scale: 1e8, Normal: r.Direction.Normalize().Scale(-1)},
+		&LambertMaterial{EmissionColor: e.Env.Radiance(r.Direction)}, true
+}
+
+func TestRecursiveRayTracerEnvironmentLastBounce(t *testing.T) {
+	img := NewImage(32, 16)
+	for i := range img.Data {
+		img.Data[i] = NewColor(0.5)
+	}
+	floor := &ColliderObject{
+		Collider: &model3d.Rect{
+			MinVal: model3d.XYZ(-5, -5, -1),
+			MaxVal: model3d.XYZ(5, 5, 0),
+		},
+		Material: &LambertMaterial{DiffuseColor: NewColor(0.8)},
+	}
+	ray := &model3d.Ray{Origin: model3d.Z(1), Direction: model3d.Z(-1)}
+
+	// Without another bounce, the explicit samples alone
+	// must account for the entire sky.
+	tracer := &RecursiveRayTracer{Environment: NewEnvironmentLight(img)}
+	gen := rand.New(rand.NewSource(1337))
+	var mean float64
+	const n = 20000
+	for i := 0; i < n; i++ {
+		mean += tracer.recurse(gen, floor, ray, 0, NewColor(1), 0).X / n
+	}
+	if expected := 0.8 * 0.5; math.Abs(mean-expected) > 0.02*expected {
+		t.Errorf("expected mean %f but got %f", expected, mean)
+	}
+}
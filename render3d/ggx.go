@@ -0,0 +1,198 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const DefaultGGXMaterialSpecularReflectance = 0.04
+
+// ggxMinAlpha prevents the distribution from becoming a
+// delta function for perfectly smooth surfaces.
+const ggxMinAlpha = 1e-3
+
+// GGXMaterial is a physically-based microfacet material
+// using the GGX (Trowbridge-Reitz) distribution, with the
+// same roughness and metalness parameters as common PBR
+// workflows.
+//
+// The specular term is importance sampled using the
+// distribution of visible normals, which works well even
+// for smooth surfaces and grazing angles.
+//
+// See https://jcgt.org/published/0007/04/01/.
+type GGXMaterial struct {
+	// Roughness is the perceptual roughness in [0, 1],
+	// where 0 is perfectly smooth.
+	// The microfacet alpha parameter is the square of
+	// the roughness.
+	Roughness float64
+
+	// Metalness is in [0, 1], where 0 is a dielectric
+	// such as plastic, and 1 is a metal.
+	Metalness float64
+
+	// BaseColor is the diffuse color of dielectrics, and
+	// the specular color of metals.
+	BaseColor Color
+
+	// SpecularReflectance is the reflectance of
+	// dielectrics at normal incidence.
+	//
+	// If 0, DefaultGGXMaterialSpecularReflectance is used.
+	SpecularReflectance float64
+
+	EmissionColor Color
+	AmbientColor  Color
+}
+
+func (g *GGXMaterial) BSDF(normal, source, dest model3d.Coord3D) Color {
+	destDot := dest.Dot(normal)
+	sourceDot := -source.Dot(normal)
+	if destDot <= 0 || sourceDot <= 0 {
+		return Color{}
+	}
+	half := dest.Sub(source).Normalize()
+	fresnel := g.fresnel(dest.Dot(half))
+
+	alpha := g.alpha()
+	d := ggxDistribution(alpha, normal.Dot(half))
+	shadowing := ggxSmithG1(alpha, destDot) * ggxSmithG1(alpha, sourceDot)
+
+	// Multiply the usual BRDF by 4*pi, since BSDFs are
+	// relative to the uniform distribution on the sphere.
+	specular := fresnel.Scale(math.Pi * d * shadowing / (sourceDot * destDot))
+
+	if g.Metalness >= 1 {
+		return specular
+	}
+	// See LambertMaterial.BSDF() for scale.
+	diffuse := NewColor(1).Sub(fresnel).Mul(g.BaseColor).Scale(4 * (1 - g.Metalness))
+	return specular.Add(diffuse)
+}
+
+// SampleSource samples the specular term using visible
+// normals, and mixes in cosine-weighted samples for the
+// diffuse term of dielectrics.
+func (g *GGXMaterial) SampleSource(gen *rand.Rand, normal,
+	dest model3d.Coord3D) model3d.Coord3D {
+	if dest.Dot(normal) <= 0 || gen.Float64() >= g.specularProb() {
+		return (&LambertMaterial{}).SampleSource(gen, normal, dest)
+	}
+	half := g.sampleHalf(gen, normal, dest)
+	return half.Reflect(dest).Scale(-1)
+}
+
+func (g *GGXMaterial) SourceDensity(normal, source, dest model3d.Coord3D) float64 {
+	lambertDensity := (&LambertMaterial{}).SourceDensity(normal, source, dest)
+	destDot := dest.Dot(normal)
+	if destDot <= 0 {
+		return lambertDensity
+	}
+	half := dest.Sub(source).Normalize()
+	alpha := g.alpha()
+
+	// The density of visible normals is
+	//
+	//     G1(v) * max(0, v.h) * D(h) / (n.v)
+	//
+	// and reflection introduces a Jacobian of 1/(4*v.h).
+	// Multiply by 4*pi for the density relative to the
+	// uniform sphere.
+	var specDensity float64
+	if dest.Dot(half) > 0 {
+		specDensity = math.Pi * ggxSmithG1(alpha, destDot) *
+			ggxDistribution(alpha, normal.Dot(half)) / destDot
+	}
+	p := g.specularProb()
+	return p*specDensity + (1-p)*lambertDensity
+}
+
+func (g *GGXMaterial) Emission() Color {
+	return g.EmissionColor
+}
+
+func (g *GGXMaterial) Ambient() Color {
+	return g.AmbientColor
+}
+
+// sampleHalf samples a microfacet normal visible from the
+// dest direction.
+func (g *GGXMaterial) sampleHalf(gen *rand.Rand, normal,
+	dest model3d.Coord3D) model3d.Coord3D {
+	alpha := g.alpha()
+	b1, b2 := normal.OrthoBasis()
+
+	// Transform the view direction into the hemisphere
+	// configuration.
+	view := model3d.XYZ(alpha*dest.Dot(b1), alpha*dest.Dot(b2), dest.Dot(normal)).Normalize()
+
+	// Orthonormal basis around the view direction.
+	var t1 model3d.Coord3D
+	if lenSq := view.X*view.X + view.Y*view.Y; lenSq > 0 {
+		t1 = model3d.XY(-view.Y, view.X).Scale(1 / math.Sqrt(lenSq))
+	} else {
+		t1 = model3d.X(1)
+	}
+	t2 := view.Cross(t1)
+
+	// Sample a point on the projected hemisphere.
+	r := math.Sqrt(gen.Float64())
+	phi := 2 * math.Pi * gen.Float64()
+	p1 := r * math.Cos(phi)
+	p2 := r * math.Sin(phi)
+	s := 0.5 * (1 + view.Z)
+	p2 = (1-s)*math.Sqrt(1-p1*p1) + s*p2
+
+	// Reproject onto the hemisphere, and then undo the
+	// stretch.
+	h := t1.Scale(p1).Add(t2.Scale(p2)).Add(
+		view.Scale(math.Sqrt(math.Max(0, 1-p1*p1-p2*p2))),
+	)
+	h = model3d.XYZ(alpha*h.X, alpha*h.Y, math.Max(0, h.Z)).Normalize()
+	return b1.Scale(h.X).Add(b2.Scale(h.Y)).Add(normal.Scale(h.Z))
+}
+
+// fresnel computes Schlick's approximation of the
+// reflectance, given the cosine between the dest and the
+// microfacet normal.
+func (g *GGXMaterial) fresnel(cos float64) Color {
+	r0 := g.SpecularReflectance
+	if r0 == 0 {
+		r0 = DefaultGGXMaterialSpecularReflectance
+	}
+	f0 := NewColor(r0).Scale(1 - g.Metalness).Add(g.BaseColor.Scale(g.Metalness))
+	weight := math.Pow(1-math.Max(0, math.Min(1, cos)), 5)
+	return f0.Add(NewColor(1).Sub(f0).Scale(weight))
+}
+
+// specularProb gets the probability of sampling the
+// specular term rather than the diffuse term.
+func (g *GGXMaterial) specularProb() float64 {
+	return math.Min(1, (1+g.Metalness)/2)
+}
+
+func (g *GGXMaterial) alpha() float64 {
+	return math.Max(ggxMinAlpha, g.Roughness*g.Roughness)
+}
+
+// ggxDistribution computes the GGX normal distribution
+// function D(h), given the cosine between h and the
+// surface normal.
+func ggxDistribution(alpha, cos float64) float64 {
+	if cos <= 0 {
+		return 0
+	}
+	a2 := alpha * alpha
+	x := cos*cos*(a2-1) + 1
+	return a2 / (math.Pi * x * x)
+}
+
+// ggxSmithG1 computes the Smith masking function for a
+// direction with the given cosine to the surface normal.
+func ggxSmithG1(alpha, cos float64) float64 {
+	a2 := alpha * alpha
+	return 2 * cos / (cos + math.Sqrt(a2+(1-a2)*cos*cos))
+}
@@ -0,0 +1,85 @@
+package render3d
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestGGXMaterialSampling(t *testing.T) {
+	for _, roughness := range []float64{0.5, 0.8} {
+		for _, metalness := range []float64{0, 0.5, 1} {
+			name := fmt.Sprintf("Roughness%.1fMetalness%.1f", roughness, metalness)
+			t.Run(name, func(t *testing.T) {
+				testMaterialSampling(t, &GGXMaterial{
+					Roughness: roughness,
+					Metalness: metalness,
+					BaseColor: Color{X: 1, Y: 0.9, Z: 0.5},
+				})
+			})
+		}
+	}
+}
+
+func TestGGXMaterialSourceDensity(t *testing.T) {
+	for _, roughness := range []float64{0.3, 0.6, 1} {
+		t.Run(fmt.Sprintf("Roughness%.1f", roughness), func(t *testing.T) {
+			mat := &GGXMaterial{Roughness: roughness, Metalness: 1}
+			normal := model3d.NewCoord3DRandUnit()
+			dest := model3d.NewCoord3DRandUnit()
+			for dest.Dot(normal) < 0.1 {
+				dest = model3d.NewCoord3DRandUnit()
+			}
+
+			// Densities are relative to the uniform sphere, so
+			// they should average to 1.
+			var sum float64
+			const n = 4000000
+			for i := 0; i < n; i++ {
+				sum += mat.SourceDensity(normal, model3d.NewCoord3DRandUnit(), dest)
+			}
+			if mean := sum / n; math.Abs(mean-1) > 0.02 {
+				t.Errorf("unexpected mean density: %f", mean)
+			}
+		})
+	}
+}
+
+func TestGGXMaterialEnergy(t *testing.T) {
+	for _, roughness := range []float64{0, 0.3, 1} {
+		t.Run(fmt.Sprintf("Roughness%.1f", roughness), func(t *testing.T) {
+			mat := &GGXMaterial{
+				Roughness: roughness,
+				Metalness: 1,
+				BaseColor: NewColor(1),
+			}
+			normal := model3d.NewCoord3DRandUnit()
+			var dest model3d.Coord3D
+			for math.Abs(normal.Dot(dest)-0.8) > 0.1 {
+				dest = model3d.NewCoord3DRandUnit()
+			}
+			gen := rand.New(rand.NewSource(1337))
+
+			var sum float64
+			const n = 1000000
+			for i := 0; i < n; i++ {
+				source := mat.SampleSource(gen, normal, dest)
+				weight := 1 / mat.SourceDensity(normal, source, dest)
+				areaIn := math.Abs(source.Dot(normal))
+				sum += weight * areaIn * mat.BSDF(normal, source, dest).X
+			}
+			// Single-scattering microfacet models lose some
+			// energy for rough surfaces, but never gain any.
+			mean := sum / n
+			if mean > 1.01 || mean <= 0 {
+				t.Errorf("unexpected mean BSDF: %f", mean)
+			}
+			if roughness == 0 && mean < 0.99 {
+				t.Errorf("smooth surface lost energy: %f", mean)
+			}
+		})
+	}
+}
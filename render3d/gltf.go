@@ -0,0 +1,677 @@
+package render3d
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// DefaultGLTFResolution is the number of grid cells along
+// the longest side of a collider which is meshed by a
+// GLTFExporter, when no Delta is specified.
+const DefaultGLTFResolution = 64
+
+const (
+	gltfArrayBuffer        = 34962
+	gltfElementArrayBuffer = 34963
+	gltfFloat              = 5126
+	gltfUnsignedInt        = 5125
+	gltfTriangles          = 4
+)
+
+// A GLTFExporter converts scenes of Objects into glTF 2.0
+// files, which can be viewed in web browsers and most 3D
+// software.
+//
+// Meshes are exported as-is, while other colliders, like
+// spheres, are converted to meshes with marching cubes.
+// Translated, rotated, and scaled objects are supported,
+// as are objects created by Objectify, which are exported
+// with vertex colors, and AttributedMeshObjects, which are
+// exported with their normals, texture coordinates, and
+// colors.
+//
+// Materials are approximated with physically based glTF
+// materials, using the diffuse color as the base color,
+// the Phong exponent to determine roughness, and
+// extensions for emission and refraction.
+// Other kinds of materials are exported as a neutral gray.
+type GLTFExporter struct {
+	// Delta is the grid spacing for meshing colliders which
+	// are not made of triangles.
+	//
+	// If 0, DefaultGLTFResolution is used to determine the
+	// grid spacing for each collider.
+	Delta float64
+
+	// SmoothingAngle, if non-zero, is the maximum angle
+	// between triangles which are shaded smoothly, for
+	// objects without their own normals.
+	// Normals are computed with model3d.Mesh.SmoothNormals.
+	//
+	// If 0, normals are not exported for these objects, and
+	// most viewers will shade them with flat triangles.
+	SmoothingAngle float64
+
+	// YUp indicates that the scene uses the y-axis as the
+	// vertical axis.
+	// By default, the scene is assumed to use the z-axis,
+	// and it is rotated to match the glTF convention.
+	YUp bool
+}
+
+// SaveGLTF exports a scene to a file using a default
+// GLTFExporter.
+//
+// If the path ends in ".glb", a binary glTF file is saved.
+// Otherwise, a text glTF file with embedded data is saved.
+func SaveGLTF(path string, obj Object) error {
+	return (&GLTFExporter{}).Save(path, obj)
+}
+
+// Save exports a scene to a file.
+//
+// See SaveGLTF for details on the file format.
+func (g *GLTFExporter) Save(path string, obj Object) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "save glTF")
+	}
+	defer f.Close()
+	if strings.ToLower(filepath.Ext(path)) == ".glb" {
+		err = g.WriteGLB(f, obj)
+	} else {
+		err = g.WriteGLTF(f, obj)
+	}
+	if err != nil {
+		return errors.Wrap(err, "save glTF")
+	}
+	return nil
+}
+
+// WriteGLTF writes a scene as a text glTF file, with the
+// binary data embedded as a base64 data URI.
+func (g *GLTFExporter) WriteGLTF(w io.Writer, obj Object) error {
+	doc, data, err := g.build(obj)
+	if err != nil {
+		return errors.Wrap(err, "write glTF")
+	}
+	if len(doc.Buffers) > 0 {
+		doc.Buffers[0].URI = "data:application/octet-stream;base64," +
+			base64.StdEncoding.EncodeToString(data)
+	}
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(doc); err != nil {
+		return errors.Wrap(err, "write glTF")
+	}
+	return nil
+}
+
+// WriteGLB writes a scene as a binary glTF file.
+func (g *GLTFExporter) WriteGLB(w io.Writer, obj Object) error {
+	doc, data, err := g.build(obj)
+	if err != nil {
+		return errors.Wrap(err, "write GLB")
+	}
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return errors.Wrap(err, "write GLB")
+	}
+	for len(jsonData)%4 != 0 {
+		jsonData = append(jsonData, ' ')
+	}
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+
+	var buf bytes.Buffer
+	totalSize := 12 + 8 + len(jsonData)
+	if len(data) > 0 {
+		totalSize += 8 + len(data)
+	}
+	binary.Write(&buf, binary.LittleEndian, []uint32{0x46546C67, 2, uint32(totalSize)})
+	binary.Write(&buf, binary.LittleEndian, []uint32{uint32(len(jsonData)), 0x4E4F534A})
+	buf.Write(jsonData)
+	if len(data) > 0 {
+		binary.Write(&buf, binary.LittleEndian, []uint32{uint32(len(data)), 0x004E4942})
+		buf.Write(data)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "write GLB")
+	}
+	return nil
+}
+
+// gltfPrimitive is a list of triangles with a material,
+// and optional colors, normals, and texture coordinates
+// for the vertices of each triangle.
+type gltfPrimitive struct {
+	Triangles []*model3d.Triangle
+	Colors    [][3]Color
+	Normals   [][3]model3d.Coord3D
+	UVs       [][3]model2d.Coord
+	Material  Material
+}
+
+func (g *GLTFExporter) primitives(obj Object) ([]*gltfPrimitive, error) {
+	switch obj := obj.(type) {
+	case JoinedObject:
+		var res []*gltfPrimitive
+		for _, o := range obj {
+			prims, err := g.primitives(o)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, prims...)
+		}
+		return res, nil
+	case *ColliderObject:
+		tris, ok := model3d.ColliderTriangles(obj.Collider)
+		if !ok {
+			tris = g.meshCollider(obj.Collider).TriangleSlice()
+		}
+		prim := &gltfPrimitive{Triangles: tris, Material: obj.Material}
+		if g.SmoothingAngle != 0 {
+			normals := model3d.NewMeshTriangles(tris).SmoothNormals(g.SmoothingAngle)
+			prim.Normals = make([][3]model3d.Coord3D, len(tris))
+			for i, t := range tris {
+				for j := range t {
+					prim.Normals[i][j] = normals[model3d.MeshCorner{Triangle: t, Index: j}]
+				}
+			}
+		}
+		return []*gltfPrimitive{prim}, nil
+	case *AttributedMeshObject:
+		tris, ok := model3d.ColliderTriangles(obj.Collider)
+		if !ok {
+			return nil, errors.New("attributed mesh collider is not made of triangles")
+		}
+		return []*gltfPrimitive{newGLTFAttributedPrimitive(tris, obj.Attributes,
+			obj.Material)}, nil
+	case *colorFuncObject:
+		prims, err := g.primitives(obj.Object)
+		if err != nil || obj.ColorFunc == nil {
+			return prims, err
+		}
+		for _, p := range prims {
+			p.Colors = make([][3]Color, len(p.Triangles))
+			for i, t := range p.Triangles {
+				for j, c := range t {
+					var bary [3]float64
+					bary[j] = 1
+					p.Colors[i][j] = obj.ColorFunc(c, model3d.RayCollision{
+						Normal: t.Normal(),
+						Extra: &model3d.TriangleCollision{
+							Triangle:    t,
+							Barycentric: bary,
+						},
+					})
+				}
+			}
+		}
+		return prims, nil
+	case *translatedObject:
+		return g.mapPrimitives(obj.Object, func(c model3d.Coord3D) model3d.Coord3D {
+			return c.Add(obj.Offset)
+		}, nil, false)
+	case *matrixObject:
+		normalMatrix := obj.Inverse.Transpose()
+		normalFunc := func(n model3d.Coord3D) model3d.Coord3D {
+			return normalMatrix.MulColumn(n).Normalize()
+		}
+		return g.mapPrimitives(obj.Object, obj.Matrix.MulColumn, normalFunc, obj.Matrix.Det() < 0)
+	default:
+		return nil, fmt.Errorf("unsupported object type: %T", obj)
+	}
+}
+
+func (g *GLTFExporter) mapPrimitives(obj Object,
+	f, normalFunc func(model3d.Coord3D) model3d.Coord3D, flip bool) ([]*gltfPrimitive, error) {
+	prims, err := g.primitives(obj)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prims {
+		mapped := make([]*model3d.Triangle, len(p.Triangles))
+		for i, t := range p.Triangles {
+			mapped[i] = &model3d.Triangle{f(t[0]), f(t[1]), f(t[2])}
+			if p.Normals != nil && normalFunc != nil {
+				for j, n := range p.Normals[i] {
+					p.Normals[i][j] = normalFunc(n)
+				}
+			}
+			if flip {
+				mapped[i][0], mapped[i][1] = mapped[i][1], mapped[i][0]
+				if p.Colors != nil {
+					p.Colors[i][0], p.Colors[i][1] = p.Colors[i][1], p.Colors[i][0]
+				}
+				if p.Normals != nil {
+					p.Normals[i][0], p.Normals[i][1] = p.Normals[i][1], p.Normals[i][0]
+				}
+				if p.UVs != nil {
+					p.UVs[i][0], p.UVs[i][1] = p.UVs[i][1], p.UVs[i][0]
+				}
+			}
+		}
+		p.Triangles = mapped
+	}
+	return prims, nil
+}
+
+func (g *GLTFExporter) meshCollider(c model3d.Collider) *model3d.Mesh {
+	delta := g.Delta
+	if delta == 0 {
+		size := c.Max().Sub(c.Min())
+		delta = math.Max(size.X, math.Max(size.Y, size.Z)) / DefaultGLTFResolution
+	}
+	solid, ok := c.(model3d.Solid)
+	if !ok {
+		solid = model3d.NewColliderSolid(c)
+	}
+	return model3d.MarchingCubesSearch(solid, delta, 8)
+}
+
+type gltfDocument struct {
+	Asset          gltfAsset          `json:"asset"`
+	ExtensionsUsed []string           `json:"extensionsUsed,omitempty"`
+	Scene          int                `json:"scene"`
+	Scenes         []gltfScene        `json:"scenes"`
+	Nodes          []*gltfNode        `json:"nodes"`
+	Meshes         []*gltfMesh        `json:"meshes,omitempty"`
+	Materials      []*gltfMaterial    `json:"materials,omitempty"`
+	Accessors      []*gltfAccessor    `json:"accessors,omitempty"`
+	BufferViews    []*gltfBufferView  `json:"bufferViews,omitempty"`
+	Buffers        []*gltfBufferEntry `json:"buffers,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Mesh     *int      `json:"mesh,omitempty"`
+	Children []int     `json:"children,omitempty"`
+	Rotation []float64 `json:"rotation,omitempty"`
+}
+
+type gltfMesh struct {
+	Primitives []gltfMeshPrimitive `json:"primitives"`
+}
+
+type gltfMeshPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   int            `json:"material"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfMaterial struct {
+	PBR            gltfPBR                `json:"pbrMetallicRoughness"`
+	EmissiveFactor []float64              `json:"emissiveFactor,omitempty"`
+	Extensions     map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type gltfPBR struct {
+	BaseColorFactor [4]float64 `json:"baseColorFactor"`
+	MetallicFactor  float64    `json:"metallicFactor"`
+	RoughnessFactor float64    `json:"roughnessFactor"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float64 `json:"min,omitempty"`
+	Max           []float64 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+type gltfBufferEntry struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri,omitempty"`
+}
+
+func (g *GLTFExporter) build(obj Object) (*gltfDocument, []byte, error) {
+	prims, err := g.primitives(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc := &gltfDocument{
+		Asset:  gltfAsset{Version: "2.0", Generator: "model3d"},
+		Scenes: []gltfScene{{Nodes: []int{0}}},
+		Nodes:  []*gltfNode{{}},
+	}
+	if !g.YUp {
+		// Rotate -90 degrees around the x-axis, so that the
+		// z-axis points up.
+		doc.Nodes[0].Rotation = []float64{-math.Sqrt2 / 2, 0, 0, math.Sqrt2 / 2}
+	}
+
+	var data bytes.Buffer
+	addView := func(target int, values interface{}) int {
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+		offset := data.Len()
+		binary.Write(&data, binary.LittleEndian, values)
+		doc.BufferViews = append(doc.BufferViews, &gltfBufferView{
+			ByteOffset: offset,
+			ByteLength: data.Len() - offset,
+			Target:     target,
+		})
+		return len(doc.BufferViews) - 1
+	}
+	addAccessor := func(a *gltfAccessor) int {
+		doc.Accessors = append(doc.Accessors, a)
+		return len(doc.Accessors) - 1
+	}
+
+	materialIndices := map[string]int{}
+	extensions := map[string]bool{}
+	for _, p := range prims {
+		if len(p.Triangles) == 0 {
+			continue
+		}
+		positions, colors, normals, uvs, indices := p.buffers()
+
+		min := model3d.Ones(math.Inf(1))
+		max := model3d.Ones(math.Inf(-1))
+		for _, t := range p.Triangles {
+			for _, c := range t {
+				min = min.Min(c)
+				max = max.Max(c)
+			}
+		}
+		attributes := map[string]int{
+			"POSITION": addAccessor(&gltfAccessor{
+				BufferView:    addView(gltfArrayBuffer, positions),
+				ComponentType: gltfFloat,
+				Count:         len(positions) / 3,
+				Type:          "VEC3",
+				Min:           gltfFloat32Array(min),
+				Max:           gltfFloat32Array(max),
+			}),
+		}
+		if colors != nil {
+			attributes["COLOR_0"] = addAccessor(&gltfAccessor{
+				BufferView:    addView(gltfArrayBuffer, colors),
+				ComponentType: gltfFloat,
+				Count:         len(colors) / 3,
+				Type:          "VEC3",
+			})
+		}
+		if normals != nil {
+			attributes["NORMAL"] = addAccessor(&gltfAccessor{
+				BufferView:    addView(gltfArrayBuffer, normals),
+				ComponentType: gltfFloat,
+				Count:         len(normals) / 3,
+				Type:          "VEC3",
+			})
+		}
+		if uvs != nil {
+			attributes["TEXCOORD_0"] = addAccessor(&gltfAccessor{
+				BufferView:    addView(gltfArrayBuffer, uvs),
+				ComponentType: gltfFloat,
+				Count:         len(uvs) / 2,
+				Type:          "VEC2",
+			})
+		}
+		indexAccessor := addAccessor(&gltfAccessor{
+			BufferView:    addView(gltfElementArrayBuffer, indices),
+			ComponentType: gltfUnsignedInt,
+			Count:         len(indices),
+			Type:          "SCALAR",
+		})
+
+		mat := newGLTFMaterial(p.Material, colors != nil)
+		for name := range mat.Extensions {
+			extensions[name] = true
+		}
+		key, _ := json.Marshal(mat)
+		matIdx, ok := materialIndices[string(key)]
+		if !ok {
+			matIdx = len(doc.Materials)
+			materialIndices[string(key)] = matIdx
+			doc.Materials = append(doc.Materials, mat)
+		}
+
+		doc.Meshes = append(doc.Meshes, &gltfMesh{
+			Primitives: []gltfMeshPrimitive{{
+				Attributes: attributes,
+				Indices:    indexAccessor,
+				Material:   matIdx,
+				Mode:       gltfTriangles,
+			}},
+		})
+		meshIdx := len(doc.Meshes) - 1
+		doc.Nodes = append(doc.Nodes, &gltfNode{Mesh: &meshIdx})
+		doc.Nodes[0].Children = append(doc.Nodes[0].Children, len(doc.Nodes)-1)
+	}
+	for _, name := range []string{
+		"KHR_materials_emissive_strength",
+		"KHR_materials_ior",
+		"KHR_materials_transmission",
+		"KHR_materials_volume",
+	} {
+		if extensions[name] {
+			doc.ExtensionsUsed = append(doc.ExtensionsUsed, name)
+		}
+	}
+
+	if data.Len() > 0 {
+		doc.Buffers = []*gltfBufferEntry{{ByteLength: data.Len()}}
+	}
+	return doc, data.Bytes(), nil
+}
+
+// buffers creates the vertex and index data for the
+// primitive.
+//
+// Vertices are shared between triangles, unless there are
+// vertex attributes, in which case each triangle may have
+// different attributes at the same point.
+//
+// Texture coordinates are flipped vertically, since glTF
+// puts the origin at the top-left corner of images.
+func (g *gltfPrimitive) buffers() (positions, colors, normals, uvs []float32,
+	indices []uint32) {
+	if g.Colors != nil || g.Normals != nil || g.UVs != nil {
+		for i, t := range g.Triangles {
+			for j, c := range t {
+				indices = append(indices, uint32(len(positions)/3))
+				positions = append(positions, gltfFloat32s(c)...)
+				if g.Colors != nil {
+					colors = append(colors, gltfFloat32s(ClampColor(g.Colors[i][j]))...)
+				}
+				if g.Normals != nil {
+					normals = append(normals, gltfFloat32s(g.Normals[i][j])...)
+				}
+				if g.UVs != nil {
+					uv := g.UVs[i][j]
+					uvs = append(uvs, float32(uv.X), float32(1-uv.Y))
+				}
+			}
+		}
+		return
+	}
+	coordToIdx := model3d.NewCoordToInt()
+	for _, t := range g.Triangles {
+		for _, c := range t {
+			idx, ok := coordToIdx.Load(c)
+			if !ok {
+				idx = len(positions) / 3
+				coordToIdx.Store(c, idx)
+				positions = append(positions, gltfFloat32s(c)...)
+			}
+			indices = append(indices, uint32(idx))
+		}
+	}
+	return
+}
+
+// newGLTFAttributedPrimitive creates a primitive with the
+// attributes of a mesh.
+//
+// Corners without an attribute use white for colors, the
+// flat triangle normal for normals, and the origin for
+// texture coordinates.
+func newGLTFAttributedPrimitive(tris []*model3d.Triangle, attrs *model3d.MeshAttributes,
+	mat Material) *gltfPrimitive {
+	res := &gltfPrimitive{Triangles: tris, Material: mat}
+	if attrs.HasColors() {
+		res.Colors = make([][3]Color, len(tris))
+	}
+	if attrs.HasNormals() {
+		res.Normals = make([][3]model3d.Coord3D, len(tris))
+	}
+	if attrs.HasUVs() {
+		res.UVs = make([][3]model2d.Coord, len(tris))
+	}
+	for i, t := range tris {
+		for j := range t {
+			if res.Colors != nil {
+				res.Colors[i][j] = NewColor(1)
+				if c, ok := attrs.Color(t, j); ok {
+					res.Colors[i][j] = NewColorRGB(c[0], c[1], c[2])
+				}
+			}
+			if res.Normals != nil {
+				n, ok := attrs.Normal(t, j)
+				if !ok {
+					n = t.Normal()
+				}
+				res.Normals[i][j] = n.Normalize()
+			}
+			if res.UVs != nil {
+				res.UVs[i][j], _ = attrs.UV(t, j)
+			}
+		}
+	}
+	return res
+}
+
+func newGLTFMaterial(mat Material, vertexColors bool) *gltfMaterial {
+	res := &gltfMaterial{
+		PBR: gltfPBR{
+			BaseColorFactor: [4]float64{0.5, 0.5, 0.5, 1},
+			RoughnessFactor: 1,
+		},
+	}
+	var emission Color
+	switch mat := mat.(type) {
+	case *LambertMaterial:
+		res.setBaseColor(mat.DiffuseColor)
+		emission = mat.EmissionColor
+	case *PhongMaterial:
+		res.setBaseColor(mat.DiffuseColor)
+		res.PBR.RoughnessFactor = phongRoughness(mat.Alpha)
+		emission = mat.EmissionColor
+	case *GGXMaterial:
+		res.setBaseColor(mat.BaseColor)
+		res.PBR.MetallicFactor = mat.Metalness
+		res.PBR.RoughnessFactor = mat.Roughness
+		emission = mat.EmissionColor
+	case *RefractMaterial:
+		res.setBaseColor(mat.RefractColor)
+		res.PBR.RoughnessFactor = 0
+		res.Extensions = map[string]interface{}{
+			"KHR_materials_transmission": map[string]float64{"transmissionFactor": 1},
+			"KHR_materials_ior":          map[string]float64{"ior": mat.IndexOfRefraction},
+		}
+	case *DielectricMaterial:
+		res.setBaseColor(mat.RefractColor)
+		res.PBR.RoughnessFactor = 0
+		res.Extensions = map[string]interface{}{
+			"KHR_materials_transmission": map[string]float64{"transmissionFactor": 1},
+			"KHR_materials_ior":          map[string]float64{"ior": mat.IndexOfRefraction},
+		}
+		if (mat.Absorption != Color{}) {
+			// Express the absorption as the color remaining
+			// after a unit distance.
+			attenuation := mat.Transmittance(1)
+			res.Extensions["KHR_materials_volume"] = map[string]interface{}{
+				"attenuationColor":    attenuation.Array(),
+				"attenuationDistance": 1.0,
+				"thicknessFactor":     1.0,
+			}
+		}
+	case *JoinedMaterial:
+		// The BSDFs are added together, so the colors are
+		// summed and the smoothest roughness is kept.
+		var base Color
+		for _, m := range mat.Materials {
+			sub := newGLTFMaterial(m, false)
+			base = base.Add(model3d.XYZ(sub.PBR.BaseColorFactor[0],
+				sub.PBR.BaseColorFactor[1], sub.PBR.BaseColorFactor[2]))
+			res.PBR.RoughnessFactor = math.Min(res.PBR.RoughnessFactor, sub.PBR.RoughnessFactor)
+		}
+		res.setBaseColor(base)
+		emission = mat.Emission()
+	}
+	if vertexColors {
+		// Vertex colors are multiplied by the base color.
+		res.setBaseColor(NewColor(1))
+	}
+
+	if maxEmission := math.Max(emission.X, math.Max(emission.Y, emission.Z)); maxEmission > 0 {
+		if maxEmission > 1 {
+			emission = emission.Scale(1 / maxEmission)
+			if res.Extensions == nil {
+				res.Extensions = map[string]interface{}{}
+			}
+			res.Extensions["KHR_materials_emissive_strength"] = map[string]float64{
+				"emissiveStrength": maxEmission,
+			}
+		}
+		res.EmissiveFactor = []float64{emission.X, emission.Y, emission.Z}
+	}
+	return res
+}
+
+func (g *gltfMaterial) setBaseColor(c Color) {
+	c = ClampColor(c)
+	g.PBR.BaseColorFactor = [4]float64{c.X, c.Y, c.Z, 1}
+}
+
+// phongRoughness approximates the glTF roughness for a
+// Phong exponent, by matching the exponent to a Beckmann
+// distribution.
+func phongRoughness(alpha float64) float64 {
+	return math.Sqrt(math.Sqrt(2 / (alpha + 2)))
+}
+
+func gltfFloat32s(c model3d.Coord3D) []float32 {
+	return []float32{float32(c.X), float32(c.Y), float32(c.Z)}
+}
+
+func gltfFloat32Array(c model3d.Coord3D) []float64 {
+	res := make([]float64, 3)
+	for i, x := range gltfFloat32s(c) {
+		res[i] = float64(x)
+	}
+	return res
+}
@@ -0,0 +1,314 @@
+package render3d
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestGLTFExporter(t *testing.T) {
+	box := model3d.NewMeshRect(model3d.XYZ(0, 0, 0), model3d.XYZ(1, 2, 3))
+	scene := JoinedObject{
+		&ColliderObject{
+			Collider: model3d.MeshToCollider(box),
+			Material: &LambertMaterial{DiffuseColor: NewColorRGB(1, 0, 0)},
+		},
+		Translate(&ColliderObject{
+			Collider: &model3d.Sphere{Radius: 1},
+			Material: &PhongMaterial{
+				Alpha:         20,
+				DiffuseColor:  NewColorRGB(0, 1, 0),
+				EmissionColor: NewColor(5),
+			},
+		}, model3d.X(10)),
+		Scale(Objectify(box, TriangleColorFunc(func(t *model3d.Triangle) [3]float64 {
+			return [3]float64{0, 0, 1}
+		})), -1),
+	}
+
+	var buf bytes.Buffer
+	if err := (&GLTFExporter{}).WriteGLB(&buf, scene); err != nil {
+		t.Fatal(err)
+	}
+	glb := buf.Bytes()
+	var header [5]uint32
+	binary.Read(bytes.NewReader(glb), binary.LittleEndian, &header)
+	if header[0] != 0x46546C67 || header[1] != 2 || int(header[2]) != len(glb) ||
+		header[4] != 0x4E4F534A {
+		t.Fatalf("unexpected header: %v", header)
+	}
+	var doc gltfDocument
+	if err := json.Unmarshal(glb[20:20+header[3]], &doc); err != nil {
+		t.Fatal(err)
+	}
+	binData := glb[28+header[3]:]
+
+	if len(doc.Meshes) != 3 || len(doc.Materials) != 3 || len(doc.Nodes) != 4 {
+		t.Fatalf("unexpected document: %d meshes, %d materials, %d nodes", len(doc.Meshes),
+			len(doc.Materials), len(doc.Nodes))
+	}
+	if len(doc.Nodes[0].Children) != 3 || len(doc.Nodes[0].Rotation) != 4 {
+		t.Error("unexpected root node")
+	}
+	if len(doc.ExtensionsUsed) != 1 || doc.ExtensionsUsed[0] != "KHR_materials_emissive_strength" {
+		t.Errorf("unexpected extensions: %v", doc.ExtensionsUsed)
+	}
+
+	// The box should be exported exactly, with shared
+	// vertices.
+	prim := doc.Meshes[0].Primitives[0]
+	positions := doc.Accessors[prim.Attributes["POSITION"]]
+	if positions.Count != 8 || doc.Accessors[prim.Indices].Count != 36 {
+		t.Errorf("unexpected box accessors: %d positions and %d indices", positions.Count,
+			doc.Accessors[prim.Indices].Count)
+	}
+	for i, x := range []float64{1, 2, 3} {
+		if positions.Min[i] != 0 || positions.Max[i] != x {
+			t.Errorf("unexpected bounds: %v, %v", positions.Min, positions.Max)
+		}
+	}
+	if base := doc.Materials[prim.Material].PBR.BaseColorFactor; base != [4]float64{1, 0, 0, 1} {
+		t.Errorf("unexpected base color: %v", base)
+	}
+
+	// The sphere should be meshed and translated.
+	prim = doc.Meshes[1].Primitives[0]
+	positions = doc.Accessors[prim.Attributes["POSITION"]]
+	if math.Abs(positions.Min[0]-9) > 0.05 || math.Abs(positions.Max[0]-11) > 0.05 {
+		t.Errorf("unexpected sphere bounds: %v, %v", positions.Min, positions.Max)
+	}
+	mat := doc.Materials[prim.Material]
+	if mat.PBR.RoughnessFactor >= 1 || mat.PBR.RoughnessFactor <= 0 {
+		t.Errorf("unexpected roughness: %f", mat.PBR.RoughnessFactor)
+	}
+	if len(mat.EmissiveFactor) != 3 || mat.EmissiveFactor[0] != 1 {
+		t.Errorf("unexpected emission: %v", mat.EmissiveFactor)
+	}
+
+	// The colored box should be flipped, with a vertex per
+	// triangle corner.
+	prim = doc.Meshes[2].Primitives[0]
+	positions = doc.Accessors[prim.Attributes["POSITION"]]
+	colors := doc.Accessors[prim.Attributes["COLOR_0"]]
+	if positions.Count != 36 || colors.Count != 36 {
+		t.Fatalf("unexpected counts: %d positions and %d colors", positions.Count, colors.Count)
+	}
+	if positions.Max[2] != 0 || positions.Min[2] != -3 {
+		t.Errorf("unexpected bounds: %v, %v", positions.Min, positions.Max)
+	}
+	readVec := func(accessor *gltfAccessor, idx int) model3d.Coord3D {
+		view := doc.BufferViews[accessor.BufferView]
+		var vec [3]float32
+		binary.Read(bytes.NewReader(binData[view.ByteOffset+idx*12:]), binary.LittleEndian, &vec)
+		return model3d.XYZ(float64(vec[0]), float64(vec[1]), float64(vec[2]))
+	}
+	if c := readVec(colors, 5); c != model3d.Z(1) {
+		t.Errorf("unexpected vertex color: %v", c)
+	}
+	mesh := model3d.NewMesh()
+	for i := 0; i < 36; i += 3 {
+		mesh.Add(&model3d.Triangle{readVec(positions, i), readVec(positions, i+1),
+			readVec(positions, i+2)})
+	}
+	if v := mesh.Volume(); math.Abs(v-6) > 1e-5 {
+		t.Errorf("flipped mesh should have positive volume, but got %f", v)
+	}
+}
+
+func TestGLTFExporterText(t *testing.T) {
+	obj := &ColliderObject{
+		Collider: model3d.MeshToCollider(model3d.NewMeshRect(model3d.Coord3D{}, model3d.XYZ(1, 1, 1))),
+		Material: &RefractMaterial{IndexOfRefraction: 1.5, RefractColor: NewColor(1)},
+	}
+	var buf bytes.Buffer
+	if err := (&GLTFExporter{YUp: true}).WriteGLTF(&buf, obj); err != nil {
+		t.Fatal(err)
+	}
+	var doc gltfDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Nodes[0].Rotation != nil {
+		t.Error("expected no rotation")
+	}
+	if len(doc.ExtensionsUsed) != 2 {
+		t.Errorf("unexpected extensions: %v", doc.ExtensionsUsed)
+	}
+	prefix := "data:application/octet-stream;base64,"
+	if !strings.HasPrefix(doc.Buffers[0].URI, prefix) {
+		t.Fatalf("unexpected URI: %s", doc.Buffers[0].URI)
+	}
+	data, err := base64.StdEncoding.DecodeString(doc.Buffers[0].URI[len(prefix):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != doc.Buffers[0].ByteLength {
+		t.Errorf("expected %d bytes but got %d", doc.Buffers[0].ByteLength, len(data))
+	}
+
+	err = (&GLTFExporter{}).WriteGLTF(&buf, &ParticipatingMedium{
+		Collider: &model3d.Sphere{Radius: 1},
+		Material: &HGMaterial{},
+		Lambda:   1,
+	})
+	if err == nil {
+		t.Error("expected error for unsupported object")
+	}
+}
+
+func TestGLTFExporterAttributes(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.Coord3D{}, model3d.XYZ(1, 1, 1))
+	attrMesh := model3d.NewAttributedMesh(mesh)
+	mesh.IterateVertices(func(c model3d.Coord3D) {
+		attrMesh.Attributes.Normals[c] = c.Sub(model3d.XYZ(0.5, 0.5, 0.5)).Normalize()
+		attrMesh.Attributes.UVs[c] = model2d.XY(c.X, c.Y)
+	})
+	obj := MatrixMultiply(NewAttributedMeshObject(attrMesh, &LambertMaterial{}),
+		&model3d.Matrix3{2, 0, 0, 0, 1, 0, 0, 0, 1})
+
+	var buf bytes.Buffer
+	if err := (&GLTFExporter{}).WriteGLTF(&buf, obj); err != nil {
+		t.Fatal(err)
+	}
+	var doc gltfDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.SplitN(doc.Buffers[0].URI, ",", 2)[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	prim := doc.Meshes[0].Primitives[0]
+	if _, ok := prim.Attributes["COLOR_0"]; ok {
+		t.Error("unexpected vertex colors")
+	}
+	readFloats := func(name string, n int) [][]float32 {
+		accessor := doc.Accessors[prim.Attributes[name]]
+		view := doc.BufferViews[accessor.BufferView]
+		res := make([][]float32, accessor.Count)
+		for i := range res {
+			res[i] = make([]float32, n)
+			binary.Read(bytes.NewReader(data[view.ByteOffset+i*n*4:]), binary.LittleEndian, res[i])
+		}
+		return res
+	}
+	positions := readFloats("POSITION", 3)
+	normals := readFloats("NORMAL", 3)
+	uvs := readFloats("TEXCOORD_0", 2)
+	if len(positions) != 36 || len(normals) != 36 || len(uvs) != 36 {
+		t.Fatalf("unexpected counts: %d, %d, %d", len(positions), len(normals), len(uvs))
+	}
+	for i, p := range positions {
+		c := model3d.XYZ(float64(p[0]), float64(p[1]), float64(p[2]))
+		expectedNormal := model3d.XYZ((c.X-1)/4, c.Y-0.5, c.Z-0.5).Normalize()
+		n := model3d.XYZ(float64(normals[i][0]), float64(normals[i][1]), float64(normals[i][2]))
+		if n.Dist(expectedNormal) > 1e-5 {
+			t.Fatalf("expected normal %v but got %v", expectedNormal, n)
+		}
+		if math.Abs(float64(uvs[i][0])-c.X/2) > 1e-5 || math.Abs(float64(uvs[i][1])-(1-c.Y)) > 1e-5 {
+			t.Fatalf("unexpected UV %v at %v", uvs[i], c)
+		}
+	}
+}
+
+func TestGLTFExporterSmoothing(t *testing.T) {
+	obj := &ColliderObject{
+		Collider: model3d.MeshToCollider(model3d.NewMeshIcosphere(model3d.Coord3D{}, 1, 4)),
+		Material: &LambertMaterial{},
+	}
+	var buf bytes.Buffer
+	if err := (&GLTFExporter{SmoothingAngle: math.Pi / 4}).WriteGLB(&buf, obj); err != nil {
+		t.Fatal(err)
+	}
+	glb := buf.Bytes()
+	jsonSize := binary.LittleEndian.Uint32(glb[12:])
+	var doc gltfDocument
+	if err := json.Unmarshal(glb[20:20+jsonSize], &doc); err != nil {
+		t.Fatal(err)
+	}
+	binData := glb[28+jsonSize:]
+	prim := doc.Meshes[0].Primitives[0]
+	positions := doc.Accessors[prim.Attributes["POSITION"]]
+	normalIdx, ok := prim.Attributes["NORMAL"]
+	if !ok {
+		t.Fatal("missing normals")
+	}
+	normals := doc.Accessors[normalIdx]
+	if normals.Count != positions.Count {
+		t.Fatalf("expected %d normals but got %d", positions.Count, normals.Count)
+	}
+	readVec := func(accessor *gltfAccessor, idx int) model3d.Coord3D {
+		view := doc.BufferViews[accessor.BufferView]
+		var vec [3]float32
+		binary.Read(bytes.NewReader(binData[view.ByteOffset+idx*12:]), binary.LittleEndian, &vec)
+		return model3d.XYZ(float64(vec[0]), float64(vec[1]), float64(vec[2]))
+	}
+	for i := 0; i < positions.Count; i++ {
+		p := readVec(positions, i)
+		n := readVec(normals, i)
+		if n.Dist(p.Normalize()) > 0.05 {
+			t.Fatalf("normal %v is not close to radial direction at %v", n, p)
+		}
+	}
+}
+
+func TestGLTFExporterAtlas(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.Coord3D{}, model3d.XYZ(3, 2, 1))
+	atlas := model3d.PackAtlas(model3d.PlanarAtlasCharts(mesh, 1e-5), 0.01)
+	obj := NewAttributedMeshObject(atlas.AttributedMesh(mesh), &LambertMaterial{})
+
+	var buf bytes.Buffer
+	if err := (&GLTFExporter{}).WriteGLTF(&buf, obj); err != nil {
+		t.Fatal(err)
+	}
+	var doc gltfDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.SplitN(doc.Buffers[0].URI, ",", 2)[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	prim := doc.Meshes[0].Primitives[0]
+	readFloats := func(name string, n int) [][]float32 {
+		accessor := doc.Accessors[prim.Attributes[name]]
+		view := doc.BufferViews[accessor.BufferView]
+		res := make([][]float32, accessor.Count)
+		for i := range res {
+			res[i] = make([]float32, n)
+			binary.Read(bytes.NewReader(data[view.ByteOffset+i*n*4:]), binary.LittleEndian, res[i])
+		}
+		return res
+	}
+	positions := readFloats("POSITION", 3)
+	uvs := readFloats("TEXCOORD_0", 2)
+	if len(positions) != len(uvs) || len(uvs) == 0 {
+		t.Fatalf("unexpected counts: %d positions and %d UVs", len(positions), len(uvs))
+	}
+
+	// The center of every exported triangle should map back
+	// to the same point through the atlas.
+	for i := 0; i+2 < len(positions); i += 3 {
+		var c model3d.Coord3D
+		var uv model2d.Coord
+		for j := i; j < i+3; j++ {
+			p := positions[j]
+			c = c.Add(model3d.XYZ(float64(p[0]), float64(p[1]), float64(p[2])).Scale(1.0 / 3))
+			uv = uv.Add(model2d.XY(float64(uvs[j][0]), 1-float64(uvs[j][1])).Scale(1.0 / 3))
+		}
+		_, point, ok := atlas.SurfacePoint(uv)
+		if !ok {
+			t.Fatalf("texture coordinate %v is not in the atlas", uv)
+		}
+		if point.Dist(c) > 1e-4 {
+			t.Fatalf("expected point %v but got %v", c, point)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package render3d
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+)
+
+// LoadHDR reads a Radiance RGBE (.hdr) image from a file.
+func LoadHDR(path string) (*Image, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "load HDR")
+	}
+	defer r.Close()
+	img, err := ReadHDR(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "load HDR")
+	}
+	return img, nil
+}
+
+// ReadHDR decodes a Radiance RGBE (.hdr) image.
+//
+// Unlike 8-bit images, the pixels are not gamma corrected
+// and are not limited to the range [0, 1].
+//
+// Only the standard "-Y height +X width" orientation is
+// supported.
+func ReadHDR(r io.Reader) (img *Image, err error) {
+	defer essentials.AddCtxTo("read HDR", &err)
+
+	br := bufio.NewReader(r)
+	firstLine := true
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if firstLine {
+			if !strings.HasPrefix(line, "#?") {
+				return nil, errors.New("missing magic number")
+			}
+			firstLine = false
+		} else if line == "" {
+			break
+		} else if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("unsupported format: %s", line[len("FORMAT="):])
+		}
+	}
+
+	sizeLine, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	var width, height int
+	if _, err := fmt.Sscanf(sizeLine, "-Y %d +X %d", &height, &width); err != nil {
+		return nil, fmt.Errorf("unsupported resolution line: %s", strings.TrimSpace(sizeLine))
+	}
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("invalid image size")
+	}
+
+	img = NewImage(width, height)
+	scanline := make([][4]byte, width)
+	for y := 0; y < height; y++ {
+		if err := readHDRScanline(br, scanline); err != nil {
+			return nil, err
+		}
+		for x, rgbe := range scanline {
+			img.Data[y*width+x] = rgbeToColor(rgbe)
+		}
+	}
+	return img, nil
+}
+
+// WriteHDR encodes an image in the Radiance RGBE (.hdr)
+// format, without run-length encoding.
+func WriteHDR(w io.Writer, img *Image) error {
+	bw := bufio.NewWriter(w)
+	header := fmt.Sprintf("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n",
+		img.Height, img.Width)
+	if _, err := bw.WriteString(header); err != nil {
+		return errors.Wrap(err, "write HDR")
+	}
+	for _, c := range img.Data {
+		rgbe := colorToRGBE(c)
+		if _, err := bw.Write(rgbe[:]); err != nil {
+			return errors.Wrap(err, "write HDR")
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return errors.Wrap(err, "write HDR")
+	}
+	return nil
+}
+
+func readHDRScanline(r *bufio.Reader, out [][4]byte) error {
+	var first [4]byte
+	if _, err := io.ReadFull(r, first[:]); err != nil {
+		return err
+	}
+	width := len(out)
+	if width < 8 || width > 0x7fff || first[0] != 2 || first[1] != 2 || first[2]&0x80 != 0 {
+		// Flat scanline.
+		out[0] = first
+		for i := 1; i < width; i++ {
+			if _, err := io.ReadFull(r, out[i][:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if int(first[2])<<8|int(first[3]) != width {
+		return errors.New("scanline width mismatch")
+	}
+
+	// Each channel is run-length encoded separately.
+	for ch := 0; ch < 4; ch++ {
+		for i := 0; i < width; {
+			count, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+			if count > 128 {
+				n := int(count) - 128
+				value, err := r.ReadByte()
+				if err != nil {
+					return err
+				}
+				if i+n > width {
+					return errors.New("run overflows scanline")
+				}
+				for j := 0; j < n; j++ {
+					out[i][ch] = value
+					i++
+				}
+			} else {
+				n := int(count)
+				if n == 0 || i+n > width {
+					return errors.New("invalid run length")
+				}
+				for j := 0; j < n; j++ {
+					value, err := r.ReadByte()
+					if err != nil {
+						return err
+					}
+					out[i][ch] = value
+					i++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func rgbeToColor(rgbe [4]byte) Color {
+	if rgbe[3] == 0 {
+		return Color{}
+	}
+	f := math.Ldexp(1, int(rgbe[3])-(128+8))
+	return Color{
+		X: (float64(rgbe[0]) + 0.5) * f,
+		Y: (float64(rgbe[1]) + 0.5) * f,
+		Z: (float64(rgbe[2]) + 0.5) * f,
+	}
+}
+
+func colorToRGBE(c Color) [4]byte {
+	c = c.Max(Color{})
+	v := math.Max(c.X, math.Max(c.Y, c.Z))
+	if v < 1e-32 {
+		return [4]byte{}
+	}
+	frac, exp := math.Frexp(v)
+	if exp > 127 {
+		return [4]byte{255, 255, 255, 255}
+	} else if exp <= -128 {
+		return [4]byte{}
+	}
+	scale := frac * 256 / v
+	return [4]byte{
+		byte(math.Min(255, c.X*scale)),
+		byte(math.Min(255, c.Y*scale)),
+		byte(math.Min(255, c.Z*scale)),
+		byte(exp + 128),
+	}
+}
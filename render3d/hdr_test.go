@@ -0,0 +1,69 @@
+package render3d
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestHDRRoundTrip(t *testing.T) {
+	img := NewImage(5, 3)
+	for i := range img.Data {
+		img.Data[i] = Color{X: float64(i) * 0.3, Y: math.Pow(2, float64(i-7)), Z: 100}
+	}
+	img.Data[4] = Color{}
+
+	var buf bytes.Buffer
+	if err := img.Write(&buf, "hdr"); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := ReadHDR(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Width != img.Width || actual.Height != img.Height {
+		t.Fatalf("unexpected size: %dx%d", actual.Width, actual.Height)
+	}
+	for i, expected := range img.Data {
+		a := actual.Data[i]
+		maxComp := math.Max(expected.X, math.Max(expected.Y, expected.Z))
+		for j, x := range a.Sub(expected).Array() {
+			if math.Abs(x) > maxComp/100 {
+				t.Errorf("pixel %d (channel %d): expected %v but got %v", i, j, expected, a)
+			}
+		}
+	}
+}
+
+func TestReadHDRRunLength(t *testing.T) {
+	data := []byte("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\nEXPOSURE=1.0\n\n-Y 1 +X 8\n")
+	data = append(data, 2, 2, 0, 8)
+	// Red: a run of 8.
+	data = append(data, 128+8, 128)
+	// Green: 3 literals, then a run of 5.
+	data = append(data, 3, 0, 64, 128, 128+5, 255)
+	// Blue: all zero.
+	data = append(data, 128+8, 0)
+	// Exponent: 2^1.
+	data = append(data, 128+8, 129)
+
+	img, err := ReadHDR(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedGreen := []float64{0, 64, 128, 255, 255, 255, 255, 255}
+	for i, c := range img.Data {
+		expected := Color{
+			X: 128.5 / 128,
+			Y: (expectedGreen[i] + 0.5) / 128,
+			Z: 0.5 / 128,
+		}
+		if c.Dist(expected) > 1e-8 {
+			t.Errorf("pixel %d: expected %v but got %v", i, expected, c)
+		}
+	}
+
+	if _, err := ReadHDR(bytes.NewReader(data[:len(data)-3])); err == nil {
+		t.Error("expected error for truncated data")
+	}
+}
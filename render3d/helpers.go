@@ -2,6 +2,7 @@ package render3d
 
 import (
 	"math"
+	"math/rand"
 
 	"github.com/unixpickle/model3d/model3d"
 )
@@ -11,6 +12,20 @@ const (
 	helperAmbient     = 0.1
 	helperDiffuse     = 0.8
 	helperSpecular    = 0.2
+	helperMaxDepth    = 3
+
+	// helperSmoothingAngle is the maximum angle between
+	// triangles which are shaded smoothly in meshes.
+	helperSmoothingAngle = math.Pi / 6
+
+	// helperSupersample is the factor by which each
+	// dimension of a grid cell is supersampled to
+	// anti-alias silhouettes.
+	helperSupersample = 3
+
+	// helperAOSamples is the number of ambient occlusion
+	// rays per supersampled pixel.
+	helperAOSamples = 8
 )
 
 // ColorFunc determines a color for collisions on a
@@ -31,6 +46,23 @@ func TriangleColorFunc(f func(t *model3d.Triangle) [3]float64) ColorFunc {
 	}
 }
 
+// VertexColorFunc creates a ColorFunc from a function that
+// colors vertices, smoothly interpolating the colors of
+// each triangle's vertices across its surface.
+//
+// This only works when rendering meshes or triangles.
+func VertexColorFunc(f func(c model3d.Coord3D) [3]float64) ColorFunc {
+	return func(_ model3d.Coord3D, rc model3d.RayCollision) Color {
+		tc := rc.Extra.(*model3d.TriangleCollision)
+		var res Color
+		for i, c := range tc.Triangle {
+			color := f(c)
+			res = res.Add(NewColorRGB(color[0], color[1], color[2]).Scale(tc.Barycentric[i]))
+		}
+		return res
+	}
+}
+
 type colorFuncObject struct {
 	Object
 	ColorFunc ColorFunc
@@ -58,8 +90,11 @@ func (c *colorFuncObject) Cast(r *model3d.Ray) (model3d.RayCollision, Material,
 //
 //     - render3d.Object
 //     - *model3d.Mesh
+//     - *model3d.AttributedMesh
 //     - model3d.Collider
 //
+// Meshes are shaded smoothly, except at sharp edges.
+//
 // The colorFunc is used to color the object's material.
 // If colorFunc is used, a default yellow color is used,
 // unless the object already has an associated material.
@@ -71,22 +106,31 @@ func Objectify(obj interface{}, colorFunc ColorFunc) Object {
 		return &colorFuncObject{
 			Object: &ColliderObject{
 				Collider: obj,
-				Material: &PhongMaterial{
-					Alpha:         10,
-					SpecularColor: NewColor(helperSpecular),
-					DiffuseColor:  NewColorRGB(224.0/255, 209.0/255, 0).Scale(helperDiffuse),
-					AmbientColor:  NewColorRGB(224.0/255, 209.0/255, 0).Scale(helperAmbient),
-				},
+				Material: helperMaterial(),
 			},
 			ColorFunc: colorFunc,
 		}
 	case *model3d.Mesh:
-		return Objectify(model3d.MeshToCollider(obj), colorFunc)
+		return Objectify(model3d.NewAttributedMeshNormals(obj, helperSmoothingAngle), colorFunc)
+	case *model3d.AttributedMesh:
+		return &colorFuncObject{
+			Object:    NewAttributedMeshObject(obj, helperMaterial()),
+			ColorFunc: colorFunc,
+		}
 	default:
 		panic("type not recognized")
 	}
 }
 
+func helperMaterial() Material {
+	return &PhongMaterial{
+		Alpha:         10,
+		SpecularColor: NewColor(helperSpecular),
+		DiffuseColor:  NewColorRGB(224.0/255, 209.0/255, 0).Scale(helperDiffuse),
+		AmbientColor:  NewColorRGB(224.0/255, 209.0/255, 0).Scale(helperAmbient),
+	}
+}
+
 // SaveRendering renders a 3D object from the given point
 // and saves the image to a file.
 //
@@ -99,93 +143,434 @@ func Objectify(obj interface{}, colorFunc ColorFunc) Object {
 // color for the visible parts of the model.
 func SaveRendering(path string, obj interface{}, origin model3d.Coord3D, width, height int,
 	colorFunc ColorFunc) error {
+	return RenderView(obj, origin, width, height, colorFunc).Save(path)
+}
+
+// RenderView is like SaveRendering, but it returns the
+// rendered image instead of saving it, so that it can be
+// written elsewhere with Image.Write.
+func RenderView(obj interface{}, origin model3d.Coord3D, width, height int,
+	colorFunc ColorFunc) *Image {
+	return renderView(obj, origin, width, height, colorFunc, nil)
+}
+
+// SaveRenderingBuffers is like SaveRendering, but it also
+// saves a depth map and a normal map of the rendering.
+//
+// See GeometryBuffers.DepthImage and
+// GeometryBuffers.NormalImage for the format of the maps.
+// If depthPath or normalPath is empty, the corresponding
+// map is not saved.
+func SaveRenderingBuffers(path, depthPath, normalPath string, obj interface{},
+	origin model3d.Coord3D, width, height int, colorFunc ColorFunc) error {
+	img, buffers := RenderViewBuffers(obj, origin, width, height, colorFunc)
+	if err := img.Save(path); err != nil {
+		return err
+	}
+	if depthPath != "" {
+		if err := buffers.DepthImage().Save(depthPath); err != nil {
+			return err
+		}
+	}
+	if normalPath != "" {
+		if err := buffers.NormalImage().Save(normalPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderViewBuffers is like RenderView, but it also
+// returns the depth and normal buffers of the rendering.
+func RenderViewBuffers(obj interface{}, origin model3d.Coord3D, width, height int,
+	colorFunc ColorFunc) (*Image, *GeometryBuffers) {
+	buffers := NewGeometryBuffers(width, height)
+	return renderView(obj, origin, width, height, colorFunc, buffers), buffers
+}
+
+func renderView(obj interface{}, origin model3d.Coord3D, width, height int,
+	colorFunc ColorFunc, buffers *GeometryBuffers) *Image {
+	object := Objectify(obj, colorFunc)
+	image := NewImage(width, height)
+	camera, lights := helperCameraLights(object, origin)
+	caster := RayCaster{
+		Camera:  camera,
+		Lights:  lights,
+		Buffers: buffers,
+	}
+	caster.Render(image, object)
+	return image
+}
+
+// SaveDenoisedRendering is like SaveRendering, but it uses
+// path tracing to include shadows and indirect lighting.
+//
+// Only numSamples rays are traced per pixel, and the
+// result is cleaned up with a BilateralDenoiser, so that
+// previews can be produced quickly.
+func SaveDenoisedRendering(path string, obj interface{}, origin model3d.Coord3D,
+	width, height, numSamples int, colorFunc ColorFunc) error {
+	return RenderViewDenoised(obj, origin, width, height, numSamples, colorFunc).Save(path)
+}
+
+// RenderViewDenoised is like SaveDenoisedRendering, but it
+// returns the rendered image instead of saving it.
+func RenderViewDenoised(obj interface{}, origin model3d.Coord3D, width, height,
+	numSamples int, colorFunc ColorFunc) *Image {
 	object := Objectify(obj, colorFunc)
 	image := NewImage(width, height)
+	camera, lights := helperCameraLights(object, origin)
+	tracer := RecursiveRayTracer{
+		Camera:     camera,
+		Lights:     lights,
+		MaxDepth:   helperMaxDepth,
+		NumSamples: numSamples,
+		Antialias:  1,
+		Cutoff:     1e-4,
+		Denoiser:   &BilateralDenoiser{},
+	}
+	tracer.Render(image, object)
+	return image
+}
 
+func helperCameraLights(object Object, origin model3d.Coord3D) (*PinholeCamera,
+	[]*PointLight) {
 	min, max := object.Min(), object.Max()
 	center := min.Mid(max)
-	caster := RayCaster{
-		Camera: NewCameraAt(origin, center, helperFieldOfView),
-		Lights: []*PointLight{
-			{
-				Origin: center.Add(origin.Sub(center).Scale(1000)),
-				Color:  NewColor(1.0),
-			},
+	camera := NewCameraAt(origin, center, helperFieldOfView)
+	lights := []*PointLight{
+		{
+			Origin: center.Add(origin.Sub(center).Scale(1000)),
+			Color:  NewColor(1.0),
 		},
 	}
-	caster.Render(image, object)
-	return image.Save(path)
+	return camera, lights
 }
 
 // SaveRandomGrid renders a 3D object from a variety of
 // randomized angles and saves the grid of renderings to a
 // file.
 //
+// Each camera is positioned to fit the object within its
+// cell, and each cell is supersampled to smooth out the
+// edges of the object.
+// By default, this casts nine rays per output pixel; use
+// SaveRandomGridOptions with GridOptions.Supersample set
+// to 1 for faster, aliased renderings.
+// For meshes, the camera is fit to the vertices of the
+// mesh rather than to its bounding box, so that thin or
+// diagonal models are not rendered overly small.
+//
 // The obj argument must be supported by Objectify.
 //
 // If colorFunc is non-nil, it is used to determine the
 // color for the visible parts of the model.
 func SaveRandomGrid(path string, obj interface{}, rows, cols, imgSize int,
 	colorFunc ColorFunc) error {
+	return RenderRandomGrid(obj, rows, cols, imgSize, colorFunc).Save(path)
+}
+
+// RenderRandomGrid is like SaveRandomGrid, but it returns
+// the rendered grid instead of saving it, so that it can
+// be written elsewhere with Image.Write.
+func RenderRandomGrid(obj interface{}, rows, cols, imgSize int, colorFunc ColorFunc) *Image {
+	return RenderRandomGridOptions(obj, rows, cols, imgSize, colorFunc, nil)
+}
+
+// SaveRandomGridAO is like SaveRandomGrid, but it also
+// approximates ambient occlusion by casting rays around
+// each visible point.
+//
+// This darkens cavities and corners, making the shape of
+// the object easier to see than with direct lighting
+// alone, at the cost of slower rendering.
+func SaveRandomGridAO(path string, obj interface{}, rows, cols, imgSize int,
+	colorFunc ColorFunc) error {
+	return RenderRandomGridAO(obj, rows, cols, imgSize, colorFunc).Save(path)
+}
+
+// RenderRandomGridAO is like SaveRandomGridAO, but it
+// returns the rendered grid instead of saving it.
+func RenderRandomGridAO(obj interface{}, rows, cols, imgSize int, colorFunc ColorFunc) *Image {
+	return RenderRandomGridOptions(obj, rows, cols, imgSize, colorFunc,
+		&GridOptions{AOSamples: helperAOSamples})
+}
+
+// GridOptions customizes the views in grids of renderings,
+// such as those produced by SaveRandomGridOptions and
+// SaveViewGrid.
+//
+// The zero value is equivalent to the behavior of
+// SaveRandomGrid.
+type GridOptions struct {
+	// Seed, if non-zero, is used to choose random view
+	// directions, so that grids are reproducible.
+	//
+	// If 0, the global random source is used.
+	Seed int64
+
+	// Distance, if non-zero, is the distance from each
+	// camera to the center of the object.
+	//
+	// If 0, each camera is fit to the object.
+	Distance float64
+
+	// MinElevation and MaxElevation restrict random view
+	// directions to a range of angles above the xy-plane,
+	// in radians.
+	//
+	// If both are 0, view directions are uniformly random
+	// over the entire sphere.
+	MinElevation float64
+	MaxElevation float64
+
+	// Lights, if non-nil, are used to light every view.
+	//
+	// If nil, each view is lit from the direction of the
+	// camera.
+	Lights []*PointLight
+
+	// AOSamples, if non-zero, is the number of ambient
+	// occlusion rays per supersampled pixel.
+	// See RayCaster for details.
+	AOSamples int
+
+	// Supersample, if non-zero, is the factor by which each
+	// dimension of a cell is supersampled, so the cost of
+	// rendering grows with its square.
+	// A value of 1 disables supersampling.
+	//
+	// If 0, a default of 3 is used.
+	Supersample int
+}
+
+func (g *GridOptions) supersample() int {
+	if g.Supersample == 0 {
+		return helperSupersample
+	}
+	return g.Supersample
+}
+
+// RandomDirections samples n random view directions
+// according to the options.
+func (g *GridOptions) RandomDirections(n int) []model3d.Coord3D {
+	if g == nil {
+		g = &GridOptions{}
+	}
+	var gen *rand.Rand
+	if g.Seed != 0 {
+		gen = rand.New(rand.NewSource(g.Seed))
+	}
+	res := make([]model3d.Coord3D, n)
+	for i := range res {
+		if g.MinElevation == 0 && g.MaxElevation == 0 {
+			if gen == nil {
+				res[i] = model3d.NewCoord3DRandUnit()
+			} else {
+				res[i] = model3d.XYZ(gen.NormFloat64(), gen.NormFloat64(),
+					gen.NormFloat64()).Normalize()
+			}
+			continue
+		}
+		var u, v float64
+		if gen == nil {
+			u, v = rand.Float64(), rand.Float64()
+		} else {
+			u, v = gen.Float64(), gen.Float64()
+		}
+		// Sampling z uniformly gives directions that are
+		// uniform over the band of the sphere.
+		minZ, maxZ := math.Sin(g.MinElevation), math.Sin(g.MaxElevation)
+		z := minZ + (maxZ-minZ)*u
+		r := math.Sqrt(math.Max(0, 1-z*z))
+		theta := 2 * math.Pi * v
+		res[i] = model3d.XYZ(r*math.Cos(theta), r*math.Sin(theta), z)
+	}
+	return res
+}
+
+// SaveRandomGridOptions is like SaveRandomGrid, but with
+// options to control the views.
+//
+// If opts is nil, this is equivalent to SaveRandomGrid.
+func SaveRandomGridOptions(path string, obj interface{}, rows, cols, imgSize int,
+	colorFunc ColorFunc, opts *GridOptions) error {
+	return RenderRandomGridOptions(obj, rows, cols, imgSize, colorFunc, opts).Save(path)
+}
+
+// RenderRandomGridOptions is like SaveRandomGridOptions,
+// but it returns the rendered grid instead of saving it.
+func RenderRandomGridOptions(obj interface{}, rows, cols, imgSize int, colorFunc ColorFunc,
+	opts *GridOptions) *Image {
+	directions := opts.RandomDirections(rows * cols)
+	return RenderViewGrid(obj, directions, cols, imgSize, colorFunc, opts)
+}
+
+// SaveViewGrid renders a 3D object from the given view
+// directions and saves the grid of renderings to a file.
+//
+// Each direction points from the object towards the
+// camera, like for FitCamera, and needn't be normalized.
+// The views are arranged in rows of cols images, and the
+// last row may be partially empty.
+//
+// The opts argument may be nil, and its random view
+// options are ignored.
+// Otherwise, this is like SaveRandomGrid.
+func SaveViewGrid(path string, obj interface{}, directions []model3d.Coord3D, cols,
+	imgSize int, colorFunc ColorFunc, opts *GridOptions) error {
+	return RenderViewGrid(obj, directions, cols, imgSize, colorFunc, opts).Save(path)
+}
+
+// RenderViewGrid is like SaveViewGrid, but it returns the
+// rendered grid instead of saving it.
+func RenderViewGrid(obj interface{}, directions []model3d.Coord3D, cols, imgSize int,
+	colorFunc ColorFunc, opts *GridOptions) *Image {
+	if opts == nil {
+		opts = &GridOptions{}
+	}
+	supersample := opts.supersample()
 	object := Objectify(obj, colorFunc)
+	rows := (len(directions) + cols - 1) / cols
 	fullOutput := NewImage(cols*imgSize, rows*imgSize)
 
 	min, max := object.Min(), object.Max()
 	center := min.Mid(max)
+	points := framingPoints(obj, object)
 
-	for i := 0; i < rows; i++ {
-		for j := 0; j < cols; j++ {
-			direction := model3d.NewCoord3DRandUnit()
-			caster := &RayCaster{
-				Camera: directionalCamera(object, direction),
-				Lights: []*PointLight{
-					{
-						Origin: center.Add(direction.Scale(1000)),
-						Color:  NewColor(1.0),
-					},
+	for i, direction := range directions {
+		direction = direction.Normalize()
+		var camera *PinholeCamera
+		if opts.Distance != 0 {
+			camera = NewCameraAt(center.Add(direction.Scale(opts.Distance)), center,
+				helperFieldOfView)
+		} else {
+			camera = fitCamera(points, helperFieldOfView, direction)
+		}
+		lights := opts.Lights
+		if lights == nil {
+			lights = []*PointLight{
+				{
+					Origin: center.Add(direction.Scale(1000)),
+					Color:  NewColor(1.0),
 				},
 			}
-			subImage := NewImage(imgSize, imgSize)
-			caster.Render(subImage, object)
-			fullOutput.CopyFrom(subImage, j*imgSize, i*imgSize)
 		}
+		caster := &RayCaster{
+			Camera:    camera,
+			Lights:    lights,
+			AOSamples: opts.AOSamples,
+		}
+		subImage := NewImage(imgSize*supersample, imgSize*supersample)
+		caster.Render(subImage, object)
+		fullOutput.CopyFrom(subImage.Downsample(supersample), (i%cols)*imgSize,
+			(i/cols)*imgSize)
 	}
 
-	return fullOutput.Save(path)
+	return fullOutput
 }
 
-// directionalCamera figures out where to move a camera in
-// the given unit direction to capture the bounding box of
-// an object.
-func directionalCamera(object Object, direction model3d.Coord3D) *Camera {
-	min, max := object.Min(), object.Max()
-	baseline := min.Dist(max)
-	center := min.Mid(max)
+// SaveCSGProvenanceGrid is like SaveRandomGrid, but it
+// renders a mesh of a CSG solid with each triangle colored
+// by the direct child of the solid which produced it.
+//
+// This can be used to debug boolean operations which
+// produce unexpected geometry.
+// The epsilon argument is passed to
+// model3d.CSGProvenanceColor.
+func SaveCSGProvenanceGrid(path string, solid model3d.Solid, mesh *model3d.Mesh,
+	epsilon float64, rows, cols, imgSize int) error {
+	colorFunc := TriangleColorFunc(model3d.CSGProvenanceColor(solid, epsilon, 1))
+	return SaveRandomGrid(path, mesh, rows, cols, imgSize, colorFunc)
+}
+
+// SaveCrossSectionGrid renders a grid of images of a 3D
+// object with progressively deeper cuts through it, and
+// saves the grid to a file.
+//
+// Each cut is a plane orthogonal to axis, and removes the
+// part of the object furthest along axis.
+// The first image shows the entire object, and later
+// images cut further into it.
+// The faces created by the cuts are colored red, so that
+// internal features such as threads and cavities stand
+// out from the inner surfaces of the model.
+//
+// All of the images are rendered from the same viewpoint,
+// looking at the cut faces from an angle.
+//
+// The obj argument must be supported by Objectify, and
+// must be closed for the cut faces to be filled in.
+//
+// If colorFunc is non-nil, it is used to determine the
+// color for the visible parts of the model.
+func SaveCrossSectionGrid(path string, obj interface{}, axis model3d.Coord3D, rows, cols,
+	imgSize int, colorFunc ColorFunc) error {
+	return RenderCrossSectionGrid(obj, axis, rows, cols, imgSize, colorFunc).Save(path)
+}
+
+// RenderCrossSectionGrid is like SaveCrossSectionGrid,
+// but it returns the rendered grid instead of saving it.
+func RenderCrossSectionGrid(obj interface{}, axis model3d.Coord3D, rows, cols, imgSize int,
+	colorFunc ColorFunc) *Image {
+	object := Objectify(obj, colorFunc)
+	fullOutput := NewImage(cols*imgSize, rows*imgSize)
 
-	margin := 0.05
-	minDist := baseline * 1e-4
-	maxDist := baseline * 1e4
-	for i := 0; i < 32; i++ {
-		d := (minDist + maxDist) / 2
-		cam := NewCameraAt(center.Add(direction.Scale(d)), center, helperFieldOfView)
-		uncaster := cam.Uncaster(1, 1)
-		contained := true
-		for _, x := range []float64{min.X, max.X} {
-			for _, y := range []float64{min.Y, max.Y} {
-				for _, z := range []float64{min.Z, max.Z} {
-					sx, sy := uncaster(model3d.XYZ(x, y, z))
-					if sx < margin || sy < margin || sx >= 1-margin || sy >= 1-margin {
-						contained = false
-					}
-				}
+	axis = axis.Normalize()
+	points := framingPoints(obj, object)
+	minDot, maxDot := math.Inf(1), math.Inf(-1)
+	for _, p := range boundsCorners(object) {
+		minDot = math.Min(minDot, p.Dot(axis))
+		maxDot = math.Max(maxDot, p.Dot(axis))
+	}
+
+	b1, b2 := axis.OrthoBasis()
+	direction := axis.Add(b1.Scale(0.7)).Add(b2.Scale(0.4)).Normalize()
+	camera := fitCamera(points, helperFieldOfView, direction)
+	center := object.Min().Mid(object.Max())
+	lights := []*PointLight{
+		{
+			Origin: center.Add(direction.Scale(1000)),
+			Color:  NewColor(1.0),
+		},
+	}
+	capColor := NewColorRGB(0.9, 0.15, 0.1)
+	capMaterial := &PhongMaterial{
+		Alpha:         10,
+		SpecularColor: NewColor(helperSpecular),
+		DiffuseColor:  capColor.Scale(helperDiffuse),
+		AmbientColor:  capColor.Scale(helperAmbient),
+	}
+
+	numCells := rows * cols
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			frac := float64(numCells-(i*cols+j)) / float64(numCells)
+			clipped := &ClippedObject{
+				Object: object,
+				Plane: &model3d.Plane{
+					Normal: axis,
+					Offset: minDot + (maxDot-minDot)*frac,
+				},
+				CapMaterial: capMaterial,
 			}
-		}
-		if contained {
-			maxDist = d
-		} else {
-			minDist = d
+			caster := &RayCaster{Camera: camera, Lights: lights}
+			subImage := NewImage(imgSize*helperSupersample, imgSize*helperSupersample)
+			caster.Render(subImage, clipped)
+			fullOutput.CopyFrom(subImage.Downsample(helperSupersample), j*imgSize, i*imgSize)
 		}
 	}
 
-	return NewCameraAt(center.Add(direction.Scale(maxDist)), center, helperFieldOfView)
+	return fullOutput
+}
+
+// framingPoints gets the points which a camera must see
+// to capture the entire object.
+func framingPoints(obj interface{}, object Object) []model3d.Coord3D {
+	if mesh, ok := obj.(*model3d.Mesh); ok {
+		if vertices := mesh.VertexSlice(); len(vertices) > 0 {
+			return vertices
+		}
+	}
+	return boundsCorners(object)
 }
@@ -0,0 +1,100 @@
+package render3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestGridOptionsRandomDirections(t *testing.T) {
+	opts := &GridOptions{Seed: 1337, MinElevation: 0.2, MaxElevation: 0.6}
+	dirs := opts.RandomDirections(100)
+	for i, d := range opts.RandomDirections(100) {
+		if d != dirs[i] {
+			t.Fatal("directions are not reproducible")
+		}
+		if math.Abs(d.Norm()-1) > 1e-8 {
+			t.Fatalf("direction is not normalized: %v", d)
+		}
+		elevation := math.Asin(d.Z)
+		if elevation < 0.2-1e-8 || elevation > 0.6+1e-8 {
+			t.Fatalf("unexpected elevation: %f", elevation)
+		}
+	}
+
+	opts = &GridOptions{Seed: 1337}
+	var sum model3d.Coord3D
+	for _, d := range opts.RandomDirections(1000) {
+		sum = sum.Add(d)
+	}
+	if sum.Norm()/1000 > 0.1 {
+		t.Errorf("directions are not uniform: mean %v", sum.Scale(1.0/1000))
+	}
+}
+
+func TestRenderViewGrid(t *testing.T) {
+	box := &model3d.Rect{MinVal: model3d.XYZ(-1, -1, -1), MaxVal: model3d.XYZ(1, 2, 1)}
+	directions := []model3d.Coord3D{
+		model3d.X(1), model3d.Y(-1), model3d.Z(1), model3d.XYZ(1, 1, 1), model3d.X(-1),
+	}
+	img := RenderViewGrid(box, directions, 2, 10, nil, nil)
+	if img.Width != 20 || img.Height != 30 {
+		t.Fatalf("unexpected size: %dx%d", img.Width, img.Height)
+	}
+	cellSum := func(row, col int) float64 {
+		var sum float64
+		for y := row * 10; y < (row+1)*10; y++ {
+			for x := col * 10; x < (col+1)*10; x++ {
+				sum += img.Data[y*img.Width+x].Sum()
+			}
+		}
+		return sum
+	}
+	for i := range directions {
+		if cellSum(i/2, i%2) == 0 {
+			t.Errorf("view %d is empty", i)
+		}
+	}
+	if cellSum(2, 1) != 0 {
+		t.Error("unused cell is not empty")
+	}
+}
+
+func TestRenderRandomGridOptions(t *testing.T) {
+	box := &model3d.Rect{MinVal: model3d.XYZ(-1, -1, -1), MaxVal: model3d.XYZ(1, 2, 1)}
+	opts := &GridOptions{Seed: 1, Distance: 10}
+	img1 := RenderRandomGridOptions(box, 2, 2, 8, nil, opts)
+	img2 := RenderRandomGridOptions(box, 2, 2, 8, nil, opts)
+	for i, c := range img1.Data {
+		if c != img2.Data[i] {
+			t.Fatal("renderings are not reproducible")
+		}
+	}
+}
+
+func TestRenderViewGridSupersample(t *testing.T) {
+	box := &model3d.Rect{MinVal: model3d.XYZ(-1, -1, -1), MaxVal: model3d.XYZ(1, 2, 1)}
+	directions := []model3d.Coord3D{model3d.XYZ(1, 1, 1), model3d.XYZ(-1, 2, 1)}
+	img1 := RenderViewGrid(box, directions, 2, 20, nil, &GridOptions{Supersample: 1})
+	img3 := RenderViewGrid(box, directions, 2, 20, nil, nil)
+	if img1.Width != 40 || img1.Height != 20 {
+		t.Fatalf("unexpected size: %dx%d", img1.Width, img1.Height)
+	}
+	var sum1, sum3 float64
+	var different bool
+	for i, c := range img1.Data {
+		sum1 += c.Sum()
+		sum3 += img3.Data[i].Sum()
+		different = different || c != img3.Data[i]
+	}
+	if sum1 == 0 {
+		t.Error("rendering without supersampling is empty")
+	}
+	if math.Abs(sum1-sum3) > 0.1*sum3 {
+		t.Errorf("unexpected brightness %f (expected roughly %f)", sum1, sum3)
+	}
+	if !different {
+		t.Error("supersampling had no effect")
+	}
+}
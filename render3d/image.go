@@ -6,6 +6,7 @@ import (
 	"image/color"
 	"image/jpeg"
 	"image/png"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
@@ -30,6 +31,42 @@ func NewImage(width, height int) *Image {
 	}
 }
 
+// NewImageFromStd creates an image from a standard
+// library image, converting sRGB values to linear colors.
+func NewImageFromStd(img image.Image) *Image {
+	bounds := img.Bounds()
+	res := NewImage(bounds.Dx(), bounds.Dy())
+	var idx int
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			res.Data[idx] = NewColorRGB(float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff)
+			idx++
+		}
+	}
+	return res
+}
+
+// LoadImage reads a PNG or JPEG image from a file.
+//
+// If the file has a .hdr extension, it is read with
+// LoadHDR.
+func LoadImage(path string) (*Image, error) {
+	if strings.ToLower(filepath.Ext(path)) == ".hdr" {
+		return LoadHDR(path)
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "load image")
+	}
+	defer r.Close()
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "load image")
+	}
+	return NewImageFromStd(img), nil
+}
+
 // CopyFrom copies the image img into this image at the
 // given coordinates x, y in i.
 //
@@ -45,6 +82,29 @@ func (i *Image) CopyFrom(i1 *Image, x, y int) {
 	}
 }
 
+// Downsample creates a smaller image by averaging each
+// factor x factor block of pixels.
+//
+// If the dimensions are not divisible by factor, the
+// remaining rows and columns are dropped.
+func (i *Image) Downsample(factor int) *Image {
+	res := NewImage(i.Width/factor, i.Height/factor)
+	scale := 1 / float64(factor*factor)
+	for y := 0; y < res.Height; y++ {
+		for x := 0; x < res.Width; x++ {
+			var sum Color
+			for subY := 0; subY < factor; subY++ {
+				rowIdx := (y*factor+subY)*i.Width + x*factor
+				for _, c := range i.Data[rowIdx : rowIdx+factor] {
+					sum = sum.Add(c)
+				}
+			}
+			res.Data[y*res.Width+x] = sum.Scale(scale)
+		}
+	}
+	return res
+}
+
 // FillRange scales the color values so that the largest
 // color component is exactly 1.
 func (i *Image) FillRange() {
@@ -125,25 +185,45 @@ func (i *Image) Gray() *image.Gray {
 // Save saves the image to a file.
 //
 // It uses the extension to determine the type.
-// Use either .png, .jpg, or .jpeg.
+// Use either .png, .jpg, .jpeg, or .hdr.
 func (i *Image) Save(path string) error {
 	ext := strings.ToLower(filepath.Ext(path))
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".hdr" {
 		return fmt.Errorf("save image: unknown extension '%s'", ext)
 	}
 	w, err := os.Create(path)
 	if err != nil {
 		return errors.Wrap(err, "save image")
 	}
-	defer w.Close()
-	rgba := i.RGBA()
-	if ext == ".png" {
-		err = png.Encode(w, rgba)
-	} else {
-		err = jpeg.Encode(w, rgba, nil)
+	if err := i.Write(w, ext[1:]); err != nil {
+		w.Close()
+		return errors.Wrap(err, "save image")
 	}
-	if err != nil {
+	if err := w.Close(); err != nil {
 		return errors.Wrap(err, "save image")
 	}
 	return nil
 }
+
+// Write encodes the image to w.
+//
+// The format may be "png", "jpg", "jpeg", or "hdr".
+// Only the "hdr" format preserves colors outside of the
+// range [0, 1].
+func (i *Image) Write(w io.Writer, format string) error {
+	var err error
+	switch strings.ToLower(format) {
+	case "png":
+		err = png.Encode(w, i.RGBA())
+	case "jpg", "jpeg":
+		err = jpeg.Encode(w, i.RGBA(), nil)
+	case "hdr":
+		err = WriteHDR(w, i)
+	default:
+		return fmt.Errorf("write image: unknown format '%s'", format)
+	}
+	if err != nil {
+		return errors.Wrap(err, "write image")
+	}
+	return nil
+}
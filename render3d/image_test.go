@@ -0,0 +1,43 @@
+package render3d
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestImageDownsample(t *testing.T) {
+	img := NewImage(5, 4)
+	for i := range img.Data {
+		img.Data[i] = NewColor(float64(i))
+	}
+	small := img.Downsample(2)
+	if small.Width != 2 || small.Height != 2 {
+		t.Fatalf("unexpected size: %dx%d", small.Width, small.Height)
+	}
+	expected := []float64{3, 5, 13, 15}
+	for i, x := range expected {
+		if actual := small.Data[i]; actual != NewColor(x) {
+			t.Errorf("pixel %d: expected %f but got %v", i, x, actual)
+		}
+	}
+}
+
+func TestImageWrite(t *testing.T) {
+	img := NewImage(3, 2)
+	img.Data[1] = NewColor(1)
+	var buf bytes.Buffer
+	if err := img.Write(&buf, "png"); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := decoded.Bounds().Size(); size.X != 3 || size.Y != 2 {
+		t.Errorf("unexpected size: %v", size)
+	}
+	if err := img.Write(&buf, "bmp"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,244 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const DefaultFisheyeFieldOfView = math.Pi
+
+// An OrthographicCamera renders a parallel projection,
+// where every ray of sight faces the same direction.
+//
+// This is useful for technical views, since distances
+// along the viewing plane do not depend on depth.
+type OrthographicCamera struct {
+	// Origin is the center of the viewing plane, from
+	// which rays of sight originate.
+	Origin model3d.Coord3D
+
+	// ScreenX and ScreenY are the (normalized) directions
+	// in 3D space that are rendered along the x-axis and
+	// y-axis in images, like for a PinholeCamera.
+	ScreenX model3d.Coord3D
+	ScreenY model3d.Coord3D
+
+	// Size is the distance covered by the longer side of
+	// an image.
+	Size float64
+}
+
+// NewOrthographicCameraAt creates an OrthographicCamera
+// that is looking at a point from another point.
+//
+// The size argument is the distance covered by the
+// longer side of an image.
+func NewOrthographicCameraAt(source, dest model3d.Coord3D,
+	size float64) *OrthographicCamera {
+	xAxis, yAxis := screenAxes(source, dest)
+	return &OrthographicCamera{
+		Origin:  source,
+		ScreenX: xAxis,
+		ScreenY: yAxis,
+		Size:    size,
+	}
+}
+
+// FitOrthographicCamera creates an OrthographicCamera
+// which looks at an object from the given direction, with
+// the object's bounding box fully in view with a small
+// margin.
+//
+// The direction points from the object towards the
+// camera, and needn't be normalized.
+func FitOrthographicCamera(bounds model3d.Bounder,
+	direction model3d.Coord3D) *OrthographicCamera {
+	points := boundsCorners(bounds)
+	direction = direction.Normalize()
+	center, baseline := projectedCenter(points, direction)
+	cam := NewOrthographicCameraAt(center.Add(direction.Scale(baseline+1)), center, 0)
+	var maxOffset float64
+	for _, p := range points {
+		offset := p.Sub(center)
+		maxOffset = math.Max(maxOffset, math.Abs(offset.Dot(cam.ScreenX)))
+		maxOffset = math.Max(maxOffset, math.Abs(offset.Dot(cam.ScreenY)))
+	}
+	cam.Size = 2 * maxOffset / (1 - 2*fitCameraMargin)
+	if cam.Size == 0 {
+		cam.Size = 1
+	}
+	return cam
+}
+
+// Rays produces a function that casts parallel rays from
+// points on the viewing plane.
+func (o *OrthographicCamera) Rays(imageWidth, imageHeight float64) func(gen *rand.Rand, x,
+	y float64) model3d.Ray {
+	x, y := o.ScreenX.Scale(o.Size/2), o.ScreenY.Scale(o.Size/2)
+	if imageWidth > imageHeight {
+		y = y.Scale(imageHeight / imageWidth)
+	} else {
+		x = x.Scale(imageWidth / imageHeight)
+	}
+	forward := o.forward()
+	cx, cy := imageWidth/2, imageHeight/2
+	return func(gen *rand.Rand, imgX, imgY float64) model3d.Ray {
+		outX := x.Scale((imgX - cx) / cx)
+		outY := y.Scale((imgY - cy) / cy)
+		return model3d.Ray{
+			Origin:    o.Origin.Add(outX).Add(outY),
+			Direction: forward,
+		}
+	}
+}
+
+// Depth computes the distance from the viewing plane to a
+// point.
+func (o *OrthographicCamera) Depth(coord model3d.Coord3D) float64 {
+	return coord.Sub(o.Origin).Dot(o.forward())
+}
+
+func (o *OrthographicCamera) forward() model3d.Coord3D {
+	return o.ScreenX.Cross(o.ScreenY).Normalize()
+}
+
+// A ThinLensCamera is a PinholeCamera with a lens of
+// non-zero size, simulating depth of field.
+//
+// Objects on the focal plane appear sharp, while objects
+// in front of or behind it are blurred.
+// Since rays are sampled randomly across the lens,
+// renderers must take many samples per pixel.
+type ThinLensCamera struct {
+	PinholeCamera
+
+	// Aperture is the diameter of the lens.
+	// Larger apertures give more blur.
+	Aperture float64
+
+	// FocalDistance is the distance from the camera to
+	// the plane which is in focus, measured along the
+	// viewing direction.
+	FocalDistance float64
+}
+
+// NewThinLensCameraAt creates a ThinLensCamera that is
+// looking at a point from another point, with the point
+// in focus.
+//
+// If fov is 0, DefaultFieldOfView is used.
+func NewThinLensCameraAt(source, dest model3d.Coord3D, fov,
+	aperture float64) *ThinLensCamera {
+	return &ThinLensCamera{
+		PinholeCamera: *NewCameraAt(source, dest, fov),
+		Aperture:      aperture,
+		FocalDistance: dest.Dist(source),
+	}
+}
+
+// Rays produces a function that casts rays from random
+// points on the lens towards the focal plane.
+func (t *ThinLensCamera) Rays(imageWidth, imageHeight float64) func(gen *rand.Rand, x,
+	y float64) model3d.Ray {
+	caster := t.Caster(imageWidth, imageHeight)
+	forward := t.forward()
+	return func(gen *rand.Rand, x, y float64) model3d.Ray {
+		direction := caster(x, y)
+		if t.Aperture == 0 {
+			return model3d.Ray{Origin: t.Origin, Direction: direction}
+		}
+
+		// Scale the direction so that it reaches the focal
+		// plane at a scale of 1.
+		direction = direction.Scale(t.FocalDistance / math.Abs(direction.Dot(forward)))
+		focus := t.Origin.Add(direction)
+
+		radius := t.Aperture / 2 * math.Sqrt(gen.Float64())
+		theta := 2 * math.Pi * gen.Float64()
+		origin := t.Origin.Add(t.ScreenX.Scale(radius * math.Cos(theta))).Add(
+			t.ScreenY.Scale(radius * math.Sin(theta)),
+		)
+		return model3d.Ray{Origin: origin, Direction: focus.Sub(origin)}
+	}
+}
+
+// A FisheyeCamera renders an equidistant fisheye
+// projection, where the angle between a ray and the
+// viewing direction is proportional to the distance from
+// the center of the image.
+//
+// Unlike a PinholeCamera, this can capture fields of view
+// of 180 degrees or more.
+type FisheyeCamera struct {
+	// Origin is the location of the camera, from whence
+	// lines of sight originate.
+	Origin model3d.Coord3D
+
+	// ScreenX and ScreenY are the (normalized) directions
+	// in 3D space that are rendered along the x-axis and
+	// y-axis in images, like for a PinholeCamera.
+	ScreenX model3d.Coord3D
+	ScreenY model3d.Coord3D
+
+	// FieldOfView is the angle spanned by the longer side
+	// of an image.
+	//
+	// This is measured in radians.
+	FieldOfView float64
+}
+
+// NewFisheyeCameraAt creates a FisheyeCamera that is
+// looking at a point from another point.
+//
+// If fov is 0, DefaultFisheyeFieldOfView is used.
+func NewFisheyeCameraAt(source, dest model3d.Coord3D, fov float64) *FisheyeCamera {
+	if fov == 0 {
+		fov = DefaultFisheyeFieldOfView
+	}
+	xAxis, yAxis := screenAxes(source, dest)
+	return &FisheyeCamera{
+		Origin:      source,
+		ScreenX:     xAxis,
+		ScreenY:     yAxis,
+		FieldOfView: fov,
+	}
+}
+
+// Rays produces a function that casts rays from the
+// origin in every direction within the field of view.
+func (f *FisheyeCamera) Rays(imageWidth, imageHeight float64) func(gen *rand.Rand, x,
+	y float64) model3d.Ray {
+	scaleX, scaleY := 1.0, 1.0
+	if imageWidth > imageHeight {
+		scaleY = imageHeight / imageWidth
+	} else {
+		scaleX = imageWidth / imageHeight
+	}
+	forward := f.ScreenX.Cross(f.ScreenY).Normalize()
+	cx, cy := imageWidth/2, imageHeight/2
+	return func(gen *rand.Rand, imgX, imgY float64) model3d.Ray {
+		px := scaleX * (imgX - cx) / cx
+		py := scaleY * (imgY - cy) / cy
+		r := math.Sqrt(px*px + py*py)
+		if r == 0 {
+			return model3d.Ray{Origin: f.Origin, Direction: forward}
+		}
+		theta := r * f.FieldOfView / 2
+		radial := f.ScreenX.Scale(px / r).Add(f.ScreenY.Scale(py / r))
+		return model3d.Ray{
+			Origin:    f.Origin,
+			Direction: forward.Scale(math.Cos(theta)).Add(radial.Scale(math.Sin(theta))),
+		}
+	}
+}
+
+// Depth computes the distance from the camera to a point.
+//
+// Unlike for other cameras, this is not measured along
+// the viewing direction, since the field of view may
+// include points behind the camera.
+func (f *FisheyeCamera) Depth(coord model3d.Coord3D) float64 {
+	return coord.Dist(f.Origin)
+}
@@ -0,0 +1,130 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestOrthographicCamera(t *testing.T) {
+	camera := NewOrthographicCameraAt(model3d.Y(-5), model3d.Coord3D{}, 4)
+	sphere := &model3d.Sphere{Radius: 1}
+	buffers := RenderGeometryBuffers(camera, Objectify(sphere, nil), 33, 17)
+
+	rays := camera.Rays(32, 16)
+	for y := 0; y < 17; y++ {
+		for x := 0; x < 33; x++ {
+			ray := rays(nil, float64(x), float64(y))
+			if ray.Direction.Dist(model3d.Y(1)) > 1e-8 {
+				t.Fatalf("unexpected direction: %v", ray.Direction)
+			}
+			offset := ray.Origin.XZ().Norm()
+			depth := buffers.Depth[y*33+x]
+			if offset < 0.99 {
+				expected := 5 - math.Sqrt(1-offset*offset)
+				if math.Abs(depth-expected) > 1e-5 {
+					t.Fatalf("pixel %d,%d: expected depth %f but got %f", x, y, expected,
+						depth)
+				}
+			} else if offset > 1.01 && !math.IsInf(depth, 1) {
+				t.Fatalf("pixel %d,%d: unexpected depth %f", x, y, depth)
+			}
+		}
+	}
+
+	// The longer side of the image spans the size.
+	if d := rays(nil, 0, 8).Origin.Dist(rays(nil, 32, 8).Origin); math.Abs(d-4) > 1e-8 {
+		t.Errorf("unexpected width: %f", d)
+	}
+}
+
+func TestFitOrthographicCamera(t *testing.T) {
+	box := &model3d.Rect{MinVal: model3d.XYZ(1, 2, 3), MaxVal: model3d.XYZ(2, 5, 4)}
+	for i := 0; i < 10; i++ {
+		cam := FitOrthographicCamera(box, model3d.NewCoord3DRandNorm())
+		basis := [2]model3d.Coord3D{cam.ScreenX, cam.ScreenY}
+		forward := cam.ScreenX.Cross(cam.ScreenY)
+		minMargin := math.Inf(1)
+		for _, p := range boundsCorners(box) {
+			if cam.Depth(p) <= 0 {
+				t.Fatal("point behind camera")
+			}
+			offset := p.Sub(cam.Origin).ProjectOut(forward)
+			for _, axis := range basis {
+				frac := 0.5 + offset.Dot(axis)/cam.Size
+				margin := math.Min(frac, 1-frac)
+				if margin < fitCameraMargin-1e-8 {
+					t.Fatalf("point outside of margin: %f", margin)
+				}
+				minMargin = math.Min(minMargin, margin)
+			}
+		}
+		if minMargin > fitCameraMargin+1e-8 {
+			t.Errorf("camera is not tight: margin %f", minMargin)
+		}
+	}
+}
+
+func TestThinLensCamera(t *testing.T) {
+	gen := rand.New(rand.NewSource(1337))
+	source, dest := model3d.XYZ(1, -5, 2), model3d.XYZ(0.5, 0.3, 0.1)
+	camera := NewThinLensCameraAt(source, dest, math.Pi/3, 0.5)
+	pinhole := NewCameraAt(source, dest, math.Pi/3)
+	rays := camera.Rays(20, 10)
+	pinholeRays := pinhole.Rays(20, 10)
+
+	for i := 0; i < 100; i++ {
+		x, y := gen.Float64()*20, gen.Float64()*10
+		expected := pinholeRays(gen, x, y)
+
+		// Every ray through a pixel should meet at a single
+		// point on the focal plane.
+		var focus model3d.Coord3D
+		var maxOriginDist float64
+		for j := 0; j < 10; j++ {
+			ray := rays(gen, x, y)
+			maxOriginDist = math.Max(maxOriginDist, ray.Origin.Dist(source))
+			if ray.Origin.Dist(source) > camera.Aperture/2+1e-8 {
+				t.Fatal("ray origin outside of lens")
+			}
+			p := ray.Origin.Add(ray.Direction)
+			if j == 0 {
+				focus = p
+			} else if p.Dist(focus) > 1e-8 {
+				t.Fatalf("inconsistent focal point: %v and %v", p, focus)
+			}
+		}
+		if maxOriginDist < 1e-3 {
+			t.Error("ray origins were not sampled across the lens")
+		}
+		if math.Abs(camera.Depth(focus)-camera.FocalDistance) > 1e-8 {
+			t.Errorf("unexpected focal depth: %f", camera.Depth(focus))
+		}
+		dir := focus.Sub(source).Normalize()
+		if dir.Dist(expected.Direction.Normalize()) > 1e-8 {
+			t.Errorf("focal point is not on the pinhole ray")
+		}
+	}
+}
+
+func TestFisheyeCamera(t *testing.T) {
+	camera := NewFisheyeCameraAt(model3d.Coord3D{}, model3d.Y(1), math.Pi*1.5)
+	rays := camera.Rays(20, 10)
+	if d := rays(nil, 10, 5).Direction; d.Dist(model3d.Y(1)) > 1e-8 {
+		t.Errorf("unexpected center direction: %v", d)
+	}
+	for _, x := range []float64{0, 20} {
+		d := rays(nil, x, 5).Direction
+		angle := math.Acos(d.Normalize().Dot(model3d.Y(1)))
+		if math.Abs(angle-math.Pi*0.75) > 1e-8 {
+			t.Errorf("unexpected edge angle: %f", angle)
+		}
+	}
+	d := rays(nil, 10, 0).Direction
+	angle := math.Acos(d.Normalize().Dot(model3d.Y(1)))
+	if math.Abs(angle-math.Pi*0.375) > 1e-8 {
+		t.Errorf("unexpected top angle: %f", angle)
+	}
+}
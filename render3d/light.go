@@ -211,7 +211,7 @@ func (c *CylinderAreaLight) SampleLight(gen *rand.Rand) (point, normal model3d.C
 	} else {
 		// Sample the shaft.
 		y := gen.Float64()
-		point = c.cylinder.P1.Add(unscaledAxis.Scale(y)).Add(radialPart)
+		point = c.cylinder.P1.Add(unscaledAxis.Scale(y)).Add(radialPart.Scale(c.cylinder.Radius))
 		normal = radialPart
 	}
 	emission = c.emission
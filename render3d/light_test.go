@@ -0,0 +1,37 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestCylinderAreaLightSampleLight(t *testing.T) {
+	cyl := &model3d.Cylinder{P1: model3d.XYZ(1, 2, 3), P2: model3d.XYZ(2, 4, 1), Radius: 0.3}
+	light := NewCylinderAreaLight(cyl, NewColor(1))
+	axis := cyl.P2.Sub(cyl.P1).Normalize()
+	gen := rand.New(rand.NewSource(1337))
+	for i := 0; i < 1000; i++ {
+		point, normal, _ := light.SampleLight(gen)
+		if math.Abs(normal.Norm()-1) > 1e-8 {
+			t.Fatalf("normal is not normalized: %v", normal)
+		}
+		offset := point.Sub(cyl.P1)
+		along := offset.Dot(axis)
+		radial := offset.Sub(axis.Scale(along)).Norm()
+		if math.Abs(normal.Dot(axis)) > 1-1e-8 {
+			// Point on one of the flat faces.
+			if math.Abs(along) > 1e-8 && math.Abs(along-cyl.P1.Dist(cyl.P2)) > 1e-8 {
+				t.Fatalf("point %v is not on a face", point)
+			}
+			if radial > cyl.Radius+1e-8 {
+				t.Fatalf("point %v is outside of a face", point)
+			}
+		} else if math.Abs(radial-cyl.Radius) > 1e-8 {
+			t.Fatalf("point %v is at distance %f from the axis, expected %f", point,
+				radial, cyl.Radius)
+		}
+	}
+}
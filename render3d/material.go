@@ -478,6 +478,187 @@ func (r *RefractMaterial) Ambient() Color {
 	return Color{}
 }
 
+// An AbsorbingMaterial is a Material on the surface of a
+// medium which absorbs light passing through it, such as
+// tinted glass.
+//
+// Renderers which support absorption attenuate light that
+// travels through the inside of an object, i.e. along a ray
+// that exits the object through a surface with this
+// material.
+// For this to work, objects should be closed and have
+// outward-facing normals.
+type AbsorbingMaterial interface {
+	Material
+
+	// Transmittance gets the fraction of light which is
+	// not absorbed after traveling a given distance
+	// through the medium.
+	Transmittance(distance float64) Color
+}
+
+// DielectricMaterial is a smooth, transparent material,
+// such as glass or water.
+//
+// Light is refracted according to Snell's law, and is
+// reflected in proportion to the Fresnel equations.
+// Like RefractMaterial, the BSDF is based on delta
+// functions, so the material must be importance sampled.
+//
+// Light traveling through the material is absorbed
+// according to the Beer-Lambert law.
+type DielectricMaterial struct {
+	// IndexOfRefraction is the index of refraction of
+	// this material, relative to the outside medium.
+	IndexOfRefraction float64
+
+	// RefractColor is the mask used for refracted flux.
+	// Typically, this is white, and the tint of the
+	// material is determined by Absorption.
+	RefractColor Color
+
+	// ReflectColor is the mask used for reflected flux.
+	// Typically, this is white.
+	ReflectColor Color
+
+	// Absorption is the fraction of light absorbed per
+	// unit distance traveled through the material, for
+	// each color channel.
+	// After a distance d, a fraction exp(-Absorption*d) of
+	// the light remains.
+	Absorption Color
+}
+
+func (d *DielectricMaterial) BSDF(normal, source, dest model3d.Coord3D) Color {
+	reflectAmount := d.reflectance(normal, source)
+
+	// Scale the delta functions so that the flux is
+	// weighted exactly by the reflection or refraction
+	// amount.
+	// eps/2 is the spanned fraction of the sphere for
+	// which we return non-zero.
+	scale := 2 / (cosineEpsilon * math.Max(cosineEpsilon, math.Abs(source.Dot(normal))))
+
+	var res Color
+	if dest.Dot(d.reflect(normal, source)) >= 1-cosineEpsilon {
+		res = res.Add(d.ReflectColor.Scale(reflectAmount * scale))
+	}
+	if refracted, ok := d.refract(normal, source); ok && dest.Dot(refracted) >= 1-cosineEpsilon {
+		res = res.Add(d.RefractColor.Scale((1 - reflectAmount) * scale))
+	}
+	return res
+}
+
+// SampleSource chooses between reflection and refraction
+// according to the Fresnel equations, and then samples the
+// source deterministically.
+func (d *DielectricMaterial) SampleSource(gen *rand.Rand, normal,
+	dest model3d.Coord3D) model3d.Coord3D {
+	// Trace the path backwards, since light paths are
+	// reversible.
+	reverse := dest.Scale(-1)
+	if gen.Float64() < d.reflectance(normal, reverse) {
+		return d.reflect(normal, reverse).Scale(-1)
+	}
+	refracted, _ := d.refract(normal, reverse)
+	return refracted.Scale(-1)
+}
+
+func (d *DielectricMaterial) SourceDensity(normal, source, dest model3d.Coord3D) float64 {
+	reverse := dest.Scale(-1)
+	reflectAmount := d.reflectance(normal, reverse)
+	var density float64
+	if source.Dot(d.reflect(normal, reverse).Scale(-1)) >= 1-cosineEpsilon {
+		density += reflectAmount
+	}
+	refracted, ok := d.refract(normal, reverse)
+	if ok && source.Dot(refracted.Scale(-1)) >= 1-cosineEpsilon {
+		density += 1 - reflectAmount
+	}
+	return density * 2 / cosineEpsilon
+}
+
+func (d *DielectricMaterial) SampleDest(gen *rand.Rand, normal,
+	source model3d.Coord3D) model3d.Coord3D {
+	return d.SampleSource(gen, normal.Scale(-1), source)
+}
+
+func (d *DielectricMaterial) DestDensity(normal, source, dest model3d.Coord3D) float64 {
+	return d.SourceDensity(normal.Scale(-1), dest, source)
+}
+
+func (d *DielectricMaterial) Emission() Color {
+	return Color{}
+}
+
+func (d *DielectricMaterial) Ambient() Color {
+	return Color{}
+}
+
+// Transmittance computes the Beer-Lambert attenuation for
+// light traveling the given distance through the material.
+func (d *DielectricMaterial) Transmittance(distance float64) Color {
+	return Color{
+		X: math.Exp(-d.Absorption.X * distance),
+		Y: math.Exp(-d.Absorption.Y * distance),
+		Z: math.Exp(-d.Absorption.Z * distance),
+	}
+}
+
+// reflect computes the direction of a ray after it
+// reflects off of the surface.
+func (d *DielectricMaterial) reflect(normal, direction model3d.Coord3D) model3d.Coord3D {
+	return direction.Sub(normal.Scale(2 * normal.Dot(direction)))
+}
+
+// refract computes the direction of a ray after it passes
+// through the surface, or returns false if the ray is
+// totally internally reflected.
+func (d *DielectricMaterial) refract(normal, direction model3d.Coord3D) (model3d.Coord3D,
+	bool) {
+	cosIn, eta := d.incidence(normal, direction)
+	sin2Out := eta * eta * (1 - cosIn*cosIn)
+	if sin2Out > 1 {
+		return model3d.Coord3D{}, false
+	}
+	cosOut := math.Sqrt(1 - sin2Out)
+	if normal.Dot(direction) > 0 {
+		normal = normal.Scale(-1)
+	}
+	return direction.Scale(eta).Add(normal.Scale(eta*cosIn - cosOut)), true
+}
+
+// reflectance computes the fraction of unpolarized light
+// which is reflected for a ray hitting the surface.
+//
+// The result is the same for both directions of a
+// refracted path.
+func (d *DielectricMaterial) reflectance(normal, direction model3d.Coord3D) float64 {
+	// https://en.wikipedia.org/wiki/Fresnel_equations
+	cosIn, eta := d.incidence(normal, direction)
+	sin2Out := eta * eta * (1 - cosIn*cosIn)
+	if sin2Out >= 1 {
+		// Total internal reflection.
+		return 1
+	}
+	cosOut := math.Sqrt(1 - sin2Out)
+	rs := (eta*cosIn - cosOut) / (eta*cosIn + cosOut)
+	rp := (eta*cosOut - cosIn) / (eta*cosOut + cosIn)
+	return math.Min(1, (rs*rs+rp*rp)/2)
+}
+
+// incidence gets the cosine of the angle between a ray and
+// the normal, and the ratio of the index of refraction the
+// ray is leaving to the one it is entering.
+func (d *DielectricMaterial) incidence(normal, direction model3d.Coord3D) (float64, float64) {
+	cos := normal.Dot(direction)
+	if cos < 0 {
+		// The ray is entering the material.
+		return math.Min(1, -cos), 1 / d.IndexOfRefraction
+	}
+	return math.Min(1, cos), d.IndexOfRefraction
+}
+
 // HGMaterial implements the Henyey-Greenstein phase
 // function for ray scattering.
 //
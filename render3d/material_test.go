@@ -78,6 +78,104 @@ func TestRefractMaterialAsym(t *testing.T) {
 	}
 }
 
+func TestDielectricMaterialBSDF(t *testing.T) {
+	for _, ior := range []float64{1.3, 1 / 1.3} {
+		testMaterialEnergyConservation(t, &DielectricMaterial{
+			IndexOfRefraction: ior,
+			RefractColor:      NewColor(1),
+			ReflectColor:      NewColor(1),
+		})
+	}
+}
+
+func TestDielectricMaterialFresnel(t *testing.T) {
+	mat := &DielectricMaterial{IndexOfRefraction: 1.5}
+	normal := model3d.Z(1)
+
+	// At normal incidence, R = ((n1-n2)/(n1+n2))^2.
+	for _, dir := range []model3d.Coord3D{model3d.Z(-1), model3d.Z(1)} {
+		if r := mat.reflectance(normal, dir); math.Abs(r-0.04) > 1e-8 {
+			t.Errorf("unexpected reflectance %f for %v", r, dir)
+		}
+	}
+
+	// Snell's law, and agreement between both directions
+	// of the same path.
+	source := model3d.XYZ(0.5, 0.2, -1).Normalize()
+	refracted, ok := mat.refract(normal, source)
+	if !ok {
+		t.Fatal("unexpected total internal reflection")
+	}
+	sinIn := source.ProjectOut(normal).Norm()
+	sinOut := refracted.ProjectOut(normal).Norm()
+	if math.Abs(sinIn/sinOut-1.5) > 1e-8 {
+		t.Errorf("unexpected sine ratio: %f", sinIn/sinOut)
+	}
+	if r1, r2 := mat.reflectance(normal, source),
+		mat.reflectance(normal, refracted.Scale(-1)); math.Abs(r1-r2) > 1e-8 {
+		t.Errorf("reflectance mismatch: %f vs %f", r1, r2)
+	}
+
+	// Beyond the critical angle, all light is reflected.
+	inside := model3d.XYZ(0.9, 0, 0.3).Normalize()
+	if _, ok := mat.refract(normal, inside); ok {
+		t.Error("expected total internal reflection")
+	}
+	if r := mat.reflectance(normal, inside); r != 1 {
+		t.Errorf("unexpected reflectance: %f", r)
+	}
+}
+
+func TestDielectricMaterialAsym(t *testing.T) {
+	mat := &DielectricMaterial{
+		IndexOfRefraction: 1.3,
+		RefractColor:      NewColor(1),
+		ReflectColor:      NewColor(1),
+	}
+	gen := rand.New(rand.NewSource(1337))
+	for i := 0; i < 5000; i++ {
+		normal := model3d.NewCoord3DRandUnit()
+		source := model3d.NewCoord3DRandUnit()
+		dest := mat.SampleDest(gen, normal, source)
+		if mat.DestDensity(normal, source, dest) == 0 {
+			t.Fatal("zero density", normal.Dot(source), normal.Dot(dest))
+		}
+		if mat.SourceDensity(normal, source, dest) == 0 {
+			t.Fatal("zero source density")
+		}
+		if mat.BSDF(normal, source, dest).X == 0 {
+			t.Fatal("zero BSDF")
+		}
+	}
+}
+
+func TestDielectricMaterialAbsorption(t *testing.T) {
+	img := NewImage(1, 1)
+	img.Data[0] = NewColor(1)
+	tracer := &RecursiveRayTracer{
+		Environment: NewEnvironmentLight(img),
+		MaxDepth:    5,
+	}
+	mat := &DielectricMaterial{
+		// With no change in the index of refraction, no
+		// light is reflected.
+		IndexOfRefraction: 1,
+		RefractColor:      NewColor(1),
+		ReflectColor:      NewColor(1),
+		Absorption:        Color{X: 0.1, Y: 0.5, Z: 1},
+	}
+	obj := &ColliderObject{
+		Collider: &model3d.Rect{MinVal: model3d.XYZ(-1, -1, 0), MaxVal: model3d.XYZ(1, 1, 2)},
+		Material: mat,
+	}
+	ray := &model3d.Ray{Origin: model3d.XYZ(0.1, 0.2, -1), Direction: model3d.Z(1)}
+	actual := tracer.recurse(rand.New(rand.NewSource(1337)), obj, ray, 0, NewColor(1), 0)
+	expected := mat.Transmittance(2)
+	if actual.Dist(expected) > 1e-3 {
+		t.Errorf("expected %v but got %v", expected, actual)
+	}
+}
+
 func TestHGMaterialBSDF(t *testing.T) {
 	for _, g := range []float64{-0.9, -0.5, 0, 0.5, 0.9} {
 		t.Run(fmt.Sprintf("G%.1f", g), func(t *testing.T) {
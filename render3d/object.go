@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"sort"
 
+	"github.com/unixpickle/model3d/model2d"
 	"github.com/unixpickle/model3d/model3d"
 )
 
@@ -24,6 +25,9 @@ type Object interface {
 
 // A ColliderObject wraps a model3d.Collider in the Object
 // interface, using a constant material.
+//
+// If the material is a SpatialMaterial, it is evaluated at
+// every collision, without texture coordinates.
 type ColliderObject struct {
 	Collider model3d.Collider
 	Material Material
@@ -42,7 +46,104 @@ func (c *ColliderObject) Max() model3d.Coord3D {
 // Cast returns the first ray collision.
 func (c *ColliderObject) Cast(r *model3d.Ray) (model3d.RayCollision, Material, bool) {
 	coll, ok := c.Collider.FirstRayCollision(r)
-	return coll, c.Material, ok
+	if !ok {
+		return coll, nil, false
+	}
+	return coll, resolveMaterial(c.Material, r, coll, nil), true
+}
+
+// An AttributedMeshObject is an Object for a mesh with
+// per-vertex normals and colors.
+//
+// Normals are interpolated across each triangle for smooth
+// shading, and colors are interpolated to determine the
+// diffuse color at each point.
+// Triangles without attributes at all of their corners are
+// rendered with their flat normals or the object's
+// Material, respectively.
+//
+// If the Material is a SpatialMaterial, it receives the
+// interpolated texture coordinates at each collision.
+type AttributedMeshObject struct {
+	// Collider must be made up of the triangles which the
+	// attributes refer to, such as a collider created by
+	// model3d.MeshToCollider.
+	Collider   model3d.Collider
+	Attributes *model3d.MeshAttributes
+	Material   Material
+}
+
+// NewAttributedMeshObject creates an AttributedMeshObject
+// for a mesh.
+func NewAttributedMeshObject(m *model3d.AttributedMesh, mat Material) *AttributedMeshObject {
+	return &AttributedMeshObject{
+		Collider:   model3d.MeshToCollider(m.Mesh),
+		Attributes: m.Attributes,
+		Material:   mat,
+	}
+}
+
+// Min gets the minimum of the bounding box.
+func (a *AttributedMeshObject) Min() model3d.Coord3D {
+	return a.Collider.Min()
+}
+
+// Max gets the maximum of the bounding box.
+func (a *AttributedMeshObject) Max() model3d.Coord3D {
+	return a.Collider.Max()
+}
+
+// Cast returns the first ray collision, with the normal
+// and material determined by the attributes.
+func (a *AttributedMeshObject) Cast(r *model3d.Ray) (model3d.RayCollision, Material, bool) {
+	coll, ok := a.Collider.FirstRayCollision(r)
+	if !ok {
+		return coll, nil, false
+	}
+	mat := a.Material
+	tc, ok := coll.Extra.(*model3d.TriangleCollision)
+	if !ok {
+		return coll, resolveMaterial(mat, r, coll, nil), true
+	}
+
+	var normal model3d.Coord3D
+	var color Color
+	var uv model2d.Coord
+	hasNormals, hasColors, hasUVs := true, true, true
+	for i, w := range tc.Barycentric {
+		if n, ok := a.Attributes.Normal(tc.Triangle, i); ok {
+			normal = normal.Add(n.Scale(w))
+		} else {
+			hasNormals = false
+		}
+		if c, ok := a.Attributes.Color(tc.Triangle, i); ok {
+			color = color.Add(NewColorRGB(c[0], c[1], c[2]).Scale(w))
+		} else {
+			hasColors = false
+		}
+		if c, ok := a.Attributes.UV(tc.Triangle, i); ok {
+			uv = uv.Add(c.Scale(w))
+		} else {
+			hasUVs = false
+		}
+	}
+	if hasNormals && normal.Norm() > 0 {
+		coll.Normal = normal.Normalize()
+	}
+	if hasUVs {
+		mat = resolveMaterial(mat, r, coll, &uv)
+	} else {
+		mat = resolveMaterial(mat, r, coll, nil)
+	}
+	if hasColors {
+		mat = &PhongMaterial{
+			Alpha:         10,
+			SpecularColor: NewColor(helperSpecular),
+			DiffuseColor:  color.Scale(helperDiffuse),
+			AmbientColor:  color.Scale(helperAmbient),
+		}
+	}
+	return coll, mat, true
 }
 
 // ParticipatingMedium is a volume in which a ray has a
@@ -151,3 +252,70 @@ func (j JoinedObject) Cast(r *model3d.Ray) (model3d.RayCollision, Material, bool
 	}
 	return coll, mat, found
 }
+
+// A ClippedObject is an Object with everything on one side
+// of a plane removed, which can be used to look inside of
+// a model.
+//
+// Points where Plane.SignedDist() is positive are removed.
+//
+// If CapMaterial is non-nil, the cut is filled in with a
+// flat face of this material wherever the plane passes
+// through the inside of the object.
+// This requires the object to be closed, so that hitting
+// the back of a surface indicates that the ray started
+// inside the object.
+type ClippedObject struct {
+	Object      Object
+	Plane       *model3d.Plane
+	CapMaterial Material
+}
+
+// Min gets the minimum of the bounding box.
+func (c *ClippedObject) Min() model3d.Coord3D {
+	return c.Object.Min()
+}
+
+// Max gets the maximum of the bounding box.
+func (c *ClippedObject) Max() model3d.Coord3D {
+	return c.Object.Max()
+}
+
+// Cast casts the ray onto the part of the object which is
+// not clipped away.
+func (c *ClippedObject) Cast(r *model3d.Ray) (model3d.RayCollision, Material, bool) {
+	dist := c.Plane.SignedDist(r.Origin)
+	slope := r.Direction.Dot(c.Plane.Normal)
+
+	// Find the range of scales where the ray is on the
+	// kept side of the plane.
+	minScale, maxScale := 0.0, math.Inf(1)
+	if dist > 0 {
+		if slope >= 0 {
+			return model3d.RayCollision{}, nil, false
+		}
+		minScale = -dist / slope
+	} else if slope > 0 {
+		maxScale = -dist / slope
+	}
+
+	shifted := &model3d.Ray{
+		Origin:    r.Origin.Add(r.Direction.Scale(minScale)),
+		Direction: r.Direction,
+	}
+	coll, mat, ok := c.Object.Cast(shifted)
+	if !ok {
+		return coll, mat, ok
+	}
+	coll.Scale += minScale
+	if coll.Scale > maxScale {
+		return model3d.RayCollision{}, nil, false
+	}
+	if minScale > 0 && c.CapMaterial != nil && coll.Normal.Dot(r.Direction) > 0 {
+		return model3d.RayCollision{
+			Scale:  minScale,
+			Normal: c.Plane.Normal,
+		}, c.CapMaterial, true
+	}
+	return coll, mat, true
+}
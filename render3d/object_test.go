@@ -0,0 +1,95 @@
+package render3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestClippedObject(t *testing.T) {
+	capMaterial := &LambertMaterial{}
+	obj := &ClippedObject{
+		Object: &ColliderObject{
+			Collider: &model3d.Sphere{Radius: 1},
+			Material: &PhongMaterial{},
+		},
+		Plane:       &model3d.Plane{Normal: model3d.Z(1), Offset: 0.5},
+		CapMaterial: capMaterial,
+	}
+
+	// Looking down through the cut should hit the cap.
+	rc, mat, ok := obj.Cast(&model3d.Ray{Origin: model3d.Z(3), Direction: model3d.Z(-1)})
+	if !ok || mat != capMaterial {
+		t.Fatal("expected cap collision")
+	}
+	if math.Abs(rc.Scale-2.5) > 1e-8 || rc.Normal != model3d.Z(1) {
+		t.Errorf("unexpected cap collision: %v", rc)
+	}
+
+	// Looking up from below should hit the sphere.
+	rc, mat, ok = obj.Cast(&model3d.Ray{Origin: model3d.Z(-3), Direction: model3d.Z(1)})
+	if !ok || mat == capMaterial || math.Abs(rc.Scale-2) > 1e-8 {
+		t.Errorf("unexpected collision: %v", rc)
+	}
+
+	// Rays which only hit the clipped part should miss.
+	ray := &model3d.Ray{Origin: model3d.XYZ(-3, 0, 0.8), Direction: model3d.X(1)}
+	if _, _, ok := obj.Cast(ray); ok {
+		t.Error("unexpected collision in clipped region")
+	}
+
+	// Rays leaving the kept region should not see the
+	// clipped part.
+	ray = &model3d.Ray{Origin: model3d.XYZ(0, 0, 0.4), Direction: model3d.XYZ(1, 0, 1)}
+	if _, _, ok := obj.Cast(ray); ok {
+		t.Error("unexpected collision after leaving kept region")
+	}
+
+	// Without a cap, the back of the sphere is visible.
+	obj.CapMaterial = nil
+	rc, mat, ok = obj.Cast(&model3d.Ray{Origin: model3d.Z(3), Direction: model3d.Z(-1)})
+	if !ok || mat == capMaterial || math.Abs(rc.Scale-4) > 1e-8 {
+		t.Errorf("unexpected collision: %v", rc)
+	}
+}
+
+func TestAttributedMeshObject(t *testing.T) {
+	mesh := model3d.NewMeshIcosphere(model3d.Coord3D{}, 1, 2)
+	attrMesh := model3d.NewAttributedMesh(mesh)
+	mesh.IterateVertices(func(c model3d.Coord3D) {
+		attrMesh.Attributes.Normals[c] = c.Normalize()
+		if c.Z > 0 {
+			attrMesh.Attributes.Colors[c] = [3]float64{0, 1, 0}
+		}
+	})
+	baseMat := &LambertMaterial{}
+	obj := NewAttributedMeshObject(attrMesh, baseMat)
+
+	for i := 0; i < 100; i++ {
+		dir := model3d.NewCoord3DRandUnit()
+		ray := &model3d.Ray{Origin: dir.Scale(-3), Direction: dir}
+		rc, mat, ok := obj.Cast(ray)
+		if !ok {
+			t.Fatal("expected collision")
+		}
+		point := ray.Origin.Add(ray.Direction.Scale(rc.Scale))
+		flatNormal := rc.Extra.(*model3d.TriangleCollision).Triangle.Normal()
+		actual := point.Normalize()
+		if rc.Normal.Dist(actual) > flatNormal.Dist(actual)+1e-8 {
+			t.Errorf("interpolated normal %v is worse than flat normal %v", rc.Normal,
+				flatNormal)
+		}
+
+		tri := rc.Extra.(*model3d.TriangleCollision).Triangle
+		allTop := tri[0].Z > 0 && tri[1].Z > 0 && tri[2].Z > 0
+		if allTop {
+			phong, ok := mat.(*PhongMaterial)
+			if !ok || phong.DiffuseColor.X != 0 || phong.DiffuseColor.Y <= 0 {
+				t.Errorf("unexpected material for colored triangle: %v", mat)
+			}
+		} else if mat != baseMat {
+			t.Errorf("unexpected material for uncolored triangle: %v", mat)
+		}
+	}
+}
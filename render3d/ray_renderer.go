@@ -2,6 +2,7 @@ package render3d
 
 import (
 	"math"
+	"math/rand"
 
 	"github.com/unixpickle/essentials"
 	"github.com/unixpickle/model3d/model3d"
@@ -12,7 +13,7 @@ import (
 type rayRenderer struct {
 	RayColor func(g *goInfo, obj Object, ray *model3d.Ray) Color
 
-	Camera               *Camera
+	Camera               Camera
 	NumSamples           int
 	MinSamples           int
 	MaxStddev            float64
@@ -20,6 +21,9 @@ type rayRenderer struct {
 	Convergence          func(mean, stddev Color) bool
 	Antialias            float64
 	LogFunc              func(frac float64, sampleRate float64)
+	Logger               model3d.Logger
+	Buffers              *GeometryBuffers
+	Denoiser             Denoiser
 }
 
 func (r *rayRenderer) Render(img *Image, obj Object) {
@@ -28,39 +32,60 @@ func (r *rayRenderer) Render(img *Image, obj Object) {
 	}
 	maxX := float64(img.Width) - 1
 	maxY := float64(img.Height) - 1
-	caster := r.Camera.Caster(maxX, maxY)
+	rays := r.Camera.Rays(maxX, maxY)
+
+	buffers := r.Buffers
+	if buffers == nil && r.Denoiser != nil {
+		buffers = NewGeometryBuffers(img.Width, img.Height)
+	}
+	if buffers != nil {
+		buffers.resize(img.Width, img.Height)
+		buffers.render(r.Camera, obj)
+	}
 
 	progressCh := make(chan int, 1)
 	go func() {
 		mapCoordinates(img.Width, img.Height, func(g *goInfo, x, y, idx int) {
-			color, numSamples := r.estimateColor(g, obj, float64(x), float64(y), caster)
+			color, variance, numSamples := r.estimateColor(g, obj, float64(x), float64(y),
+				rays)
 			img.Data[idx] = color
+			if buffers != nil {
+				buffers.Variance[idx] = variance
+			}
 			progressCh <- numSamples
 		})
 		close(progressCh)
 	}()
 
+	phase := model3d.StartLogPhase(r.Logger, "render")
+	defer phase.End()
+
 	updateInterval := essentials.MaxInt(1, img.Width*img.Height/1000)
 	var pixelsComplete int
 	var samplesTaken int
 	for n := range progressCh {
-		if r.LogFunc != nil {
-			pixelsComplete++
-			samplesTaken += n
-			if pixelsComplete%updateInterval == 0 {
-				r.LogFunc(float64(pixelsComplete)/float64(img.Width*img.Height),
-					float64(samplesTaken)/float64(pixelsComplete))
+		pixelsComplete++
+		samplesTaken += n
+		if pixelsComplete%updateInterval == 0 {
+			frac := float64(pixelsComplete) / float64(img.Width*img.Height)
+			if r.LogFunc != nil {
+				r.LogFunc(frac, float64(samplesTaken)/float64(pixelsComplete))
 			}
+			phase.Progress(frac)
 		}
 	}
+
+	if r.Denoiser != nil {
+		copy(img.Data, r.Denoiser.Denoise(img, buffers).Data)
+	}
 }
 
 func (r *rayRenderer) RenderVariance(img *Image, obj Object, numSamples int) {
 	maxX := float64(img.Width) - 1
 	maxY := float64(img.Height) - 1
-	caster := r.Camera.Caster(maxX, maxY)
+	rays := r.Camera.Rays(maxX, maxY)
 	mapCoordinates(img.Width, img.Height, func(g *goInfo, x, y, idx int) {
-		img.Data[idx] = r.estimateVariance(g, obj, float64(x), float64(y), caster,
+		img.Data[idx] = r.estimateVariance(g, obj, float64(x), float64(y), rays,
 			numSamples)
 	})
 }
@@ -85,17 +110,11 @@ func (r *rayRenderer) RayVariance(obj Object, width, height, samples int) float6
 }
 
 func (r *rayRenderer) estimateVariance(g *goInfo, obj Object, x, y float64,
-	caster func(x, y float64) model3d.Coord3D, numSamples int) Color {
-	ray := model3d.Ray{Origin: r.Camera.Origin}
-	ray.Direction = caster(x, y)
+	rays func(gen *rand.Rand, x, y float64) model3d.Ray, numSamples int) Color {
 	var colorSum Color
 	var colorSqSum Color
 	for i := 0; i < numSamples; i++ {
-		if r.Antialias != 0 {
-			dx := r.Antialias * (g.Gen.Float64() - 0.5)
-			dy := r.Antialias * (g.Gen.Float64() - 0.5)
-			ray.Direction = caster(x+dx, y+dy)
-		}
+		ray := r.sampleRay(g, x, y, rays)
 		sampleColor := r.RayColor(g, obj, &ray)
 		colorSum = colorSum.Add(sampleColor)
 		colorSqSum = colorSqSum.Add(sampleColor.Mul(sampleColor))
@@ -110,27 +129,21 @@ func (r *rayRenderer) estimateVariance(g *goInfo, obj Object, x, y float64,
 }
 
 func (r *rayRenderer) estimateColor(g *goInfo, obj Object, x, y float64,
-	caster func(x, y float64) model3d.Coord3D) (sampleMean Color, numSamples int) {
-	ray := model3d.Ray{Origin: r.Camera.Origin}
-	ray.Direction = caster(x, y)
+	rays func(gen *rand.Rand, x, y float64) model3d.Ray) (sampleMean, meanVariance Color,
+	numSamples int) {
 	var colorSum Color
 	var colorSqSum Color
 
 	for numSamples = 0; numSamples < r.NumSamples; numSamples++ {
-		if r.Antialias != 0 {
-			dx := r.Antialias * (g.Gen.Float64() - 0.5)
-			dy := r.Antialias * (g.Gen.Float64() - 0.5)
-			ray.Direction = caster(x+dx, y+dy)
-		}
+		ray := r.sampleRay(g, x, y, rays)
 		sampleColor := r.RayColor(g, obj, &ray)
 		colorSum = colorSum.Add(sampleColor)
+		colorSqSum = colorSqSum.Add(sampleColor.Mul(sampleColor))
 
 		if !r.HasConvergenceCheck() {
 			continue
 		}
 
-		colorSqSum = colorSqSum.Add(sampleColor.Mul(sampleColor))
-
 		if numSamples < r.MinSamples || numSamples < 2 {
 			continue
 		}
@@ -147,7 +160,26 @@ func (r *rayRenderer) estimateColor(g *goInfo, obj Object, x, y float64,
 			break
 		}
 	}
-	return colorSum.Scale(1 / float64(numSamples)), numSamples
+	sampleMean = colorSum.Scale(1 / float64(numSamples))
+	if numSamples > 1 {
+		// Bessel's correction, followed by the variance of
+		// the mean of numSamples samples.
+		n := float64(numSamples)
+		meanVariance = colorSqSum.Scale(1 / n).Sub(sampleMean.Mul(sampleMean))
+		meanVariance = meanVariance.Max(Color{}).Scale(1 / (n - 1))
+	}
+	return sampleMean, meanVariance, numSamples
+}
+
+// sampleRay samples a ray for a pixel, jittering the
+// pixel coordinates for anti-aliasing.
+func (r *rayRenderer) sampleRay(g *goInfo, x, y float64,
+	rays func(gen *rand.Rand, x, y float64) model3d.Ray) model3d.Ray {
+	if r.Antialias != 0 {
+		x += r.Antialias * (g.Gen.Float64() - 0.5)
+		y += r.Antialias * (g.Gen.Float64() - 0.5)
+	}
+	return rays(g.Gen, x, y)
 }
 
 func (r *rayRenderer) HasConvergenceCheck() bool {
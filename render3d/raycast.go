@@ -4,36 +4,110 @@ import (
 	"github.com/unixpickle/model3d/model3d"
 )
 
+// DefaultAODistanceFraction is the default maximum
+// distance of occluders for ambient occlusion, as a
+// fraction of the diagonal of the object's bounding box.
+const DefaultAODistanceFraction = 0.2
+
 // A RayCaster renders objects using simple one-step ray
 // tracing with no recursion.
 type RayCaster struct {
-	Camera *Camera
+	Camera Camera
 	Lights []*PointLight
+
+	// AOSamples, if non-zero, is the number of rays used to
+	// approximate ambient occlusion at each point.
+	//
+	// Ambient occlusion darkens crevices and points close
+	// to other surfaces, giving a better sense of depth
+	// than direct lighting alone.
+	AOSamples int
+
+	// AODistance is the maximum distance at which a surface
+	// can occlude a point.
+	//
+	// If 0, DefaultAODistanceFraction of the diagonal of the
+	// object's bounding box is used.
+	AODistance float64
+
+	// Epsilon is a small distance used to move away from
+	// surfaces when casting ambient occlusion rays.
+	//
+	// If 0, DefaultEpsilon is used.
+	Epsilon float64
+
+	// Buffers, if non-nil, is filled in with the depth,
+	// normal, and albedo of the first surface seen through
+	// each pixel whenever an image is rendered.
+	//
+	// The buffers are resized to match the image.
+	Buffers *GeometryBuffers
 }
 
 // Render renders the object to an image.
 func (r *RayCaster) Render(img *Image, obj Object) {
 	maxX := float64(img.Width) - 1
 	maxY := float64(img.Height) - 1
-	caster := r.Camera.Caster(maxX, maxY)
+	rays := r.Camera.Rays(maxX, maxY)
+
+	aoDistance := r.AODistance
+	if aoDistance == 0 {
+		aoDistance = obj.Max().Dist(obj.Min()) * DefaultAODistanceFraction
+	}
+
+	if r.Buffers != nil {
+		r.Buffers.resize(img.Width, img.Height)
+		r.Buffers.render(r.Camera, obj)
+	}
 
 	mapCoordinates(img.Width, img.Height, func(g *goInfo, x, y, idx int) {
-		ray := model3d.Ray{
-			Origin:    r.Camera.Origin,
-			Direction: caster(float64(x), float64(y)),
-		}
+		ray := rays(g.Gen, float64(x), float64(y))
 		collision, material, ok := obj.Cast(&ray)
 		if !ok {
 			return
 		}
 		point := ray.Origin.Add(ray.Direction.Scale(collision.Scale))
-		color := material.Ambient().Add(material.Emission())
+		color := material.Ambient()
 		for _, l := range r.Lights {
 			brdf := material.BSDF(collision.Normal, point.Sub(l.Origin).Normalize(),
 				ray.Origin.Sub(point).Normalize())
 			p2l := l.Origin.Sub(point)
 			color = color.Add(l.ShadeCollision(collision.Normal, p2l).Mul(brdf))
 		}
-		img.Data[idx] = color
+		if r.AOSamples > 0 {
+			color = color.Scale(r.ambientVisibility(g, obj, &ray, collision, aoDistance))
+		}
+		img.Data[idx] = color.Add(material.Emission())
 	})
 }
+
+// ambientVisibility estimates the fraction of the
+// hemisphere around a collision which is not blocked by
+// nearby surfaces, weighted by the cosine of each
+// direction with the normal.
+func (r *RayCaster) ambientVisibility(g *goInfo, obj Object, ray *model3d.Ray,
+	rc model3d.RayCollision, maxDist float64) float64 {
+	eps := r.Epsilon
+	if eps == 0 {
+		eps = DefaultEpsilon
+	}
+	point := ray.Origin.Add(ray.Direction.Scale(rc.Scale))
+	normal := rc.Normal
+	if normal.Dot(ray.Direction) > 0 {
+		// Occlusion is measured on the side facing the
+		// camera, e.g. for the inside of an open mesh.
+		normal = normal.Scale(-1)
+	}
+	var numVisible int
+	for i := 0; i < r.AOSamples; i++ {
+		dir := sampleAngularDest(g.Gen, normal)
+		aoRay := &model3d.Ray{
+			Origin:    point.Add(dir.Scale(eps)),
+			Direction: dir,
+		}
+		if hit, _, ok := obj.Cast(aoRay); !ok || hit.Scale > maxDist {
+			numVisible++
+		}
+	}
+	return float64(numVisible) / float64(r.AOSamples)
+}
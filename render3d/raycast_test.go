@@ -0,0 +1,52 @@
+package render3d
+
+import (
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestRayCasterAmbientOcclusion(t *testing.T) {
+	render := func(obj Object, aoSamples int) *Image {
+		caster := &RayCaster{
+			Camera: NewCameraAt(model3d.XYZ(3, -4, 5), model3d.XYZ(0, 0, 0.5), helperFieldOfView),
+			Lights: []*PointLight{
+				{Origin: model3d.XYZ(30, -40, 50), Color: NewColor(1)},
+			},
+			AOSamples: aoSamples,
+		}
+		img := NewImage(32, 32)
+		caster.Render(img, obj)
+		return img
+	}
+
+	// Nothing can occlude a convex object.
+	sphere := Objectify(&model3d.Sphere{Center: model3d.Z(0.5), Radius: 1}, nil)
+	expected := render(sphere, 0)
+	actual := render(sphere, 16)
+	for i, c := range expected.Data {
+		if c.Dist(actual.Data[i]) > 1e-8 {
+			t.Fatalf("pixel %d: expected %v but got %v", i, c, actual.Data[i])
+		}
+	}
+
+	// The corners of a step should be darkened.
+	mesh := model3d.NewMeshRect(model3d.XYZ(-1, -1, 0), model3d.XYZ(1, 1, 0.5))
+	mesh.AddMesh(model3d.NewMeshRect(model3d.XYZ(-1, 0, 0.5), model3d.XYZ(1, 1, 1)))
+	step := Objectify(mesh, nil)
+	expected = render(step, 0)
+	actual = render(step, 16)
+	var numDarker int
+	for i, c := range expected.Data {
+		a := actual.Data[i]
+		if a.X > c.X+1e-8 || a.Y > c.Y+1e-8 || a.Z > c.Z+1e-8 {
+			t.Fatalf("pixel %d: expected at most %v but got %v", i, c, a)
+		}
+		if a.Sum() < c.Sum()*0.9 {
+			numDarker++
+		}
+	}
+	if numDarker == 0 {
+		t.Error("no pixels were darkened")
+	}
+}
@@ -12,9 +12,29 @@ const DefaultEpsilon = 1e-8
 // A RecursiveRayTracer renders objects using recursive
 // tracing with random sampling.
 type RecursiveRayTracer struct {
-	Camera *Camera
+	Camera Camera
 	Lights []*PointLight
 
+	// Environment, if non-nil, is an infinitely distant
+	// light surrounding the scene.
+	// It is seen directly by rays which escape the scene,
+	// and it is also sampled explicitly at every
+	// collision, using multiple importance sampling to
+	// combine both strategies.
+	Environment *EnvironmentLight
+
+	// AreaLight, if non-nil, is sampled explicitly at
+	// every collision, using multiple importance sampling
+	// to combine this with rays that hit the light by
+	// chance.
+	// This can greatly reduce noise for small lights.
+	//
+	// The light must also be included in the rendered
+	// object, since it is only used for sampling.
+	// It may be created with JoinAreaLights to combine
+	// multiple lights.
+	AreaLight AreaLight
+
 	// FocusPoints are functions which cause rays to
 	// bounce more in certain directions, with the aim of
 	// reducing variance with no bias.
@@ -92,6 +112,25 @@ type RecursiveRayTracer struct {
 	// The sampleRate argument specifies the mean number
 	// of rays traced per pixel.
 	LogFunc func(frac float64, sampleRate float64)
+
+	// Logger, if non-nil, receives progress for the
+	// "render" phase.
+	Logger model3d.Logger
+
+	// Buffers, if non-nil, is filled in with the depth,
+	// normal, and albedo of the first surface seen through
+	// each pixel, and with the variance of each pixel,
+	// whenever an image is rendered.
+	//
+	// The buffers are resized to match the image.
+	Buffers *GeometryBuffers
+
+	// Denoiser, if non-nil, is applied to the rendered
+	// image to reduce noise from low sample counts.
+	//
+	// The denoiser receives Buffers, or temporary buffers
+	// if Buffers is nil.
+	Denoiser Denoiser
 }
 
 // Render renders the object to an image.
@@ -99,6 +138,16 @@ func (r *RecursiveRayTracer) Render(img *Image, obj Object) {
 	r.rayRenderer().Render(img, obj)
 }
 
+// RenderTiled is like Render, but it renders the image
+// one tile at a time, periodically saving progress to a
+// checkpoint which can be used to resume an interrupted
+// render.
+//
+// See TileOptions for details.
+func (r *RecursiveRayTracer) RenderTiled(img *Image, obj Object, opts *TileOptions) error {
+	return r.rayRenderer().RenderTiled(img, obj, opts)
+}
+
 // RenderVariance computes the variance per pixel using a
 // fixed number of rays per pixel, and writes the results
 // as pixels in an image.
@@ -121,7 +170,7 @@ func (r *RecursiveRayTracer) RayVariance(obj Object, width, height, samples int)
 func (r *RecursiveRayTracer) rayRenderer() *rayRenderer {
 	return &rayRenderer{
 		RayColor: func(g *goInfo, obj Object, ray *model3d.Ray) Color {
-			return r.recurse(g.Gen, obj, ray, 0, NewColor(1))
+			return r.recurse(g.Gen, obj, ray, 0, NewColor(1), 0)
 		},
 
 		Camera:               r.Camera,
@@ -132,22 +181,45 @@ func (r *RecursiveRayTracer) rayRenderer() *rayRenderer {
 		Convergence:          r.Convergence,
 		Antialias:            r.Antialias,
 		LogFunc:              r.LogFunc,
+		Logger:               r.Logger,
+		Buffers:              r.Buffers,
+		Denoiser:             r.Denoiser,
 	}
 }
 
+// recurse computes the light arriving along a ray.
+//
+// The density argument is the density with which the
+// direction of the ray was sampled, or 0 for rays from the
+// camera.
 func (r *RecursiveRayTracer) recurse(gen *rand.Rand, obj Object, ray *model3d.Ray,
-	depth int, scale Color) Color {
+	depth int, scale Color, density float64) Color {
 	if scale.Sum()/3 < r.Cutoff {
 		return Color{}
 	}
 	collision, material, ok := obj.Cast(ray)
 	if !ok {
-		return Color{}
+		return r.environmentMiss(ray, density)
+	}
+	if a, ok := material.(AbsorbingMaterial); ok && ray.Direction.Dot(collision.Normal) > 0 {
+		// The ray traveled through the inside of the object
+		// before exiting it.
+		transmittance := a.Transmittance(collision.Scale * ray.Direction.Norm())
+		return r.shade(gen, obj, ray, collision, material, depth,
+			scale.Mul(transmittance), density).Mul(transmittance)
 	}
+	return r.shade(gen, obj, ray, collision, material, depth, scale, density)
+}
+
+// shade computes the light leaving a collision along the
+// reverse direction of a ray.
+func (r *RecursiveRayTracer) shade(gen *rand.Rand, obj Object, ray *model3d.Ray,
+	collision model3d.RayCollision, material Material, depth int, scale Color,
+	density float64) Color {
 	point := ray.Origin.Add(ray.Direction.Scale(collision.Scale))
 
 	dest := ray.Direction.Normalize().Scale(-1)
-	color := material.Emission()
+	color := r.emission(ray, collision, material, density)
 	if depth == 0 {
 		// Only add ambient light directly to object, not to
 		// recursive rays.
@@ -165,20 +237,160 @@ func (r *RecursiveRayTracer) recurse(gen *rand.Rand, obj Object, ray *model3d.Ra
 		brdf := material.BSDF(collision.Normal, point.Sub(l.Origin).Normalize(), dest)
 		color = color.Add(l.ShadeCollision(collision.Normal, lightDirection).Mul(brdf))
 	}
-	if depth >= r.MaxDepth {
+
+	// Without another bounce, explicit light samples are
+	// the only way to reach the lights.
+	lastBounce := depth >= r.MaxDepth
+	if r.Environment != nil {
+		color = color.Add(r.environmentHit(gen, obj, point, collision.Normal, dest, material,
+			!lastBounce))
+	}
+	if r.AreaLight != nil {
+		color = color.Add(r.areaLightHit(gen, obj, point, collision.Normal, dest, material,
+			!lastBounce))
+	}
+	if lastBounce {
 		return color
 	}
 	nextSource := r.sampleNextSource(gen, point, collision.Normal, dest, material)
-	weight := 1 / r.sourceDensity(point, collision.Normal, nextSource, dest, material)
+	nextDensity := r.sourceDensity(point, collision.Normal, nextSource, dest, material)
+	weight := 1 / nextDensity
 	weight *= math.Abs(nextSource.Dot(collision.Normal))
 	reflectWeight := material.BSDF(collision.Normal, nextSource, dest)
 	nextRay := r.bounceRay(point, nextSource.Scale(-1))
 	nextMask := reflectWeight.Scale(weight)
 	nextScale := scale.Mul(nextMask)
-	nextColor := r.recurse(gen, obj, nextRay, depth+1, nextScale)
+	nextColor := r.recurse(gen, obj, nextRay, depth+1, nextScale, nextDensity)
 	return color.Add(nextColor.Mul(nextMask))
 }
 
+// environmentMiss computes the environment light seen by
+// a ray which hit nothing, weighted to account for the
+// explicit sampling in environmentHit.
+func (r *RecursiveRayTracer) environmentMiss(ray *model3d.Ray, density float64) Color {
+	if r.Environment == nil {
+		return Color{}
+	}
+	radiance := r.Environment.Radiance(ray.Direction)
+	if density == 0 {
+		return radiance
+	}
+	envDensity := r.Environment.Density(ray.Direction)
+	return radiance.Scale(powerHeuristic(density, envDensity))
+}
+
+// environmentHit samples the environment light arriving
+// directly at a point on a surface.
+//
+// If mis is true, the sample is weighted to account for
+// the next bounce hitting the environment by chance.
+func (r *RecursiveRayTracer) environmentHit(gen *rand.Rand, obj Object, point, normal,
+	dest model3d.Coord3D, mat Material, mis bool) Color {
+	direction, radiance, density := r.Environment.SampleDirection(gen)
+	if density == 0 {
+		return Color{}
+	}
+	source := direction.Scale(-1)
+	bsdf := mat.BSDF(normal, source, dest)
+	if (bsdf == Color{}) {
+		return Color{}
+	}
+	if _, _, ok := obj.Cast(r.bounceRay(point, direction)); ok {
+		return Color{}
+	}
+	weight := math.Abs(normal.Dot(direction)) / density
+	if mis {
+		weight *= powerHeuristic(density, r.sourceDensity(point, normal, source, dest, mat))
+	}
+	return radiance.Mul(bsdf).Scale(weight)
+}
+
+// emission computes the light emitted by a surface along a
+// ray, weighted to account for the explicit sampling in
+// areaLightHit.
+//
+// The density argument is the same as for recurse().
+func (r *RecursiveRayTracer) emission(ray *model3d.Ray, collision model3d.RayCollision,
+	mat Material, density float64) Color {
+	emission := mat.Emission()
+	if r.AreaLight == nil || density == 0 || (emission == Color{}) {
+		return emission
+	}
+	total := r.AreaLight.TotalEmission()
+	if total == 0 {
+		return emission
+	}
+	// Only surfaces of the light could have been sampled
+	// explicitly.
+	lightCollision, _, ok := r.AreaLight.Cast(ray)
+	if !ok || math.Abs(lightCollision.Scale-collision.Scale) > 1e-8*math.Max(1, collision.Scale) {
+		return emission
+	}
+	dirNorm := ray.Direction.Norm()
+	dist := collision.Scale * dirNorm
+	cos := math.Abs(collision.Normal.Dot(ray.Direction)) / dirNorm
+	lightDensity := areaLightDensity(emission, total, dist, cos)
+	return emission.Scale(powerHeuristic(density, lightDensity))
+}
+
+// areaLightHit samples a point on the area light and
+// computes the light arriving from it directly at a point
+// on a surface.
+//
+// If mis is true, the sample is weighted to account for
+// the next bounce hitting the light by chance.
+func (r *RecursiveRayTracer) areaLightHit(gen *rand.Rand, obj Object, point, normal,
+	dest model3d.Coord3D, mat Material, mis bool) Color {
+	total := r.AreaLight.TotalEmission()
+	if total == 0 {
+		return Color{}
+	}
+	lightPoint, lightNormal, emission := r.AreaLight.SampleLight(gen)
+	toLight := lightPoint.Sub(point)
+	dist := toLight.Norm()
+	if dist == 0 {
+		return Color{}
+	}
+	direction := toLight.Scale(1 / dist)
+	cos := math.Abs(lightNormal.Dot(direction))
+	if cos < cosineEpsilon {
+		return Color{}
+	}
+	source := direction.Scale(-1)
+	bsdf := mat.BSDF(normal, source, dest)
+	if (bsdf == Color{}) {
+		return Color{}
+	}
+
+	// The light itself is part of the scene, so the shadow
+	// ray should hit it at the end.
+	if coll, _, ok := obj.Cast(r.bounceRay(point, toLight)); !ok || coll.Scale < 1-1e-5 {
+		return Color{}
+	}
+
+	density := areaLightDensity(emission, total, dist, cos)
+	weight := math.Abs(normal.Dot(direction)) / density
+	if mis {
+		weight *= powerHeuristic(density, r.sourceDensity(point, normal, source, dest, mat))
+	}
+	return emission.Mul(bsdf).Scale(weight)
+}
+
+// areaLightDensity converts the density of a point sampled
+// from an area light into a density over directions,
+// relative to the uniform distribution on the sphere.
+func areaLightDensity(emission Color, totalEmission, dist, cos float64) float64 {
+	areaDensity := emission.Sum() / totalEmission
+	return 4 * math.Pi * areaDensity * dist * dist / math.Max(cos, cosineEpsilon)
+}
+
+// powerHeuristic computes the multiple importance sampling
+// weight for a sample with density d1, when the same
+// sample could also have been drawn with density d2.
+func powerHeuristic(d1, d2 float64) float64 {
+	return d1 * d1 / (d1*d1 + d2*d2)
+}
+
 func (r *RecursiveRayTracer) sampleNextSource(gen *rand.Rand, point, normal, dest model3d.Coord3D,
 	mat Material) model3d.Coord3D {
 	if len(r.FocusPoints) == 0 {
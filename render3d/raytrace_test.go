@@ -0,0 +1,59 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestRecursiveRayTracerAreaLight(t *testing.T) {
+	light := NewSphereAreaLight(&model3d.Sphere{Center: model3d.Z(2), Radius: 0.2}, NewColor(10))
+	floor := &ColliderObject{
+		Collider: &model3d.Rect{
+			MinVal: model3d.XYZ(-5, -5, -1),
+			MaxVal: model3d.XYZ(5, 5, 0),
+		},
+		Material: &LambertMaterial{DiffuseColor: NewColor(0.8)},
+	}
+	obj := JoinedObject{floor, light}
+	ray := &model3d.Ray{Origin: model3d.XYZ(0.3, 0, 1), Direction: model3d.Z(-1)}
+
+	estimate := func(tracer *RecursiveRayTracer, n int) (mean, variance float64) {
+		gen := rand.New(rand.NewSource(1337))
+		var sum, sqSum float64
+		for i := 0; i < n; i++ {
+			x := tracer.recurse(gen, obj, ray, 0, NewColor(1), 0).X
+			sum += x
+			sqSum += x * x
+		}
+		mean = sum / float64(n)
+		return mean, sqSum/float64(n) - mean*mean
+	}
+
+	// For a small sphere, the irradiance is approximately
+	// pi*emission*(r/d)^2*cos, and the Lambert BRDF is
+	// albedo/pi.
+	toLight := light.sphere.Center.Sub(ray.Origin.Add(ray.Direction))
+	dist := toLight.Norm()
+	irradiance := 10 * math.Pi * math.Pow(0.2/dist, 2) * toLight.Z / dist
+	expected := 0.8 / math.Pi * irradiance
+
+	bsdfMean, bsdfVar := estimate(&RecursiveRayTracer{MaxDepth: 1}, 200000)
+	neeMean, neeVar := estimate(&RecursiveRayTracer{MaxDepth: 1, AreaLight: light}, 20000)
+	directMean, _ := estimate(&RecursiveRayTracer{MaxDepth: 0, AreaLight: light}, 20000)
+
+	if math.Abs(bsdfMean-expected) > 0.05*expected {
+		t.Errorf("expected mean %f but got %f without light sampling", expected, bsdfMean)
+	}
+	if math.Abs(neeMean-expected) > 0.02*expected {
+		t.Errorf("expected mean %f but got %f", expected, neeMean)
+	}
+	if math.Abs(directMean-expected) > 0.02*expected {
+		t.Errorf("expected mean %f but got %f for direct lighting", expected, directMean)
+	}
+	if neeVar > bsdfVar/10 {
+		t.Errorf("variance did not decrease enough: %f -> %f", bsdfVar, neeVar)
+	}
+}
@@ -0,0 +1,204 @@
+package render3d
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const (
+	DefaultTextureMaterialTriplanarScale     = 1.0
+	DefaultTextureMaterialTriplanarSharpness = 4.0
+)
+
+// A SpatialMaterial is a Material whose properties vary
+// across the surface of an object.
+//
+// Objects such as ColliderObject and AttributedMeshObject
+// call MaterialAt for every collision, and report the
+// resulting Material instead of the SpatialMaterial
+// itself.
+type SpatialMaterial interface {
+	Material
+
+	// MaterialAt gets the material at a point on a
+	// surface.
+	//
+	// The uv argument is the texture coordinate of the
+	// point, or nil if the surface has no texture
+	// coordinates.
+	MaterialAt(point, normal model3d.Coord3D, uv *model2d.Coord) Material
+}
+
+// resolveMaterial gets the material for a collision if
+// mat is a SpatialMaterial, or mat otherwise.
+func resolveMaterial(mat Material, r *model3d.Ray, coll model3d.RayCollision,
+	uv *model2d.Coord) Material {
+	if sm, ok := mat.(SpatialMaterial); ok {
+		point := r.Origin.Add(r.Direction.Scale(coll.Scale))
+		return sm.MaterialAt(point, coll.Normal, uv)
+	}
+	return mat
+}
+
+// A TextureMaterial is a PhongMaterial whose diffuse and
+// specular colors are modulated by images, so that
+// surfaces can have detail without changing the geometry.
+//
+// Images are looked up using texture coordinates if they
+// are available, such as for an AttributedMeshObject with
+// UVs.
+// Otherwise, or if Triplanar is set, the images are
+// projected onto the surface along the x, y, and z axes,
+// and the three projections are blended according to the
+// surface normal.
+type TextureMaterial struct {
+	// These fields are like the fields of a
+	// PhongMaterial.
+	//
+	// The diffuse texture scales both DiffuseColor and
+	// AmbientColor, and the specular texture scales
+	// SpecularColor.
+	Alpha         float64
+	SpecularColor Color
+	DiffuseColor  Color
+	EmissionColor Color
+	AmbientColor  Color
+
+	// DiffuseTexture and SpecularTexture are optional
+	// linear images.
+	// Texture coordinates are wrapped, with (0, 0) at the
+	// bottom left of the image and (1, 1) at the top
+	// right.
+	DiffuseTexture  *Image
+	SpecularTexture *Image
+
+	// Triplanar, if true, forces triplanar projection even
+	// for surfaces with texture coordinates.
+	Triplanar bool
+
+	// TriplanarScale is the size, in world units, covered
+	// by one copy of a texture under triplanar projection.
+	//
+	// If 0, DefaultTextureMaterialTriplanarScale is used.
+	TriplanarScale float64
+
+	// TriplanarSharpness is an exponent which controls how
+	// quickly projections are blended as the normal
+	// changes, where higher values give sharper seams.
+	//
+	// If 0, DefaultTextureMaterialTriplanarSharpness is
+	// used.
+	TriplanarSharpness float64
+}
+
+// MaterialAt gets a PhongMaterial with the colors at the
+// given point.
+func (t *TextureMaterial) MaterialAt(point, normal model3d.Coord3D,
+	uv *model2d.Coord) Material {
+	res := t.untextured()
+	if t.DiffuseTexture != nil {
+		c := t.lookup(t.DiffuseTexture, point, normal, uv)
+		res.DiffuseColor = res.DiffuseColor.Mul(c)
+		res.AmbientColor = res.AmbientColor.Mul(c)
+	}
+	if t.SpecularTexture != nil {
+		c := t.lookup(t.SpecularTexture, point, normal, uv)
+		res.SpecularColor = res.SpecularColor.Mul(c)
+	}
+	return res
+}
+
+// BSDF uses the untextured colors, and is only used when
+// an Object does not support SpatialMaterial.
+func (t *TextureMaterial) BSDF(normal, source, dest model3d.Coord3D) Color {
+	return t.untextured().BSDF(normal, source, dest)
+}
+
+func (t *TextureMaterial) SampleSource(gen *rand.Rand, normal,
+	dest model3d.Coord3D) model3d.Coord3D {
+	return t.untextured().SampleSource(gen, normal, dest)
+}
+
+func (t *TextureMaterial) SourceDensity(normal, source, dest model3d.Coord3D) float64 {
+	return t.untextured().SourceDensity(normal, source, dest)
+}
+
+func (t *TextureMaterial) Emission() Color {
+	return t.EmissionColor
+}
+
+func (t *TextureMaterial) Ambient() Color {
+	return t.AmbientColor
+}
+
+func (t *TextureMaterial) untextured() *PhongMaterial {
+	return &PhongMaterial{
+		Alpha:         t.Alpha,
+		SpecularColor: t.SpecularColor,
+		DiffuseColor:  t.DiffuseColor,
+		EmissionColor: t.EmissionColor,
+		AmbientColor:  t.AmbientColor,
+	}
+}
+
+func (t *TextureMaterial) lookup(img *Image, point, normal model3d.Coord3D,
+	uv *model2d.Coord) Color {
+	if uv != nil && !t.Triplanar {
+		return SampleTexture(img, *uv)
+	}
+
+	scale := t.TriplanarScale
+	if scale == 0 {
+		scale = DefaultTextureMaterialTriplanarScale
+	}
+	sharpness := t.TriplanarSharpness
+	if sharpness == 0 {
+		sharpness = DefaultTextureMaterialTriplanarSharpness
+	}
+	p := point.Scale(1 / scale)
+	weights := model3d.XYZ(
+		math.Pow(math.Abs(normal.X), sharpness),
+		math.Pow(math.Abs(normal.Y), sharpness),
+		math.Pow(math.Abs(normal.Z), sharpness),
+	)
+	total := weights.Sum()
+	if total == 0 {
+		return SampleTexture(img, p.XY())
+	}
+	var res Color
+	if weights.X > 0 {
+		res = res.Add(SampleTexture(img, p.YZ()).Scale(weights.X / total))
+	}
+	if weights.Y > 0 {
+		res = res.Add(SampleTexture(img, p.XZ()).Scale(weights.Y / total))
+	}
+	if weights.Z > 0 {
+		res = res.Add(SampleTexture(img, p.XY()).Scale(weights.Z / total))
+	}
+	return res
+}
+
+// SampleTexture gets the color of an image at a texture
+// coordinate using bilinear interpolation.
+//
+// Texture coordinates are wrapped, with (0, 0) at the
+// bottom left of the image and (1, 1) at the top right.
+func SampleTexture(img *Image, uv model2d.Coord) Color {
+	x := (uv.X-math.Floor(uv.X))*float64(img.Width) - 0.5
+	y := (1-(uv.Y-math.Floor(uv.Y)))*float64(img.Height) - 0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+
+	pixel := func(x, y int) Color {
+		x = ((x % img.Width) + img.Width) % img.Width
+		y = ((y % img.Height) + img.Height) % img.Height
+		return img.Data[y*img.Width+x]
+	}
+	ix, iy := int(x0), int(y0)
+	top := pixel(ix, iy).Scale(1 - fx).Add(pixel(ix+1, iy).Scale(fx))
+	bottom := pixel(ix, iy+1).Scale(1 - fx).Add(pixel(ix+1, iy+1).Scale(fx))
+	return top.Scale(1 - fy).Add(bottom.Scale(fy))
+}
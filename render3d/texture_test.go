@@ -0,0 +1,133 @@
+package render3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestSampleTexture(t *testing.T) {
+	img := NewImage(2, 2)
+	img.Data = []Color{NewColor(0), NewColor(1), NewColor(2), NewColor(3)}
+
+	// Pixel centers, with the first row at the top.
+	for _, c := range []struct {
+		UV       model2d.Coord
+		Expected float64
+	}{
+		{model2d.XY(0.25, 0.75), 0},
+		{model2d.XY(0.75, 0.75), 1},
+		{model2d.XY(0.25, 0.25), 2},
+		{model2d.XY(1.75, -0.75), 3},
+		{model2d.XY(0.5, 0.75), 0.5},
+		{model2d.XY(0.5, 0.5), 1.5},
+		{model2d.XY(0, 0.75), 0.5},
+	} {
+		actual := SampleTexture(img, c.UV)
+		if actual.Dist(NewColor(c.Expected)) > 1e-8 {
+			t.Errorf("uv %v: expected %f but got %v", c.UV, c.Expected, actual)
+		}
+	}
+}
+
+func TestTextureMaterialUV(t *testing.T) {
+	img := NewImage(2, 1)
+	img.Data = []Color{{X: 1}, {Y: 1}}
+	texMat := &TextureMaterial{
+		DiffuseColor:   NewColor(0.5),
+		AmbientColor:   NewColor(0.1),
+		DiffuseTexture: img,
+	}
+
+	mesh := model3d.NewMesh()
+	tri := &model3d.Triangle{model3d.XYZ(0, 0, 0), model3d.XYZ(1, 0, 0), model3d.XYZ(0, 1, 0)}
+	mesh.Add(tri)
+	attrs := model3d.NewMeshAttributes()
+	attrs.UVs[tri[0]] = model2d.XY(0, 0.5)
+	attrs.UVs[tri[1]] = model2d.XY(1, 0.5)
+	attrs.UVs[tri[2]] = model2d.XY(0, 0.5)
+	obj := NewAttributedMeshObject(&model3d.AttributedMesh{Mesh: mesh, Attributes: attrs},
+		texMat)
+
+	for _, c := range []struct {
+		X        float64
+		Expected Color
+	}{
+		{0.25, Color{X: 0.5}},
+		{0.75, Color{Y: 0.5}},
+	} {
+		ray := &model3d.Ray{Origin: model3d.XYZ(c.X, 0.1, 1), Direction: model3d.Z(-1)}
+		_, mat, ok := obj.Cast(ray)
+		if !ok {
+			t.Fatal("expected collision")
+		}
+		phong, ok := mat.(*PhongMaterial)
+		if !ok {
+			t.Fatalf("unexpected material type: %T", mat)
+		}
+		if phong.DiffuseColor.Dist(c.Expected) > 1e-8 {
+			t.Errorf("x=%f: expected diffuse %v but got %v", c.X, c.Expected, phong.DiffuseColor)
+		}
+		if phong.AmbientColor.Dist(c.Expected.Scale(0.2)) > 1e-8 {
+			t.Errorf("x=%f: unexpected ambient %v", c.X, phong.AmbientColor)
+		}
+	}
+}
+
+func TestTextureMaterialTriplanar(t *testing.T) {
+	img := NewImage(2, 2)
+	img.Data = []Color{NewColor(1), NewColor(0), NewColor(0), NewColor(1)}
+	texMat := &TextureMaterial{
+		DiffuseColor:   NewColor(1),
+		SpecularColor:  NewColor(0.5),
+		Alpha:          10,
+		DiffuseTexture: img,
+		TriplanarScale: 2,
+	}
+	obj := &ColliderObject{
+		Collider: &model3d.Rect{MinVal: model3d.XYZ(0, 0, 0), MaxVal: model3d.XYZ(2, 2, 2)},
+		Material: texMat,
+	}
+	check := func(ray *model3d.Ray, expected float64) {
+		_, mat, ok := obj.Cast(ray)
+		if !ok {
+			t.Fatal("expected collision")
+		}
+		phong := mat.(*PhongMaterial)
+		if math.Abs(phong.DiffuseColor.X-expected) > 1e-8 {
+			t.Errorf("ray %v: expected %f but got %f", ray.Origin, expected,
+				phong.DiffuseColor.X)
+		}
+		if phong.SpecularColor != NewColor(0.5) {
+			t.Errorf("unexpected specular color: %v", phong.SpecularColor)
+		}
+	}
+
+	// Top face, projected onto the xy plane.
+	check(&model3d.Ray{Origin: model3d.XYZ(0.5, 1.5, 3), Direction: model3d.Z(-1)}, 1)
+	check(&model3d.Ray{Origin: model3d.XYZ(1.5, 1.5, 3), Direction: model3d.Z(-1)}, 0)
+	// Side face, projected onto the yz plane.
+	check(&model3d.Ray{Origin: model3d.XYZ(3, 0.5, 0.5), Direction: model3d.X(-1)}, 0)
+	check(&model3d.Ray{Origin: model3d.XYZ(3, 1.5, 0.5), Direction: model3d.X(-1)}, 1)
+
+	// Rendering should produce a checkerboard.
+	renderer := &RayCaster{
+		Camera: NewCameraAt(model3d.XYZ(1, 1, 5), model3d.XYZ(1, 1, 0), math.Pi/4),
+		Lights: []*PointLight{{Origin: model3d.XYZ(1, 1, 10), Color: NewColor(1)}},
+	}
+	rendered := NewImage(33, 33)
+	renderer.Render(rendered, obj)
+	var dark, light int
+	for _, c := range rendered.Data {
+		if c.Sum() < 0.1 {
+			dark++
+		} else {
+			light++
+		}
+	}
+	if dark < 100 || light < 100 {
+		t.Errorf("expected a checkerboard but got %d dark and %d light pixels", dark, light)
+	}
+}
@@ -0,0 +1,297 @@
+package render3d
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const DefaultTileSize = 32
+
+// tileCheckpointMagic identifies checkpoint files, and
+// should be changed if the format changes.
+const tileCheckpointMagic = "model3d-tiles-v1"
+
+// TileOptions configures tile-based rendering, where an
+// image is rendered one tile at a time so that partial
+// results can be saved and resumed.
+type TileOptions struct {
+	// TileSize is the side length of each square tile, in
+	// pixels.
+	//
+	// If 0, DefaultTileSize is used.
+	TileSize int
+
+	// CheckpointPath, if non-empty, is a file which
+	// stores the completed tiles of the image.
+	//
+	// If the file exists when rendering starts, tiles
+	// which it contains are not rendered again.
+	// The file is left in place after rendering finishes.
+	CheckpointPath string
+
+	// CheckpointInterval is the minimum amount of time
+	// between checkpoints.
+	// A checkpoint is always saved after the final tile.
+	//
+	// If 0, a checkpoint is saved after every tile.
+	CheckpointInterval time.Duration
+
+	// PreviewPath, if non-empty, is an image file which
+	// is updated with the partially rendered image
+	// whenever a checkpoint is saved.
+	// The format is determined by the file extension, as
+	// in Image.Save.
+	PreviewPath string
+}
+
+func (t *TileOptions) tileSize() int {
+	if t == nil || t.TileSize == 0 {
+		return DefaultTileSize
+	}
+	return t.TileSize
+}
+
+// RenderTiled renders the image in square tiles, like
+// Render, but saving and resuming from checkpoints
+// according to opts.
+//
+// If opts is nil, default options are used and no
+// checkpoints are saved.
+func (r *rayRenderer) RenderTiled(img *Image, obj Object, opts *TileOptions) (err error) {
+	defer essentials.AddCtxTo("render tiled", &err)
+	if r.NumSamples == 0 {
+		panic("must set NumSamples to non-zero for rayRenderer")
+	}
+	if opts == nil {
+		opts = &TileOptions{}
+	}
+
+	buffers := r.Buffers
+	if buffers == nil && r.Denoiser != nil {
+		buffers = NewGeometryBuffers(img.Width, img.Height)
+	}
+	if buffers != nil {
+		buffers.resize(img.Width, img.Height)
+		buffers.render(r.Camera, obj)
+	}
+
+	checkpoint := newTileCheckpoint(img.Width, img.Height, opts.tileSize())
+	if opts.CheckpointPath != "" {
+		if _, err := os.Stat(opts.CheckpointPath); err == nil {
+			loaded, err := loadTileCheckpoint(opts.CheckpointPath)
+			if err != nil {
+				return err
+			} else if !checkpoint.Compatible(loaded) {
+				return errors.New("checkpoint does not match image and tile size")
+			}
+			checkpoint = loaded
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	copy(img.Data, checkpoint.Colors)
+	if buffers != nil {
+		copy(buffers.Variance, checkpoint.Variance)
+	}
+
+	maxX := float64(img.Width) - 1
+	maxY := float64(img.Height) - 1
+	rays := r.Camera.Rays(maxX, maxY)
+
+	phase := model3d.StartLogPhase(r.Logger, "render")
+	defer phase.End()
+
+	tiles := checkpoint.Tiles()
+	numDone := checkpoint.NumDone()
+	var samplesTaken, pixelsRendered int
+	lastSave := time.Now()
+	for i, tile := range tiles {
+		if checkpoint.Done[i] {
+			continue
+		}
+		width, height := tile[2]-tile[0], tile[3]-tile[1]
+		counts := make([]int, width*height)
+		mapCoordinates(width, height, func(g *goInfo, x, y, idx int) {
+			x += tile[0]
+			y += tile[1]
+			color, variance, numSamples := r.estimateColor(g, obj, float64(x), float64(y),
+				rays)
+			imgIdx := y*img.Width + x
+			checkpoint.Colors[imgIdx] = color
+			checkpoint.Variance[imgIdx] = variance
+			counts[idx] = numSamples
+		})
+		for y := tile[1]; y < tile[3]; y++ {
+			start, end := y*img.Width+tile[0], y*img.Width+tile[2]
+			copy(img.Data[start:end], checkpoint.Colors[start:end])
+			if buffers != nil {
+				copy(buffers.Variance[start:end], checkpoint.Variance[start:end])
+			}
+		}
+		checkpoint.Done[i] = true
+		numDone++
+
+		for _, n := range counts {
+			samplesTaken += n
+		}
+		pixelsRendered += len(counts)
+		frac := float64(numDone) / float64(len(tiles))
+		if r.LogFunc != nil {
+			r.LogFunc(frac, float64(samplesTaken)/float64(pixelsRendered))
+		}
+		phase.Progress(frac)
+
+		if numDone == len(tiles) || time.Since(lastSave) >= opts.CheckpointInterval {
+			if err := r.saveTileProgress(checkpoint, img, opts); err != nil {
+				return err
+			}
+			lastSave = time.Now()
+		}
+	}
+
+	if r.Denoiser != nil {
+		copy(img.Data, r.Denoiser.Denoise(img, buffers).Data)
+	}
+	return nil
+}
+
+func (r *rayRenderer) saveTileProgress(t *tileCheckpoint, img *Image, opts *TileOptions) error {
+	if opts.CheckpointPath != "" {
+		if err := t.Save(opts.CheckpointPath); err != nil {
+			return err
+		}
+	}
+	if opts.PreviewPath != "" {
+		if err := img.Save(opts.PreviewPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tileCheckpoint stores the colors and variances of the
+// completed tiles of an image.
+type tileCheckpoint struct {
+	Width    int
+	Height   int
+	TileSize int
+
+	// Done indicates which tiles have been rendered, in
+	// the order returned by Tiles().
+	Done []bool
+
+	Colors   []Color
+	Variance []Color
+}
+
+func newTileCheckpoint(width, height, tileSize int) *tileCheckpoint {
+	res := &tileCheckpoint{
+		Width:    width,
+		Height:   height,
+		TileSize: tileSize,
+		Colors:   make([]Color, width*height),
+		Variance: make([]Color, width*height),
+	}
+	res.Done = make([]bool, len(res.Tiles()))
+	return res
+}
+
+func loadTileCheckpoint(path string) (res *tileCheckpoint, err error) {
+	defer essentials.AddCtxTo("load checkpoint", &err)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	magic := make([]byte, len(tileCheckpointMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	} else if string(magic) != tileCheckpointMagic {
+		return nil, errors.New("unknown checkpoint format")
+	}
+	var header [3]uint32
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header[2] == 0 {
+		return nil, errors.New("invalid tile size")
+	}
+	res = newTileCheckpoint(int(header[0]), int(header[1]), int(header[2]))
+	for _, data := range []interface{}{res.Done, res.Colors, res.Variance} {
+		if err := binary.Read(r, binary.LittleEndian, data); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Save atomically writes the checkpoint to a file.
+func (t *tileCheckpoint) Save(path string) (err error) {
+	defer essentials.AddCtxTo("save checkpoint", &err)
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	header := [3]uint32{uint32(t.Width), uint32(t.Height), uint32(t.TileSize)}
+	data := []interface{}{[]byte(tileCheckpointMagic), header, t.Done, t.Colors, t.Variance}
+	for _, x := range data {
+		if err = binary.Write(w, binary.LittleEndian, x); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Compatible checks if two checkpoints are for the same
+// image and tile sizes.
+func (t *tileCheckpoint) Compatible(t1 *tileCheckpoint) bool {
+	return t.Width == t1.Width && t.Height == t1.Height && t.TileSize == t1.TileSize
+}
+
+// Tiles gets the pixel bounds of each tile, as
+// [minX, minY, maxX, maxY] with exclusive maximums.
+func (t *tileCheckpoint) Tiles() [][4]int {
+	var res [][4]int
+	for y := 0; y < t.Height; y += t.TileSize {
+		for x := 0; x < t.Width; x += t.TileSize {
+			res = append(res, [4]int{
+				x,
+				y,
+				essentials.MinInt(x+t.TileSize, t.Width),
+				essentials.MinInt(y+t.TileSize, t.Height),
+			})
+		}
+	}
+	return res
+}
+
+func (t *tileCheckpoint) NumDone() int {
+	var res int
+	for _, d := range t.Done {
+		if d {
+			res++
+		}
+	}
+	return res
+}
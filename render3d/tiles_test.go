@@ -0,0 +1,112 @@
+package render3d
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestRenderTiled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "render_tiled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	opts := &TileOptions{
+		TileSize:       8,
+		CheckpointPath: filepath.Join(dir, "checkpoint"),
+		PreviewPath:    filepath.Join(dir, "preview.png"),
+	}
+
+	renderer := func(value float64) *rayRenderer {
+		return &rayRenderer{
+			RayColor: func(g *goInfo, obj Object, ray *model3d.Ray) Color {
+				return NewColor(value)
+			},
+			Camera:     NewCameraAt(model3d.Y(-3), model3d.Coord3D{}, 0),
+			NumSamples: 2,
+		}
+	}
+	obj := Objectify(&model3d.Sphere{Radius: 1}, nil)
+
+	img := NewImage(20, 12)
+	if err := renderer(0.5).RenderTiled(img, obj, opts); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range img.Data {
+		if c != NewColor(0.5) {
+			t.Fatalf("unexpected color: %v", c)
+		}
+	}
+	if _, err := os.Stat(opts.PreviewPath); err != nil {
+		t.Error(err)
+	}
+
+	// Simulate an interrupted render by marking some of
+	// the tiles as incomplete.
+	checkpoint, err := loadTileCheckpoint(opts.CheckpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoint.Done) != 6 || checkpoint.NumDone() != 6 {
+		t.Fatalf("unexpected tiles: %v", checkpoint.Done)
+	}
+	checkpoint.Done[1] = false
+	checkpoint.Done[4] = false
+	if err := checkpoint.Save(opts.CheckpointPath); err != nil {
+		t.Fatal(err)
+	}
+
+	img = NewImage(20, 12)
+	if err := renderer(0.25).RenderTiled(img, obj, opts); err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < img.Height; y++ {
+		for x := 0; x < img.Width; x++ {
+			tile := (y/8)*3 + x/8
+			expected := NewColor(0.5)
+			if tile == 1 || tile == 4 {
+				expected = NewColor(0.25)
+			}
+			if c := img.Data[y*img.Width+x]; c != expected {
+				t.Fatalf("pixel %d,%d: expected %v but got %v", x, y, expected, c)
+			}
+		}
+	}
+
+	opts.TileSize = 4
+	if err := renderer(0.25).RenderTiled(img, obj, opts); err == nil {
+		t.Error("expected error for mismatched tile size")
+	}
+}
+
+func TestRecursiveRayTracerRenderTiled(t *testing.T) {
+	tracer := &RecursiveRayTracer{
+		Camera:     NewCameraAt(model3d.Y(-3), model3d.Coord3D{}, 0),
+		NumSamples: 100,
+		MaxDepth:   2,
+	}
+	obj := Objectify(&model3d.Sphere{Radius: 1}, nil)
+	light := NewSphereAreaLight(&model3d.Sphere{Center: model3d.XYZ(0, -3, 3), Radius: 1},
+		NewColor(5))
+	obj = JoinedObject{obj, light}
+	tracer.AreaLight = light
+	expected := NewImage(16, 16)
+	tracer.Render(expected, obj)
+	actual := NewImage(16, 16)
+	if err := tracer.RenderTiled(actual, obj, &TileOptions{TileSize: 5}); err != nil {
+		t.Fatal(err)
+	}
+	var expectedSum, actualSum float64
+	for i, c := range expected.Data {
+		expectedSum += c.Sum()
+		actualSum += actual.Data[i].Sum()
+	}
+	if math.Abs(expectedSum-actualSum) > 0.1*math.Abs(expectedSum) {
+		t.Errorf("expected total brightness %f but got %f", expectedSum, actualSum)
+	}
+}
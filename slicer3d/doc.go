@@ -0,0 +1,10 @@
+// Package slicer3d converts 3D models into toolpaths and
+// G-code for FDM 3D printers.
+//
+// The slicer is intentionally minimal: it prints a number
+// of perimeters around each layer and fills the rest with
+// straight lines, without supports, bridging detection, or
+// travel optimization.
+// This is enough to print many simple models without an
+// external slicer.
+package slicer3d
@@ -0,0 +1,165 @@
+package slicer3d
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// minRetractTravel is the shortest travel distance, in
+// millimeters, for which filament is retracted.
+const minRetractTravel = 2.0
+
+// WriteGCode slices a mesh and writes G-code for it.
+func (s *Slicer) WriteGCode(w io.Writer, m *model3d.Mesh) error {
+	return s.WriteLayersGCode(w, s.Slice(m))
+}
+
+// SaveGCode slices a mesh and saves G-code for it to a
+// file.
+func (s *Slicer) SaveGCode(path string, m *model3d.Mesh) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "save G-code")
+	}
+	defer f.Close()
+	if err := s.WriteGCode(f, m); err != nil {
+		return errors.Wrap(err, "save G-code")
+	}
+	return nil
+}
+
+// WriteLayersGCode writes G-code for layers produced by
+// Slice.
+//
+// Extrusion distances are absolute, and the printer is
+// homed before printing.
+// The layers are moved onto the bed as described by
+// s.BedCenter.
+func (s *Slicer) WriteLayersGCode(w io.Writer, layers []*Layer) error {
+	offset := s.bedOffset(layers)
+	g := &gcodeWriter{
+		w:      bufio.NewWriter(w),
+		slicer: s,
+		offset: offset,
+
+		// Homing moves the nozzle to the bed origin.
+		pos: offset.Scale(-1),
+	}
+	g.Printf("; generated by slicer3d\n")
+	g.Printf("; layer height: %.3f, line width: %.3f, perimeters: %d, infill: %.0f%%\n",
+		s.LayerHeight, s.LineWidth, s.Perimeters, s.InfillDensity*100)
+	g.Printf("M140 S%.0f\n", s.BedTemperature)
+	g.Printf("M104 S%.0f\n", s.NozzleTemperature)
+	g.Printf("G21\nG90\nM82\nG28\n")
+	g.Printf("M190 S%.0f\n", s.BedTemperature)
+	g.Printf("M109 S%.0f\n", s.NozzleTemperature)
+	g.Printf("G92 E0\n")
+
+	for i, layer := range layers {
+		g.Printf("; layer %d\n", i)
+		g.Printf("G0 Z%.3f F%.0f\n", layer.Z, s.TravelSpeed*60)
+		for _, loop := range layer.Perimeters {
+			g.Path(append(append([]model2d.Coord{}, loop...), loop[0]))
+		}
+		for _, seg := range layer.Infill {
+			g.Path([]model2d.Coord{seg[0], seg[1]})
+		}
+	}
+
+	g.Printf("M104 S0\nM140 S0\n")
+	if len(layers) > 0 {
+		g.Printf("G0 Z%.3f F%.0f\n", layers[len(layers)-1].Z+10, s.TravelSpeed*60)
+	}
+	g.Printf("M84\n")
+	if g.err != nil {
+		return errors.Wrap(g.err, "write G-code")
+	}
+	if err := g.w.Flush(); err != nil {
+		return errors.Wrap(err, "write G-code")
+	}
+	return nil
+}
+
+// bedOffset computes the translation from model
+// coordinates to bed coordinates.
+func (s *Slicer) bedOffset(layers []*Layer) model2d.Coord {
+	min := model2d.XY(math.Inf(1), math.Inf(1))
+	max := model2d.XY(math.Inf(-1), math.Inf(-1))
+	for _, layer := range layers {
+		for _, loop := range layer.Perimeters {
+			for _, c := range loop {
+				min, max = min.Min(c), max.Max(c)
+			}
+		}
+		for _, seg := range layer.Infill {
+			for _, c := range seg {
+				min, max = min.Min(c), max.Max(c)
+			}
+		}
+	}
+	if math.IsInf(min.X, 1) {
+		return model2d.Coord{}
+	}
+	if s.BedCenter == (model2d.Coord{}) {
+		return model2d.XY(DefaultBedMargin, DefaultBedMargin).Sub(min)
+	}
+	return s.BedCenter.Sub(min.Mid(max))
+}
+
+type gcodeWriter struct {
+	w      *bufio.Writer
+	slicer *Slicer
+	offset model2d.Coord
+	err    error
+
+	// pos is the nozzle position in model coordinates.
+	pos       model2d.Coord
+	extrusion float64
+	retracted bool
+}
+
+func (g *gcodeWriter) Printf(format string, args ...interface{}) {
+	if g.err == nil {
+		_, g.err = fmt.Fprintf(g.w, format, args...)
+	}
+}
+
+// Path travels to the first point and extrudes along the
+// rest of the points.
+func (g *gcodeWriter) Path(points []model2d.Coord) {
+	s := g.slicer
+	if dist := g.pos.Dist(points[0]); dist > 0 {
+		if s.Retraction > 0 && dist >= minRetractTravel && !g.retracted {
+			g.extrusion -= s.Retraction
+			g.Printf("G1 E%.5f F1800\n", g.extrusion)
+			g.retracted = true
+		}
+		p := points[0].Add(g.offset)
+		g.Printf("G0 X%.3f Y%.3f F%.0f\n", p.X, p.Y, s.TravelSpeed*60)
+		g.pos = points[0]
+	}
+	if g.retracted {
+		g.extrusion += s.Retraction
+		g.Printf("G1 E%.5f F1800\n", g.extrusion)
+		g.retracted = false
+	}
+	filamentArea := math.Pi * math.Pow(s.FilamentDiameter/2, 2)
+	for _, p := range points[1:] {
+		if p == g.pos {
+			continue
+		}
+		volume := g.pos.Dist(p) * s.LineWidth * s.LayerHeight
+		g.extrusion += volume / filamentArea
+		bedPoint := p.Add(g.offset)
+		g.Printf("G1 X%.3f Y%.3f E%.5f F%.0f\n", bedPoint.X, bedPoint.Y, g.extrusion,
+			s.PrintSpeed*60)
+		g.pos = p
+	}
+}
@@ -0,0 +1,296 @@
+package slicer3d
+
+import (
+	"math"
+	"sort"
+
+	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const slicerSearchIters = 8
+
+// DefaultBedMargin is the distance, in millimeters, from
+// the bed origin to a model when no BedCenter is given.
+const DefaultBedMargin = 10.0
+
+// A Slicer converts meshes into layers of toolpaths.
+//
+// Models should be measured in millimeters, and are
+// printed with their lowest point on the bed.
+// Toolpaths are computed in model coordinates, and are
+// moved into place on the bed when G-code is written.
+type Slicer struct {
+	// LayerHeight is the thickness of each layer.
+	LayerHeight float64
+
+	// LineWidth is the width of each extruded line.
+	LineWidth float64
+
+	// Perimeters is the number of loops printed around the
+	// outline of each layer.
+	Perimeters int
+
+	// InfillDensity is the fraction of the interior of each
+	// layer to fill, from 0 to 1.
+	InfillDensity float64
+
+	// SolidLayers is the number of layers at the bottom and
+	// top of the model which are completely filled.
+	SolidLayers int
+
+	// Resolution is the grid spacing used to trace the
+	// outlines of each layer.
+	//
+	// If 0, a quarter of the LineWidth is used.
+	Resolution float64
+
+	// Settings for the printer.
+	FilamentDiameter  float64
+	NozzleTemperature float64
+	BedTemperature    float64
+
+	// Speeds in millimeters per second.
+	PrintSpeed  float64
+	TravelSpeed float64
+
+	// Retraction is the length of filament to retract
+	// before traveling, or 0 to disable retraction.
+	Retraction float64
+
+	// BedCenter is the point on the bed where the center
+	// of the XY bounds of the toolpaths is placed.
+	//
+	// If it is the zero coordinate, the toolpaths are
+	// instead placed with the minimum of their bounds at
+	// (DefaultBedMargin, DefaultBedMargin), so that every
+	// move has positive coordinates.
+	BedCenter model2d.Coord
+}
+
+// NewSlicer creates a Slicer with typical settings for a
+// 0.4mm nozzle and PLA filament.
+func NewSlicer() *Slicer {
+	return &Slicer{
+		LayerHeight:       0.2,
+		LineWidth:         0.4,
+		Perimeters:        2,
+		InfillDensity:     0.2,
+		SolidLayers:       3,
+		FilamentDiameter:  1.75,
+		NozzleTemperature: 200,
+		BedTemperature:    60,
+		PrintSpeed:        40,
+		TravelSpeed:       120,
+		Retraction:        1,
+	}
+}
+
+// A Layer contains the toolpaths for one layer of a print,
+// in the XY coordinates of the model.
+type Layer struct {
+	// Z is the height of the top of the layer.
+	Z float64
+
+	// Perimeters are closed loops around the outline of
+	// the layer, ordered from the outside in.
+	Perimeters [][]model2d.Coord
+
+	// Infill contains straight lines which fill in the
+	// interior of the layer.
+	Infill []*model2d.Segment
+}
+
+// Slice computes the toolpaths for every layer of a mesh.
+//
+// The mesh should be closed and oriented correctly.
+func (s *Slicer) Slice(m *model3d.Mesh) []*Layer {
+	minZ, maxZ := m.Min().Z, m.Max().Z
+	numLayers := int(math.Round((maxZ - minZ) / s.LayerHeight))
+	layers := make([]*Layer, numLayers)
+	essentials.ConcurrentMap(0, numLayers, func(i int) {
+		// Slice through the middle of each layer.
+		z := minZ + (float64(i)+0.5)*s.LayerHeight
+		solid := i < s.SolidLayers || i >= numLayers-s.SolidLayers
+		layer := s.sliceLayer(m.SliceZ(z), i, solid)
+		layer.Z = float64(i+1) * s.LayerHeight
+		layers[i] = layer
+	})
+	return layers
+}
+
+func (s *Slicer) sliceLayer(outline *model2d.Mesh, index int, solid bool) *Layer {
+	res := &Layer{}
+	if len(outline.SegmentSlice()) == 0 {
+		return res
+	}
+	sdf := model2d.MeshToSDF(outline)
+	for i := 0; i < s.Perimeters; i++ {
+		contour := s.contour(sdf, (float64(i)+0.5)*s.LineWidth)
+		for _, loop := range meshLoops(contour) {
+			loop = simplifyLoop(loop, s.LineWidth*1e-3)
+			if len(loop) > 2 {
+				res.Perimeters = append(res.Perimeters, loop)
+			}
+		}
+	}
+
+	density := s.InfillDensity
+	if solid {
+		density = 1
+	}
+	if density > 0 {
+		// Slightly overlap the innermost perimeter so that
+		// the infill bonds to it.
+		contour := s.contour(sdf, (float64(s.Perimeters)+0.25)*s.LineWidth)
+		angle := math.Pi / 4
+		if index%2 == 1 {
+			angle = -angle
+		}
+		res.Infill = scanlineInfill(contour, s.LineWidth/density, angle)
+	}
+	return res
+}
+
+func (s *Slicer) contour(sdf model2d.SDF, inset float64) *model2d.Mesh {
+	resolution := s.Resolution
+	if resolution == 0 {
+		resolution = s.LineWidth / 4
+	}
+	return model2d.MarchingSquaresSearch(&insetSolid{sdf: sdf, inset: inset}, resolution,
+		slicerSearchIters)
+}
+
+// insetSolid contains the points of a 2D shape which are
+// at least inset away from its boundary.
+type insetSolid struct {
+	sdf   model2d.SDF
+	inset float64
+}
+
+func (i *insetSolid) Min() model2d.Coord {
+	return i.sdf.Min()
+}
+
+func (i *insetSolid) Max() model2d.Coord {
+	return i.sdf.Max()
+}
+
+func (i *insetSolid) Contains(c model2d.Coord) bool {
+	return model2d.InBounds(i, c) && i.sdf.SDF(c) > i.inset
+}
+
+// meshLoops splits a manifold 2D mesh into closed loops of
+// points, in a deterministic order.
+func meshLoops(m *model2d.Mesh) [][]model2d.Coord {
+	segs := m.SegmentSlice()
+	sort.Slice(segs, func(i, j int) bool {
+		return coordLess(segs[i][0], segs[j][0])
+	})
+	visited := map[*model2d.Segment]bool{}
+	var res [][]model2d.Coord
+	for _, start := range segs {
+		if visited[start] {
+			continue
+		}
+		var loop []model2d.Coord
+		seg := start
+		for !visited[seg] {
+			visited[seg] = true
+			loop = append(loop, seg[0])
+			var next *model2d.Segment
+			for _, s := range m.Find(seg[1]) {
+				if s[0] == seg[1] && !visited[s] {
+					next = s
+					break
+				}
+			}
+			if next == nil {
+				break
+			}
+			seg = next
+		}
+		if len(loop) > 2 {
+			res = append(res, loop)
+		}
+	}
+	return res
+}
+
+// simplifyLoop removes points from a closed loop which
+// are within epsilon of the line through their neighbors,
+// such as duplicate points and points along straight
+// edges.
+func simplifyLoop(loop []model2d.Coord, epsilon float64) []model2d.Coord {
+	res := append([]model2d.Coord{}, loop...)
+	for changed := true; changed && len(res) > 2; {
+		changed = false
+		for i := 0; i < len(res) && len(res) > 2; i++ {
+			prev := res[(i+len(res)-1)%len(res)]
+			next := res[(i+1)%len(res)]
+			seg := model2d.Segment{prev, next}
+			if seg.Dist(res[i]) < epsilon {
+				res = append(res[:i], res[i+1:]...)
+				i--
+				changed = true
+			}
+		}
+	}
+	return res
+}
+
+// scanlineInfill creates evenly spaced parallel lines at
+// the given angle inside of a 2D mesh, alternating their
+// direction so that consecutive lines can be connected.
+func scanlineInfill(m *model2d.Mesh, spacing, angle float64) []*model2d.Segment {
+	rotation := model2d.NewMatrix2Rotation(-angle)
+	inverse := model2d.NewMatrix2Rotation(angle)
+	rotated := m.MapCoords(rotation.MulColumn)
+	segs := rotated.SegmentSlice()
+	if len(segs) == 0 {
+		return nil
+	}
+	min, max := rotated.Min(), rotated.Max()
+
+	var res []*model2d.Segment
+	var lineIndex int
+	start := math.Floor(min.Y/spacing) * spacing
+	for y := start + spacing/2; y < max.Y; y += spacing {
+		var xs []float64
+		for _, seg := range segs {
+			y1, y2 := seg[0].Y, seg[1].Y
+			// Half-open intervals count each vertex once.
+			if (y1 <= y) != (y2 <= y) {
+				frac := (y - y1) / (y2 - y1)
+				xs = append(xs, seg[0].X+frac*(seg[1].X-seg[0].X))
+			}
+		}
+		sort.Float64s(xs)
+		var line []*model2d.Segment
+		for i := 0; i+1 < len(xs); i += 2 {
+			line = append(line, &model2d.Segment{
+				inverse.MulColumn(model2d.XY(xs[i], y)),
+				inverse.MulColumn(model2d.XY(xs[i+1], y)),
+			})
+		}
+		if lineIndex%2 == 1 {
+			for i, j := 0, len(line)-1; i < j; i, j = i+1, j-1 {
+				line[i], line[j] = line[j], line[i]
+			}
+			for _, seg := range line {
+				seg[0], seg[1] = seg[1], seg[0]
+			}
+		}
+		res = append(res, line...)
+		lineIndex++
+	}
+	return res
+}
+
+func coordLess(c1, c2 model2d.Coord) bool {
+	if c1.X != c2.X {
+		return c1.X < c2.X
+	}
+	return c1.Y < c2.Y
+}
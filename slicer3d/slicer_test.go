@@ -0,0 +1,176 @@
+package slicer3d
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestSlicerSlice(t *testing.T) {
+	box := model3d.NewMeshRect(model3d.XYZ(0, 0, 5), model3d.XYZ(10, 10, 7))
+	slicer := NewSlicer()
+	layers := slicer.Slice(box)
+	if len(layers) != 10 {
+		t.Fatalf("expected 10 layers but got %d", len(layers))
+	}
+	for i, layer := range layers {
+		if math.Abs(layer.Z-float64(i+1)*0.2) > 1e-8 {
+			t.Errorf("layer %d: unexpected z %f", i, layer.Z)
+		}
+		if len(layer.Perimeters) != 2 {
+			t.Fatalf("layer %d: expected 2 perimeters but got %d", i, len(layer.Perimeters))
+		}
+		for j, loop := range layer.Perimeters {
+			side := 10 - 0.4*(2*float64(j)+1)
+			if l := loopLength(loop); math.Abs(l-side*4) > 0.1 {
+				t.Errorf("layer %d: perimeter %d has length %f (expected %f)", i, j, l, side*4)
+			}
+		}
+
+		// Count the area covered by infill to check the
+		// density.
+		var infillArea float64
+		for _, seg := range layer.Infill {
+			infillArea += seg.Length() * slicer.LineWidth
+		}
+		innerArea := math.Pow(10-4*0.4, 2)
+		density := infillArea / innerArea
+		expected := slicer.InfillDensity
+		if i < 3 || i >= 7 {
+			expected = 1
+		}
+		if math.Abs(density-expected) > 0.1*expected {
+			t.Errorf("layer %d: expected density %f but got %f", i, expected, density)
+		}
+	}
+}
+
+func TestSlicerSliceHole(t *testing.T) {
+	tube := model3d.MarchingCubesSearch(&model3d.SubtractedSolid{
+		Positive: &model3d.Cylinder{P2: model3d.Z(1), Radius: 5},
+		Negative: &model3d.Cylinder{P1: model3d.Z(-1), P2: model3d.Z(2), Radius: 3},
+	}, 0.1, 8)
+	slicer := NewSlicer()
+	slicer.SolidLayers = 0
+	for _, layer := range slicer.Slice(tube) {
+		if len(layer.Perimeters) != 4 {
+			t.Fatalf("expected 4 perimeters but got %d", len(layer.Perimeters))
+		}
+		for _, seg := range layer.Infill {
+			r := seg[0].Mid(seg[1]).Norm()
+			if r < 3 || r > 5 {
+				t.Fatalf("infill outside of tube: %v", seg)
+			}
+		}
+	}
+}
+
+func TestSlicerWriteGCode(t *testing.T) {
+	box := model3d.NewMeshRect(model3d.XYZ(0, 0, 0), model3d.XYZ(10, 10, 1))
+	slicer := NewSlicer()
+	var buf bytes.Buffer
+	if err := slicer.WriteGCode(&buf, box); err != nil {
+		t.Fatal(err)
+	}
+
+	var numLayers int
+	var lastE, lastZ float64
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var layerIndex int
+		if _, err := fmt.Sscanf(line, "; layer %d", &layerIndex); err == nil {
+			if layerIndex != numLayers {
+				t.Fatalf("unexpected layer index: %s", line)
+			}
+			numLayers++
+		}
+		for _, field := range strings.Fields(line) {
+			if strings.HasPrefix(field, "Z") {
+				z, err := strconv.ParseFloat(field[1:], 64)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if z < lastZ {
+					t.Fatalf("z decreased: %s", line)
+				}
+				lastZ = z
+			} else if strings.HasPrefix(field, "E") && strings.HasPrefix(line, "G1 X") {
+				e, err := strconv.ParseFloat(field[1:], 64)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if e <= lastE {
+					t.Fatalf("extrusion did not increase: %s", line)
+				}
+				lastE = e
+			}
+		}
+	}
+	if numLayers != 5 {
+		t.Errorf("expected 5 layers but got %d", numLayers)
+	}
+
+	// The box is solid, so the extruded volume should be
+	// close to the volume of the box.
+	filamentArea := math.Pi * math.Pow(slicer.FilamentDiameter/2, 2)
+	if v := lastE * filamentArea; math.Abs(v-100) > 10 {
+		t.Errorf("expected to extrude about 100 mm^3 but got %f", v)
+	}
+}
+
+func TestSlicerBedPlacement(t *testing.T) {
+	cube := model3d.NewMeshRect(model3d.XYZ(-5, -5, -5), model3d.XYZ(5, 5, 5))
+
+	checkBounds := func(t *testing.T, slicer *Slicer, min, max model2d.Coord) {
+		var buf bytes.Buffer
+		if err := slicer.WriteGCode(&buf, cube); err != nil {
+			t.Fatal(err)
+		}
+		actualMin := model2d.XY(math.Inf(1), math.Inf(1))
+		actualMax := model2d.XY(math.Inf(-1), math.Inf(-1))
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if !strings.HasPrefix(line, "G0 X") && !strings.HasPrefix(line, "G1 X") {
+				continue
+			}
+			var x, y float64
+			if _, err := fmt.Sscanf(strings.Fields(line)[1]+" "+strings.Fields(line)[2],
+				"X%f Y%f", &x, &y); err != nil {
+				t.Fatal(err)
+			}
+			if x < 0 || y < 0 {
+				t.Fatalf("negative coordinate: %s", line)
+			}
+			actualMin = actualMin.Min(model2d.XY(x, y))
+			actualMax = actualMax.Max(model2d.XY(x, y))
+		}
+		if actualMin.Dist(min) > 0.05 || actualMax.Dist(max) > 0.05 {
+			t.Errorf("expected bounds %v-%v but got %v-%v", min, max, actualMin, actualMax)
+		}
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		// The outer perimeter is inset by half a line width.
+		checkBounds(t, NewSlicer(), model2d.XY(10, 10), model2d.XY(19.6, 19.6))
+	})
+	t.Run("Center", func(t *testing.T) {
+		slicer := NewSlicer()
+		slicer.BedCenter = model2d.XY(100, 50)
+		checkBounds(t, slicer, model2d.XY(95.2, 45.2), model2d.XY(104.8, 54.8))
+	})
+}
+
+func loopLength(loop []model2d.Coord) float64 {
+	var res float64
+	for i, c := range loop {
+		res += c.Dist(loop[(i+1)%len(loop)])
+	}
+	return res
+}
@@ -0,0 +1,185 @@
+package toolbox3d
+
+import (
+	"fmt"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// An Anchor is a coordinate frame attached to a part, such
+// as the top of a screw or the center of a lid.
+//
+// Anchors make it possible to position parts relative to
+// each other without recomputing coordinates by hand.
+type Anchor struct {
+	// Origin is the location of the anchor.
+	Origin model3d.Coord3D
+
+	// Rotation is an orthonormal matrix whose columns are
+	// the x, y, and z axes of the frame.
+	Rotation *model3d.Matrix3
+}
+
+// NewAnchor creates an anchor at an origin with a given
+// z-axis (e.g. an outward surface normal) and a direction
+// for the x-axis.
+//
+// The x direction is projected to be perpendicular to the
+// z-axis. If it is zero or parallel to the z-axis, an
+// arbitrary x-axis is chosen.
+func NewAnchor(origin, z, x model3d.Coord3D) *Anchor {
+	z = z.Normalize()
+	x = x.ProjectOut(z)
+	if x.Norm() < 1e-8 {
+		x, _ = z.OrthoBasis()
+	}
+	x = x.Normalize()
+	return &Anchor{
+		Origin:   origin,
+		Rotation: model3d.NewMatrix3Columns(x, z.Cross(x), z),
+	}
+}
+
+// XAxis gets the x-axis of the frame.
+func (a *Anchor) XAxis() model3d.Coord3D {
+	return model3d.XYZ(a.Rotation[0], a.Rotation[3], a.Rotation[6])
+}
+
+// YAxis gets the y-axis of the frame.
+func (a *Anchor) YAxis() model3d.Coord3D {
+	return model3d.XYZ(a.Rotation[1], a.Rotation[4], a.Rotation[7])
+}
+
+// ZAxis gets the z-axis of the frame.
+func (a *Anchor) ZAxis() model3d.Coord3D {
+	return model3d.XYZ(a.Rotation[2], a.Rotation[5], a.Rotation[8])
+}
+
+// Flip rotates the anchor 180 degrees around its x-axis,
+// so that the z-axis points the other way.
+//
+// This is useful for mating two faces, whose outward
+// normals point in opposite directions.
+func (a *Anchor) Flip() *Anchor {
+	return &Anchor{
+		Origin: a.Origin,
+		Rotation: model3d.NewMatrix3Columns(
+			a.XAxis(),
+			a.YAxis().Scale(-1),
+			a.ZAxis().Scale(-1),
+		),
+	}
+}
+
+// Transform applies a transformation to the anchor.
+//
+// The transformation should be affine, and it is assumed
+// to preserve angles (e.g. a rotation, translation, or
+// uniform scale).
+// The axes of the resulting frame are re-normalized.
+func (a *Anchor) Transform(t model3d.Transform) *Anchor {
+	origin := t.Apply(a.Origin)
+	z := t.Apply(a.Origin.Add(a.ZAxis())).Sub(origin)
+	x := t.Apply(a.Origin.Add(a.XAxis())).Sub(origin)
+	y := t.Apply(a.Origin.Add(a.YAxis())).Sub(origin)
+	res := NewAnchor(origin, z, x)
+	if res.YAxis().Dot(y) < 0 {
+		// The transform changed handedness, so we cannot
+		// represent the result as a rotation.
+		panic("transform does not preserve handedness")
+	}
+	return res
+}
+
+// ToWorld gets a transformation from the anchor's local
+// coordinates into world coordinates.
+func (a *Anchor) ToWorld() model3d.DistTransform {
+	return &rigidTransform{Rotation: a.Rotation, Offset: a.Origin}
+}
+
+// AnchorTransform computes the rigid transformation which
+// moves the frame from onto the frame to.
+func AnchorTransform(from, to *Anchor) model3d.DistTransform {
+	rotation := to.Rotation.Mul(from.Rotation.Transpose())
+	return &rigidTransform{
+		Rotation: rotation,
+		Offset:   to.Origin.Sub(rotation.MulColumn(from.Origin)),
+	}
+}
+
+// An AnchoredSolid is a model3d.Solid with named anchors.
+//
+// Transforming an AnchoredSolid transforms its anchors as
+// well, so parts can be positioned relative to each other
+// using the anchors.
+type AnchoredSolid struct {
+	model3d.Solid
+	Anchors map[string]*Anchor
+}
+
+// NewAnchoredSolid creates an AnchoredSolid with no
+// anchors.
+func NewAnchoredSolid(s model3d.Solid) *AnchoredSolid {
+	return &AnchoredSolid{Solid: s, Anchors: map[string]*Anchor{}}
+}
+
+// AddAnchor adds a named anchor to the solid, replacing
+// any existing anchor with the same name.
+func (a *AnchoredSolid) AddAnchor(name string, anchor *Anchor) {
+	a.Anchors[name] = anchor
+}
+
+// Anchor gets the anchor with the given name.
+//
+// This panics if the anchor does not exist.
+func (a *AnchoredSolid) Anchor(name string) *Anchor {
+	anchor, ok := a.Anchors[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown anchor: %s", name))
+	}
+	return anchor
+}
+
+// Transform applies a transformation to the solid and its
+// anchors.
+func (a *AnchoredSolid) Transform(t model3d.Transform) *AnchoredSolid {
+	res := NewAnchoredSolid(model3d.TransformSolid(t, a.Solid))
+	for name, anchor := range a.Anchors {
+		res.Anchors[name] = anchor.Transform(t)
+	}
+	return res
+}
+
+// Mate moves the solid so that its named anchor coincides
+// with a target anchor.
+func (a *AnchoredSolid) Mate(name string, target *Anchor) *AnchoredSolid {
+	return a.Transform(AnchorTransform(a.Anchor(name), target))
+}
+
+// rigidTransform is a rotation followed by a translation.
+type rigidTransform struct {
+	Rotation *model3d.Matrix3
+	Offset   model3d.Coord3D
+}
+
+func (r *rigidTransform) Apply(c model3d.Coord3D) model3d.Coord3D {
+	return r.Rotation.MulColumn(c).Add(r.Offset)
+}
+
+func (r *rigidTransform) ApplyBounds(min, max model3d.Coord3D) (model3d.Coord3D, model3d.Coord3D) {
+	mt := &model3d.Matrix3Transform{Matrix: r.Rotation}
+	min, max = mt.ApplyBounds(min, max)
+	return min.Add(r.Offset), max.Add(r.Offset)
+}
+
+func (r *rigidTransform) Inverse() model3d.Transform {
+	inv := r.Rotation.Transpose()
+	return &rigidTransform{
+		Rotation: inv,
+		Offset:   inv.MulColumn(r.Offset).Scale(-1),
+	}
+}
+
+func (r *rigidTransform) ApplyDistance(d float64) float64 {
+	return d
+}
@@ -0,0 +1,56 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestAnchorTransform(t *testing.T) {
+	from := NewAnchor(model3d.XYZ(1, 2, 3), model3d.XYZ(1, 1, 0), model3d.Z(1))
+	to := NewAnchor(model3d.XYZ(-1, 0, 2), model3d.XYZ(0, -1, 1), model3d.X(1))
+	xf := AnchorTransform(from, to)
+	moved := from.Transform(xf)
+	if moved.Origin.Dist(to.Origin) > 1e-8 {
+		t.Errorf("expected origin %v but got %v", to.Origin, moved.Origin)
+	}
+	for i := range moved.Rotation {
+		if math.Abs(moved.Rotation[i]-to.Rotation[i]) > 1e-8 {
+			t.Fatalf("expected rotation %v but got %v", to.Rotation, moved.Rotation)
+		}
+	}
+
+	// Check the inverse.
+	c := model3d.XYZ(0.3, -0.7, 1.1)
+	if actual := xf.Inverse().Apply(xf.Apply(c)); actual.Dist(c) > 1e-8 {
+		t.Errorf("expected %v but got %v", c, actual)
+	}
+}
+
+func TestAnchoredSolidMate(t *testing.T) {
+	box := NewAnchoredSolid(&model3d.Rect{MaxVal: model3d.XYZ(2, 2, 1)})
+	box.AddAnchor("top", NewAnchor(model3d.XYZ(1, 1, 1), model3d.Z(1), model3d.X(1)))
+
+	lid := NewAnchoredSolid(&model3d.Rect{
+		MinVal: model3d.XYZ(-1, -1, 0),
+		MaxVal: model3d.XYZ(1, 1, 0.2),
+	})
+	lid.AddAnchor("bottom", NewAnchor(model3d.Coord3D{}, model3d.Z(-1), model3d.X(1)))
+	lid.AddAnchor("knob", NewAnchor(model3d.Z(0.2), model3d.Z(1), model3d.X(1)))
+
+	placed := lid.Mate("bottom", box.Anchor("top").Flip())
+	if !placed.Contains(model3d.XYZ(1.5, 1.5, 1.1)) {
+		t.Error("expected lid to rest on top of the box")
+	}
+	if placed.Contains(model3d.XYZ(1.5, 1.5, 0.9)) {
+		t.Error("expected lid not to overlap the box")
+	}
+	knob := placed.Anchor("knob")
+	if knob.Origin.Dist(model3d.XYZ(1, 1, 1.2)) > 1e-8 {
+		t.Errorf("unexpected knob origin: %v", knob.Origin)
+	}
+	if knob.ZAxis().Dist(model3d.Z(1)) > 1e-8 {
+		t.Errorf("unexpected knob axis: %v", knob.ZAxis())
+	}
+}
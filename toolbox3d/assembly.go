@@ -0,0 +1,333 @@
+package toolbox3d
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model3d"
+	"github.com/unixpickle/model3d/numerical"
+)
+
+const (
+	assemblyMaxIters  = 200
+	assemblyTolerance = 1e-10
+	assemblyEpsilon   = 1e-7
+)
+
+// An Assembly positions a collection of parts by solving
+// for rigid transformations which satisfy a set of mate
+// constraints between the parts' anchors.
+//
+// Anchors are referred to by "part.anchor", where part is
+// the name of a part in the assembly and anchor is the
+// name of an anchor on the part.
+type Assembly struct {
+	names       []string
+	parts       map[string]*assemblyPart
+	constraints []*assemblyConstraint
+}
+
+// NewAssembly creates an empty assembly.
+func NewAssembly() *Assembly {
+	return &Assembly{parts: map[string]*assemblyPart{}}
+}
+
+// AddPart adds a named part to the assembly.
+//
+// If fixed is true, the part will not be moved.
+// At least one part should be fixed to pin down the
+// assembly as a whole.
+func (a *Assembly) AddPart(name string, part *AnchoredSolid, fixed bool) {
+	if _, ok := a.parts[name]; ok {
+		panic("duplicate part: " + name)
+	}
+	a.names = append(a.names, name)
+	a.parts[name] = &assemblyPart{
+		Part:     part,
+		Fixed:    fixed,
+		Rotation: model3d.NewMatrix3Rotation(model3d.Z(1), 0),
+	}
+}
+
+// Coincident constrains the origins of two anchors to
+// be at the same point.
+func (a *Assembly) Coincident(anchor1, anchor2 string) {
+	a.addConstraint(anchor1, anchor2, func(f1, f2 *Anchor) []float64 {
+		diff := f1.Origin.Sub(f2.Origin)
+		return []float64{diff.X, diff.Y, diff.Z}
+	})
+}
+
+// Concentric constrains two anchors to share the same
+// z-axis line, e.g. for a shaft in a hole.
+// The axes may point in the same or opposite directions.
+func (a *Assembly) Concentric(anchor1, anchor2 string) {
+	a.addConstraint(anchor1, anchor2, func(f1, f2 *Anchor) []float64 {
+		z1, z2 := f1.ZAxis(), f2.ZAxis()
+		cross := z1.Cross(z2)
+		offset := f2.Origin.Sub(f1.Origin).ProjectOut(z1)
+		return []float64{cross.X, cross.Y, cross.Z, offset.X, offset.Y, offset.Z}
+	})
+}
+
+// Distance constrains the origins of two anchors to be a
+// fixed distance apart.
+func (a *Assembly) Distance(anchor1, anchor2 string, distance float64) {
+	a.addConstraint(anchor1, anchor2, func(f1, f2 *Anchor) []float64 {
+		return []float64{f1.Origin.Dist(f2.Origin) - distance}
+	})
+}
+
+// Angle constrains the angle (in radians) between the
+// z-axes of two anchors.
+//
+// For parallel or anti-parallel axes, Concentric or Align
+// is more robust, since the angle is not differentiable
+// at 0 or pi.
+func (a *Assembly) Angle(anchor1, anchor2 string, angle float64) {
+	a.addConstraint(anchor1, anchor2, func(f1, f2 *Anchor) []float64 {
+		z1, z2 := f1.ZAxis(), f2.ZAxis()
+		return []float64{math.Atan2(z1.Cross(z2).Norm(), z1.Dot(z2)) - angle}
+	})
+}
+
+// Align constrains two anchors to be the same frame, as
+// done by AnchoredSolid.Mate().
+func (a *Assembly) Align(anchor1, anchor2 string) {
+	a.addConstraint(anchor1, anchor2, func(f1, f2 *Anchor) []float64 {
+		var res []float64
+		for i := range f1.Rotation {
+			res = append(res, f1.Rotation[i]-f2.Rotation[i])
+		}
+		diff := f1.Origin.Sub(f2.Origin)
+		return append(res, diff.X, diff.Y, diff.Z)
+	})
+}
+
+// Solve computes transformations for all of the movable
+// parts which satisfy the constraints.
+//
+// The current placement of the parts is used as a
+// starting point, so parts should be defined roughly in
+// place to avoid local minima.
+//
+// An error is returned if the constraints could not be
+// satisfied.
+func (a *Assembly) Solve() error {
+	var free []*assemblyPart
+	for _, name := range a.names {
+		if p := a.parts[name]; !p.Fixed {
+			free = append(free, p)
+		}
+	}
+	numParams := len(free) * 6
+
+	residuals := numerical.Vec(a.residuals())
+	cost := residuals.NormSquared()
+	damping := 1e-3
+	for iter := 0; iter < assemblyMaxIters && cost > assemblyTolerance; iter++ {
+		// Compute the Jacobian with finite differences.
+		jacobian := make([]numerical.Vec, numParams)
+		for i, p := range free {
+			for j := 0; j < 6; j++ {
+				var delta [6]float64
+				delta[j] = assemblyEpsilon
+				backup := *p
+				p.Perturb(delta)
+				r := a.residuals()
+				*p = backup
+				col := make(numerical.Vec, len(r))
+				for k, x := range r {
+					col[k] = (x - residuals[k]) / assemblyEpsilon
+				}
+				jacobian[i*6+j] = col
+			}
+		}
+
+		// Solve the damped normal equations.
+		lhs := make([][]float64, numParams)
+		rhs := make([]float64, numParams)
+		for i := range lhs {
+			lhs[i] = make([]float64, numParams)
+			for j := range lhs[i] {
+				lhs[i][j] = jacobian[i].Dot(jacobian[j])
+			}
+			rhs[i] = -jacobian[i].Dot(residuals)
+		}
+
+		improved := false
+		for !improved && damping < 1e10 {
+			step := solveDampedSystem(lhs, rhs, damping)
+			backups := make([]assemblyPart, len(free))
+			for i, p := range free {
+				backups[i] = *p
+				var delta [6]float64
+				copy(delta[:], step[i*6:])
+				p.Perturb(delta)
+			}
+			newResiduals := numerical.Vec(a.residuals())
+			if newCost := newResiduals.NormSquared(); newCost < cost {
+				residuals, cost = newResiduals, newCost
+				damping = math.Max(damping/10, 1e-12)
+				improved = true
+			} else {
+				for i, p := range free {
+					*p = backups[i]
+				}
+				damping *= 10
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	if cost > assemblyTolerance {
+		return errors.Errorf("solve assembly: constraints not satisfied (residual %e)",
+			math.Sqrt(cost))
+	}
+	return nil
+}
+
+// Transform gets the current transformation of a part.
+func (a *Assembly) Transform(name string) model3d.DistTransform {
+	p := a.part(name)
+	return &rigidTransform{Rotation: p.Rotation, Offset: p.Offset}
+}
+
+// Placed gets a part transformed into its current place
+// in the assembly.
+func (a *Assembly) Placed(name string) *AnchoredSolid {
+	return a.part(name).Part.Transform(a.Transform(name))
+}
+
+// Solid joins all of the placed parts into one solid.
+func (a *Assembly) Solid() model3d.Solid {
+	var res model3d.JoinedSolid
+	for _, name := range a.names {
+		res = append(res, a.Placed(name))
+	}
+	return res
+}
+
+func (a *Assembly) part(name string) *assemblyPart {
+	p, ok := a.parts[name]
+	if !ok {
+		panic("unknown part: " + name)
+	}
+	return p
+}
+
+func (a *Assembly) addConstraint(anchor1, anchor2 string, f func(f1, f2 *Anchor) []float64) {
+	a.constraints = append(a.constraints, &assemblyConstraint{
+		Anchor1:  a.anchorRef(anchor1),
+		Anchor2:  a.anchorRef(anchor2),
+		Residual: f,
+	})
+}
+
+func (a *Assembly) anchorRef(name string) [2]string {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) != 2 {
+		panic(fmt.Sprintf("invalid anchor reference: %s", name))
+	}
+	// Validate the reference up front.
+	a.part(parts[0]).Part.Anchor(parts[1])
+	return [2]string{parts[0], parts[1]}
+}
+
+func (a *Assembly) anchor(ref [2]string) *Anchor {
+	p := a.part(ref[0])
+	local := p.Part.Anchor(ref[1])
+	return &Anchor{
+		Origin:   p.Rotation.MulColumn(local.Origin).Add(p.Offset),
+		Rotation: p.Rotation.Mul(local.Rotation),
+	}
+}
+
+func (a *Assembly) residuals() []float64 {
+	var res []float64
+	for _, c := range a.constraints {
+		res = append(res, c.Residual(a.anchor(c.Anchor1), a.anchor(c.Anchor2))...)
+	}
+	return res
+}
+
+type assemblyPart struct {
+	Part     *AnchoredSolid
+	Fixed    bool
+	Rotation *model3d.Matrix3
+	Offset   model3d.Coord3D
+}
+
+// Perturb applies a small rotation (given as a rotation
+// vector) and a translation to the part's transform.
+//
+// The rotation is performed around the current Offset,
+// i.e. the placed position of the part's local origin, so
+// that the rotation does not move the local origin.
+func (a *assemblyPart) Perturb(delta [6]float64) {
+	rotVec := model3d.XYZ(delta[0], delta[1], delta[2])
+	if angle := rotVec.Norm(); angle > 0 {
+		rotation := model3d.NewMatrix3Rotation(rotVec.Scale(1/angle), angle)
+		a.Rotation = rotation.Mul(a.Rotation)
+	}
+	a.Offset = a.Offset.Add(model3d.XYZ(delta[3], delta[4], delta[5]))
+}
+
+type assemblyConstraint struct {
+	Anchor1  [2]string
+	Anchor2  [2]string
+	Residual func(f1, f2 *Anchor) []float64
+}
+
+// solveDampedSystem solves the Levenberg-Marquardt system
+// (A + damping*(I + diag(A)))*x = b for a symmetric,
+// positive semi-definite matrix A.
+//
+// Since the damped matrix is positive definite, it can be
+// inverted with a Cholesky decomposition.
+// The system only has six rows per part, so a dense
+// decomposition is sufficient.
+func solveDampedSystem(mat [][]float64, rhs []float64, damping float64) []float64 {
+	n := len(rhs)
+
+	// Compute the lower-triangular factor L of L*L^T.
+	lower := make([][]float64, n)
+	for i := range lower {
+		lower[i] = make([]float64, i+1)
+		for j := 0; j <= i; j++ {
+			sum := mat[i][j]
+			if i == j {
+				sum += damping * (1 + sum)
+			}
+			for k := 0; k < j; k++ {
+				sum -= lower[i][k] * lower[j][k]
+			}
+			if i == j {
+				lower[i][i] = math.Sqrt(math.Max(sum, 1e-300))
+			} else {
+				lower[i][j] = sum / lower[j][j]
+			}
+		}
+	}
+
+	// Solve L*y = b, and then L^T*x = y.
+	res := make([]float64, n)
+	for i := 0; i < n; i++ {
+		sum := rhs[i]
+		for k := 0; k < i; k++ {
+			sum -= lower[i][k] * res[k]
+		}
+		res[i] = sum / lower[i][i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		sum := res[i]
+		for k := i + 1; k < n; k++ {
+			sum -= lower[k][i] * res[k]
+		}
+		res[i] = sum / lower[i][i]
+	}
+	return res
+}
@@ -0,0 +1,126 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestAssemblyAlign(t *testing.T) {
+	box := NewAnchoredSolid(&model3d.Rect{MaxVal: model3d.XYZ(2, 2, 1)})
+	box.AddAnchor("top", NewAnchor(model3d.XYZ(1, 1, 1), model3d.Z(-1), model3d.X(1)))
+	lid := NewAnchoredSolid(&model3d.Rect{
+		MinVal: model3d.XYZ(-1, -1, 0),
+		MaxVal: model3d.XYZ(1, 1, 0.2),
+	})
+	lid.AddAnchor("bottom", NewAnchor(model3d.Coord3D{}, model3d.Z(-1), model3d.X(1)))
+
+	// Start the lid out of place.
+	lid = lid.Transform(model3d.JoinedTransform{
+		model3d.Rotation(model3d.XYZ(1, 2, 3).Normalize(), 0.5),
+		&model3d.Translate{Offset: model3d.XYZ(3, -1, 2)},
+	})
+
+	a := NewAssembly()
+	a.AddPart("box", box, true)
+	a.AddPart("lid", lid, false)
+	a.Align("lid.bottom", "box.top")
+	if err := a.Solve(); err != nil {
+		t.Fatal(err)
+	}
+	placed := a.Placed("lid")
+	if !placed.Contains(model3d.XYZ(1.5, 1.5, 1.1)) || placed.Contains(model3d.XYZ(1.5, 1.5, 1.3)) {
+		t.Error("lid is not on top of box")
+	}
+	if a.Placed("box").Anchor("top").Origin.Dist(model3d.XYZ(1, 1, 1)) > 1e-8 {
+		t.Error("fixed part should not move")
+	}
+}
+
+func TestAssemblyConstraints(t *testing.T) {
+	base := NewAnchoredSolid(&model3d.Rect{MaxVal: model3d.XYZ(1, 1, 1)})
+	base.AddAnchor("hole", NewAnchor(model3d.XYZ(0.5, 0.5, 1), model3d.Z(1), model3d.X(1)))
+	base.AddAnchor("corner", NewAnchor(model3d.Coord3D{}, model3d.X(1), model3d.Y(1)))
+
+	shaft := NewAnchoredSolid(&model3d.Cylinder{P2: model3d.Z(2), Radius: 0.1})
+	shaft.AddAnchor("axis", NewAnchor(model3d.Coord3D{}, model3d.Z(1), model3d.X(1)))
+	shaft = shaft.Transform(model3d.JoinedTransform{
+		model3d.Rotation(model3d.X(1), 0.3),
+		&model3d.Translate{Offset: model3d.XYZ(0.2, 0.7, 1.5)},
+	})
+
+	arm := NewAnchoredSolid(&model3d.Rect{MaxVal: model3d.XYZ(0.1, 0.1, 1)})
+	arm.AddAnchor("end", NewAnchor(model3d.Coord3D{}, model3d.Z(1), model3d.X(1)))
+	arm = arm.Transform(&model3d.Translate{Offset: model3d.XYZ(2, 2, 2)})
+
+	a := NewAssembly()
+	a.AddPart("base", base, true)
+	a.AddPart("shaft", shaft, false)
+	a.AddPart("arm", arm, false)
+	a.Concentric("shaft.axis", "base.hole")
+	a.Distance("shaft.axis", "base.hole", 0.25)
+	a.Distance("arm.end", "base.corner", 3)
+	a.Angle("arm.end", "base.corner", math.Pi/3)
+	if err := a.Solve(); err != nil {
+		t.Fatal(err)
+	}
+
+	axis := a.Placed("shaft").Anchor("axis")
+	if math.Abs(math.Abs(axis.ZAxis().Z)-1) > 1e-5 {
+		t.Errorf("shaft not vertical: %v", axis.ZAxis())
+	}
+	if d := axis.Origin.Dist(model3d.XYZ(0.5, 0.5, axis.Origin.Z)); d > 1e-5 {
+		t.Errorf("shaft off center by %f", d)
+	}
+	if d := math.Abs(axis.Origin.Z - 1); math.Abs(d-0.25) > 1e-5 {
+		t.Errorf("unexpected shaft offset %f", d)
+	}
+	end := a.Placed("arm").Anchor("end")
+	if d := end.Origin.Norm(); math.Abs(d-3) > 1e-5 {
+		t.Errorf("unexpected arm distance %f", d)
+	}
+	if angle := math.Acos(end.ZAxis().X); math.Abs(angle-math.Pi/3) > 1e-4 {
+		t.Errorf("unexpected arm angle %f", angle)
+	}
+}
+
+func TestAssemblyInconsistent(t *testing.T) {
+	base := NewAnchoredSolid(&model3d.Rect{MaxVal: model3d.XYZ(1, 1, 1)})
+	base.AddAnchor("a", NewAnchor(model3d.Coord3D{}, model3d.Z(1), model3d.X(1)))
+	base.AddAnchor("b", NewAnchor(model3d.X(1), model3d.Z(1), model3d.X(1)))
+	part := NewAnchoredSolid(&model3d.Rect{MaxVal: model3d.XYZ(1, 1, 1)})
+	part.AddAnchor("c", NewAnchor(model3d.Coord3D{}, model3d.Z(1), model3d.X(1)))
+
+	a := NewAssembly()
+	a.AddPart("base", base, true)
+	a.AddPart("part", part, false)
+	a.Coincident("part.c", "base.a")
+	a.Coincident("part.c", "base.b")
+	if a.Solve() == nil {
+		t.Error("expected error for inconsistent constraints")
+	}
+}
+
+func TestSolveDampedSystem(t *testing.T) {
+	mat := [][]float64{
+		{4, 1, 0},
+		{1, 3, 1},
+		{0, 1, 2},
+	}
+	rhs := []float64{1, 2, 3}
+	damping := 0.5
+	solution := solveDampedSystem(mat, rhs, damping)
+	for i, row := range mat {
+		var product float64
+		for j, x := range row {
+			if i == j {
+				x += damping * (1 + x)
+			}
+			product += x * solution[j]
+		}
+		if math.Abs(product-rhs[i]) > 1e-8 {
+			t.Errorf("row %d: expected %f but got %f", i, rhs[i], product)
+		}
+	}
+}
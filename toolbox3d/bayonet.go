@@ -0,0 +1,155 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A Bayonet describes a twist-lock coupling, where lugs on
+// a cylindrical plug slide into L-shaped slots in a
+// socket and lock after a partial turn.
+//
+// This can be used as an alternative to a ScrewSolid for
+// lids and caps which should close with a quarter turn.
+type Bayonet struct {
+	// P1 is the center of the socket's opening, which is
+	// also where the base of the plug rests when the
+	// coupling is closed.
+	P1 model3d.Coord3D
+
+	// P2 is the center of the bottom of the socket, which
+	// is also where the tip of the plug rests.
+	P2 model3d.Coord3D
+
+	// Radius is the radius of the socket, not including
+	// the slots.
+	Radius float64
+
+	// Clearance is the gap left between the plug and the
+	// socket on every side.
+	Clearance float64
+
+	// NumLugs is the number of lugs, evenly spaced around
+	// the plug.
+	NumLugs int
+
+	// LugDistance is the distance from P1 to the center of
+	// each lug along the axis.
+	LugDistance float64
+
+	// LugHeight is how far each lug sticks out past the
+	// plug's radius.
+	LugHeight float64
+
+	// LugWidth is the size of each lug along the axis.
+	LugWidth float64
+
+	// LugAngle is the angle (in radians) spanned by each
+	// lug around the axis.
+	LugAngle float64
+
+	// TwistAngle is the angle (in radians) that the plug
+	// must be turned counter-clockwise (around the axis
+	// pointing from P1 to P2) to lock it.
+	TwistAngle float64
+}
+
+// Plug creates the male part of the coupling: a cylinder
+// with lugs on the outside.
+//
+// The plug is in its inserted but unlocked orientation.
+// To model a locked coupling, rotate the plug by
+// TwistAngle around the axis.
+func (b *Bayonet) Plug() model3d.Solid {
+	r := b.Radius - b.Clearance
+	outerRadius := r + b.LugHeight
+	minZ := b.LugDistance - b.LugWidth/2
+	maxZ := b.LugDistance + b.LugWidth/2
+	cyl := &model3d.Cylinder{P1: b.P1, P2: b.P2, Radius: outerRadius}
+	return model3d.CheckedFuncSolid(cyl.Min(), cyl.Max(), func(c model3d.Coord3D) bool {
+		z, radius, theta := b.cylindricalCoords(c)
+		if z < 0 || z > b.height() || radius > outerRadius {
+			return false
+		}
+		if radius <= r {
+			return true
+		}
+		return z >= minZ && z <= maxZ && b.lugIndex(theta, b.LugAngle/2) != -1
+	})
+}
+
+// Socket creates the cutout for the female part of the
+// coupling, which should be subtracted from a larger
+// solid.
+//
+// The cutout includes the bore and an L-shaped slot for
+// each lug, which is open at P1.
+func (b *Bayonet) Socket() model3d.Solid {
+	outerRadius := b.Radius + b.LugHeight
+	angleClearance := b.Clearance / b.Radius
+	minZ := b.LugDistance - b.LugWidth/2 - b.Clearance
+	maxZ := b.LugDistance + b.LugWidth/2 + b.Clearance
+	halfAngle := b.LugAngle/2 + angleClearance
+	cyl := &model3d.Cylinder{P1: b.P1, P2: b.P2, Radius: outerRadius}
+	return model3d.CheckedFuncSolid(cyl.Min(), cyl.Max(), func(c model3d.Coord3D) bool {
+		z, radius, theta := b.cylindricalCoords(c)
+		if z < 0 || z > b.height() || radius > outerRadius {
+			return false
+		}
+		if radius <= b.Radius {
+			return true
+		}
+		if z > maxZ {
+			return false
+		}
+		if b.lugIndex(theta, halfAngle) != -1 {
+			// Vertical entry channel.
+			return true
+		}
+		if z < minZ {
+			return false
+		}
+		// Horizontal locking channel, which sweeps the lug
+		// around by the twist angle.
+		centerTheta := theta - b.TwistAngle/2
+		return b.lugIndex(centerTheta, halfAngle+b.TwistAngle/2) != -1
+	})
+}
+
+func (b *Bayonet) height() float64 {
+	return b.P2.Dist(b.P1)
+}
+
+func (b *Bayonet) cylindricalCoords(c model3d.Coord3D) (z, radius, theta float64) {
+	axis := b.P2.Sub(b.P1).Normalize()
+	b1, b2 := axis.OrthoBasis()
+
+	// Make sure basis obeys right-hand rule.
+	if b1.Cross(b2).Dot(axis) < 0 {
+		b2, b1 = b1, b2
+	}
+
+	offset := c.Sub(b.P1)
+	z = offset.Dot(axis)
+	x, y := offset.Dot(b1), offset.Dot(b2)
+	return z, math.Sqrt(x*x + y*y), math.Atan2(y, x)
+}
+
+// lugIndex finds the lug whose center angle is within
+// halfAngle of theta, or returns -1.
+func (b *Bayonet) lugIndex(theta, halfAngle float64) int {
+	spacing := 2 * math.Pi / float64(b.NumLugs)
+	for i := 0; i < b.NumLugs; i++ {
+		diff := math.Mod(theta-spacing*float64(i), 2*math.Pi)
+		if diff > math.Pi {
+			diff -= 2 * math.Pi
+		} else if diff < -math.Pi {
+			diff += 2 * math.Pi
+		}
+		if math.Abs(diff) <= halfAngle {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,47 @@
+package toolbox3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestBayonetFits(t *testing.T) {
+	b := &Bayonet{
+		P2:          model3d.Z(1),
+		Radius:      1,
+		Clearance:   0.02,
+		NumLugs:     3,
+		LugDistance: 0.6,
+		LugHeight:   0.15,
+		LugWidth:    0.2,
+		LugAngle:    0.4,
+		TwistAngle:  math.Pi / 4,
+	}
+	socket := b.Socket()
+	plug := b.Plug()
+	locked := model3d.RotateSolid(plug, model3d.Z(1), b.TwistAngle)
+
+	min, max := plug.Min(), plug.Max()
+	for i := 0; i < 20000; i++ {
+		c := model3d.XYZ(rand.Float64(), rand.Float64(), rand.Float64())
+		c = min.Add(c.Mul(max.Sub(min)))
+		if plug.Contains(c) && !socket.Contains(c) {
+			t.Fatalf("unlocked plug intersects socket at %v", c)
+		}
+		if locked.Contains(c) && !socket.Contains(c) {
+			t.Fatalf("locked plug intersects socket at %v", c)
+		}
+	}
+
+	// The locked lug should not be able to slide out.
+	lockedLug := model3d.XYZ(math.Cos(b.TwistAngle), math.Sin(b.TwistAngle), 0).Scale(1.05)
+	if !locked.Contains(lockedLug.Add(model3d.Z(b.LugDistance))) {
+		t.Error("expected locked lug")
+	}
+	if socket.Contains(lockedLug.Add(model3d.Z(0.1))) {
+		t.Error("locked lug should be blocked")
+	}
+}
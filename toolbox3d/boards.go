@@ -0,0 +1,136 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// Board templates describe common development boards, with
+// the origin at the bottom-left corner of the board and
+// all dimensions in millimeters.
+//
+// Dimensions come from the manufacturers' mechanical
+// drawings, but it is always a good idea to measure a real
+// board before printing a tight-fitting case.
+
+// BoardRPi4 creates a template for a Raspberry Pi 4 Model B
+// (85x56 mm, 1.4 mm thick, M2.5 mounting holes).
+func BoardRPi4() *PCB {
+	return boardTemplate(85, 56, 3, 2.7, [][2]float64{
+		{3.5, 3.5}, {61.5, 3.5}, {3.5, 52.5}, {61.5, 52.5},
+	})
+}
+
+// BoardRPiZero creates a template for a Raspberry Pi Zero
+// (65x30 mm, 1.4 mm thick, M2.5 mounting holes).
+func BoardRPiZero() *PCB {
+	return boardTemplate(65, 30, 3, 2.75, [][2]float64{
+		{3.5, 3.5}, {61.5, 3.5}, {3.5, 26.5}, {61.5, 26.5},
+	})
+}
+
+// BoardArduinoUno creates a template for an Arduino Uno
+// (68.6x53.3 mm, 1.6 mm thick, M3 mounting holes).
+//
+// The USB and power jacks are on the left (x=0) edge.
+func BoardArduinoUno() *PCB {
+	return boardTemplate(68.58, 53.34, 0, 3.2, [][2]float64{
+		{13.97, 2.54}, {15.24, 50.8}, {66.04, 7.62}, {66.04, 35.56},
+	})
+}
+
+// BoardArduinoNano creates a template for an Arduino Nano
+// (17.8x43.2 mm, 1.6 mm thick, 1.8 mm mounting holes).
+func BoardArduinoNano() *PCB {
+	return boardTemplate(17.78, 43.18, 0, 1.8, [][2]float64{
+		{1.27, 1.27}, {16.51, 1.27}, {1.27, 41.91}, {16.51, 41.91},
+	})
+}
+
+// BoardESP32DevKitC creates a template for an Espressif
+// ESP32-DevKitC (54.4x27.9 mm, 1.6 mm thick).
+//
+// This board has no mounting holes, so it should be held
+// by its edges or headers.
+func BoardESP32DevKitC() *PCB {
+	return boardTemplate(54.4, 27.9, 0, 0, nil)
+}
+
+// Solid creates a 3D model of the board, with its bottom
+// at z=0 and its holes drilled through.
+//
+// This is useful for previewing a board in its enclosure.
+func (p *PCB) Solid(thickness float64) model3d.Solid {
+	board := model3d.ProfileSolid(p.OutlineSolid(0), 0, thickness)
+	if len(p.Holes) == 0 {
+		return board
+	}
+	return &model3d.SubtractedSolid{
+		Positive: board,
+		Negative: p.HolesSolid(-thickness, 2*thickness),
+	}
+}
+
+// HolesSolid creates cylinders for all of the board's
+// holes, extending from minZ to maxZ.
+//
+// This can be subtracted from a part to leave room for
+// screws or pins passing through the board.
+func (p *PCB) HolesSolid(minZ, maxZ float64) model3d.Solid {
+	if len(p.Holes) == 0 {
+		panic("PCB has no holes")
+	}
+	var res model3d.JoinedSolid
+	for _, h := range p.Holes {
+		res = append(res, &model3d.Cylinder{
+			P1:     model3d.XYZ(h.Center.X, h.Center.Y, minZ),
+			P2:     model3d.XYZ(h.Center.X, h.Center.Y, maxZ),
+			Radius: h.Diameter / 2,
+		})
+	}
+	return res.Optimize()
+}
+
+func boardTemplate(width, height, cornerRadius, holeDiameter float64,
+	holes [][2]float64) *PCB {
+	res := &PCB{Outline: boardRoundedRect(width, height, cornerRadius)}
+	for _, h := range holes {
+		res.Holes = append(res.Holes, PCBHole{
+			Center:   model2d.NewCoordArray(h),
+			Diameter: holeDiameter,
+			Plated:   true,
+		})
+	}
+	return res
+}
+
+// boardRoundedRect creates an outline of a rectangle with
+// rounded corners, with segments ordered clockwise like
+// model2d.NewMeshRect.
+func boardRoundedRect(width, height, radius float64) *model2d.Mesh {
+	if radius == 0 {
+		return model2d.NewMeshRect(model2d.Coord{}, model2d.XY(width, height))
+	}
+	const cornerSegments = 8
+	var points []model2d.Coord
+	corners := []model2d.Coord{
+		model2d.XY(width-radius, radius),
+		model2d.XY(width-radius, height-radius),
+		model2d.XY(radius, height-radius),
+		model2d.XY(radius, radius),
+	}
+	for i, center := range corners {
+		start := float64(i-1) * math.Pi / 2
+		for j := 0; j <= cornerSegments; j++ {
+			theta := start + float64(j)*math.Pi/(2*cornerSegments)
+			points = append(points, center.Add(model2d.XY(math.Cos(theta), math.Sin(theta)).Scale(radius)))
+		}
+	}
+	res := model2d.NewMesh()
+	for i, p := range points {
+		res.Add(&model2d.Segment{points[(i+1)%len(points)], p})
+	}
+	return res
+}
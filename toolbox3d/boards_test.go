@@ -0,0 +1,71 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestBoardTemplates(t *testing.T) {
+	boards := map[string]struct {
+		PCB      *PCB
+		Size     model2d.Coord
+		NumHoles int
+	}{
+		"RPi4":    {BoardRPi4(), model2d.XY(85, 56), 4},
+		"RPiZero": {BoardRPiZero(), model2d.XY(65, 30), 4},
+		"Uno":     {BoardArduinoUno(), model2d.XY(68.58, 53.34), 4},
+		"Nano":    {BoardArduinoNano(), model2d.XY(17.78, 43.18), 4},
+		"ESP32":   {BoardESP32DevKitC(), model2d.XY(54.4, 27.9), 0},
+	}
+	for name, b := range boards {
+		min, max := b.PCB.Outline.Min(), b.PCB.Outline.Max()
+		if min.Norm() > 1e-8 || max.Dist(b.Size) > 1e-8 {
+			t.Errorf("%s: unexpected bounds %v %v", name, min, max)
+		}
+		if !b.PCB.Outline.Manifold() {
+			t.Errorf("%s: outline is not manifold", name)
+		}
+		if _, n := b.PCB.Outline.RepairNormals(1e-8); n != 0 {
+			t.Errorf("%s: outline has %d bad normals", name, n)
+		}
+		area := b.PCB.Outline.Area()
+		if area > b.Size.X*b.Size.Y+1e-8 || area < b.Size.X*b.Size.Y-10 {
+			t.Errorf("%s: unexpected area %f", name, area)
+		}
+		if len(b.PCB.Holes) != b.NumHoles {
+			t.Errorf("%s: expected %d holes but got %d", name, b.NumHoles, len(b.PCB.Holes))
+		}
+		outline := b.PCB.OutlineSolid(0)
+		for _, h := range b.PCB.Holes {
+			// Each hole should have some material around it.
+			for i := 0; i < 8; i++ {
+				theta := float64(i) * math.Pi / 4
+				p := h.Center.Add(model2d.XY(math.Cos(theta), math.Sin(theta)).Scale(h.Diameter/2 + 0.3))
+				if !outline.Contains(p) {
+					t.Errorf("%s: hole %v is too close to the edge", name, h.Center)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestPCBSolid(t *testing.T) {
+	pcb := BoardRPi4()
+	solid := pcb.Solid(1.4)
+	if !solid.Contains(model3d.XYZ(40, 30, 0.7)) {
+		t.Error("board should be solid")
+	}
+	if solid.Contains(model3d.XYZ(3.5, 3.5, 0.7)) {
+		t.Error("hole should be empty")
+	}
+	if solid.Contains(model3d.XYZ(0.3, 0.3, 0.7)) {
+		t.Error("corner should be rounded")
+	}
+	if solid.Contains(model3d.XYZ(40, 30, 1.5)) {
+		t.Error("board is too thick")
+	}
+}
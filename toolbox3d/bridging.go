@@ -0,0 +1,251 @@
+package toolbox3d
+
+import (
+	"math"
+	"sort"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const (
+	DefaultBridgeAnalyzerMaxAngle  = math.Pi / 36
+	DefaultBridgeAnalyzerNumPoints = 2000
+)
+
+// A BridgeRegion is a flat, downward-facing region of a
+// mesh which must be printed over empty space, such as the
+// ceiling of a tunnel or the underside of a shelf.
+type BridgeRegion struct {
+	// Face contains the triangles of the region.
+	Face *model3d.PlanarFace
+
+	// Height is the position of the region along the
+	// build direction.
+	Height float64
+
+	// Area is the area of the region.
+	Area float64
+
+	// Anchored is true if any edge of the region rests on
+	// a wall below it.
+	// Regions which are not anchored are completely
+	// unsupported, and have an infinite MaxSpan.
+	Anchored bool
+
+	// MaxSpan is the length of the longest bridge needed
+	// to print the region.
+	//
+	// This is twice the largest distance from any point in
+	// the region to an anchored edge, so it is the width
+	// of a tunnel's ceiling, or the diameter of a round
+	// ceiling supported around its edge.
+	// For a shelf anchored on only one side, it is twice
+	// the shelf's depth, since the free edge must be
+	// printed as a cantilever.
+	MaxSpan float64
+
+	// SpanCenter is the point in the region furthest from
+	// an anchored edge, i.e. the middle of the longest
+	// span.
+	SpanCenter model3d.Coord3D
+}
+
+// A BridgeAnalyzer finds the regions of a mesh which must
+// be printed as bridges, and estimates how far each
+// bridge spans, so that designers can check the spans
+// against their printer's bridging capability.
+type BridgeAnalyzer struct {
+	// BuildDirection is the upward direction when the part
+	// is printed.
+	// If this is the zero vector, the Z axis is used.
+	BuildDirection model3d.Coord3D
+
+	// MaxAngle is the largest angle between a face's
+	// normal and the downward direction for the face to
+	// count as a bridge.
+	//
+	// If 0, DefaultBridgeAnalyzerMaxAngle is used.
+	MaxAngle float64
+
+	// PlaneTolerance is the distance within which vertices
+	// of a region must lie from a common plane.
+	//
+	// If 0, a small fraction of the mesh's size is used.
+	PlaneTolerance float64
+
+	// NumPoints is the approximate number of points at
+	// which each region's span is measured.
+	//
+	// If 0, DefaultBridgeAnalyzerNumPoints is used.
+	NumPoints int
+}
+
+// Analyze finds the bridge regions of a mesh, sorted by
+// decreasing span.
+//
+// Regions touching the bottom of the mesh are assumed to
+// rest on the build plate, and are not included.
+func (b *BridgeAnalyzer) Analyze(m *model3d.Mesh) []*BridgeRegion {
+	up := b.buildDirection()
+	minCos := math.Cos(b.maxAngle())
+
+	vertices := m.VertexSlice()
+	if len(vertices) == 0 {
+		return nil
+	}
+	minHeight := math.Inf(1)
+	for _, v := range vertices {
+		minHeight = math.Min(minHeight, v.Dot(up))
+	}
+	tolerance := b.PlaneTolerance
+	if tolerance == 0 {
+		tolerance = m.Max().Dist(m.Min()) * 1e-5
+	}
+
+	var res []*BridgeRegion
+	for _, face := range m.ExtractPlanarFaces(tolerance) {
+		if -face.Plane.Normal.Dot(up) < minCos {
+			continue
+		}
+		// Regions touching the bottom of the mesh rest on
+		// the build plate.
+		onPlate := false
+		for _, v := range face.Mesh.VertexSlice() {
+			if v.Dot(up)-minHeight <= tolerance {
+				onPlate = true
+				break
+			}
+		}
+		if onPlate {
+			continue
+		}
+		height := bridgeRegionCenter(face.Mesh).Dot(up)
+		res = append(res, b.analyzeRegion(m, face, up, height, tolerance))
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].MaxSpan > res[j].MaxSpan
+	})
+	return res
+}
+
+func (b *BridgeAnalyzer) analyzeRegion(m *model3d.Mesh, face *model3d.PlanarFace,
+	up model3d.Coord3D, height, tolerance float64) *BridgeRegion {
+	plane := &model3d.Plane{Normal: up, Offset: height}
+	region := &BridgeRegion{
+		Face:   face,
+		Height: height,
+		Area:   face.Mesh.Area(),
+	}
+
+	// An edge is anchored if the triangle on the other
+	// side of it goes downward from the region.
+	anchors := model2d.NewMesh()
+	face.Mesh.Iterate(func(t *model3d.Triangle) {
+		for i := 0; i < 3; i++ {
+			p1, p2 := t[i], t[(i+1)%3]
+			if len(face.Mesh.Find(p1, p2)) != 1 {
+				continue
+			}
+			for _, neighbor := range m.Find(p1, p2) {
+				if face.Mesh.Contains(neighbor) {
+					continue
+				}
+				for _, c := range neighbor {
+					if c != p1 && c != p2 && c.Dot(up) < height-tolerance {
+						anchors.Add(&model2d.Segment{plane.To2D(p1), plane.To2D(p2)})
+					}
+				}
+			}
+		}
+	})
+	if len(anchors.SegmentSlice()) == 0 {
+		region.MaxSpan = math.Inf(1)
+		region.SpanCenter = bridgeRegionCenter(face.Mesh)
+		return region
+	}
+	region.Anchored = true
+
+	sdf := model2d.MeshToSDF(anchors)
+	spacing := math.Sqrt(region.Area / float64(b.numPoints()))
+	var maxDist float64
+	var maxPoint model2d.Coord
+	face.Mesh.Iterate(func(t *model3d.Triangle) {
+		p1, p2, p3 := plane.To2D(t[0]), plane.To2D(t[1]), plane.To2D(t[2])
+		maxEdge := math.Max(p1.Dist(p2), math.Max(p2.Dist(p3), p3.Dist(p1)))
+		n := int(math.Ceil(maxEdge / spacing))
+		if n < 1 {
+			n = 1
+		}
+		for i := 0; i <= n; i++ {
+			for j := 0; i+j <= n; j++ {
+				p := p1.Add(p2.Sub(p1).Scale(float64(i) / float64(n))).Add(
+					p3.Sub(p1).Scale(float64(j) / float64(n)))
+				if dist := math.Abs(sdf.SDF(p)); dist > maxDist {
+					maxDist = dist
+					maxPoint = p
+				}
+			}
+		}
+	})
+	region.MaxSpan = 2 * maxDist
+	region.SpanCenter = plane.From2D(maxPoint)
+	return region
+}
+
+// bridgeRegionCenter computes the area-weighted center of
+// a flat region.
+func bridgeRegionCenter(m *model3d.Mesh) model3d.Coord3D {
+	var sum model3d.Coord3D
+	var totalArea float64
+	m.Iterate(func(t *model3d.Triangle) {
+		area := t.Area()
+		sum = sum.Add(t[0].Add(t[1]).Add(t[2]).Scale(area / 3))
+		totalArea += area
+	})
+	return sum.Scale(1 / totalArea)
+}
+
+func (b *BridgeAnalyzer) buildDirection() model3d.Coord3D {
+	if (b.BuildDirection == model3d.Coord3D{}) {
+		return model3d.Z(1)
+	}
+	return b.BuildDirection.Normalize()
+}
+
+func (b *BridgeAnalyzer) maxAngle() float64 {
+	if b.MaxAngle == 0 {
+		return DefaultBridgeAnalyzerMaxAngle
+	}
+	return b.MaxAngle
+}
+
+func (b *BridgeAnalyzer) numPoints() int {
+	if b.NumPoints == 0 {
+		return DefaultBridgeAnalyzerNumPoints
+	}
+	return b.NumPoints
+}
+
+// MaxBridgeSpan gets the largest span of any region, or 0
+// if there are no regions.
+func MaxBridgeSpan(regions []*BridgeRegion) float64 {
+	var res float64
+	for _, r := range regions {
+		res = math.Max(res, r.MaxSpan)
+	}
+	return res
+}
+
+// BridgesExceeding gets the regions whose span is larger
+// than the given limit, such as the longest bridge that a
+// printer can reliably print.
+func BridgesExceeding(regions []*BridgeRegion, maxSpan float64) []*BridgeRegion {
+	var res []*BridgeRegion
+	for _, r := range regions {
+		if r.MaxSpan > maxSpan {
+			res = append(res, r)
+		}
+	}
+	return res
+}
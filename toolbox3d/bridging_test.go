@@ -0,0 +1,76 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestBridgeAnalyzerTunnel(t *testing.T) {
+	solid := &model3d.SubtractedSolid{
+		Positive: model3d.NewRect(model3d.Coord3D{}, model3d.XYZ(10, 4, 5)),
+		Negative: model3d.NewRect(model3d.XYZ(2, -1, -1), model3d.XYZ(8, 5, 3)),
+	}
+	mesh := model3d.MarchingCubesSearch(solid, 0.1, 8)
+
+	regions := (&BridgeAnalyzer{}).Analyze(mesh)
+	if len(regions) != 1 {
+		t.Fatalf("expected 1 region but got %d", len(regions))
+	}
+	r := regions[0]
+	if !r.Anchored {
+		t.Error("expected region to be anchored")
+	}
+	if math.Abs(r.Height-3) > 0.05 {
+		t.Errorf("unexpected height: %f", r.Height)
+	}
+	if math.Abs(r.MaxSpan-6) > 0.3 {
+		t.Errorf("unexpected span: %f", r.MaxSpan)
+	}
+	if math.Abs(r.SpanCenter.X-5) > 0.3 || math.Abs(r.SpanCenter.Z-3) > 0.05 {
+		t.Errorf("unexpected span center: %v", r.SpanCenter)
+	}
+	if math.Abs(r.Area-24) > 2 {
+		t.Errorf("unexpected area: %f", r.Area)
+	}
+
+	if n := len(BridgesExceeding(regions, 7)); n != 0 {
+		t.Errorf("expected no regions exceeding limit but got %d", n)
+	}
+	if n := len(BridgesExceeding(regions, 5)); n != 1 {
+		t.Errorf("expected 1 region exceeding limit but got %d", n)
+	}
+
+	// Printed on its side, the tunnel has no ceiling, but
+	// the part above the tunnel overhangs on one side.
+	regions = (&BridgeAnalyzer{BuildDirection: model3d.X(1)}).Analyze(mesh)
+	if len(regions) != 1 {
+		t.Fatalf("expected 1 region but got %d", len(regions))
+	}
+	r = regions[0]
+	if math.Abs(r.Height-8) > 0.05 {
+		t.Errorf("unexpected height: %f", r.Height)
+	}
+	if math.Abs(r.MaxSpan-6) > 0.3 {
+		t.Errorf("unexpected span: %f", r.MaxSpan)
+	}
+}
+
+func TestBridgeAnalyzerFloating(t *testing.T) {
+	solid := model3d.JoinedSolid{
+		model3d.NewRect(model3d.Coord3D{}, model3d.XYZ(2, 2, 1)),
+		model3d.NewRect(model3d.XYZ(0, 0, 2), model3d.XYZ(2, 2, 3)),
+	}
+	mesh := model3d.MarchingCubesSearch(solid, 0.1, 8)
+	regions := (&BridgeAnalyzer{}).Analyze(mesh)
+	if len(regions) != 1 {
+		t.Fatalf("expected 1 region but got %d", len(regions))
+	}
+	if regions[0].Anchored || !math.IsInf(regions[0].MaxSpan, 1) {
+		t.Errorf("expected unanchored region but got span %f", regions[0].MaxSpan)
+	}
+	if !math.IsInf(MaxBridgeSpan(regions), 1) {
+		t.Errorf("unexpected max span: %f", MaxBridgeSpan(regions))
+	}
+}
@@ -0,0 +1,198 @@
+package toolbox3d
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const (
+	// DefaultFluidDensity is the density of fresh water in
+	// grams per cubic millimeter, for models measured in
+	// millimeters with masses in grams.
+	DefaultFluidDensity = 0.001
+
+	flotationSearchIters = 64
+)
+
+// Flotation describes how a model floats upright in a
+// fluid, as computed by Float.
+//
+// The fluid's surface is the plane z=Waterline.
+type Flotation struct {
+	// Waterline is the z coordinate of the fluid surface.
+	Waterline float64
+
+	// Draft is the depth of the lowest point of the model
+	// below the waterline.
+	Draft float64
+
+	// Freeboard is the height of the highest point of the
+	// model above the waterline.
+	Freeboard float64
+
+	// SubmergedVolume is the volume of fluid displaced by
+	// the model.
+	SubmergedVolume float64
+
+	// CenterOfBuoyancy is the centroid of the submerged
+	// part of the model.
+	CenterOfBuoyancy model3d.Coord3D
+
+	// CenterOfMass is the model's center of mass.
+	CenterOfMass model3d.Coord3D
+
+	// WaterplaneArea is the area of the cross-section of
+	// the model at the waterline.
+	WaterplaneArea float64
+
+	// MetacentricHeightX and MetacentricHeightY measure the
+	// initial stability of the model for small rotations
+	// around the x- and y-axes, respectively.
+	//
+	// Larger values resist tipping more strongly, and
+	// negative values mean that the model will capsize
+	// around the corresponding axis.
+	MetacentricHeightX float64
+	MetacentricHeightY float64
+}
+
+// Stable checks if the model resists small rotations
+// around both the x- and y-axes.
+func (f *Flotation) Stable() bool {
+	return f.MetacentricHeightX > 0 && f.MetacentricHeightY > 0
+}
+
+// Float computes the waterline of a closed mesh floating
+// in a fluid without rotating, along with metrics for the
+// model's stability.
+//
+// The mass and fluidDensity should use consistent units,
+// such that mass/fluidDensity is the displaced volume.
+// For example, see DefaultFluidDensity.
+//
+// The model must be upright in the sense that the center
+// of buoyancy lies directly below the center of mass;
+// otherwise, the model would rotate, and the stability
+// metrics do not describe a resting state.
+//
+// An error is returned if the model is too heavy to
+// float.
+func Float(m *model3d.Mesh, mass, fluidDensity float64,
+	centerOfMass model3d.Coord3D) (*Flotation, error) {
+	targetVolume := mass / fluidDensity
+	if total := m.Volume(); targetVolume > total {
+		return nil, fmt.Errorf("float: displacement %f exceeds volume %f", targetVolume, total)
+	}
+
+	min, max := m.Min(), m.Max()
+	lo, hi := min.Z, max.Z
+	for i := 0; i < flotationSearchIters; i++ {
+		mid := (lo + hi) / 2
+		if v, _ := submergedVolume(m, mid); v < targetVolume {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	waterline := (lo + hi) / 2
+	volume, buoyancyCenter := submergedVolume(m, waterline)
+
+	area, ixx, iyy := waterplaneMoments(m.SliceZ(waterline))
+	bg := centerOfMass.Z - buoyancyCenter.Z
+	var gmX, gmY float64
+	if volume > 0 {
+		gmX = ixx/volume - bg
+		gmY = iyy/volume - bg
+	}
+
+	return &Flotation{
+		Waterline:          waterline,
+		Draft:              waterline - min.Z,
+		Freeboard:          max.Z - waterline,
+		SubmergedVolume:    volume,
+		CenterOfBuoyancy:   buoyancyCenter,
+		CenterOfMass:       centerOfMass,
+		WaterplaneArea:     area,
+		MetacentricHeightX: gmX,
+		MetacentricHeightY: gmY,
+	}, nil
+}
+
+// FloatUniform is like Float for a solid model of uniform
+// density.
+func FloatUniform(m *model3d.Mesh, density, fluidDensity float64) (*Flotation, error) {
+	return Float(m, density*m.Volume(), fluidDensity, m.Centroid())
+}
+
+// submergedVolume computes the volume and centroid of the
+// part of a closed mesh below the plane z=waterline.
+//
+// Using a point on the plane as the apex of every
+// tetrahedron means that the missing cap of the clipped
+// mesh contributes nothing, so only the clipped triangles
+// need to be considered.
+func submergedVolume(m *model3d.Mesh, waterline float64) (float64, model3d.Coord3D) {
+	apex := model3d.Z(waterline)
+	var volume float64
+	var moment model3d.Coord3D
+	m.Iterate(func(t *model3d.Triangle) {
+		poly := clipTriangleBelow(t, waterline)
+		for i := 2; i < len(poly); i++ {
+			p1, p2, p3 := poly[0].Sub(apex), poly[i-1].Sub(apex), poly[i].Sub(apex)
+			v := p1.Dot(p2.Cross(p3)) / 6
+			volume += v
+			moment = moment.Add(p1.Add(p2).Add(p3).Scale(v / 4))
+		}
+	})
+	if volume == 0 {
+		return 0, apex
+	}
+	return volume, moment.Scale(1 / volume).Add(apex)
+}
+
+// clipTriangleBelow gets the polygon of the part of a
+// triangle below a plane.
+func clipTriangleBelow(t *model3d.Triangle, z float64) []model3d.Coord3D {
+	var res []model3d.Coord3D
+	for i, p1 := range t {
+		p2 := t[(i+1)%3]
+		if p1.Z <= z {
+			res = append(res, p1)
+		}
+		if (p1.Z < z) != (p2.Z < z) && p1.Z != p2.Z {
+			frac := (z - p1.Z) / (p2.Z - p1.Z)
+			res = append(res, p1.Add(p2.Sub(p1).Scale(frac)))
+		}
+	}
+	return res
+}
+
+// waterplaneMoments computes the area of a 2D mesh and its
+// second moments of area about axes through its centroid
+// parallel to the x- and y-axes.
+func waterplaneMoments(m *model2d.Mesh) (area, ixx, iyy float64) {
+	var cx, cy, sxx, syy float64
+	m.Iterate(func(s *model2d.Segment) {
+		p1, p2 := s[0], s[1]
+		cross := p1.X*p2.Y - p2.X*p1.Y
+		area += cross / 2
+		cx += cross * (p1.X + p2.X) / 6
+		cy += cross * (p1.Y + p2.Y) / 6
+		sxx += cross * (p1.Y*p1.Y + p1.Y*p2.Y + p2.Y*p2.Y) / 12
+		syy += cross * (p1.X*p1.X + p1.X*p2.X + p2.X*p2.X) / 12
+	})
+	if area == 0 {
+		return 0, 0, 0
+	}
+	// Meshes in model2d are oriented clockwise, which
+	// negates every integral.
+	sign := math.Copysign(1, area)
+	area *= sign
+	cx, cy = cx*sign/area, cy*sign/area
+	ixx = sxx*sign - area*cy*cy
+	iyy = syy*sign - area*cx*cx
+	return
+}
@@ -0,0 +1,88 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestFloatBox(t *testing.T) {
+	box := model3d.NewMeshRect(model3d.XYZ(0, 0, 1), model3d.XYZ(2, 4, 2))
+	f, err := FloatUniform(box, 0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Flotation{
+		Waterline:          1.5,
+		Draft:              0.5,
+		Freeboard:          0.5,
+		SubmergedVolume:    4,
+		CenterOfBuoyancy:   model3d.XYZ(1, 2, 1.25),
+		CenterOfMass:       model3d.XYZ(1, 2, 1.5),
+		WaterplaneArea:     8,
+		MetacentricHeightX: 2*64.0/12/4 - 0.25,
+		MetacentricHeightY: 4*8.0/12/4 - 0.25,
+	}
+	checks := [][3]interface{}{
+		{"waterline", f.Waterline, expected.Waterline},
+		{"draft", f.Draft, expected.Draft},
+		{"freeboard", f.Freeboard, expected.Freeboard},
+		{"volume", f.SubmergedVolume, expected.SubmergedVolume},
+		{"area", f.WaterplaneArea, expected.WaterplaneArea},
+		{"GM x", f.MetacentricHeightX, expected.MetacentricHeightX},
+		{"GM y", f.MetacentricHeightY, expected.MetacentricHeightY},
+	}
+	for _, check := range checks {
+		if math.Abs(check[1].(float64)-check[2].(float64)) > 1e-8 {
+			t.Errorf("%s: expected %f but got %f", check[0], check[2], check[1])
+		}
+	}
+	if f.CenterOfBuoyancy.Dist(expected.CenterOfBuoyancy) > 1e-8 {
+		t.Errorf("unexpected center of buoyancy: %v", f.CenterOfBuoyancy)
+	}
+	if f.CenterOfMass.Dist(expected.CenterOfMass) > 1e-8 {
+		t.Errorf("unexpected center of mass: %v", f.CenterOfMass)
+	}
+	if !f.Stable() {
+		t.Error("expected box to be stable")
+	}
+
+	// A tall column should tip over.
+	column := model3d.NewMeshRect(model3d.XYZ(0, 0, 0), model3d.XYZ(1, 1, 4))
+	f, err = FloatUniform(column, 0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Stable() {
+		t.Error("expected column to be unstable")
+	}
+
+	if _, err := FloatUniform(box, 1.5, 1); err == nil {
+		t.Error("expected dense box to sink")
+	}
+}
+
+func TestFloatSphere(t *testing.T) {
+	sphere := model3d.NewMeshIcosphere(model3d.XYZ(1, 2, 3), 1, 4)
+	volume := sphere.Volume()
+
+	// A heavy weight at the bottom keeps the sphere
+	// upright.
+	f, err := Float(sphere, volume/2*0.001, 0.001, model3d.XYZ(1, 2, 2.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(f.Waterline-3) > 1e-3 || math.Abs(f.SubmergedVolume-volume/2) > 1e-5 {
+		t.Errorf("unexpected waterline %f with volume %f", f.Waterline, f.SubmergedVolume)
+	}
+	// The metacenter of a sphere is its center.
+	for _, gm := range []float64{f.MetacentricHeightX, f.MetacentricHeightY} {
+		if math.Abs(gm-0.5) > 0.02 {
+			t.Errorf("expected metacentric height 0.5 but got %f", gm)
+		}
+	}
+	if !f.Stable() {
+		t.Error("expected weighted sphere to be stable")
+	}
+}
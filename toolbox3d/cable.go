@@ -0,0 +1,220 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A CableFeature is an add-on for routing cables, such as
+// a zip-tie slot or a clip.
+//
+// Features are defined in a local frame where the surface
+// they attach to is the XY plane, the z-axis points out of
+// the surface, and the cable runs along the x-axis.
+// Use an Anchor to place a feature on a part.
+type CableFeature interface {
+	// Positive returns the material added by the feature,
+	// or nil if the feature adds no material.
+	Positive() model3d.Solid
+
+	// Negative returns the material removed by the feature,
+	// or nil if the feature removes no material.
+	Negative() model3d.Solid
+}
+
+// A PlacedCableFeature is a CableFeature positioned on a
+// part by an anchor.
+type PlacedCableFeature struct {
+	Feature CableFeature
+	Anchor  *Anchor
+}
+
+// AttachCableFeatures adds cable features to a solid.
+//
+// The positive parts of all features are added before any
+// of the negative parts are removed, so for example a
+// strain relief boss can be drilled through the host's
+// wall.
+func AttachCableFeatures(host model3d.Solid, features ...PlacedCableFeature) model3d.Solid {
+	positive := model3d.JoinedSolid{host}
+	var negative model3d.JoinedSolid
+	for _, f := range features {
+		xf := f.Anchor.ToWorld()
+		if pos := f.Feature.Positive(); pos != nil {
+			positive = append(positive, model3d.TransformSolid(xf, pos))
+		}
+		if neg := f.Feature.Negative(); neg != nil {
+			negative = append(negative, model3d.TransformSolid(xf, neg))
+		}
+	}
+	if len(negative) == 0 {
+		return positive.Optimize()
+	}
+	return &model3d.SubtractedSolid{
+		Positive: positive.Optimize(),
+		Negative: negative.Optimize(),
+	}
+}
+
+// NewSurfaceAnchor creates an anchor on the surface of an
+// SDF, at the point closest to c.
+//
+// The z-axis of the anchor is the outward surface normal,
+// and the x-axis is as close as possible to direction.
+func NewSurfaceAnchor(host model3d.SDF, c, direction model3d.Coord3D) *Anchor {
+	point, normal := decalSurfacePoint(host, c)
+	return NewAnchor(point, normal, direction)
+}
+
+// A ZipTieSlot is a tunnel beneath a surface for threading
+// a zip tie around a cable.
+//
+// The tie enters and exits the surface through two slots
+// on either side of the cable, leaving a bridge of
+// material between them.
+type ZipTieSlot struct {
+	// TieWidth and TieThickness are the dimensions of the
+	// zip tie, including any clearance.
+	TieWidth     float64
+	TieThickness float64
+
+	// BridgeWidth is the distance between the slots, which
+	// should be at least the width of the cable.
+	BridgeWidth float64
+
+	// Depth is the thickness of the bridge above the
+	// tunnel.
+	Depth float64
+}
+
+// Positive returns nil, since the slot adds no material.
+func (z *ZipTieSlot) Positive() model3d.Solid {
+	return nil
+}
+
+// Negative returns the slots and the tunnel connecting
+// them.
+func (z *ZipTieSlot) Negative() model3d.Solid {
+	halfWidth := z.TieWidth / 2
+	outer := z.BridgeWidth/2 + z.TieThickness
+	bottom := -(z.Depth + z.TieThickness)
+	// Extend the slots slightly above the surface to avoid
+	// leaving a thin film.
+	top := z.TieThickness
+	return model3d.JoinedSolid{
+		&model3d.Rect{
+			MinVal: model3d.XYZ(-halfWidth, -outer, bottom),
+			MaxVal: model3d.XYZ(halfWidth, -z.BridgeWidth/2, top),
+		},
+		&model3d.Rect{
+			MinVal: model3d.XYZ(-halfWidth, z.BridgeWidth/2, bottom),
+			MaxVal: model3d.XYZ(halfWidth, outer, top),
+		},
+		&model3d.Rect{
+			MinVal: model3d.XYZ(-halfWidth, -outer, bottom),
+			MaxVal: model3d.XYZ(halfWidth, outer, -z.Depth),
+		},
+	}
+}
+
+// A CableClip is a C-shaped clip which holds a cable
+// above a surface.
+//
+// The clip is open at the top, so the cable can be
+// snapped into it.
+type CableClip struct {
+	// CableRadius is the radius of the cable, including any
+	// clearance.
+	CableRadius float64
+
+	// Thickness is the thickness of the clip's walls and of
+	// the base beneath the cable.
+	Thickness float64
+
+	// Length is the length of the clip along the cable.
+	Length float64
+
+	// OpeningWidth is the width of the gap at the top of the
+	// clip, which should be slightly less than the cable's
+	// diameter so that the cable snaps in place.
+	OpeningWidth float64
+}
+
+// Positive returns the clip.
+func (c *CableClip) Positive() model3d.Solid {
+	// The bottom of the ring touches the surface.
+	outer := c.CableRadius + c.Thickness
+	center := outer
+	halfLength := c.Length / 2
+	return model3d.CheckedFuncSolid(
+		model3d.XYZ(-halfLength, -outer, 0),
+		model3d.XYZ(halfLength, outer, center+outer),
+		func(p model3d.Coord3D) bool {
+			offset := model3d.Coord2D{X: p.Y, Y: p.Z - center}
+			r := offset.Norm()
+			if r < c.CableRadius {
+				return false
+			}
+			if offset.Y > 0 && math.Abs(offset.X) < c.OpeningWidth/2 {
+				return false
+			}
+			// A flat foot joins the ring to the surface.
+			return r <= outer || (offset.Y < 0 && math.Abs(offset.X) < c.CableRadius)
+		},
+	)
+}
+
+// Negative returns nil, since the clip does not cut into
+// the surface.
+func (c *CableClip) Negative() model3d.Solid {
+	return nil
+}
+
+// A StrainReliefBoss is a tapered boss around a hole where
+// a cable passes through a wall.
+//
+// The boss spreads bending over a longer length of cable,
+// preventing the cable from fraying at the hole.
+type StrainReliefBoss struct {
+	// CableRadius is the radius of the hole, including any
+	// clearance.
+	CableRadius float64
+
+	// BaseRadius and TipRadius are the outer radii of the
+	// boss at the surface and at its tip.
+	BaseRadius float64
+	TipRadius  float64
+
+	// Height is how far the boss protrudes from the surface.
+	Height float64
+
+	// WallThickness is the thickness of the wall that the
+	// hole passes through.
+	WallThickness float64
+}
+
+// Positive returns the tapered boss.
+func (s *StrainReliefBoss) Positive() model3d.Solid {
+	r := math.Max(s.BaseRadius, s.TipRadius)
+	return model3d.CheckedFuncSolid(
+		model3d.XYZ(-r, -r, 0),
+		model3d.XYZ(r, r, s.Height),
+		func(c model3d.Coord3D) bool {
+			frac := c.Z / s.Height
+			radius := s.BaseRadius*(1-frac) + s.TipRadius*frac
+			return c.XY().Norm() <= radius
+		},
+	)
+}
+
+// Negative returns the hole through the boss and the wall.
+func (s *StrainReliefBoss) Negative() model3d.Solid {
+	// Extend the hole past both ends to avoid thin films.
+	extra := s.CableRadius
+	return &model3d.Cylinder{
+		P1:     model3d.Z(-(s.WallThickness + extra)),
+		P2:     model3d.Z(s.Height + extra),
+		Radius: s.CableRadius,
+	}
+}
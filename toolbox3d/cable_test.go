@@ -0,0 +1,65 @@
+package toolbox3d
+
+import (
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestAttachCableFeatures(t *testing.T) {
+	host := &model3d.Rect{MinVal: model3d.XYZ(-5, -5, -2), MaxVal: model3d.XYZ(5, 5, 0)}
+	solid := AttachCableFeatures(
+		host,
+		PlacedCableFeature{
+			Feature: &ZipTieSlot{TieWidth: 0.5, TieThickness: 0.2, BridgeWidth: 1, Depth: 0.3},
+			Anchor:  NewSurfaceAnchor(host, model3d.XYZ(-3, 0, 1), model3d.X(1)),
+		},
+		PlacedCableFeature{
+			Feature: &CableClip{CableRadius: 0.5, Thickness: 0.2, Length: 1, OpeningWidth: 0.8},
+			Anchor:  NewSurfaceAnchor(host, model3d.XYZ(0, 0, 1), model3d.X(1)),
+		},
+		PlacedCableFeature{
+			Feature: &StrainReliefBoss{
+				CableRadius:   0.3,
+				BaseRadius:    1,
+				TipRadius:     0.5,
+				Height:        1,
+				WallThickness: 2,
+			},
+			// Placed on the side of the box, pointing along +X.
+			Anchor: NewSurfaceAnchor(host, model3d.XYZ(6, 0, -1), model3d.Z(1)),
+		},
+	)
+
+	testCases := []struct {
+		Point    model3d.Coord3D
+		Expected bool
+	}{
+		// Zip tie slots and tunnel.
+		{model3d.XYZ(-3, 0.6, -0.1), false},
+		{model3d.XYZ(-3, -0.6, -0.1), false},
+		{model3d.XYZ(-3, 0, -0.1), true},
+		{model3d.XYZ(-3, 0, -0.4), false},
+		{model3d.XYZ(-3, 0, -0.6), true},
+		{model3d.XYZ(-3.3, 0.6, -0.1), true},
+
+		// Cable clip.
+		{model3d.XYZ(0, 0, 0.7), false},
+		{model3d.XYZ(0, 0.6, 0.7), true},
+		{model3d.XYZ(0, -0.6, 0.7), true},
+		{model3d.XYZ(0, 0, 1.3), false},
+		{model3d.XYZ(0.6, 0.6, 0.7), false},
+
+		// Strain relief boss.
+		{model3d.XYZ(5.5, 0.5, -1), true},
+		{model3d.XYZ(5.5, 0, -1), false},
+		{model3d.XYZ(4, 0, -1), false},
+		{model3d.XYZ(4, 0.5, -1), true},
+		{model3d.XYZ(5.9, 0.8, -1), false},
+	}
+	for i, tc := range testCases {
+		if actual := solid.Contains(tc.Point); actual != tc.Expected {
+			t.Errorf("case %d (%v): expected %v but got %v", i, tc.Point, tc.Expected, actual)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package toolbox3d
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const calibrationErosionSamples = 16
+
+// A CalibrationPiece is a printable test piece for
+// measuring the dimensional accuracy of a printer.
+//
+// The piece is a rectangular plate with a row of vertical
+// holes along the first half of its length, and a row of
+// vertical pegs on top of the second half.
+// After printing it, measure the outside of the plate, the
+// holes, and the pegs, and pass the results to
+// FitCompensation.
+type CalibrationPiece struct {
+	// SizeX and SizeY are the outer dimensions of the
+	// plate.
+	SizeX float64
+	SizeY float64
+
+	// Thickness is the height of the plate.
+	Thickness float64
+
+	// HoleDiameters are the diameters of the holes.
+	// They should be small enough to fit in a row along
+	// half of the plate.
+	HoleDiameters []float64
+
+	// PegDiameters are the diameters of the pegs.
+	// They should be small enough to fit in a row along
+	// half of the plate.
+	PegDiameters []float64
+
+	// PegHeight is the height of the pegs above the plate.
+	PegHeight float64
+}
+
+// NewCalibrationPiece creates a calibration piece with
+// typical dimensions, in millimeters.
+func NewCalibrationPiece() *CalibrationPiece {
+	return &CalibrationPiece{
+		SizeX:         60,
+		SizeY:         20,
+		Thickness:     3,
+		HoleDiameters: []float64{3, 5, 8},
+		PegDiameters:  []float64{3, 5, 8},
+		PegHeight:     5,
+	}
+}
+
+// Solid creates a solid for the piece, where the bottom
+// of the plate is at z=0 and its corner is at the origin.
+func (c *CalibrationPiece) Solid() model3d.Solid {
+	plate := model3d.NewRect(model3d.Coord3D{}, model3d.XYZ(c.SizeX, c.SizeY, c.Thickness))
+	var holes model3d.JoinedSolid
+	for i, d := range c.HoleDiameters {
+		center := c.holeCenter(i)
+		holes = append(holes, &model3d.Cylinder{
+			P1:     model3d.XYZ(center.X, center.Y, -1),
+			P2:     model3d.XYZ(center.X, center.Y, c.Thickness+1),
+			Radius: d / 2,
+		})
+	}
+	res := model3d.JoinedSolid{&model3d.SubtractedSolid{Positive: plate, Negative: holes}}
+	for i, d := range c.PegDiameters {
+		center := c.pegCenter(i)
+		res = append(res, &model3d.Cylinder{
+			P1:     model3d.XYZ(center.X, center.Y, c.Thickness),
+			P2:     model3d.XYZ(center.X, center.Y, c.Thickness+c.PegHeight),
+			Radius: d / 2,
+		})
+	}
+	return res.Optimize()
+}
+
+func (c *CalibrationPiece) holeCenter(i int) model2d.Coord {
+	spacing := c.SizeX / 2 / float64(len(c.HoleDiameters))
+	return model2d.XY(spacing*(float64(i)+0.5), c.SizeY/2)
+}
+
+func (c *CalibrationPiece) pegCenter(i int) model2d.Coord {
+	spacing := c.SizeX / 2 / float64(len(c.PegDiameters))
+	return model2d.XY(c.SizeX/2+spacing*(float64(i)+0.5), c.SizeY/2)
+}
+
+// CalibrationMeasurements are the measured dimensions of a
+// printed CalibrationPiece.
+type CalibrationMeasurements struct {
+	// SizeX and SizeY are the outer dimensions of the
+	// plate.
+	SizeX float64
+	SizeY float64
+
+	// HoleDiameters and PegDiameters correspond to the
+	// features of the piece.
+	HoleDiameters []float64
+	PegDiameters  []float64
+}
+
+// A Compensation describes the dimensional errors of a
+// printer, and can correct models to account for them.
+//
+// Printed parts are modeled as being scaled in the XY
+// plane, after which every outer surface is pushed out by
+// Expansion and the radius of every hole shrinks by an
+// additional HoleShrink.
+type Compensation struct {
+	// ScaleX and ScaleY are the ratios of printed to
+	// nominal sizes along each axis.
+	ScaleX float64
+	ScaleY float64
+
+	// Expansion is the horizontal distance that printed
+	// surfaces extend past their nominal position.
+	// It is negative if parts are undersized.
+	Expansion float64
+
+	// HoleShrink is the amount that the radius of printed
+	// holes shrinks, beyond what is caused by Expansion.
+	HoleShrink float64
+}
+
+// FitCompensation estimates a Compensation from the
+// measured dimensions of a printed CalibrationPiece.
+//
+// The scales and expansion are fit to the plate and pegs
+// with least squares, and the hole shrinkage is averaged
+// across the holes.
+// If the piece has no pegs, then the expansion cannot be
+// separated from the scale, so it is assumed to be zero.
+func FitCompensation(piece *CalibrationPiece,
+	m *CalibrationMeasurements) (*Compensation, error) {
+	if len(m.HoleDiameters) != len(piece.HoleDiameters) {
+		return nil, fmt.Errorf("fit compensation: expected %d hole measurements but got %d",
+			len(piece.HoleDiameters), len(m.HoleDiameters))
+	} else if len(m.PegDiameters) != len(piece.PegDiameters) {
+		return nil, fmt.Errorf("fit compensation: expected %d peg measurements but got %d",
+			len(piece.PegDiameters), len(m.PegDiameters))
+	} else if m.SizeX <= 0 || m.SizeY <= 0 {
+		return nil, errors.New("fit compensation: plate size must be positive")
+	}
+
+	res := &Compensation{}
+	if len(piece.PegDiameters) == 0 {
+		res.ScaleX = m.SizeX / piece.SizeX
+		res.ScaleY = m.SizeY / piece.SizeY
+	} else {
+		// Solve for (ScaleX, ScaleY, Expansion) with the
+		// normal equations, where each measurement is a
+		// linear function of these unknowns.
+		var ata model3d.Matrix3
+		var atb model3d.Coord3D
+		addRow := func(row model3d.Coord3D, value float64) {
+			for i, x := range row.Array() {
+				for j, y := range row.Array() {
+					ata[i*3+j] += x * y
+				}
+			}
+			atb = atb.Add(row.Scale(value))
+		}
+		addRow(model3d.XYZ(piece.SizeX, 0, 2), m.SizeX)
+		addRow(model3d.XYZ(0, piece.SizeY, 2), m.SizeY)
+		for i, d := range piece.PegDiameters {
+			addRow(model3d.XYZ(d/2, d/2, 2), m.PegDiameters[i])
+		}
+		if math.Abs(ata.Det()) < 1e-8 {
+			return nil, errors.New("fit compensation: measurements are degenerate")
+		}
+		solution := ata.Inverse().MulColumn(atb)
+		res.ScaleX, res.ScaleY, res.Expansion = solution.X, solution.Y, solution.Z
+	}
+
+	if len(piece.HoleDiameters) > 0 {
+		scale := (res.ScaleX + res.ScaleY) / 2
+		for i, d := range piece.HoleDiameters {
+			res.HoleShrink += (scale*d-m.HoleDiameters[i])/2 - res.Expansion
+		}
+		res.HoleShrink /= float64(len(piece.HoleDiameters))
+	}
+	return res, nil
+}
+
+// HoleRadius gets the radius to use in a model for a hole
+// of the given nominal radius.
+//
+// This only corrects for HoleShrink, since Expansion and
+// scale are corrected by Solid and Mesh.
+func (c *Compensation) HoleRadius(radius float64) float64 {
+	return radius + c.HoleShrink
+}
+
+// Solid creates a compensated version of a solid, which
+// will print at the dimensions of the original.
+//
+// The solid is scaled inversely to the printer's scale,
+// and eroded (or dilated) horizontally by the expansion.
+// Holes should be created using HoleRadius to correct for
+// hole shrinkage.
+func (c *Compensation) Solid(s model3d.Solid) model3d.Solid {
+	scale := model3d.XYZ(c.ScaleX, c.ScaleY, 1)
+	invScale := model3d.XYZ(1/c.ScaleX, 1/c.ScaleY, 1)
+	expansion := c.Expansion
+	var offsets []model3d.Coord3D
+	for i := 0; i < calibrationErosionSamples; i++ {
+		theta := 2 * math.Pi * float64(i) / calibrationErosionSamples
+		offsets = append(offsets, model3d.XYZ(math.Cos(theta), math.Sin(theta), 0).Scale(
+			math.Abs(expansion)))
+	}
+	pad := invScale.Mul(model3d.XYZ(1, 1, 0)).Scale(math.Max(0, -expansion))
+	return model3d.CheckedFuncSolid(
+		s.Min().Mul(invScale).Sub(pad),
+		s.Max().Mul(invScale).Add(pad),
+		func(coord model3d.Coord3D) bool {
+			coord = coord.Mul(scale)
+			if expansion == 0 {
+				return s.Contains(coord)
+			}
+			erode := expansion > 0
+			if s.Contains(coord) != erode {
+				return !erode
+			}
+			for _, offset := range offsets {
+				if s.Contains(coord.Add(offset)) != erode {
+					return !erode
+				}
+			}
+			return erode
+		},
+	)
+}
+
+// Mesh creates a compensated version of a mesh, which will
+// print at the dimensions of the original.
+//
+// The mesh is scaled inversely to the printer's scale, and
+// each vertex is moved horizontally so that the surfaces
+// around it are offset inward by the expansion.
+// Holes should be created using HoleRadius to correct for
+// hole shrinkage.
+func (c *Compensation) Mesh(m *model3d.Mesh) *model3d.Mesh {
+	scaled := m.MapCoords(func(coord model3d.Coord3D) model3d.Coord3D {
+		return model3d.XYZ(coord.X/c.ScaleX, coord.Y/c.ScaleY, coord.Z)
+	})
+	if c.Expansion == 0 {
+		return scaled
+	}
+	return scaled.MapCoords(func(coord model3d.Coord3D) model3d.Coord3D {
+		// Find an offset d such that d.u = -Expansion for the
+		// horizontal direction u of each adjacent face.
+		var ata model2d.Matrix2
+		var atb model2d.Coord
+		for _, t := range scaled.Find(coord) {
+			n := t.Normal()
+			horiz := model2d.XY(n.X, n.Y)
+			if horiz.Norm() < 1e-5 {
+				continue
+			}
+			u := horiz.Normalize()
+			w := t.Area() * horiz.Norm()
+			ata[0] += w * u.X * u.X
+			ata[1] += w * u.X * u.Y
+			ata[2] += w * u.X * u.Y
+			ata[3] += w * u.Y * u.Y
+			atb = atb.Add(u.Scale(-c.Expansion * w))
+		}
+		trace := ata[0] + ata[3]
+		if trace == 0 {
+			return coord
+		}
+		// Regularize so that a single wall direction gives
+		// the minimum-norm solution.
+		ata[0] += trace * 1e-8
+		ata[3] += trace * 1e-8
+		d := ata.Inverse().MulColumn(atb)
+		return coord.Add(model3d.XYZ(d.X, d.Y, 0))
+	})
+}
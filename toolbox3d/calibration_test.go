@@ -0,0 +1,106 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestFitCompensation(t *testing.T) {
+	piece := NewCalibrationPiece()
+	expected := &Compensation{ScaleX: 1.01, ScaleY: 0.995, Expansion: 0.15, HoleShrink: 0.1}
+	scale := (expected.ScaleX + expected.ScaleY) / 2
+	measurements := &CalibrationMeasurements{
+		SizeX: piece.SizeX*expected.ScaleX + 2*expected.Expansion,
+		SizeY: piece.SizeY*expected.ScaleY + 2*expected.Expansion,
+	}
+	for _, d := range piece.HoleDiameters {
+		measurements.HoleDiameters = append(measurements.HoleDiameters,
+			d*scale-2*(expected.Expansion+expected.HoleShrink))
+	}
+	for _, d := range piece.PegDiameters {
+		measurements.PegDiameters = append(measurements.PegDiameters,
+			d*scale+2*expected.Expansion)
+	}
+	actual, err := FitCompensation(piece, measurements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pair := range [][2]float64{
+		{actual.ScaleX, expected.ScaleX},
+		{actual.ScaleY, expected.ScaleY},
+		{actual.Expansion, expected.Expansion},
+		{actual.HoleShrink, expected.HoleShrink},
+	} {
+		if math.Abs(pair[0]-pair[1]) > 1e-8 {
+			t.Errorf("field %d: expected %f but got %f", i, pair[1], pair[0])
+		}
+	}
+
+	measurements.PegDiameters = measurements.PegDiameters[1:]
+	if _, err := FitCompensation(piece, measurements); err == nil {
+		t.Error("expected error for missing measurement")
+	}
+}
+
+func TestCalibrationPieceSolid(t *testing.T) {
+	piece := NewCalibrationPiece()
+	solid := piece.Solid()
+	for i, d := range piece.HoleDiameters {
+		center := piece.holeCenter(i)
+		if solid.Contains(model3d.XYZ(center.X+d/2-0.01, center.Y, 1)) ||
+			!solid.Contains(model3d.XYZ(center.X+d/2+0.01, center.Y, 1)) {
+			t.Errorf("unexpected hole %d", i)
+		}
+	}
+	for i, d := range piece.PegDiameters {
+		center := piece.pegCenter(i)
+		z := piece.Thickness + piece.PegHeight/2
+		if !solid.Contains(model3d.XYZ(center.X+d/2-0.01, center.Y, z)) ||
+			solid.Contains(model3d.XYZ(center.X+d/2+0.01, center.Y, z)) {
+			t.Errorf("unexpected peg %d", i)
+		}
+	}
+}
+
+func TestCompensationSolid(t *testing.T) {
+	rect := model3d.NewRect(model3d.Coord3D{}, model3d.XYZ(10, 10, 2))
+	for _, comp := range []*Compensation{
+		{ScaleX: 1, ScaleY: 1, Expansion: 0.5},
+		{ScaleX: 1.1, ScaleY: 0.9, Expansion: -0.5},
+	} {
+		solid := comp.Solid(rect)
+		for _, axis := range []int{0, 1} {
+			scale := []float64{comp.ScaleX, comp.ScaleY}[axis]
+			inner := 10/scale - comp.Expansion/scale
+			check := func(x float64, expected bool) {
+				arr := model3d.XYZ(5, 5, 1).Array()
+				arr[axis] = x
+				c := model3d.NewCoord3DArray(arr)
+				if solid.Contains(c) != expected {
+					t.Errorf("comp %v: expected %v at %v", comp, expected, c)
+				}
+			}
+			check(inner-0.01, true)
+			check(inner+0.01, false)
+		}
+		if solid.Max().X < 10/comp.ScaleX-comp.Expansion/comp.ScaleX {
+			t.Errorf("bounds too small: %v", solid.Max())
+		}
+	}
+}
+
+func TestCompensationMesh(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.Coord3D{}, model3d.XYZ(10, 10, 2))
+	comp := &Compensation{ScaleX: 1.02, ScaleY: 0.98, Expansion: 0.2}
+	result := comp.Mesh(mesh)
+	expectedMin := model3d.XYZ(0.2, 0.2, 0)
+	expectedMax := model3d.XYZ(10/1.02-0.2, 10/0.98-0.2, 2)
+	if result.Min().Dist(expectedMin) > 1e-5 || result.Max().Dist(expectedMax) > 1e-5 {
+		t.Errorf("unexpected bounds: %v, %v", result.Min(), result.Max())
+	}
+	if _, n := result.RepairNormals(1e-5); n != 0 {
+		t.Error("unexpected normal repairs")
+	}
+}
@@ -0,0 +1,116 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A ChainMail describes a flexible sheet of interlocked
+// rings, arranged in a 4-in-1 pattern.
+//
+// Flat rings are placed on a square grid in the XY plane,
+// and every pair of adjacent flat rings is connected by an
+// upright ring which passes through both of them.
+// All of the rings are separate pieces, so the sheet can
+// be printed in one go and flexed afterwards.
+//
+// The bottom of the upright rings rests on the z=0 plane.
+// The flat rings are suspended above the bed by at least
+// the clearance, so they need a small amount of support
+// or a printing process that tolerates overhangs.
+type ChainMail struct {
+	// Rows and Cols determine the number of flat rings.
+	Rows int
+	Cols int
+
+	// Spacing is the distance between adjacent flat rings.
+	Spacing float64
+
+	// WireRadius is the radius of each ring's wire.
+	WireRadius float64
+
+	// RingRadius is the radius of each ring, measured to
+	// the center of the wire.
+	//
+	// If 0, the radius which maximizes Clearance() is used.
+	RingRadius float64
+
+	// Mask, if non-nil, restricts the sheet to a region of
+	// the XY plane.
+	// Only flat rings whose centers are within the mask are
+	// included.
+	Mask model2d.Solid
+}
+
+// Clearance computes the minimum gap between the wires of
+// any two rings.
+func (c *ChainMail) Clearance() float64 {
+	r := c.ringRadius()
+	linkGap := r - math.Abs(r-c.Spacing/2)
+	crossGap := math.Sqrt2 * (c.Spacing/2 - r)
+	neighborGap := c.Spacing - 2*r
+	return math.Min(linkGap, math.Min(crossGap, neighborGap)) - 2*c.WireRadius
+}
+
+// Center gets the center of the flat ring at the given
+// grid location.
+func (c *ChainMail) Center(row, col int) model3d.Coord3D {
+	return model3d.XYZ(
+		float64(col)*c.Spacing,
+		float64(row)*c.Spacing,
+		c.ringRadius()+c.WireRadius,
+	)
+}
+
+// Rings creates a separate solid for every ring.
+func (c *ChainMail) Rings() []model3d.Solid {
+	radius := c.ringRadius()
+	var res []model3d.Solid
+	ring := func(center, axis model3d.Coord3D) {
+		res = append(res, &model3d.Torus{
+			Center:      center,
+			Axis:        axis,
+			OuterRadius: radius,
+			InnerRadius: c.WireRadius,
+		})
+	}
+	for row := 0; row < c.Rows; row++ {
+		for col := 0; col < c.Cols; col++ {
+			if !c.included(row, col) {
+				continue
+			}
+			center := c.Center(row, col)
+			ring(center, model3d.Z(1))
+			if col+1 < c.Cols && c.included(row, col+1) {
+				ring(center.Add(model3d.X(c.Spacing/2)), model3d.Y(1))
+			}
+			if row+1 < c.Rows && c.included(row+1, col) {
+				ring(center.Add(model3d.Y(c.Spacing/2)), model3d.X(1))
+			}
+		}
+	}
+	return res
+}
+
+// Solid creates a solid for the entire sheet.
+func (c *ChainMail) Solid() model3d.Solid {
+	return model3d.JoinedSolid(c.Rings()).Optimize()
+}
+
+func (c *ChainMail) ringRadius() float64 {
+	if c.RingRadius != 0 {
+		return c.RingRadius
+	}
+	// Balance the gap between linked rings against the gap
+	// between perpendicular upright rings.
+	return c.Spacing * (0.5 + math.Sqrt2/2) / (2 + math.Sqrt2)
+}
+
+func (c *ChainMail) included(row, col int) bool {
+	if c.Mask == nil {
+		return true
+	}
+	return c.Mask.Contains(c.Center(row, col).XY())
+}
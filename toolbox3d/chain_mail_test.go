@@ -0,0 +1,47 @@
+package toolbox3d
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestChainMailClearance(t *testing.T) {
+	c := &ChainMail{Rows: 3, Cols: 3, Spacing: 1, WireRadius: 0.05}
+	clearance := c.Clearance()
+	if clearance < 0.1 {
+		t.Fatalf("unexpectedly small clearance: %f", clearance)
+	}
+
+	// Grow every ring by slightly less than half of the
+	// clearance and make sure no two rings overlap.
+	var rings []model3d.Solid
+	for _, r := range c.Rings() {
+		torus := *r.(*model3d.Torus)
+		torus.InnerRadius += clearance/2 - 1e-3
+		rings = append(rings, &torus)
+	}
+	if len(rings) != 9+12 {
+		t.Fatalf("unexpected number of rings: %d", len(rings))
+	}
+	joined := model3d.JoinedSolid(rings)
+	min, max := joined.Min(), joined.Max()
+	for i := 0; i < 100000; i++ {
+		p := model3d.XYZ(rand.Float64(), rand.Float64(), rand.Float64())
+		p = min.Add(p.Mul(max.Sub(min)))
+		var count int
+		for _, r := range rings {
+			if r.Contains(p) {
+				count++
+			}
+		}
+		if count > 1 {
+			t.Fatalf("rings overlap at %v", p)
+		}
+	}
+
+	if c.Solid().Min().Z < -1e-8 {
+		t.Error("rings should not extend below the bed")
+	}
+}
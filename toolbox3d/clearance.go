@@ -0,0 +1,114 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A ClearanceSample records the clearance between two
+// meshes for one value of a motion parameter.
+type ClearanceSample struct {
+	// Value is the motion parameter, such as an angle.
+	Value float64
+
+	// Clearance is the approximate minimum distance between
+	// the meshes.
+	// It is negative (or zero) if the meshes intersect, in
+	// which case its magnitude approximates the depth of
+	// the intersection.
+	Clearance float64
+}
+
+// ClearanceSweep moves a mesh through a range of motion
+// and measures its clearance from a static mesh at each
+// step.
+//
+// The motion function maps a parameter value to a
+// transformation for the moving mesh.
+// See RevoluteMotion and PrismaticMotion for common
+// joints.
+//
+// Distances are measured from the vertices of each mesh
+// to the surface of the other, so both meshes should be
+// finely tessellated compared to the expected clearance.
+func ClearanceSweep(static, moving *model3d.Mesh, motion func(value float64) model3d.Transform,
+	values []float64) []ClearanceSample {
+	staticSDF := model3d.MeshToSDF(static)
+	movingSDF := model3d.MeshToSDF(moving)
+	staticVertices := static.VertexSlice()
+	movingVertices := moving.VertexSlice()
+
+	res := make([]ClearanceSample, len(values))
+	for i, value := range values {
+		xf := motion(value)
+		inv := xf.Inverse()
+
+		clearance := math.Inf(1)
+		for _, v := range movingVertices {
+			clearance = math.Min(clearance, -staticSDF.SDF(xf.Apply(v)))
+		}
+		for _, v := range staticVertices {
+			clearance = math.Min(clearance, -movingSDF.SDF(inv.Apply(v)))
+		}
+		if clearance > 0 && clearanceMeshesIntersect(static, moving.Transform(xf)) {
+			// Edges can cross without any vertex entering
+			// the other mesh.
+			clearance = 0
+		}
+		res[i] = ClearanceSample{Value: value, Clearance: clearance}
+	}
+	return res
+}
+
+// MinClearance finds the sample with the smallest
+// clearance.
+func MinClearance(samples []ClearanceSample) ClearanceSample {
+	res := samples[0]
+	for _, s := range samples[1:] {
+		if s.Clearance < res.Clearance {
+			res = s
+		}
+	}
+	return res
+}
+
+// RevoluteMotion creates a motion function that rotates
+// by the given value (in radians) around an axis through
+// an origin.
+func RevoluteMotion(origin, axis model3d.Coord3D) func(float64) model3d.Transform {
+	axis = axis.Normalize()
+	return func(angle float64) model3d.Transform {
+		return model3d.JoinedTransform{
+			&model3d.Translate{Offset: origin.Scale(-1)},
+			model3d.Rotation(axis, angle),
+			&model3d.Translate{Offset: origin},
+		}
+	}
+}
+
+// PrismaticMotion creates a motion function that
+// translates by the given value along a direction.
+func PrismaticMotion(direction model3d.Coord3D) func(float64) model3d.Transform {
+	direction = direction.Normalize()
+	return func(dist float64) model3d.Transform {
+		return &model3d.Translate{Offset: direction.Scale(dist)}
+	}
+}
+
+// SweepValues creates n evenly spaced values from min to
+// max, inclusive.
+func SweepValues(min, max float64, n int) []float64 {
+	if n == 1 {
+		return []float64{min}
+	}
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = min + (max-min)*float64(i)/float64(n-1)
+	}
+	return res
+}
+
+func clearanceMeshesIntersect(m1, m2 *model3d.Mesh) bool {
+	return meshesIntersect(m1, model3d.MeshToCollider(m2))
+}
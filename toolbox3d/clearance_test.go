@@ -0,0 +1,37 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestClearanceSweep(t *testing.T) {
+	static := model3d.NewMeshRect(model3d.XYZ(2, -0.5, -0.5), model3d.XYZ(3, 0.5, 0.5))
+	moving := model3d.NewMeshRect(model3d.XYZ(-1.5, -0.1, -0.1), model3d.XYZ(1.5, 0.1, 0.1))
+
+	samples := ClearanceSweep(static, moving, RevoluteMotion(model3d.Coord3D{}, model3d.Z(1)),
+		SweepValues(0, math.Pi, 5))
+	if math.Abs(samples[0].Clearance-0.5) > 1e-8 {
+		t.Errorf("unexpected clearance at 0: %f", samples[0].Clearance)
+	}
+	if samples[2].Clearance < 1 {
+		t.Errorf("unexpected clearance at pi/2: %f", samples[2].Clearance)
+	}
+	if MinClearance(samples).Clearance != samples[0].Clearance {
+		t.Error("unexpected minimum")
+	}
+
+	samples = ClearanceSweep(static, moving, PrismaticMotion(model3d.X(1)),
+		[]float64{0.4, 0.6, 2})
+	if math.Abs(samples[0].Clearance-0.1) > 1e-8 {
+		t.Errorf("unexpected clearance: %f", samples[0].Clearance)
+	}
+	if math.Abs(samples[1].Clearance+0.1) > 1e-8 {
+		t.Errorf("unexpected penetration: %f", samples[1].Clearance)
+	}
+	if samples[2].Clearance > 0 {
+		t.Errorf("expected intersection: %f", samples[2].Clearance)
+	}
+}
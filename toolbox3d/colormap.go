@@ -0,0 +1,131 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A Colormap maps scalars in the range [0, 1] to RGB
+// colors, for visualizing scalar fields such as curvature
+// or wall thickness.
+//
+// Inputs outside of [0, 1] are clamped.
+type Colormap func(x float64) [3]float64
+
+// NewColormap creates a Colormap which linearly
+// interpolates between evenly spaced colors.
+//
+// At least two colors must be provided.
+func NewColormap(colors ...[3]float64) Colormap {
+	if len(colors) < 2 {
+		panic("colormap requires at least two colors")
+	}
+	colors = append([][3]float64{}, colors...)
+	return func(x float64) [3]float64 {
+		x = math.Max(0, math.Min(1, x)) * float64(len(colors)-1)
+		idx := essentials.MinInt(int(x), len(colors)-2)
+		frac := x - float64(idx)
+		c1, c2 := colors[idx], colors[idx+1]
+		var res [3]float64
+		for i := range res {
+			res[i] = c1[i] + (c2[i]-c1[i])*frac
+		}
+		return res
+	}
+}
+
+// Viridis is a perceptually uniform colormap going from
+// dark purple to yellow.
+//
+// It is well suited for non-negative values like
+// thickness or distance.
+var Viridis = NewColormap(
+	hexColor(0x440154), hexColor(0x482878), hexColor(0x3e4a89), hexColor(0x31688e),
+	hexColor(0x26828e), hexColor(0x1f9e89), hexColor(0x35b779), hexColor(0x6dcd59),
+	hexColor(0xb4de2c), hexColor(0xfde725),
+)
+
+// Coolwarm is a diverging colormap going from blue to
+// gray to red.
+//
+// It is well suited for signed values like curvature or
+// deviation from a target, where 0.5 is neutral.
+var Coolwarm = NewColormap(
+	hexColor(0x3b4cc0), hexColor(0x6282ea), hexColor(0x8db0fe), hexColor(0xb8d0f9),
+	hexColor(0xdddddd), hexColor(0xf5c4ac), hexColor(0xf4987a), hexColor(0xde604d),
+	hexColor(0xb40426),
+)
+
+// ScalarRange gets the minimum and maximum of a set of
+// scalar values, such as the values from VertexScalars.
+func ScalarRange(values map[model3d.Coord3D]float64) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	return
+}
+
+// VertexScalars evaluates a scalar function at every
+// vertex of a mesh, in parallel.
+func VertexScalars(m *model3d.Mesh, f func(c model3d.Coord3D) float64) map[model3d.Coord3D]float64 {
+	vertices := m.VertexSlice()
+	values := make([]float64, len(vertices))
+	essentials.ConcurrentMap(0, len(vertices), func(i int) {
+		values[i] = f(vertices[i])
+	})
+	res := make(map[model3d.Coord3D]float64, len(vertices))
+	for i, v := range vertices {
+		res[v] = values[i]
+	}
+	return res
+}
+
+// VertexScalarColor creates a function which colors each
+// vertex by its scalar value.
+//
+// Values are mapped linearly from [min, max] to [0, 1]
+// before being passed to the colormap.
+//
+// The result can be passed to render3d.VertexColorFunc
+// to smoothly color a rendering.
+func VertexScalarColor(values map[model3d.Coord3D]float64, min, max float64,
+	cmap Colormap) func(c model3d.Coord3D) [3]float64 {
+	return func(c model3d.Coord3D) [3]float64 {
+		return cmap(scalarFraction(values[c], min, max))
+	}
+}
+
+// TriangleScalarColor creates a function which colors each
+// triangle by the mean scalar value of its vertices.
+//
+// Values are mapped linearly from [min, max] to [0, 1]
+// before being passed to the colormap.
+//
+// The result can be passed to Mesh.SaveMaterialOBJ or
+// render3d.TriangleColorFunc.
+func TriangleScalarColor(values map[model3d.Coord3D]float64, min, max float64,
+	cmap Colormap) func(t *model3d.Triangle) [3]float64 {
+	return func(t *model3d.Triangle) [3]float64 {
+		mean := (values[t[0]] + values[t[1]] + values[t[2]]) / 3
+		return cmap(scalarFraction(mean, min, max))
+	}
+}
+
+func scalarFraction(value, min, max float64) float64 {
+	if max == min {
+		return 0.5
+	}
+	return (value - min) / (max - min)
+}
+
+func hexColor(c int) [3]float64 {
+	return [3]float64{
+		float64((c>>16)&0xff) / 255,
+		float64((c>>8)&0xff) / 255,
+		float64(c&0xff) / 255,
+	}
+}
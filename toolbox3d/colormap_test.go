@@ -0,0 +1,66 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestNewColormap(t *testing.T) {
+	cmap := NewColormap([3]float64{0, 0, 0}, [3]float64{1, 0, 0}, [3]float64{1, 1, 0})
+	cases := map[float64][3]float64{
+		-1:   {0, 0, 0},
+		0:    {0, 0, 0},
+		0.25: {0.5, 0, 0},
+		0.5:  {1, 0, 0},
+		0.75: {1, 0.5, 0},
+		1:    {1, 1, 0},
+		2:    {1, 1, 0},
+	}
+	for x, expected := range cases {
+		actual := cmap(x)
+		for i := range expected {
+			if math.Abs(actual[i]-expected[i]) > 1e-8 {
+				t.Errorf("input %f: expected %v but got %v", x, expected, actual)
+				break
+			}
+		}
+	}
+
+	for _, cmap := range []Colormap{Viridis, Coolwarm} {
+		for i := 0; i <= 100; i++ {
+			for _, c := range cmap(float64(i) / 100) {
+				if c < 0 || c > 1 {
+					t.Fatalf("color out of range: %f", c)
+				}
+			}
+		}
+	}
+}
+
+func TestTriangleScalarColor(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.XYZ(0, 0, 0), model3d.XYZ(1, 1, 1))
+	values := VertexScalars(mesh, func(c model3d.Coord3D) float64 {
+		return c.Z * 10
+	})
+	min, max := ScalarRange(values)
+	if min != 0 || max != 10 {
+		t.Fatalf("unexpected range: %f, %f", min, max)
+	}
+
+	cmap := NewColormap([3]float64{0, 0, 0}, [3]float64{1, 1, 1})
+	triColor := TriangleScalarColor(values, min, max, cmap)
+	vertexColor := VertexScalarColor(values, min, max, cmap)
+	mesh.Iterate(func(tri *model3d.Triangle) {
+		expected := (tri[0].Z + tri[1].Z + tri[2].Z) / 3
+		if actual := triColor(tri)[0]; math.Abs(actual-expected) > 1e-8 {
+			t.Errorf("expected %f but got %f", expected, actual)
+		}
+		for _, c := range tri {
+			if actual := vertexColor(c)[1]; actual != c.Z {
+				t.Errorf("expected %f but got %f", c.Z, actual)
+			}
+		}
+	})
+}
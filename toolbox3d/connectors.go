@@ -0,0 +1,183 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A BoxFace is one of the six faces of an axis-aligned
+// box, such as the walls of an enclosure.
+type BoxFace int
+
+const (
+	BoxFront  BoxFace = iota // -Y
+	BoxBack                  // +Y
+	BoxLeft                  // -X
+	BoxRight                 // +X
+	BoxTop                   // +Z
+	BoxBottom                // -Z
+)
+
+// BoxFaceAnchor creates an anchor on a face of a box, with
+// its z-axis pointing out of the box.
+//
+// The offset is measured from the center of the face.
+// When looking at the face from outside the box, the
+// anchor's x-axis points to the right and its y-axis
+// points up.
+// For the top and bottom faces, "up" is the +Y and -Y
+// direction, respectively.
+func BoxFaceAnchor(min, max model3d.Coord3D, face BoxFace, offset model2d.Coord) *Anchor {
+	center := min.Mid(max)
+	var normal, right model3d.Coord3D
+	switch face {
+	case BoxFront:
+		center.Y = min.Y
+		normal, right = model3d.Y(-1), model3d.X(1)
+	case BoxBack:
+		center.Y = max.Y
+		normal, right = model3d.Y(1), model3d.X(-1)
+	case BoxLeft:
+		center.X = min.X
+		normal, right = model3d.X(-1), model3d.Y(-1)
+	case BoxRight:
+		center.X = max.X
+		normal, right = model3d.X(1), model3d.Y(1)
+	case BoxTop:
+		center.Z = max.Z
+		normal, right = model3d.Z(1), model3d.X(1)
+	case BoxBottom:
+		center.Z = min.Z
+		normal, right = model3d.Z(-1), model3d.X(1)
+	default:
+		panic("unknown box face")
+	}
+	up := normal.Cross(right)
+	origin := center.Add(right.Scale(offset.X)).Add(up.Scale(offset.Y))
+	return NewAnchor(origin, normal, right)
+}
+
+// A ConnectorCutout is a hole in a panel for a connector
+// or a switch.
+type ConnectorCutout struct {
+	// Profile is the shape of the hole, centered at the
+	// origin, including any clearance.
+	Profile model2d.Solid
+}
+
+// NewRoundedRectCutout creates a cutout for a rectangle
+// with rounded corners, expanded by a clearance on every
+// side.
+func NewRoundedRectCutout(width, height, radius, clearance float64) *ConnectorCutout {
+	w := width/2 + clearance
+	h := height/2 + clearance
+	r := radius + clearance
+	return &ConnectorCutout{
+		Profile: model2d.CheckedFuncSolid(
+			model2d.XY(-w, -h),
+			model2d.XY(w, h),
+			func(c model2d.Coord) bool {
+				dx := math.Max(0, math.Abs(c.X)-(w-r))
+				dy := math.Max(0, math.Abs(c.Y)-(h-r))
+				return dx*dx+dy*dy <= r*r
+			},
+		),
+	}
+}
+
+// NewRoundCutout creates a cutout for a circular hole,
+// expanded by a clearance.
+func NewRoundCutout(diameter, clearance float64) *ConnectorCutout {
+	return &ConnectorCutout{
+		Profile: &model2d.Circle{Radius: diameter/2 + clearance},
+	}
+}
+
+// NewUSBCCutout creates a cutout for the shell of a USB-C
+// receptacle (8.94x3.26 mm).
+//
+// The clearance should be increased to make room for the
+// overmolded housings of cable plugs.
+func NewUSBCCutout(clearance float64) *ConnectorCutout {
+	return NewRoundedRectCutout(8.94, 3.26, 1.63, clearance)
+}
+
+// NewMicroUSBCutout creates a cutout for the shell of a
+// micro-USB B receptacle (7.5x2.6 mm).
+func NewMicroUSBCutout(clearance float64) *ConnectorCutout {
+	return NewRoundedRectCutout(7.5, 2.6, 0.5, clearance)
+}
+
+// NewHDMICutout creates a cutout for the shell of a
+// full-size HDMI receptacle (15x5.6 mm).
+func NewHDMICutout(clearance float64) *ConnectorCutout {
+	return NewRoundedRectCutout(15, 5.6, 0.5, clearance)
+}
+
+// NewMicroHDMICutout creates a cutout for the shell of a
+// micro HDMI receptacle (6.5x3 mm), as on a Raspberry Pi 4.
+func NewMicroHDMICutout(clearance float64) *ConnectorCutout {
+	return NewRoundedRectCutout(6.5, 3, 0.5, clearance)
+}
+
+// NewBarrelJackCutout creates a cutout for a panel-mount
+// DC barrel jack with an M8 thread.
+func NewBarrelJackCutout(clearance float64) *ConnectorCutout {
+	return NewRoundCutout(8, clearance)
+}
+
+// NewRJ45Cutout creates a cutout for the front of a
+// PCB-mounted RJ45 jack (16x13.6 mm).
+func NewRJ45Cutout(clearance float64) *ConnectorCutout {
+	return NewRoundedRectCutout(16, 13.6, 0.5, clearance)
+}
+
+// NewToggleSwitchCutout creates a cutout for a miniature
+// toggle switch with a 1/4 inch bushing.
+//
+// The hole includes a notch above the bushing for the
+// switch's anti-rotation washer.
+func NewToggleSwitchCutout(clearance float64) *ConnectorCutout {
+	const (
+		bushingDiameter = 6.35
+		keyWidth        = 1.6
+		keyOffset       = 4.5
+	)
+	radius := bushingDiameter/2 + clearance
+	keyHalfWidth := keyWidth/2 + clearance
+	keyTop := keyOffset + keyHalfWidth
+	return &ConnectorCutout{
+		Profile: model2d.CheckedFuncSolid(
+			model2d.XY(-radius, -radius),
+			model2d.XY(radius, math.Max(radius, keyTop)),
+			func(c model2d.Coord) bool {
+				if c.Norm() <= radius {
+					return true
+				}
+				// The washer's tab fits in a round-ended slot.
+				if math.Abs(c.X) > keyHalfWidth || c.Y < 0 {
+					return false
+				}
+				return c.Y <= keyOffset || c.Dist(model2d.Y(keyOffset)) <= keyHalfWidth
+			},
+		),
+	}
+}
+
+// Solid creates the cutout in the local frame of a face,
+// extending from depth units inside the face to slightly
+// outside of it.
+func (c *ConnectorCutout) Solid(depth float64) model3d.Solid {
+	// Extend past the surface to avoid thin films.
+	return model3d.ProfileSolid(c.Profile, -depth, depth*0.1+1e-3)
+}
+
+// Place creates the cutout at an anchor on a face, such as
+// an anchor from BoxFaceAnchor.
+//
+// The depth should be at least the thickness of the wall.
+func (c *ConnectorCutout) Place(anchor *Anchor, depth float64) model3d.Solid {
+	return model3d.TransformSolid(anchor.ToWorld(), c.Solid(depth))
+}
@@ -0,0 +1,94 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestBoxFaceAnchor(t *testing.T) {
+	min, max := model3d.XYZ(0, 0, 0), model3d.XYZ(10, 20, 30)
+	for face := BoxFront; face <= BoxBottom; face++ {
+		a := BoxFaceAnchor(min, max, face, model2d.XY(1, 2))
+
+		// The anchor should be on the surface of the box.
+		rect := &model3d.Rect{MinVal: min, MaxVal: max}
+		if sdf := rect.SDF(a.Origin); math.Abs(sdf) > 1e-8 {
+			t.Errorf("face %d: anchor not on surface (sdf %f)", face, sdf)
+		}
+		if rect.Contains(a.Origin.Add(a.ZAxis().Scale(0.1))) {
+			t.Errorf("face %d: z-axis should point outward", face)
+		}
+		if face < BoxTop && a.YAxis().Z != 1 {
+			t.Errorf("face %d: y-axis should point up", face)
+		}
+		if math.Abs(a.XAxis().Cross(a.YAxis()).Dot(a.ZAxis())-1) > 1e-8 {
+			t.Errorf("face %d: frame should be right-handed", face)
+		}
+	}
+}
+
+func TestConnectorCutouts(t *testing.T) {
+	cutouts := map[string]struct {
+		Cutout *ConnectorCutout
+		Width  float64
+		Height float64
+	}{
+		"USBC":      {NewUSBCCutout(0.2), 9.34, 3.66},
+		"MicroUSB":  {NewMicroUSBCutout(0.2), 7.9, 3.0},
+		"HDMI":      {NewHDMICutout(0.2), 15.4, 6.0},
+		"MicroHDMI": {NewMicroHDMICutout(0.2), 6.9, 3.4},
+		"Barrel":    {NewBarrelJackCutout(0.2), 8.4, 8.4},
+		"RJ45":      {NewRJ45Cutout(0.2), 16.4, 14.0},
+	}
+	for name, c := range cutouts {
+		size := c.Cutout.Profile.Max().Sub(c.Cutout.Profile.Min())
+		if math.Abs(size.X-c.Width) > 1e-8 || math.Abs(size.Y-c.Height) > 1e-8 {
+			t.Errorf("%s: unexpected size %v", name, size)
+		}
+		if !c.Cutout.Profile.Contains(model2d.Coord{}) {
+			t.Errorf("%s: center should be in cutout", name)
+		}
+	}
+
+	toggle := NewToggleSwitchCutout(0.1)
+	if !toggle.Profile.Contains(model2d.Y(4.5)) || toggle.Profile.Contains(model2d.XY(1.5, 4.5)) {
+		t.Error("unexpected toggle key slot")
+	}
+	if toggle.Profile.Contains(model2d.Y(-3.5)) {
+		t.Error("key slot should only be above the bushing")
+	}
+}
+
+func TestConnectorCutoutPlace(t *testing.T) {
+	min, max := model3d.XYZ(0, 0, 0), model3d.XYZ(50, 40, 30)
+	wall := 2.0
+	enclosure := &model3d.SubtractedSolid{
+		Positive: &model3d.Rect{MinVal: min, MaxVal: max},
+		Negative: &model3d.Rect{
+			MinVal: min.Add(model3d.Ones(wall)),
+			MaxVal: max.Sub(model3d.Ones(wall)),
+		},
+	}
+	anchor := BoxFaceAnchor(min, max, BoxFront, model2d.XY(10, -5))
+	solid := &model3d.SubtractedSolid{
+		Positive: enclosure,
+		Negative: NewUSBCCutout(0.2).Place(anchor, wall*2),
+	}
+
+	center := model3d.XYZ(35, 1, 10)
+	if solid.Contains(center) {
+		t.Error("missing cutout")
+	}
+	if !solid.Contains(center.Add(model3d.X(5))) || !solid.Contains(center.Add(model3d.Z(2))) {
+		t.Error("cutout is too large")
+	}
+	if solid.Contains(center.Add(model3d.X(4.5))) {
+		t.Error("cutout is too small")
+	}
+	if !solid.Contains(model3d.XYZ(35, 39, 10)) {
+		t.Error("cutout should not reach the back wall")
+	}
+}
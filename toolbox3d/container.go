@@ -0,0 +1,167 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A ContainerSpec describes a system of cylindrical
+// container modules which screw together, such as
+// stacking jars or modular storage towers.
+//
+// Every module is created from the same spec, so any
+// module with a threaded spigot (a wall section or a lid)
+// can be screwed into any module with a threaded socket
+// (a base or a wall section).
+//
+// Each module is centered on the z-axis, with the bottom
+// of its body at z=0.
+// Spigots extend below z=0, so that a module of height H
+// screws into the module below it when it is translated
+// up by H.
+type ContainerSpec struct {
+	// Radius is the outer radius of each module.
+	Radius float64
+
+	// WallThickness is the thickness of the walls.
+	// The threads are cut into the walls, so this must be
+	// more than twice the GrooveSize.
+	WallThickness float64
+
+	// FloorThickness is the thickness of the base's floor.
+	FloorThickness float64
+
+	// ThreadLength is the length of each spigot and the
+	// depth of each socket.
+	ThreadLength float64
+
+	// GrooveSize is the size of the thread grooves, as in
+	// ScrewSolid.
+	GrooveSize float64
+
+	// Clearance is the gap between mating threads.
+	Clearance float64
+}
+
+// NewContainerSpec creates a spec with reasonable default
+// proportions for the given outer radius.
+func NewContainerSpec(radius float64) *ContainerSpec {
+	return &ContainerSpec{
+		Radius:         radius,
+		WallThickness:  radius * 0.1,
+		FloorThickness: radius * 0.1,
+		ThreadLength:   radius * 0.25,
+		GrooveSize:     radius * 0.025,
+		Clearance:      radius * 0.01,
+	}
+}
+
+// InnerRadius gets the radius of the container's interior.
+func (c *ContainerSpec) InnerRadius() float64 {
+	return c.Radius - c.WallThickness
+}
+
+// ThreadRadius gets the outer radius of the threads on
+// each spigot, including grooves.
+func (c *ContainerSpec) ThreadRadius() float64 {
+	return c.Radius - c.WallThickness/2
+}
+
+// Base creates the bottom module of a container, with a
+// floor and a threaded socket at the top.
+//
+// The height includes the floor and the socket.
+func (c *ContainerSpec) Base(height float64) model3d.Solid {
+	c.checkHeight(height, c.FloorThickness+c.ThreadLength)
+	return &model3d.SubtractedSolid{
+		Positive: c.body(height),
+		Negative: model3d.JoinedSolid{
+			&model3d.Cylinder{
+				P1:     model3d.Z(c.FloorThickness),
+				P2:     model3d.Z(height + 1e-3),
+				Radius: c.InnerRadius(),
+			},
+			c.socket(height),
+		},
+	}
+}
+
+// Wall creates an open-ended module which can be stacked
+// between a base and a lid, or between other walls.
+//
+// The wall has a spigot at the bottom and a socket at the
+// top, and the height does not include the spigot.
+func (c *ContainerSpec) Wall(height float64) model3d.Solid {
+	c.checkHeight(height, c.ThreadLength)
+	return model3d.JoinedSolid{
+		&model3d.SubtractedSolid{
+			Positive: c.body(height),
+			Negative: model3d.JoinedSolid{
+				&model3d.Cylinder{
+					P1:     model3d.Z(-1e-3),
+					P2:     model3d.Z(height + 1e-3),
+					Radius: c.InnerRadius(),
+				},
+				c.socket(height),
+			},
+		},
+		c.spigot(),
+	}
+}
+
+// Lid creates a solid cap with a spigot at the bottom.
+//
+// The height is the thickness of the top of the lid, not
+// including the spigot.
+func (c *ContainerSpec) Lid(height float64) model3d.Solid {
+	c.checkHeight(height, 0)
+	return model3d.JoinedSolid{c.body(height), c.spigot()}
+}
+
+func (c *ContainerSpec) body(height float64) model3d.Solid {
+	return &model3d.Cylinder{
+		P2:     model3d.Z(height),
+		Radius: c.Radius,
+	}
+}
+
+func (c *ContainerSpec) socket(height float64) model3d.Solid {
+	return &ScrewSolid{
+		P1:         model3d.Z(height - c.ThreadLength),
+		P2:         model3d.Z(height + 1e-3),
+		Radius:     c.ThreadRadius() + c.Clearance,
+		GrooveSize: c.GrooveSize,
+	}
+}
+
+func (c *ContainerSpec) spigot() model3d.Solid {
+	// The thread starts at the same height as the socket's
+	// thread once the module is stacked, so that the two
+	// threads are in phase.
+	screw := &ScrewSolid{
+		P1:         model3d.Z(-c.ThreadLength),
+		P2:         model3d.Z(1e-3),
+		Radius:     c.ThreadRadius(),
+		GrooveSize: c.GrooveSize,
+	}
+
+	// Shorten the spigot so that it does not bottom out
+	// before the module's shoulder meets the socket.
+	minZ := -c.ThreadLength + c.Clearance
+	innerRadius := c.InnerRadius()
+	min := screw.Min()
+	min.Z = minZ
+	return model3d.CheckedFuncSolid(min, screw.Max(), func(coord model3d.Coord3D) bool {
+		return coord.Z >= minZ && coord.XY().Norm() >= innerRadius && screw.Contains(coord)
+	})
+}
+
+func (c *ContainerSpec) checkHeight(height, minHeight float64) {
+	if c.WallThickness <= 2*c.GrooveSize {
+		panic("wall thickness must exceed twice the groove size")
+	}
+	if height <= minHeight || math.IsNaN(height) {
+		panic("container module is too short")
+	}
+}
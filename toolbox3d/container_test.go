@@ -0,0 +1,81 @@
+package toolbox3d
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestContainerSpecStack(t *testing.T) {
+	spec := NewContainerSpec(1)
+	base := spec.Base(1)
+	wall := model3d.TranslateSolid(spec.Wall(0.8), model3d.Z(1))
+	lid := model3d.TranslateSolid(spec.Lid(0.1), model3d.Z(1.8))
+
+	parts := []model3d.Solid{base, wall, lid}
+	min, max := base.Min(), lid.Max()
+	for i := 0; i < 50000; i++ {
+		c := model3d.XYZ(rand.Float64(), rand.Float64(), rand.Float64())
+		c = min.Add(c.Mul(max.Sub(min)))
+		count := 0
+		for _, p := range parts {
+			if p.Contains(c) {
+				count++
+			}
+		}
+		if count > 1 {
+			t.Fatalf("modules intersect at %v", c)
+		}
+	}
+
+	// The threads should engage, so the spigots must
+	// overlap the sockets radially.
+	threadZ := 1 - spec.ThreadLength/2
+	for _, r := range []float64{spec.InnerRadius() + 0.01, spec.ThreadRadius() - 0.005} {
+		found := false
+		for i := 0; i < 100; i++ {
+			c := model3d.XYZ(r, 0, threadZ+float64(i)*spec.GrooveSize/50)
+			if wall.Contains(c) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("no spigot at radius %f", r)
+		}
+	}
+
+	// The container should be closed.
+	contains := func(c model3d.Coord3D) bool {
+		for _, p := range parts {
+			if p.Contains(c) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, z := range []float64{0.15, 0.5, 1.2, 1.75} {
+		if contains(model3d.Z(z)) {
+			t.Errorf("interior should be empty at z=%f", z)
+		}
+	}
+	for _, z := range []float64{0.05, 1.85} {
+		if !contains(model3d.Z(z)) {
+			t.Errorf("container should be closed at z=%f", z)
+		}
+	}
+}
+
+func TestContainerSpecMesh(t *testing.T) {
+	spec := NewContainerSpec(1)
+	for i, solid := range []model3d.Solid{spec.Base(1), spec.Wall(0.8), spec.Lid(0.1)} {
+		mesh := model3d.MarchingCubesSearch(solid, 0.02, 8)
+		if mesh.NeedsRepair() {
+			t.Errorf("module %d: mesh needs repair", i)
+		}
+		if _, n := mesh.RepairNormals(1e-5); n != 0 {
+			t.Errorf("module %d: mesh has bad normals", i)
+		}
+	}
+}
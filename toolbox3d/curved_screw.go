@@ -0,0 +1,201 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A CurvedScrewSolid is like a ScrewSolid, but its axis
+// follows a path of connected line segments rather than a
+// single straight line.
+//
+// The grooves are measured along the length of the path
+// and around a frame which twists as little as possible
+// along the path, so that the threads stay evenly spaced
+// around bends.
+// This can be used for curved worm gears and decorative
+// twisted columns.
+//
+// Paths should be sampled finely enough that adjacent
+// segments are nearly parallel, since the grooves are not
+// smooth across sharp corners.
+type CurvedScrewSolid struct {
+	// Profile and Starts are the thread profile and number
+	// of thread starts, as in ScrewSolid.
+	Profile ThreadProfile
+	Starts  int
+
+	path     []model3d.Coord3D
+	segments []*curvedScrewSegment
+
+	radius     float64
+	grooveSize float64
+
+	min model3d.Coord3D
+	max model3d.Coord3D
+}
+
+type curvedScrewSegment struct {
+	Segment model3d.Segment
+	Min     model3d.Coord3D
+	Max     model3d.Coord3D
+
+	// Start is the distance along the path to the start of
+	// the segment.
+	Start  float64
+	Length float64
+
+	// Axis, B1, and B2 form a right-handed frame.
+	Axis model3d.Coord3D
+	B1   model3d.Coord3D
+	B2   model3d.Coord3D
+}
+
+// NewCurvedScrewSolid creates a screw along a path.
+//
+// The radius and grooveSize arguments are analogous to the
+// fields of ScrewSolid.
+// For a path with two points, the result is equivalent to
+// a ScrewSolid from the first point to the second.
+//
+// Duplicate consecutive points in the path are ignored.
+// The path must contain at least two distinct points.
+func NewCurvedScrewSolid(path []model3d.Coord3D, radius, grooveSize float64) *CurvedScrewSolid {
+	var points []model3d.Coord3D
+	for _, p := range path {
+		if len(points) == 0 || points[len(points)-1] != p {
+			points = append(points, p)
+		}
+	}
+	if len(points) < 2 {
+		panic("path must contain at least two distinct points")
+	}
+
+	res := &CurvedScrewSolid{
+		path:       points,
+		radius:     radius,
+		grooveSize: grooveSize,
+		min:        points[0],
+		max:        points[0],
+	}
+	var start float64
+	var b1 model3d.Coord3D
+	for i := 1; i < len(points); i++ {
+		p1, p2 := points[i-1], points[i]
+		diff := p2.Sub(p1)
+		axis := diff.Normalize()
+		if i == 1 {
+			var b2 model3d.Coord3D
+			b1, b2 = axis.OrthoBasis()
+			if b1.Cross(b2).Dot(axis) < 0 {
+				b1 = b2
+			}
+		} else {
+			b1 = parallelTransport(b1, res.segments[i-2].Axis, axis)
+		}
+		b1 = b1.Sub(axis.Scale(b1.Dot(axis))).Normalize()
+		seg := &curvedScrewSegment{
+			Segment: model3d.Segment{p1, p2},
+			Min:     p1.Min(p2).Sub(model3d.XYZ(radius, radius, radius)),
+			Max:     p1.Max(p2).Add(model3d.XYZ(radius, radius, radius)),
+			Start:   start,
+			Length:  diff.Norm(),
+			Axis:    axis,
+			B1:      b1,
+			B2:      axis.Cross(b1),
+		}
+		res.segments = append(res.segments, seg)
+		res.min = res.min.Min(seg.Min)
+		res.max = res.max.Max(seg.Max)
+		start += seg.Length
+	}
+	return res
+}
+
+// ArcPath creates a path along a circular arc, which can
+// be passed to NewCurvedScrewSolid.
+//
+// The arc starts at the point start and rotates around a
+// line through center in the direction of axis, by the
+// given angle in radians, using the right-hand rule.
+// The arc is approximated by numSegments segments.
+func ArcPath(center, axis, start model3d.Coord3D, angle float64,
+	numSegments int) []model3d.Coord3D {
+	res := make([]model3d.Coord3D, numSegments+1)
+	offset := start.Sub(center)
+	for i := range res {
+		theta := angle * float64(i) / float64(numSegments)
+		res[i] = center.Add(model3d.Rotation(axis, theta).Apply(offset))
+	}
+	return res
+}
+
+// Length gets the length of the screw's axis.
+func (c *CurvedScrewSolid) Length() float64 {
+	last := c.segments[len(c.segments)-1]
+	return last.Start + last.Length
+}
+
+func (c *CurvedScrewSolid) Min() model3d.Coord3D {
+	return c.min
+}
+
+func (c *CurvedScrewSolid) Max() model3d.Coord3D {
+	return c.max
+}
+
+func (c *CurvedScrewSolid) Contains(coord model3d.Coord3D) bool {
+	if !model3d.InBounds(c, coord) {
+		return false
+	}
+
+	var closest *curvedScrewSegment
+	var closestFrac float64
+	closestDist := math.Inf(1)
+	for _, seg := range c.segments {
+		if coord.Min(seg.Min) != seg.Min || coord.Max(seg.Max) != seg.Max {
+			continue
+		}
+		frac := coord.Sub(seg.Segment[0]).Dot(seg.Axis) / seg.Length
+		point := seg.Segment[0].Add(seg.Axis.Scale(math.Max(0, math.Min(1, frac)) * seg.Length))
+		if dist := point.Dist(coord); dist < closestDist {
+			closest = seg
+			closestFrac = frac
+			closestDist = dist
+		}
+	}
+	if closest == nil {
+		return false
+	}
+	if closest == c.segments[0] && closestFrac < 0 {
+		return false
+	} else if closest == c.segments[len(c.segments)-1] && closestFrac > 1 {
+		return false
+	}
+
+	maxDistance := c.radius - closestDist
+	if maxDistance < 0 {
+		return false
+	} else if maxDistance > c.grooveSize {
+		return true
+	}
+
+	offset := coord.Sub(closest.Segment[0])
+	z := closest.Start + math.Max(0, math.Min(1, closestFrac))*closest.Length
+	theta := math.Atan2(offset.Dot(closest.B2), offset.Dot(closest.B1))
+	return threadContains(c.Profile, c.Starts, c.grooveSize, z, theta, maxDistance)
+}
+
+// parallelTransport rotates v by the smallest rotation
+// which maps the direction d1 to d2.
+func parallelTransport(v, d1, d2 model3d.Coord3D) model3d.Coord3D {
+	axis := d1.Cross(d2)
+	sin := axis.Norm()
+	if sin < 1e-12 {
+		return v
+	}
+	axis = axis.Scale(1 / sin)
+	cos := d1.Dot(d2)
+	return v.Scale(cos).Add(axis.Cross(v).Scale(sin)).Add(axis.Scale(axis.Dot(v) * (1 - cos)))
+}
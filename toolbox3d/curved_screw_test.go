@@ -0,0 +1,79 @@
+package toolbox3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestCurvedScrewSolidStraight(t *testing.T) {
+	p1 := model3d.XYZ(1, 2, 3)
+	p2 := model3d.XYZ(2, -1, 7)
+	screw := &ScrewSolid{
+		P1:         p1,
+		P2:         p2,
+		Radius:     1,
+		GrooveSize: 0.2,
+	}
+	curved := NewCurvedScrewSolid([]model3d.Coord3D{p1, p1.Mid(p2), p2}, 1, 0.2)
+	if math.Abs(curved.Length()-p1.Dist(p2)) > 1e-8 {
+		t.Errorf("unexpected length: %f", curved.Length())
+	}
+	min, max := screw.Min(), screw.Max()
+	for i := 0; i < 10000; i++ {
+		c := model3d.XYZ(rand.Float64(), rand.Float64(), rand.Float64())
+		c = min.Add(c.Mul(max.Sub(min)))
+		if screw.Contains(c) != curved.Contains(c) {
+			t.Fatalf("mismatch at %v: expected %v", c, screw.Contains(c))
+		}
+	}
+}
+
+func TestCurvedScrewSolidArc(t *testing.T) {
+	const (
+		bendRadius = 5.0
+		radius     = 1.0
+		grooveSize = 0.2
+	)
+	path := ArcPath(model3d.Coord3D{}, model3d.Z(1), model3d.X(bendRadius), math.Pi, 200)
+	screw := NewCurvedScrewSolid(path, radius, grooveSize)
+	if length := screw.Length(); math.Abs(length-math.Pi*bendRadius) > 1e-3 {
+		t.Errorf("unexpected length: %f", length)
+	}
+
+	var grooveCount, grooveTotal int
+	for i := 0; i < 10000; i++ {
+		theta := 0.05 + rand.Float64()*(math.Pi-0.1)
+		phi := rand.Float64() * 2 * math.Pi
+		center := model3d.XY(math.Cos(theta), math.Sin(theta)).Scale(bendRadius)
+		normal := center.Normalize()
+		dir := normal.Scale(math.Cos(phi)).Add(model3d.Z(math.Sin(phi)))
+		if !screw.Contains(center.Add(dir.Scale(radius - grooveSize - 1e-3))) {
+			t.Fatalf("missing core of screw: theta=%f phi=%f", theta, phi)
+		}
+		if screw.Contains(center.Add(dir.Scale(radius + 1e-3))) {
+			t.Fatal("screw is too thick")
+		}
+		if screw.Contains(center.Add(dir.Scale(radius - grooveSize/2))) {
+			grooveCount++
+		}
+		grooveTotal++
+	}
+	frac := float64(grooveCount) / float64(grooveTotal)
+	if math.Abs(frac-0.5) > 0.05 {
+		t.Errorf("expected threads to fill half of the groove region, but got %f", frac)
+	}
+
+	// The ends should be flat.
+	if screw.Contains(model3d.XYZ(bendRadius, -0.01, 0)) ||
+		!screw.Contains(model3d.XYZ(bendRadius, 0.01, 0)) {
+		t.Error("unexpected start of screw")
+	}
+
+	mesh := model3d.MarchingCubesSearch(screw, 0.1, 4)
+	if mesh.NeedsRepair() {
+		t.Error("mesh needs repair")
+	}
+}
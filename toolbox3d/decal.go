@@ -0,0 +1,135 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const (
+	decalProjectionIters  = 16
+	decalDefaultBlendFrac = 0.25
+)
+
+// A Decal describes a smaller part which should be stuck
+// onto the surface of a larger host shape, such as an ear
+// or a nub on the body of a figurine.
+//
+// The part is defined in a local coordinate system where
+// the origin lies on the host's surface and the positive
+// Z axis points out of the surface, along the normal.
+type Decal struct {
+	// Part is the shape to attach, in local coordinates.
+	Part model3d.SDF
+
+	// Point is the approximate location on the host's
+	// surface where the part should be attached.
+	// It is projected onto the surface automatically.
+	Point model3d.Coord3D
+
+	// Spin is a rotation (in radians) of the part around
+	// the surface normal.
+	Spin float64
+
+	// Sink is the distance to push the part into the
+	// host, along the normal.
+	Sink float64
+
+	// BlendRadius is the radius of the smooth fillet
+	// between the part and the host.
+	//
+	// If 0, a radius is chosen automatically based on the
+	// size of the part.
+	BlendRadius float64
+}
+
+// Placement computes the transformation which maps the
+// part's local coordinates into the host's coordinates.
+func (d *Decal) Placement(host model3d.SDF) model3d.DistTransform {
+	point, normal := decalSurfacePoint(host, d.Point)
+
+	// Rotate the local Z axis onto the normal.
+	var rotation model3d.DistTransform
+	cross := model3d.Z(1).Cross(normal)
+	if crossNorm := cross.Norm(); crossNorm < 1e-8 {
+		if normal.Z > 0 {
+			rotation = model3d.Rotation(model3d.Z(1), 0)
+		} else {
+			rotation = model3d.Rotation(model3d.X(1), math.Pi)
+		}
+	} else {
+		angle := math.Atan2(crossNorm, normal.Z)
+		rotation = model3d.Rotation(cross.Scale(1/crossNorm), angle)
+	}
+
+	return model3d.JoinedTransform{
+		model3d.Rotation(model3d.Z(1), d.Spin),
+		rotation,
+		&model3d.Translate{Offset: point.Sub(normal.Scale(d.Sink))},
+	}
+}
+
+// PlacedPart gets the part's SDF in the host's
+// coordinates.
+func (d *Decal) PlacedPart(host model3d.SDF) model3d.SDF {
+	return model3d.TransformSDF(d.Placement(host), d.Part)
+}
+
+// Attach joins the part to the host, smoothly blending
+// the two shapes together around the seam.
+func (d *Decal) Attach(host model3d.SDF) model3d.Solid {
+	return AttachDecals(host, d)
+}
+
+// AttachDecals joins any number of decals onto the
+// surface of a host shape, smoothly blending each part
+// with the host.
+//
+// The largest BlendRadius of all the decals is used for
+// the entire model.
+//
+// To use a model3d.Solid as a host, it can first be
+// converted to an SDF, e.g. by creating a mesh with
+// model3d.MarchingCubesSearch and using
+// model3d.MeshToSDF.
+func AttachDecals(host model3d.SDF, decals ...*Decal) model3d.Solid {
+	sdfs := []model3d.SDF{host}
+	var radius float64
+	for _, d := range decals {
+		sdfs = append(sdfs, d.PlacedPart(host))
+		radius = math.Max(radius, d.blendRadius())
+	}
+	return model3d.SmoothJoin(radius, sdfs...)
+}
+
+func (d *Decal) blendRadius() float64 {
+	if d.BlendRadius != 0 {
+		return d.BlendRadius
+	}
+	size := d.Part.Max().Sub(d.Part.Min())
+	return decalDefaultBlendFrac * math.Min(size.X, math.Min(size.Y, size.Z))
+}
+
+// decalSurfacePoint projects c onto the surface of an SDF
+// and computes the outward normal at the resulting point.
+func decalSurfacePoint(s model3d.SDF, c model3d.Coord3D) (point, normal model3d.Coord3D) {
+	if p, ok := s.(model3d.PointSDF); ok {
+		point, _ = p.PointSDF(c)
+	} else {
+		point = c
+		for i := 0; i < decalProjectionIters; i++ {
+			grad := model3d.SDFGradient(s, point, 0)
+			if grad.Norm() == 0 {
+				break
+			}
+			point = point.Sub(grad.Normalize().Scale(s.SDF(point)))
+		}
+	}
+	if f, ok := s.(model3d.FaceSDF); ok {
+		face, _, _ := f.FaceSDF(point)
+		normal = face.Normal()
+	} else {
+		normal = model3d.SDFGradient(s, point, 0).Scale(-1).Normalize()
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package toolbox3d
+
+import (
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestDecalPlacement(t *testing.T) {
+	host := &model3d.Sphere{Radius: 2}
+	part := &model3d.Cylinder{P2: model3d.Z(0.5), Radius: 0.2}
+
+	for _, point := range []model3d.Coord3D{
+		model3d.XYZ(2.5, 0.3, -0.1),
+		model3d.XYZ(0, 0, 3),
+		model3d.XYZ(0, 0, -3),
+	} {
+		decal := &Decal{Part: part, Point: point, Spin: 0.3}
+		placement := decal.Placement(host)
+
+		expectedPoint := point.Normalize().Scale(2)
+		if actual := placement.Apply(model3d.Coord3D{}); actual.Dist(expectedPoint) > 1e-5 {
+			t.Errorf("expected origin to map to %v but got %v", expectedPoint, actual)
+		}
+		expectedNormal := point.Normalize()
+		actualNormal := placement.Apply(model3d.Z(1)).Sub(placement.Apply(model3d.Coord3D{}))
+		if actualNormal.Dist(expectedNormal) > 1e-5 {
+			t.Errorf("expected normal %v but got %v", expectedNormal, actualNormal)
+		}
+	}
+}
+
+func TestDecalAttachGenericSDF(t *testing.T) {
+	// Hide the PointSDF implementation to test projection.
+	host := model3d.FuncSDF(model3d.XYZ(-2, -2, -2), model3d.XYZ(2, 2, 2),
+		(&model3d.Sphere{Radius: 2}).SDF)
+	decal := &Decal{
+		Part:  &model3d.Cylinder{P1: model3d.Z(-0.5), P2: model3d.Z(0.5), Radius: 0.2},
+		Point: model3d.XYZ(0, 3, 0),
+	}
+	solid := decal.Attach(host)
+	if !solid.Contains(model3d.Y(2.4)) {
+		t.Error("expected part to be attached along normal")
+	}
+	if solid.Contains(model3d.XYZ(0.4, 2.4, 0)) {
+		t.Error("expected point beside part to be outside")
+	}
+	// The fillet should add material right beside the seam.
+	if !solid.Contains(model3d.XYZ(0.21, 2.0, 0)) {
+		t.Error("expected blend around the seam")
+	}
+}
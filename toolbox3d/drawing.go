@@ -0,0 +1,508 @@
+package toolbox3d
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/fileformats"
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const (
+	DefaultDrawingCreaseAngle     = math.Pi / 6
+	DefaultDrawingDimensionFormat = "%.2f"
+
+	drawingDefaultResolution = 1.0 / 200
+	drawingLineWidth         = 0.004
+	drawingFontSize          = 0.05
+	drawingViewGap           = 0.4
+)
+
+// A DrawingView is an orthographic projection of a mesh's
+// edges, as seen in an engineering drawing.
+//
+// Coordinates in the view have the x-axis pointing to the
+// right and the y-axis pointing up.
+type DrawingView struct {
+	// Axis is the axis the view looks along: AxisY for a
+	// front view, AxisZ for a top view, or AxisX for a
+	// right side view.
+	Axis Axis
+
+	// Visible contains the edges which can be seen.
+	Visible *model2d.Mesh
+
+	// Hidden contains the edges which are obscured by
+	// other parts of the mesh, which are typically drawn
+	// with dashed lines.
+	Hidden *model2d.Mesh
+
+	// Min and Max are the bounds of the projected mesh.
+	Min model2d.Coord
+	Max model2d.Coord
+}
+
+// A TechnicalDrawing creates simple engineering drawings
+// of meshes, with front, top, and right side views
+// annotated with the overall dimensions.
+//
+// Only the silhouette, boundary, and sharp edges of the
+// mesh are drawn, so that curved surfaces made of many
+// small triangles do not clutter the drawing.
+type TechnicalDrawing struct {
+	// CreaseAngle is the minimum angle, in radians, between
+	// the normals of two neighboring triangles for their
+	// shared edge to be drawn.
+	//
+	// If 0, DefaultDrawingCreaseAngle is used.
+	CreaseAngle float64
+
+	// Resolution is the length of the pieces that edges
+	// are split into when determining which parts of them
+	// are hidden.
+	//
+	// If 0, a small fraction of the mesh's size is used.
+	Resolution float64
+
+	// DimensionFormat is the format string used to label
+	// dimensions, such as "%.1f mm".
+	//
+	// If empty, DefaultDrawingDimensionFormat is used.
+	DimensionFormat string
+}
+
+// View projects the mesh along an axis.
+func (t *TechnicalDrawing) View(m *model3d.Mesh, axis Axis) *DrawingView {
+	toViewer, project := drawingProjection(axis)
+
+	view := &DrawingView{
+		Axis:    axis,
+		Visible: model2d.NewMesh(),
+		Hidden:  model2d.NewMesh(),
+		Min:     model2d.Ones(math.Inf(1)),
+		Max:     model2d.Ones(math.Inf(-1)),
+	}
+	m.IterateVertices(func(c model3d.Coord3D) {
+		view.Min = view.Min.Min(project(c))
+		view.Max = view.Max.Max(project(c))
+	})
+
+	diameter := m.Max().Dist(m.Min())
+	resolution := t.Resolution
+	if resolution == 0 {
+		resolution = diameter * drawingDefaultResolution
+	}
+	creaseAngle := t.CreaseAngle
+	if creaseAngle == 0 {
+		creaseAngle = DefaultDrawingCreaseAngle
+	}
+	creaseCos := math.Cos(creaseAngle)
+	epsilon := diameter * 1e-5
+
+	var edges []model3d.Segment
+	var outwards []model3d.Coord3D
+	for _, edge := range drawingEdges(m) {
+		tris := m.Find(edge[0], edge[1])
+		if !drawingFeatureEdge(tris, toViewer, creaseCos) {
+			continue
+		}
+		if project(edge[0]).Dist(project(edge[1])) < epsilon {
+			// The edge is seen end-on.
+			continue
+		}
+		var outward model3d.Coord3D
+		for _, t := range tris {
+			outward = outward.Add(t.Normal())
+		}
+		if norm := outward.Norm(); norm > 0 {
+			outward = outward.Scale(1 / norm)
+		}
+		edges = append(edges, edge)
+		outwards = append(outwards, outward)
+	}
+
+	// Split each edge into pieces and check if each piece
+	// can be seen by casting a ray towards the viewer.
+	collider := model3d.MeshToCollider(m)
+	hidden := make([][]bool, len(edges))
+	essentials.ConcurrentMap(0, len(edges), func(i int) {
+		edge := edges[i]
+		n := essentials.MaxInt(1, int(math.Ceil(edge[0].Dist(edge[1])/resolution)))
+		hidden[i] = make([]bool, n)
+		offset := toViewer.Add(outwards[i]).Scale(epsilon)
+		for j := range hidden[i] {
+			frac := (float64(j) + 0.5) / float64(n)
+			ray := &model3d.Ray{
+				Origin:    edge[0].Add(edge[1].Sub(edge[0]).Scale(frac)).Add(offset),
+				Direction: toViewer,
+			}
+			_, hidden[i][j] = collider.FirstRayCollision(ray)
+		}
+	})
+
+	for i, edge := range edges {
+		visible := make([]bool, len(hidden[i]))
+		for j, h := range hidden[i] {
+			visible[j] = !h
+		}
+		drawingAddRuns(view.Visible, project(edge[0]), project(edge[1]), visible)
+	}
+
+	// Hidden lines behind visible lines are redundant, and
+	// often come from edges along the silhouette.
+	visibleCollider := model2d.MeshToCollider(view.Visible)
+	tolerance := resolution / 4
+	for i, edge := range edges {
+		p1, p2 := project(edge[0]), project(edge[1])
+		pieces := hidden[i]
+		for j, h := range pieces {
+			if !h {
+				continue
+			}
+			t1 := float64(j) / float64(len(pieces))
+			t2 := float64(j+1) / float64(len(pieces))
+			covered := true
+			for _, t := range []float64{t1, (t1 + t2) / 2, t2} {
+				c := p1.Add(p2.Sub(p1).Scale(t))
+				if !visibleCollider.CircleCollision(c, tolerance) {
+					covered = false
+					break
+				}
+			}
+			pieces[j] = !covered
+		}
+		drawingAddRuns(view.Hidden, p1, p2, pieces)
+	}
+
+	return view
+}
+
+// WriteSVG writes a drawing of the mesh to an SVG file.
+//
+// The views are arranged using third-angle projection,
+// with the top view above the front view and the right
+// side view to the right of the front view.
+func (t *TechnicalDrawing) WriteSVG(w io.Writer, m *model3d.Mesh) error {
+	front := t.View(m, AxisY)
+	top := t.View(m, AxisZ)
+	right := t.View(m, AxisX)
+
+	size := m.Max().Sub(m.Min())
+	maxSize := math.Max(size.X, math.Max(size.Y, size.Z))
+	gap := maxSize * drawingViewGap
+
+	frontOffset := front.Min.Scale(-1)
+	topOffset := model2d.XY(frontOffset.X, front.Max.Y+frontOffset.Y+gap-top.Min.Y)
+	rightOffset := model2d.XY(front.Max.X+frontOffset.X+gap-right.Min.X, frontOffset.Y)
+
+	d := &drawingSVG{
+		lineWidth: maxSize * drawingLineWidth,
+		fontSize:  maxSize * drawingFontSize,
+		format:    t.DimensionFormat,
+		min:       model2d.Ones(math.Inf(1)),
+		max:       model2d.Ones(math.Inf(-1)),
+	}
+	if d.format == "" {
+		d.format = DefaultDrawingDimensionFormat
+	}
+	views := []*DrawingView{front, top, right}
+	offsets := []model2d.Coord{frontOffset, topOffset, rightOffset}
+	for i, view := range views {
+		d.View(view, offsets[i])
+	}
+
+	// Label each overall dimension once.
+	dimOffset := gap * 0.4
+	frontMin, frontMax := front.Min.Add(frontOffset), front.Max.Add(frontOffset)
+	d.Dimension(frontMin, model2d.XY(frontMax.X, frontMin.Y), model2d.Y(-dimOffset))
+	d.Dimension(frontMin, model2d.XY(frontMin.X, frontMax.Y), model2d.X(-dimOffset))
+	topMin, topMax := top.Min.Add(topOffset), top.Max.Add(topOffset)
+	d.Dimension(topMin, model2d.XY(topMin.X, topMax.Y), model2d.X(-dimOffset))
+
+	if err := d.Write(w); err != nil {
+		return errors.Wrap(err, "write technical drawing")
+	}
+	return nil
+}
+
+// SaveSVG saves a drawing of the mesh to an SVG file.
+//
+// See WriteSVG for details.
+func (t *TechnicalDrawing) SaveSVG(path string, m *model3d.Mesh) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "save technical drawing")
+	}
+	defer f.Close()
+	if err := t.WriteSVG(f, m); err != nil {
+		return errors.Wrap(err, "save technical drawing")
+	}
+	return nil
+}
+
+// drawingAddRuns adds the parts of the segment from p1 to
+// p2 for which include is true, where include divides the
+// segment into equally sized pieces.
+func drawingAddRuns(m *model2d.Mesh, p1, p2 model2d.Coord, include []bool) {
+	start := 0
+	for j := 1; j <= len(include); j++ {
+		if j < len(include) && include[j] == include[start] {
+			continue
+		}
+		if include[start] {
+			t1 := float64(start) / float64(len(include))
+			t2 := float64(j) / float64(len(include))
+			m.Add(&model2d.Segment{
+				p1.Add(p2.Sub(p1).Scale(t1)),
+				p1.Add(p2.Sub(p1).Scale(t2)),
+			})
+		}
+		start = j
+	}
+}
+
+// drawingProjection gets the direction pointing towards
+// the viewer and a function mapping points into the view
+// for a given axis.
+func drawingProjection(axis Axis) (model3d.Coord3D, func(c model3d.Coord3D) model2d.Coord) {
+	switch axis {
+	case AxisX:
+		return model3d.X(1), func(c model3d.Coord3D) model2d.Coord {
+			return model2d.XY(c.Y, c.Z)
+		}
+	case AxisY:
+		return model3d.Y(-1), func(c model3d.Coord3D) model2d.Coord {
+			return model2d.XY(c.X, c.Z)
+		}
+	case AxisZ:
+		return model3d.Z(1), func(c model3d.Coord3D) model2d.Coord {
+			return model2d.XY(c.X, c.Y)
+		}
+	default:
+		panic("unknown axis")
+	}
+}
+
+// drawingEdges gets the unique edges of a mesh in a
+// deterministic order.
+func drawingEdges(m *model3d.Mesh) []model3d.Segment {
+	edgeSet := map[model3d.Segment]bool{}
+	m.Iterate(func(t *model3d.Triangle) {
+		for _, seg := range t.Segments() {
+			if drawingCoordLess(seg[1], seg[0]) {
+				seg[0], seg[1] = seg[1], seg[0]
+			}
+			edgeSet[seg] = true
+		}
+	})
+	edges := make([]model3d.Segment, 0, len(edgeSet))
+	for edge := range edgeSet {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		e1, e2 := edges[i], edges[j]
+		if e1[0] != e2[0] {
+			return drawingCoordLess(e1[0], e2[0])
+		}
+		return drawingCoordLess(e1[1], e2[1])
+	})
+	return edges
+}
+
+func drawingCoordLess(c1, c2 model3d.Coord3D) bool {
+	a1, a2 := c1.Array(), c2.Array()
+	for i := range a1 {
+		if a1[i] != a2[i] {
+			return a1[i] < a2[i]
+		}
+	}
+	return false
+}
+
+// drawingFeatureEdge checks if an edge with the given
+// neighboring triangles should appear in a drawing.
+func drawingFeatureEdge(tris []*model3d.Triangle, toViewer model3d.Coord3D,
+	creaseCos float64) bool {
+	if len(tris) != 2 {
+		return true
+	}
+	n1, n2 := tris[0].Normal(), tris[1].Normal()
+	if n1.Dot(n2) < creaseCos {
+		return true
+	}
+	// Silhouettes of smooth surfaces.
+	return n1.Dot(toViewer)*n2.Dot(toViewer) < 0
+}
+
+// drawingSVG accumulates the elements of a drawing so that
+// the SVG's bounds are known before it is written.
+type drawingSVG struct {
+	lineWidth float64
+	fontSize  float64
+	format    string
+
+	elements []func(w *fileformats.SVGWriter) error
+	min      model2d.Coord
+	max      model2d.Coord
+}
+
+func (d *drawingSVG) View(v *DrawingView, offset model2d.Coord) {
+	// Draw visible lines last so that they take precedence.
+	hiddenAttrs := map[string]string{
+		"stroke":           "gray",
+		"stroke-width":     fmt.Sprintf("%f", d.lineWidth*0.75),
+		"stroke-dasharray": fmt.Sprintf("%f,%f", d.lineWidth*4, d.lineWidth*3),
+		"fill":             "none",
+	}
+	visibleAttrs := map[string]string{
+		"stroke":         "black",
+		"stroke-width":   fmt.Sprintf("%f", d.lineWidth),
+		"stroke-linecap": "round",
+		"fill":           "none",
+	}
+	for _, mesh := range []*model2d.Mesh{v.Hidden, v.Visible} {
+		attrs := hiddenAttrs
+		if mesh == v.Visible {
+			attrs = visibleAttrs
+		}
+		segs := mesh.SegmentSlice()
+		sort.Slice(segs, func(i, j int) bool {
+			s1, s2 := segs[i], segs[j]
+			for k := 0; k < 2; k++ {
+				if s1[k] != s2[k] {
+					if s1[k].X != s2[k].X {
+						return s1[k].X < s2[k].X
+					}
+					return s1[k].Y < s2[k].Y
+				}
+			}
+			return false
+		})
+		for _, seg := range segs {
+			d.Line(seg[0].Add(offset), seg[1].Add(offset), attrs)
+		}
+	}
+	d.expand(v.Min.Add(offset))
+	d.expand(v.Max.Add(offset))
+}
+
+// Dimension draws a dimension between two points, where
+// the dimension line is moved away from the points by an
+// offset perpendicular to the line between them.
+func (d *drawingSVG) Dimension(p1, p2, offset model2d.Coord) {
+	attrs := map[string]string{
+		"stroke":       "black",
+		"stroke-width": fmt.Sprintf("%f", d.lineWidth*0.5),
+		"fill":         "none",
+	}
+	offsetDir := offset.Normalize()
+	extGap := d.lineWidth * 2
+	for _, p := range []model2d.Coord{p1, p2} {
+		d.Line(p.Add(offsetDir.Scale(extGap)), p.Add(offset).Add(offsetDir.Scale(extGap*2)), attrs)
+	}
+
+	q1, q2 := p1.Add(offset), p2.Add(offset)
+	d.Line(q1, q2, attrs)
+	dir := q2.Sub(q1).Normalize()
+	arrowLen := d.fontSize * 0.6
+	arrowWidth := arrowLen * 0.3
+	perp := model2d.XY(-dir.Y, dir.X)
+	for _, arrow := range [][2]model2d.Coord{{q1, dir}, {q2, dir.Scale(-1)}} {
+		tip, u := arrow[0], arrow[1]
+		base := tip.Add(u.Scale(arrowLen))
+		d.Polygon([]model2d.Coord{
+			tip,
+			base.Add(perp.Scale(arrowWidth)),
+			base.Sub(perp.Scale(arrowWidth)),
+		}, map[string]string{"fill": "black"})
+	}
+
+	label := fmt.Sprintf(d.format, p1.Dist(p2))
+	mid := q1.Mid(q2)
+	vertical := math.Abs(dir.Y) > math.Abs(dir.X)
+	anchor := mid.Add(offsetDir.Scale(d.fontSize * 0.3))
+	if !vertical && offsetDir.Y < 0 {
+		// Text extends above its baseline, so it must be
+		// moved down to sit below the dimension line.
+		anchor = anchor.Sub(model2d.Y(d.fontSize))
+	}
+	d.Text(anchor, label, vertical)
+}
+
+func (d *drawingSVG) Line(p1, p2 model2d.Coord, attrs map[string]string) {
+	d.expand(p1)
+	d.expand(p2)
+	d.elements = append(d.elements, func(w *fileformats.SVGWriter) error {
+		return w.WritePoly([][2]float64{drawingSVGPoint(p1), drawingSVGPoint(p2)}, attrs)
+	})
+}
+
+func (d *drawingSVG) Polygon(points []model2d.Coord, attrs map[string]string) {
+	arrs := make([][2]float64, len(points)+1)
+	for i, p := range points {
+		d.expand(p)
+		arrs[i] = drawingSVGPoint(p)
+	}
+	arrs[len(points)] = arrs[0]
+	d.elements = append(d.elements, func(w *fileformats.SVGWriter) error {
+		return w.WritePoly(arrs, attrs)
+	})
+}
+
+func (d *drawingSVG) Text(anchor model2d.Coord, text string, vertical bool) {
+	// Roughly estimate the text size to fit it in bounds.
+	halfWidth := float64(len(text)) * d.fontSize * 0.3
+	if vertical {
+		d.expand(anchor.Sub(model2d.XY(d.fontSize, halfWidth)))
+		d.expand(anchor.Add(model2d.Y(halfWidth)))
+	} else {
+		d.expand(anchor.Sub(model2d.X(halfWidth)))
+		d.expand(anchor.Add(model2d.XY(halfWidth, d.fontSize)))
+	}
+	p := drawingSVGPoint(anchor)
+	attrs := map[string]string{
+		"font-family": "sans-serif",
+		"font-size":   fmt.Sprintf("%f", d.fontSize),
+		"text-anchor": "middle",
+	}
+	if vertical {
+		attrs["transform"] = fmt.Sprintf("rotate(-90 %f %f)", p[0], p[1])
+	}
+	d.elements = append(d.elements, func(w *fileformats.SVGWriter) error {
+		return w.WriteText(p[0], p[1], text, attrs)
+	})
+}
+
+func (d *drawingSVG) Write(w io.Writer) error {
+	margin := d.fontSize
+	min, max := d.min.Sub(model2d.Ones(margin)), d.max.Add(model2d.Ones(margin))
+	writer, err := fileformats.NewSVGWriter(w, [4]float64{
+		min.X, -max.Y, max.X - min.X, max.Y - min.Y,
+	})
+	if err != nil {
+		return err
+	}
+	for _, element := range d.elements {
+		if err := element(writer); err != nil {
+			return err
+		}
+	}
+	return writer.WriteEnd()
+}
+
+func (d *drawingSVG) expand(c model2d.Coord) {
+	d.min = d.min.Min(c)
+	d.max = d.max.Max(c)
+}
+
+// drawingSVGPoint flips the y-axis, since SVG files have
+// a y-axis pointing downward.
+func drawingSVGPoint(c model2d.Coord) [2]float64 {
+	return [2]float64{c.X, -c.Y}
+}
@@ -0,0 +1,95 @@
+package toolbox3d
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestTechnicalDrawingView(t *testing.T) {
+	box := model3d.NewMeshRect(model3d.Coord3D{}, model3d.XYZ(3, 2, 1))
+	drawing := &TechnicalDrawing{}
+
+	expectedSizes := map[Axis]model2d.Coord{
+		AxisX: model2d.XY(2, 1),
+		AxisY: model2d.XY(3, 1),
+		AxisZ: model2d.XY(3, 2),
+	}
+	for axis, size := range expectedSizes {
+		view := drawing.View(box, axis)
+		if actual := view.Max.Sub(view.Min); actual.Dist(size) > 1e-8 {
+			t.Errorf("axis %d: expected size %v but got %v", axis, size, actual)
+		}
+		if n := len(view.Hidden.SegmentSlice()); n != 0 {
+			t.Errorf("axis %d: unexpected hidden segments: %d", axis, n)
+		}
+		// Only the outline of the box should be visible, and
+		// diagonals of the box's faces are not creases.
+		// The front and back edges both lie on the outline.
+		var length float64
+		view.Visible.Iterate(func(s *model2d.Segment) {
+			length += s.Length()
+		})
+		if expected := 4 * (size.X + size.Y); math.Abs(length-expected) > 1e-8 {
+			t.Errorf("axis %d: expected outline length %f but got %f", axis, expected, length)
+		}
+	}
+}
+
+func TestTechnicalDrawingHidden(t *testing.T) {
+	// A box with a pocket in its top face, which is hidden
+	// in the front view.
+	mesh := drawingTestPocket()
+
+	view := (&TechnicalDrawing{}).View(mesh, AxisY)
+	var hiddenLength float64
+	view.Hidden.Iterate(func(s *model2d.Segment) {
+		hiddenLength += s.Length()
+	})
+	// The pocket's floor and vertical edges, which may be
+	// doubled up by the beveled edges from marching cubes.
+	if hiddenLength < 4 || hiddenLength > 8.5 {
+		t.Errorf("unexpected hidden length: %f", hiddenLength)
+	}
+
+	// From the top, the pocket is visible.
+	view = (&TechnicalDrawing{}).View(mesh, AxisZ)
+	view.Hidden.Iterate(func(s *model2d.Segment) {
+		if s.Length() > 0.1 {
+			t.Errorf("unexpected hidden segment in top view: %v", s)
+		}
+	})
+}
+
+func TestTechnicalDrawingSVG(t *testing.T) {
+	mesh := drawingTestPocket()
+
+	var buf bytes.Buffer
+	drawing := &TechnicalDrawing{DimensionFormat: "%.1f mm"}
+	if err := drawing.WriteSVG(&buf, mesh); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+	for _, label := range []string{">3.0 mm<", ">2.0 mm<", ">1.0 mm<"} {
+		if !strings.Contains(data, label) {
+			t.Errorf("missing label %s", label)
+		}
+	}
+	if !strings.Contains(data, "stroke-dasharray") {
+		t.Error("missing hidden lines")
+	}
+}
+
+func drawingTestPocket() *model3d.Mesh {
+	return model3d.MarchingCubesSearch(&model3d.SubtractedSolid{
+		Positive: &model3d.Rect{MaxVal: model3d.XYZ(3, 2, 1)},
+		Negative: &model3d.Rect{
+			MinVal: model3d.XYZ(1, 0.5, 0.5),
+			MaxVal: model3d.XYZ(2, 1.5, 2),
+		},
+	}, 0.05, 8)
+}
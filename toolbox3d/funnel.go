@@ -0,0 +1,278 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A Funnel is a conical funnel with a cylindrical spout.
+//
+// The funnel is centered on the z-axis, with the end of
+// the spout at z=0 and the wide opening at the top.
+// All radii are measured on the inside of the funnel.
+type Funnel struct {
+	TopRadius   float64
+	SpoutRadius float64
+
+	ConeHeight  float64
+	SpoutLength float64
+
+	// WallThickness is measured perpendicular to the walls.
+	WallThickness float64
+}
+
+// Solid creates the funnel.
+func (f *Funnel) Solid() model3d.Solid {
+	slope := (f.TopRadius - f.SpoutRadius) / f.ConeHeight
+	maxRadius := math.Max(f.TopRadius, f.SpoutRadius) +
+		f.WallThickness*math.Sqrt(1+slope*slope)
+	height := f.SpoutLength + f.ConeHeight
+	return sweptWallSolid(
+		model3d.XYZ(-maxRadius, -maxRadius, 0),
+		model3d.XYZ(maxRadius, maxRadius, height),
+		f.WallThickness,
+		func(c model3d.Coord3D) float64 {
+			r := f.SpoutRadius
+			if c.Z > f.SpoutLength {
+				r += slope * (c.Z - f.SpoutLength)
+			}
+			return c.XY().Norm() - r
+		},
+	)
+}
+
+// A Hopper is a funnel which transitions from a rectangular
+// opening at the top to a round spout at the bottom.
+//
+// The hopper is centered on the z-axis, with the end of
+// the spout at z=0.
+// All dimensions are measured on the inside of the hopper.
+type Hopper struct {
+	// TopWidth and TopDepth are the size of the opening
+	// along the x and y axes, respectively, and
+	// TopCornerRadius rounds its corners.
+	TopWidth        float64
+	TopDepth        float64
+	TopCornerRadius float64
+
+	SpoutRadius float64
+
+	// Height is the height of the transition, not
+	// including the spout.
+	Height      float64
+	SpoutLength float64
+
+	// WallThickness is approximately measured
+	// perpendicular to the walls.
+	WallThickness float64
+}
+
+// Solid creates the hopper.
+//
+// Cross-sections are interpolated between the circle and
+// the rounded rectangle, so the interior is smooth.
+func (h *Hopper) Solid() model3d.Solid {
+	halfW, halfD := h.TopWidth/2, h.TopDepth/2
+	rr := h.TopCornerRadius
+	maxSize := math.Max(math.Hypot(halfW, halfD), h.SpoutRadius)
+	slope := maxSize / h.Height
+	pad := h.WallThickness * math.Sqrt(1+slope*slope)
+	return sweptWallSolid(
+		model3d.XYZ(-maxSize-pad, -maxSize-pad, 0),
+		model3d.XYZ(maxSize+pad, maxSize+pad, h.SpoutLength+h.Height),
+		h.WallThickness,
+		func(c model3d.Coord3D) float64 {
+			circleDist := c.XY().Norm() - h.SpoutRadius
+			if c.Z <= h.SpoutLength {
+				return circleDist
+			}
+			dx := math.Abs(c.X) - (halfW - rr)
+			dy := math.Abs(c.Y) - (halfD - rr)
+			outside := math.Hypot(math.Max(dx, 0), math.Max(dy, 0))
+			rectDist := outside + math.Min(math.Max(dx, dy), 0) - rr
+			t := (c.Z - h.SpoutLength) / h.Height
+			return (1-t)*circleDist + t*rectDist
+		},
+	)
+}
+
+// An AdapterEndKind determines how an end of an Adapter
+// connects to other parts.
+type AdapterEndKind int
+
+const (
+	// PlainEnd is a plain tube.
+	PlainEnd AdapterEndKind = iota
+
+	// HoseBarbEnd is a tube with barbs around the outside
+	// to hold a hose in place.
+	HoseBarbEnd
+
+	// MaleThreadEnd is a threaded tube.
+	MaleThreadEnd
+
+	// FemaleThreadEnd is a threaded socket.
+	FemaleThreadEnd
+)
+
+// An AdapterEnd describes one end of an Adapter.
+type AdapterEnd struct {
+	Kind AdapterEndKind
+
+	// Radius is the outer radius of a plain tube, a hose
+	// barb (not including the barbs), or a male thread.
+	// For a female thread, it is the maximum radius of the
+	// threaded hole.
+	Radius float64
+
+	Length float64
+
+	// GrooveSize is the thread groove size, as in
+	// ScrewSolid.
+	GrooveSize float64
+
+	// NumBarbs is the number of barbs on a hose barb, and
+	// BarbSize is how far each barb sticks out.
+	NumBarbs int
+	BarbSize float64
+}
+
+func (a *AdapterEnd) outerRadius(wallThickness float64) float64 {
+	if a.Kind == FemaleThreadEnd {
+		return a.Radius + wallThickness
+	}
+	return a.Radius
+}
+
+func (a *AdapterEnd) innerRadius(wallThickness float64) float64 {
+	switch a.Kind {
+	case FemaleThreadEnd:
+		return a.Radius - a.GrooveSize
+	case MaleThreadEnd:
+		return a.Radius - a.GrooveSize - wallThickness
+	default:
+		return a.Radius - wallThickness
+	}
+}
+
+// solid creates the end with its tip at z=0, extending to
+// z=Length (and slightly beyond to overlap the transition).
+func (a *AdapterEnd) solid(wallThickness float64) model3d.Solid {
+	inner := a.innerRadius(wallThickness)
+	outer := a.outerRadius(wallThickness)
+	maxZ := a.Length + 1e-3
+	maxRadius := outer + math.Max(a.BarbSize, 0)
+	min := model3d.XYZ(-maxRadius, -maxRadius, 0)
+	max := model3d.XYZ(maxRadius, maxRadius, maxZ)
+	switch a.Kind {
+	case PlainEnd, HoseBarbEnd:
+		barbLength := a.Length / float64(a.NumBarbs)
+		return model3d.CheckedFuncSolid(min, max, func(c model3d.Coord3D) bool {
+			r := c.XY().Norm()
+			outerR := outer
+			if a.Kind == HoseBarbEnd && a.NumBarbs > 0 && c.Z < a.Length {
+				// Each barb widens away from the tip, so that a
+				// hose slides on but not off.
+				frac := c.Z / barbLength
+				outerR += a.BarbSize * (frac - math.Floor(frac))
+			}
+			return r >= inner && r <= outerR
+		})
+	case MaleThreadEnd:
+		screw := &ScrewSolid{
+			P2:         model3d.Z(maxZ),
+			Radius:     a.Radius,
+			GrooveSize: a.GrooveSize,
+		}
+		return model3d.CheckedFuncSolid(min, max, func(c model3d.Coord3D) bool {
+			return c.XY().Norm() >= inner && screw.Contains(c)
+		})
+	case FemaleThreadEnd:
+		hole := &ScrewSolid{
+			P1:         model3d.Z(-1e-3),
+			P2:         model3d.Z(maxZ + 1e-3),
+			Radius:     a.Radius,
+			GrooveSize: a.GrooveSize,
+		}
+		return model3d.CheckedFuncSolid(min, max, func(c model3d.Coord3D) bool {
+			return c.XY().Norm() <= outer && !hole.Contains(c)
+		})
+	default:
+		panic("unknown adapter end kind")
+	}
+}
+
+// An Adapter joins two tubes of different sizes or kinds,
+// such as a threaded pipe and a hose.
+//
+// The adapter is centered on the z-axis, with the tip of
+// the bottom end at z=0.
+// The ends are joined by a transition whose inner and
+// outer radii change smoothly.
+type Adapter struct {
+	Bottom AdapterEnd
+	Top    AdapterEnd
+
+	TransitionLength float64
+
+	// WallThickness is the thickness of each tube, not
+	// including threads or barbs.
+	WallThickness float64
+}
+
+// Height gets the total height of the adapter.
+func (a *Adapter) Height() float64 {
+	return a.Bottom.Length + a.TransitionLength + a.Top.Length
+}
+
+// Solid creates the adapter.
+func (a *Adapter) Solid() model3d.Solid {
+	z1 := a.Bottom.Length
+	z2 := z1 + a.TransitionLength
+	inner1, inner2 := a.Bottom.innerRadius(a.WallThickness), a.Top.innerRadius(a.WallThickness)
+	outer1, outer2 := a.Bottom.outerRadius(a.WallThickness), a.Top.outerRadius(a.WallThickness)
+	maxRadius := math.Max(outer1, outer2)
+	transition := model3d.CheckedFuncSolid(
+		model3d.XYZ(-maxRadius, -maxRadius, z1),
+		model3d.XYZ(maxRadius, maxRadius, z2),
+		func(c model3d.Coord3D) bool {
+			t := (c.Z - z1) / a.TransitionLength
+			t = t * t * (3 - 2*t)
+			r := c.XY().Norm()
+			return r >= inner1+(inner2-inner1)*t && r <= outer1+(outer2-outer1)*t
+		},
+	)
+	top := model3d.TransformSolid(
+		model3d.JoinedTransform{
+			model3d.Rotation(model3d.X(1), math.Pi),
+			&model3d.Translate{Offset: model3d.Z(a.Height())},
+		},
+		a.Top.solid(a.WallThickness),
+	)
+	return model3d.JoinedSolid{a.Bottom.solid(a.WallThickness), transition, top}
+}
+
+// sweptWallSolid creates a wall on the outside of the
+// surface where dist is zero.
+//
+// The dist function should approximate the horizontal
+// distance to the surface, with positive values outside.
+// The horizontal thickness of the wall is increased on
+// sloped parts of the surface to keep the perpendicular
+// thickness roughly constant.
+func sweptWallSolid(min, max model3d.Coord3D, thickness float64,
+	dist func(c model3d.Coord3D) float64) model3d.Solid {
+	const epsilon = 1e-4
+	return model3d.CheckedFuncSolid(min, max, func(c model3d.Coord3D) bool {
+		d := dist(c)
+		if d < 0 {
+			return false
+		}
+		z1, z2 := math.Max(min.Z, c.Z-epsilon), math.Min(max.Z, c.Z+epsilon)
+		c1, c2 := c, c
+		c1.Z, c2.Z = z1, z2
+		slope := (dist(c2) - dist(c1)) / (z2 - z1)
+		return d <= thickness*math.Sqrt(1+slope*slope)
+	})
+}
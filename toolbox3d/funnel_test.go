@@ -0,0 +1,101 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestFunnelSolid(t *testing.T) {
+	f := &Funnel{
+		TopRadius:     5,
+		SpoutRadius:   1,
+		ConeHeight:    4,
+		SpoutLength:   3,
+		WallThickness: 0.2,
+	}
+	solid := f.Solid()
+	for _, z := range []float64{0.5, 2.9, 4, 6.9} {
+		if solid.Contains(model3d.Z(z)) {
+			t.Errorf("funnel should be open at z=%f", z)
+		}
+	}
+	if !solid.Contains(model3d.XYZ(1.1, 0, 1)) || solid.Contains(model3d.XYZ(1.3, 0, 1)) {
+		t.Error("unexpected spout wall")
+	}
+	// The cone is at 45 degrees, so the horizontal
+	// thickness should be scaled by sqrt(2).
+	if !solid.Contains(model3d.XYZ(3+0.2*math.Sqrt2-0.01, 0, 5)) ||
+		solid.Contains(model3d.XYZ(3+0.2*math.Sqrt2+0.01, 0, 5)) {
+		t.Error("unexpected cone wall")
+	}
+}
+
+func TestHopperSolid(t *testing.T) {
+	h := &Hopper{
+		TopWidth:        8,
+		TopDepth:        4,
+		TopCornerRadius: 0.5,
+		SpoutRadius:     1,
+		Height:          5,
+		SpoutLength:     1,
+		WallThickness:   0.2,
+	}
+	solid := h.Solid()
+	if !solid.Contains(model3d.XYZ(1.1, 0, 0.5)) || solid.Contains(model3d.XYZ(0.9, 0, 0.5)) {
+		t.Error("unexpected spout")
+	}
+	top := h.SpoutLength + h.Height - 1e-3
+	if solid.Contains(model3d.XYZ(3.8, 1.8, top)) || !solid.Contains(model3d.XYZ(4.05, 0, top)) ||
+		!solid.Contains(model3d.XYZ(0, 2.05, top)) {
+		t.Error("unexpected top opening")
+	}
+
+	mesh := model3d.MarchingCubesSearch(solid, 0.1, 8)
+	if mesh.NeedsRepair() {
+		t.Error("mesh needs repair")
+	}
+}
+
+func TestAdapterSolid(t *testing.T) {
+	a := &Adapter{
+		Bottom: AdapterEnd{
+			Kind:       FemaleThreadEnd,
+			Radius:     10,
+			Length:     10,
+			GrooveSize: 1,
+		},
+		Top: AdapterEnd{
+			Kind:     HoseBarbEnd,
+			Radius:   4,
+			Length:   12,
+			NumBarbs: 3,
+			BarbSize: 0.8,
+		},
+		TransitionLength: 8,
+		WallThickness:    2,
+	}
+	solid := a.Solid()
+	if max := solid.Max(); math.Abs(max.Z-a.Height()) > 1e-2 {
+		t.Errorf("unexpected height: %f", max.Z)
+	}
+	for _, z := range []float64{-1e-3, 5, 14, 25, 30} {
+		if solid.Contains(model3d.Z(z)) {
+			t.Errorf("adapter should be open at z=%f", z)
+		}
+	}
+	if !solid.Contains(model3d.XYZ(11.5, 0, 5)) || solid.Contains(model3d.XYZ(12.5, 0, 5)) {
+		t.Error("unexpected female wall")
+	}
+	// Barbs should widen towards the transition.
+	tip := a.Height()
+	if !solid.Contains(model3d.XYZ(4.7, 0, tip-3.9)) || solid.Contains(model3d.XYZ(4.7, 0, tip-0.1)) {
+		t.Error("unexpected barb")
+	}
+
+	mesh := model3d.MarchingCubesSearch(solid, 0.4, 8)
+	if mesh.NeedsRepair() {
+		t.Error("mesh needs repair")
+	}
+}
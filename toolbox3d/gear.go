@@ -146,6 +146,31 @@ func (i *involuteGearProfile) PitchRadius() float64 {
 	return i.pitchRadius
 }
 
+func (i *involuteGearProfile) BaseRadius() float64 {
+	return i.baseRadius
+}
+
+func (i *involuteGearProfile) RootRadius() float64 {
+	return i.rootRadius
+}
+
+func (i *involuteGearProfile) OuterRadius() float64 {
+	return i.outerRadius
+}
+
+func (i *involuteGearProfile) NumTeeth() int {
+	return int(math.Round(math.Pi * 2 / i.toothTheta))
+}
+
+func (i *involuteGearProfile) ToothThickness(r float64) float64 {
+	if r < i.baseRadius {
+		r = i.baseRadius
+	}
+	tForR := math.Sqrt(math.Pow(r/i.baseRadius, 2) - 1)
+	x, y := involuteCoords(tForR)
+	return r * (i.reflectTheta - 2*math.Atan2(y, x))
+}
+
 func (i *involuteGearProfile) Min() model2d.Coord {
 	return model2d.Coord{X: -i.outerRadius, Y: -i.outerRadius}
 }
@@ -0,0 +1,138 @@
+package toolbox3d
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// An InvoluteProfile is a GearProfile with the dimensions
+// of an involute gear, such as the profiles returned by
+// InvoluteGearProfile.
+type InvoluteProfile interface {
+	GearProfile
+
+	BaseRadius() float64
+	RootRadius() float64
+	OuterRadius() float64
+	NumTeeth() int
+
+	// ToothThickness gets the arc length of a tooth at the
+	// given radius.
+	ToothThickness(r float64) float64
+}
+
+// A GearMeshAnalysis describes how a pair of involute
+// gears mesh at a given center distance.
+type GearMeshAnalysis struct {
+	// PressureAngle is the operating pressure angle, which
+	// depends on the center distance.
+	PressureAngle float64
+
+	// Backlash is the circular backlash (the gap between
+	// the teeth) along the operating pitch circles.
+	Backlash float64
+
+	// ContactRatio is the average number of tooth pairs in
+	// contact at once.
+	ContactRatio float64
+
+	// TipClearance1 is the radial distance between the tips
+	// of the first gear and the roots of the second gear,
+	// and TipClearance2 is the opposite.
+	TipClearance1 float64
+	TipClearance2 float64
+
+	// Undercut is true if the tip of either gear reaches
+	// below the base circle of the other gear, where the
+	// involute profile does not exist.
+	Undercut bool
+
+	// Warnings describes potential problems with the gear
+	// pair, such as binding or skipping.
+	Warnings []string
+}
+
+// AnalyzeGearMesh analyzes a pair of involute gears with a
+// given distance between their centers.
+//
+// The minBacklash argument is the smallest gap that should
+// be left between teeth, e.g. to account for printing
+// tolerances.
+// If the backlash is smaller than this, a warning is
+// produced indicating that the gears may bind.
+//
+// An error is returned if the profiles are not involute,
+// or if the gears are too far apart to mesh at all.
+func AnalyzeGearMesh(g1, g2 GearProfile, centerDistance,
+	minBacklash float64) (*GearMeshAnalysis, error) {
+	p1, ok1 := g1.(InvoluteProfile)
+	p2, ok2 := g2.(InvoluteProfile)
+	if !ok1 || !ok2 {
+		return nil, errors.New("analyze gear mesh: profiles must be involute")
+	}
+
+	rb1, rb2 := p1.BaseRadius(), p2.BaseRadius()
+	if rb1+rb2 >= centerDistance {
+		return nil, errors.New("analyze gear mesh: center distance is too small")
+	}
+	ra1, ra2 := p1.OuterRadius(), p2.OuterRadius()
+	if ra1+ra2 <= centerDistance {
+		return nil, errors.New("analyze gear mesh: gears do not reach each other")
+	}
+
+	res := &GearMeshAnalysis{}
+	res.PressureAngle = math.Acos((rb1 + rb2) / centerDistance)
+
+	// Teeth must have the same spacing along the line of
+	// action in order to mesh smoothly.
+	basePitch1 := 2 * math.Pi * rb1 / float64(p1.NumTeeth())
+	basePitch2 := 2 * math.Pi * rb2 / float64(p2.NumTeeth())
+	if math.Abs(basePitch1-basePitch2) > 1e-3*basePitch1 {
+		res.Warnings = append(res.Warnings, fmt.Sprintf(
+			"base pitches differ (%f vs %f), so the gears will not mesh smoothly",
+			basePitch1, basePitch2,
+		))
+	}
+
+	// Operating pitch circles are where the gears roll
+	// against each other without slipping.
+	r1 := centerDistance * rb1 / (rb1 + rb2)
+	r2 := centerDistance * rb2 / (rb1 + rb2)
+	circularPitch := 2 * math.Pi * r1 / float64(p1.NumTeeth())
+	res.Backlash = circularPitch - p1.ToothThickness(r1) - p2.ToothThickness(r2)
+
+	lineOfAction := centerDistance * math.Sin(res.PressureAngle)
+	approach1 := math.Sqrt(ra1*ra1 - rb1*rb1)
+	approach2 := math.Sqrt(ra2*ra2 - rb2*rb2)
+	res.ContactRatio = (approach1 + approach2 - lineOfAction) / basePitch1
+	res.Undercut = approach1 > lineOfAction || approach2 > lineOfAction
+
+	res.TipClearance1 = centerDistance - ra1 - p2.RootRadius()
+	res.TipClearance2 = centerDistance - ra2 - p1.RootRadius()
+
+	if res.Backlash < minBacklash {
+		res.Warnings = append(res.Warnings, fmt.Sprintf(
+			"backlash %f is less than %f, so the gears may bind",
+			res.Backlash, minBacklash,
+		))
+	}
+	if res.ContactRatio < 1 {
+		res.Warnings = append(res.Warnings, fmt.Sprintf(
+			"contact ratio %f is less than 1, so the gears may skip",
+			res.ContactRatio,
+		))
+	}
+	if res.TipClearance1 < minBacklash || res.TipClearance2 < minBacklash {
+		res.Warnings = append(res.Warnings, fmt.Sprintf(
+			"tip clearances (%f, %f) are too small, so tips may hit roots",
+			res.TipClearance1, res.TipClearance2,
+		))
+	}
+	if res.Undercut {
+		res.Warnings = append(res.Warnings,
+			"tips reach below the other gear's base circle, causing interference")
+	}
+	return res, nil
+}
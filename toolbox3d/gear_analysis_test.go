@@ -0,0 +1,68 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzeGearMesh(t *testing.T) {
+	pressure := 20 * math.Pi / 180
+	g1 := InvoluteGearProfileSizes(pressure, 1, 1, 1.25, 20)
+	g2 := InvoluteGearProfileSizes(pressure, 1, 1, 1.25, 30)
+
+	res, err := AnalyzeGearMesh(g1, g2, 25, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.Backlash) > 1e-8 {
+		t.Errorf("expected no backlash at standard distance, got %f", res.Backlash)
+	}
+	if math.Abs(res.PressureAngle-pressure) > 1e-8 {
+		t.Errorf("unexpected pressure angle: %f", res.PressureAngle)
+	}
+	if res.ContactRatio < 1.55 || res.ContactRatio > 1.65 {
+		t.Errorf("unexpected contact ratio: %f", res.ContactRatio)
+	}
+	if len(res.Warnings) != 1 {
+		t.Errorf("expected a single binding warning, got %v", res.Warnings)
+	}
+
+	// Spreading the gears apart should add backlash.
+	res, err = AnalyzeGearMesh(g1, g2, 25.3, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := 2 * 0.3 * math.Tan(pressure)
+	if math.Abs(res.Backlash-expected) > 0.02 {
+		t.Errorf("expected backlash near %f but got %f", expected, res.Backlash)
+	}
+	if len(res.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", res.Warnings)
+	}
+
+	if _, err := AnalyzeGearMesh(g1, g2, 30, 0.1); err == nil {
+		t.Error("expected error for distant gears")
+	}
+	if _, err := AnalyzeGearMesh(&involuteWrapper{g1}, g2, 25, 0.1); err == nil {
+		t.Error("expected error for non-involute profile")
+	}
+}
+
+type involuteWrapper struct {
+	GearProfile
+}
+
+func TestAnalyzeGearMeshTipInterference(t *testing.T) {
+	// With a small dedendum, the tips of each gear will
+	// hit the roots of the other gear.
+	pressure := 20 * math.Pi / 180
+	g1 := InvoluteGearProfileSizes(pressure, 1, 1, 0.5, 20)
+	g2 := InvoluteGearProfileSizes(pressure, 1, 1, 0.5, 30)
+	res, err := AnalyzeGearMesh(g1, g2, 25.1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TipClearance1 > 0 || res.TipClearance2 > 0 || len(res.Warnings) != 1 {
+		t.Errorf("unexpected analysis: %+v", res)
+	}
+}
@@ -0,0 +1,260 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A GrillePattern determines the shape and arrangement of
+// the holes in a Grille.
+type GrillePattern int
+
+const (
+	// HexGrille is a honeycomb of hexagonal holes.
+	HexGrille GrillePattern = iota
+
+	// SlotGrille is staggered rows of rounded slots.
+	SlotGrille
+
+	// CircularGrille is round holes arranged in concentric
+	// rings, as in a speaker grille.
+	CircularGrille
+)
+
+// grilleBoundarySamples is the number of points checked
+// along the outline of each hole to make sure it fits in
+// the grille's boundary.
+const grilleBoundarySamples = 32
+
+// A Grille generates ventilation or speaker-grille holes
+// within a 2D region, for cutting out of an enclosure wall.
+type Grille struct {
+	Pattern GrillePattern
+
+	// Boundary is the region where holes may be placed.
+	Boundary model2d.Solid
+
+	// HoleSize is the distance across the flats of hexagonal
+	// holes, the diameter of round holes, or the width of
+	// slots.
+	HoleSize float64
+
+	// SlotLength is the total length of each slot, which
+	// must be at least HoleSize.
+	SlotLength float64
+
+	// RibWidth is the minimum width of the material between
+	// holes, and between the holes and the edge of the
+	// boundary.
+	RibWidth float64
+}
+
+// Holes computes the holes of the grille.
+//
+// Only holes which fit entirely within the boundary, with
+// a margin of RibWidth, are included.
+func (g *Grille) Holes() []model2d.Solid {
+	var res []model2d.Solid
+	for _, hole := range g.candidates() {
+		if g.fits(hole) {
+			res = append(res, hole.Solid())
+		}
+	}
+	return res
+}
+
+// Solid creates a 2D solid containing all of the holes.
+func (g *Grille) Solid() model2d.Solid {
+	holes := g.Holes()
+	if len(holes) == 0 {
+		return model2d.CheckedFuncSolid(model2d.Coord{}, model2d.Coord{}, func(model2d.Coord) bool {
+			return false
+		})
+	}
+	return model2d.JoinedSolid(holes).Optimize()
+}
+
+// Cutout creates a 3D solid containing all of the holes,
+// extruded along the z-axis between minZ and maxZ.
+//
+// The result can be subtracted from a wall in the XY
+// plane, or transformed to fit other walls.
+func (g *Grille) Cutout(minZ, maxZ float64) model3d.Solid {
+	return model3d.ProfileSolid(g.Solid(), minZ, maxZ)
+}
+
+func (g *Grille) candidates() []grilleHole {
+	min, max := g.Boundary.Min(), g.Boundary.Max()
+	center := min.Mid(max)
+	pitch := g.HoleSize + g.RibWidth
+
+	var res []grilleHole
+	switch g.Pattern {
+	case HexGrille:
+		// Neighboring hexagons share parallel flats, so the
+		// rows are offset by half a pitch.
+		rowSpacing := pitch * math.Sqrt(3) / 2
+		res = grilleStaggeredGrid(min, max, center, pitch, rowSpacing, func(c model2d.Coord) grilleHole {
+			return &grilleHexagon{Center: c, Apothem: g.HoleSize / 2}
+		})
+	case SlotGrille:
+		if g.SlotLength < g.HoleSize {
+			panic("slot length must be at least the hole size")
+		}
+		res = grilleStaggeredGrid(min, max, center, g.SlotLength+g.RibWidth, pitch,
+			func(c model2d.Coord) grilleHole {
+				offset := model2d.X((g.SlotLength - g.HoleSize) / 2)
+				return &grilleSlot{P1: c.Sub(offset), P2: c.Add(offset), Radius: g.HoleSize / 2}
+			})
+	case CircularGrille:
+		radius := g.HoleSize / 2
+		res = append(res, &grilleCircle{Center: center, Radius: radius})
+		maxRadius := max.Sub(center).Norm()
+		for ring := 1; float64(ring)*pitch <= maxRadius; ring++ {
+			r := float64(ring) * pitch
+			// Adjacent holes are separated by at least a pitch
+			// in a straight line.
+			count := int(math.Floor(math.Pi / math.Asin(math.Min(1, pitch/(2*r)))))
+			for i := 0; i < count; i++ {
+				theta := 2 * math.Pi * float64(i) / float64(count)
+				res = append(res, &grilleCircle{
+					Center: center.Add(model2d.XY(math.Cos(theta), math.Sin(theta)).Scale(r)),
+					Radius: radius,
+				})
+			}
+		}
+	default:
+		panic("unknown grille pattern")
+	}
+	return res
+}
+
+func (g *Grille) fits(hole grilleHole) bool {
+	for _, c := range hole.Outline(g.RibWidth) {
+		if !g.Boundary.Contains(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// grilleStaggeredGrid creates holes on a grid of rows
+// centered at center, where every other row is offset by
+// half of the spacing along the row.
+func grilleStaggeredGrid(min, max, center model2d.Coord, spacing, rowSpacing float64,
+	f func(c model2d.Coord) grilleHole) []grilleHole {
+	var res []grilleHole
+	numRows := int(math.Ceil(math.Max(center.Y-min.Y, max.Y-center.Y)/rowSpacing)) + 1
+	numCols := int(math.Ceil(math.Max(center.X-min.X, max.X-center.X)/spacing)) + 1
+	for row := -numRows; row <= numRows; row++ {
+		offset := 0.0
+		if row%2 != 0 {
+			offset = spacing / 2
+		}
+		for col := -numCols; col <= numCols; col++ {
+			res = append(res, f(model2d.XY(
+				center.X+float64(col)*spacing+offset,
+				center.Y+float64(row)*rowSpacing,
+			)))
+		}
+	}
+	return res
+}
+
+type grilleHole interface {
+	Solid() model2d.Solid
+
+	// Outline samples points on the outline of the hole,
+	// expanded outward by a margin.
+	Outline(margin float64) []model2d.Coord
+}
+
+type grilleCircle struct {
+	Center model2d.Coord
+	Radius float64
+}
+
+func (g *grilleCircle) Solid() model2d.Solid {
+	return &model2d.Circle{Center: g.Center, Radius: g.Radius}
+}
+
+func (g *grilleCircle) Outline(margin float64) []model2d.Coord {
+	res := make([]model2d.Coord, grilleBoundarySamples)
+	for i := range res {
+		theta := 2 * math.Pi * float64(i) / grilleBoundarySamples
+		res[i] = g.Center.Add(model2d.XY(math.Cos(theta), math.Sin(theta)).Scale(g.Radius + margin))
+	}
+	return res
+}
+
+// grilleHexagon is a hexagon with flat sides on the left
+// and right, so that neighbors in a row share flat sides.
+type grilleHexagon struct {
+	Center  model2d.Coord
+	Apothem float64
+}
+
+func (g *grilleHexagon) Solid() model2d.Solid {
+	circumradius := g.Apothem * 2 / math.Sqrt(3)
+	return model2d.CheckedFuncSolid(
+		g.Center.Sub(model2d.XY(g.Apothem, circumradius)),
+		g.Center.Add(model2d.XY(g.Apothem, circumradius)),
+		func(c model2d.Coord) bool {
+			offset := c.Sub(g.Center)
+			for i := 0; i < 3; i++ {
+				theta := float64(i) * math.Pi / 3
+				normal := model2d.XY(math.Cos(theta), math.Sin(theta))
+				if math.Abs(offset.Dot(normal)) > g.Apothem {
+					return false
+				}
+			}
+			return true
+		},
+	)
+}
+
+func (g *grilleHexagon) Outline(margin float64) []model2d.Coord {
+	// Conservatively sample a circle around the hexagon,
+	// since the expanded hexagon has rounded corners.
+	circle := &grilleCircle{Center: g.Center, Radius: g.Apothem * 2 / math.Sqrt(3)}
+	return circle.Outline(margin)
+}
+
+type grilleSlot struct {
+	P1     model2d.Coord
+	P2     model2d.Coord
+	Radius float64
+}
+
+func (g *grilleSlot) Solid() model2d.Solid {
+	r := model2d.XY(g.Radius, g.Radius)
+	seg := model2d.Segment{g.P1, g.P2}
+	return model2d.CheckedFuncSolid(
+		g.P1.Min(g.P2).Sub(r),
+		g.P1.Max(g.P2).Add(r),
+		func(c model2d.Coord) bool {
+			return seg.Dist(c) <= g.Radius
+		},
+	)
+}
+
+func (g *grilleSlot) Outline(margin float64) []model2d.Coord {
+	var res []model2d.Coord
+	for _, end := range []model2d.Coord{g.P1, g.P2} {
+		circle := &grilleCircle{Center: end, Radius: g.Radius}
+		res = append(res, circle.Outline(margin)...)
+	}
+	length := g.P1.Dist(g.P2)
+	if length == 0 {
+		return res
+	}
+	side := g.P2.Sub(g.P1).Normalize()
+	normal := model2d.XY(-side.Y, side.X).Scale(g.Radius + margin)
+	for i := 0; i <= grilleBoundarySamples; i++ {
+		c := g.P1.Add(side.Scale(length * float64(i) / grilleBoundarySamples))
+		res = append(res, c.Add(normal), c.Sub(normal))
+	}
+	return res
+}
@@ -0,0 +1,89 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestGrilleRibWidth(t *testing.T) {
+	boundary := &model2d.Circle{Radius: 10}
+	for _, pattern := range []GrillePattern{HexGrille, SlotGrille, CircularGrille} {
+		g := &Grille{
+			Pattern:    pattern,
+			Boundary:   boundary,
+			HoleSize:   1,
+			SlotLength: 3,
+			RibWidth:   0.4,
+		}
+		holes := g.Holes()
+		if len(holes) < 10 {
+			t.Fatalf("pattern %d: expected many holes but got %d", pattern, len(holes))
+		}
+
+		// Sample the grille and make sure that every point
+		// inside a hole is far from every other hole and from
+		// the edge of the boundary.
+		meshes := make([]*model2d.Mesh, len(holes))
+		sdfs := make([]model2d.SDF, len(holes))
+		for i, h := range holes {
+			meshes[i] = model2d.MarchingSquaresSearch(h, 0.05, 8)
+			sdfs[i] = model2d.MeshToSDF(meshes[i])
+		}
+		for i, mesh := range meshes {
+			for _, v := range mesh.VertexSlice() {
+				if v.Norm() > 10-g.RibWidth+0.02 {
+					t.Fatalf("pattern %d: hole %d too close to boundary", pattern, i)
+				}
+				for j, sdf := range sdfs {
+					if j == i || !model2d.InBounds(grilleExpandedBounds(sdf, 1), v) {
+						continue
+					}
+					if d := -sdf.SDF(v); d < g.RibWidth-0.03 {
+						t.Fatalf("pattern %d: holes %d and %d are too close (%f)", pattern, i, j, d)
+					}
+				}
+			}
+		}
+	}
+}
+
+func grilleExpandedBounds(b model2d.Bounder, margin float64) model2d.Bounder {
+	return &model2d.Rect{
+		MinVal: b.Min().Sub(model2d.XY(margin, margin)),
+		MaxVal: b.Max().Add(model2d.XY(margin, margin)),
+	}
+}
+
+func TestGrilleHexagon(t *testing.T) {
+	hex := (&grilleHexagon{Apothem: 1}).Solid()
+	mesh := model2d.MarchingSquaresSearch(hex, 0.01, 8)
+	expected := 2 * math.Sqrt(3)
+	if area := mesh.Area(); math.Abs(area-expected) > 0.01 {
+		t.Errorf("expected area %f but got %f", expected, area)
+	}
+	if !hex.Contains(model2d.XY(0, 1.1)) || hex.Contains(model2d.XY(1.1, 0)) {
+		t.Error("unexpected orientation")
+	}
+}
+
+func TestGrilleCutout(t *testing.T) {
+	g := &Grille{
+		Pattern:  CircularGrille,
+		Boundary: &model2d.Rect{MaxVal: model2d.XY(10, 10)},
+		HoleSize: 1,
+		RibWidth: 0.5,
+	}
+	cutout := g.Cutout(-1, 1)
+	if !cutout.Contains(model3d.XYZ(5, 5, 0)) {
+		t.Error("missing center hole")
+	}
+	if cutout.Contains(model3d.XYZ(5.75, 5, 0)) {
+		t.Error("unexpected hole in rib")
+	}
+	if !cutout.Contains(model3d.XYZ(6.5, 5, 0)) {
+		t.Error("missing first ring")
+	}
+}
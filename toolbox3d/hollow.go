@@ -0,0 +1,88 @@
+package toolbox3d
+
+import (
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A DrainHole is a cylindrical hole which connects the
+// cavity of a hollowed solid to the outside, allowing
+// uncured resin or unfused powder to escape.
+type DrainHole struct {
+	// Origin is the start of the hole, which should be
+	// inside the cavity.
+	Origin model3d.Coord3D
+
+	// Direction is the direction in which the hole extends
+	// from the origin, pointing towards the outside.
+	Direction model3d.Coord3D
+
+	// Radius is the radius of the hole.
+	Radius float64
+}
+
+// HollowSolid removes the inside of a solid, leaving walls
+// of a given thickness, and then cuts drain holes through
+// the walls.
+//
+// If the solid implements model3d.SDF, the SDF is used to
+// measure the thickness of the walls.
+// Otherwise, the solid's surface is approximated with a
+// mesh using a grid spacing of delta.
+//
+// Each drain hole extends from its origin past the
+// bounds of the solid, so it will cut through any parts of
+// the solid beyond the cavity along its direction.
+// FindTrappedVoids can be used to check that the cavity
+// has been drained.
+func HollowSolid(solid model3d.Solid, thickness, delta float64,
+	holes []*DrainHole) model3d.Solid {
+	sdf, ok := solid.(model3d.SDF)
+	if !ok {
+		sdf = model3d.SolidToSDF(solid, delta)
+	}
+	negative := model3d.JoinedSolid{
+		&hollowCavity{
+			sdf:       sdf,
+			min:       solid.Min().Add(model3d.Ones(thickness)),
+			max:       solid.Max().Sub(model3d.Ones(thickness)),
+			thickness: thickness,
+		},
+	}
+
+	diameter := solid.Max().Dist(solid.Min())
+	center := solid.Min().Mid(solid.Max())
+	for _, h := range holes {
+		length := diameter + h.Origin.Dist(center) + h.Radius
+		negative = append(negative, &model3d.Cylinder{
+			P1:     h.Origin,
+			P2:     h.Origin.Add(h.Direction.Normalize().Scale(length)),
+			Radius: h.Radius,
+		})
+	}
+
+	return &model3d.SubtractedSolid{
+		Positive: solid,
+		Negative: negative.Optimize(),
+	}
+}
+
+// hollowCavity contains the points of a solid which are
+// further than thickness from its surface.
+type hollowCavity struct {
+	sdf       model3d.SDF
+	min       model3d.Coord3D
+	max       model3d.Coord3D
+	thickness float64
+}
+
+func (h *hollowCavity) Min() model3d.Coord3D {
+	return h.min
+}
+
+func (h *hollowCavity) Max() model3d.Coord3D {
+	return h.max.Max(h.min)
+}
+
+func (h *hollowCavity) Contains(c model3d.Coord3D) bool {
+	return model3d.InBounds(h, c) && h.sdf.SDF(c) > h.thickness
+}
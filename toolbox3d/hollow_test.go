@@ -0,0 +1,59 @@
+package toolbox3d
+
+import (
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestHollowSolid(t *testing.T) {
+	sphere := &model3d.Sphere{Center: model3d.XYZ(1, 2, 3), Radius: 2}
+	hollow := HollowSolid(sphere, 0.4, 0.05, nil)
+	for _, r := range []float64{0, 0.5, 1.5, 1.55} {
+		if hollow.Contains(sphere.Center.Add(model3d.X(r))) {
+			t.Errorf("unexpected point in cavity at radius %f", r)
+		}
+	}
+	for _, r := range []float64{1.65, 1.9} {
+		if !hollow.Contains(sphere.Center.Add(model3d.Y(-r))) {
+			t.Errorf("expected point in wall at radius %f", r)
+		}
+	}
+	if voids := FindTrappedVoids(hollow, 0.1); len(voids) != 1 {
+		t.Fatalf("expected one void but got %d", len(voids))
+	}
+
+	drained := HollowSolid(sphere, 0.4, 0.05, []*DrainHole{
+		{Origin: sphere.Center, Direction: model3d.Z(-1), Radius: 0.3},
+	})
+	if voids := FindTrappedVoids(drained, 0.1); len(voids) != 0 {
+		t.Errorf("expected no voids but got %d", len(voids))
+	}
+	if drained.Contains(sphere.Center.Sub(model3d.Z(1.8))) {
+		t.Error("expected drain hole through the bottom wall")
+	}
+	if !drained.Contains(sphere.Center.Add(model3d.Z(1.8))) {
+		t.Error("expected top wall to be intact")
+	}
+}
+
+func TestHollowSolidMesh(t *testing.T) {
+	// Solids without an SDF are hollowed using a mesh.
+	box := model3d.NewMeshRect(model3d.Coord3D{}, model3d.XYZ(3, 2, 2))
+	solid := model3d.NewColliderSolid(model3d.MeshToCollider(box))
+	hollow := HollowSolid(solid, 0.25, 0.05, []*DrainHole{
+		{Origin: model3d.XYZ(1.5, 1, 1), Direction: model3d.X(1), Radius: 0.2},
+	})
+	if hollow.Contains(model3d.XYZ(1.5, 1, 1)) || hollow.Contains(model3d.XYZ(0.3, 0.3, 0.3)) {
+		t.Error("unexpected point in cavity")
+	}
+	if !hollow.Contains(model3d.XYZ(0.2, 1, 1)) || !hollow.Contains(model3d.XYZ(1.5, 1, 0.1)) {
+		t.Error("expected point in wall")
+	}
+	if hollow.Contains(model3d.XYZ(2.9, 1, 1)) {
+		t.Error("expected drain hole through the wall")
+	}
+	if voids := FindTrappedVoids(hollow, 0.1); len(voids) != 0 {
+		t.Errorf("expected no voids but got %d", len(voids))
+	}
+}
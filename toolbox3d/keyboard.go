@@ -0,0 +1,345 @@
+package toolbox3d
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A KeySwitch is a type of mechanical keyboard switch.
+type KeySwitch int
+
+const (
+	// CherryMX switches have a cross-shaped stem, are
+	// spaced 19.05 mm apart, and clip into 1.5 mm plates.
+	CherryMX KeySwitch = iota
+
+	// KailhChoc switches are low-profile switches with two
+	// stem slots, and are spaced 18x17 mm apart.
+	KailhChoc
+)
+
+// Pitch gets the standard spacing between the centers of
+// adjacent 1u keys, along the x and y axes.
+func (k KeySwitch) Pitch() model2d.Coord {
+	if k == KailhChoc {
+		return model2d.XY(18, 17)
+	}
+	return model2d.XY(19.05, 19.05)
+}
+
+// PlateHoleSize gets the side length of the square hole in
+// a switch plate.
+func (k KeySwitch) PlateHoleSize() float64 {
+	if k == KailhChoc {
+		return 13.8
+	}
+	return 14
+}
+
+// A KeyboardKey is a key in a keyboard layout.
+type KeyboardKey struct {
+	Label string
+
+	// Center is the center of the key in millimeters.
+	Center model2d.Coord
+
+	// Width and Height are the size of the key in units,
+	// where a standard key is 1x1.
+	Width  float64
+	Height float64
+
+	// Rotation is the counter-clockwise rotation of the key
+	// in radians.
+	Rotation float64
+}
+
+// ReadKLELayout reads a keyboard layout in the JSON format
+// downloaded from keyboard-layout-editor.com.
+//
+// Keys are positioned using the pitch of the switch type,
+// with the y-axis flipped so that the first row has the
+// largest y coordinate.
+func ReadKLELayout(r io.Reader, switchType KeySwitch) ([]*KeyboardKey, error) {
+	var rows []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, errors.Wrap(err, "read KLE layout")
+	}
+	pitch := switchType.Pitch()
+
+	var res []*KeyboardKey
+	var x, y, w, h, rotation, rx, ry float64
+	w, h = 1, 1
+	for _, rawRow := range rows {
+		var row []interface{}
+		if err := json.Unmarshal(rawRow, &row); err != nil {
+			// The first element may be an object containing
+			// keyboard metadata.
+			continue
+		}
+		for _, item := range row {
+			switch item := item.(type) {
+			case string:
+				center := model2d.XY(x+w/2, y+h/2)
+				if rotation != 0 {
+					center = model2d.Rotation(rotation * math.Pi / 180).Apply(
+						center.Sub(model2d.XY(rx, ry)),
+					).Add(model2d.XY(rx, ry))
+				}
+				res = append(res, &KeyboardKey{
+					Label:    item,
+					Center:   model2d.XY(center.X*pitch.X, -center.Y*pitch.Y),
+					Width:    w,
+					Height:   h,
+					Rotation: -rotation * math.Pi / 180,
+				})
+				x += w
+				w, h = 1, 1
+			case map[string]interface{}:
+				num := func(name string) (float64, bool) {
+					v, ok := item[name].(float64)
+					return v, ok
+				}
+				if v, ok := num("r"); ok {
+					rotation = v
+				}
+				if v, ok := num("rx"); ok {
+					rx = v
+					x, y = rx, ry
+				}
+				if v, ok := num("ry"); ok {
+					ry = v
+					x, y = rx, ry
+				}
+				if v, ok := num("x"); ok {
+					x += v
+				}
+				if v, ok := num("y"); ok {
+					y += v
+				}
+				if v, ok := num("w"); ok {
+					w = v
+				}
+				if v, ok := num("h"); ok {
+					h = v
+				}
+			default:
+				return nil, errors.New("read KLE layout: unexpected item in row")
+			}
+		}
+		y++
+		x = rx
+	}
+	return res, nil
+}
+
+// A Keycap is a parametric keycap with a dished top.
+//
+// The keycap is centered at the origin in the XY plane,
+// with its bottom at z=0.
+// The legs of Kailh Choc keycaps extend below z=0.
+type Keycap struct {
+	Switch KeySwitch
+
+	// Width and Height are the size of the key in units.
+	Width  float64
+	Height float64
+
+	// Gap is the space between adjacent keycaps.
+	Gap float64
+
+	// TopInset is how much narrower the top of the keycap
+	// is than the bottom on each side.
+	TopInset float64
+
+	// Thickness is the total height of the keycap.
+	Thickness float64
+
+	// DishDepth is the depth of the cylindrical dish on top.
+	DishDepth float64
+
+	// WallThickness is the thickness of the walls and the
+	// top of the keycap.
+	WallThickness float64
+
+	// CornerRadius is the radius of the keycap's corners.
+	CornerRadius float64
+
+	// StemClearance is added to the stem's cavity (or
+	// subtracted from its legs) to account for printing
+	// tolerances.
+	StemClearance float64
+}
+
+// NewKeycap creates a keycap with default dimensions for a
+// switch type and key size (in units).
+func NewKeycap(switchType KeySwitch, width, height float64) *Keycap {
+	k := &Keycap{
+		Switch:        switchType,
+		Width:         width,
+		Height:        height,
+		Gap:           1,
+		TopInset:      2.5,
+		Thickness:     8,
+		DishDepth:     0.8,
+		WallThickness: 1.2,
+		CornerRadius:  1,
+		StemClearance: 0.05,
+	}
+	if switchType == KailhChoc {
+		k.Gap = 0.5
+		k.TopInset = 1
+		k.Thickness = 3.5
+		k.DishDepth = 0.4
+		k.WallThickness = 1
+	}
+	return k
+}
+
+// Solid creates the keycap.
+func (k *Keycap) Solid() model3d.Solid {
+	pitch := k.Switch.Pitch()
+	halfSize := model2d.XY(k.Width*pitch.X-k.Gap, k.Height*pitch.Y-k.Gap).Scale(0.5)
+	topHalfWidth := halfSize.X - k.TopInset
+
+	// The dish is a cylinder along the y-axis which meets
+	// the top corners of the keycap.
+	dishRadius := (topHalfWidth*topHalfWidth + k.DishDepth*k.DishDepth) / (2 * k.DishDepth)
+	dishCenter := k.Thickness - k.DishDepth + dishRadius
+	ceiling := k.Thickness - k.DishDepth - k.WallThickness
+
+	shell := model3d.CheckedFuncSolid(
+		model3d.XYZ(-halfSize.X, -halfSize.Y, 0),
+		model3d.XYZ(halfSize.X, halfSize.Y, k.Thickness),
+		func(c model3d.Coord3D) bool {
+			if k.DishDepth > 0 && math.Hypot(c.X, c.Z-dishCenter) < dishRadius {
+				return false
+			}
+			inset := k.TopInset * c.Z / k.Thickness
+			if !keycapRoundedRect(c.XY(), halfSize, inset, k.CornerRadius) {
+				return false
+			}
+			if c.Z > ceiling {
+				return true
+			}
+			return !keycapRoundedRect(c.XY(), halfSize, inset+k.WallThickness, k.CornerRadius)
+		},
+	)
+	return model3d.JoinedSolid{shell, k.stem(ceiling)}
+}
+
+func (k *Keycap) stem(ceiling float64) model3d.Solid {
+	if k.Switch == KailhChoc {
+		// Two legs fit into the slots of the switch's stem.
+		const legSpacing = 5.7
+		const legDepth = 3
+		legSize := model3d.XYZ(1.2-k.StemClearance, 3-k.StemClearance, 0).Scale(0.5)
+		var legs model3d.JoinedSolid
+		for _, x := range []float64{-legSpacing / 2, legSpacing / 2} {
+			center := model3d.XYZ(x, 0, ceiling)
+			legs = append(legs, &model3d.Rect{
+				MinVal: center.Sub(legSize).Sub(model3d.Z(legDepth)),
+				MaxVal: center.Add(legSize).Add(model3d.Z(k.WallThickness / 2)),
+			})
+		}
+		return legs
+	}
+
+	const stemRadius = 2.75
+	const crossLength = 4.1
+	const crossWidth = 1.3
+	const crossDepth = 4
+	halfLength := crossLength/2 + k.StemClearance
+	halfWidth := crossWidth/2 + k.StemClearance
+	return &model3d.SubtractedSolid{
+		Positive: &model3d.Cylinder{
+			P1:     model3d.Z(0.5),
+			P2:     model3d.Z(ceiling + k.WallThickness/2),
+			Radius: stemRadius,
+		},
+		Negative: model3d.CheckedFuncSolid(
+			model3d.XYZ(-halfLength, -halfLength, 0),
+			model3d.XYZ(halfLength, halfLength, 0.5+crossDepth),
+			func(c model3d.Coord3D) bool {
+				x, y := math.Abs(c.X), math.Abs(c.Y)
+				return (x <= halfLength && y <= halfWidth) || (x <= halfWidth && y <= halfLength)
+			},
+		),
+	}
+}
+
+func keycapRoundedRect(c, halfSize model2d.Coord, inset, radius float64) bool {
+	w, h := halfSize.X-inset, halfSize.Y-inset
+	dx := math.Max(0, math.Abs(c.X)-(w-radius))
+	dy := math.Max(0, math.Abs(c.Y)-(h-radius))
+	return math.Abs(c.X) <= w && math.Abs(c.Y) <= h && dx*dx+dy*dy <= radius*radius
+}
+
+// A SwitchPlate is a plate with holes for the switches in
+// a keyboard layout.
+type SwitchPlate struct {
+	Switch KeySwitch
+	Keys   []*KeyboardKey
+
+	// Thickness should be 1.5 mm for Cherry MX switches to
+	// clip into the plate, or 1.3 mm for Kailh Choc.
+	Thickness float64
+
+	// Margin is the width of the plate beyond the edges of
+	// the outermost keys.
+	Margin float64
+
+	// Clearance is added to each side of the switch holes.
+	Clearance float64
+}
+
+// Holes creates a 2D solid containing all of the switch
+// holes in the plate.
+func (s *SwitchPlate) Holes() model2d.Solid {
+	halfSize := s.Switch.PlateHoleSize()/2 + s.Clearance
+	var holes model2d.JoinedSolid
+	for _, key := range s.Keys {
+		square := &model2d.Rect{
+			MinVal: model2d.XY(-halfSize, -halfSize),
+			MaxVal: model2d.XY(halfSize, halfSize),
+		}
+		xf := model2d.JoinedTransform{
+			model2d.Rotation(key.Rotation),
+			&model2d.Translate{Offset: key.Center},
+		}
+		holes = append(holes, model2d.TransformSolid(xf, square))
+	}
+	return holes.Optimize()
+}
+
+// Solid creates the plate, with its bottom at z=0.
+//
+// The plate is sized to fit the keys, so this panics if
+// there are no keys.
+func (s *SwitchPlate) Solid() model3d.Solid {
+	if len(s.Keys) == 0 {
+		panic("switch plate has no keys")
+	}
+	pitch := s.Switch.Pitch()
+	min, max := s.Keys[0].Center, s.Keys[0].Center
+	for _, key := range s.Keys {
+		halfSize := model2d.XY(key.Width*pitch.X, key.Height*pitch.Y).Scale(0.5)
+		if key.Rotation != 0 {
+			// Use the circumscribed circle of rotated keys.
+			r := halfSize.Norm()
+			halfSize = model2d.XY(r, r)
+		}
+		min = min.Min(key.Center.Sub(halfSize))
+		max = max.Max(key.Center.Add(halfSize))
+	}
+	margin := model2d.XY(s.Margin, s.Margin)
+	outline := &model2d.Rect{MinVal: min.Sub(margin), MaxVal: max.Add(margin)}
+	return model3d.ProfileSolid(&model2d.SubtractedSolid{
+		Positive: outline,
+		Negative: s.Holes(),
+	}, 0, s.Thickness)
+}
@@ -0,0 +1,119 @@
+package toolbox3d
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const keyboardTestLayout = `[
+	{"name": "test"},
+	["Esc", {"x": 0.5}, "F1"],
+	[{"w": 1.5}, "Tab", "Q"],
+	[{"r": 90, "rx": 5, "ry": 0}, "R"]
+]`
+
+func TestReadKLELayout(t *testing.T) {
+	keys, err := ReadKLELayout(strings.NewReader(keyboardTestLayout), CherryMX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*KeyboardKey{
+		{Label: "Esc", Center: model2d.XY(0.5, -0.5), Width: 1, Height: 1},
+		{Label: "F1", Center: model2d.XY(2, -0.5), Width: 1, Height: 1},
+		{Label: "Tab", Center: model2d.XY(0.75, -1.5), Width: 1.5, Height: 1},
+		{Label: "Q", Center: model2d.XY(2, -1.5), Width: 1, Height: 1},
+		// Rotated 90 degrees clockwise around (5, 0).
+		{Label: "R", Center: model2d.XY(4.5, -0.5), Width: 1, Height: 1, Rotation: -math.Pi / 2},
+	}
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %d keys but got %d", len(expected), len(keys))
+	}
+	for i, key := range keys {
+		e := expected[i]
+		e.Center = e.Center.Scale(19.05)
+		if key.Label != e.Label || key.Center.Dist(e.Center) > 1e-8 || key.Width != e.Width ||
+			key.Height != e.Height || math.Abs(key.Rotation-e.Rotation) > 1e-8 {
+			t.Errorf("key %d: expected %v but got %v", i, e, key)
+		}
+	}
+}
+
+func TestKeycapSolid(t *testing.T) {
+	for _, switchType := range []KeySwitch{CherryMX, KailhChoc} {
+		k := NewKeycap(switchType, 1, 1)
+		solid := k.Solid()
+		mesh := model3d.MarchingCubesSearch(solid, 0.2, 8)
+		if mesh.NeedsRepair() {
+			t.Errorf("switch %d: mesh needs repair", switchType)
+		}
+		size := mesh.Max().Sub(mesh.Min())
+		pitch := switchType.Pitch()
+		if math.Abs(size.X-(pitch.X-k.Gap)) > 0.3 || math.Abs(mesh.Max().Z-k.Thickness) > 0.3 {
+			t.Errorf("switch %d: unexpected size %v", switchType, size)
+		}
+
+		// The top should be dished.
+		if solid.Contains(model3d.Z(k.Thickness - k.DishDepth/2)) {
+			t.Errorf("switch %d: missing dish", switchType)
+		}
+		if !solid.Contains(model3d.Z(k.Thickness - k.DishDepth - k.WallThickness/2)) {
+			t.Errorf("switch %d: missing top", switchType)
+		}
+	}
+
+	mx := NewKeycap(CherryMX, 1, 1).Solid()
+	if mx.Contains(model3d.Z(2)) || mx.Contains(model3d.XYZ(1.5, 0, 2)) {
+		t.Error("missing stem cross")
+	}
+	if !mx.Contains(model3d.XYZ(1.5, 1.5, 2)) {
+		t.Error("missing stem")
+	}
+
+	choc := NewKeycap(KailhChoc, 1, 1).Solid()
+	if !choc.Contains(model3d.XYZ(2.85, 0, 0.5)) || choc.Contains(model3d.XYZ(0, 0, 0.5)) {
+		t.Error("unexpected choc legs")
+	}
+}
+
+func TestSwitchPlate(t *testing.T) {
+	keys, err := ReadKLELayout(strings.NewReader(keyboardTestLayout), CherryMX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plate := &SwitchPlate{
+		Switch:    CherryMX,
+		Keys:      keys,
+		Thickness: 1.5,
+		Margin:    5,
+		Clearance: 0.05,
+	}
+	solid := plate.Solid()
+	for _, key := range keys {
+		center := model3d.XYZ(key.Center.X, key.Center.Y, 0.75)
+		if solid.Contains(center) {
+			t.Errorf("missing hole for %s", key.Label)
+		}
+		if solid.Contains(center.Add(model3d.XYZ(6.9, 6.9, 0))) {
+			t.Errorf("hole for %s is too small", key.Label)
+		}
+		if !solid.Contains(center.Add(model3d.X(7.2))) {
+			t.Errorf("hole for %s is too large", key.Label)
+		}
+	}
+	if solid.Contains(model3d.XYZ(0, 0, 1.6)) {
+		t.Error("plate is too thick")
+	}
+}
+
+func TestSwitchPlateNoKeys(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for plate without keys")
+		}
+	}()
+	(&SwitchPlate{Switch: CherryMX, Thickness: 1.5}).Solid()
+}
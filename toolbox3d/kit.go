@@ -0,0 +1,255 @@
+package toolbox3d
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// KitManifestFile is the name of the manifest written to
+// a kit's directory.
+const KitManifestFile = "manifest.json"
+
+// A KitFormat is a file format for the parts of a Kit.
+type KitFormat int
+
+const (
+	KitSTL KitFormat = iota
+	Kit3MF
+)
+
+// Extension gets the file extension for the format,
+// including the leading ".".
+func (k KitFormat) Extension() string {
+	switch k {
+	case KitSTL:
+		return ".stl"
+	case Kit3MF:
+		return ".3mf"
+	default:
+		panic("unknown kit format")
+	}
+}
+
+// A KitPart is a single part of a Kit.
+type KitPart struct {
+	// Name identifies the part, and is used in its
+	// filename.
+	Name string
+
+	// Mesh is the part in its assembled (or modeled)
+	// orientation.
+	Mesh *model3d.Mesh
+
+	// Quantity is the number of copies of the part which
+	// should be printed.
+	//
+	// If 0, 1 is used.
+	Quantity int
+
+	// Material is an optional description of the material
+	// to print the part with, e.g. "PLA" or "TPU".
+	Material string
+
+	// Orientation, if non-nil, rotates the part from its
+	// modeled orientation into its printing orientation.
+	Orientation *model3d.Matrix3
+
+	// Notes are optional printing instructions, such as
+	// infill or support settings.
+	Notes string
+}
+
+// A Kit is a named collection of parts which are printed
+// separately and then assembled, such as the top, legs,
+// and stand of a table.
+//
+// Kits are saved as a directory with one file per part,
+// plus a manifest describing how many of each part to
+// print, and how each part was oriented on the plate.
+type Kit struct {
+	Name   string
+	Format KitFormat
+	Parts  []*KitPart
+}
+
+// Add appends a part to the kit.
+func (k *Kit) Add(part *KitPart) {
+	k.Parts = append(k.Parts, part)
+}
+
+// Save writes every part and the manifest to a directory,
+// creating the directory if necessary.
+//
+// Files are numbered in the order the parts were added,
+// e.g. "01_top.stl", so that they can be printed
+// sequentially.
+// Every part is rotated into its printing orientation and
+// then translated to rest on the plane z=0, centered
+// around the z axis.
+func (k *Kit) Save(dir string) error {
+	if err := k.save(dir); err != nil {
+		return errors.Wrap(err, "save kit")
+	}
+	return nil
+}
+
+func (k *Kit) save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	manifest, meshes, err := k.build()
+	if err != nil {
+		return err
+	}
+	for i, part := range manifest.Parts {
+		tris := meshes[i].TriangleSlice()
+		f, err := os.Create(filepath.Join(dir, part.File))
+		if err != nil {
+			return err
+		}
+		if k.Format == Kit3MF {
+			err = model3d.Write3MF(f, tris)
+		} else {
+			err = model3d.WriteSTL(f, tris)
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, KitManifestFile), append(data, '\n'), 0644)
+}
+
+// Manifest computes the manifest which Save would write.
+func (k *Kit) Manifest() (*KitManifest, error) {
+	manifest, _, err := k.build()
+	if err != nil {
+		return nil, errors.Wrap(err, "kit manifest")
+	}
+	return manifest, nil
+}
+
+// PlacedMesh gets the mesh for a part in the position
+// where Save would write it, along with the translation
+// that was applied after the part's orientation.
+func (k *KitPart) PlacedMesh() (*model3d.Mesh, model3d.Coord3D) {
+	mesh := k.Mesh
+	if k.Orientation != nil {
+		mesh = mesh.Transform(&model3d.Matrix3Transform{Matrix: k.Orientation})
+	}
+	min, max := mesh.Min(), mesh.Max()
+	center := min.Mid(max)
+	offset := model3d.XYZ(-center.X, -center.Y, -min.Z)
+	return mesh.Translate(offset), offset
+}
+
+func (k *Kit) build() (*KitManifest, []*model3d.Mesh, error) {
+	manifest := &KitManifest{Name: k.Name, Format: strings.TrimPrefix(k.Format.Extension(), ".")}
+	var meshes []*model3d.Mesh
+	digits := len(fmt.Sprint(len(k.Parts)))
+	if digits < 2 {
+		digits = 2
+	}
+	for i, part := range k.Parts {
+		if part.Name == "" {
+			return nil, nil, fmt.Errorf("part %d has no name", i)
+		} else if part.Quantity < 0 {
+			return nil, nil, fmt.Errorf("part %s has negative quantity", part.Name)
+		} else if part.Mesh == nil || len(part.Mesh.TriangleSlice()) == 0 {
+			return nil, nil, fmt.Errorf("part %s has no triangles", part.Name)
+		}
+		file := fmt.Sprintf("%0*d_%s%s", digits, i+1, kitFileName(part.Name),
+			k.Format.Extension())
+
+		mesh, offset := part.PlacedMesh()
+		meshes = append(meshes, mesh)
+
+		orientation := model3d.Matrix3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+		if part.Orientation != nil {
+			orientation = *part.Orientation
+		}
+		quantity := part.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		manifest.Parts = append(manifest.Parts, &KitManifestPart{
+			Name:         part.Name,
+			File:         file,
+			Quantity:     quantity,
+			Material:     part.Material,
+			Notes:        part.Notes,
+			Orientation:  orientation,
+			Translation:  offset.Array(),
+			NumTriangles: len(mesh.TriangleSlice()),
+			Size:         mesh.Max().Sub(mesh.Min()).Array(),
+			Volume:       mesh.Volume(),
+		})
+	}
+	return manifest, meshes, nil
+}
+
+// kitFileName converts a part name into a string which is
+// safe to use in a filename.
+func kitFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// A KitManifest describes the parts of a saved Kit.
+type KitManifest struct {
+	Name   string             `json:"name"`
+	Format string             `json:"format"`
+	Parts  []*KitManifestPart `json:"parts"`
+}
+
+// A KitManifestPart describes a single part of a saved
+// Kit.
+type KitManifestPart struct {
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Quantity int    `json:"quantity"`
+	Material string `json:"material,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+
+	// Orientation is the row-major rotation applied to the
+	// part, followed by Translation, to move it from its
+	// modeled position to its position on the plate.
+	Orientation model3d.Matrix3 `json:"orientation"`
+	Translation [3]float64      `json:"translation"`
+
+	NumTriangles int        `json:"num_triangles"`
+	Size         [3]float64 `json:"size"`
+	Volume       float64    `json:"volume"`
+}
+
+// ReadKitManifest reads the manifest from a saved kit's
+// directory.
+func ReadKitManifest(dir string) (*KitManifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, KitManifestFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "read kit manifest")
+	}
+	var res KitManifest
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, errors.Wrap(err, "read kit manifest")
+	}
+	return &res, nil
+}
@@ -0,0 +1,119 @@
+package toolbox3d
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestKitSave(t *testing.T) {
+	for _, format := range []KitFormat{KitSTL, Kit3MF} {
+		dir, err := ioutil.TempDir("", "kit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		kit := &Kit{Name: "table", Format: format}
+		kit.Add(&KitPart{
+			Name:     "top",
+			Mesh:     model3d.NewMeshRect(model3d.XYZ(1, 2, 3), model3d.XYZ(5, 4, 3.5)),
+			Material: "PLA",
+		})
+		kit.Add(&KitPart{
+			Name:        "leg (long)",
+			Mesh:        model3d.NewMeshRect(model3d.XYZ(0, 0, 0), model3d.XYZ(6, 1, 1)),
+			Quantity:    3,
+			Orientation: model3d.NewMatrix3Rotation(model3d.Y(1), -math.Pi/2),
+			Notes:       "print standing up",
+		})
+		if err := kit.Save(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		manifest, err := ReadKitManifest(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := kit.Manifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Volumes are summed in an arbitrary order, so they
+		// may differ slightly between calls.
+		for i, part := range expected.Parts {
+			if i < len(manifest.Parts) && math.Abs(part.Volume-manifest.Parts[i].Volume) < 1e-8 {
+				part.Volume = manifest.Parts[i].Volume
+			}
+		}
+		if !reflect.DeepEqual(manifest, expected) {
+			t.Errorf("expected manifest %#v but got %#v", expected, manifest)
+		}
+		if manifest.Name != "table" || len(manifest.Parts) != 2 {
+			t.Fatalf("unexpected manifest: %#v", manifest)
+		}
+
+		top, leg := manifest.Parts[0], manifest.Parts[1]
+		ext := format.Extension()
+		if top.File != "01_top"+ext || leg.File != "02_leg__long_"+ext {
+			t.Errorf("unexpected files: %s, %s", top.File, leg.File)
+		}
+		if top.Quantity != 1 || leg.Quantity != 3 {
+			t.Errorf("unexpected quantities: %d, %d", top.Quantity, leg.Quantity)
+		}
+		if top.Material != "PLA" || leg.Notes != "print standing up" {
+			t.Error("missing material or notes")
+		}
+		if top.Translation != [3]float64{-3, -3, -3} {
+			t.Errorf("unexpected translation: %v", top.Translation)
+		}
+		for i, x := range []float64{1, 1, 6} {
+			if math.Abs(leg.Size[i]-x) > 1e-8 {
+				t.Errorf("unexpected leg size: %v", leg.Size)
+				break
+			}
+		}
+
+		for _, part := range manifest.Parts {
+			path := filepath.Join(dir, part.File)
+			var mesh *model3d.Mesh
+			if format == Kit3MF {
+				mesh, err = model3d.Load3MF(path)
+			} else {
+				mesh, err = model3d.LoadSTL(path)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(mesh.Volume()-part.Volume) > 1e-4 {
+				t.Errorf("%s: expected volume %f but got %f", part.Name, part.Volume,
+					mesh.Volume())
+			}
+			min, max := mesh.Min(), mesh.Max()
+			if math.Abs(min.Z) > 1e-5 || math.Abs(min.X+max.X) > 1e-5 ||
+				math.Abs(min.Y+max.Y) > 1e-5 {
+				t.Errorf("%s: part not placed on plate: min=%v max=%v", part.Name,
+					min.Array(), max.Array())
+			}
+		}
+	}
+}
+
+func TestKitErrors(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.Coord3D{}, model3d.XYZ(1, 1, 1))
+	kits := []*Kit{
+		{Parts: []*KitPart{{Mesh: mesh}}},
+		{Parts: []*KitPart{{Name: "a", Mesh: mesh, Quantity: -1}}},
+		{Parts: []*KitPart{{Name: "a", Mesh: model3d.NewMesh()}}},
+	}
+	for i, kit := range kits {
+		if _, err := kit.Manifest(); err == nil {
+			t.Errorf("kit %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A BoreKind is the shape of a shaft which fits into a
+// bore.
+type BoreKind int
+
+const (
+	RoundBore BoreKind = iota
+	DShaftBore
+	HexBore
+)
+
+// A ShaftBore is a hole for mounting a part on a shaft.
+//
+// Bores are centered on the z-axis.
+type ShaftBore struct {
+	Kind BoreKind
+
+	// Diameter is the diameter of the shaft.
+	// For hex shafts, this is the distance across flats.
+	Diameter float64
+
+	// FlatDistance is the distance from the axis of a
+	// D-shaft to its flat, which faces the +X direction.
+	// For example, this is 1.5 for a 6 mm shaft which is
+	// 4.5 mm thick at the flat.
+	FlatDistance float64
+
+	// Clearance is added to every side of the bore.
+	Clearance float64
+}
+
+// Profile creates the 2D cross-section of the bore.
+func (s *ShaftBore) Profile() model2d.Solid {
+	r := s.Diameter/2 + s.Clearance
+	switch s.Kind {
+	case RoundBore:
+		return &model2d.Circle{Radius: r}
+	case DShaftBore:
+		flat := s.FlatDistance + s.Clearance
+		return model2d.CheckedFuncSolid(
+			model2d.XY(-r, -r),
+			model2d.XY(math.Min(r, flat), r),
+			func(c model2d.Coord) bool {
+				return c.X <= flat && c.Norm() <= r
+			},
+		)
+	case HexBore:
+		// Flats face the +X and -X directions.
+		corner := r / math.Cos(math.Pi/6)
+		return model2d.CheckedFuncSolid(
+			model2d.XY(-r, -corner),
+			model2d.XY(r, corner),
+			func(c model2d.Coord) bool {
+				for i := 0; i < 3; i++ {
+					theta := float64(i) * math.Pi / 3
+					if math.Abs(c.X*math.Cos(theta)+c.Y*math.Sin(theta)) > r {
+						return false
+					}
+				}
+				return true
+			},
+		)
+	default:
+		panic("unknown bore kind")
+	}
+}
+
+// Solid creates the bore between two z values, to be
+// subtracted from a part.
+func (s *ShaftBore) Solid(minZ, maxZ float64) model3d.Solid {
+	return model3d.ProfileSolid(s.Profile(), minZ, maxZ)
+}
+
+// A KnobGrip is the shape of the sides of a knob.
+type KnobGrip int
+
+const (
+	// RibbedGrip has square ribs around the knob.
+	RibbedGrip KnobGrip = iota
+
+	// LobedGrip has round lobes, like a fluted knob.
+	LobedGrip
+
+	// StarGrip has pointed lobes.
+	StarGrip
+)
+
+// A Knob is a grip which mounts on a shaft.
+//
+// The knob is centered on the z-axis, with its bottom at
+// z=0 and its bore opening at the bottom.
+type Knob struct {
+	Grip KnobGrip
+
+	Radius float64
+	Height float64
+
+	// NumGrips is the number of ribs or lobes.
+	NumGrips int
+
+	// GripDepth is how far the valleys between ribs or
+	// lobes are inset from Radius.
+	// For lobed knobs, this is also the radius of each
+	// lobe.
+	GripDepth float64
+
+	// ChamferSize is the size of the 45 degree chamfer
+	// around the top of the knob.
+	ChamferSize float64
+
+	// Bore is the hole for the shaft, or nil for no bore.
+	Bore *ShaftBore
+
+	// BoreDepth is the depth of the bore from the bottom
+	// of the knob.
+	BoreDepth float64
+
+	// PointerHeight and PointerWidth are the size of a
+	// raised pointer on top of the knob, facing the +X
+	// direction (towards the flat of a D-shaft).
+	// If PointerHeight is 0, there is no pointer.
+	PointerHeight float64
+	PointerWidth  float64
+}
+
+// Profile creates the 2D cross-section of the knob's grip.
+func (k *Knob) Profile() model2d.Solid {
+	if k.NumGrips < 1 {
+		panic("knob must have at least one grip")
+	}
+	r := k.Radius
+	n := float64(k.NumGrips)
+	if k.Grip == LobedGrip {
+		solid := model2d.JoinedSolid{&model2d.Circle{Radius: r - k.GripDepth}}
+		for i := 0; i < k.NumGrips; i++ {
+			theta := float64(i) * 2 * math.Pi / n
+			solid = append(solid, &model2d.Circle{
+				Center: model2d.XY(math.Cos(theta), math.Sin(theta)).Scale(r - k.GripDepth),
+				Radius: k.GripDepth,
+			})
+		}
+		return solid.Optimize()
+	}
+	return model2d.CheckedFuncSolid(
+		model2d.XY(-r, -r),
+		model2d.XY(r, r),
+		func(c model2d.Coord) bool {
+			theta := math.Atan2(c.Y, c.X)
+			// Phase is 0.5 at the center of each rib or point.
+			phase := theta*n/(2*math.Pi) + 0.5
+			phase -= math.Floor(phase)
+			var inset float64
+			switch k.Grip {
+			case RibbedGrip:
+				if phase < 0.25 || phase > 0.75 {
+					inset = k.GripDepth
+				}
+			case StarGrip:
+				inset = k.GripDepth * math.Abs(1-2*phase)
+			default:
+				panic("unknown knob grip")
+			}
+			return c.Norm() <= r-inset
+		},
+	)
+}
+
+// Solid creates the knob.
+//
+// This panics if ChamferSize is not less than Radius, or
+// if a pointer does not fit between the center of the
+// knob and the chamfer and grips.
+func (k *Knob) Solid() model3d.Solid {
+	if k.ChamferSize >= k.Radius {
+		panic("knob chamfer size must be less than the radius")
+	}
+	pointerMin := k.Radius * 0.2
+	pointerMax := k.Radius - k.ChamferSize - k.GripDepth
+	if k.PointerHeight != 0 && pointerMax <= pointerMin {
+		panic("knob pointer does not fit inside the chamfer and grips")
+	}
+	profile := k.Profile()
+	chamferZ := k.Height - k.ChamferSize
+	body := model3d.CheckedFuncSolid(
+		model3d.XYZ(-k.Radius, -k.Radius, 0),
+		model3d.XYZ(k.Radius, k.Radius, k.Height),
+		func(c model3d.Coord3D) bool {
+			p := c.XY()
+			if c.Z > chamferZ {
+				// Shrink the profile towards the top.
+				scale := (k.Radius - (c.Z - chamferZ)) / k.Radius
+				p = p.Scale(1 / scale)
+			}
+			return profile.Contains(p)
+		},
+	)
+	var solid model3d.Solid = body
+	if k.PointerHeight != 0 {
+		solid = model3d.JoinedSolid{
+			body,
+			&model3d.Rect{
+				MinVal: model3d.XYZ(pointerMin, -k.PointerWidth/2, k.Height-1e-3),
+				MaxVal: model3d.XYZ(pointerMax, k.PointerWidth/2, k.Height+k.PointerHeight),
+			},
+		}
+	}
+	if k.Bore == nil {
+		return solid
+	}
+	return &model3d.SubtractedSolid{
+		Positive: solid,
+		Negative: k.Bore.Solid(-1e-3, k.BoreDepth),
+	}
+}
+
+// A Handwheel is a spoked wheel with a hub, such as the
+// wheel on a lead screw or a valve.
+//
+// The wheel is centered on the z-axis, with the bottom of
+// its hub at z=0.
+type Handwheel struct {
+	// Radius is the distance from the axis to the center
+	// of the rim, and RimRadius is the radius of the rim's
+	// cross-section.
+	Radius    float64
+	RimRadius float64
+
+	HubRadius float64
+	HubHeight float64
+
+	NumSpokes   int
+	SpokeRadius float64
+
+	// Bore is the hole through the hub, or nil for none.
+	Bore *ShaftBore
+
+	// HandleRadius and HandleLength describe an optional
+	// crank handle on top of the rim in the +X direction.
+	// If HandleLength is 0, there is no handle.
+	HandleRadius float64
+	HandleLength float64
+}
+
+// Solid creates the handwheel.
+func (h *Handwheel) Solid() model3d.Solid {
+	midZ := h.HubHeight / 2
+	solid := model3d.JoinedSolid{
+		&model3d.Cylinder{
+			P2:     model3d.Z(h.HubHeight),
+			Radius: h.HubRadius,
+		},
+		&model3d.Torus{
+			Center:      model3d.Z(midZ),
+			Axis:        model3d.Z(1),
+			OuterRadius: h.Radius,
+			InnerRadius: h.RimRadius,
+		},
+	}
+	for i := 0; i < h.NumSpokes; i++ {
+		theta := float64(i) * 2 * math.Pi / float64(h.NumSpokes)
+		dir := model3d.XYZ(math.Cos(theta), math.Sin(theta), 0)
+		solid = append(solid, &model3d.Cylinder{
+			P1:     model3d.Z(midZ),
+			P2:     dir.Scale(h.Radius).Add(model3d.Z(midZ)),
+			Radius: h.SpokeRadius,
+		})
+	}
+	if h.HandleLength != 0 {
+		solid = append(solid, &model3d.Cylinder{
+			P1:     model3d.XYZ(h.Radius, 0, midZ),
+			P2:     model3d.XYZ(h.Radius, 0, midZ+h.RimRadius+h.HandleLength),
+			Radius: h.HandleRadius,
+		})
+	}
+	if h.Bore == nil {
+		return solid.Optimize()
+	}
+	return &model3d.SubtractedSolid{
+		Positive: solid.Optimize(),
+		Negative: h.Bore.Solid(-1e-3, h.HubHeight+1e-3),
+	}
+}
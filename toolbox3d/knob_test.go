@@ -0,0 +1,128 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestShaftBoreProfile(t *testing.T) {
+	d := (&ShaftBore{Kind: DShaftBore, Diameter: 6, FlatDistance: 1.5, Clearance: 0.1}).Profile()
+	if !d.Contains(model2d.XY(-3.05, 0)) || d.Contains(model2d.XY(1.7, 0)) ||
+		!d.Contains(model2d.XY(1.5, 0)) {
+		t.Error("unexpected D-shaft profile")
+	}
+	if max := d.Max(); math.Abs(max.X-1.6) > 1e-8 {
+		t.Errorf("unexpected max: %v", max)
+	}
+
+	hex := (&ShaftBore{Kind: HexBore, Diameter: 4}).Profile()
+	corner := 2 / math.Cos(math.Pi/6)
+	if !hex.Contains(model2d.XY(1.99, 0)) || hex.Contains(model2d.XY(2.01, 0)) {
+		t.Error("unexpected hex flats")
+	}
+	if !hex.Contains(model2d.XY(0, corner-0.01)) || hex.Contains(model2d.XY(0, corner+0.01)) {
+		t.Error("unexpected hex corners")
+	}
+}
+
+func TestKnobProfile(t *testing.T) {
+	for _, grip := range []KnobGrip{RibbedGrip, LobedGrip, StarGrip} {
+		k := &Knob{Grip: grip, Radius: 1, NumGrips: 6, GripDepth: 0.2}
+		profile := k.Profile()
+		if !profile.Contains(model2d.X(0.99)) {
+			t.Errorf("grip %d: expected rib or lobe along +X", grip)
+		}
+		valley := model2d.XY(math.Cos(math.Pi/6), math.Sin(math.Pi/6)).Scale(0.95)
+		if profile.Contains(valley) {
+			t.Errorf("grip %d: expected valley between grips", grip)
+		}
+		if !profile.Contains(valley.Scale(0.75 / 0.95)) {
+			t.Errorf("grip %d: expected solid center", grip)
+		}
+	}
+}
+
+func TestKnobSolid(t *testing.T) {
+	k := &Knob{
+		Grip:          StarGrip,
+		Radius:        10,
+		Height:        8,
+		NumGrips:      5,
+		GripDepth:     2,
+		ChamferSize:   1,
+		Bore:          &ShaftBore{Kind: DShaftBore, Diameter: 6, FlatDistance: 1.5, Clearance: 0.1},
+		BoreDepth:     6,
+		PointerHeight: 0.5,
+		PointerWidth:  1,
+	}
+	solid := k.Solid()
+	if solid.Contains(model3d.Z(3)) || !solid.Contains(model3d.Z(7)) {
+		t.Error("unexpected bore")
+	}
+	if !solid.Contains(model3d.XYZ(5, 0, 8.25)) || solid.Contains(model3d.XYZ(5, 1, 8.25)) {
+		t.Error("unexpected pointer")
+	}
+	if !solid.Contains(model3d.XYZ(9.9, 0, 1)) || solid.Contains(model3d.XYZ(9.5, 0, 7.9)) {
+		t.Error("unexpected chamfer")
+	}
+
+	mesh := model3d.MarchingCubesSearch(solid, 0.25, 8)
+	if mesh.NeedsRepair() {
+		t.Error("mesh needs repair")
+	}
+}
+
+func TestKnobSolidInvalid(t *testing.T) {
+	testPanic := func(t *testing.T, k *Knob) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic for invalid knob")
+			}
+		}()
+		k.Solid()
+	}
+	t.Run("Chamfer", func(t *testing.T) {
+		testPanic(t, &Knob{Radius: 5, Height: 8, NumGrips: 5, ChamferSize: 5})
+	})
+	t.Run("Pointer", func(t *testing.T) {
+		testPanic(t, &Knob{
+			Radius:        5,
+			Height:        8,
+			NumGrips:      5,
+			GripDepth:     2,
+			ChamferSize:   2,
+			PointerHeight: 0.5,
+			PointerWidth:  1,
+		})
+	})
+}
+
+func TestHandwheelSolid(t *testing.T) {
+	h := &Handwheel{
+		Radius:       40,
+		RimRadius:    4,
+		HubRadius:    8,
+		HubHeight:    10,
+		NumSpokes:    3,
+		SpokeRadius:  2.5,
+		Bore:         &ShaftBore{Kind: HexBore, Diameter: 5},
+		HandleRadius: 3,
+		HandleLength: 20,
+	}
+	solid := h.Solid()
+	if solid.Contains(model3d.Z(5)) || !solid.Contains(model3d.XYZ(6, 0, 5)) {
+		t.Error("unexpected hub")
+	}
+	if !solid.Contains(model3d.XYZ(20, 0, 5)) || solid.Contains(model3d.XYZ(0, 20, 5)) {
+		t.Error("unexpected spokes")
+	}
+	if !solid.Contains(model3d.XYZ(40, 0, 25)) || solid.Contains(model3d.XYZ(-40, 0, 25)) {
+		t.Error("unexpected handle")
+	}
+	if max := solid.Max(); math.Abs(max.Z-29) > 1e-5 {
+		t.Errorf("unexpected max: %v", max)
+	}
+}
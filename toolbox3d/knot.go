@@ -0,0 +1,126 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A KnotPanel is a rectangular decorative panel covered
+// in an interlaced (Celtic plait) pattern.
+//
+// Two families of diagonal strands cross each other on a
+// checkerboard of grid points, alternating between going
+// over and under at every crossing.
+// Crossings are realized as height offsets, so the over
+// strand rises above the under strand.
+//
+// The panel spans [0, Cols*CellSize] along the x-axis and
+// [0, Rows*CellSize] along the y-axis, with its bottom at
+// z=0.
+// The strands are cut off by a frame around the edge.
+type KnotPanel struct {
+	// Rows and Cols determine the number of grid cells.
+	Rows int
+	Cols int
+
+	// CellSize is the side length of each grid cell.
+	CellSize float64
+
+	// StrandWidth is the width of each strand, measured
+	// perpendicular to the strand.
+	StrandWidth float64
+
+	// StrandThickness is the vertical thickness of each
+	// strand.
+	StrandThickness float64
+
+	// Lift is the amount that strands rise and fall from
+	// their average height.
+	// To avoid intersecting strands, this should be at
+	// least StrandThickness / 2.
+	Lift float64
+
+	// Gap is the width of the groove cut into an under
+	// strand along the edges of the over strand.
+	Gap float64
+
+	// BaseThickness is the thickness of the backing plate.
+	BaseThickness float64
+
+	// FrameWidth is the width of the frame around the edge
+	// of the panel.
+	FrameWidth float64
+}
+
+// Height gets the total height of the panel.
+func (k *KnotPanel) Height() float64 {
+	return k.BaseThickness + 2*k.Lift + k.StrandThickness
+}
+
+// Solid creates a solid for the panel.
+func (k *KnotPanel) Solid() model3d.Solid {
+	size := model3d.XY(float64(k.Cols), float64(k.Rows)).Scale(k.CellSize)
+	max := model3d.XYZ(size.X, size.Y, k.Height())
+	return model3d.CheckedFuncSolid(model3d.Coord3D{}, max, func(c model3d.Coord3D) bool {
+		if c.Z <= k.BaseThickness {
+			return true
+		}
+		if c.X < k.FrameWidth || c.Y < k.FrameWidth || c.X > size.X-k.FrameWidth ||
+			c.Y > size.Y-k.FrameWidth {
+			return true
+		}
+		return k.strandContains(c, 0) || k.strandContains(c, 1)
+	})
+}
+
+// strandContains checks if a point is within a strand of
+// the given family.
+//
+// Family 0 consists of the lines x-y = 2*k*CellSize, and
+// family 1 of the lines x+y = 2*k*CellSize.
+func (k *KnotPanel) strandContains(c model3d.Coord3D, family int) bool {
+	halfWidth := k.StrandWidth / 2
+	if k.strandDist(c, family) > halfWidth {
+		return false
+	}
+	z := k.strandHeight(c, family)
+	if math.Abs(c.Z-z) > k.StrandThickness/2 {
+		return false
+	}
+	// Outline the over strand by cutting a groove into the
+	// under strand along either side of it.
+	other := 1 - family
+	if d := k.strandDist(c, other); d > halfWidth && d <= halfWidth+k.Gap &&
+		k.strandHeight(c, other) > z {
+		return false
+	}
+	return true
+}
+
+// strandDist computes the horizontal distance from a
+// point to the center of the nearest strand in a family.
+func (k *KnotPanel) strandDist(c model3d.Coord3D, family int) float64 {
+	var offset float64
+	if family == 0 {
+		offset = c.X - c.Y
+	} else {
+		offset = c.X + c.Y
+	}
+	period := 2 * k.CellSize
+	offset -= period * math.Round(offset/period)
+	return math.Abs(offset) / math.Sqrt2
+}
+
+// strandHeight computes the height of the center of a
+// strand of some family at a given point.
+//
+// Family 0 is over at grid points with even x, and family
+// 1 is over at the rest.
+func (k *KnotPanel) strandHeight(c model3d.Coord3D, family int) float64 {
+	offset := k.Lift * math.Cos(math.Pi*c.X/k.CellSize)
+	if family == 1 {
+		offset = -offset
+	}
+	return k.BaseThickness + k.Lift + k.StrandThickness/2 + offset
+}
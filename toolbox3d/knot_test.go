@@ -0,0 +1,56 @@
+package toolbox3d
+
+import (
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestKnotPanelCrossings(t *testing.T) {
+	k := &KnotPanel{
+		Rows:            6,
+		Cols:            6,
+		CellSize:        1,
+		StrandWidth:     0.3,
+		StrandThickness: 0.2,
+		Lift:            0.15,
+		Gap:             0.05,
+		BaseThickness:   0.1,
+		FrameWidth:      0.2,
+	}
+	solid := k.Solid()
+	low := k.BaseThickness + k.StrandThickness/2
+	high := low + 2*k.Lift
+
+	// Family 0 is over at (2, 2), and family 1 is over at
+	// (3, 3).
+	for _, x := range []float64{2, 3} {
+		c := model3d.XYZ(x, x, 0)
+		if !solid.Contains(c.Add(model3d.Z(high))) {
+			t.Errorf("expected over strand at %f", x)
+		}
+		if solid.Contains(c.Add(model3d.Z(high - k.Lift))) {
+			t.Errorf("expected gap between strands at %f", x)
+		}
+		if !solid.Contains(c.Add(model3d.Z(low))) {
+			t.Errorf("expected under strand at %f", x)
+		}
+	}
+
+	// Check that the under strand leaves a gap beside the
+	// over strand.
+	overDir := model3d.XY(1, -1).Normalize()
+	underDir := model3d.XY(1, 1).Normalize()
+	p := model3d.XY(2, 2).Add(overDir.Scale(k.StrandWidth/2 + k.Gap/2))
+	if solid.Contains(p.Add(model3d.Z(low))) {
+		t.Error("expected gap beside over strand")
+	}
+	p = model3d.XY(2, 2).Add(underDir.Scale(0.3))
+	if solid.Contains(p.Add(model3d.Z(k.BaseThickness + 0.01)).Add(overDir.Scale(0.4))) {
+		t.Error("expected empty space between strands")
+	}
+
+	if !solid.Contains(model3d.XYZ(0.1, 3, k.Height()-0.01)) {
+		t.Error("expected frame")
+	}
+}
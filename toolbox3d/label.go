@@ -0,0 +1,359 @@
+package toolbox3d
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A CylindricalLabel wraps a 2D label, such as text,
+// around the side of a cylinder.
+//
+// The x-axis of the label is mapped to arc length around
+// the cylinder, and the y-axis is mapped to distance along
+// the cylinder's axis.
+// Thus, the label is not distorted by the curvature of
+// the cylinder, unlike a straight-line projection.
+type CylindricalLabel struct {
+	// P1 and P2 define the axis of the cylinder.
+	// The label's y=0 is at P1, and y increases towards
+	// P2.
+	P1 model3d.Coord3D
+	P2 model3d.Coord3D
+
+	// Radius is the radius of the cylinder's surface.
+	Radius float64
+
+	// Front is a direction perpendicular to the axis
+	// pointing at the label's x=0 line.
+	// If this is the zero vector, an arbitrary direction
+	// is chosen.
+	Front model3d.Coord3D
+
+	// Label is the 2D shape to wrap around the cylinder.
+	Label model2d.Solid
+}
+
+// Emboss creates a solid that raises the label out of
+// the cylinder's surface by the given depth.
+//
+// The result should be joined with the cylinder.
+func (c *CylindricalLabel) Emboss(depth float64) model3d.Solid {
+	return c.solid(c.Radius, c.Radius+depth)
+}
+
+// Engrave creates a solid that cuts the label into the
+// cylinder's surface by the given depth.
+//
+// The result should be subtracted from the cylinder.
+func (c *CylindricalLabel) Engrave(depth float64) model3d.Solid {
+	return c.solid(c.Radius-depth, c.Radius)
+}
+
+func (c *CylindricalLabel) solid(minRadius, maxRadius float64) model3d.Solid {
+	axis := c.P2.Sub(c.P1).Normalize()
+	front := c.Front.ProjectOut(axis)
+	if front.Norm() < 1e-8 {
+		front, _ = axis.OrthoBasis()
+	} else {
+		front = front.Normalize()
+	}
+	// The x-axis of the label points to the right when
+	// looking at the front of the cylinder from outside.
+	side := axis.Cross(front)
+
+	labelMin, labelMax := c.Label.Min(), c.Label.Max()
+	bounds := &model3d.Cylinder{
+		P1:     c.P1.Add(axis.Scale(labelMin.Y)),
+		P2:     c.P1.Add(axis.Scale(labelMax.Y)),
+		Radius: maxRadius,
+	}
+	return model3d.CheckedFuncSolid(bounds.Min(), bounds.Max(), func(coord model3d.Coord3D) bool {
+		offset := coord.Sub(c.P1)
+		y := offset.Dot(axis)
+		r := offset.ProjectOut(axis).Norm()
+		if r < minRadius || r > maxRadius {
+			return false
+		}
+		theta := math.Atan2(offset.Dot(side), offset.Dot(front))
+		return c.Label.Contains(model2d.XY(theta*c.Radius, y))
+	})
+}
+
+// A SurfaceLabel wraps a 2D label, such as text, onto the
+// surface of an arbitrary mesh.
+//
+// The label is laid out using an approximate geodesic
+// parameterization of the surface around an origin point,
+// so that straight lines in the label follow geodesics on
+// the surface and distances along the surface are
+// preserved near the origin.
+type SurfaceLabel struct {
+	label model2d.Solid
+	sdf   model3d.FaceSDF
+	uvs   *model3d.CoordMap
+
+	min model3d.Coord3D
+	max model3d.Coord3D
+}
+
+// NewSurfaceLabel creates a SurfaceLabel on the surface
+// of a closed mesh.
+//
+// The label's origin is placed at the point on the mesh
+// nearest to origin, and the label's y-axis points in the
+// up direction (projected onto the surface).
+//
+// The mesh should be finely tessellated compared to the
+// details of the label, since the parameterization is
+// interpolated linearly within each triangle.
+func NewSurfaceLabel(mesh *model3d.Mesh, label model2d.Solid, origin,
+	up model3d.Coord3D) *SurfaceLabel {
+	sdf := model3d.MeshToSDF(mesh)
+	face, point, _ := sdf.FaceSDF(origin)
+
+	labelMin, labelMax := label.Min(), label.Max()
+	maxDist := math.Max(labelMin.Norm(), labelMax.Norm())
+	maxDist = math.Max(maxDist, model2d.XY(labelMin.X, labelMax.Y).Norm())
+	maxDist = math.Max(maxDist, model2d.XY(labelMax.X, labelMin.Y).Norm())
+
+	uvs := surfaceLabelParameterize(mesh, face, point, up, maxDist*2)
+	res := &SurfaceLabel{
+		label: label,
+		sdf:   sdf,
+		uvs:   uvs,
+	}
+	res.computeBounds(mesh)
+	return res
+}
+
+// Emboss creates a solid that raises the label out of
+// the surface by the given depth along the normal.
+//
+// The result should be joined with the original model.
+func (s *SurfaceLabel) Emboss(depth float64) model3d.Solid {
+	return s.solid(-depth, 0, depth)
+}
+
+// Engrave creates a solid that cuts the label into the
+// surface by the given depth along the normal.
+//
+// The result should be subtracted from the original
+// model.
+func (s *SurfaceLabel) Engrave(depth float64) model3d.Solid {
+	return s.solid(0, depth, depth)
+}
+
+// Coord2D gets the 2D label coordinate for a point on
+// the surface, or false if the point is outside of the
+// parameterized region around the origin.
+func (s *SurfaceLabel) Coord2D(c model3d.Coord3D) (model2d.Coord, bool) {
+	face, point, _ := s.sdf.FaceSDF(c)
+	return s.faceCoord(face, point)
+}
+
+func (s *SurfaceLabel) faceCoord(face *model3d.Triangle, point model3d.Coord3D) (model2d.Coord,
+	bool) {
+	var uvs [3]model2d.Coord
+	for i, p := range face {
+		uv, ok := s.uvs.Load(p)
+		if !ok {
+			return model2d.Coord{}, false
+		}
+		uvs[i] = uv.(model2d.Coord)
+	}
+	bary := barycentricCoords(face, point)
+	return uvs[0].Scale(bary[0]).Add(uvs[1].Scale(bary[1])).Add(uvs[2].Scale(bary[2])), true
+}
+
+func (s *SurfaceLabel) solid(minSDF, maxSDF, depth float64) model3d.Solid {
+	pad := model3d.Ones(depth + 1e-8)
+	return model3d.CheckedFuncSolid(s.min.Sub(pad), s.max.Add(pad), func(c model3d.Coord3D) bool {
+		face, point, dist := s.sdf.FaceSDF(c)
+		if dist < minSDF || dist > maxSDF {
+			return false
+		}
+		uv, ok := s.faceCoord(face, point)
+		return ok && s.label.Contains(uv)
+	})
+}
+
+func (s *SurfaceLabel) computeBounds(mesh *model3d.Mesh) {
+	labelMin, labelMax := s.label.Min(), s.label.Max()
+	first := true
+	mesh.Iterate(func(t *model3d.Triangle) {
+		var uvMin, uvMax model2d.Coord
+		for i, p := range t {
+			uv, ok := s.uvs.Load(p)
+			if !ok {
+				return
+			}
+			c := uv.(model2d.Coord)
+			if i == 0 {
+				uvMin, uvMax = c, c
+			} else {
+				uvMin, uvMax = uvMin.Min(c), uvMax.Max(c)
+			}
+		}
+		if uvMax.X < labelMin.X || uvMax.Y < labelMin.Y ||
+			uvMin.X > labelMax.X || uvMin.Y > labelMax.Y {
+			return
+		}
+		if first {
+			s.min, s.max = t.Min(), t.Max()
+			first = false
+		} else {
+			s.min, s.max = s.min.Min(t.Min()), s.max.Max(t.Max())
+		}
+	})
+}
+
+// surfaceLabelParameterize computes a discrete
+// exponential map around a point on a triangle, assigning
+// 2D coordinates to every vertex within maxDist (along
+// the surface) of the point.
+func surfaceLabelParameterize(mesh *model3d.Mesh, face *model3d.Triangle, point,
+	up model3d.Coord3D, maxDist float64) *model3d.CoordMap {
+	normals := surfaceLabelVertexNormals(mesh)
+
+	normal := face.Normal()
+	yAxis := up.ProjectOut(normal)
+	if yAxis.Norm() < 1e-8 {
+		_, yAxis = normal.OrthoBasis()
+	}
+	yAxis = yAxis.Normalize()
+	xAxis := yAxis.Cross(normal)
+
+	states := map[model3d.Coord3D]*surfaceLabelVertex{}
+	queue := &surfaceLabelQueue{}
+	for _, p := range face {
+		offset := p.Sub(point)
+		v := &surfaceLabelVertex{
+			Coord: p,
+			Dist:  offset.Norm(),
+			UV:    model2d.XY(offset.Dot(xAxis), offset.Dot(yAxis)),
+		}
+		v.XAxis, v.YAxis = surfaceLabelTransport(xAxis, normals.Value(p))
+		states[p] = v
+		heap.Push(queue, v)
+	}
+
+	result := model3d.NewCoordMap()
+	for queue.Len() > 0 {
+		v := heap.Pop(queue).(*surfaceLabelVertex)
+		if v.Done {
+			continue
+		}
+		v.Done = true
+		result.Store(v.Coord, v.UV)
+
+		n := normals.Value(v.Coord)
+		for _, t := range mesh.Find(v.Coord) {
+			for _, neighbor := range t {
+				if neighbor == v.Coord {
+					continue
+				}
+				offset := neighbor.Sub(v.Coord)
+				length := offset.Norm()
+				newDist := v.Dist + length
+				if newDist > maxDist {
+					continue
+				}
+				if s, ok := states[neighbor]; ok && (s.Done || s.Dist <= newDist) {
+					continue
+				}
+
+				// Rotate the offset into the tangent plane
+				// while preserving its length.
+				tangent := offset.ProjectOut(n)
+				if norm := tangent.Norm(); norm > 1e-12 {
+					tangent = tangent.Scale(length / norm)
+				}
+				next := &surfaceLabelVertex{
+					Coord: neighbor,
+					Dist:  newDist,
+					UV:    v.UV.Add(model2d.XY(tangent.Dot(v.XAxis), tangent.Dot(v.YAxis))),
+				}
+				next.XAxis, next.YAxis = surfaceLabelTransport(v.XAxis,
+					normals.Value(neighbor))
+				if old, ok := states[neighbor]; ok {
+					old.Done = true
+				}
+				states[neighbor] = next
+				heap.Push(queue, next)
+			}
+		}
+	}
+	return result
+}
+
+func surfaceLabelTransport(xAxis, normal model3d.Coord3D) (model3d.Coord3D, model3d.Coord3D) {
+	x := xAxis.ProjectOut(normal)
+	if x.Norm() < 1e-8 {
+		x, _ = normal.OrthoBasis()
+	}
+	x = x.Normalize()
+	return x, normal.Cross(x)
+}
+
+func surfaceLabelVertexNormals(mesh *model3d.Mesh) *model3d.CoordToCoord {
+	result := model3d.NewCoordToCoord()
+	mesh.Iterate(func(t *model3d.Triangle) {
+		// Area-weighted normal.
+		n := t[1].Sub(t[0]).Cross(t[2].Sub(t[0]))
+		for _, p := range t {
+			result.Store(p, result.Value(p).Add(n))
+		}
+	})
+	result.Range(func(k, v model3d.Coord3D) bool {
+		result.Store(k, v.Normalize())
+		return true
+	})
+	return result
+}
+
+func barycentricCoords(t *model3d.Triangle, c model3d.Coord3D) [3]float64 {
+	v1 := t[1].Sub(t[0])
+	v2 := t[2].Sub(t[0])
+	mat := model3d.NewMatrix3Columns(v1, v2, t.Normal())
+	if math.Abs(mat.Det()) < 1e-20 {
+		return [3]float64{1, 0, 0}
+	}
+	components := mat.Inverse().MulColumn(c.Sub(t[0]))
+	return [3]float64{1 - (components.X + components.Y), components.X, components.Y}
+}
+
+type surfaceLabelVertex struct {
+	Coord model3d.Coord3D
+	Dist  float64
+	UV    model2d.Coord
+	XAxis model3d.Coord3D
+	YAxis model3d.Coord3D
+	Done  bool
+}
+
+type surfaceLabelQueue []*surfaceLabelVertex
+
+func (s surfaceLabelQueue) Len() int {
+	return len(s)
+}
+
+func (s surfaceLabelQueue) Less(i, j int) bool {
+	return s[i].Dist < s[j].Dist
+}
+
+func (s surfaceLabelQueue) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s *surfaceLabelQueue) Push(x interface{}) {
+	*s = append(*s, x.(*surfaceLabelVertex))
+}
+
+func (s *surfaceLabelQueue) Pop() interface{} {
+	res := (*s)[len(*s)-1]
+	*s = (*s)[:len(*s)-1]
+	return res
+}
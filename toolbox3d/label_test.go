@@ -0,0 +1,77 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestSurfaceLabelFlat(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.XYZ(-2, -2, -1), model3d.XYZ(2, 2, 0))
+	mesh = model3d.SubdivideEdges(mesh, 10)
+
+	label := &model2d.Rect{
+		MinVal: model2d.XY(-0.5, -0.3),
+		MaxVal: model2d.XY(0.5, 0.3),
+	}
+	sl := NewSurfaceLabel(mesh, label, model3d.XYZ(0.1, 0.2, 0.5), model3d.Y(1))
+
+	for _, c := range []model2d.Coord{{X: 0.1, Y: 0.2}, {X: 0.5, Y: -0.3}, {X: -0.3, Y: 0.4}} {
+		uv, ok := sl.Coord2D(model3d.XYZ(c.X, c.Y, 0))
+		if !ok {
+			t.Errorf("point %v not parameterized", c)
+			continue
+		}
+		expected := c.Sub(model2d.XY(0.1, 0.2))
+		if uv.Dist(expected) > 1e-5 {
+			t.Errorf("point %v: expected %v but got %v", c, expected, uv)
+		}
+	}
+
+	emboss := sl.Emboss(0.1)
+	if !emboss.Contains(model3d.XYZ(0.1, 0.2, 0.05)) {
+		t.Error("emboss should contain point above label")
+	}
+	if emboss.Contains(model3d.XYZ(0.1, 0.2, -0.05)) {
+		t.Error("emboss should not contain point below surface")
+	}
+	if emboss.Contains(model3d.XYZ(1.0, 0.2, 0.05)) {
+		t.Error("emboss should not contain point outside label")
+	}
+	engrave := sl.Engrave(0.1)
+	if !engrave.Contains(model3d.XYZ(0.1, 0.2, -0.05)) {
+		t.Error("engrave should contain point below label")
+	}
+}
+
+func TestCylindricalLabel(t *testing.T) {
+	label := &model2d.Rect{
+		MinVal: model2d.XY(-0.5, 0.2),
+		MaxVal: model2d.XY(0.5, 0.8),
+	}
+	cl := &CylindricalLabel{
+		P2:     model3d.Z(1),
+		Radius: 1,
+		Front:  model3d.X(1),
+		Label:  label,
+	}
+	emboss := cl.Emboss(0.1)
+
+	// An angle of 0.4 radians is 0.4 units of arc length.
+	inside := model3d.XYZ(math.Cos(0.4), math.Sin(0.4), 0).Scale(1.05).Add(model3d.Z(0.5))
+	if !emboss.Contains(inside) {
+		t.Error("expected point inside label")
+	}
+	outside := model3d.XYZ(math.Cos(0.6), math.Sin(0.6), 0).Scale(1.05).Add(model3d.Z(0.5))
+	if emboss.Contains(outside) {
+		t.Error("expected point outside label")
+	}
+	if emboss.Contains(inside.Scale(0.9)) {
+		t.Error("expected point below surface to be outside emboss")
+	}
+	if !cl.Engrave(0.1).Contains(model3d.XYZ(0.95, 0, 0.5)) {
+		t.Error("expected point inside engraving")
+	}
+}
@@ -0,0 +1,251 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A LatticeNode is a joint in a Lattice.
+type LatticeNode struct {
+	Position model3d.Coord3D
+
+	// Radius is the radius of the sphere at the joint.
+	Radius float64
+}
+
+// A LatticeEdge is a strut between two nodes of a Lattice.
+type LatticeEdge struct {
+	From int
+	To   int
+
+	// Radius is the radius of the strut.
+	// If 0, the strut tapers between the radii of the
+	// nodes at its endpoints.
+	Radius float64
+}
+
+// A Lattice is a graph of spherical joints connected by
+// cylindrical struts, such as a truss or a wireframe.
+type Lattice struct {
+	Nodes []LatticeNode
+	Edges []LatticeEdge
+
+	// BlendRadius, if non-zero, smooths the creases where
+	// struts meet each other and the joints.
+	BlendRadius float64
+}
+
+// AddNode adds a node to the lattice and returns its
+// index.
+func (l *Lattice) AddNode(position model3d.Coord3D, radius float64) int {
+	l.Nodes = append(l.Nodes, LatticeNode{Position: position, Radius: radius})
+	return len(l.Nodes) - 1
+}
+
+// AddEdge adds a strut between two nodes, using a radius
+// of 0 to taper between the node radii.
+func (l *Lattice) AddEdge(from, to int, radius float64) {
+	if from < 0 || from >= len(l.Nodes) || to < 0 || to >= len(l.Nodes) {
+		panic("node index out of range")
+	}
+	l.Edges = append(l.Edges, LatticeEdge{From: from, To: to, Radius: radius})
+}
+
+// SDF creates an SDF for the blended lattice.
+//
+// Without blending, the SDF is exact inside the lattice,
+// but it may overestimate distances outside the lattice.
+// The sign of the SDF is always correct.
+func (l *Lattice) SDF() model3d.SDF {
+	if len(l.Nodes) == 0 {
+		panic("lattice has no nodes")
+	}
+	var elements []latticeElement
+	for _, n := range l.Nodes {
+		elements = append(elements, latticeElement{
+			P1: n.Position,
+			P2: n.Position,
+			R1: n.Radius,
+			R2: n.Radius,
+		})
+	}
+	for _, e := range l.Edges {
+		n1, n2 := l.Nodes[e.From], l.Nodes[e.To]
+		r1, r2 := e.Radius, e.Radius
+		if r1 == 0 {
+			r1, r2 = n1.Radius, n2.Radius
+		}
+		elements = append(elements, latticeElement{
+			P1: n1.Position,
+			P2: n2.Position,
+			R1: r1,
+			R2: r2,
+		})
+	}
+	return newLatticeSDF(elements, l.BlendRadius)
+}
+
+// Solid creates a solid for the blended lattice.
+func (l *Lattice) Solid() model3d.Solid {
+	sdf := l.SDF()
+	return model3d.CheckedFuncSolid(sdf.Min(), sdf.Max(), func(c model3d.Coord3D) bool {
+		return sdf.SDF(c) > 0
+	})
+}
+
+// Mesh creates a mesh for the lattice using marching
+// cubes with the given grid size.
+func (l *Lattice) Mesh(delta float64) *model3d.Mesh {
+	return model3d.MarchingCubesSearch(l.Solid(), delta, 8)
+}
+
+// latticeElement is a sphere-capped cone between two
+// points, or a sphere if the points are equal.
+type latticeElement struct {
+	P1 model3d.Coord3D
+	P2 model3d.Coord3D
+	R1 float64
+	R2 float64
+}
+
+func (l *latticeElement) Min() model3d.Coord3D {
+	return l.P1.Sub(model3d.Ones(l.R1)).Min(l.P2.Sub(model3d.Ones(l.R2)))
+}
+
+func (l *latticeElement) Max() model3d.Coord3D {
+	return l.P1.Add(model3d.Ones(l.R1)).Max(l.P2.Add(model3d.Ones(l.R2)))
+}
+
+// Dist computes the signed distance to the element, which
+// is negative inside the element.
+func (l *latticeElement) Dist(c model3d.Coord3D) float64 {
+	ba := l.P2.Sub(l.P1)
+	l2 := ba.Dot(ba)
+	rr := l.R1 - l.R2
+	a2 := l2 - rr*rr
+	if a2 <= 0 {
+		// One sphere contains the other.
+		if l.R1 > l.R2 {
+			return c.Dist(l.P1) - l.R1
+		}
+		return c.Dist(l.P2) - l.R2
+	}
+	il2 := 1 / l2
+
+	pa := c.Sub(l.P1)
+	y := pa.Dot(ba)
+	z := y - l2
+	x := pa.Scale(l2).Sub(ba.Scale(y))
+	x2 := x.Dot(x)
+	y2 := y * y * l2
+	z2 := z * z * l2
+
+	k := math.Copysign(rr*rr*x2, rr)
+	if math.Copysign(a2*z2, z) > k {
+		return math.Sqrt(x2+z2)*il2 - l.R2
+	}
+	if math.Copysign(a2*y2, y) < k {
+		return math.Sqrt(x2+y2)*il2 - l.R1
+	}
+	return (math.Sqrt(x2*a2*il2)+y*rr)*il2 - l.R1
+}
+
+// latticeSDF evaluates the smooth union of many elements,
+// using a grid to find the elements near a point.
+type latticeSDF struct {
+	elements []latticeElement
+	blend    float64
+	min      model3d.Coord3D
+	max      model3d.Coord3D
+
+	cellSize float64
+	cells    map[[3]int][]int
+}
+
+func newLatticeSDF(elements []latticeElement, blend float64) *latticeSDF {
+	res := &latticeSDF{
+		elements: elements,
+		blend:    blend,
+		min:      elements[0].Min(),
+		max:      elements[0].Max(),
+		cells:    map[[3]int][]int{},
+	}
+	var totalSize float64
+	for i := range elements {
+		e := &elements[i]
+		res.min = res.min.Min(e.Min())
+		res.max = res.max.Max(e.Max())
+		size := e.Max().Sub(e.Min())
+		totalSize += math.Max(size.X, math.Max(size.Y, size.Z))
+	}
+	res.cellSize = totalSize/float64(len(elements)) + 2*blend
+
+	// Blending can slightly grow the union in concave
+	// regions, including near the bounds.
+	res.min = res.min.Sub(model3d.Ones(blend))
+	res.max = res.max.Add(model3d.Ones(blend))
+
+	// Elements further than two blend radii away from a
+	// point cannot change whether the point is inside the
+	// smooth union.
+	margin := model3d.Ones(2 * blend)
+	for i := range elements {
+		e := &elements[i]
+		min := res.cell(e.Min().Sub(margin))
+		max := res.cell(e.Max().Add(margin))
+		for x := min[0]; x <= max[0]; x++ {
+			for y := min[1]; y <= max[1]; y++ {
+				for z := min[2]; z <= max[2]; z++ {
+					key := [3]int{x, y, z}
+					res.cells[key] = append(res.cells[key], i)
+				}
+			}
+		}
+	}
+	return res
+}
+
+func (l *latticeSDF) Min() model3d.Coord3D {
+	return l.min
+}
+
+func (l *latticeSDF) Max() model3d.Coord3D {
+	return l.max
+}
+
+func (l *latticeSDF) SDF(c model3d.Coord3D) float64 {
+	indices, ok := l.cells[l.cell(c)]
+	if !ok {
+		// Far from every element, so an unblended distance
+		// is accurate enough.
+		res := math.Inf(1)
+		for i := range l.elements {
+			res = math.Min(res, l.elements[i].Dist(c))
+		}
+		return -res
+	}
+	res := math.Inf(1)
+	for _, i := range indices {
+		res = latticeSmoothMin(res, l.elements[i].Dist(c), l.blend)
+	}
+	return -res
+}
+
+func (l *latticeSDF) cell(c model3d.Coord3D) [3]int {
+	return [3]int{
+		int(math.Floor(c.X / l.cellSize)),
+		int(math.Floor(c.Y / l.cellSize)),
+		int(math.Floor(c.Z / l.cellSize)),
+	}
+}
+
+// latticeSmoothMin computes a polynomial smooth minimum,
+// which is never more than k/4 below the true minimum.
+func latticeSmoothMin(a, b, k float64) float64 {
+	if k == 0 {
+		return math.Min(a, b)
+	}
+	h := math.Max(k-math.Abs(a-b), 0) / k
+	return math.Min(a, b) - h*h*k/4
+}
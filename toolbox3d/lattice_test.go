@@ -0,0 +1,110 @@
+package toolbox3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestLatticeElementDist(t *testing.T) {
+	elements := []latticeElement{
+		{P1: model3d.XYZ(0, 0, 0), P2: model3d.XYZ(0, 0, 0), R1: 0.5, R2: 0.5},
+		{P1: model3d.XYZ(-1, 0, 0), P2: model3d.XYZ(1, 0.5, 0), R1: 0.3, R2: 0.3},
+		{P1: model3d.XYZ(-1, 0, 0), P2: model3d.XYZ(1, 0.5, 0.2), R1: 0.4, R2: 0.1},
+		{P1: model3d.XYZ(0, 0, 0), P2: model3d.XYZ(0.1, 0, 0), R1: 0.5, R2: 0.2},
+	}
+	for i, e := range elements {
+		// Compare to a brute-force union of spheres along the
+		// element's axis.
+		for j := 0; j < 100; j++ {
+			c := model3d.NewCoord3DRandNorm()
+			expected := math.Inf(1)
+			for k := 0; k <= 10000; k++ {
+				frac := float64(k) / 10000
+				p := e.P1.Scale(1 - frac).Add(e.P2.Scale(frac))
+				r := e.R1*(1-frac) + e.R2*frac
+				expected = math.Min(expected, c.Dist(p)-r)
+			}
+			if actual := e.Dist(c); math.Abs(actual-expected) > 1e-3 {
+				t.Fatalf("element %d: point %v: expected %f but got %f", i, c, expected, actual)
+			}
+		}
+	}
+}
+
+func TestLatticeSDF(t *testing.T) {
+	lattice := &Lattice{}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			lattice.AddNode(model3d.XYZ(float64(i), float64(j), 0), 0.2)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			idx := i*3 + j
+			if i < 2 {
+				lattice.AddEdge(idx, idx+3, 0.1)
+			}
+			if j < 2 {
+				lattice.AddEdge(idx, idx+1, 0)
+			}
+		}
+	}
+
+	sdf := lattice.SDF()
+	for i := 0; i < 1000; i++ {
+		c := model3d.XYZ(rand.Float64()*4-1, rand.Float64()*4-1, rand.Float64()*2-1)
+		expected := math.Inf(1)
+		for _, n := range lattice.Nodes {
+			expected = math.Min(expected, c.Dist(n.Position)-n.Radius)
+		}
+		for _, e := range lattice.Edges {
+			seg := model3d.Segment{lattice.Nodes[e.From].Position, lattice.Nodes[e.To].Position}
+			if e.Radius != 0 {
+				expected = math.Min(expected, seg.Dist(c)-e.Radius)
+			} else {
+				// Tapering between equal radii.
+				expected = math.Min(expected, seg.Dist(c)-0.2)
+			}
+		}
+		actual := -sdf.SDF(c)
+		if (actual < 0) != (expected < 0) {
+			t.Fatalf("point %v: expected %f but got %f", c, expected, actual)
+		} else if expected < 0 && math.Abs(actual-expected) > 1e-8 {
+			t.Fatalf("point %v: expected %f but got %f", c, expected, actual)
+		}
+	}
+}
+
+func TestLatticeBlend(t *testing.T) {
+	lattice := &Lattice{BlendRadius: 0.2}
+	a := lattice.AddNode(model3d.XYZ(0, 0, 0), 0.1)
+	b := lattice.AddNode(model3d.XYZ(1, 0, 0), 0.1)
+	c := lattice.AddNode(model3d.XYZ(0, 1, 0), 0.1)
+	lattice.AddEdge(a, b, 0)
+	lattice.AddEdge(a, c, 0)
+
+	solid := lattice.Solid()
+	crease := model3d.XYZ(0.12, 0.12, 0)
+	if !solid.Contains(crease) {
+		t.Error("crease should be filled")
+	}
+	unblended := &Lattice{Nodes: lattice.Nodes, Edges: lattice.Edges}
+	if unblended.Solid().Contains(crease) {
+		t.Error("crease should not be filled without blending")
+	}
+
+	mesh := lattice.Mesh(0.02)
+	if _, n := mesh.RepairNormals(1e-5); n != 0 {
+		t.Errorf("mesh has %d bad normals", n)
+	}
+	if mesh.NeedsRepair() {
+		t.Error("mesh needs repair")
+	}
+	min, max := mesh.Min(), mesh.Max()
+	if min.X > -0.09 || max.X < 1.09 || max.Y < 1.09 {
+		t.Errorf("unexpected bounds %v %v", min, max)
+	}
+}
@@ -0,0 +1,391 @@
+package toolbox3d
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A JointKind determines how a joint moves its child part.
+type JointKind int
+
+const (
+	// RevoluteJoint rotates the child around the joint's
+	// axis, using the joint value as an angle in radians.
+	RevoluteJoint JointKind = iota
+
+	// PrismaticJoint slides the child along the joint's
+	// axis, using the joint value as a distance.
+	PrismaticJoint
+)
+
+// A Mechanism simulates the motion of an assembly's parts
+// as they are moved by joints.
+//
+// Joints form a tree, where each joint moves a child part
+// (and all of the child's descendants) relative to a
+// parent part.
+// Parts which are not the child of any joint do not move.
+//
+// Joint values are either driven by functions of time, or
+// coupled to other joints, e.g. by a gear ratio.
+type Mechanism struct {
+	assembly *Assembly
+	joints   []*MechanismJoint
+	byChild  map[string]*MechanismJoint
+	byName   map[string]*MechanismJoint
+	ignored  map[[2]string]bool
+	meshes   map[mechanismMeshKey]*model3d.Mesh
+}
+
+// A MechanismJoint is a joint between two parts in a
+// Mechanism.
+type MechanismJoint struct {
+	Name   string
+	Kind   JointKind
+	Parent string
+	Child  string
+
+	// Axis is the axis of the joint, in the assembled
+	// coordinates at time 0.
+	// The joint rotates around or slides along the z-axis.
+	Axis *Anchor
+
+	// Drive computes the joint value at a given time.
+	// If nil, the value is 0, unless Driver is set.
+	Drive func(t float64) float64
+
+	// Driver, if non-empty, is the name of another joint
+	// whose value is multiplied by Ratio to get this
+	// joint's value.
+	Driver string
+	Ratio  float64
+
+	// Lower and Upper are the limits of the joint's value.
+	// They are only used for exporting, and if they are
+	// equal, the joint is treated as unlimited.
+	Lower float64
+	Upper float64
+}
+
+// An Interference records two parts which overlap at some
+// point in time.
+type Interference struct {
+	Time  float64
+	Part1 string
+	Part2 string
+}
+
+// NewMechanism creates a mechanism for the parts of an
+// assembly, which should already be solved.
+func NewMechanism(a *Assembly) *Mechanism {
+	return &Mechanism{
+		assembly: a,
+		byChild:  map[string]*MechanismJoint{},
+		byName:   map[string]*MechanismJoint{},
+		ignored:  map[[2]string]bool{},
+	}
+}
+
+// Revolute adds a joint which rotates the child part
+// around the z-axis of an anchor, referenced as
+// "part.anchor" in the assembly.
+func (m *Mechanism) Revolute(name, parent, child, axis string) *MechanismJoint {
+	return m.addJoint(name, RevoluteJoint, parent, child, axis)
+}
+
+// Prismatic adds a joint which slides the child part along
+// the z-axis of an anchor, referenced as "part.anchor" in
+// the assembly.
+func (m *Mechanism) Prismatic(name, parent, child, axis string) *MechanismJoint {
+	return m.addJoint(name, PrismaticJoint, parent, child, axis)
+}
+
+// Drive sets a joint's value as a function of time.
+func (m *Mechanism) Drive(joint string, f func(t float64) float64) {
+	j := m.joint(joint)
+	j.Drive = f
+	j.Driver = ""
+}
+
+// Gear couples a joint to a driving joint, so that the
+// joint's value is ratio times the driver's value.
+//
+// For meshing gears, the ratio is negative, since the
+// gears turn in opposite directions.
+func (m *Mechanism) Gear(joint, driver string, ratio float64) {
+	j := m.joint(joint)
+	m.joint(driver)
+	j.Drive = nil
+	j.Driver = driver
+	j.Ratio = ratio
+}
+
+// IgnoreInterference disables interference checks between
+// two parts, e.g. for a shaft which is press-fit into a
+// hole.
+func (m *Mechanism) IgnoreInterference(part1, part2 string) {
+	m.ignored[[2]string{part1, part2}] = true
+	m.ignored[[2]string{part2, part1}] = true
+}
+
+// JointValue computes the value of a joint at a time.
+func (m *Mechanism) JointValue(joint string, t float64) float64 {
+	return m.jointValue(m.joint(joint), t, 0)
+}
+
+// Transforms computes the transformation of every part at
+// a time, including the part's placement in the assembly.
+func (m *Mechanism) Transforms(t float64) map[string]model3d.Transform {
+	res := map[string]model3d.Transform{}
+	for _, name := range m.assembly.names {
+		res[name] = model3d.JoinedTransform{
+			m.assembly.Transform(name),
+			m.motion(name, t, 0),
+		}
+	}
+	return res
+}
+
+// Solid creates a solid of the entire mechanism at a time.
+func (m *Mechanism) Solid(t float64) model3d.Solid {
+	var res model3d.JoinedSolid
+	xfs := m.Transforms(t)
+	for _, name := range m.sortedNames() {
+		res = append(res, model3d.TransformSolid(xfs[name], m.assembly.part(name).Part))
+	}
+	return res
+}
+
+// Mesh creates a mesh of the entire mechanism at a time.
+//
+// Each part is meshed once with marching cubes using the
+// given delta, and the result is cached for future calls.
+func (m *Mechanism) Mesh(t, delta float64) *model3d.Mesh {
+	res := model3d.NewMesh()
+	xfs := m.Transforms(t)
+	for _, name := range m.sortedNames() {
+		res.AddMesh(m.partMesh(name, delta).Transform(xfs[name]))
+	}
+	return res
+}
+
+// Interferences checks for overlapping parts at each of
+// the given times.
+//
+// Parts are meshed with marching cubes using the given
+// delta, and two parts interfere if their meshes intersect
+// or if one part is entirely inside the other.
+func (m *Mechanism) Interferences(times []float64, delta float64) []Interference {
+	var res []Interference
+	names := m.assembly.names
+	for _, t := range times {
+		xfs := m.Transforms(t)
+		colliders := make([]model3d.MultiCollider, len(names))
+		meshes := make([]*model3d.Mesh, len(names))
+		for i, name := range names {
+			meshes[i] = m.partMesh(name, delta).Transform(xfs[name])
+			colliders[i] = model3d.MeshToCollider(meshes[i])
+		}
+		for i, name1 := range names {
+			for j := i + 1; j < len(names); j++ {
+				name2 := names[j]
+				if m.ignored[[2]string{name1, name2}] {
+					continue
+				}
+				if meshesInterfere(meshes[i], colliders[i], meshes[j], colliders[j]) {
+					res = append(res, Interference{Time: t, Part1: name1, Part2: name2})
+				}
+			}
+		}
+	}
+	return res
+}
+
+// PartMesh creates a mesh of a single part at a time.
+//
+// This can be used to highlight interfering parts when
+// rendering frames.
+func (m *Mechanism) PartMesh(part string, t, delta float64) *model3d.Mesh {
+	return m.partMesh(part, delta).Transform(m.Transforms(t)[part])
+}
+
+// RenderFrames renders the mechanism at evenly spaced
+// times from 0 to duration, inclusive.
+//
+// For each frame, render is called with a mesh of the
+// entire mechanism and the interferences at that time, so
+// that it can highlight overlapping parts, e.g. by
+// rendering PartMesh() of each part in a different color
+// with render3d.
+//
+// Parts are meshed with marching cubes using the given
+// delta, as in Interferences().
+func (m *Mechanism) RenderFrames(numFrames int, duration, delta float64,
+	render func(frame int, mesh *model3d.Mesh, hits []Interference) image.Image) []image.Image {
+	res := make([]image.Image, numFrames)
+	for i := range res {
+		t := duration * float64(i) / math.Max(1, float64(numFrames-1))
+		hits := m.Interferences([]float64{t}, delta)
+		res[i] = render(i, m.Mesh(t, delta), hits)
+	}
+	return res
+}
+
+// SaveFrames renders frames like RenderFrames and saves
+// them as numbered PNG files.
+//
+// The pathFormat is a format string for the frame index,
+// such as "frame_%03d.png".
+func (m *Mechanism) SaveFrames(pathFormat string, numFrames int, duration, delta float64,
+	render func(frame int, mesh *model3d.Mesh, hits []Interference) image.Image) error {
+	for i, frame := range m.RenderFrames(numFrames, duration, delta, render) {
+		if err := savePNG(fmt.Sprintf(pathFormat, i), frame); err != nil {
+			return errors.Wrap(err, "save mechanism frames")
+		}
+	}
+	return nil
+}
+
+func (m *Mechanism) addJoint(name string, kind JointKind, parent, child,
+	axis string) *MechanismJoint {
+	if _, ok := m.byName[name]; ok {
+		panic("duplicate joint: " + name)
+	}
+	if _, ok := m.byChild[child]; ok {
+		panic("part already has a parent joint: " + child)
+	}
+	m.assembly.part(parent)
+	m.assembly.part(child)
+	j := &MechanismJoint{
+		Name:   name,
+		Kind:   kind,
+		Parent: parent,
+		Child:  child,
+		Axis:   m.assembly.anchor(m.assembly.anchorRef(axis)),
+	}
+	m.joints = append(m.joints, j)
+	m.byName[name] = j
+	m.byChild[child] = j
+	return j
+}
+
+func (m *Mechanism) joint(name string) *MechanismJoint {
+	j, ok := m.byName[name]
+	if !ok {
+		panic("unknown joint: " + name)
+	}
+	return j
+}
+
+func (m *Mechanism) jointValue(j *MechanismJoint, t float64, depth int) float64 {
+	if depth > len(m.joints) {
+		panic("cyclic joint coupling")
+	}
+	if j.Driver != "" {
+		return j.Ratio * m.jointValue(m.joint(j.Driver), t, depth+1)
+	} else if j.Drive != nil {
+		return j.Drive(t)
+	}
+	return 0
+}
+
+// motion computes the transformation of a part relative to
+// its assembled position.
+func (m *Mechanism) motion(part string, t float64, depth int) model3d.Transform {
+	j, ok := m.byChild[part]
+	if !ok {
+		return model3d.JoinedTransform{}
+	}
+	if depth > len(m.joints) {
+		panic("cyclic joint hierarchy")
+	}
+	value := m.jointValue(j, t, 0)
+	var local model3d.Transform
+	if j.Kind == RevoluteJoint {
+		local = model3d.JoinedTransform{
+			&model3d.Translate{Offset: j.Axis.Origin.Scale(-1)},
+			model3d.Rotation(j.Axis.ZAxis(), value),
+			&model3d.Translate{Offset: j.Axis.Origin},
+		}
+	} else {
+		local = &model3d.Translate{Offset: j.Axis.ZAxis().Scale(value)}
+	}
+	return model3d.JoinedTransform{local, m.motion(j.Parent, t, depth+1)}
+}
+
+func (m *Mechanism) sortedNames() []string {
+	names := append([]string{}, m.assembly.names...)
+	sort.Strings(names)
+	return names
+}
+
+func (m *Mechanism) partMesh(name string, delta float64) *model3d.Mesh {
+	if m.meshes == nil {
+		m.meshes = map[mechanismMeshKey]*model3d.Mesh{}
+	}
+	key := mechanismMeshKey{Part: name, Delta: delta}
+	if mesh, ok := m.meshes[key]; ok {
+		return mesh
+	}
+	mesh := model3d.MarchingCubesSearch(m.assembly.part(name).Part, delta, 8)
+	m.meshes[key] = mesh
+	return mesh
+}
+
+type mechanismMeshKey struct {
+	Part  string
+	Delta float64
+}
+
+// meshesInterfere checks if two closed meshes intersect or
+// if one is entirely inside the other.
+func meshesInterfere(m1 *model3d.Mesh, c1 model3d.MultiCollider, m2 *model3d.Mesh,
+	c2 model3d.MultiCollider) bool {
+	if meshesIntersect(m1, c2) {
+		return true
+	}
+	// Without any intersections, each mesh is either
+	// entirely inside or entirely outside of the other, so
+	// a single vertex of each suffices.
+	return meshVertexInside(m1, c2) || meshVertexInside(m2, c1)
+}
+
+func meshVertexInside(m *model3d.Mesh, c model3d.Collider) bool {
+	var inside, checked bool
+	m.Iterate(func(t *model3d.Triangle) {
+		if !checked {
+			checked = true
+			inside = model3d.ColliderContains(c, t[0], 0)
+		}
+	})
+	return inside
+}
+
+func meshesIntersect(m *model3d.Mesh, c model3d.MultiCollider) bool {
+	intersects := false
+	m.Iterate(func(t *model3d.Triangle) {
+		if !intersects && len(c.TriangleCollisions(t)) > 0 {
+			intersects = true
+		}
+	})
+	return intersects
+}
+
+func savePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
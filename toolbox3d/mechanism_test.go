@@ -0,0 +1,162 @@
+package toolbox3d
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestMechanismGears(t *testing.T) {
+	base := NewAnchoredSolid(&model3d.Rect{
+		MinVal: model3d.XYZ(-1, -1, -0.2),
+		MaxVal: model3d.XYZ(4, 1, 0),
+	})
+	base.AddAnchor("axle1", NewAnchor(model3d.Coord3D{}, model3d.Z(1), model3d.X(1)))
+	base.AddAnchor("axle2", NewAnchor(model3d.X(3), model3d.Z(1), model3d.X(1)))
+
+	// Simple "gears" are arms sticking out from axles.
+	arm := func(length float64) *AnchoredSolid {
+		res := NewAnchoredSolid(&model3d.Rect{
+			MinVal: model3d.XYZ(-0.1, -0.1, 0.1),
+			MaxVal: model3d.XYZ(length, 0.1, 0.3),
+		})
+		res.AddAnchor("hub", NewAnchor(model3d.Coord3D{}, model3d.Z(1), model3d.X(1)))
+		return res
+	}
+
+	a := NewAssembly()
+	a.AddPart("base", base, true)
+	a.AddPart("arm1", arm(1), false)
+	a.AddPart("arm2", arm(0.5), false)
+	a.Align("arm1.hub", "base.axle1")
+	a.Align("arm2.hub", "base.axle2")
+	if err := a.Solve(); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMechanism(a)
+	m.Revolute("j1", "base", "arm1", "base.axle1")
+	m.Revolute("j2", "base", "arm2", "base.axle2")
+	m.Drive("j1", func(t float64) float64 { return t * math.Pi / 2 })
+	m.Gear("j2", "j1", -2)
+
+	if v := m.JointValue("j2", 1); math.Abs(v+math.Pi) > 1e-8 {
+		t.Errorf("unexpected coupled value: %f", v)
+	}
+	xfs := m.Transforms(1)
+	tip1 := xfs["arm1"].Apply(model3d.X(1))
+	if tip1.Dist(model3d.Y(1)) > 1e-5 {
+		t.Errorf("unexpected arm1 tip: %v", tip1)
+	}
+	tip2 := xfs["arm2"].Apply(model3d.X(0.5))
+	if tip2.Dist(model3d.X(2.5)) > 1e-5 {
+		t.Errorf("unexpected arm2 tip: %v", tip2)
+	}
+
+	// The arms are too short to reach each other.
+	m.IgnoreInterference("base", "arm1")
+	m.IgnoreInterference("base", "arm2")
+	if res := m.Interferences([]float64{0, 1}, 0.05); len(res) != 0 {
+		t.Errorf("unexpected interferences: %v", res)
+	}
+}
+
+func TestMechanismPrismaticInterference(t *testing.T) {
+	wall := NewAnchoredSolid(&model3d.Rect{
+		MinVal: model3d.XYZ(2, -1, -1),
+		MaxVal: model3d.XYZ(2.2, 1, 1),
+	})
+	wall.AddAnchor("rail", NewAnchor(model3d.Coord3D{}, model3d.X(1), model3d.Y(1)))
+	slider := NewAnchoredSolid(&model3d.Rect{
+		MinVal: model3d.XYZ(-0.3, -0.3, -0.3),
+		MaxVal: model3d.XYZ(0.3, 0.3, 0.3),
+	})
+
+	a := NewAssembly()
+	a.AddPart("wall", wall, true)
+	a.AddPart("slider", slider, false)
+
+	m := NewMechanism(a)
+	m.Prismatic("slide", "wall", "slider", "wall.rail")
+	m.Drive("slide", func(t float64) float64 { return t })
+	res := m.Interferences([]float64{0, 1, 2}, 0.05)
+	if len(res) != 1 || res[0].Time != 2 {
+		t.Errorf("unexpected interferences: %v", res)
+	}
+}
+
+func TestMechanismContainedInterference(t *testing.T) {
+	outer := NewAnchoredSolid(&model3d.Rect{
+		MinVal: model3d.XYZ(-1, -1, -1),
+		MaxVal: model3d.XYZ(1, 1, 1),
+	})
+	outer.AddAnchor("rail", NewAnchor(model3d.Coord3D{}, model3d.X(1), model3d.Y(1)))
+	inner := NewAnchoredSolid(&model3d.Rect{
+		MinVal: model3d.XYZ(-0.3, -0.3, -0.3),
+		MaxVal: model3d.XYZ(0.3, 0.3, 0.3),
+	})
+
+	a := NewAssembly()
+	a.AddPart("outer", outer, true)
+	a.AddPart("inner", inner, false)
+
+	m := NewMechanism(a)
+	m.Prismatic("slide", "outer", "inner", "outer.rail")
+	m.Drive("slide", func(t float64) float64 { return t })
+	res := m.Interferences([]float64{0, 1, 2}, 0.05)
+	if len(res) != 2 || res[0].Time != 0 || res[1].Time != 1 {
+		t.Errorf("unexpected interferences: %v", res)
+	}
+}
+
+func TestMechanismRenderFrames(t *testing.T) {
+	base := NewAnchoredSolid(&model3d.Rect{MaxVal: model3d.XYZ(1, 1, 1)})
+	base.AddAnchor("rail", NewAnchor(model3d.Coord3D{}, model3d.X(1), model3d.Y(1)))
+	a := NewAssembly()
+	a.AddPart("base", base, true)
+	// The slider partly overlaps the base at first, since
+	// exactly coincident surfaces are ambiguous.
+	a.AddPart("slider", NewAnchoredSolid(&model3d.Rect{
+		MinVal: model3d.XYZ(0.5, 0.25, 0.25),
+		MaxVal: model3d.XYZ(1.5, 0.75, 0.75),
+	}), false)
+
+	m := NewMechanism(a)
+	m.Prismatic("slide", "base", "slider", "base.rail")
+	m.Drive("slide", func(t float64) float64 { return t })
+
+	var numHits []int
+	frames := m.RenderFrames(3, 4, 0.1, func(frame int, mesh *model3d.Mesh,
+		hits []Interference) image.Image {
+		if frame != len(numHits) {
+			t.Fatal("unexpected frame index")
+		}
+		numHits = append(numHits, len(hits))
+		expectedMax := float64(frame)*2 + 1.5
+		if math.Abs(mesh.Max().X-expectedMax) > 1e-3 {
+			t.Errorf("frame %d: expected max x %f but got %f", frame, expectedMax,
+				mesh.Max().X)
+		}
+		if frame == 0 {
+			slider := m.PartMesh("slider", 0, 0.1)
+			if math.Abs(slider.Max().X-1.5) > 1e-3 {
+				t.Errorf("unexpected slider max: %v", slider.Max())
+			}
+		}
+		return image.NewGray(image.Rect(0, 0, frame+1, 1))
+	})
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames but got %d", len(frames))
+	}
+	for i, frame := range frames {
+		if frame.Bounds().Dx() != i+1 {
+			t.Errorf("frame %d is out of order", i)
+		}
+	}
+	// The parts only overlap in the first frame.
+	if len(numHits) != 3 || numHits[0] != 1 || numHits[1] != 0 || numHits[2] != 0 {
+		t.Errorf("unexpected interference counts: %v", numHits)
+	}
+}
@@ -0,0 +1,113 @@
+package toolbox3d
+
+import (
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// MirrorPart creates a mirror image of a mesh by negating
+// the x-axis, e.g. to turn a left bracket into a right
+// bracket.
+//
+// Unlike simply scaling the mesh by -1, the triangles are
+// re-oriented so that normals still point outward.
+//
+// Any threads in the mesh will become left-handed.
+// To avoid this, see MirrorPartThreads.
+func MirrorPart(m *model3d.Mesh) *model3d.Mesh {
+	res := model3d.NewMesh()
+	m.Iterate(func(t *model3d.Triangle) {
+		res.Add(&model3d.Triangle{
+			mirrorCoord(t[0]),
+			mirrorCoord(t[2]),
+			mirrorCoord(t[1]),
+		})
+	})
+	return res
+}
+
+// A MirrorThread describes a threaded region of a part
+// which should keep its handedness when the part is
+// mirrored by MirrorPartThreads.
+type MirrorThread struct {
+	// Screw is the thread in the original part's
+	// coordinates.
+	//
+	// For screw holes, this should be the solid which was
+	// subtracted to create the hole, including clearance.
+	Screw *ScrewSolid
+
+	// Hole is true if the thread is a screw hole rather
+	// than an external screw.
+	Hole bool
+
+	// Tolerance is how far outside of a screw hole to look
+	// for the surrounding part's material when filling in
+	// the mirrored hole before it is re-cut.
+	// It should exceed any error in the mesh's surface near
+	// the hole, but be smaller than the hole's walls.
+	//
+	// If 0, the delta passed to MirrorPartThreads is used.
+	Tolerance float64
+}
+
+// MirrorPartThreads is like MirrorPart, but it replaces
+// the mirrored threads with right-handed ones.
+//
+// Within the bounding cylinder of every thread, the
+// mirrored geometry is discarded and the thread is
+// re-generated at the mirrored location.
+// The resulting solid is converted back into a mesh with
+// marching cubes, using the given delta.
+func MirrorPartThreads(m *model3d.Mesh, threads []*MirrorThread, delta float64) *model3d.Mesh {
+	mirrored := MirrorPart(m)
+	var result model3d.Solid = model3d.NewColliderSolid(model3d.MeshToCollider(mirrored))
+	for _, t := range threads {
+		screw := *t.Screw
+		screw.P1 = mirrorCoord(screw.P1)
+		screw.P2 = mirrorCoord(screw.P2)
+		if t.Hole {
+			tolerance := t.Tolerance
+			if tolerance == 0 {
+				tolerance = delta
+			}
+			result = &model3d.SubtractedSolid{
+				Positive: model3d.JoinedSolid{result, mirrorHoleFill(result, &screw, tolerance)},
+				Negative: &screw,
+			}
+		} else {
+			result = model3d.JoinedSolid{
+				&model3d.SubtractedSolid{
+					Positive: result,
+					Negative: screw.boundingCylinder(),
+				},
+				&screw,
+			}
+		}
+	}
+	return model3d.MarchingCubesSearch(result, delta, 8)
+}
+
+// mirrorHoleFill creates a solid which fills in the
+// bounding cylinder of a screw hole wherever the
+// surrounding part has material at the given distance
+// outside the hole.
+func mirrorHoleFill(part model3d.Solid, screw *ScrewSolid, tolerance float64) model3d.Solid {
+	bounds := screw.boundingCylinder()
+	axis := screw.P2.Sub(screw.P1).Normalize()
+	return model3d.CheckedFuncSolid(bounds.Min(), bounds.Max(), func(c model3d.Coord3D) bool {
+		if !bounds.Contains(c) {
+			return false
+		}
+		offset := c.Sub(screw.P1)
+		center := screw.P1.Add(axis.Scale(offset.Dot(axis)))
+		radial := offset.ProjectOut(axis)
+		if radial.Norm() < 1e-8 {
+			radial, _ = axis.OrthoBasis()
+		}
+		return part.Contains(center.Add(radial.Normalize().Scale(screw.Radius + tolerance)))
+	})
+}
+
+func mirrorCoord(c model3d.Coord3D) model3d.Coord3D {
+	return model3d.XYZ(-c.X, c.Y, c.Z)
+}
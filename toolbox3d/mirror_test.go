@@ -0,0 +1,86 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestMirrorPart(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.XYZ(1, 0, 0), model3d.XYZ(2, 1, 3))
+	mirrored := MirrorPart(mesh)
+	if v := mirrored.Volume(); v < 2.999 || v > 3.001 {
+		t.Errorf("unexpected volume (normals may be flipped): %f", v)
+	}
+	if min := mirrored.Min(); min.Dist(model3d.XYZ(-2, 0, 0)) > 1e-8 {
+		t.Errorf("unexpected min: %v", min)
+	}
+}
+
+func TestMirrorPartThreads(t *testing.T) {
+	screw := &ScrewSolid{
+		P1:         model3d.XYZ(2, 0, 0),
+		P2:         model3d.XYZ(2, 0, 2),
+		Radius:     0.5,
+		GrooveSize: 0.1,
+	}
+	block := model3d.NewMeshRect(model3d.XYZ(1, -1, -0.5), model3d.XYZ(3, 1, 0))
+	part := model3d.MarchingCubesSearch(model3d.JoinedSolid{
+		model3d.NewColliderSolid(model3d.MeshToCollider(block)),
+		screw,
+	}, 0.03, 8)
+
+	mirrored := MirrorPartThreads(part, []*MirrorThread{{Screw: screw}}, 0.03)
+	expected := *screw
+	expected.P1 = model3d.XYZ(-2, 0, 0)
+	expected.P2 = model3d.XYZ(-2, 0, 2)
+
+	// Compare the grooves of the mirrored thread to a
+	// right-handed screw at the mirrored location.
+	collider := model3d.MeshToCollider(mirrored)
+	var mismatches, total int
+	for z := 0.3; z < 1.7; z += 0.05 {
+		for x := -2.5; x < -2.4; x += 0.01 {
+			c := model3d.XYZ(x, 0.01, z)
+			if (collider.RayCollisions(&model3d.Ray{Origin: c, Direction: model3d.X(1)}, nil)%2 == 1) !=
+				expected.Contains(c) {
+				mismatches++
+			}
+			total++
+		}
+	}
+	if float64(mismatches) > 0.15*float64(total) {
+		t.Errorf("too many mismatches: %d/%d", mismatches, total)
+	}
+}
+
+func TestMirrorPartThreadsHoleTolerance(t *testing.T) {
+	hole := &ScrewSolid{
+		P1:         model3d.XYZ(2, 0, -0.1),
+		P2:         model3d.XYZ(2, 0, 1.1),
+		Radius:     0.5,
+		GrooveSize: 0.1,
+	}
+	part := model3d.MarchingCubesSearch(&model3d.SubtractedSolid{
+		Positive: &model3d.Rect{MinVal: model3d.XYZ(1, -1, 0), MaxVal: model3d.XYZ(3, 1, 1)},
+		Negative: hole,
+	}, 0.03, 8)
+
+	for _, tolerance := range []float64{0, 0.1} {
+		mirrored := MirrorPartThreads(part, []*MirrorThread{
+			{Screw: hole, Hole: true, Tolerance: tolerance},
+		}, 0.03)
+		solid := model3d.NewColliderSolid(model3d.MeshToCollider(mirrored))
+		if solid.Contains(model3d.XYZ(-2, 0, 0.5)) {
+			t.Errorf("tolerance %f: expected hole to be empty", tolerance)
+		}
+		if !solid.Contains(model3d.XYZ(-2, 0.7, 0.5)) {
+			t.Errorf("tolerance %f: expected walls around the hole", tolerance)
+		}
+		if v := mirrored.Volume(); math.Abs(v-part.Volume()) > 0.05 {
+			t.Errorf("tolerance %f: expected volume %f but got %f", tolerance,
+				part.Volume(), v)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package toolbox3d
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image"
+	"image/png"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const (
+	DefaultParamServerResolution = 64
+
+	paramServerSearchIters = 8
+)
+
+// A Param is a named, adjustable model parameter.
+type Param struct {
+	Name    string
+	Min     float64
+	Max     float64
+	Default float64
+
+	// Step is the granularity of the parameter's slider.
+	//
+	// If 0, the range is divided into 100 steps.
+	Step float64
+}
+
+// ParamValues maps parameter names to their values.
+type ParamValues map[string]float64
+
+// A ParamServer serves a web page for interactively tuning
+// the parameters of a model.
+//
+// The page shows a slider for each parameter, and shows a
+// preview of a coarse mesh of the model every time a
+// slider moves.
+// The current mesh can also be downloaded as an STL file.
+//
+// A ParamServer is an http.Handler, so it can be served
+// with ListenAndServe or mounted on an existing server.
+type ParamServer struct {
+	Params []*Param
+
+	// Build creates the model for the given parameters.
+	Build func(p ParamValues) model3d.Solid
+
+	// Delta is the grid spacing used to mesh the model.
+	//
+	// If 0, the largest dimension of the model is divided
+	// by DefaultParamServerResolution.
+	Delta float64
+
+	// Render creates a preview image of a mesh of the
+	// model, e.g. using render3d.RenderView.
+	//
+	// If nil, no preview is shown.
+	Render func(mesh *model3d.Mesh) image.Image
+}
+
+// ListenAndServe serves the web UI at the given address,
+// such as "localhost:8080".
+func (p *ParamServer) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, p)
+}
+
+// ServeHTTP serves the web UI and the resources it uses.
+func (p *ParamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		p.serveIndex(w)
+	case "/render.png", "/mesh.stl":
+		if r.URL.Path == "/render.png" && p.Render == nil {
+			http.NotFound(w, r)
+			return
+		}
+		values, err := p.parseValues(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mesh := p.mesh(values)
+
+		// Encode the entire response before writing it, so
+		// that errors can still be reported.
+		var buf bytes.Buffer
+		if r.URL.Path == "/mesh.stl" {
+			err = model3d.WriteSTL(&buf, mesh.TriangleSlice())
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", "attachment; filename=\"mesh.stl\"")
+		} else {
+			err = png.Encode(&buf, p.Render(mesh))
+			w.Header().Set("Content-Type", "image/png")
+		}
+		if err != nil {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(buf.Bytes())
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// DefaultValues gets the default value of every parameter.
+func (p *ParamServer) DefaultValues() ParamValues {
+	res := ParamValues{}
+	for _, param := range p.Params {
+		res[param.Name] = param.Default
+	}
+	return res
+}
+
+func (p *ParamServer) parseValues(r *http.Request) (ParamValues, error) {
+	values := p.DefaultValues()
+	query := r.URL.Query()
+	for name := range query {
+		if _, ok := values[name]; !ok {
+			return nil, fmt.Errorf("unknown parameter: %s", name)
+		}
+	}
+	for _, param := range p.Params {
+		if s := query.Get(param.Name); s != "" {
+			x, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, errors.Wrap(err, "parse parameter "+param.Name)
+			}
+			values[param.Name] = math.Max(param.Min, math.Min(param.Max, x))
+		}
+	}
+	return values, nil
+}
+
+func (p *ParamServer) mesh(values ParamValues) *model3d.Mesh {
+	solid := p.Build(values)
+	delta := p.Delta
+	if delta == 0 {
+		size := solid.Max().Sub(solid.Min())
+		delta = math.Max(size.X, math.Max(size.Y, size.Z)) / DefaultParamServerResolution
+	}
+	return model3d.MarchingCubesSearch(solid, delta, paramServerSearchIters)
+}
+
+func (p *ParamServer) serveIndex(w http.ResponseWriter) {
+	type sliderParam struct {
+		*Param
+		Step float64
+	}
+	var data struct {
+		Params  []sliderParam
+		Preview bool
+	}
+	data.Preview = p.Render != nil
+	for _, param := range p.Params {
+		step := param.Step
+		if step == 0 {
+			step = (param.Max - param.Min) / 100
+		}
+		data.Params = append(data.Params, sliderParam{Param: param, Step: step})
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	paramServerTemplate.Execute(w, data)
+}
+
+var paramServerTemplate = template.Must(template.New("index").Parse(`<!doctype html>
+<html>
+<head>
+<title>Parameters</title>
+<style>
+body { font-family: sans-serif; display: flex; }
+#controls { width: 320px; margin-right: 20px; }
+.param { margin-bottom: 10px; }
+.param input { width: 100%; }
+</style>
+</head>
+<body>
+<div id="controls">
+{{range .Params}}
+<div class="param">
+<label>{{.Name}}: <span class="value">{{.Default}}</span></label>
+<input type="range" name="{{.Name}}" min="{{.Min}}" max="{{.Max}}" step="{{.Step}}" value="{{.Default}}">
+</div>
+{{end}}
+<a id="download" href="mesh.stl">Download STL</a>
+</div>
+{{if .Preview}}<img id="preview" src="render.png">{{end}}
+<script>
+var inputs = document.querySelectorAll('input[type=range]');
+var preview = document.getElementById('preview');
+var download = document.getElementById('download');
+var busy = false;
+var pending = false;
+
+function query() {
+  var parts = [];
+  inputs.forEach(function(input) {
+    parts.push(encodeURIComponent(input.name) + '=' + encodeURIComponent(input.value));
+  });
+  return parts.join('&');
+}
+
+function update() {
+  if (!preview) {
+    download.href = 'mesh.stl?' + query();
+    return;
+  }
+  if (busy) {
+    pending = true;
+    return;
+  }
+  busy = true;
+  var q = query();
+  preview.src = 'render.png?' + q;
+  download.href = 'mesh.stl?' + q;
+}
+
+function finished() {
+  busy = false;
+  if (pending) {
+    pending = false;
+    update();
+  }
+}
+
+if (preview) {
+  preview.onload = finished;
+  preview.onerror = finished;
+}
+inputs.forEach(function(input) {
+  input.addEventListener('input', function() {
+    input.parentNode.querySelector('.value').textContent = input.value;
+    update();
+  });
+});
+</script>
+</body>
+</html>
+`))
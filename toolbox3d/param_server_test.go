@@ -0,0 +1,87 @@
+package toolbox3d
+
+import (
+	"image"
+	"image/png"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestParamServer(t *testing.T) {
+	server := &ParamServer{
+		Params: []*Param{
+			{Name: "radius", Min: 0.5, Max: 2, Default: 1},
+			{Name: "height", Min: 1, Max: 3, Default: 2, Step: 0.5},
+		},
+		Build: func(p ParamValues) model3d.Solid {
+			return &model3d.Cylinder{P2: model3d.Z(p["height"]), Radius: p["radius"]}
+		},
+		Render: func(mesh *model3d.Mesh) image.Image {
+			return image.NewGray(image.Rect(0, 0, 64, 64))
+		},
+	}
+	request := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	index := request("/")
+	if index.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", index.Code)
+	}
+	for _, s := range []string{`name="radius"`, `name="height"`, `step="0.5"`} {
+		if !strings.Contains(index.Body.String(), s) {
+			t.Errorf("index is missing %s", s)
+		}
+	}
+
+	rendering := request("/render.png?radius=0.7")
+	if rendering.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rendering.Code)
+	}
+	img, err := png.Decode(rendering.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 64 || img.Bounds().Dy() != 64 {
+		t.Errorf("unexpected image bounds: %v", img.Bounds())
+	}
+
+	// Values are clamped to the range of each parameter.
+	stl := request("/mesh.stl?radius=0.7&height=10")
+	mesh, err := model3d.DecodeSTL(stl.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := mesh.Max().Sub(mesh.Min())
+	if math.Abs(size.X-1.4) > 0.1 || math.Abs(size.Z-3) > 0.1 {
+		t.Errorf("unexpected mesh size: %v", size)
+	}
+
+	for _, path := range []string{"/render.png?radius=x", "/mesh.stl?width=3"} {
+		if code := request(path).Code; code != http.StatusBadRequest {
+			t.Errorf("%s: expected bad request but got status %d", path, code)
+		}
+	}
+	if code := request("/missing").Code; code != http.StatusNotFound {
+		t.Errorf("unexpected status: %d", code)
+	}
+
+	// Without a Render function, there is no preview.
+	server.Render = nil
+	if strings.Contains(request("/").Body.String(), `id="preview"`) {
+		t.Error("unexpected preview without Render")
+	}
+	if code := request("/render.png").Code; code != http.StatusNotFound {
+		t.Errorf("unexpected status: %d", code)
+	}
+	if code := request("/mesh.stl").Code; code != http.StatusOK {
+		t.Errorf("unexpected status: %d", code)
+	}
+}
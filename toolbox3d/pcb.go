@@ -0,0 +1,379 @@
+package toolbox3d
+
+import (
+	"io"
+	"math"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/fileformats"
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A PCBHole is a hole drilled through a circuit board.
+type PCBHole struct {
+	Center   model2d.Coord
+	Diameter float64
+	Plated   bool
+}
+
+// A PCBComponent is a component placed on a circuit
+// board.
+type PCBComponent struct {
+	Ref     string
+	Value   string
+	Package string
+	Center  model2d.Coord
+
+	// Rotation is the counter-clockwise rotation of the
+	// component in degrees.
+	Rotation float64
+
+	// Bottom is true if the component is on the bottom of
+	// the board.
+	Bottom bool
+}
+
+// A PCBKeepout is a box below a circuit board which a
+// mount must leave empty, e.g. for a component on the
+// bottom of the board.
+type PCBKeepout struct {
+	Center model2d.Coord
+
+	// Size is the size of the box before rotation.
+	Size model2d.Coord
+
+	// Rotation is the counter-clockwise rotation of the
+	// box in degrees.
+	Rotation float64
+
+	// Depth is how far the box extends below the bottom of
+	// the board.
+	Depth float64
+}
+
+// A PCB describes the geometry of a circuit board which
+// is relevant for mounting it in an enclosure.
+//
+// Coordinates are in millimeters, in the coordinate system
+// of the board's fabrication files.
+type PCB struct {
+	// Outline is the edge of the board, or nil if it is
+	// unknown.
+	Outline *model2d.Mesh
+
+	Holes []PCBHole
+
+	// Components are placed components, which are usually
+	// added with ReadPlacements.
+	Components []PCBComponent
+}
+
+// ReadPCB reads a board from a Gerber file for the board
+// outline (e.g. KiCad's Edge.Cuts layer) and any number of
+// Excellon drill files.
+//
+// The outline reader may be nil if there is no outline.
+func ReadPCB(outline io.Reader, drills ...io.Reader) (*PCB, error) {
+	res := &PCB{}
+	if outline != nil {
+		segments, err := fileformats.ReadGerberOutline(outline)
+		if err != nil {
+			return nil, errors.Wrap(err, "read PCB")
+		}
+		res.Outline = model2d.NewMesh()
+		for _, s := range segments {
+			res.Outline.Add(&model2d.Segment{
+				model2d.NewCoordArray(s[0]),
+				model2d.NewCoordArray(s[1]),
+			})
+		}
+		// Segments may be stored in any order and direction,
+		// and their endpoints may not exactly match.
+		res.Outline = res.Outline.Repair(1e-3)
+		res.Outline, _ = res.Outline.RepairNormals(1e-3)
+	}
+	for _, r := range drills {
+		holes, err := fileformats.ReadExcellon(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "read PCB")
+		}
+		for _, h := range holes {
+			res.Holes = append(res.Holes, PCBHole{
+				Center:   model2d.NewCoordArray(h.Center),
+				Diameter: h.Diameter,
+				Plated:   h.Plated,
+			})
+		}
+	}
+	return res, nil
+}
+
+// LoadPCB is like ReadPCB, but reads files from paths.
+//
+// The outline path may be empty if there is no outline.
+func LoadPCB(outlinePath string, drillPaths ...string) (*PCB, error) {
+	var outline io.Reader
+	var drills []io.Reader
+	if outlinePath != "" {
+		f, err := os.Open(outlinePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "load PCB")
+		}
+		defer f.Close()
+		outline = f
+	}
+	for _, path := range drillPaths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "load PCB")
+		}
+		defer f.Close()
+		drills = append(drills, f)
+	}
+	return ReadPCB(outline, drills...)
+}
+
+// ReadPlacements adds components from a placement file,
+// such as KiCad's footprint position file.
+//
+// The positions must use the same origin as the files
+// that the board was read from.
+func (p *PCB) ReadPlacements(r io.Reader) error {
+	placements, err := fileformats.ReadPlacements(r)
+	if err != nil {
+		return errors.Wrap(err, "read PCB placements")
+	}
+	for _, placement := range placements {
+		p.Components = append(p.Components, PCBComponent{
+			Ref:      placement.Ref,
+			Value:    placement.Value,
+			Package:  placement.Package,
+			Center:   model2d.NewCoordArray(placement.Position),
+			Rotation: placement.Rotation,
+			Bottom:   placement.Bottom,
+		})
+	}
+	return nil
+}
+
+// LoadPlacements is like ReadPlacements, but reads a file
+// from a path.
+func (p *PCB) LoadPlacements(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "load PCB placements")
+	}
+	defer f.Close()
+	return p.ReadPlacements(f)
+}
+
+// BottomKeepouts creates keep-out regions for the
+// components on the bottom of the board.
+//
+// The size function gets the footprint size (before
+// rotation) and the height of a component, since these are
+// not included in placement files.
+// Components with a zero size are skipped.
+func (p *PCB) BottomKeepouts(size func(c PCBComponent) (model2d.Coord,
+	float64)) []PCBKeepout {
+	var res []PCBKeepout
+	for _, c := range p.Components {
+		if !c.Bottom {
+			continue
+		}
+		footprint, height := size(c)
+		if footprint == (model2d.Coord{}) {
+			continue
+		}
+		res = append(res, PCBKeepout{
+			Center:   c.Center,
+			Size:     footprint,
+			Rotation: c.Rotation,
+			Depth:    height,
+		})
+	}
+	return res
+}
+
+// MountingHoles finds the holes with a diameter between
+// minDiameter and maxDiameter, inclusive.
+//
+// For example, holes for M3 screws are usually around 3.2
+// millimeters in diameter.
+func (p *PCB) MountingHoles(minDiameter, maxDiameter float64) []PCBHole {
+	var res []PCBHole
+	for _, h := range p.Holes {
+		if h.Diameter >= minDiameter && h.Diameter <= maxDiameter {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+// OutlineSolid creates a 2D solid for the board, expanded
+// outward by a margin.
+func (p *PCB) OutlineSolid(margin float64) model2d.Solid {
+	if p.Outline == nil {
+		panic("PCB has no outline")
+	}
+	return model2d.NewColliderSolidInset(model2d.MeshToCollider(p.Outline), -margin)
+}
+
+// A PCBMount creates standoffs and a tray for mounting a
+// circuit board.
+//
+// The board lies in the XY plane with its bottom at
+// FloorThickness+StandoffHeight, and the bottom of the
+// mount is at z=0.
+type PCBMount struct {
+	PCB *PCB
+
+	// Holes are the mounting holes, which are usually a
+	// subset of the PCB's holes found with MountingHoles.
+	Holes []PCBHole
+
+	// FloorThickness is the thickness of the tray's floor.
+	FloorThickness float64
+
+	// StandoffHeight is the gap between the floor and the
+	// bottom of the board.
+	StandoffHeight float64
+
+	// StandoffRadius is the outer radius of the standoffs.
+	StandoffRadius float64
+
+	// ScrewRadius is the radius of the pilot hole in each
+	// standoff.
+	// If 0, the standoffs are solid.
+	ScrewRadius float64
+
+	// BoardThickness is the thickness of the board, which
+	// is used to find the top of the board.
+	BoardThickness float64
+
+	// Clearance is the gap between the edge of the board
+	// and the walls of the tray.
+	Clearance float64
+
+	// WallThickness and WallHeight determine the walls of
+	// the tray around the board, where the height is
+	// measured from the top of the floor.
+	// If WallHeight is 0, the tray has no walls.
+	WallThickness float64
+	WallHeight    float64
+
+	// Keepouts are regions below the board, such as those
+	// found with PCB.BottomKeepouts, which are cut out of
+	// the standoffs and floor.
+	// They are expanded by Clearance.
+	Keepouts []PCBKeepout
+}
+
+// BoardZ gets the z coordinate of the bottom of the board.
+func (p *PCBMount) BoardZ() float64 {
+	return p.FloorThickness + p.StandoffHeight
+}
+
+// BoardTopZ gets the z coordinate of the top of the board.
+func (p *PCBMount) BoardTopZ() float64 {
+	return p.BoardZ() + p.BoardThickness
+}
+
+// Standoffs creates the standoffs for the mounting holes,
+// extending from z=0 to the bottom of the board.
+//
+// There must be at least one mounting hole.
+func (p *PCBMount) Standoffs() model3d.Solid {
+	if len(p.Holes) == 0 {
+		panic("PCB mount has no holes")
+	}
+	var res model3d.JoinedSolid
+	for _, h := range p.Holes {
+		res = append(res, &model3d.Cylinder{
+			P1:     model3d.XYZ(h.Center.X, h.Center.Y, 0),
+			P2:     model3d.XYZ(h.Center.X, h.Center.Y, p.BoardZ()),
+			Radius: p.StandoffRadius,
+		})
+	}
+	return res.Optimize()
+}
+
+// ScrewHoles creates the pilot holes for the standoffs,
+// which stop at the top of the floor.
+//
+// There must be at least one mounting hole.
+func (p *PCBMount) ScrewHoles() model3d.Solid {
+	if len(p.Holes) == 0 {
+		panic("PCB mount has no holes")
+	}
+	var res model3d.JoinedSolid
+	for _, h := range p.Holes {
+		res = append(res, &model3d.Cylinder{
+			P1:     model3d.XYZ(h.Center.X, h.Center.Y, p.FloorThickness),
+			P2:     model3d.XYZ(h.Center.X, h.Center.Y, p.BoardZ()+p.ScrewRadius),
+			Radius: p.ScrewRadius,
+		})
+	}
+	return res.Optimize()
+}
+
+// Pocket creates a region around the board, from the top
+// of the floor to maxZ, which can be subtracted from an
+// enclosure to make room for the board.
+//
+// For example, maxZ might be BoardTopZ() plus the height
+// of the tallest component.
+func (p *PCBMount) Pocket(maxZ float64) model3d.Solid {
+	return model3d.ProfileSolid(p.PCB.OutlineSolid(p.Clearance), p.FloorThickness, maxZ)
+}
+
+// KeepoutSolid creates the keep-out regions below the
+// board, or nil if there are no keep-outs.
+func (p *PCBMount) KeepoutSolid() model3d.Solid {
+	if len(p.Keepouts) == 0 {
+		return nil
+	}
+	var res model3d.JoinedSolid
+	for _, k := range p.Keepouts {
+		halfSize := k.Size.Scale(0.5).Add(model2d.Ones(p.Clearance))
+		box := &model3d.Rect{
+			MinVal: model3d.XYZ(-halfSize.X, -halfSize.Y, p.BoardZ()-k.Depth-p.Clearance),
+			MaxVal: model3d.XYZ(halfSize.X, halfSize.Y, p.BoardZ()),
+		}
+		res = append(res, model3d.TransformSolid(model3d.JoinedTransform{
+			model3d.Rotation(model3d.Z(1), k.Rotation*math.Pi/180),
+			&model3d.Translate{Offset: model3d.XYZ(k.Center.X, k.Center.Y, 0)},
+		}, box))
+	}
+	return res.Optimize()
+}
+
+// Solid creates the full mount, consisting of a tray
+// shaped like the board, the standoffs, and the screw
+// holes, with any keep-outs cut out.
+func (p *PCBMount) Solid() model3d.Solid {
+	outer := p.PCB.OutlineSolid(p.Clearance + p.WallThickness)
+	top := p.FloorThickness + p.WallHeight
+	positive := model3d.JoinedSolid{
+		model3d.ProfileSolid(outer, 0, top),
+		p.Standoffs(),
+	}
+	negative := model3d.JoinedSolid{
+		&model3d.SubtractedSolid{
+			Positive: p.Pocket(top + p.FloorThickness),
+			Negative: p.Standoffs(),
+		},
+	}
+	if p.ScrewRadius != 0 {
+		negative = append(negative, p.ScrewHoles())
+	}
+	if keepouts := p.KeepoutSolid(); keepouts != nil {
+		negative = append(negative, keepouts)
+	}
+	return &model3d.SubtractedSolid{
+		Positive: positive.Optimize(),
+		Negative: negative.Optimize(),
+	}
+}
@@ -0,0 +1,158 @@
+package toolbox3d
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const pcbTestOutline = `%FSLAX46Y46*%
+%MOMM*%
+%ADD10C,0.100000*%
+D10*
+X0Y0D02*
+X30000000Y0D01*
+X30000000Y20000000D01*
+X0Y20000000D01*
+X0Y0D01*
+M02*
+`
+
+const pcbTestDrill = `M48
+METRIC
+T1C0.800
+T2C3.200
+%
+G90
+G05
+T1
+X15.0Y10.0
+T2
+X3.0Y3.0
+X27.0Y3.0
+X27.0Y17.0
+X3.0Y17.0
+M30
+`
+
+func TestPCBMount(t *testing.T) {
+	pcb, err := ReadPCB(strings.NewReader(pcbTestOutline), strings.NewReader(pcbTestDrill))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pcb.Holes) != 5 {
+		t.Fatalf("expected 5 holes but got %d", len(pcb.Holes))
+	}
+	holes := pcb.MountingHoles(3, 3.5)
+	if len(holes) != 4 {
+		t.Fatalf("expected 4 mounting holes but got %d", len(holes))
+	}
+
+	outline := pcb.OutlineSolid(0)
+	if !outline.Contains(model2d.XY(15, 10)) || outline.Contains(model2d.XY(31, 10)) {
+		t.Error("unexpected outline")
+	}
+
+	mount := &PCBMount{
+		PCB:            pcb,
+		Holes:          holes,
+		FloorThickness: 2,
+		StandoffHeight: 5,
+		StandoffRadius: 3,
+		ScrewRadius:    1.2,
+		BoardThickness: 1.6,
+		Clearance:      0.5,
+		WallThickness:  2,
+		WallHeight:     8,
+	}
+	if z := mount.BoardTopZ(); z != 8.6 {
+		t.Errorf("unexpected board top %f", z)
+	}
+	solid := mount.Solid()
+	testCases := []struct {
+		Point    model3d.Coord3D
+		Expected bool
+	}{
+		// Floor.
+		{model3d.XYZ(15, 10, 1), true},
+		{model3d.XYZ(15, 10, 3), false},
+
+		// Walls.
+		{model3d.XYZ(31.5, 10, 5), true},
+		{model3d.XYZ(30.2, 10, 5), false},
+		{model3d.XYZ(33, 10, 5), false},
+		{model3d.XYZ(31.5, 10, 10.5), false},
+
+		// Standoff with pilot hole.
+		{model3d.XYZ(5, 3, 6), true},
+		{model3d.XYZ(3, 3, 6), false},
+		{model3d.XYZ(3, 3, 1), true},
+		{model3d.XYZ(5, 3, 7.5), false},
+	}
+	for i, tc := range testCases {
+		if actual := solid.Contains(tc.Point); actual != tc.Expected {
+			t.Errorf("case %d (%v): expected %v but got %v", i, tc.Point, tc.Expected, actual)
+		}
+	}
+}
+
+func TestPCBMountKeepouts(t *testing.T) {
+	pcb, err := ReadPCB(strings.NewReader(pcbTestOutline), strings.NewReader(pcbTestDrill))
+	if err != nil {
+		t.Fatal(err)
+	}
+	placements := `Ref,Val,Package,PosX,PosY,Rot,Side
+"C1","100n","C_0603",15.0,10.0,0.0,top
+"J1","Conn","Conn_1x03",5.0,5.0,90.0,bottom
+`
+	if err := pcb.ReadPlacements(strings.NewReader(placements)); err != nil {
+		t.Fatal(err)
+	}
+	if len(pcb.Components) != 2 {
+		t.Fatalf("expected 2 components but got %d", len(pcb.Components))
+	}
+	keepouts := pcb.BottomKeepouts(func(c PCBComponent) (model2d.Coord, float64) {
+		return model2d.XY(6, 2), 6
+	})
+	if len(keepouts) != 1 || keepouts[0].Center != model2d.XY(5, 5) {
+		t.Fatalf("unexpected keepouts: %v", keepouts)
+	}
+
+	mount := &PCBMount{
+		PCB:            pcb,
+		Holes:          pcb.MountingHoles(3, 3.5),
+		FloorThickness: 2,
+		StandoffHeight: 5,
+		StandoffRadius: 3,
+		ScrewRadius:    1.2,
+		BoardThickness: 1.6,
+		Clearance:      0.5,
+		WallThickness:  2,
+		WallHeight:     8,
+		Keepouts:       keepouts,
+	}
+	solid := mount.Solid()
+	testCases := []struct {
+		Point    model3d.Coord3D
+		Expected bool
+	}{
+		// Part of a standoff inside the keep-out.
+		{model3d.XYZ(5, 5, 4), false},
+		{model3d.XYZ(0.5, 3, 4), true},
+
+		// The keep-out is rotated and cuts into the floor.
+		{model3d.XYZ(5, 7.5, 1), false},
+		{model3d.XYZ(5, 7.5, 0.25), true},
+		{model3d.XYZ(8, 5, 1), true},
+
+		// Top components do not affect the mount.
+		{model3d.XYZ(15, 10, 1), true},
+	}
+	for i, tc := range testCases {
+		if actual := solid.Contains(tc.Point); actual != tc.Expected {
+			t.Errorf("case %d (%v): expected %v but got %v", i, tc.Point, tc.Expected, actual)
+		}
+	}
+}
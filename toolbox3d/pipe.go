@@ -0,0 +1,329 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A PipeFittingKind determines the shape of a fitting at
+// the end of a Pipe.
+type PipeFittingKind int
+
+const (
+	// FlangeFitting is a disc around the end of the pipe,
+	// optionally with bolt holes.
+	FlangeFitting PipeFittingKind = iota
+
+	// CouplerFitting is a sleeve that extends past the end
+	// of the pipe, with a socket that fits over the end of
+	// another pipe of the same size.
+	CouplerFitting
+)
+
+// A PipeFitting is a flange or coupler at the end of a
+// Pipe.
+type PipeFitting struct {
+	Kind PipeFittingKind
+
+	// Radius is the outer radius of the fitting.
+	Radius float64
+
+	// Length is the thickness of a flange, or the length
+	// of a coupler's socket.
+	Length float64
+
+	// Clearance is the gap between a coupler's socket and
+	// the pipe inserted into it.
+	Clearance float64
+
+	// NumHoles is the number of bolt holes in a flange.
+	// The holes are centered between the pipe and the edge
+	// of the flange.
+	NumHoles   int
+	HoleRadius float64
+}
+
+// A Pipe is a hollow tube routed through a sequence of
+// waypoints, with rounded bends at each waypoint.
+type Pipe struct {
+	Waypoints []model3d.Coord3D
+
+	// BendRadius is the radius of the bends, measured at the
+	// center of the pipe.
+	// It must be larger than OuterRadius.
+	BendRadius float64
+
+	OuterRadius   float64
+	WallThickness float64
+
+	// StartFitting and EndFitting are optional fittings at
+	// the first and last waypoints.
+	StartFitting *PipeFitting
+	EndFitting   *PipeFitting
+}
+
+// Length computes the length of the pipe's center line,
+// including the bends.
+func (p *Pipe) Length() float64 {
+	var res float64
+	for _, piece := range p.pieces() {
+		res += piece.Length()
+	}
+	return res
+}
+
+// Solid creates a solid for the pipe and its fittings.
+//
+// The ends of the pipe are open.
+func (p *Pipe) Solid() model3d.Solid {
+	pieces := p.pieces()
+	positive := model3d.JoinedSolid{newPipeSweep(pieces, p.OuterRadius, 0)}
+	negative := model3d.JoinedSolid{newPipeSweep(pieces, p.innerRadius(), p.WallThickness)}
+
+	n := len(p.Waypoints)
+	ends := []struct {
+		Fitting *PipeFitting
+		Point   model3d.Coord3D
+		Dir     model3d.Coord3D
+	}{
+		{p.StartFitting, p.Waypoints[0], p.Waypoints[0].Sub(p.Waypoints[1]).Normalize()},
+		{p.EndFitting, p.Waypoints[n-1], p.Waypoints[n-1].Sub(p.Waypoints[n-2]).Normalize()},
+	}
+	for _, end := range ends {
+		pos, neg := p.fittingSolids(end.Fitting, end.Point, end.Dir)
+		positive = append(positive, pos...)
+		negative = append(negative, neg...)
+	}
+	return &model3d.SubtractedSolid{
+		Positive: positive.Optimize(),
+		Negative: negative.Optimize(),
+	}
+}
+
+// Channel creates a solid for the pipe's bore, without
+// any walls or fittings.
+//
+// This can be subtracted from another solid to create a
+// channel for air, wires, or liquids.
+func (p *Pipe) Channel() model3d.Solid {
+	return newPipeSweep(p.pieces(), p.innerRadius(), 0)
+}
+
+func (p *Pipe) innerRadius() float64 {
+	return p.OuterRadius - p.WallThickness
+}
+
+func (p *Pipe) fittingSolids(f *PipeFitting, point, dir model3d.Coord3D) (pos, neg []model3d.Solid) {
+	if f == nil {
+		return nil, nil
+	}
+	switch f.Kind {
+	case FlangeFitting:
+		pos = append(pos, &model3d.Cylinder{
+			P1:     point.Sub(dir.Scale(f.Length)),
+			P2:     point,
+			Radius: f.Radius,
+		})
+		b1, b2 := dir.OrthoBasis()
+		holeDist := (f.Radius + p.OuterRadius) / 2
+		for i := 0; i < f.NumHoles; i++ {
+			theta := 2 * math.Pi * float64(i) / float64(f.NumHoles)
+			center := point.Add(b1.Scale(math.Cos(theta) * holeDist)).Add(
+				b2.Scale(math.Sin(theta) * holeDist),
+			)
+			neg = append(neg, &model3d.Cylinder{
+				P1:     center.Sub(dir.Scale(f.Length + p.WallThickness)),
+				P2:     center.Add(dir.Scale(p.WallThickness)),
+				Radius: f.HoleRadius,
+			})
+		}
+	case CouplerFitting:
+		pos = append(pos, &model3d.Cylinder{
+			P1:     point.Sub(dir.Scale(f.Length)),
+			P2:     point.Add(dir.Scale(f.Length)),
+			Radius: f.Radius,
+		})
+		neg = append(neg, &model3d.Cylinder{
+			P1:     point,
+			P2:     point.Add(dir.Scale(f.Length + p.WallThickness)),
+			Radius: p.OuterRadius + f.Clearance,
+		})
+	default:
+		panic("unknown fitting kind")
+	}
+	return
+}
+
+// pieces splits the center line into straight segments
+// and circular arcs.
+func (p *Pipe) pieces() []pipePiece {
+	if len(p.Waypoints) < 2 {
+		panic("pipe needs at least two waypoints")
+	}
+	var res []pipePiece
+	start := p.Waypoints[0]
+	for i := 1; i < len(p.Waypoints)-1; i++ {
+		prev, cur, next := p.Waypoints[i-1], p.Waypoints[i], p.Waypoints[i+1]
+		d1 := cur.Sub(prev).Normalize()
+		d2 := next.Sub(cur).Normalize()
+		angle := math.Acos(math.Max(-1, math.Min(1, d1.Dot(d2))))
+		if angle < 1e-8 {
+			continue
+		} else if angle > math.Pi-1e-8 {
+			panic("pipe cannot reverse direction")
+		} else if p.BendRadius <= p.OuterRadius {
+			panic("bend radius must be larger than outer radius")
+		}
+		tangent := p.BendRadius * math.Tan(angle/2)
+		if tangent > cur.Dist(prev)/2 || tangent > cur.Dist(next)/2 {
+			panic("bend radius is too large for waypoint spacing")
+		}
+		arcStart := cur.Sub(d1.Scale(tangent))
+		res = append(res, newPipeStraight(start, arcStart))
+		normal := d2.Sub(d1.Scale(d1.Dot(d2))).Normalize()
+		res = append(res, &pipeArc{
+			Center: arcStart.Add(normal.Scale(p.BendRadius)),
+			U:      normal.Scale(-1),
+			V:      d1,
+			Axis:   d1.Cross(normal),
+			Radius: p.BendRadius,
+			Angle:  angle,
+		})
+		start = cur.Add(d2.Scale(tangent))
+	}
+	res = append(res, newPipeStraight(start, p.Waypoints[len(p.Waypoints)-1]))
+	return res
+}
+
+type pipePiece interface {
+	Length() float64
+
+	// Dist computes the distance from a point to the piece,
+	// or returns infinity if the closest point on the
+	// center line is not in the interior of the piece.
+	Dist(c model3d.Coord3D) float64
+
+	// Bounds computes a bounding box for the piece when it
+	// is swept with a radius.
+	Bounds(radius float64) (model3d.Coord3D, model3d.Coord3D)
+}
+
+type pipeStraight struct {
+	P1  model3d.Coord3D
+	P2  model3d.Coord3D
+	Dir model3d.Coord3D
+	Len float64
+}
+
+func newPipeStraight(p1, p2 model3d.Coord3D) *pipeStraight {
+	return &pipeStraight{
+		P1:  p1,
+		P2:  p2,
+		Dir: p2.Sub(p1).Normalize(),
+		Len: p1.Dist(p2),
+	}
+}
+
+func (p *pipeStraight) Length() float64 {
+	return p.Len
+}
+
+func (p *pipeStraight) Dist(c model3d.Coord3D) float64 {
+	offset := c.Sub(p.P1)
+	t := offset.Dot(p.Dir)
+	if t < 0 || t > p.Len {
+		return math.Inf(1)
+	}
+	return offset.Sub(p.Dir.Scale(t)).Norm()
+}
+
+func (p *pipeStraight) Bounds(radius float64) (model3d.Coord3D, model3d.Coord3D) {
+	r := model3d.Ones(radius)
+	return p.P1.Min(p.P2).Sub(r), p.P1.Max(p.P2).Add(r)
+}
+
+// pipeArc is an arc around Center, starting in the U
+// direction and turning Angle radians towards V.
+type pipeArc struct {
+	Center model3d.Coord3D
+	U      model3d.Coord3D
+	V      model3d.Coord3D
+	Axis   model3d.Coord3D
+	Radius float64
+	Angle  float64
+}
+
+func (p *pipeArc) Length() float64 {
+	return p.Radius * p.Angle
+}
+
+func (p *pipeArc) Dist(c model3d.Coord3D) float64 {
+	offset := c.Sub(p.Center)
+	u, v, h := offset.Dot(p.U), offset.Dot(p.V), offset.Dot(p.Axis)
+	theta := math.Atan2(v, u)
+	if theta < 0 || theta > p.Angle {
+		return math.Inf(1)
+	}
+	radial := math.Sqrt(u*u+v*v) - p.Radius
+	return math.Sqrt(radial*radial + h*h)
+}
+
+func (p *pipeArc) Bounds(radius float64) (model3d.Coord3D, model3d.Coord3D) {
+	// The full torus contains the arc.
+	torus := &model3d.Torus{
+		Center:      p.Center,
+		Axis:        p.Axis,
+		OuterRadius: p.Radius,
+		InnerRadius: radius,
+	}
+	return torus.Min(), torus.Max()
+}
+
+// pipeSweep is the solid swept by a sphere along the
+// pieces of a pipe, without caps at the ends.
+type pipeSweep struct {
+	pieces []pipePiece
+	radius float64
+	min    model3d.Coord3D
+	max    model3d.Coord3D
+}
+
+// newPipeSweep creates a sweep, optionally extending the
+// first and last pieces past the ends of the pipe.
+func newPipeSweep(pieces []pipePiece, radius, extend float64) *pipeSweep {
+	if extend != 0 {
+		pieces = append([]pipePiece{}, pieces...)
+		first := pieces[0].(*pipeStraight)
+		pieces[0] = newPipeStraight(first.P1.Sub(first.Dir.Scale(extend)), first.P2)
+		last := pieces[len(pieces)-1].(*pipeStraight)
+		pieces[len(pieces)-1] = newPipeStraight(last.P1, last.P2.Add(last.Dir.Scale(extend)))
+	}
+	min, max := pieces[0].Bounds(radius)
+	for _, piece := range pieces[1:] {
+		min1, max1 := piece.Bounds(radius)
+		min = min.Min(min1)
+		max = max.Max(max1)
+	}
+	return &pipeSweep{pieces: pieces, radius: radius, min: min, max: max}
+}
+
+func (p *pipeSweep) Min() model3d.Coord3D {
+	return p.min
+}
+
+func (p *pipeSweep) Max() model3d.Coord3D {
+	return p.max
+}
+
+func (p *pipeSweep) Contains(c model3d.Coord3D) bool {
+	if !model3d.InBounds(p, c) {
+		return false
+	}
+	for _, piece := range p.pieces {
+		if piece.Dist(c) <= p.radius {
+			return true
+		}
+	}
+	return false
+}
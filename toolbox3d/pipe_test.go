@@ -0,0 +1,107 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestPipeSolid(t *testing.T) {
+	pipe := &Pipe{
+		Waypoints: []model3d.Coord3D{
+			model3d.XYZ(0, 0, 0),
+			model3d.XYZ(5, 0, 0),
+			model3d.XYZ(5, 5, 0),
+		},
+		BendRadius:    1.5,
+		OuterRadius:   0.5,
+		WallThickness: 0.1,
+	}
+	expectedLength := 7 + 1.5*math.Pi/2
+	if l := pipe.Length(); math.Abs(l-expectedLength) > 1e-8 {
+		t.Errorf("expected length %f but got %f", expectedLength, l)
+	}
+
+	solid := pipe.Solid()
+	bendCenter := model3d.XYZ(3.5, 1.5, 0)
+	bendDir := model3d.XYZ(1, -1, 0).Normalize()
+	for _, r := range []float64{0, 0.3, 0.45, 0.55} {
+		straight := model3d.XYZ(2, r, 0)
+		bend := bendCenter.Add(bendDir.Scale(1.5 + r))
+		expected := r > 0.4 && r < 0.5
+		if solid.Contains(straight) != expected {
+			t.Errorf("straight at radius %f: expected %v", r, expected)
+		}
+		if solid.Contains(bend) != expected {
+			t.Errorf("bend at radius %f: expected %v", r, expected)
+		}
+		if pipe.Channel().Contains(bend) != (r < 0.4) {
+			t.Errorf("channel at radius %f: unexpected result", r)
+		}
+	}
+
+	// The ends should be open and not extend past the
+	// waypoints.
+	if solid.Contains(model3d.XYZ(-0.01, 0.45, 0)) {
+		t.Error("pipe should not extend past start")
+	}
+	if !solid.Contains(model3d.XYZ(0.01, 0.45, 0)) {
+		t.Error("pipe should reach start")
+	}
+
+	mesh := model3d.MarchingCubesSearch(solid, 0.03, 8)
+	expectedVolume := math.Pi * (0.5*0.5 - 0.4*0.4) * expectedLength
+	if v := mesh.Volume(); math.Abs(v-expectedVolume) > 0.05*expectedVolume {
+		t.Errorf("expected volume %f but got %f", expectedVolume, v)
+	}
+}
+
+func TestPipeFittings(t *testing.T) {
+	pipe := &Pipe{
+		Waypoints:     []model3d.Coord3D{model3d.XYZ(0, 0, 0), model3d.XYZ(0, 0, 4)},
+		OuterRadius:   0.5,
+		WallThickness: 0.1,
+		StartFitting: &PipeFitting{
+			Kind:       FlangeFitting,
+			Radius:     1.5,
+			Length:     0.2,
+			NumHoles:   4,
+			HoleRadius: 0.1,
+		},
+		EndFitting: &PipeFitting{
+			Kind:      CouplerFitting,
+			Radius:    0.7,
+			Length:    0.5,
+			Clearance: 0.05,
+		},
+	}
+	solid := pipe.Solid()
+
+	if !solid.Contains(model3d.XYZ(1.3, 0, 0.1)) {
+		t.Error("missing flange")
+	}
+	if solid.Contains(model3d.XYZ(1, 0, 0.1)) {
+		t.Error("missing bolt hole")
+	}
+	if !solid.Contains(model3d.XYZ(0.7, 0.7, 0.1)) {
+		t.Error("flange between bolt holes should be solid")
+	}
+	if solid.Contains(model3d.XYZ(0, 0, 0.1)) {
+		t.Error("flange should not cover bore")
+	}
+
+	// The coupler's socket fits the outside of another pipe.
+	if solid.Contains(model3d.XYZ(0.52, 0, 4.2)) {
+		t.Error("socket should have clearance")
+	}
+	if !solid.Contains(model3d.XYZ(0.6, 0, 4.2)) {
+		t.Error("missing socket wall")
+	}
+	if !solid.Contains(model3d.XYZ(0.6, 0, 3.8)) {
+		t.Error("missing coupler sleeve")
+	}
+	if solid.Contains(model3d.XYZ(0.6, 0, 4.6)) {
+		t.Error("coupler is too long")
+	}
+}
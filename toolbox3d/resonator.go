@@ -0,0 +1,205 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// DefaultSpeedOfSound is the speed of sound in air at room
+// temperature, in millimeters per second.
+const DefaultSpeedOfSound = 343000.0
+
+// helmholtzEndCorrection is multiplied by the radius of a
+// neck to account for the air that moves with it on both
+// ends, assuming that both ends are flanged.
+const helmholtzEndCorrection = 1.7
+
+// HelmholtzFrequency computes the resonant frequency of a
+// cavity with the given volume, connected to the outside
+// through a neck with the given cross-sectional area and
+// length.
+//
+// The neck may be a hole through a wall, in which case
+// its length is the thickness of the wall.
+// If speedOfSound is 0, DefaultSpeedOfSound is used, so
+// all lengths should be in millimeters.
+func HelmholtzFrequency(volume, neckArea, neckLength, speedOfSound float64) float64 {
+	if speedOfSound == 0 {
+		speedOfSound = DefaultSpeedOfSound
+	}
+	effLength := helmholtzEffectiveLength(neckArea, neckLength)
+	return speedOfSound / (2 * math.Pi) * math.Sqrt(neckArea/(volume*effLength))
+}
+
+// HelmholtzVolume computes the cavity volume needed for a
+// resonator to produce the given frequency.
+//
+// This is the inverse of HelmholtzFrequency.
+func HelmholtzVolume(frequency, neckArea, neckLength, speedOfSound float64) float64 {
+	if speedOfSound == 0 {
+		speedOfSound = DefaultSpeedOfSound
+	}
+	effLength := helmholtzEffectiveLength(neckArea, neckLength)
+	k := 2 * math.Pi * frequency / speedOfSound
+	return neckArea / (effLength * k * k)
+}
+
+func helmholtzEffectiveLength(neckArea, neckLength float64) float64 {
+	radius := math.Sqrt(neckArea / math.Pi)
+	return neckLength + helmholtzEndCorrection*radius
+}
+
+// A HelmholtzResonator is a hollow sphere with a round
+// neck, like a bottle, which sounds when air is blown
+// across the opening of the neck.
+//
+// The resonator is centered on the z-axis, with the center
+// of the sphere at the origin and the neck pointing up.
+type HelmholtzResonator struct {
+	// Frequency is the target pitch in Hz.
+	Frequency float64
+
+	// NeckRadius is the inner radius of the neck.
+	NeckRadius float64
+
+	// NeckLength is the length of the neck, measured from
+	// the inside of the sphere to the opening.
+	// It should be at least WallThickness.
+	NeckLength float64
+
+	WallThickness float64
+
+	// SpeedOfSound defaults to DefaultSpeedOfSound.
+	SpeedOfSound float64
+}
+
+// CavityRadius computes the inner radius of the sphere
+// needed to reach the target frequency.
+func (h *HelmholtzResonator) CavityRadius() float64 {
+	volume := HelmholtzVolume(h.Frequency, math.Pi*h.NeckRadius*h.NeckRadius,
+		h.NeckLength, h.SpeedOfSound)
+	return math.Cbrt(volume * 3 / (4 * math.Pi))
+}
+
+// Solid creates the resonator.
+func (h *HelmholtzResonator) Solid() model3d.Solid {
+	cavityRadius := h.CavityRadius()
+	outerRadius := cavityRadius + h.WallThickness
+	neckOuter := h.NeckRadius + h.WallThickness
+
+	// The neck starts where it meets the inside of the
+	// sphere.
+	neckBase := math.Sqrt(math.Max(0, cavityRadius*cavityRadius-h.NeckRadius*h.NeckRadius))
+	top := math.Max(neckBase+h.NeckLength, outerRadius)
+
+	profile := model2d.CheckedFuncSolid(
+		model2d.XY(-outerRadius, -outerRadius),
+		model2d.XY(outerRadius, top),
+		func(c model2d.Coord) bool {
+			r := math.Abs(c.X)
+			if r < h.NeckRadius && c.Y > 0 {
+				// Inside the neck.
+				return false
+			}
+			norm := c.Norm()
+			if norm < cavityRadius {
+				return false
+			}
+			return norm < outerRadius || (r < neckOuter && c.Y > 0 && c.Y < top)
+		},
+	)
+	return model3d.RevolveSolid(profile, model3d.Z(1))
+}
+
+// A VesselWhistle is an ocarina-style whistle, where a
+// windway directs air across a window in the top of a
+// cylindrical cavity, splitting it on the far edge of the
+// window (the labium).
+//
+// The cavity acts as a Helmholtz resonator, where the
+// window is the neck.
+//
+// The whistle sits on the plane z=0, centered around the
+// z-axis, with the mouthpiece pointing in the -x
+// direction.
+type VesselWhistle struct {
+	// Frequency is the target pitch in Hz.
+	Frequency float64
+
+	// WindowWidth is the size of the window along the
+	// y-axis, and WindowLength is the distance from the
+	// end of the windway to the labium.
+	WindowWidth  float64
+	WindowLength float64
+
+	// WindwayHeight is the height of the windway, which is
+	// as wide as the window.
+	// It should be less than twice WallThickness.
+	WindwayHeight float64
+
+	// MouthpieceLength is how far the mouthpiece extends
+	// beyond the side of the cavity.
+	MouthpieceLength float64
+
+	WallThickness float64
+
+	// SpeedOfSound defaults to DefaultSpeedOfSound.
+	SpeedOfSound float64
+}
+
+// CavityRadius computes the radius of the cavity, which is
+// as tall as its radius.
+func (v *VesselWhistle) CavityRadius() float64 {
+	volume := HelmholtzVolume(v.Frequency, v.WindowWidth*v.WindowLength, v.WallThickness,
+		v.SpeedOfSound)
+	return math.Cbrt(volume / math.Pi)
+}
+
+// Solid creates the whistle.
+func (v *VesselWhistle) Solid() model3d.Solid {
+	t := v.WallThickness
+	radius := v.CavityRadius()
+	cavityTop := t + radius
+	plateTop := cavityTop + t
+	halfWidth := v.WindowWidth / 2
+	halfLength := v.WindowLength / 2
+
+	// The windway is centered on the top of the plate, so
+	// that the jet hits the edge of the labium.
+	windwayMin := plateTop - v.WindwayHeight/2
+	windwayMax := plateTop + v.WindwayHeight/2
+	mouthEnd := -(radius + t + v.MouthpieceLength)
+
+	body := &model3d.Cylinder{
+		P2:     model3d.Z(plateTop),
+		Radius: radius + t,
+	}
+	mouthpiece := &model3d.Rect{
+		MinVal: model3d.XYZ(mouthEnd, -halfWidth-t, cavityTop),
+		MaxVal: model3d.XYZ(-halfLength, halfWidth+t, windwayMax+t),
+	}
+	return model3d.CheckedFuncSolid(
+		model3d.XYZ(mouthEnd, -(radius+t), 0),
+		model3d.XYZ(radius+t, radius+t, windwayMax+t),
+		func(c model3d.Coord3D) bool {
+			if !body.Contains(c) && !mouthpiece.Contains(c) {
+				return false
+			}
+			if c.Z > t && c.Z < cavityTop && c.XY().Norm() < radius {
+				return false
+			}
+			inWidth := math.Abs(c.Y) < halfWidth
+			if inWidth && math.Abs(c.X) < halfLength && c.Z >= cavityTop {
+				// Inside the window.
+				return false
+			}
+			if inWidth && c.X < -halfLength && c.Z > windwayMin && c.Z < windwayMax {
+				// Inside the windway.
+				return false
+			}
+			return true
+		},
+	)
+}
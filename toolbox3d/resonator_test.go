@@ -0,0 +1,130 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestHelmholtzVolume(t *testing.T) {
+	for _, freq := range []float64{200, 440, 1000, 3000} {
+		volume := HelmholtzVolume(freq, 20, 3, 0)
+		actual := HelmholtzFrequency(volume, 20, 3, 0)
+		if math.Abs(actual-freq) > 1e-8*freq {
+			t.Errorf("expected frequency %f but got %f", freq, actual)
+		}
+	}
+
+	// A 500mL bottle with a 1cm radius neck that is 8cm
+	// long should resonate around 139 Hz.
+	freq := HelmholtzFrequency(500000, math.Pi*100, 80, 0)
+	if freq < 135 || freq > 143 {
+		t.Errorf("unexpected bottle frequency: %f", freq)
+	}
+
+	// Doubling the frequency should reduce the volume by
+	// a factor of four.
+	ratio := HelmholtzVolume(440, 20, 3, 0) / HelmholtzVolume(880, 20, 3, 0)
+	if math.Abs(ratio-4) > 1e-8 {
+		t.Errorf("unexpected volume ratio: %f", ratio)
+	}
+}
+
+func TestHelmholtzResonator(t *testing.T) {
+	h := &HelmholtzResonator{
+		Frequency:     440,
+		NeckRadius:    3,
+		NeckLength:    6,
+		WallThickness: 1,
+	}
+	radius := h.CavityRadius()
+	volume := 4.0 / 3.0 * math.Pi * math.Pow(radius, 3)
+	freq := HelmholtzFrequency(volume, math.Pi*9, 6, 0)
+	if math.Abs(freq-440) > 1e-5 {
+		t.Errorf("unexpected frequency: %f", freq)
+	}
+
+	solid := h.Solid()
+	if solid.Contains(model3d.Coord3D{}) {
+		t.Error("cavity should be empty")
+	}
+	if !solid.Contains(model3d.XYZ(radius+0.5, 0, 0)) ||
+		!solid.Contains(model3d.XYZ(0, 0, -(radius+0.5))) {
+		t.Error("missing wall")
+	}
+	if solid.Contains(model3d.XYZ(radius+1.5, 0, 0)) {
+		t.Error("wall is too thick")
+	}
+	neckBase := math.Sqrt(radius*radius - 9)
+	neckTop := neckBase + 6
+	for z := radius - 1; z < neckTop; z += 0.5 {
+		if solid.Contains(model3d.Z(z)) {
+			t.Fatalf("neck is blocked at z=%f", z)
+		}
+		if z > neckBase && !solid.Contains(model3d.XYZ(3.5, 0, z)) {
+			t.Fatalf("missing neck wall at z=%f", z)
+		}
+	}
+	if solid.Contains(model3d.XYZ(3.5, 0, neckTop+0.1)) {
+		t.Error("neck is too long")
+	}
+}
+
+func TestVesselWhistle(t *testing.T) {
+	v := &VesselWhistle{
+		Frequency:        2000,
+		WindowWidth:      6,
+		WindowLength:     4,
+		WindwayHeight:    1,
+		MouthpieceLength: 15,
+		WallThickness:    1.5,
+	}
+	radius := v.CavityRadius()
+	volume := math.Pi * math.Pow(radius, 3)
+	freq := HelmholtzFrequency(volume, 24, 1.5, 0)
+	if math.Abs(freq-2000) > 1e-5 {
+		t.Errorf("unexpected frequency: %f", freq)
+	}
+	if radius < 3 || radius > 20 {
+		t.Fatalf("unexpected radius: %f", radius)
+	}
+
+	solid := v.Solid()
+	plateTop := 3 + radius
+
+	// Air should flow from the mouth to the window.
+	for x := solid.Min().X + 1e-3; x < 0; x += 0.25 {
+		if solid.Contains(model3d.XYZ(x, 0, plateTop)) {
+			t.Fatalf("windway is blocked at x=%f", x)
+		}
+		if x < -(radius+1.5) && !solid.Contains(model3d.XYZ(x, 0, plateTop-0.75)) {
+			t.Fatalf("missing windway floor at x=%f", x)
+		}
+		if x < -2 && !solid.Contains(model3d.XYZ(x, 0, plateTop+0.75)) {
+			t.Fatalf("missing windway ceiling at x=%f", x)
+		}
+	}
+
+	// The window should open into the cavity, and the
+	// labium should be in the path of the jet.
+	if solid.Contains(model3d.XYZ(0, 0, plateTop-0.75)) {
+		t.Error("window should be open")
+	}
+	if !solid.Contains(model3d.XYZ(2.1, 0, plateTop-1e-3)) {
+		t.Error("missing labium")
+	}
+
+	// The cavity should be sealed everywhere else.
+	if solid.Contains(model3d.XYZ(radius/2, radius/2, radius/2)) {
+		t.Error("cavity should be empty")
+	}
+	if !solid.Contains(model3d.XYZ(radius/2, radius/2, plateTop-0.75)) {
+		t.Error("missing top plate")
+	}
+
+	mesh := model3d.MarchingCubesSearch(solid, 0.25, 8)
+	if mesh.NeedsRepair() {
+		t.Error("mesh needs repair")
+	}
+}
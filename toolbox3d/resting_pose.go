@@ -0,0 +1,119 @@
+package toolbox3d
+
+import (
+	"math"
+	"sort"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A RestingPose is an orientation in which a model can
+// rest on a flat surface without tipping over.
+type RestingPose struct {
+	// Normal is the outward normal of the face of the
+	// model's convex hull that touches the surface, in the
+	// model's original coordinates.
+	Normal model3d.Coord3D
+
+	// Transform rotates and translates the model so that it
+	// rests on the plane z=0, with its center of mass above
+	// the origin.
+	Transform model3d.DistTransform
+
+	// Area is the area of the face touching the surface.
+	Area float64
+
+	// Height is the height of the center of mass above the
+	// surface.
+	Height float64
+
+	// Stability is the minimum amount that the center of
+	// mass must be raised to tip the model onto another
+	// face.
+	// This is proportional to the energy needed to knock the
+	// model out of the pose.
+	Stability float64
+}
+
+// RestingPoses finds the stable orientations of a closed
+// mesh on a flat surface, sorted from most to least
+// stable.
+//
+// The model is assumed to have a uniform density.
+// Each pose corresponds to a face of the convex hull of
+// the mesh for which the center of mass lies above the
+// face, so that gravity cannot tip the model over.
+// Nearly coplanar faces of the hull are merged, with a
+// tolerance relative to the size of the mesh.
+func RestingPoses(m *model3d.Mesh) []*RestingPose {
+	hull := model3d.ConvexHull(m.VertexSlice())
+	if len(hull.TriangleSlice()) == 0 {
+		return nil
+	}
+	center := m.Centroid()
+	tolerance := hull.Max().Dist(hull.Min()) * 1e-8
+
+	var res []*RestingPose
+	for _, face := range hull.ExtractPlanarFaces(tolerance) {
+		normal := face.Plane.Normal
+		height := -face.Plane.SignedDist(center)
+		stability := math.Inf(1)
+		for _, loop := range face.Boundary {
+			for i, p1 := range loop {
+				p2 := loop[(i+1)%len(loop)]
+				edge := p2.Sub(p1)
+				if edge.Norm() == 0 {
+					continue
+				}
+				// Boundaries are counter-clockwise, so the face
+				// is to the left of each edge.
+				inward := normal.Cross(edge).Normalize()
+				dist := center.Sub(p1).Dot(inward)
+				if dist <= tolerance {
+					stability = -1
+					break
+				}
+				pivotDist := math.Sqrt(dist*dist + height*height)
+				stability = math.Min(stability, pivotDist-height)
+			}
+		}
+		if stability < 0 {
+			continue
+		}
+		res = append(res, &RestingPose{
+			Normal:    normal,
+			Transform: restingPoseTransform(normal, center, height),
+			Area:      face.Mesh.Area(),
+			Height:    height,
+			Stability: stability,
+		})
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Stability > res[j].Stability
+	})
+	return res
+}
+
+func restingPoseTransform(normal, center model3d.Coord3D,
+	height float64) model3d.DistTransform {
+	down := model3d.Z(-1)
+	var rotation model3d.DistTransform
+	axis := normal.Cross(down)
+	if axis.Norm() < 1e-8 {
+		if normal.Z < 0 {
+			rotation = model3d.Rotation(model3d.X(1), 0)
+		} else {
+			rotation = model3d.Rotation(model3d.X(1), math.Pi)
+		}
+	} else {
+		angle := math.Acos(math.Max(-1, math.Min(1, normal.Dot(down))))
+		rotation = model3d.Rotation(axis.Normalize(), angle)
+	}
+	rotatedCenter := rotation.Apply(center)
+	return model3d.JoinedTransform{
+		rotation,
+		&model3d.Translate{
+			Offset: model3d.XYZ(-rotatedCenter.X, -rotatedCenter.Y, height-rotatedCenter.Z),
+		},
+	}
+}
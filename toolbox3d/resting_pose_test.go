@@ -0,0 +1,66 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestRestingPosesBox(t *testing.T) {
+	box := model3d.NewMeshRect(model3d.XYZ(1, 2, 3), model3d.XYZ(4, 4, 4))
+	poses := RestingPoses(box)
+	if len(poses) != 6 {
+		t.Fatalf("expected 6 poses but got %d", len(poses))
+	}
+	if math.Abs(poses[0].Normal.Z) < 1-1e-8 || math.Abs(poses[0].Area-6) > 1e-8 {
+		t.Errorf("unexpected most stable pose: %v", poses[0].Normal)
+	}
+	if math.Abs(poses[0].Stability-(math.Sqrt(1.25)-0.5)) > 1e-8 {
+		t.Errorf("unexpected stability: %f", poses[0].Stability)
+	}
+	if math.Abs(poses[5].Normal.X) < 1-1e-8 {
+		t.Errorf("unexpected least stable pose: %v", poses[5].Normal)
+	}
+
+	for _, pose := range poses {
+		transformed := box.Transform(pose.Transform)
+		if z := transformed.Min().Z; math.Abs(z) > 1e-8 {
+			t.Errorf("expected min z of 0 but got %f", z)
+		}
+		center := transformed.Centroid()
+		if center.Dist(model3d.Z(pose.Height)) > 1e-8 {
+			t.Errorf("unexpected center of mass: %v", center)
+		}
+		var bottomArea float64
+		transformed.Iterate(func(tri *model3d.Triangle) {
+			if tri.Normal().Z < -1+1e-8 {
+				bottomArea += tri.Area()
+			}
+		})
+		if math.Abs(bottomArea-pose.Area) > 1e-8 {
+			t.Errorf("expected bottom area %f but got %f", pose.Area, bottomArea)
+		}
+	}
+}
+
+func TestRestingPosesUnstable(t *testing.T) {
+	// A prism with an obtuse triangular cross-section,
+	// which cannot rest on its shortest side.
+	var points []model3d.Coord3D
+	for _, y := range []float64{0, 1} {
+		points = append(points, model3d.XYZ(0, y, 0), model3d.XYZ(10, y, 0),
+			model3d.XYZ(11, y, 1))
+	}
+	prism := model3d.ConvexHull(points)
+	poses := RestingPoses(prism)
+	if len(poses) != 4 {
+		t.Fatalf("expected 4 poses but got %d", len(poses))
+	}
+	unstable := model3d.XZ(1, -1).Normalize()
+	for _, pose := range poses {
+		if pose.Normal.Dist(unstable) < 1e-8 {
+			t.Error("unexpected unstable pose")
+		}
+	}
+}
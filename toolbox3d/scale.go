@@ -0,0 +1,346 @@
+package toolbox3d
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+// A Graduation describes the tick marks of a measurement
+// scale, independent of the scale's shape.
+//
+// Ticks are placed at every multiple of Step between Min
+// and Max, inclusive.
+// Every MidEvery-th tick is a mid-length tick, and every
+// MajorEvery-th tick is a labeled major tick, counting
+// from zero (rather than from Min).
+type Graduation struct {
+	Min  float64
+	Max  float64
+	Step float64
+
+	// MidEvery and MajorEvery may be 0 to disable mid or
+	// major ticks, respectively.
+	MidEvery   int
+	MajorEvery int
+
+	MinorLength float64
+	MidLength   float64
+	MajorLength float64
+	TickWidth   float64
+
+	// LabelGap is the space between the end of a major
+	// tick and the center of its label.
+	LabelGap float64
+
+	// LabelFormat is a format string for the labels of
+	// major ticks. If empty, "%g" is used.
+	LabelFormat string
+}
+
+// NewGraduation creates a graduation for a range of values
+// with roughly numMajor major ticks, choosing a step size
+// of 1, 2, or 5 times a power of ten.
+//
+// Other tick lengths, the tick width, and the label gap
+// are derived from majorLength.
+func NewGraduation(min, max float64, numMajor int, majorLength float64) *Graduation {
+	if max <= min || numMajor < 1 {
+		panic("invalid graduation range")
+	}
+	rawStep := (max - min) / float64(numMajor)
+	power := math.Pow(10, math.Floor(math.Log10(rawStep)))
+	g := &Graduation{
+		Min:         min,
+		Max:         max,
+		MinorLength: majorLength * 0.5,
+		MidLength:   majorLength * 0.7,
+		MajorLength: majorLength,
+		TickWidth:   majorLength * 0.08,
+		LabelGap:    majorLength * 0.5,
+	}
+	var majorStep float64
+	switch {
+	case rawStep <= power*1.5:
+		majorStep = power
+		g.MajorEvery, g.MidEvery = 10, 5
+	case rawStep <= power*3.5:
+		majorStep = power * 2
+		g.MajorEvery, g.MidEvery = 4, 2
+	case rawStep <= power*7.5:
+		majorStep = power * 5
+		g.MajorEvery, g.MidEvery = 5, 0
+	default:
+		majorStep = power * 10
+		g.MajorEvery, g.MidEvery = 10, 5
+	}
+	g.Step = majorStep / float64(g.MajorEvery)
+	return g
+}
+
+// A ScaleTick is a tick mark on a scale.
+type ScaleTick struct {
+	Value float64
+
+	// Level is 0 for minor ticks, 1 for mid ticks, and 2
+	// for major ticks.
+	Level int
+
+	// Position is where the tick meets the scale's edge,
+	// and the tick extends from there in Direction (a unit
+	// vector) by Length.
+	Position  model2d.Coord
+	Direction model2d.Coord
+	Length    float64
+}
+
+// A ScaleLabel is the position of a major tick's label.
+type ScaleLabel struct {
+	Value float64
+	Text  string
+
+	// Position is the center of the label.
+	Position model2d.Coord
+
+	// Rotation is the counter-clockwise rotation of the
+	// label, in radians.
+	Rotation float64
+}
+
+// Transform gets a transformation which moves a label that
+// is centered at the origin into position.
+//
+// For example, this can be applied to a text bitmap with
+// model2d.TransformSolid.
+func (s *ScaleLabel) Transform() model2d.Transform {
+	return model2d.JoinedTransform{
+		model2d.Rotation(s.Rotation),
+		&model2d.Translate{Offset: s.Position},
+	}
+}
+
+// A Scale is a graduated measurement scale, such as a
+// ruler, a protractor, or the face of a dial.
+//
+// The mesh or solid of a scale can be extruded with
+// model3d.ProfileSolid, or wrapped around a knob with a
+// CylindricalLabel.
+type Scale interface {
+	Ticks() []*ScaleTick
+	Labels() []*ScaleLabel
+
+	// Mesh creates a 2D mesh containing a rectangle for
+	// each tick.
+	Mesh() *model2d.Mesh
+
+	// Solid creates a 2D solid containing the ticks.
+	Solid() model2d.Solid
+}
+
+// A LinearScale is a straight scale, such as a ruler.
+//
+// Ticks extend to the left of the line from Start to End,
+// so that a scale running along the +X axis has its ticks
+// pointing in the +Y direction.
+type LinearScale struct {
+	Graduation
+
+	// Start is the position of Graduation.Min, and End is
+	// the position of Graduation.Max.
+	Start model2d.Coord
+	End   model2d.Coord
+}
+
+// Ticks computes the tick marks of the scale.
+func (l *LinearScale) Ticks() []*ScaleTick {
+	dir := l.End.Sub(l.Start).Normalize()
+	normal := model2d.XY(-dir.Y, dir.X)
+	var res []*ScaleTick
+	for _, v := range l.Graduation.values() {
+		frac := (v.Value - l.Min) / (l.Max - l.Min)
+		res = append(res, &ScaleTick{
+			Value:     v.Value,
+			Level:     v.Level,
+			Position:  l.Start.Add(l.End.Sub(l.Start).Scale(frac)),
+			Direction: normal,
+			Length:    l.tickLength(v.Level),
+		})
+	}
+	return res
+}
+
+// Labels computes the label positions of the scale.
+//
+// Labels are rotated to be parallel to the scale.
+func (l *LinearScale) Labels() []*ScaleLabel {
+	dir := l.End.Sub(l.Start)
+	return l.labels(l.Ticks(), math.Atan2(dir.Y, dir.X))
+}
+
+// Mesh creates a mesh for the tick marks.
+func (l *LinearScale) Mesh() *model2d.Mesh {
+	return l.mesh(l.Ticks())
+}
+
+// Solid creates a solid for the tick marks.
+func (l *LinearScale) Solid() model2d.Solid {
+	return model2d.NewColliderSolid(model2d.MeshToCollider(l.Mesh()))
+}
+
+// An ArcScale is a circular scale, such as a protractor or
+// the face of a dial.
+//
+// Ticks extend inward from the circle of the given Radius.
+type ArcScale struct {
+	Graduation
+
+	Center model2d.Coord
+	Radius float64
+
+	// StartAngle is the angle (in radians) of
+	// Graduation.Min, and EndAngle is the angle of
+	// Graduation.Max.
+	// EndAngle may be less than StartAngle for clockwise
+	// scales.
+	//
+	// If the angles span a full turn, the tick for Max is
+	// omitted since it coincides with the tick for Min.
+	StartAngle float64
+	EndAngle   float64
+}
+
+// Ticks computes the tick marks of the scale.
+func (a *ArcScale) Ticks() []*ScaleTick {
+	values := a.Graduation.values()
+	if len(values) > 1 && math.Abs(math.Abs(a.EndAngle-a.StartAngle)-2*math.Pi) < 1e-8 {
+		last := values[len(values)-1]
+		if math.Abs(last.Value-a.Max) < a.Step*1e-5 {
+			values = values[:len(values)-1]
+		}
+	}
+	var res []*ScaleTick
+	for _, v := range values {
+		theta := a.angle(v.Value)
+		outward := model2d.XY(math.Cos(theta), math.Sin(theta))
+		res = append(res, &ScaleTick{
+			Value:     v.Value,
+			Level:     v.Level,
+			Position:  a.Center.Add(outward.Scale(a.Radius)),
+			Direction: outward.Scale(-1),
+			Length:    a.tickLength(v.Level),
+		})
+	}
+	return res
+}
+
+// Labels computes the label positions of the scale.
+//
+// Labels are rotated so that their tops face away from the
+// center.
+func (a *ArcScale) Labels() []*ScaleLabel {
+	res := a.labels(a.Ticks(), 0)
+	for _, l := range res {
+		l.Rotation = a.angle(l.Value) - math.Pi/2
+	}
+	return res
+}
+
+// Mesh creates a mesh for the tick marks.
+func (a *ArcScale) Mesh() *model2d.Mesh {
+	return a.mesh(a.Ticks())
+}
+
+// Solid creates a solid for the tick marks.
+func (a *ArcScale) Solid() model2d.Solid {
+	return model2d.NewColliderSolid(model2d.MeshToCollider(a.Mesh()))
+}
+
+func (a *ArcScale) angle(value float64) float64 {
+	frac := (value - a.Min) / (a.Max - a.Min)
+	return a.StartAngle + frac*(a.EndAngle-a.StartAngle)
+}
+
+type graduationValue struct {
+	Value float64
+	Level int
+}
+
+func (g *Graduation) values() []graduationValue {
+	if g.Step <= 0 || g.Max < g.Min {
+		panic("invalid graduation")
+	}
+	// Tolerate rounding error at the ends of the range.
+	eps := 1e-5
+	first := int(math.Ceil(g.Min/g.Step - eps))
+	last := int(math.Floor(g.Max/g.Step + eps))
+	value := func(i int) float64 {
+		// Avoid values like 0.30000000000000004 for steps
+		// which are not exactly representable.
+		if inv := 1 / g.Step; inv > 1 && math.Abs(inv-math.Round(inv)) < 1e-8 {
+			return float64(i) / math.Round(inv)
+		}
+		return float64(i) * g.Step
+	}
+	var res []graduationValue
+	for i := first; i <= last; i++ {
+		level := 0
+		if g.MajorEvery > 0 && i%g.MajorEvery == 0 {
+			level = 2
+		} else if g.MidEvery > 0 && i%g.MidEvery == 0 {
+			level = 1
+		}
+		res = append(res, graduationValue{Value: value(i), Level: level})
+	}
+	return res
+}
+
+func (g *Graduation) tickLength(level int) float64 {
+	switch level {
+	case 2:
+		return g.MajorLength
+	case 1:
+		return g.MidLength
+	default:
+		return g.MinorLength
+	}
+}
+
+func (g *Graduation) labels(ticks []*ScaleTick, rotation float64) []*ScaleLabel {
+	format := g.LabelFormat
+	if format == "" {
+		format = "%g"
+	}
+	var res []*ScaleLabel
+	for _, t := range ticks {
+		if t.Level != 2 {
+			continue
+		}
+		res = append(res, &ScaleLabel{
+			Value:    t.Value,
+			Text:     fmt.Sprintf(format, t.Value),
+			Position: t.Position.Add(t.Direction.Scale(t.Length + g.LabelGap)),
+			Rotation: rotation,
+		})
+	}
+	return res
+}
+
+func (g *Graduation) mesh(ticks []*ScaleTick) *model2d.Mesh {
+	res := model2d.NewMesh()
+	for _, t := range ticks {
+		side := model2d.XY(t.Direction.Y, -t.Direction.X).Scale(g.TickWidth / 2)
+		length := t.Direction.Scale(t.Length)
+		// Segments are ordered clockwise, like NewMeshRect.
+		corners := []model2d.Coord{
+			t.Position.Sub(side),
+			t.Position.Sub(side).Add(length),
+			t.Position.Add(side).Add(length),
+			t.Position.Add(side),
+		}
+		for i, c := range corners {
+			res.Add(&model2d.Segment{c, corners[(i+1)%4]})
+		}
+	}
+	return res
+}
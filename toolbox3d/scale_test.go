@@ -0,0 +1,115 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+)
+
+func TestNewGraduation(t *testing.T) {
+	g := NewGraduation(0, 100, 10, 1)
+	if math.Abs(g.Step-1) > 1e-8 || g.MajorEvery != 10 || g.MidEvery != 5 {
+		t.Errorf("unexpected graduation: %+v", g)
+	}
+	g = NewGraduation(0, 1, 5, 1)
+	if math.Abs(g.Step*float64(g.MajorEvery)-0.2) > 1e-8 {
+		t.Errorf("unexpected major step: %f", g.Step*float64(g.MajorEvery))
+	}
+}
+
+func TestLinearScale(t *testing.T) {
+	scale := &LinearScale{
+		Graduation: *NewGraduation(0, 1, 10, 2),
+		Start:      model2d.XY(0, 0),
+		End:        model2d.XY(100, 0),
+	}
+	ticks := scale.Ticks()
+	if len(ticks) != 101 {
+		t.Fatalf("expected 101 ticks but got %d", len(ticks))
+	}
+	for i, tick := range ticks {
+		expectedX := float64(i)
+		if math.Abs(tick.Position.X-expectedX) > 1e-8 || tick.Direction.Y != 1 {
+			t.Fatalf("tick %d: unexpected position %v", i, tick.Position)
+		}
+		expectedLevel := 0
+		if i%10 == 0 {
+			expectedLevel = 2
+		} else if i%5 == 0 {
+			expectedLevel = 1
+		}
+		if tick.Level != expectedLevel {
+			t.Errorf("tick %d: expected level %d but got %d", i, expectedLevel, tick.Level)
+		}
+	}
+
+	labels := scale.Labels()
+	if len(labels) != 11 {
+		t.Fatalf("expected 11 labels but got %d", len(labels))
+	}
+	if labels[3].Text != "0.3" {
+		t.Errorf("unexpected label text: %s", labels[3].Text)
+	}
+	if labels[3].Position.Dist(model2d.XY(30, 3)) > 1e-8 {
+		t.Errorf("unexpected label position: %v", labels[3].Position)
+	}
+
+	mesh := scale.Mesh()
+	if !mesh.Manifold() {
+		t.Error("mesh is not manifold")
+	}
+	if _, n := mesh.RepairNormals(1e-8); n != 0 {
+		t.Errorf("mesh has %d bad normals", n)
+	}
+	expectedArea := (11*2 + 10*1.4 + 80*1) * scale.TickWidth
+	if area := mesh.Area(); math.Abs(area-expectedArea) > 1e-5 {
+		t.Errorf("expected area %f but got %f", expectedArea, area)
+	}
+	solid := scale.Solid()
+	if !solid.Contains(model2d.XY(50, 1.9)) || solid.Contains(model2d.XY(51, 1.1)) {
+		t.Error("unexpected solid")
+	}
+}
+
+func TestArcScale(t *testing.T) {
+	dial := &ArcScale{
+		Graduation: Graduation{
+			Min:         0,
+			Max:         360,
+			Step:        10,
+			MajorEvery:  3,
+			MinorLength: 0.1,
+			MajorLength: 0.2,
+			TickWidth:   0.02,
+			LabelGap:    0.1,
+		},
+		Radius:     1,
+		StartAngle: math.Pi / 2,
+		EndAngle:   math.Pi/2 - 2*math.Pi,
+	}
+	ticks := dial.Ticks()
+	if len(ticks) != 36 {
+		t.Fatalf("expected 36 ticks but got %d", len(ticks))
+	}
+	// The dial is clockwise, starting at the top.
+	if ticks[9].Position.Dist(model2d.XY(1, 0)) > 1e-8 {
+		t.Errorf("unexpected tick position: %v", ticks[9].Position)
+	}
+	labels := dial.Labels()
+	if len(labels) != 12 {
+		t.Fatalf("expected 12 labels but got %d", len(labels))
+	}
+	if labels[3].Text != "90" || labels[3].Position.Dist(model2d.XY(0.7, 0)) > 1e-8 {
+		t.Errorf("unexpected label: %+v", labels[3])
+	}
+	if math.Abs(labels[0].Rotation) > 1e-8 {
+		t.Errorf("top label should be upright, got rotation %f", labels[0].Rotation)
+	}
+	if c := labels[3].Transform().Apply(model2d.Y(1)); c.Dist(model2d.XY(1.7, 0)) > 1e-8 {
+		t.Errorf("label should face outward, got %v", c)
+	}
+	if _, n := dial.Mesh().RepairNormals(1e-8); n != 0 {
+		t.Errorf("mesh has %d bad normals", n)
+	}
+}
@@ -0,0 +1,271 @@
+package toolbox3d
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+const DefaultScatterMaxAttempts = 30
+
+// A ScatterRotation determines how randomly scattered
+// instances are rotated.
+type ScatterRotation int
+
+const (
+	// ScatterNoRotation keeps every instance in its
+	// original orientation.
+	ScatterNoRotation ScatterRotation = iota
+
+	// ScatterRotateAxis spins every instance by a random
+	// angle around its up axis, which is the surface
+	// normal if Scatter.AlignNormal is set, or the z axis
+	// otherwise.
+	ScatterRotateAxis
+
+	// ScatterRotateFull applies a uniformly random 3D
+	// rotation to every instance.
+	ScatterRotateFull
+)
+
+// A ScatterPlacement is the position and orientation of a
+// single scattered instance.
+type ScatterPlacement struct {
+	// Center is the point where the origin of the
+	// instance is placed.
+	Center model3d.Coord3D
+
+	// Normal is the surface normal at Center for surface
+	// placements, or the zero vector for volume
+	// placements.
+	Normal model3d.Coord3D
+
+	// Rotation is applied to the instance before it is
+	// translated to Center.
+	Rotation *model3d.Matrix3
+}
+
+// Transform gets the transformation which rotates and
+// then translates an instance into place.
+func (s *ScatterPlacement) Transform() model3d.DistTransform {
+	return model3d.JoinedTransform{
+		model3d.NewQuaternionMatrix(s.Rotation).Transform(),
+		&model3d.Translate{Offset: s.Center},
+	}
+}
+
+// Apply moves a solid, which is centered around the
+// origin, into place.
+func (s *ScatterPlacement) Apply(solid model3d.Solid) model3d.Solid {
+	return model3d.TransformSolid(s.Transform(), solid)
+}
+
+// Scatter randomly places instances of an object on a
+// surface or inside a volume, for example to cover a
+// model in pebbles or sprinkles.
+//
+// Instances are kept at least MinSpacing apart, so
+// instances with a bounding radius r never collide when
+// MinSpacing is at least 2*r.
+type Scatter struct {
+	// Count is the number of instances to place.
+	Count int
+
+	// MinSpacing is the minimum distance between the
+	// centers of any two instances.
+	MinSpacing float64
+
+	// Rotation determines how instances are rotated.
+	Rotation ScatterRotation
+
+	// AlignNormal, if true, rotates every instance placed
+	// on a surface so that its z axis points along the
+	// surface normal.
+	AlignNormal bool
+
+	// Avoid, if non-nil, is a region which no instance
+	// center may be placed inside.
+	Avoid model3d.Solid
+
+	// MaxAttempts is the number of candidate points which
+	// may be rejected in a row before giving up.
+	//
+	// If 0, DefaultScatterMaxAttempts is used.
+	MaxAttempts int
+
+	// Rand, if non-nil, is used as the source of
+	// randomness for reproducible placements.
+	Rand *rand.Rand
+}
+
+// Surface scatters instances across the surface of a
+// mesh, choosing points uniformly by area.
+//
+// If the spacing constraint cannot be satisfied, fewer
+// than s.Count placements may be returned.
+func (s *Scatter) Surface(m *model3d.Mesh) []*ScatterPlacement {
+	tris := m.TriangleSlice()
+	if len(tris) == 0 {
+		return nil
+	}
+	cumAreas := make([]float64, len(tris))
+	var totalArea float64
+	for i, t := range tris {
+		totalArea += t.Area()
+		cumAreas[i] = totalArea
+	}
+	return s.place(func(rng *rand.Rand) (model3d.Coord3D, model3d.Coord3D, bool) {
+		idx := sort.SearchFloat64s(cumAreas, rng.Float64()*totalArea)
+		if idx >= len(tris) {
+			idx = len(tris) - 1
+		}
+		t := tris[idx]
+		a, b := rng.Float64(), rng.Float64()
+		if a+b > 1 {
+			a, b = 1-a, 1-b
+		}
+		p := t[0].Add(t[1].Sub(t[0]).Scale(a)).Add(t[2].Sub(t[0]).Scale(b))
+		return p, t.Normal(), true
+	})
+}
+
+// Volume scatters instances uniformly throughout the
+// interior of a solid.
+//
+// If the spacing constraint cannot be satisfied, fewer
+// than s.Count placements may be returned.
+func (s *Scatter) Volume(solid model3d.Solid) []*ScatterPlacement {
+	min, max := solid.Min(), solid.Max()
+	return s.place(func(rng *rand.Rand) (model3d.Coord3D, model3d.Coord3D, bool) {
+		p := model3d.XYZ(rng.Float64(), rng.Float64(), rng.Float64())
+		p = min.Add(p.Mul(max.Sub(min)))
+		return p, model3d.Coord3D{}, solid.Contains(p)
+	})
+}
+
+func (s *Scatter) place(sample func(*rand.Rand) (model3d.Coord3D,
+	model3d.Coord3D, bool)) []*ScatterPlacement {
+	rng := s.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	maxAttempts := s.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultScatterMaxAttempts
+	}
+	grid := newScatterGrid(s.MinSpacing)
+
+	var res []*ScatterPlacement
+	failures := 0
+	for len(res) < s.Count && failures < maxAttempts {
+		p, normal, ok := sample(rng)
+		if !ok || (s.Avoid != nil && s.Avoid.Contains(p)) || grid.Collides(p) {
+			failures++
+			continue
+		}
+		failures = 0
+		grid.Add(p)
+		res = append(res, &ScatterPlacement{
+			Center:   p,
+			Normal:   normal,
+			Rotation: s.rotation(rng, normal),
+		})
+	}
+	return res
+}
+
+func (s *Scatter) rotation(rng *rand.Rand, normal model3d.Coord3D) *model3d.Matrix3 {
+	var res model3d.Quaternion
+	switch s.Rotation {
+	case ScatterNoRotation:
+		res = model3d.Quaternion{W: 1}
+	case ScatterRotateAxis:
+		res = model3d.NewQuaternionAxisAngle(model3d.Z(1), rng.Float64()*2*math.Pi)
+	case ScatterRotateFull:
+		res = model3d.Quaternion{
+			W: rng.NormFloat64(),
+			X: rng.NormFloat64(),
+			Y: rng.NormFloat64(),
+			Z: rng.NormFloat64(),
+		}.Normalize()
+	default:
+		panic("unknown scatter rotation")
+	}
+	if s.AlignNormal && normal.Norm() != 0 {
+		res = scatterAlignZ(normal).Mul(res)
+	}
+	return res.Matrix()
+}
+
+// scatterAlignZ creates a rotation which moves the z axis
+// onto a unit vector.
+func scatterAlignZ(dir model3d.Coord3D) model3d.Quaternion {
+	axis := model3d.Z(1).Cross(dir)
+	norm := axis.Norm()
+	if norm < 1e-8 {
+		if dir.Z > 0 {
+			return model3d.Quaternion{W: 1}
+		}
+		return model3d.NewQuaternionAxisAngle(model3d.X(1), math.Pi)
+	}
+	return model3d.NewQuaternionAxisAngle(axis.Scale(1/norm), math.Atan2(norm, dir.Z))
+}
+
+// ScatterSolid joins copies of an instance, which should
+// be centered around the origin, at every placement.
+func ScatterSolid(instance model3d.Solid, placements []*ScatterPlacement) model3d.Solid {
+	res := make(model3d.JoinedSolid, len(placements))
+	for i, p := range placements {
+		res[i] = p.Apply(instance)
+	}
+	return res.Optimize()
+}
+
+// scatterGrid is a spatial hash for checking the spacing
+// between points.
+type scatterGrid struct {
+	spacing float64
+	cells   map[[3]int][]model3d.Coord3D
+}
+
+func newScatterGrid(spacing float64) *scatterGrid {
+	return &scatterGrid{spacing: spacing, cells: map[[3]int][]model3d.Coord3D{}}
+}
+
+func (s *scatterGrid) Collides(c model3d.Coord3D) bool {
+	if s.spacing <= 0 {
+		return false
+	}
+	key := s.key(c)
+	for x := -1; x <= 1; x++ {
+		for y := -1; y <= 1; y++ {
+			for z := -1; z <= 1; z++ {
+				neighbor := [3]int{key[0] + x, key[1] + y, key[2] + z}
+				for _, c1 := range s.cells[neighbor] {
+					if c1.Dist(c) < s.spacing {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (s *scatterGrid) Add(c model3d.Coord3D) {
+	if s.spacing <= 0 {
+		return
+	}
+	key := s.key(c)
+	s.cells[key] = append(s.cells[key], c)
+}
+
+func (s *scatterGrid) key(c model3d.Coord3D) [3]int {
+	return [3]int{
+		int(math.Floor(c.X / s.spacing)),
+		int(math.Floor(c.Y / s.spacing)),
+		int(math.Floor(c.Z / s.spacing)),
+	}
+}
@@ -0,0 +1,125 @@
+package toolbox3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestScatterSurface(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.XYZ(0, 0, 0), model3d.XYZ(4, 4, 1))
+	s := &Scatter{
+		Count:       50,
+		MinSpacing:  0.3,
+		Rotation:    ScatterRotateAxis,
+		AlignNormal: true,
+		Rand:        rand.New(rand.NewSource(1)),
+	}
+	placements := s.Surface(mesh)
+	if len(placements) != s.Count {
+		t.Fatalf("expected %d placements but got %d", s.Count, len(placements))
+	}
+	checkScatterSpacing(t, placements, s.MinSpacing)
+	for i, p := range placements {
+		if math.Abs(p.Normal.Norm()-1) > 1e-8 {
+			t.Fatalf("placement %d: bad normal %v", i, p.Normal)
+		}
+		onFace := false
+		for _, axis := range []float64{p.Center.X, p.Center.Y} {
+			onFace = onFace || math.Abs(axis) < 1e-8 || math.Abs(axis-4) < 1e-8
+		}
+		onFace = onFace || math.Abs(p.Center.Z) < 1e-8 || math.Abs(p.Center.Z-1) < 1e-8
+		if !onFace {
+			t.Fatalf("placement %d is not on the surface: %v", i, p.Center)
+		}
+		up := p.Rotation.MulColumn(model3d.Z(1))
+		if up.Dist(p.Normal) > 1e-8 {
+			t.Fatalf("placement %d: up axis %v does not match normal %v", i, up, p.Normal)
+		}
+	}
+}
+
+func TestScatterVolume(t *testing.T) {
+	sphere := &model3d.Sphere{Radius: 2}
+	avoid := &model3d.Rect{MinVal: model3d.XYZ(-3, -3, -3), MaxVal: model3d.XYZ(3, 3, 0)}
+	s := &Scatter{
+		Count:      30,
+		MinSpacing: 0.5,
+		Rotation:   ScatterRotateFull,
+		Avoid:      avoid,
+		Rand:       rand.New(rand.NewSource(2)),
+	}
+	placements := s.Volume(sphere)
+	if len(placements) != s.Count {
+		t.Fatalf("expected %d placements but got %d", s.Count, len(placements))
+	}
+	checkScatterSpacing(t, placements, s.MinSpacing)
+	for i, p := range placements {
+		if !sphere.Contains(p.Center) || avoid.Contains(p.Center) {
+			t.Fatalf("placement %d is outside of the region: %v", i, p.Center)
+		}
+		if math.Abs(p.Rotation.Det()-1) > 1e-8 {
+			t.Fatalf("placement %d: bad rotation determinant %f", i, p.Rotation.Det())
+		}
+	}
+}
+
+func TestScatterCrowded(t *testing.T) {
+	s := &Scatter{
+		Count:      1000,
+		MinSpacing: 1,
+		Rand:       rand.New(rand.NewSource(3)),
+	}
+	placements := s.Volume(&model3d.Rect{MaxVal: model3d.XYZ(2, 2, 2)})
+	if len(placements) == 0 || len(placements) >= 30 {
+		t.Fatalf("unexpected number of placements: %d", len(placements))
+	}
+	checkScatterSpacing(t, placements, s.MinSpacing)
+}
+
+func TestScatterSolid(t *testing.T) {
+	placements := []*ScatterPlacement{
+		{
+			Center:   model3d.XYZ(1, 0, 0),
+			Rotation: model3d.NewMatrix3Rotation(model3d.Z(1), math.Pi/2),
+		},
+		{
+			Center:   model3d.XYZ(-1, 0, 0),
+			Rotation: &model3d.Matrix3{1, 0, 0, 0, 1, 0, 0, 0, 1},
+		},
+	}
+	instance := &model3d.Rect{
+		MinVal: model3d.XYZ(-0.1, -0.3, -0.1),
+		MaxVal: model3d.XYZ(0.1, 0.3, 0.1),
+	}
+	solid := ScatterSolid(instance, placements)
+	for _, c := range []model3d.Coord3D{
+		model3d.XYZ(1.25, 0, 0),
+		model3d.XYZ(-1, 0.25, 0),
+	} {
+		if !solid.Contains(c) {
+			t.Errorf("expected %v to be inside", c)
+		}
+	}
+	for _, c := range []model3d.Coord3D{
+		model3d.XYZ(1, 0.25, 0),
+		model3d.XYZ(-1.25, 0, 0),
+		model3d.XYZ(0, 0, 0),
+	} {
+		if solid.Contains(c) {
+			t.Errorf("expected %v to be outside", c)
+		}
+	}
+}
+
+func checkScatterSpacing(t *testing.T, placements []*ScatterPlacement, spacing float64) {
+	for i, p := range placements {
+		for _, p1 := range placements[:i] {
+			if p.Center.Dist(p1.Center) < spacing {
+				t.Fatalf("points too close: %v and %v", p.Center, p1.Center)
+			}
+		}
+	}
+}
@@ -3,9 +3,36 @@ package toolbox3d
 import (
 	"math"
 
+	"github.com/unixpickle/essentials"
 	"github.com/unixpickle/model3d/model3d"
 )
 
+// A ThreadProfile is the cross-sectional shape of the
+// threads of a ScrewSolid.
+type ThreadProfile int
+
+const (
+	// VThread has triangular threads with 90 degree angles
+	// between their flanks.
+	VThread ThreadProfile = iota
+
+	// TrapezoidalThread has flat-topped threads with 30
+	// degrees between their flanks, like metric trapezoidal
+	// and ACME lead screw threads.
+	TrapezoidalThread
+
+	// ButtressThread has one flank perpendicular to the
+	// axis, facing the P2 end, and another flank at 45
+	// degrees.
+	// This carries large loads which push the screw toward
+	// P2 relative to a nut.
+	ButtressThread
+
+	// SquareThread has rectangular threads, which are half
+	// as wide as the pitch.
+	SquareThread
+)
+
 // A ScrewSolid is a model3d.Solid implementation of
 // screws. It can also be used for screw holes, by
 // combining it with model3d.SubtractedSolid.
@@ -27,6 +54,17 @@ type ScrewSolid struct {
 	// This may not exceed Radius.
 	GrooveSize float64
 
+	// Profile is the shape of the threads.
+	Profile ThreadProfile
+
+	// Starts is the number of interleaved threads, where 0
+	// is equivalent to 1.
+	// The distance between adjacent threads (the pitch) is
+	// always twice the GrooveSize, so the distance that the
+	// screw advances in one turn (the lead) is multiplied
+	// by the number of starts.
+	Starts int
+
 	// Pointed can be set to true to indicate that the tip
 	// at the P2 end should be cut off at a 45 degree
 	// angle (in the shape of a cone).
@@ -79,14 +117,40 @@ func (s *ScrewSolid) Contains(c model3d.Coord3D) bool {
 		return true
 	}
 
-	zOffset := math.Atan2(offset.Y, offset.X) * s.GrooveSize / math.Pi
-	offZ := offset.Z - zOffset
-	roundedZ := math.Round(offZ/(s.GrooveSize*2)) * s.GrooveSize * 2
-	if math.Abs(roundedZ-offZ) <= maxDistance {
-		return true
-	}
+	return threadContains(s.Profile, s.Starts, s.GrooveSize, offset.Z,
+		math.Atan2(offset.Y, offset.X), maxDistance)
+}
 
-	return false
+// Lead gets the distance that the screw advances along its
+// axis in one turn.
+func (s *ScrewSolid) Lead() float64 {
+	return 2 * s.GrooveSize * float64(essentials.MaxInt(1, s.Starts))
+}
+
+// threadContains checks if a point is inside the threads
+// of a screw, given its position z along the axis, its
+// angle theta around the axis, and its depth below the
+// outer radius of the screw.
+func threadContains(profile ThreadProfile, starts int, grooveSize, z, theta,
+	depth float64) bool {
+	starts = essentials.MaxInt(1, starts)
+	pitch := grooveSize * 2
+	offZ := z - theta*grooveSize*float64(starts)/math.Pi
+
+	// u is the offset from the center of the nearest thread,
+	// ranging from -grooveSize to grooveSize.
+	u := offZ - math.Round(offZ/pitch)*pitch
+	switch profile {
+	case TrapezoidalThread:
+		// The crest is 0.366 times the pitch.
+		return math.Abs(u) <= 0.366*grooveSize+depth*math.Tan(math.Pi/12)
+	case ButtressThread:
+		return u >= -depth && u <= grooveSize/2
+	case SquareThread:
+		return math.Abs(u) <= grooveSize/2
+	default:
+		return math.Abs(u) <= depth
+	}
 }
 
 func (s *ScrewSolid) boundingCylinder() *model3d.CylinderSolid {
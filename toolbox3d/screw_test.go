@@ -0,0 +1,91 @@
+package toolbox3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestScrewSolidProfiles(t *testing.T) {
+	const grooveSize = 0.5
+	expectedFill := map[ThreadProfile]func(depth float64) float64{
+		VThread: func(depth float64) float64 {
+			return depth / grooveSize
+		},
+		TrapezoidalThread: func(depth float64) float64 {
+			return 0.366 + depth*math.Tan(math.Pi/12)/grooveSize
+		},
+		ButtressThread: func(depth float64) float64 {
+			return (depth + grooveSize/2) / (2 * grooveSize)
+		},
+		SquareThread: func(depth float64) float64 {
+			return 0.5
+		},
+	}
+	for profile, fillFn := range expectedFill {
+		for _, starts := range []int{0, 1, 3} {
+			screw := &ScrewSolid{
+				P2:         model3d.Z(10),
+				Radius:     2,
+				GrooveSize: grooveSize,
+				Profile:    profile,
+				Starts:     starts,
+			}
+			for _, depth := range []float64{0.1, 0.25, 0.4} {
+				var count int
+				const numSamples = 20000
+				for i := 0; i < numSamples; i++ {
+					theta := rand.Float64() * 2 * math.Pi
+					z := 1 + rand.Float64()*8
+					r := screw.Radius - depth
+					c := model3d.XYZ(r*math.Cos(theta), r*math.Sin(theta), z)
+					if screw.Contains(c) {
+						count++
+					}
+				}
+				actual := float64(count) / numSamples
+				expected := fillFn(depth)
+				if math.Abs(actual-expected) > 0.02 {
+					t.Errorf("profile %d starts %d depth %f: expected fill %f but got %f",
+						profile, starts, depth, expected, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestScrewSolidStarts(t *testing.T) {
+	for _, starts := range []int{1, 2, 4} {
+		screw := &ScrewSolid{
+			P2:         model3d.Z(20),
+			Radius:     2,
+			GrooveSize: 0.3,
+			Profile:    TrapezoidalThread,
+			Starts:     starts,
+		}
+		if lead := screw.Lead(); math.Abs(lead-0.6*float64(starts)) > 1e-8 {
+			t.Errorf("unexpected lead: %f", lead)
+		}
+		for i := 0; i < 1000; i++ {
+			theta := rand.Float64() * math.Pi
+			z := 5 + rand.Float64()*5
+			r := 1.8
+			c := model3d.XYZ(r*math.Cos(theta), r*math.Sin(theta), z)
+
+			// The threads are periodic with respect to the
+			// pitch, and follow a helix with the lead.
+			shifted := c.Add(model3d.Z(0.6))
+			if screw.Contains(c) != screw.Contains(shifted) {
+				t.Fatal("threads should repeat every pitch")
+			}
+			dTheta := rand.Float64() * math.Pi / 2
+			rotated := model3d.XYZ(r*math.Cos(theta+dTheta), r*math.Sin(theta+dTheta),
+				z+dTheta/(2*math.Pi)*screw.Lead())
+			if screw.Contains(c) != screw.Contains(rotated) {
+				t.Fatal("threads should follow a helix")
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A ShadowProjection describes a 2D shape which should
+// be cast as a shadow when light shines in a direction.
+type ShadowProjection struct {
+	// Art is the 2D shape of the shadow.
+	// The art is centered around the origin, which lies on
+	// the line through the origin in 3D space along the
+	// light direction.
+	Art model2d.Solid
+
+	// Direction is the direction that light travels.
+	Direction model3d.Coord3D
+
+	// Up is the direction in 3D space corresponding to the
+	// y-axis of the art.
+	// If it is the zero vector or parallel to Direction,
+	// an arbitrary direction is chosen.
+	Up model3d.Coord3D
+}
+
+// Axes computes the 3D directions corresponding to the
+// x and y axes of the art.
+//
+// The x-axis points to the right when looking at the
+// shadow from the side of the light source.
+func (s *ShadowProjection) Axes() (x, y model3d.Coord3D) {
+	dir := s.Direction.Normalize()
+	y = s.Up.ProjectOut(dir)
+	if y.Norm() < 1e-8 {
+		_, y = dir.OrthoBasis()
+	}
+	y = y.Normalize()
+	x = dir.Cross(y)
+	return
+}
+
+// Project computes the art coordinate of the shadow cast
+// by a 3D point.
+func (s *ShadowProjection) Project(c model3d.Coord3D) model2d.Coord {
+	x, y := s.Axes()
+	return model2d.XY(c.Dot(x), c.Dot(y))
+}
+
+// Solid creates an extrusion of the art along the light
+// direction, bounded to a sphere of the given radius.
+//
+// If radius is 0, it is set to the radius of the art
+// around the origin.
+func (s *ShadowProjection) Solid(radius float64) model3d.Solid {
+	if radius == 0 {
+		radius = shadowArtRadius(s.Art)
+	}
+	return model3d.CheckedFuncSolid(
+		model3d.Ones(-radius),
+		model3d.Ones(radius),
+		func(c model3d.Coord3D) bool {
+			return c.Norm() <= radius && s.Art.Contains(s.Project(c))
+		},
+	)
+}
+
+// ShadowArt creates a solid which, as closely as
+// possible, casts each of the projections as a shadow
+// when lit from the corresponding direction.
+//
+// The result is the intersection of an extrusion of every
+// projection along its light direction.
+// For a single projection, the shadow is exact.
+// For multiple projections, parts of each shadow may be
+// missing if the artwork cannot be realized at once.
+// For example, two projections may disagree about the
+// extent of the shape along a shared axis.
+func ShadowArt(projections ...*ShadowProjection) model3d.Solid {
+	var radius float64
+	for _, p := range projections {
+		radius = math.Max(radius, shadowArtRadius(p.Art))
+	}
+	var result model3d.IntersectedSolid
+	for _, p := range projections {
+		result = append(result, p.Solid(radius))
+	}
+	return result
+}
+
+func shadowArtRadius(s model2d.Solid) float64 {
+	min, max := s.Min(), s.Max()
+	extent := model2d.XY(
+		math.Max(math.Abs(min.X), math.Abs(max.X)),
+		math.Max(math.Abs(min.Y), math.Abs(max.Y)),
+	)
+	return math.Max(extent.Norm(), 1e-8)
+}
+
+// SilhouetteSolid creates the largest solid whose front,
+// side, and top views match the given silhouettes.
+//
+// One of the silhouettes may be nil, in which case that
+// view is unconstrained.
+// At least two silhouettes are required, since each one
+// only bounds the shape along two axes, and this panics
+// otherwise.
+//
+// The front silhouette is viewed from -Y, with x mapped
+// to X and y mapped to Z.
+// The side silhouette is viewed from +X, with x mapped to
+// Y and y mapped to Z.
+// The top silhouette is viewed from +Z, with x mapped to
+// X and y mapped to Y.
+//
+// If the silhouettes disagree about the extent of the
+// shape along a shared axis, some views will not be
+// matched exactly.
+func SilhouetteSolid(front, side, top model2d.Solid) model3d.Solid {
+	var projections []*ShadowProjection
+	if front != nil {
+		projections = append(projections, &ShadowProjection{
+			Art:       front,
+			Direction: model3d.Y(1),
+			Up:        model3d.Z(1),
+		})
+	}
+	if side != nil {
+		projections = append(projections, &ShadowProjection{
+			Art:       side,
+			Direction: model3d.X(-1),
+			Up:        model3d.Z(1),
+		})
+	}
+	if top != nil {
+		projections = append(projections, &ShadowProjection{
+			Art:       top,
+			Direction: model3d.Z(-1),
+			Up:        model3d.Y(1),
+		})
+	}
+	if len(projections) < 2 {
+		panic("at least two silhouettes are required")
+	}
+
+	// Bound the result with an axis-aligned box rather
+	// than a sphere, so that silhouettes are not clipped.
+	min := model3d.Ones(math.Inf(-1))
+	max := model3d.Ones(math.Inf(1))
+	if front != nil {
+		min.X, max.X = math.Max(min.X, front.Min().X), math.Min(max.X, front.Max().X)
+		min.Z, max.Z = math.Max(min.Z, front.Min().Y), math.Min(max.Z, front.Max().Y)
+	}
+	if side != nil {
+		min.Y, max.Y = math.Max(min.Y, side.Min().X), math.Min(max.Y, side.Max().X)
+		min.Z, max.Z = math.Max(min.Z, side.Min().Y), math.Min(max.Z, side.Max().Y)
+	}
+	if top != nil {
+		min.X, max.X = math.Max(min.X, top.Min().X), math.Min(max.X, top.Max().X)
+		min.Y, max.Y = math.Max(min.Y, top.Min().Y), math.Min(max.Y, top.Max().Y)
+	}
+	return model3d.CheckedFuncSolid(min, max, func(c model3d.Coord3D) bool {
+		for _, p := range projections {
+			if !p.Art.Contains(p.Project(c)) {
+				return false
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,90 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestShadowArt(t *testing.T) {
+	circle := &model2d.Circle{Radius: 1}
+	rect := &model2d.Rect{MinVal: model2d.XY(-0.5, -1), MaxVal: model2d.XY(0.5, 1)}
+	solid := ShadowArt(
+		&ShadowProjection{Art: circle, Direction: model3d.Z(-1), Up: model3d.Y(1)},
+		&ShadowProjection{Art: rect, Direction: model3d.X(-1), Up: model3d.Y(1)},
+	)
+
+	// Looking down -X with +Y up, the art's x-axis is -Z.
+	if !solid.Contains(model3d.XYZ(0, 0.5, 0.4)) {
+		t.Error("expected point inside both extrusions")
+	}
+	if solid.Contains(model3d.XYZ(0, 0.5, 0.6)) {
+		t.Error("expected point outside of rect extrusion")
+	}
+	if solid.Contains(model3d.XYZ(0.8, 0.8, 0)) {
+		t.Error("expected point outside of circle extrusion")
+	}
+
+	// Check that the circle's shadow is cast completely.
+	mesh := model3d.MarchingCubesSearch(solid, 0.05, 8)
+	for _, c := range []model2d.Coord{{X: 0.9}, {Y: -0.9}, {X: -0.6, Y: 0.6}} {
+		origin := model3d.XYZ(c.X, c.Y, 10)
+		ray := &model3d.Ray{Origin: origin, Direction: model3d.Z(-1)}
+		if model3d.MeshToCollider(mesh).RayCollisions(ray, nil) == 0 {
+			t.Errorf("expected shadow at %v", c)
+		}
+	}
+}
+
+func TestSilhouetteSolid(t *testing.T) {
+	// An L-shaped front view, a square side view, and no
+	// top view.
+	front := model2d.JoinedSolid{
+		&model2d.Rect{MinVal: model2d.XY(0, 0), MaxVal: model2d.XY(1, 3)},
+		&model2d.Rect{MinVal: model2d.XY(0, 0), MaxVal: model2d.XY(2, 1)},
+	}
+	side := &model2d.Rect{MinVal: model2d.XY(-1, 0), MaxVal: model2d.XY(1, 3)}
+	solid := SilhouetteSolid(front, side, nil)
+
+	if min, max := solid.Min(), solid.Max(); min.Dist(model3d.XYZ(0, -1, 0)) > 1e-8 ||
+		max.Dist(model3d.XYZ(2, 1, 3)) > 1e-8 {
+		t.Errorf("unexpected bounds %v, %v", min, max)
+	}
+	if !solid.Contains(model3d.XYZ(1.5, 0.9, 0.5)) {
+		t.Error("expected point in foot of L")
+	}
+	if solid.Contains(model3d.XYZ(1.5, 0.9, 1.5)) {
+		t.Error("expected point outside of L")
+	}
+	if solid.Contains(model3d.XYZ(0.5, 1.1, 1.5)) {
+		t.Error("expected point outside of side view")
+	}
+}
+
+func TestSilhouetteSolidNil(t *testing.T) {
+	square := &model2d.Rect{MinVal: model2d.XY(-1, -1), MaxVal: model2d.XY(1, 1)}
+	circle := &model2d.Circle{Radius: 1}
+	for i := 0; i < 3; i++ {
+		views := []model2d.Solid{square, square, square}
+		views[i] = nil
+		views[(i+1)%3] = circle
+		solid := SilhouetteSolid(views[0], views[1], views[2])
+		if min, max := solid.Min(), solid.Max(); min.Dist(model3d.Ones(-1)) > 1e-8 ||
+			max.Dist(model3d.Ones(1)) > 1e-8 {
+			t.Errorf("nil view %d: unexpected bounds %v, %v", i, min, max)
+		}
+		mesh := model3d.MarchingCubesSearch(solid, 0.05, 8)
+		if v := mesh.Volume(); math.Abs(v-2*math.Pi) > 0.1 {
+			t.Errorf("nil view %d: expected volume %f but got %f", i, 2*math.Pi, v)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic with one silhouette")
+		}
+	}()
+	SilhouetteSolid(square, nil, nil)
+}
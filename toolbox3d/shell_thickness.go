@@ -0,0 +1,160 @@
+package toolbox3d
+
+import (
+	"math"
+	"sort"
+
+	"github.com/unixpickle/essentials"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A ShellThickness measures the thickness of a hollow
+// part's walls, given the part's outer and inner surfaces.
+//
+// The thickness at each vertex of the outer mesh is the
+// distance from the vertex to the nearest point on the
+// inner mesh.
+type ShellThickness struct {
+	Outer *model3d.Mesh
+
+	// Thickness maps each vertex of Outer to its thickness.
+	Thickness map[model3d.Coord3D]float64
+}
+
+// MeasureShellThickness computes the thickness of the
+// walls between an outer and an inner mesh, such as the
+// result of hollowing a model.
+func MeasureShellThickness(outer, inner *model3d.Mesh) *ShellThickness {
+	sdf := model3d.MeshToSDF(inner)
+	vertices := outer.VertexSlice()
+	distances := make([]float64, len(vertices))
+	essentials.ConcurrentMap(0, len(vertices), func(i int) {
+		distances[i] = math.Abs(sdf.SDF(vertices[i]))
+	})
+	res := &ShellThickness{
+		Outer:     outer,
+		Thickness: make(map[model3d.Coord3D]float64, len(vertices)),
+	}
+	for i, v := range vertices {
+		res.Thickness[v] = distances[i]
+	}
+	return res
+}
+
+// Min gets the minimum thickness of any vertex.
+func (s *ShellThickness) Min() float64 {
+	res := math.Inf(1)
+	for _, t := range s.Thickness {
+		res = math.Min(res, t)
+	}
+	return res
+}
+
+// Max gets the maximum thickness of any vertex.
+func (s *ShellThickness) Max() float64 {
+	res := math.Inf(-1)
+	for _, t := range s.Thickness {
+		res = math.Max(res, t)
+	}
+	return res
+}
+
+// Mean gets the mean thickness over the outer surface.
+//
+// Each triangle is weighted by its area, so the result
+// does not depend on how finely the mesh is tessellated.
+// If the outer mesh is empty, the result is NaN.
+func (s *ShellThickness) Mean() float64 {
+	var sum, totalArea float64
+	s.Outer.Iterate(func(t *model3d.Triangle) {
+		area := t.Area()
+		sum += area * s.triangleThickness(t)
+		totalArea += area
+	})
+	return sum / totalArea
+}
+
+// Percentile gets the thickness below which a fraction p
+// of the vertices fall, where p is in [0, 1].
+//
+// Unlike Mean, every vertex is counted equally regardless
+// of the area around it, so that Percentile(0) and
+// Percentile(1) match Min and Max.
+// If there are no vertices, the result is NaN.
+func (s *ShellThickness) Percentile(p float64) float64 {
+	if len(s.Thickness) == 0 {
+		return math.NaN()
+	}
+	values := make([]float64, 0, len(s.Thickness))
+	for _, t := range s.Thickness {
+		values = append(values, t)
+	}
+	sort.Float64s(values)
+	idx := int(math.Round(p * float64(len(values)-1)))
+	return values[essentials.MaxInt(0, essentials.MinInt(len(values)-1, idx))]
+}
+
+// Histogram counts the vertices in numBins equally sized
+// bins from 0 to maxThickness.
+//
+// Like Percentile, this counts vertices rather than
+// weighting them by area.
+// Thicknesses above maxThickness are counted in the last
+// bin.
+func (s *ShellThickness) Histogram(numBins int, maxThickness float64) []int {
+	res := make([]int, numBins)
+	for _, t := range s.Thickness {
+		bin := int(t / maxThickness * float64(numBins))
+		res[essentials.MinInt(bin, numBins-1)]++
+	}
+	return res
+}
+
+// ThinTriangles finds the triangles of the outer mesh with
+// at least one vertex thinner than a threshold, such as
+// the minimum wall thickness of a printer.
+func (s *ShellThickness) ThinTriangles(threshold float64) []*model3d.Triangle {
+	var res []*model3d.Triangle
+	s.Outer.Iterate(func(t *model3d.Triangle) {
+		for _, c := range t {
+			if s.Thickness[c] < threshold {
+				res = append(res, t)
+				return
+			}
+		}
+	})
+	return res
+}
+
+// ThinArea gets the total area of ThinTriangles().
+func (s *ShellThickness) ThinArea(threshold float64) float64 {
+	var res float64
+	for _, t := range s.ThinTriangles(threshold) {
+		res += t.Area()
+	}
+	return res
+}
+
+// TriangleColor creates a color function for the outer
+// mesh which highlights thin regions.
+//
+// The triangles from ThinTriangles() are red, and other
+// triangles range from yellow to green as their minimum
+// thickness increases to twice the threshold.
+//
+// The result can be passed to Mesh.SaveMaterialOBJ or
+// render3d.TriangleColorFunc.
+func (s *ShellThickness) TriangleColor(threshold float64) func(t *model3d.Triangle) [3]float64 {
+	return func(t *model3d.Triangle) [3]float64 {
+		thickness := math.Min(s.Thickness[t[0]], math.Min(s.Thickness[t[1]], s.Thickness[t[2]]))
+		if thickness < threshold {
+			return [3]float64{1, 0, 0}
+		}
+		frac := math.Min(1, (thickness-threshold)/threshold)
+		return [3]float64{1 - frac, 1, 0}
+	}
+}
+
+func (s *ShellThickness) triangleThickness(t *model3d.Triangle) float64 {
+	return (s.Thickness[t[0]] + s.Thickness[t[1]] + s.Thickness[t[2]]) / 3
+}
@@ -0,0 +1,79 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestMeasureShellThickness(t *testing.T) {
+	outer := model3d.NewMeshRect(model3d.XYZ(0, 0, 0), model3d.XYZ(4, 4, 4))
+	// The inner cavity is offset, so the walls are 0.5
+	// thick on the -X side and 1.5 thick on the +X side.
+	inner := model3d.NewMeshRect(model3d.XYZ(0.5, 1, 1), model3d.XYZ(2.5, 3, 3))
+	st := MeasureShellThickness(outer, inner)
+
+	if min := st.Min(); math.Abs(min-math.Sqrt(0.5*0.5+1+1)) > 1e-8 {
+		t.Errorf("unexpected min: %f", min)
+	}
+	if max := st.Max(); math.Abs(max-math.Sqrt(1.5*1.5+1+1)) > 1e-8 {
+		t.Errorf("unexpected max: %f", max)
+	}
+	if mean := st.Mean(); mean < st.Min() || mean > st.Max() {
+		t.Errorf("unexpected mean: %f", mean)
+	}
+	if p := st.Percentile(0); p != st.Min() {
+		t.Errorf("unexpected 0th percentile: %f", p)
+	}
+	if p := st.Percentile(1); p != st.Max() {
+		t.Errorf("unexpected 100th percentile: %f", p)
+	}
+
+	hist := st.Histogram(4, 4)
+	total := 0
+	for _, n := range hist {
+		total += n
+	}
+	if total != len(outer.VertexSlice()) {
+		t.Errorf("histogram has %d vertices", total)
+	}
+
+	thin := st.ThinTriangles(1.6)
+	if len(thin) == 0 || len(thin) == len(outer.TriangleSlice()) {
+		t.Fatalf("unexpected number of thin triangles: %d", len(thin))
+	}
+	for _, tri := range thin {
+		if tri.Min().X > 0 {
+			t.Errorf("unexpected thin triangle: %v", tri)
+		}
+	}
+	// Every face except the +X face touches a vertex on the
+	// thin side.
+	if area := st.ThinArea(1.6); math.Abs(area-80) > 1e-8 {
+		t.Errorf("unexpected thin area: %f", area)
+	}
+
+	colors := st.TriangleColor(1.6)
+	for _, tri := range thin {
+		if colors(tri) != [3]float64{1, 0, 0} {
+			t.Errorf("thin triangle should be red")
+		}
+	}
+}
+
+func TestShellThicknessEmpty(t *testing.T) {
+	st := MeasureShellThickness(model3d.NewMesh(), model3d.NewMeshRect(model3d.XYZ(0, 0, 0),
+		model3d.XYZ(1, 1, 1)))
+	if p := st.Percentile(0.5); !math.IsNaN(p) {
+		t.Errorf("expected NaN percentile but got %f", p)
+	}
+	if m := st.Mean(); !math.IsNaN(m) {
+		t.Errorf("expected NaN mean but got %f", m)
+	}
+	for _, n := range st.Histogram(3, 1) {
+		if n != 0 {
+			t.Error("expected empty histogram")
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A SignIsland is a connected piece of a 2D shape.
+type SignIsland struct {
+	// Points are the centers of the grid cells in the
+	// island.
+	Points []model2d.Coord
+
+	// Area is the approximate area of the island.
+	Area float64
+}
+
+// FindSignIslands finds the connected pieces of a 2D
+// shape, such as the dot and the stem of an "i", by flood
+// filling the shape on a grid.
+//
+// The delta is the side length of each grid cell.
+// Pieces separated by gaps narrower than delta may be
+// merged into a single island.
+func FindSignIslands(art model2d.Solid, delta float64) []*SignIsland {
+	min := art.Min()
+	size := art.Max().Sub(min)
+	nx := int(math.Ceil(size.X/delta)) + 1
+	ny := int(math.Ceil(size.Y/delta)) + 1
+
+	point := func(idx int) model2d.Coord {
+		return min.Add(model2d.XY(float64(idx%nx), float64(idx/nx)).Scale(delta))
+	}
+
+	// 0 for empty cells, 1 for unvisited filled cells, and
+	// 2 for visited filled cells.
+	state := make([]uint8, nx*ny)
+	for i := range state {
+		if art.Contains(point(i)) {
+			state[i] = 1
+		}
+	}
+
+	var res []*SignIsland
+	for start, s := range state {
+		if s != 1 {
+			continue
+		}
+		island := &SignIsland{}
+		queue := []int{start}
+		state[start] = 2
+		for len(queue) > 0 {
+			idx := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			island.Points = append(island.Points, point(idx))
+			x, y := idx%nx, idx/nx
+			neighbors := [4][3]int{
+				{x - 1, y, idx - 1}, {x + 1, y, idx + 1},
+				{x, y - 1, idx - nx}, {x, y + 1, idx + nx},
+			}
+			for _, n := range neighbors {
+				if n[0] < 0 || n[1] < 0 || n[0] >= nx || n[1] >= ny {
+					continue
+				}
+				if state[n[2]] == 1 {
+					state[n[2]] = 2
+					queue = append(queue, n[2])
+				}
+			}
+		}
+		island.Area = float64(len(island.Points)) * delta * delta
+		res = append(res, island)
+	}
+	return res
+}
+
+// A Sign turns 2D text or art into a single printable
+// piece, even if the art contains disconnected islands
+// like the dot on an "i".
+//
+// By default, islands are joined by thin sprues, which
+// form a minimal tree connecting every island.
+// Alternatively, the art can be raised from a backing
+// plate.
+type Sign struct {
+	// Art is the 2D shape of the sign.
+	Art model2d.Solid
+
+	// Thickness is the height of the extruded art.
+	Thickness float64
+
+	// SprueWidth is the width of the sprues connecting
+	// islands of the art.
+	SprueWidth float64
+
+	// SprueThickness is the height of the sprues, which
+	// start at the bottom of the art.
+	//
+	// If 0, Thickness is used.
+	SprueThickness float64
+
+	// BackingThickness, if non-zero, is the thickness of a
+	// backing plate which the art is raised from.
+	// If a backing is used, no sprues are added.
+	BackingThickness float64
+
+	// BackingMargin is the distance that the backing
+	// plate extends past the bounds of the art.
+	BackingMargin float64
+
+	// Delta is the grid size used to find islands.
+	//
+	// If 0, half of SprueWidth is used, or 1/100 of the
+	// largest side of the art's bounds if SprueWidth is
+	// also 0.
+	Delta float64
+}
+
+// Sprues computes the center lines of the sprues which
+// join the islands of the art.
+//
+// The sprues connect the closest points of pairs of
+// islands, and together they form a minimum spanning
+// tree over the islands.
+// Each sprue extends slightly into the islands it
+// connects so that it is fused with them.
+func (s *Sign) Sprues() []model2d.Segment {
+	delta := s.delta()
+	islands := FindSignIslands(s.Art, delta)
+	if len(islands) < 2 {
+		return nil
+	}
+	trees := make([]*model2d.CoordTree, len(islands))
+	for i, island := range islands {
+		trees[i] = model2d.NewCoordTree(island.Points)
+	}
+	closest := func(i, j int) model2d.Segment {
+		// Search from the smaller island for efficiency.
+		if len(islands[i].Points) > len(islands[j].Points) {
+			seg := closestSignPoints(islands[j].Points, trees[i])
+			return model2d.Segment{seg[1], seg[0]}
+		}
+		return closestSignPoints(islands[i].Points, trees[j])
+	}
+
+	// Prim's algorithm, using the distance between the
+	// closest points of each pair of islands.
+	inTree := make([]bool, len(islands))
+	bestSeg := make([]model2d.Segment, len(islands))
+	bestDist := make([]float64, len(islands))
+	for i := range bestDist {
+		bestDist[i] = math.Inf(1)
+	}
+	var res []model2d.Segment
+	current := 0
+	for n := 1; n < len(islands); n++ {
+		inTree[current] = true
+		next := -1
+		for i := range islands {
+			if inTree[i] {
+				continue
+			}
+			if seg := closest(current, i); seg.Length() < bestDist[i] {
+				bestDist[i] = seg.Length()
+				bestSeg[i] = seg
+			}
+			if next == -1 || bestDist[i] < bestDist[next] {
+				next = i
+			}
+		}
+		seg := bestSeg[next]
+		if length := seg.Length(); length > 0 {
+			dir := seg[1].Sub(seg[0]).Scale(1 / length)
+			seg[0] = seg[0].Sub(dir.Scale(delta))
+			seg[1] = seg[1].Add(dir.Scale(delta))
+		}
+		res = append(res, seg)
+		current = next
+	}
+	return res
+}
+
+// Solid creates the 3D sign, with its base on the plane
+// z=0.
+func (s *Sign) Solid() model3d.Solid {
+	if s.BackingThickness != 0 {
+		min := s.Art.Min().Sub(model2d.XY(s.BackingMargin, s.BackingMargin))
+		max := s.Art.Max().Add(model2d.XY(s.BackingMargin, s.BackingMargin))
+		return model3d.JoinedSolid{
+			&model3d.Rect{
+				MinVal: model3d.XYZ(min.X, min.Y, 0),
+				MaxVal: model3d.XYZ(max.X, max.Y, s.BackingThickness),
+			},
+			model3d.ProfileSolid(s.Art, s.BackingThickness, s.BackingThickness+s.Thickness),
+		}
+	}
+
+	res := model3d.JoinedSolid{model3d.ProfileSolid(s.Art, 0, s.Thickness)}
+	sprueThickness := s.SprueThickness
+	if sprueThickness == 0 {
+		sprueThickness = s.Thickness
+	}
+	var sprues model2d.JoinedSolid
+	for _, seg := range s.Sprues() {
+		sprues = append(sprues, signSprueSolid(seg, s.SprueWidth/2))
+	}
+	if len(sprues) > 0 {
+		res = append(res, model3d.ProfileSolid(sprues.Optimize(), 0, sprueThickness))
+	}
+	return res
+}
+
+func (s *Sign) delta() float64 {
+	if s.Delta != 0 {
+		return s.Delta
+	} else if s.SprueWidth != 0 {
+		return s.SprueWidth / 2
+	}
+	size := s.Art.Max().Sub(s.Art.Min())
+	return math.Max(size.X, size.Y) / 100
+}
+
+func closestSignPoints(points []model2d.Coord, tree *model2d.CoordTree) model2d.Segment {
+	var res model2d.Segment
+	bestDist := math.Inf(1)
+	for _, p := range points {
+		p1 := tree.NearestNeighbor(p)
+		if d := p.Dist(p1); d < bestDist {
+			bestDist = d
+			res = model2d.Segment{p, p1}
+		}
+	}
+	return res
+}
+
+func signSprueSolid(seg model2d.Segment, radius float64) model2d.Solid {
+	r := model2d.XY(radius, radius)
+	return model2d.CheckedFuncSolid(seg.Min().Sub(r), seg.Max().Add(r), func(c model2d.Coord) bool {
+		return seg.Dist(c) <= radius
+	})
+}
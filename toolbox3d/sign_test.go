@@ -0,0 +1,94 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestFindSignIslands(t *testing.T) {
+	art := testSignArt()
+	islands := FindSignIslands(art, 0.02)
+	if len(islands) != 3 {
+		t.Fatalf("expected 3 islands but got %d", len(islands))
+	}
+	var totalArea float64
+	for _, island := range islands {
+		totalArea += island.Area
+	}
+	expectedArea := 0.2*1 + math.Pi*0.1*0.1 + 0.6*0.4
+	if math.Abs(totalArea-expectedArea) > 0.05 {
+		t.Errorf("expected area %f but got %f", expectedArea, totalArea)
+	}
+}
+
+func TestSignSprues(t *testing.T) {
+	sign := &Sign{
+		Art:            testSignArt(),
+		Thickness:      0.3,
+		SprueWidth:     0.05,
+		SprueThickness: 0.1,
+	}
+	sprues := sign.Sprues()
+	if len(sprues) != 2 {
+		t.Fatalf("expected 2 sprues but got %d", len(sprues))
+	}
+	for _, seg := range sprues {
+		// The islands are at most 0.5 apart, and sprues
+		// extend slightly into each island.
+		if seg.Length() > 0.6 {
+			t.Errorf("sprue is too long: %v", seg)
+		}
+	}
+
+	solid := sign.Solid()
+	if min := solid.Min(); math.Abs(min.Z) > 1e-8 {
+		t.Errorf("unexpected min: %v", min)
+	}
+	bottom := model3d.CrossSectionSolid(solid, 2, 0.05)
+	if n := len(FindSignIslands(bottom, 0.01)); n != 1 {
+		t.Errorf("expected connected bottom layer but got %d islands", n)
+	}
+	top := model3d.CrossSectionSolid(solid, 2, 0.2)
+	if n := len(FindSignIslands(top, 0.01)); n != 3 {
+		t.Errorf("expected 3 islands above sprues but got %d", n)
+	}
+}
+
+func TestSignDefaultDelta(t *testing.T) {
+	// Neither Delta nor SprueWidth is set, so the grid
+	// size must come from the art itself.
+	sign := &Sign{Art: testSignArt(), Thickness: 0.3}
+	if n := len(sign.Sprues()); n != 2 {
+		t.Errorf("expected 2 sprues but got %d", n)
+	}
+}
+
+func TestSignBacking(t *testing.T) {
+	sign := &Sign{
+		Art:              testSignArt(),
+		Thickness:        0.2,
+		BackingThickness: 0.1,
+		BackingMargin:    0.1,
+		SprueWidth:       0.05,
+	}
+	solid := sign.Solid()
+	min, max := solid.Min(), solid.Max()
+	if min.Dist(model3d.XYZ(-0.1, -0.1, 0)) > 1e-8 || max.Dist(model3d.XYZ(1.4, 1.5, 0.3)) > 1e-8 {
+		t.Errorf("unexpected bounds: %v, %v", min, max)
+	}
+	if n := len(FindSignIslands(model3d.CrossSectionSolid(solid, 2, 0.25), 0.01)); n != 3 {
+		t.Errorf("expected 3 raised islands but got %d", n)
+	}
+}
+
+func testSignArt() model2d.Solid {
+	// An "i" next to a block.
+	return model2d.JoinedSolid{
+		model2d.NewRect(model2d.XY(0, 0), model2d.XY(0.2, 1)),
+		&model2d.Circle{Center: model2d.XY(0.1, 1.3), Radius: 0.1},
+		model2d.NewRect(model2d.XY(0.7, 0), model2d.XY(1.3, 0.4)),
+	}
+}
@@ -0,0 +1,204 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A Sundial describes a flat sundial plate with a gnomon
+// and engraved hour lines.
+//
+// The plate lies in the XY plane with its top surface at
+// z=Thickness, and the gnomon sticks out in the +Z
+// direction.
+// The hour lines radiate out of the origin.
+//
+// For a horizontal dial, +Y points towards the nearest
+// pole, and +X points east in the northern hemisphere or
+// west in the southern hemisphere.
+// For a vertical dial, the plate is meant to be mounted on
+// a wall facing the equator, with +Y pointing up and +X
+// pointing east in the northern hemisphere or west in the
+// southern hemisphere.
+//
+// As a result, a southern hemisphere dial is the mirror
+// image of the corresponding northern hemisphere dial.
+type Sundial struct {
+	// Latitude is the latitude of the dial in degrees,
+	// where negative values are south of the equator.
+	Latitude float64
+
+	// Longitude is the longitude of the dial in degrees,
+	// where negative values are west of Greenwich.
+	Longitude float64
+
+	// StandardMeridian is the longitude (in degrees) of the
+	// time zone's reference meridian, e.g. -75 for US
+	// Eastern Standard Time.
+	StandardMeridian float64
+
+	// Vertical is true for a vertical, equator-facing dial,
+	// and false for a horizontal dial.
+	Vertical bool
+
+	// Radius is the radius of the circular plate.
+	Radius float64
+
+	// Thickness is the thickness of the plate.
+	Thickness float64
+
+	// GnomonThickness is the thickness of the gnomon fin.
+	// The hour lines are split around the gnomon, so that
+	// the morning and afternoon lines line up with the
+	// corresponding edge of the fin.
+	GnomonThickness float64
+
+	// LineWidth and LineDepth determine the size of the
+	// engraved hour lines.
+	LineWidth float64
+	LineDepth float64
+
+	// StartHour and EndHour are the first and last hours
+	// (in local clock time, on a 24 hour clock) to mark.
+	//
+	// If both are 0, hours 6 through 18 are marked.
+	StartHour int
+	EndHour   int
+}
+
+// HourAngle computes the sun's hour angle (in degrees) at
+// a given local clock time, measured westward from the
+// local meridian.
+//
+// This ignores the equation of time, which can be
+// accounted for by adding EquationOfTime() to the clock
+// time.
+func (s *Sundial) HourAngle(clockHour float64) float64 {
+	return 15*(clockHour-12) + s.Longitude - s.StandardMeridian
+}
+
+// GnomonAngle gets the angle (in radians) between the
+// gnomon's style and the plate.
+func (s *Sundial) GnomonAngle() float64 {
+	lat := math.Abs(s.Latitude) * math.Pi / 180
+	if s.Vertical {
+		return math.Pi/2 - lat
+	}
+	return lat
+}
+
+// HourLineDirection gets the unit direction, in the
+// plate's coordinate system, of the gnomon's shadow at a
+// given local clock time.
+func (s *Sundial) HourLineDirection(clockHour float64) model2d.Coord {
+	h := s.HourAngle(clockHour) * math.Pi / 180
+	lat := math.Abs(s.Latitude) * math.Pi / 180
+	if s.Vertical {
+		theta := math.Atan2(math.Cos(lat)*math.Sin(h), math.Cos(h))
+		return model2d.XY(s.eastSign()*math.Sin(theta), -math.Cos(theta))
+	}
+	theta := math.Atan2(math.Sin(lat)*math.Sin(h), math.Cos(h))
+	return model2d.XY(s.eastSign()*math.Sin(theta), math.Cos(theta))
+}
+
+// eastSign is 1 if +X points east, or -1 if it points
+// west.
+func (s *Sundial) eastSign() float64 {
+	if s.Latitude < 0 {
+		return -1
+	}
+	return 1
+}
+
+// Solid creates the plate with its gnomon.
+func (s *Sundial) Solid() model3d.Solid {
+	plate := &model3d.Cylinder{P2: model3d.Z(s.Thickness), Radius: s.Radius}
+	return model3d.JoinedSolid{
+		&model3d.SubtractedSolid{
+			Positive: plate,
+			Negative: s.hourLines(),
+		},
+		s.gnomon(),
+	}
+}
+
+func (s *Sundial) hourLines() model3d.Solid {
+	start, end := s.StartHour, s.EndHour
+	if start == 0 && end == 0 {
+		start, end = 6, 18
+	}
+	var lines []*model2d.Segment
+	for hour := start; hour <= end; hour++ {
+		dir := s.HourLineDirection(float64(hour))
+		// Afternoon shadows are cast by the eastern edge
+		// of the gnomon, and vice versa.
+		edge := model2d.X(s.eastSign() * s.GnomonThickness / 2)
+		if s.HourAngle(float64(hour)) < 0 {
+			edge = edge.Scale(-1)
+		}
+		lines = append(lines, &model2d.Segment{
+			edge.Add(dir.Scale(s.Radius * 0.3)),
+			edge.Add(dir.Scale(s.Radius * 0.9)),
+		})
+	}
+	minZ := s.Thickness - s.LineDepth
+	halfWidth := s.LineWidth / 2
+	return model3d.CheckedFuncSolid(
+		model3d.XYZ(-s.Radius, -s.Radius, minZ),
+		model3d.XYZ(s.Radius, s.Radius, s.Thickness+1e-5),
+		func(c model3d.Coord3D) bool {
+			if c.Z < minZ {
+				return false
+			}
+			c2 := c.XY()
+			for _, l := range lines {
+				if l.CircleCollision(c2, halfWidth) {
+					return true
+				}
+			}
+			return false
+		},
+	)
+}
+
+func (s *Sundial) gnomon() model3d.Solid {
+	// The style rises out of the origin towards the pole,
+	// which is +Y for horizontal dials and -Y for vertical
+	// dials.
+	length := s.Radius * 0.8
+	slope := math.Tan(s.GnomonAngle())
+	height := length * slope
+	minY, maxY := 0.0, length
+	if s.Vertical {
+		minY, maxY = -length, 0
+	}
+	halfThickness := s.GnomonThickness / 2
+	return model3d.CheckedFuncSolid(
+		model3d.XYZ(-halfThickness, minY, s.Thickness-1e-5),
+		model3d.XYZ(halfThickness, maxY, s.Thickness+height),
+		func(c model3d.Coord3D) bool {
+			return c.Z-s.Thickness <= math.Abs(c.Y)*slope
+		},
+	)
+}
+
+// SunDeclination approximates the sun's declination (in
+// degrees) on a given day of the year, where 1 is January
+// 1st.
+func SunDeclination(day float64) float64 {
+	return -23.44 * math.Cos(2*math.Pi/365*(day+10))
+}
+
+// EquationOfTime approximates the difference (in hours)
+// between apparent solar time and mean solar time on a
+// given day of the year, where 1 is January 1st.
+//
+// Adding this to the local clock time gives the time that
+// a sundial should read.
+func EquationOfTime(day float64) float64 {
+	b := 2 * math.Pi * (day - 81) / 364
+	minutes := 9.87*math.Sin(2*b) - 7.53*math.Cos(b) - 1.5*math.Sin(b)
+	return minutes / 60
+}
@@ -0,0 +1,114 @@
+package toolbox3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/unixpickle/model3d/model2d"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestSundialHourLines(t *testing.T) {
+	for _, vertical := range []bool{false, true} {
+		s := &Sundial{
+			Latitude:         42,
+			Longitude:        -71,
+			StandardMeridian: -75,
+			Vertical:         vertical,
+		}
+		lat := s.Latitude * math.Pi / 180
+		for _, decl := range []float64{-20, 0, 15} {
+			for hour := 8.0; hour <= 16; hour += 0.5 {
+				h := s.HourAngle(hour) * math.Pi / 180
+				d := decl * math.Pi / 180
+
+				// Sun direction in (east, north, up) coordinates.
+				sun := model3d.XYZ(
+					-math.Cos(d)*math.Sin(h),
+					math.Cos(lat)*math.Sin(d)-math.Sin(lat)*math.Cos(d)*math.Cos(h),
+					math.Sin(lat)*math.Sin(d)+math.Cos(lat)*math.Cos(d)*math.Cos(h),
+				)
+				pole := model3d.XYZ(0, math.Cos(lat), math.Sin(lat))
+
+				var expected model2d.Coord
+				if vertical {
+					// Cast the shadow of the southward style
+					// onto the plane y=0.
+					tip := pole.Scale(-1)
+					shadow := tip.Sub(sun.Scale(tip.Y / sun.Y))
+					expected = model2d.XY(shadow.X, shadow.Z).Normalize()
+				} else {
+					shadow := pole.Sub(sun.Scale(pole.Z / sun.Z))
+					expected = shadow.XY().Normalize()
+				}
+				actual := s.HourLineDirection(hour)
+				if actual.Dist(expected) > 1e-5 {
+					t.Errorf("vertical=%v decl=%f hour=%f: expected %v but got %v",
+						vertical, decl, hour, expected, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestSundialSolid(t *testing.T) {
+	s := &Sundial{
+		Latitude:        45,
+		Radius:          5,
+		Thickness:       0.5,
+		GnomonThickness: 0.2,
+		LineWidth:       0.1,
+		LineDepth:       0.2,
+	}
+	solid := s.Solid()
+	if !solid.Contains(model3d.XYZ(0, 2, 1.5)) {
+		t.Error("expected point inside gnomon")
+	}
+	if solid.Contains(model3d.XYZ(0, 2, 2.7)) {
+		t.Error("expected point above gnomon")
+	}
+	noon := s.HourLineDirection(12).Scale(3)
+	if solid.Contains(model3d.XYZ(0.1, noon.Y, 0.45)) {
+		t.Error("expected noon line to be engraved")
+	}
+	if !solid.Contains(model3d.XYZ(-2, -2, 0.45)) {
+		t.Error("expected solid plate away from lines")
+	}
+}
+
+func TestSundialSouthernMirror(t *testing.T) {
+	for _, vertical := range []bool{false, true} {
+		north := &Sundial{
+			Latitude:        35,
+			Longitude:       3,
+			Vertical:        vertical,
+			Radius:          5,
+			Thickness:       0.5,
+			GnomonThickness: 0.2,
+			LineWidth:       0.1,
+			LineDepth:       0.2,
+		}
+		south := *north
+		south.Latitude = -north.Latitude
+
+		for hour := 6.0; hour <= 18; hour += 0.5 {
+			expected := north.HourLineDirection(hour).Mul(model2d.XY(-1, 1))
+			actual := south.HourLineDirection(hour)
+			if actual.Dist(expected) > 1e-8 {
+				t.Errorf("vertical=%v hour=%f: expected %v but got %v", vertical, hour,
+					expected, actual)
+			}
+		}
+
+		northSolid, southSolid := north.Solid(), south.Solid()
+		gen := rand.New(rand.NewSource(0))
+		for i := 0; i < 10000; i++ {
+			c := model3d.XYZ(gen.Float64()*10-5, gen.Float64()*10-5, gen.Float64()*0.6)
+			mirrored := model3d.XYZ(-c.X, c.Y, c.Z)
+			if northSolid.Contains(c) != southSolid.Contains(mirrored) {
+				t.Fatalf("vertical=%v: solids differ at %v", vertical, c)
+			}
+		}
+	}
+}
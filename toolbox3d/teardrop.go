@@ -2,11 +2,44 @@ package toolbox3d
 
 import (
 	"math"
+	"sort"
 
 	"github.com/unixpickle/model3d/model2d"
 	"github.com/unixpickle/model3d/model3d"
 )
 
+const (
+	// teardropPatchAngle is the maximum angle between
+	// adjacent triangles on the wall of a detected hole.
+	teardropPatchAngle = math.Pi * 5 / 18
+
+	// teardropMaxVertical is the largest component of a
+	// normal along the build direction for a triangle to
+	// be considered part of a hole wall.
+	teardropMaxVertical = 0.95
+
+	// teardropMinArc is the smallest angle around a hole
+	// which a patch of its wall must cover.
+	teardropMinArc = 2 * math.Pi / 3
+
+	// teardropAxisTolerance is the maximum component of a
+	// hole wall's normals along the axis of the hole.
+	teardropAxisTolerance = 0.1
+
+	// teardropRadiusTolerance is the maximum deviation of
+	// a hole wall's vertices from the fit circle, relative
+	// to the radius.
+	teardropRadiusTolerance = 0.1
+
+	// teardropFitIterations is the maximum number of times
+	// a circle is refit after discarding outliers.
+	teardropFitIterations = 3
+
+	// teardropMinInliers is the minimum fraction of a
+	// patch's area which must lie on a hole's circle.
+	teardropMinInliers = 0.75
+)
+
 // Teardrop2D is a 2D solid in a "teardrop" shape, i.e. a
 // circle with a tangent triangle pointing off in one
 // direction.
@@ -92,14 +125,153 @@ func (t *Teardrop2D) Contains(c model2d.Coord) bool {
 // If possible, the point of the teardrop will be facing
 // into the positive Z direction to avoid supports.
 func Teardrop3D(p1, p2 model3d.Coord3D, radius float64) model3d.Solid {
+	return teardropSolid(p1, p2, radius, model3d.Z(1), false)
+}
+
+// A CylindricalHole is a straight, round hole in a part.
+type CylindricalHole struct {
+	// P1 and P2 are the centers of the ends of the hole.
+	P1 model3d.Coord3D
+	P2 model3d.Coord3D
+
+	Radius float64
+}
+
+// FindHorizontalHoles detects round holes in a mesh whose
+// axes are perpendicular to the build direction, i.e. the
+// holes which would have overhanging ceilings when printed.
+//
+// Holes are found by grouping smoothly connected triangles
+// and fitting cylinders to the groups, so curved walls
+// must be made up of a reasonable number of segments.
+// Both through-holes and blind holes are detected, as are
+// partial holes which are cut off by other features, as
+// long as the sides of the circle are present.
+func FindHorizontalHoles(m *model3d.Mesh, buildDir model3d.Coord3D) []*CylindricalHole {
+	buildDir = buildDir.Normalize()
+	var res []*CylindricalHole
+	for _, patch := range teardropWallPatches(m, buildDir) {
+		hole := teardropFitHole(patch, buildDir)
+		if hole == nil {
+			continue
+		}
+		// Patches on either side of a hole are merged.
+		merged := false
+		for _, other := range res {
+			if teardropSameHole(hole, other) {
+				teardropMergeHoles(other, hole)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			res = append(res, hole)
+		}
+	}
+	return res
+}
+
+func teardropSameHole(h1, h2 *CylindricalHole) bool {
+	axis1 := h1.P2.Sub(h1.P1).Normalize()
+	axis2 := h2.P2.Sub(h2.P1).Normalize()
+	if axis1.Cross(axis2).Norm() > teardropAxisTolerance {
+		return false
+	}
+	radius := math.Max(h1.Radius, h2.Radius)
+	return math.Abs(h1.Radius-h2.Radius) < teardropRadiusTolerance*radius &&
+		h2.P1.Sub(h1.P1).ProjectOut(axis1).Norm() < teardropRadiusTolerance*radius
+}
+
+// teardropMergeHoles extends dst to cover both holes,
+// averaging their radii.
+func teardropMergeHoles(dst, src *CylindricalHole) {
+	axis := dst.P2.Sub(dst.P1).Normalize()
+	minT, maxT := dst.P1.Dot(axis), dst.P2.Dot(axis)
+	for _, p := range []model3d.Coord3D{src.P1, src.P2} {
+		minT = math.Min(minT, p.Dot(axis))
+		maxT = math.Max(maxT, p.Dot(axis))
+	}
+	base := dst.P1.Sub(axis.Scale(dst.P1.Dot(axis)))
+	dst.P1 = base.Add(axis.Scale(minT))
+	dst.P2 = base.Add(axis.Scale(maxT))
+	dst.Radius = (dst.Radius + src.Radius) / 2
+}
+
+// A TeardropConverter modifies parts so that horizontal
+// holes can be printed without supports, by extending the
+// top of each hole into a point.
+type TeardropConverter struct {
+	// BuildDirection is the upward direction when the part
+	// is printed.
+	// If this is the zero vector, the Z axis is used.
+	BuildDirection model3d.Coord3D
+
+	// MinDiameter is the smallest hole diameter which is
+	// converted, since small holes can usually bridge
+	// without drooping.
+	MinDiameter float64
+
+	// Diamond, if true, makes holes symmetric by extending
+	// the bottom into a point as well as the top, so that
+	// the holes are diamond-shaped.
+	Diamond bool
+}
+
+// Solid converts the given holes in a solid.
+//
+// Holes which are not perpendicular to the build
+// direction, or which are too small, are left unchanged.
+func (t *TeardropConverter) Solid(s model3d.Solid, holes []*CylindricalHole) model3d.Solid {
+	up := t.buildDirection()
+	var negative model3d.JoinedSolid
+	for _, h := range holes {
+		axis := h.P2.Sub(h.P1)
+		if 2*h.Radius < t.MinDiameter || math.Abs(axis.Normalize().Dot(up)) > 1e-3 {
+			continue
+		}
+		// Extend the cut slightly so that it reaches the
+		// faces at the ends of the hole.
+		extra := axis.Normalize().Scale(h.Radius * 0.01)
+		negative = append(negative, teardropSolid(h.P1.Sub(extra), h.P2.Add(extra), h.Radius,
+			up, t.Diamond))
+	}
+	if len(negative) == 0 {
+		return s
+	}
+	return &model3d.SubtractedSolid{Positive: s, Negative: negative.Optimize()}
+}
+
+// Mesh detects horizontal holes in a mesh and converts
+// them, returning a solid which can be re-meshed.
+func (t *TeardropConverter) Mesh(m *model3d.Mesh) model3d.Solid {
+	holes := FindHorizontalHoles(m, t.buildDirection())
+	return t.Solid(model3d.NewColliderSolid(model3d.MeshToCollider(m)), holes)
+}
+
+func (t *TeardropConverter) buildDirection() model3d.Coord3D {
+	if (t.BuildDirection == model3d.Coord3D{}) {
+		return model3d.Z(1)
+	}
+	return t.BuildDirection.Normalize()
+}
+
+// teardropSolid extends a teardrop between p1 and p2 with
+// the point facing in the up direction.
+func teardropSolid(p1, p2 model3d.Coord3D, radius float64, up model3d.Coord3D,
+	diamond bool) model3d.Solid {
 	length := p1.Dist(p2)
-	profileSolid := model3d.ProfileSolid(&Teardrop2D{
-		Radius: radius,
-	}, 0, length)
+	var profile model2d.Solid = &Teardrop2D{Radius: radius}
+	if diamond {
+		profile = model2d.JoinedSolid{
+			profile,
+			&Teardrop2D{Radius: radius, Direction: model2d.Y(-1)},
+		}
+	}
+	profileSolid := model3d.ProfileSolid(profile, 0, length)
 	zVec := p2.Sub(p1).Normalize()
-	yVec := model3d.Z(1).ProjectOut(zVec)
+	yVec := up.ProjectOut(zVec)
 	if yVec.Norm() < 1e-5 {
-		// If the profile is extended into the Z direction,
+		// If the profile is extended in the up direction,
 		// then we default to use the Y axis for the tip.
 		yVec = model3d.Y(1).ProjectOut(zVec).Normalize()
 	} else {
@@ -113,3 +285,207 @@ func Teardrop3D(p1, p2 model3d.Coord3D, radius float64) model3d.Solid {
 	}
 	return model3d.TransformSolid(xform, profileSolid)
 }
+
+// teardropWallPatches groups the triangles of a mesh into
+// connected patches which could be part of the wall of a
+// horizontal hole.
+//
+// Each triangle in such a wall has a horizontal direction
+// perpendicular to its normal, which is the axis of the
+// hole.
+// Patches are grown from a seed triangle, only adding
+// triangles which are perpendicular to the seed's axis, so
+// that a hole's wall does not merge with the surfaces
+// around it.
+// Triangles facing nearly up or down do not have a stable
+// axis, so they are left out, splitting each hole into
+// patches on either side.
+func teardropWallPatches(m *model3d.Mesh, up model3d.Coord3D) [][]*model3d.Triangle {
+	minNormalCos := math.Cos(teardropPatchAngle)
+
+	visited := map[*model3d.Triangle]bool{}
+	var res [][]*model3d.Triangle
+	m.Iterate(func(seed *model3d.Triangle) {
+		if visited[seed] || !teardropWallCandidate(seed, up) {
+			return
+		}
+		visited[seed] = true
+		axis := seed.Normal().Cross(up).Normalize()
+		patch := []*model3d.Triangle{seed}
+		for i := 0; i < len(patch); i++ {
+			t := patch[i]
+			n := t.Normal()
+			// Neighbors share vertices rather than edges,
+			// since degenerate triangles (which are common
+			// in marching cubes meshes) would otherwise
+			// disconnect patches.
+			var neighbors []*model3d.Triangle
+			for _, c := range t {
+				neighbors = append(neighbors, m.Find(c)...)
+			}
+			for _, neighbor := range neighbors {
+				if visited[neighbor] || !teardropWallCandidate(neighbor, up) {
+					continue
+				}
+				n1 := neighbor.Normal()
+				if n.Dot(n1) >= minNormalCos &&
+					math.Abs(n1.Dot(axis)) <= teardropAxisTolerance {
+					visited[neighbor] = true
+					patch = append(patch, neighbor)
+				}
+			}
+		}
+		res = append(res, patch)
+	})
+	return res
+}
+
+func teardropWallCandidate(t *model3d.Triangle, up model3d.Coord3D) bool {
+	return t.Area() > 0 && math.Abs(t.Normal().Dot(up)) <= teardropMaxVertical
+}
+
+// teardropFitHole fits a horizontal cylindrical hole to a
+// patch of triangles, or returns nil if the patch is not
+// part of a hole.
+//
+// Triangles which do not lie on the fit circle, such as
+// where the hole intersects other features, are discarded
+// and the circle is refit, as long as most of the patch
+// remains.
+func teardropFitHole(patch []*model3d.Triangle, up model3d.Coord3D) *CylindricalHole {
+	if len(patch) < 6 {
+		return nil
+	}
+
+	// Find the horizontal axis which is most perpendicular
+	// to the normals of the patch.
+	b1, b2 := up.OrthoBasis()
+	var xx, xy, yy float64
+	for _, t := range patch {
+		w := t.Area()
+		n := t.Normal()
+		x, y := n.Dot(b1), n.Dot(b2)
+		xx += w * x * x
+		xy += w * x * y
+		yy += w * y * y
+	}
+	theta := 0.5*math.Atan2(2*xy, xx-yy) + math.Pi/2
+	axis := b1.Scale(math.Cos(theta)).Add(b2.Scale(math.Sin(theta)))
+	for _, t := range patch {
+		if math.Abs(t.Normal().Dot(axis)) > teardropAxisTolerance {
+			return nil
+		}
+	}
+
+	side := axis.Cross(up)
+	project := func(c model3d.Coord3D) model2d.Coord {
+		return model2d.XY(c.Dot(side), c.Dot(up))
+	}
+	onCircle := func(t *model3d.Triangle, center model2d.Coord, radius float64) bool {
+		for _, c := range t {
+			if math.Abs(project(c).Dist(center)-radius) > teardropRadiusTolerance*radius {
+				return false
+			}
+		}
+		return true
+	}
+
+	totalArea := teardropArea(patch)
+	tris := patch
+	var center model2d.Coord
+	var radius float64
+	for i := 0; i < teardropFitIterations; i++ {
+		var ok bool
+		center, radius, ok = teardropFitCircle(tris, project)
+		if !ok {
+			return nil
+		}
+		var inliers []*model3d.Triangle
+		for _, t := range tris {
+			if onCircle(t, center, radius) {
+				inliers = append(inliers, t)
+			}
+		}
+		if len(inliers) == len(tris) {
+			break
+		} else if teardropArea(inliers) < teardropMinInliers*totalArea {
+			return nil
+		}
+		tris = inliers
+	}
+
+	// Make sure the vertices lie on the circle, and cover
+	// enough of it, and that the normals face the center
+	// so that this is a hole rather than a boss.
+	var angles []float64
+	minT, maxT := math.Inf(1), math.Inf(-1)
+	for _, t := range tris {
+		if !onCircle(t, center, radius) {
+			return nil
+		}
+		for _, c := range t {
+			offset := project(c).Sub(center)
+			angles = append(angles, math.Atan2(offset.Y, offset.X))
+			minT = math.Min(minT, c.Dot(axis))
+			maxT = math.Max(maxT, c.Dot(axis))
+		}
+		mid := project(t[0].Add(t[1]).Add(t[2]).Scale(1.0 / 3))
+		n := t.Normal()
+		if center.Sub(mid).Dot(model2d.XY(n.Dot(side), n.Dot(up))) <= 0 {
+			return nil
+		}
+	}
+	sort.Float64s(angles)
+	maxGap := angles[0] + 2*math.Pi - angles[len(angles)-1]
+	for i := 1; i < len(angles); i++ {
+		maxGap = math.Max(maxGap, angles[i]-angles[i-1])
+	}
+	if 2*math.Pi-maxGap < teardropMinArc {
+		return nil
+	}
+
+	center3D := side.Scale(center.X).Add(up.Scale(center.Y))
+	return &CylindricalHole{
+		P1:     center3D.Add(axis.Scale(minT)),
+		P2:     center3D.Add(axis.Scale(maxT)),
+		Radius: radius,
+	}
+}
+
+// teardropFitCircle fits a circle to the projected
+// vertices of some triangles using least squares.
+func teardropFitCircle(tris []*model3d.Triangle,
+	project func(c model3d.Coord3D) model2d.Coord) (model2d.Coord, float64, bool) {
+	var ata model3d.Matrix3
+	var atb model3d.Coord3D
+	for _, t := range tris {
+		for _, c := range t {
+			p := project(c)
+			row := model3d.XYZ(p.X, p.Y, 1)
+			for i, x := range row.Array() {
+				for j, y := range row.Array() {
+					ata[i*3+j] += x * y
+				}
+			}
+			atb = atb.Sub(row.Scale(p.Dot(p)))
+		}
+	}
+	if math.Abs(ata.Det()) < 1e-12 {
+		return model2d.Coord{}, 0, false
+	}
+	solution := ata.Inverse().MulColumn(atb)
+	center := model2d.XY(-solution.X/2, -solution.Y/2)
+	radius := math.Sqrt(center.Dot(center) - solution.Z)
+	if math.IsNaN(radius) || radius == 0 {
+		return model2d.Coord{}, 0, false
+	}
+	return center, radius, true
+}
+
+func teardropArea(tris []*model3d.Triangle) float64 {
+	var res float64
+	for _, t := range tris {
+		res += t.Area()
+	}
+	return res
+}
@@ -0,0 +1,105 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestFindHorizontalHoles(t *testing.T) {
+	mesh := teardropTestMesh()
+	holes := FindHorizontalHoles(mesh, model3d.Z(1))
+	if len(holes) != 2 {
+		t.Fatalf("expected 2 holes but got %d", len(holes))
+	}
+	if holes[0].Radius < holes[1].Radius {
+		holes[0], holes[1] = holes[1], holes[0]
+	}
+
+	// Hole along the X axis.
+	h := holes[0]
+	if math.Abs(h.Radius-3) > 0.1 {
+		t.Errorf("unexpected radius: %f", h.Radius)
+	}
+	for _, p := range []model3d.Coord3D{h.P1, h.P2} {
+		if math.Abs(p.Y-5.03) > 0.1 || math.Abs(p.Z-5.07) > 0.1 {
+			t.Errorf("unexpected hole endpoint: %v", p)
+		}
+	}
+	if math.Abs(math.Abs(h.P2.X-h.P1.X)-20) > 0.2 {
+		t.Errorf("unexpected hole length: %f", h.P1.Dist(h.P2))
+	}
+
+	// Hole along the Y axis.
+	h = holes[1]
+	if math.Abs(h.Radius-1.5) > 0.1 {
+		t.Errorf("unexpected radius: %f", h.Radius)
+	}
+	if math.Abs(h.P1.X-15.03) > 0.1 || math.Abs(h.P1.Z-2.53) > 0.1 {
+		t.Errorf("unexpected hole endpoint: %v", h.P1)
+	}
+
+	// With a different build direction, the vertical hole
+	// becomes horizontal and the X hole stays horizontal.
+	holes = FindHorizontalHoles(mesh, model3d.Y(1))
+	if len(holes) != 2 {
+		t.Fatalf("expected 2 holes but got %d", len(holes))
+	}
+	if holes[0].Radius > holes[1].Radius {
+		holes[0], holes[1] = holes[1], holes[0]
+	}
+	h = holes[0]
+	if math.Abs(h.Radius-1) > 0.1 {
+		t.Errorf("unexpected radius: %f", h.Radius)
+	}
+	if math.Abs(h.P1.X-h.P2.X) > 0.1 || math.Abs(h.P1.Y-h.P2.Y) > 0.1 {
+		t.Errorf("unexpected hole axis: %v, %v", h.P1, h.P2)
+	}
+}
+
+func TestTeardropConverter(t *testing.T) {
+	mesh := teardropTestMesh()
+	for _, diamond := range []bool{false, true} {
+		converter := &TeardropConverter{MinDiameter: 4, Diamond: diamond}
+		solid := converter.Mesh(mesh)
+
+		// The large hole should have a point at the top.
+		if solid.Contains(model3d.XYZ(10, 5.03, 5.07+3*1.3)) {
+			t.Error("expected teardrop tip to be removed")
+		}
+		if !solid.Contains(model3d.XYZ(10, 5.03, 5.07+3*1.5)) {
+			t.Error("expected material above teardrop tip")
+		}
+		if solid.Contains(model3d.XYZ(10, 5.03, 5.07-3*1.3)) != !diamond {
+			t.Errorf("unexpected bottom of hole (diamond=%v)", diamond)
+		}
+
+		// The small hole should be unchanged.
+		if !solid.Contains(model3d.XYZ(15.03, 8, 2.53+1.5*1.2)) {
+			t.Error("expected small hole to be unchanged")
+		}
+
+		// The vertical hole should be unchanged.
+		if !solid.Contains(model3d.XYZ(3.03, 6.37, 9)) {
+			t.Error("expected vertical hole to be unchanged")
+		}
+	}
+}
+
+func teardropTestMesh() *model3d.Mesh {
+	solid := &model3d.SubtractedSolid{
+		Positive: model3d.NewRect(model3d.Coord3D{}, model3d.XYZ(20, 10, 10)),
+		Negative: model3d.JoinedSolid{
+			// Holes are offset slightly from the grid, since
+			// marching cubes creates stair-steps where a
+			// surface passes through grid points.
+			&model3d.Cylinder{P1: model3d.XYZ(-1, 5.03, 5.07), P2: model3d.XYZ(21, 5.03, 5.07),
+				Radius: 3},
+			&model3d.Cylinder{P1: model3d.XYZ(15.03, -1, 2.53), P2: model3d.XYZ(15.03, 11, 2.53),
+				Radius: 1.5},
+			&model3d.Cylinder{P1: model3d.XYZ(3.03, 5.07, -1), P2: model3d.XYZ(3.03, 5.07, 11), Radius: 1},
+		},
+	}
+	return model3d.MarchingCubesSearch(solid, 0.2, 8)
+}
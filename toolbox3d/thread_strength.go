@@ -0,0 +1,121 @@
+package toolbox3d
+
+import "math"
+
+// A ThreadStrength estimates how much force a threaded
+// connection can withstand before the threads strip.
+//
+// The screw and hole are assumed to be made with
+// ScrewSolid, where the hole is a ScrewSolid with a
+// slightly larger radius.
+//
+// All quantities should use consistent units, e.g.
+// millimeters and MPa give forces in Newtons.
+type ThreadStrength struct {
+	// Screw is the external thread.
+	Screw *ScrewSolid
+
+	// Clearance is the radial gap between the screw and
+	// the screw hole, i.e. the hole's radius minus the
+	// screw's radius.
+	Clearance float64
+
+	// EngagementLength is the length along the axis where
+	// the threads overlap.
+	// If 0, the length of the screw is used.
+	EngagementLength float64
+
+	// ShearStrength is the shear strength of the material.
+	// For printed parts, this should account for layer
+	// adhesion, which is usually the weakest direction.
+	ShearStrength float64
+}
+
+// EngagedFraction gets the fraction of each thread's depth
+// that overlaps with the mating thread.
+//
+// This is a unitless ratio of radial depths, not the
+// fraction of a shear surface that is filled by threads,
+// which also depends on the thread profile.
+func (t *ThreadStrength) EngagedFraction() float64 {
+	return math.Max(0, (t.Screw.GrooveSize-t.Clearance)/t.Screw.GrooveSize)
+}
+
+// ExternalShearArea computes the area which must shear for
+// the screw's threads to strip.
+//
+// This is a cylinder at the tips of the hole's threads,
+// where only part of the cylinder is filled by the screw's
+// threads, depending on the thread profile.
+// For example, VThread screws fill EngagedFraction() of
+// the cylinder, while SquareThread screws fill half of it.
+func (t *ThreadStrength) ExternalShearArea() float64 {
+	if t.EngagedFraction() == 0 {
+		return 0
+	}
+	depth := t.Screw.GrooveSize - t.Clearance
+	r := t.Screw.Radius - depth
+	return 2 * math.Pi * r * t.length() * t.threadFill(depth)
+}
+
+// InternalShearArea computes the area which must shear for
+// the hole's threads to strip.
+//
+// This is a cylinder at the tips of the screw's threads,
+// where only part of the cylinder is filled by the hole's
+// threads, like for ExternalShearArea().
+func (t *ThreadStrength) InternalShearArea() float64 {
+	if t.EngagedFraction() == 0 {
+		return 0
+	}
+	return 2 * math.Pi * t.Screw.Radius * t.length() * (1 - t.threadFill(t.Clearance))
+}
+
+// PullOutForce estimates the axial force needed to strip
+// the threads, assuming the screw and the hole are made of
+// the same material.
+func (t *ThreadStrength) PullOutForce() float64 {
+	return t.ShearStrength * math.Min(t.ExternalShearArea(), t.InternalShearArea())
+}
+
+// RequiredEngagement estimates the engagement length
+// needed to withstand a given pull-out force.
+//
+// The result is infinite if the threads are not engaged,
+// e.g. because the clearance exceeds the groove size.
+func (t *ThreadStrength) RequiredEngagement(force float64) float64 {
+	unit := *t
+	unit.EngagementLength = 1
+	perLength := unit.PullOutForce()
+	if perLength == 0 {
+		return math.Inf(1)
+	}
+	return force / perLength
+}
+
+// threadFill computes the fraction of the screw's axial
+// length which is inside its threads at a given depth
+// below its outer radius, matching the shapes created by
+// ScrewSolid.
+func (t *ThreadStrength) threadFill(depth float64) float64 {
+	g := t.Screw.GrooveSize
+	var width float64
+	switch t.Screw.Profile {
+	case TrapezoidalThread:
+		width = 2 * (0.366*g + depth*math.Tan(math.Pi/12))
+	case ButtressThread:
+		width = g/2 + depth
+	case SquareThread:
+		width = g
+	default:
+		width = 2 * depth
+	}
+	return math.Max(0, math.Min(1, width/(2*g)))
+}
+
+func (t *ThreadStrength) length() float64 {
+	if t.EngagementLength != 0 {
+		return t.EngagementLength
+	}
+	return t.Screw.P1.Dist(t.Screw.P2)
+}
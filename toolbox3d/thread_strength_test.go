@@ -0,0 +1,74 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestThreadStrength(t *testing.T) {
+	ts := &ThreadStrength{
+		Screw: &ScrewSolid{
+			P2:         model3d.Z(10),
+			Radius:     4,
+			GrooveSize: 1,
+		},
+		Clearance:     0.2,
+		ShearStrength: 30,
+	}
+	if f := ts.EngagedFraction(); math.Abs(f-0.8) > 1e-8 {
+		t.Errorf("unexpected engaged fraction: %f", f)
+	}
+	expected := 30 * 2 * math.Pi * 3.2 * 10 * 0.8
+	if f := ts.PullOutForce(); math.Abs(f-expected) > 1e-8 {
+		t.Errorf("expected force %f but got %f", expected, f)
+	}
+	if l := ts.RequiredEngagement(expected / 2); math.Abs(l-5) > 1e-8 {
+		t.Errorf("expected engagement 5 but got %f", l)
+	}
+
+	ts.Clearance = 1.5
+	if ts.PullOutForce() != 0 || !math.IsInf(ts.RequiredEngagement(1), 1) {
+		t.Error("expected threads to be disengaged")
+	}
+}
+
+func TestThreadStrengthProfiles(t *testing.T) {
+	for _, profile := range []ThreadProfile{VThread, TrapezoidalThread, ButtressThread,
+		SquareThread} {
+		ts := &ThreadStrength{
+			Screw: &ScrewSolid{
+				P2:         model3d.Z(10),
+				Radius:     4,
+				GrooveSize: 1,
+				Profile:    profile,
+			},
+			Clearance:     0.2,
+			ShearStrength: 30,
+		}
+
+		// Measure the fraction of each shear cylinder filled
+		// by the screw's threads directly.
+		fill := func(depth float64) float64 {
+			var count int
+			for i := 0; i < 10000; i++ {
+				z := 5 + 2*(float64(i)+0.5)/10000
+				if threadContains(profile, 1, 1, z, 0, depth) {
+					count++
+				}
+			}
+			return float64(count) / 10000
+		}
+		external := 2 * math.Pi * 3.2 * 10 * fill(0.8)
+		internal := 2 * math.Pi * 4 * 10 * (1 - fill(0.2))
+		if a := ts.ExternalShearArea(); math.Abs(a-external) > 0.01*external {
+			t.Errorf("profile %d: expected external area %f but got %f", profile,
+				external, a)
+		}
+		if a := ts.InternalShearArea(); math.Abs(a-internal) > 0.01*internal {
+			t.Errorf("profile %d: expected internal area %f but got %f", profile,
+				internal, a)
+		}
+	}
+}
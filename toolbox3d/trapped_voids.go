@@ -0,0 +1,188 @@
+package toolbox3d
+
+import (
+	"math"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// A TrappedVoid is an empty region inside of a solid which
+// has no path to the outside, such as the cavity of a
+// hollowed model.
+//
+// Uncured resin or unfused powder cannot escape from such
+// regions unless drain holes are added.
+type TrappedVoid struct {
+	// Points are the centers of the grid cells in the
+	// void.
+	Points []model3d.Coord3D
+
+	// Volume is the approximate volume of the void.
+	Volume float64
+}
+
+// Min gets the minimum point of the void's cells.
+func (t *TrappedVoid) Min() model3d.Coord3D {
+	res := t.Points[0]
+	for _, p := range t.Points[1:] {
+		res = res.Min(p)
+	}
+	return res
+}
+
+// Max gets the maximum point of the void's cells.
+func (t *TrappedVoid) Max() model3d.Coord3D {
+	res := t.Points[0]
+	for _, p := range t.Points[1:] {
+		res = res.Max(p)
+	}
+	return res
+}
+
+// Extreme finds the point in the void which is closest to
+// a face of the solid's bounding box.
+func (t *TrappedVoid) Extreme(face BoxFace) model3d.Coord3D {
+	normal := boxFaceNormal(face)
+	res := t.Points[0]
+	for _, p := range t.Points[1:] {
+		if p.Dot(normal) > res.Dot(normal) {
+			res = p
+		}
+	}
+	return res
+}
+
+// FindTrappedVoids finds the enclosed voids in a solid by
+// flood filling the empty space around it on a grid.
+//
+// The delta is the side length of each grid cell.
+// Openings narrower than delta may not be found, so delta
+// should be smaller than the smallest drain hole.
+//
+// To analyze a mesh, wrap it in a solid with
+// model3d.NewColliderSolid(model3d.MeshToCollider(mesh)).
+func FindTrappedVoids(solid model3d.Solid, delta float64) []*TrappedVoid {
+	// Pad the grid so that the outside is connected.
+	min := solid.Min().Sub(model3d.XYZ(delta, delta, delta))
+	max := solid.Max().Add(model3d.XYZ(delta, delta, delta))
+	size := max.Sub(min)
+	nx := int(math.Ceil(size.X/delta)) + 1
+	ny := int(math.Ceil(size.Y/delta)) + 1
+	nz := int(math.Ceil(size.Z/delta)) + 1
+
+	point := func(idx int) model3d.Coord3D {
+		x := idx % nx
+		y := (idx / nx) % ny
+		z := idx / (nx * ny)
+		return min.Add(model3d.XYZ(float64(x)+0.5, float64(y)+0.5, float64(z)+0.5).Scale(delta))
+	}
+
+	// 0 for unvisited empty cells, 1 for filled cells, and
+	// 2 for visited empty cells.
+	state := make([]uint8, nx*ny*nz)
+	for i := range state {
+		if solid.Contains(point(i)) {
+			state[i] = 1
+		}
+	}
+
+	fill := func(start int) []int {
+		var visited []int
+		queue := []int{start}
+		state[start] = 2
+		for len(queue) > 0 {
+			idx := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			visited = append(visited, idx)
+			x := idx % nx
+			y := (idx / nx) % ny
+			z := idx / (nx * ny)
+			neighbors := [6][4]int{
+				{x - 1, y, z, idx - 1}, {x + 1, y, z, idx + 1},
+				{x, y - 1, z, idx - nx}, {x, y + 1, z, idx + nx},
+				{x, y, z - 1, idx - nx*ny}, {x, y, z + 1, idx + nx*ny},
+			}
+			for _, n := range neighbors {
+				if n[0] < 0 || n[1] < 0 || n[2] < 0 || n[0] >= nx || n[1] >= ny || n[2] >= nz {
+					continue
+				}
+				if state[n[3]] == 0 {
+					state[n[3]] = 2
+					queue = append(queue, n[3])
+				}
+			}
+		}
+		return visited
+	}
+
+	// The corner of the padded grid is always outside.
+	fill(0)
+
+	var res []*TrappedVoid
+	cellVolume := delta * delta * delta
+	for i, s := range state {
+		if s != 0 {
+			continue
+		}
+		cells := fill(i)
+		void := &TrappedVoid{
+			Points: make([]model3d.Coord3D, len(cells)),
+			Volume: float64(len(cells)) * cellVolume,
+		}
+		for j, idx := range cells {
+			void.Points[j] = point(idx)
+		}
+		res = append(res, void)
+	}
+	return res
+}
+
+// AddDrainChannels cuts a straight channel from each void
+// through the solid towards a face of its bounding box.
+//
+// Each channel starts at the void's extreme point in the
+// direction of the face.
+// For example, BoxTop drains voids upward, which suits
+// resin prints hanging from an inverted build plate.
+func AddDrainChannels(solid model3d.Solid, voids []*TrappedVoid, face BoxFace,
+	radius float64) model3d.Solid {
+	if len(voids) == 0 {
+		return solid
+	}
+	normal := boxFaceNormal(face)
+	min, max := solid.Min(), solid.Max()
+	// Distance along the normal to go past the bounds.
+	limit := math.Max(max.Dot(normal), min.Dot(normal)) + radius
+	var channels model3d.JoinedSolid
+	for _, v := range voids {
+		start := v.Extreme(face)
+		channels = append(channels, &model3d.Cylinder{
+			P1:     start,
+			P2:     start.Add(normal.Scale(limit - start.Dot(normal))),
+			Radius: radius,
+		})
+	}
+	return &model3d.SubtractedSolid{
+		Positive: solid,
+		Negative: channels.Optimize(),
+	}
+}
+
+func boxFaceNormal(face BoxFace) model3d.Coord3D {
+	switch face {
+	case BoxFront:
+		return model3d.Y(-1)
+	case BoxBack:
+		return model3d.Y(1)
+	case BoxLeft:
+		return model3d.X(-1)
+	case BoxRight:
+		return model3d.X(1)
+	case BoxTop:
+		return model3d.Z(1)
+	case BoxBottom:
+		return model3d.Z(-1)
+	default:
+		panic("unknown box face")
+	}
+}
@@ -0,0 +1,55 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestFindTrappedVoids(t *testing.T) {
+	hollow := &model3d.SubtractedSolid{
+		Positive: &model3d.Rect{MaxVal: model3d.XYZ(4, 4, 4)},
+		Negative: model3d.JoinedSolid{
+			&model3d.Rect{MinVal: model3d.XYZ(1, 1, 1), MaxVal: model3d.XYZ(3, 3, 2)},
+			&model3d.Sphere{Center: model3d.XYZ(2, 2, 3.1), Radius: 0.5},
+		},
+	}
+	voids := FindTrappedVoids(hollow, 0.1)
+	if len(voids) != 2 {
+		t.Fatalf("expected 2 voids but got %d", len(voids))
+	}
+	var boxVoid *TrappedVoid
+	for _, v := range voids {
+		if v.Volume > 1 {
+			boxVoid = v
+		}
+	}
+	if boxVoid == nil || math.Abs(boxVoid.Volume-4) > 0.5 {
+		t.Fatalf("unexpected box void")
+	}
+	if boxVoid.Min().Dist(model3d.XYZ(1, 1, 1)) > 0.2 || boxVoid.Max().Dist(model3d.XYZ(3, 3, 2)) > 0.2 {
+		t.Errorf("unexpected bounds: %v %v", boxVoid.Min(), boxVoid.Max())
+	}
+	if z := boxVoid.Extreme(BoxBottom).Z; math.Abs(z-1) > 0.11 {
+		t.Errorf("unexpected extreme z: %f", z)
+	}
+
+	drained := AddDrainChannels(hollow, voids, BoxBottom, 0.25)
+	if remaining := FindTrappedVoids(drained, 0.1); len(remaining) != 0 {
+		t.Errorf("expected no voids after draining, but got %d", len(remaining))
+	}
+	if drained.Contains(boxVoid.Extreme(BoxBottom).Sub(model3d.Z(0.5))) {
+		t.Error("expected drain channel below void")
+	}
+}
+
+func TestFindTrappedVoidsOpen(t *testing.T) {
+	cup := &model3d.SubtractedSolid{
+		Positive: &model3d.Cylinder{P2: model3d.Z(2), Radius: 1},
+		Negative: &model3d.Cylinder{P1: model3d.Z(0.2), P2: model3d.Z(3), Radius: 0.8},
+	}
+	if voids := FindTrappedVoids(cup, 0.05); len(voids) != 0 {
+		t.Errorf("expected no voids but got %d", len(voids))
+	}
+}
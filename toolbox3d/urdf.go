@@ -0,0 +1,297 @@
+package toolbox3d
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/unixpickle/model3d/model3d"
+)
+
+// urdfRootLink is the name of the link added to join
+// multiple stationary parts into one tree.
+const urdfRootLink = "root"
+
+// SaveURDF writes a URDF robot description of the
+// mechanism, for use in simulators such as Gazebo.
+//
+// The URDF file is written to dir/name.urdf, and every
+// part is meshed with marching cubes (using delta) and
+// saved as an STL file in dir/meshes.
+//
+// Link masses and inertias are computed from the meshes,
+// assuming a uniform density.
+// Coordinates are written as-is, so models should be in
+// meters (and density in kg/m^3) for most simulators.
+//
+// Revolute joints are exported as continuous joints unless
+// they have limits, and gear couplings are exported as
+// mimic joints.
+func (m *Mechanism) SaveURDF(dir, name string, density, delta float64) error {
+	if err := os.MkdirAll(filepath.Join(dir, "meshes"), 0755); err != nil {
+		return errors.Wrap(err, "save URDF")
+	}
+	robot, meshes := m.buildURDF(name, density, delta)
+	for partName, mesh := range meshes {
+		if err := mesh.SaveGroupedSTL(filepath.Join(dir, "meshes", partName+".stl")); err != nil {
+			return errors.Wrap(err, "save URDF")
+		}
+	}
+	data, err := xml.MarshalIndent(robot, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "save URDF")
+	}
+	data = append([]byte(xml.Header), data...)
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".urdf"), data, 0644); err != nil {
+		return errors.Wrap(err, "save URDF")
+	}
+	return nil
+}
+
+func (m *Mechanism) buildURDF(name string, density, delta float64) (*urdfRobot,
+	map[string]*model3d.Mesh) {
+	// Every link frame is aligned with the world axes, and
+	// is centered at the link's parent joint (or the world
+	// origin, for stationary parts).
+	origins := map[string]model3d.Coord3D{}
+	var roots []string
+	for _, partName := range m.assembly.names {
+		if j, ok := m.byChild[partName]; ok {
+			origins[partName] = j.Axis.Origin
+		} else {
+			roots = append(roots, partName)
+		}
+	}
+
+	robot := &urdfRobot{Name: name}
+	meshes := map[string]*model3d.Mesh{}
+	for _, partName := range m.assembly.names {
+		origin := origins[partName]
+		mesh := m.partMesh(partName, delta).Transform(model3d.JoinedTransform{
+			m.assembly.Transform(partName),
+			&model3d.Translate{Offset: origin.Scale(-1)},
+		})
+		meshes[partName] = mesh
+
+		mass, com, inertia := urdfMassProperties(mesh, density)
+		geometry := &urdfGeometry{Mesh: urdfMesh{Filename: "meshes/" + partName + ".stl"}}
+		robot.Links = append(robot.Links, &urdfLink{
+			Name: partName,
+			Inertial: &urdfInertial{
+				Origin: urdfOrigin{XYZ: urdfVector(com), RPY: "0 0 0"},
+				Mass:   urdfValue{Value: mass},
+				Inertia: urdfInertia{
+					IXX: inertia[0], IXY: inertia[1], IXZ: inertia[2],
+					IYY: inertia[4], IYZ: inertia[5], IZZ: inertia[8],
+				},
+			},
+			Visual:    &urdfVisual{Geometry: geometry},
+			Collision: &urdfVisual{Geometry: geometry},
+		})
+	}
+
+	if len(roots) > 1 {
+		robot.Links = append([]*urdfLink{{Name: urdfRootLink}}, robot.Links...)
+		for _, root := range roots {
+			robot.Joints = append(robot.Joints, &urdfJoint{
+				Name:   urdfRootLink + "_" + root,
+				Type:   "fixed",
+				Parent: urdfLinkRef{Link: urdfRootLink},
+				Child:  urdfLinkRef{Link: root},
+				Origin: urdfOrigin{XYZ: "0 0 0", RPY: "0 0 0"},
+			})
+		}
+	}
+
+	for _, j := range m.joints {
+		joint := &urdfJoint{
+			Name:   j.Name,
+			Parent: urdfLinkRef{Link: j.Parent},
+			Child:  urdfLinkRef{Link: j.Child},
+			Origin: urdfOrigin{
+				XYZ: urdfVector(origins[j.Child].Sub(origins[j.Parent])),
+				RPY: "0 0 0",
+			},
+			Axis: &urdfAxis{XYZ: urdfVector(j.Axis.ZAxis())},
+		}
+		lower, upper := j.Lower, j.Upper
+		hasLimits := lower != upper
+		if j.Kind == RevoluteJoint {
+			if hasLimits {
+				joint.Type = "revolute"
+			} else {
+				joint.Type = "continuous"
+			}
+		} else {
+			joint.Type = "prismatic"
+			if !hasLimits {
+				lower, upper = -1e6, 1e6
+			}
+		}
+		if joint.Type != "continuous" {
+			joint.Limit = &urdfLimit{
+				Lower:    lower,
+				Upper:    upper,
+				Effort:   1e3,
+				Velocity: 1e3,
+			}
+		}
+		if j.Driver != "" {
+			joint.Mimic = &urdfMimic{Joint: j.Driver, Multiplier: j.Ratio}
+		}
+		robot.Joints = append(robot.Joints, joint)
+	}
+	return robot, meshes
+}
+
+// urdfMassProperties computes the mass, center of mass,
+// and inertia tensor (about the center of mass) of a
+// closed mesh with uniform density.
+func urdfMassProperties(m *model3d.Mesh, density float64) (float64, model3d.Coord3D,
+	model3d.Matrix3) {
+	var volume float64
+	var moment model3d.Coord3D
+	var second model3d.Matrix3
+	m.Iterate(func(t *model3d.Triangle) {
+		// Integrate over the tetrahedron between the
+		// triangle and the origin.
+		v := model3d.NewMatrix3Columns(t[0], t[1], t[2]).Det() / 6
+		volume += v
+		sum := t[0].Add(t[1]).Add(t[2])
+		moment = moment.Add(sum.Scale(v / 4))
+		for _, c1 := range t {
+			outer := urdfOuter(c1, c1)
+			for k := range second {
+				second[k] += outer[k] * v / 20
+			}
+		}
+		outer := urdfOuter(sum, sum)
+		for k := range second {
+			second[k] += outer[k] * v / 20
+		}
+	})
+	if volume < 0 {
+		volume, moment = -volume, moment.Scale(-1)
+		for k := range second {
+			second[k] = -second[k]
+		}
+	}
+	if volume == 0 {
+		return 0, model3d.Coord3D{}, model3d.Matrix3{}
+	}
+	com := moment.Scale(1 / volume)
+
+	// Shift the second moment to the center of mass.
+	shift := urdfOuter(com, com)
+	for k := range second {
+		second[k] = (second[k] - shift[k]*volume) * density
+	}
+	trace := second[0] + second[4] + second[8]
+	var inertia model3d.Matrix3
+	for k := range inertia {
+		inertia[k] = -second[k]
+	}
+	for k := 0; k < 9; k += 4 {
+		inertia[k] += trace
+	}
+	return volume * density, com, inertia
+}
+
+func urdfOuter(c1, c2 model3d.Coord3D) model3d.Matrix3 {
+	a1, a2 := c1.Array(), c2.Array()
+	var res model3d.Matrix3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			res[i*3+j] = a1[i] * a2[j]
+		}
+	}
+	return res
+}
+
+func urdfVector(c model3d.Coord3D) string {
+	return fmt.Sprintf("%g %g %g", c.X, c.Y, c.Z)
+}
+
+type urdfRobot struct {
+	XMLName xml.Name     `xml:"robot"`
+	Name    string       `xml:"name,attr"`
+	Links   []*urdfLink  `xml:"link"`
+	Joints  []*urdfJoint `xml:"joint"`
+}
+
+type urdfLink struct {
+	Name      string        `xml:"name,attr"`
+	Inertial  *urdfInertial `xml:"inertial,omitempty"`
+	Visual    *urdfVisual   `xml:"visual,omitempty"`
+	Collision *urdfVisual   `xml:"collision,omitempty"`
+}
+
+type urdfInertial struct {
+	Origin  urdfOrigin  `xml:"origin"`
+	Mass    urdfValue   `xml:"mass"`
+	Inertia urdfInertia `xml:"inertia"`
+}
+
+type urdfInertia struct {
+	IXX float64 `xml:"ixx,attr"`
+	IXY float64 `xml:"ixy,attr"`
+	IXZ float64 `xml:"ixz,attr"`
+	IYY float64 `xml:"iyy,attr"`
+	IYZ float64 `xml:"iyz,attr"`
+	IZZ float64 `xml:"izz,attr"`
+}
+
+type urdfValue struct {
+	Value float64 `xml:"value,attr"`
+}
+
+type urdfVisual struct {
+	Geometry *urdfGeometry `xml:"geometry"`
+}
+
+type urdfGeometry struct {
+	Mesh urdfMesh `xml:"mesh"`
+}
+
+type urdfMesh struct {
+	Filename string `xml:"filename,attr"`
+}
+
+type urdfOrigin struct {
+	XYZ string `xml:"xyz,attr"`
+	RPY string `xml:"rpy,attr"`
+}
+
+type urdfJoint struct {
+	Name   string      `xml:"name,attr"`
+	Type   string      `xml:"type,attr"`
+	Origin urdfOrigin  `xml:"origin"`
+	Parent urdfLinkRef `xml:"parent"`
+	Child  urdfLinkRef `xml:"child"`
+	Axis   *urdfAxis   `xml:"axis,omitempty"`
+	Limit  *urdfLimit  `xml:"limit,omitempty"`
+	Mimic  *urdfMimic  `xml:"mimic,omitempty"`
+}
+
+type urdfLinkRef struct {
+	Link string `xml:"link,attr"`
+}
+
+type urdfAxis struct {
+	XYZ string `xml:"xyz,attr"`
+}
+
+type urdfLimit struct {
+	Lower    float64 `xml:"lower,attr"`
+	Upper    float64 `xml:"upper,attr"`
+	Effort   float64 `xml:"effort,attr"`
+	Velocity float64 `xml:"velocity,attr"`
+}
+
+type urdfMimic struct {
+	Joint      string  `xml:"joint,attr"`
+	Multiplier float64 `xml:"multiplier,attr"`
+}
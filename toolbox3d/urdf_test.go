@@ -0,0 +1,67 @@
+package toolbox3d
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/model3d/model3d"
+)
+
+func TestURDFMassProperties(t *testing.T) {
+	mesh := model3d.NewMeshRect(model3d.XYZ(1, 2, 3), model3d.XYZ(3, 3, 6))
+	mass, com, inertia := urdfMassProperties(mesh, 2)
+	if math.Abs(mass-12) > 1e-8 {
+		t.Errorf("unexpected mass: %f", mass)
+	}
+	if com.Dist(model3d.XYZ(2, 2.5, 4.5)) > 1e-8 {
+		t.Errorf("unexpected center of mass: %v", com)
+	}
+	// Box inertia: m/12 * (b^2 + c^2), etc.
+	expected := model3d.Matrix3{
+		mass / 12 * (1 + 9), 0, 0,
+		0, mass / 12 * (4 + 9), 0,
+		0, 0, mass / 12 * (4 + 1),
+	}
+	for i, x := range expected {
+		if math.Abs(x-inertia[i]) > 1e-8 {
+			t.Fatalf("expected inertia %v but got %v", expected, inertia)
+		}
+	}
+}
+
+func TestURDFJoints(t *testing.T) {
+	base := NewAnchoredSolid(&model3d.Rect{MaxVal: model3d.XYZ(1, 1, 1)})
+	base.AddAnchor("axle", NewAnchor(model3d.XYZ(0.5, 0.5, 1), model3d.Z(1), model3d.X(1)))
+	wheel := NewAnchoredSolid(&model3d.Cylinder{
+		P1:     model3d.XYZ(0.5, 0.5, 1),
+		P2:     model3d.XYZ(0.5, 0.5, 1.2),
+		Radius: 0.4,
+	})
+	other := NewAnchoredSolid(&model3d.Rect{MinVal: model3d.X(2), MaxVal: model3d.XYZ(3, 1, 1)})
+	a := NewAssembly()
+	a.AddPart("base", base, true)
+	a.AddPart("wheel", wheel, false)
+	a.AddPart("other", other, true)
+	m := NewMechanism(a)
+	m.Revolute("spin", "base", "wheel", "base.axle")
+
+	robot, meshes := m.buildURDF("test", 1, 0.05)
+	if len(meshes) != 3 {
+		t.Errorf("unexpected number of meshes: %d", len(meshes))
+	}
+	if len(robot.Links) != 4 || robot.Links[0].Name != urdfRootLink {
+		t.Fatalf("unexpected links: %v", robot.Links)
+	}
+	if len(robot.Joints) != 3 {
+		t.Fatalf("unexpected number of joints: %d", len(robot.Joints))
+	}
+	spin := robot.Joints[2]
+	if spin.Type != "continuous" || spin.Origin.XYZ != "0.5 0.5 1" || spin.Axis.XYZ != "0 0 1" {
+		t.Errorf("unexpected joint: %+v", spin)
+	}
+
+	// The wheel's mesh should be relative to its joint.
+	if min := meshes["wheel"].Min(); math.Abs(min.Z) > 0.05 || math.Abs(min.X+0.4) > 0.05 {
+		t.Errorf("unexpected wheel bounds: %v", min)
+	}
+}